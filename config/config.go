@@ -56,6 +56,23 @@ type Config struct {
 	AllowedFileTypes []string
 	UploadPath       string
 
+	// Document Storage Configuration
+	StorageBackend     string
+	StoragePath        string
+	StorageURLSecret   string
+	StorageScanCommand string
+	S3Endpoint         string
+	S3Region           string
+	S3Bucket           string
+	S3AccessKeyID      string
+	S3SecretAccessKey  string
+	S3UsePathStyle     bool
+
+	// Share certificate signing key: a base64 Ed25519 seed, required at startup
+	CertificateSigningKey string
+
+	// Audit checkpoint signing secret, required at startup
+	AuditSigningKey string
 
 	// Google OAuth Configuration
 	GoogleClientID     string
@@ -139,6 +156,23 @@ func Load() *Config {
 		AllowedFileTypes: []string{"image/jpeg", "image/png", "image/webp"},
 		UploadPath:       getEnv("UPLOAD_PATH", "./uploads"),
 
+		// Document Storage Configuration
+		StorageBackend:     getEnv("STORAGE_BACKEND", "local"),
+		StoragePath:        getEnv("STORAGE_PATH", "./storage/files"),
+		StorageURLSecret:   getEnv("STORAGE_URL_SECRET", ""),   // required; the server will not start without it
+		StorageScanCommand: getEnv("STORAGE_SCAN_COMMAND", ""), // e.g. "clamdscan --no-summary -"
+		S3Endpoint:         getEnv("S3_ENDPOINT", ""),
+		S3Region:           getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:           getEnv("S3_BUCKET", ""),
+		S3AccessKeyID:      getEnv("S3_ACCESS_KEY_ID", ""),
+		S3SecretAccessKey:  getEnv("S3_SECRET_ACCESS_KEY", ""),
+		S3UsePathStyle:     getEnvAsBool("S3_USE_PATH_STYLE", false),
+
+		// Share certificate signing key; the server will not start without it
+		CertificateSigningKey: getEnv("CERTIFICATE_SIGNING_KEY", ""),
+
+		// Audit checkpoint signing secret; the server will not start without it
+		AuditSigningKey: getEnv("AUDIT_SIGNING_KEY", ""),
 
 		// Google OAuth Configuration
 		GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
@@ -2043,6 +2043,141 @@ func (m *MigrationManager) RunMigrations() error {
 		return fmt.Errorf("failed to insert vibrate sound: %w", err)
 	}
 
+	// Meeting agendas, motions and resolutions
+	if err := m.runMigration("create_meeting_governance_tables", m.createMeetingGovernanceTables); err != nil {
+		return fmt.Errorf("failed to run meeting governance migration: %w", err)
+	}
+
+	// The recipient_id step in Migrate is unreachable, so make sure the column exists
+	if err := m.runMigration("ensure_transactions_recipient_id", func() error { return addRecipientIDToTransactions(m.db) }); err != nil {
+		return fmt.Errorf("failed to ensure transactions recipient_id column: %w", err)
+	}
+
+	// Attendance fine rules, apologies and member fines
+	if err := m.runMigration("create_attendance_fine_tables", m.createAttendanceFineTables); err != nil {
+		return fmt.Errorf("failed to run attendance fines migration: %w", err)
+	}
+
+	// Welfare approval rules, claim limits, documents and payouts
+	if err := m.runMigration("create_welfare_lifecycle_tables", m.createWelfareLifecycleTables); err != nil {
+		return fmt.Errorf("failed to run welfare lifecycle migration: %w", err)
+	}
+
+	// Welfare levies raised per member for approved cases
+	if err := m.runMigration("create_welfare_levy_tables", m.createWelfareLevyTables); err != nil {
+		return fmt.Errorf("failed to run welfare levy migration: %w", err)
+	}
+
+	// Share price history, redemptions, splits and the member order book
+	if err := m.runMigration("create_share_market_tables", m.createShareMarketTables); err != nil {
+		return fmt.Errorf("failed to run share market migration: %w", err)
+	}
+
+	// Append-only, hash-chained audit log and signed checkpoints
+	if err := m.runMigration("create_audit_log_tables", m.createAuditLogTables); err != nil {
+		return fmt.Errorf("failed to run audit log migration: %w", err)
+	}
+
+	// Persisted money requests between users, including split requests
+	if err := m.runMigration("create_money_request_tables", m.createMoneyRequestTables); err != nil {
+		return fmt.Errorf("failed to run money request migration: %w", err)
+	}
+
+	// Recurring standing-order transfers from personal wallets
+	if err := m.runMigration("create_standing_order_tables", m.createStandingOrderTables); err != nil {
+		return fmt.Errorf("failed to run standing order migration: %w", err)
+	}
+
+	// Savings goals, fixed-term locked savings and chama fixed savings rules
+	if err := m.runMigration("create_savings_tables", m.createSavingsTables); err != nil {
+		return fmt.Errorf("failed to run savings migration: %w", err)
+	}
+
+	// Exchange rates, foreign-currency wallets and FX details on transactions
+	if err := m.runMigration("create_fx_tables", m.createFXTables); err != nil {
+		return fmt.Errorf("failed to run FX migration: %w", err)
+	}
+
+	// Admin-managed fee schedule, per-tier outgoing limits and limit usage
+	if err := m.runMigration("create_fee_and_limit_tables", m.createFeeAndLimitTables); err != nil {
+		return fmt.Errorf("failed to run fee and limit migration: %w", err)
+	}
+
+	// KYC submissions, identity documents, review decisions and per-tier loan ceilings
+	if err := m.runMigration("create_kyc_tables", m.createKYCTables); err != nil {
+		return fmt.Errorf("failed to run KYC migration: %w", err)
+	}
+
+	// Fraud rules, the evaluation of each outgoing transaction and per-rule hits
+	if err := m.runMigration("create_fraud_tables", m.createFraudTables); err != nil {
+		return fmt.Errorf("failed to run fraud migration: %w", err)
+	}
+
+	// Transaction disputes, their evidence and the refunds that settle them
+	if err := m.runMigration("create_dispute_tables", m.createDisputeTables); err != nil {
+		return fmt.Errorf("failed to run dispute migration: %w", err)
+	}
+
+	// Access-controlled file storage for meeting, chat and welfare uploads
+	if err := m.runMigration("create_stored_files_table", m.createStoredFilesTable); err != nil {
+		return fmt.Errorf("failed to run stored files migration: %w", err)
+	}
+
+	// Dividend record dates, time-weighted computation and withholding tax
+	if err := m.runMigration("add_dividend_entitlement_columns", m.addDividendEntitlementColumns); err != nil {
+		return fmt.Errorf("failed to run dividend entitlement migration: %w", err)
+	}
+
+	// Standing dividend reinvestment elections and what each payment reinvested
+	if err := m.runMigration("create_dividend_reinvestment_tables", m.createDividendReinvestmentTables); err != nil {
+		return fmt.Errorf("failed to run dividend reinvestment migration: %w", err)
+	}
+
+	// Signed share certificates, cancelled and re-issued as holdings change
+	if err := m.runMigration("create_share_certificates_table", m.createShareCertificatesTable); err != nil {
+		return fmt.Errorf("failed to run share certificates migration: %w", err)
+	}
+
+	// Investment portfolio assets, valuations, income and NAV history
+	if err := m.runMigration("create_portfolio_tables", m.createPortfolioTables); err != nil {
+		return fmt.Errorf("failed to run portfolio migration: %w", err)
+	}
+
+	// Chama expense claims, their approvals, approval rules and budget lines
+	if err := m.runMigration("create_expense_tables", m.createExpenseTables); err != nil {
+		return fmt.Errorf("failed to run expense migration: %w", err)
+	}
+
+	// Chama bank accounts, imported statements and their reconciliation matches
+	if err := m.runMigration("create_bank_reconciliation_tables", m.createBankReconciliationTables); err != nil {
+		return fmt.Errorf("failed to run bank reconciliation migration: %w", err)
+	}
+
+	// Per-chama chart of accounts and the ledger account each activity posts to
+	if err := m.runMigration("create_ledger_accounts_tables", m.createLedgerAccountsTables); err != nil {
+		return fmt.Errorf("failed to run ledger accounts migration: %w", err)
+	}
+
+	// Financial periods and the triggers that lock records dated inside closed ones
+	if err := m.runMigration("create_financial_periods_table", m.createFinancialPeriodsTable); err != nil {
+		return fmt.Errorf("failed to run financial periods migration: %w", err)
+	}
+
+	// Monthly member statements kept for in-app download
+	if err := m.runMigration("create_member_statements_table", m.createMemberStatementsTable); err != nil {
+		return fmt.Errorf("failed to run member statements migration: %w", err)
+	}
+
+	// KYC documents and dispute evidence kept by the storage service
+	if err := m.runMigration("add_kyc_and_dispute_file_ids", m.addKYCAndDisputeFileIDs); err != nil {
+		return fmt.Errorf("failed to run KYC and dispute file migration: %w", err)
+	}
+
+	// Which bank account a chama transaction went through, for reconciliation
+	if err := m.runMigration("add_transaction_bank_account", m.addTransactionBankAccount); err != nil {
+		return fmt.Errorf("failed to run transaction bank account migration: %w", err)
+	}
+
 	log.Println("✅ All migrations completed successfully!")
 	return nil
 }
@@ -2730,3 +2865,1668 @@ func refactorChatMessageContent(db *sql.DB) error {
 	log.Printf("Refactored chat message content to store only ciphertext")
 	return nil
 }
+
+// createMeetingGovernanceTables creates the agenda, motion and resolution tables
+func (m *MigrationManager) createMeetingGovernanceTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS meeting_agenda_items (
+			id TEXT PRIMARY KEY,
+			meeting_id TEXT NOT NULL,
+			position INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			description TEXT,
+			presenter_id TEXT,
+			allocated_minutes INTEGER DEFAULT 0,
+			status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'in_progress', 'completed', 'deferred')),
+			created_by TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (meeting_id) REFERENCES meetings(id) ON DELETE CASCADE,
+			FOREIGN KEY (presenter_id) REFERENCES users(id),
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS meeting_motions (
+			id TEXT PRIMARY KEY,
+			meeting_id TEXT NOT NULL,
+			chama_id TEXT NOT NULL,
+			agenda_item_id TEXT,
+			title TEXT NOT NULL,
+			description TEXT,
+			proposed_by TEXT NOT NULL,
+			seconded_by TEXT,
+			seconded_at DATETIME,
+			status TEXT NOT NULL DEFAULT 'proposed' CHECK (status IN ('proposed', 'seconded', 'voting', 'carried', 'defeated', 'withdrawn')),
+			votes_for INTEGER DEFAULT 0,
+			votes_against INTEGER DEFAULT 0,
+			abstentions INTEGER DEFAULT 0,
+			voting_opened_at DATETIME,
+			voting_closed_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (meeting_id) REFERENCES meetings(id) ON DELETE CASCADE,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id),
+			FOREIGN KEY (agenda_item_id) REFERENCES meeting_agenda_items(id) ON DELETE SET NULL,
+			FOREIGN KEY (proposed_by) REFERENCES users(id),
+			FOREIGN KEY (seconded_by) REFERENCES users(id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS meeting_motion_votes (
+			id TEXT PRIMARY KEY,
+			motion_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			choice TEXT NOT NULL CHECK (choice IN ('for', 'against', 'abstain')),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (motion_id) REFERENCES meeting_motions(id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES users(id),
+			UNIQUE(motion_id, user_id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS meeting_resolutions (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			meeting_id TEXT NOT NULL,
+			motion_id TEXT UNIQUE,
+			minutes_id TEXT,
+			resolution_number TEXT NOT NULL,
+			title TEXT NOT NULL,
+			text TEXT NOT NULL,
+			votes_for INTEGER DEFAULT 0,
+			votes_against INTEGER DEFAULT 0,
+			abstentions INTEGER DEFAULT 0,
+			passed_at DATETIME NOT NULL,
+			recorded_by TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id),
+			FOREIGN KEY (meeting_id) REFERENCES meetings(id),
+			FOREIGN KEY (motion_id) REFERENCES meeting_motions(id),
+			FOREIGN KEY (minutes_id) REFERENCES meeting_minutes(id),
+			FOREIGN KEY (recorded_by) REFERENCES users(id),
+			UNIQUE(chama_id, resolution_number)
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_agenda_items_meeting ON meeting_agenda_items(meeting_id, position)`,
+		`CREATE INDEX IF NOT EXISTS idx_motions_meeting ON meeting_motions(meeting_id, status)`,
+		`CREATE INDEX IF NOT EXISTS idx_motion_votes_motion ON meeting_motion_votes(motion_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_resolutions_chama ON meeting_resolutions(chama_id, passed_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_resolutions_meeting ON meeting_resolutions(meeting_id)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createAttendanceFineTables creates the attendance fine rules, apologies and fines tables
+func (m *MigrationManager) createAttendanceFineTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS attendance_fine_rules (
+			chama_id TEXT PRIMARY KEY,
+			is_enabled BOOLEAN DEFAULT 0,
+			absence_fine REAL DEFAULT 0,
+			excused_absence_fine REAL DEFAULT 0,
+			lateness_fine REAL DEFAULT 0,
+			lateness_grace_minutes INTEGER DEFAULT 15,
+			apology_deadline_hours INTEGER DEFAULT 2,
+			updated_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (updated_by) REFERENCES users(id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS meeting_apologies (
+			id TEXT PRIMARY KEY,
+			meeting_id TEXT NOT NULL,
+			chama_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			reason TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'accepted' CHECK (status IN ('accepted', 'late', 'rejected')),
+			submitted_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			reviewed_by TEXT,
+			reviewed_at DATETIME,
+			FOREIGN KEY (meeting_id) REFERENCES meetings(id) ON DELETE CASCADE,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id),
+			FOREIGN KEY (user_id) REFERENCES users(id),
+			FOREIGN KEY (reviewed_by) REFERENCES users(id),
+			UNIQUE(meeting_id, user_id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS member_fines (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			meeting_id TEXT,
+			fine_type TEXT NOT NULL CHECK (fine_type IN ('absence', 'lateness')),
+			amount REAL NOT NULL,
+			reason TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'outstanding' CHECK (status IN ('outstanding', 'paid', 'waived')),
+			transaction_id TEXT,
+			paid_at DATETIME,
+			waived_by TEXT,
+			waived_at DATETIME,
+			waiver_reason TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id),
+			FOREIGN KEY (user_id) REFERENCES users(id),
+			FOREIGN KEY (meeting_id) REFERENCES meetings(id),
+			FOREIGN KEY (waived_by) REFERENCES users(id),
+			UNIQUE(meeting_id, user_id, fine_type)
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_meeting_apologies_meeting ON meeting_apologies(meeting_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_member_fines_chama_user ON member_fines(chama_id, user_id, status)`,
+		`CREATE INDEX IF NOT EXISTS idx_member_fines_meeting ON member_fines(meeting_id)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createWelfareLifecycleTables creates welfare approval rules, claim limits and supporting
+// documents, and adds payout tracking to welfare requests
+func (m *MigrationManager) createWelfareLifecycleTables() error {
+	// beneficiary_id may still be missing on databases created before it was introduced
+	if err := addMissingWelfareRequestBeneficiaryField(m.db); err != nil {
+		return err
+	}
+
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS welfare_approval_rules (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			category TEXT NOT NULL DEFAULT 'any',
+			urgency TEXT NOT NULL DEFAULT 'any',
+			approval_percentage REAL NOT NULL DEFAULT 50,
+			auto_disburse BOOLEAN DEFAULT TRUE,
+			created_by TEXT NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (created_by) REFERENCES users(id),
+			UNIQUE(chama_id, category, urgency)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS welfare_settings (
+			chama_id TEXT PRIMARY KEY,
+			max_claims_per_year INTEGER DEFAULT 0,
+			max_amount_per_year REAL DEFAULT 0,
+			updated_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (updated_by) REFERENCES users(id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS welfare_request_documents (
+			id TEXT PRIMARY KEY,
+			welfare_request_id TEXT NOT NULL,
+			uploaded_by TEXT NOT NULL,
+			file_name TEXT NOT NULL,
+			file_path TEXT NOT NULL,
+			file_url TEXT NOT NULL,
+			file_size INTEGER DEFAULT 0,
+			file_type TEXT,
+			document_type TEXT NOT NULL DEFAULT 'supporting_document',
+			description TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (welfare_request_id) REFERENCES welfare_requests(id) ON DELETE CASCADE,
+			FOREIGN KEY (uploaded_by) REFERENCES users(id)
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_welfare_request_documents_request ON welfare_request_documents(welfare_request_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_welfare_requests_beneficiary ON welfare_requests(chama_id, beneficiary_id)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	columns := []struct {
+		name       string
+		definition string
+	}{
+		{"disbursed_amount", "REAL DEFAULT 0"},
+		{"disbursed_at", "DATETIME"},
+		{"disbursement_transaction_id", "TEXT"},
+	}
+	for _, col := range columns {
+		var count int
+		if err := m.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('welfare_requests') WHERE name = ?`, col.name).Scan(&count); err != nil {
+			return fmt.Errorf("failed to check welfare_requests.%s: %w", col.name, err)
+		}
+		if count > 0 {
+			continue
+		}
+		if _, err := m.db.Exec(fmt.Sprintf("ALTER TABLE welfare_requests ADD COLUMN %s %s", col.name, col.definition)); err != nil {
+			return fmt.Errorf("failed to add welfare_requests.%s: %w", col.name, err)
+		}
+	}
+
+	return nil
+}
+
+// createWelfareLevyTables creates welfare levies, member assessments and wallet deduction consents
+func (m *MigrationManager) createWelfareLevyTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS welfare_levies (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			welfare_request_id TEXT NOT NULL,
+			title TEXT NOT NULL,
+			amount_per_member REAL NOT NULL,
+			target_amount REAL NOT NULL,
+			collected_amount REAL DEFAULT 0,
+			status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'closed', 'cancelled')),
+			due_date DATETIME,
+			reminder_interval_hours INTEGER DEFAULT 24,
+			last_reminder_at DATETIME,
+			created_by TEXT NOT NULL,
+			closed_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id),
+			FOREIGN KEY (welfare_request_id) REFERENCES welfare_requests(id),
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS welfare_levy_assessments (
+			id TEXT PRIMARY KEY,
+			levy_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			amount REAL NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'cancelled')),
+			payment_method TEXT,
+			transaction_id TEXT,
+			reminders_sent INTEGER DEFAULT 0,
+			last_reminded_at DATETIME,
+			paid_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (levy_id) REFERENCES welfare_levies(id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES users(id),
+			UNIQUE(levy_id, user_id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS welfare_levy_consents (
+			chama_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			max_amount REAL DEFAULT 0,
+			granted_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (chama_id, user_id),
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES users(id)
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_welfare_levies_chama ON welfare_levies(chama_id, status)`,
+		`CREATE INDEX IF NOT EXISTS idx_welfare_levies_request ON welfare_levies(welfare_request_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_welfare_levy_assessments_user ON welfare_levy_assessments(user_id, status)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createShareMarketTables creates tables for share valuation, splits and member-to-member share sales
+func (m *MigrationManager) createShareMarketTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS share_prices (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			price REAL NOT NULL,
+			source TEXT NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'nav', 'split')),
+			net_asset_value REAL,
+			shares_outstanding INTEGER DEFAULT 0,
+			notes TEXT,
+			set_by TEXT NOT NULL,
+			effective_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (set_by) REFERENCES users(id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS share_market_settings (
+			chama_id TEXT PRIMARY KEY,
+			lock_in_days INTEGER DEFAULT 0,
+			redemption_enabled BOOLEAN DEFAULT TRUE,
+			secondary_market_enabled BOOLEAN DEFAULT TRUE,
+			updated_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS share_splits (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			ratio_from INTEGER NOT NULL,
+			ratio_to INTEGER NOT NULL,
+			shares_before INTEGER NOT NULL,
+			shares_after INTEGER NOT NULL,
+			executed_by TEXT NOT NULL,
+			notes TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (executed_by) REFERENCES users(id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS share_listings (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			seller_id TEXT NOT NULL,
+			share_id TEXT NOT NULL,
+			quantity INTEGER NOT NULL,
+			remaining_quantity INTEGER NOT NULL,
+			price_per_share REAL NOT NULL,
+			status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'filled', 'cancelled')),
+			notes TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (seller_id) REFERENCES users(id),
+			FOREIGN KEY (share_id) REFERENCES shares(id)
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_share_prices_chama ON share_prices(chama_id, effective_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_share_listings_chama ON share_listings(chama_id, status, price_per_share)`,
+		`CREATE INDEX IF NOT EXISTS idx_share_listings_share ON share_listings(share_id, status)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createAuditLogTables creates the hash-chained audit log and its checkpoints.
+// Triggers reject updates and deletes so entries can only be appended.
+func (m *MigrationManager) createAuditLogTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS audit_log (
+			id TEXT PRIMARY KEY,
+			chain_id TEXT NOT NULL,
+			sequence INTEGER NOT NULL,
+			actor_id TEXT,
+			action TEXT NOT NULL,
+			entity_type TEXT NOT NULL,
+			entity_id TEXT NOT NULL,
+			amount REAL,
+			details TEXT NOT NULL DEFAULT '{}',
+			previous_hash TEXT NOT NULL,
+			entry_hash TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			UNIQUE(chain_id, sequence)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS audit_checkpoints (
+			id TEXT PRIMARY KEY,
+			chain_id TEXT NOT NULL,
+			sequence INTEGER NOT NULL,
+			entry_hash TEXT NOT NULL,
+			signature TEXT NOT NULL,
+			public_key TEXT NOT NULL,
+			created_by TEXT,
+			created_at TEXT NOT NULL
+		)`,
+
+		`CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+		BEGIN
+			SELECT RAISE(ABORT, 'audit log is append-only');
+		END`,
+
+		`CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+		BEGIN
+			SELECT RAISE(ABORT, 'audit log is append-only');
+		END`,
+
+		`CREATE TRIGGER IF NOT EXISTS audit_checkpoints_no_update BEFORE UPDATE ON audit_checkpoints
+		BEGIN
+			SELECT RAISE(ABORT, 'audit checkpoints are append-only');
+		END`,
+
+		`CREATE TRIGGER IF NOT EXISTS audit_checkpoints_no_delete BEFORE DELETE ON audit_checkpoints
+		BEGIN
+			SELECT RAISE(ABORT, 'audit checkpoints are append-only');
+		END`,
+
+		`CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log(chain_id, action)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_checkpoints_chain ON audit_checkpoints(chain_id, sequence)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createMoneyRequestTables creates the table behind money requests and their lifecycle
+func (m *MigrationManager) createMoneyRequestTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS money_requests (
+			id TEXT PRIMARY KEY,
+			requester_id TEXT NOT NULL,
+			target_user_id TEXT,
+			amount REAL NOT NULL,
+			reason TEXT NOT NULL DEFAULT '',
+			request_type TEXT NOT NULL DEFAULT 'direct',
+			status TEXT NOT NULL DEFAULT 'pending',
+			split_id TEXT,
+			transaction_id TEXT,
+			paid_by TEXT,
+			paid_at DATETIME,
+			decline_reason TEXT,
+			responded_at DATETIME,
+			reminder_sent_at DATETIME,
+			expires_at DATETIME NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (requester_id) REFERENCES users(id),
+			FOREIGN KEY (target_user_id) REFERENCES users(id),
+			FOREIGN KEY (paid_by) REFERENCES users(id)
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_money_requests_requester ON money_requests(requester_id, status)`,
+		`CREATE INDEX IF NOT EXISTS idx_money_requests_target ON money_requests(target_user_id, status)`,
+		`CREATE INDEX IF NOT EXISTS idx_money_requests_split ON money_requests(split_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_money_requests_expiry ON money_requests(status, expires_at)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createStandingOrderTables creates standing orders and their execution history
+func (m *MigrationManager) createStandingOrderTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS standing_orders (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			target_type TEXT NOT NULL,
+			chama_id TEXT,
+			recipient_id TEXT,
+			amount REAL NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			frequency TEXT NOT NULL,
+			day_of_month INTEGER,
+			day_of_week INTEGER,
+			status TEXT NOT NULL DEFAULT 'active',
+			start_date DATETIME NOT NULL,
+			end_date DATETIME,
+			max_executions INTEGER,
+			execution_count INTEGER NOT NULL DEFAULT 0,
+			max_retries INTEGER NOT NULL DEFAULT 3,
+			retry_count INTEGER NOT NULL DEFAULT 0,
+			next_run_at DATETIME,
+			retry_at DATETIME,
+			last_run_at DATETIME,
+			last_failure_reason TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id),
+			FOREIGN KEY (chama_id) REFERENCES chamas(id),
+			FOREIGN KEY (recipient_id) REFERENCES users(id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS standing_order_executions (
+			id TEXT PRIMARY KEY,
+			standing_order_id TEXT NOT NULL,
+			scheduled_for DATETIME NOT NULL,
+			attempt INTEGER NOT NULL DEFAULT 1,
+			status TEXT NOT NULL,
+			amount REAL NOT NULL,
+			transaction_id TEXT,
+			failure_reason TEXT,
+			executed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (standing_order_id) REFERENCES standing_orders(id) ON DELETE CASCADE
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_standing_orders_user ON standing_orders(user_id, status)`,
+		`CREATE INDEX IF NOT EXISTS idx_standing_orders_due ON standing_orders(status, next_run_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_standing_order_executions_order ON standing_order_executions(standing_order_id, executed_at)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createSavingsTables creates savings goals, fixed-term savings and the chama rules behind them
+func (m *MigrationManager) createSavingsTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS savings_goals (
+			id TEXT PRIMARY KEY,
+			wallet_id TEXT NOT NULL UNIQUE,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			target_amount REAL NOT NULL,
+			target_date DATETIME,
+			status TEXT NOT NULL DEFAULT 'active',
+			sweep_type TEXT,
+			sweep_amount REAL,
+			sweep_frequency TEXT,
+			sweep_day INTEGER,
+			next_sweep_at DATETIME,
+			last_sweep_at DATETIME,
+			achieved_at DATETIME,
+			closed_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (wallet_id) REFERENCES wallets(id),
+			FOREIGN KEY (user_id) REFERENCES users(id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS fixed_savings (
+			id TEXT PRIMARY KEY,
+			wallet_id TEXT NOT NULL UNIQUE,
+			user_id TEXT NOT NULL,
+			chama_id TEXT,
+			name TEXT NOT NULL DEFAULT '',
+			principal REAL NOT NULL,
+			term_months INTEGER NOT NULL,
+			interest_rate REAL NOT NULL DEFAULT 0,
+			early_withdrawal_penalty_rate REAL NOT NULL DEFAULT 0,
+			start_date DATETIME NOT NULL,
+			maturity_date DATETIME NOT NULL,
+			status TEXT NOT NULL DEFAULT 'active',
+			interest_paid REAL NOT NULL DEFAULT 0,
+			penalty_charged REAL NOT NULL DEFAULT 0,
+			payout_amount REAL NOT NULL DEFAULT 0,
+			payout_transaction_id TEXT,
+			closed_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (wallet_id) REFERENCES wallets(id),
+			FOREIGN KEY (user_id) REFERENCES users(id),
+			FOREIGN KEY (chama_id) REFERENCES chamas(id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS fixed_savings_rules (
+			chama_id TEXT PRIMARY KEY,
+			enabled BOOLEAN NOT NULL DEFAULT FALSE,
+			annual_interest_rate REAL NOT NULL DEFAULT 0,
+			early_withdrawal_penalty_rate REAL NOT NULL DEFAULT 5,
+			min_term_months INTEGER NOT NULL DEFAULT 1,
+			max_term_months INTEGER NOT NULL DEFAULT 60,
+			updated_by TEXT,
+			updated_at DATETIME,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id)
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_savings_goals_user ON savings_goals(user_id, status)`,
+		`CREATE INDEX IF NOT EXISTS idx_savings_goals_sweep ON savings_goals(status, next_sweep_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_fixed_savings_user ON fixed_savings(user_id, status)`,
+		`CREATE INDEX IF NOT EXISTS idx_fixed_savings_maturity ON fixed_savings(status, maturity_date)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createFXTables creates the exchange rate table, limits foreign-currency wallets to one
+// per owner and currency, and adds FX columns to transactions and a base currency to chamas
+func (m *MigrationManager) createFXTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS exchange_rates (
+			id TEXT PRIMARY KEY,
+			base_currency TEXT NOT NULL,
+			quote_currency TEXT NOT NULL,
+			rate REAL NOT NULL CHECK (rate > 0),
+			spread_percent REAL NOT NULL DEFAULT 0,
+			source TEXT NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'import')),
+			effective_at DATETIME NOT NULL,
+			created_by TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_exchange_rates_pair ON exchange_rates(base_currency, quote_currency, effective_at)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_currency_owner ON wallets(owner_id, currency) WHERE type = 'currency'`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	columns := []struct {
+		table      string
+		name       string
+		definition string
+	}{
+		{"transactions", "fx_rate", "REAL"},
+		{"transactions", "fx_spread", "REAL"},
+		{"transactions", "converted_amount", "REAL"},
+		{"transactions", "converted_currency", "TEXT"},
+		{"chamas", "base_currency", "TEXT DEFAULT 'KES'"},
+	}
+	for _, col := range columns {
+		var count int
+		if err := m.db.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM pragma_table_info('%s') WHERE name = ?`, col.table), col.name).Scan(&count); err != nil {
+			return fmt.Errorf("failed to check %s.%s: %w", col.table, col.name, err)
+		}
+		if count > 0 {
+			continue
+		}
+		if _, err := m.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", col.table, col.name, col.definition)); err != nil {
+			return fmt.Errorf("failed to add %s.%s: %w", col.table, col.name, err)
+		}
+	}
+
+	return nil
+}
+
+// createFeeAndLimitTables creates the fee schedule, seeded with the fees that used to be
+// hard-coded, the per-KYC-tier outgoing limits and the ledger of limit usage
+func (m *MigrationManager) createFeeAndLimitTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS fee_rules (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			transaction_type TEXT NOT NULL DEFAULT '',
+			payment_method TEXT NOT NULL DEFAULT '',
+			calculation TEXT NOT NULL CHECK (calculation IN ('flat', 'percentage', 'banded')),
+			flat_fee REAL NOT NULL DEFAULT 0,
+			percentage REAL NOT NULL DEFAULT 0,
+			min_fee REAL NOT NULL DEFAULT 0,
+			max_fee REAL,
+			bands TEXT NOT NULL DEFAULT '[]',
+			is_active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_by TEXT NOT NULL,
+			updated_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS transaction_limit_tiers (
+			tier INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			single_transaction_limit REAL NOT NULL,
+			daily_limit REAL NOT NULL,
+			monthly_limit REAL NOT NULL,
+			updated_by TEXT,
+			updated_at DATETIME
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS transaction_limit_usage (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			reference TEXT,
+			amount REAL NOT NULL,
+			created_at DATETIME NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(id)
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_fee_rules_match ON fee_rules(is_active, transaction_type, payment_method)`,
+		`CREATE INDEX IF NOT EXISTS idx_transaction_limit_usage_user ON transaction_limit_usage(user_id, created_at)`,
+
+		`INSERT OR IGNORE INTO fee_rules (id, name, transaction_type, payment_method, calculation, flat_fee, percentage, min_fee, created_by) VALUES
+			('fee-default-withdrawal-mpesa', 'M-Pesa withdrawal', 'withdrawal', 'mpesa', 'percentage', 0, 2, 10, 'system'),
+			('fee-default-withdrawal-bank', 'Bank withdrawal', 'withdrawal', 'bank', 'percentage', 0, 1, 10, 'system'),
+			('fee-default-withdrawal', 'Withdrawal', 'withdrawal', '', 'flat', 5, 0, 0, 'system'),
+			('fee-default-transfer', 'Wallet transfer', 'transfer', '', 'flat', 2, 0, 0, 'system')`,
+
+		`INSERT OR IGNORE INTO transaction_limit_tiers (tier, name, single_transaction_limit, daily_limit, monthly_limit) VALUES
+			(0, 'Unverified', 70000, 150000, 300000),
+			(1, 'Basic', 150000, 300000, 1000000),
+			(2, 'Verified', 1000000, 1000000, 5000000),
+			(3, 'Enhanced', 1000000, 5000000, 20000000)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	var count int
+	if err := m.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('users') WHERE name = 'kyc_tier'`).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check users.kyc_tier: %w", err)
+	}
+	if count == 0 {
+		if _, err := m.db.Exec("ALTER TABLE users ADD COLUMN kyc_tier INTEGER DEFAULT 0"); err != nil {
+			return fmt.Errorf("failed to add users.kyc_tier: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createKYCTables creates KYC submissions, their documents and the reviews of them, and
+// gives each limit tier the largest loan its members may apply for
+func (m *MigrationManager) createKYCTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS kyc_submissions (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			requested_tier INTEGER NOT NULL,
+			status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'pending', 'approved', 'rejected', 'expired', 'superseded')),
+			full_name TEXT NOT NULL,
+			national_id_number TEXT NOT NULL,
+			date_of_birth DATE NOT NULL,
+			submitted_at DATETIME,
+			reviewed_by TEXT,
+			reviewed_at DATETIME,
+			rejection_reason TEXT,
+			review_notes TEXT,
+			expires_at DATETIME,
+			expiry_reminder_sent_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id),
+			FOREIGN KEY (reviewed_by) REFERENCES users(id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS kyc_documents (
+			id TEXT PRIMARY KEY,
+			submission_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			document_type TEXT NOT NULL,
+			file_name TEXT NOT NULL,
+			file_path TEXT NOT NULL,
+			file_size INTEGER NOT NULL,
+			file_type TEXT NOT NULL,
+			uploaded_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (submission_id) REFERENCES kyc_submissions(id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES users(id),
+			UNIQUE(submission_id, document_type)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS kyc_reviews (
+			id TEXT PRIMARY KEY,
+			submission_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			reviewer_id TEXT NOT NULL,
+			decision TEXT NOT NULL,
+			tier INTEGER NOT NULL,
+			reason TEXT,
+			notes TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (submission_id) REFERENCES kyc_submissions(id),
+			FOREIGN KEY (reviewer_id) REFERENCES users(id)
+		)`,
+
+		// A user has at most one submission being filled in or awaiting review
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_kyc_submissions_open ON kyc_submissions(user_id) WHERE status IN ('draft', 'pending')`,
+		`CREATE INDEX IF NOT EXISTS idx_kyc_submissions_status ON kyc_submissions(status, submitted_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_kyc_submissions_id_number ON kyc_submissions(national_id_number)`,
+		`CREATE INDEX IF NOT EXISTS idx_kyc_reviews_reviewer ON kyc_reviews(reviewer_id, created_at)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	var count int
+	if err := m.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('transaction_limit_tiers') WHERE name = 'max_loan_amount'`).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check transaction_limit_tiers.max_loan_amount: %w", err)
+	}
+	if count == 0 {
+		if _, err := m.db.Exec("ALTER TABLE transaction_limit_tiers ADD COLUMN max_loan_amount REAL NOT NULL DEFAULT 0"); err != nil {
+			return fmt.Errorf("failed to add transaction_limit_tiers.max_loan_amount: %w", err)
+		}
+		if _, err := m.db.Exec(`
+			UPDATE transaction_limit_tiers SET max_loan_amount = CASE tier
+				WHEN 1 THEN 50000 WHEN 2 THEN 500000 WHEN 3 THEN 5000000 ELSE 0 END
+		`); err != nil {
+			return fmt.Errorf("failed to seed loan ceilings: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createFraudTables creates the fraud rules with their default settings, the evaluation
+// recorded for each outgoing transaction and the rule hits behind each evaluation
+func (m *MigrationManager) createFraudTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS fraud_rules (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL,
+			is_active BOOLEAN NOT NULL DEFAULT TRUE,
+			action TEXT NOT NULL CHECK (action IN ('score', 'hold', 'block')),
+			score INTEGER NOT NULL DEFAULT 0,
+			params TEXT NOT NULL DEFAULT '{}',
+			updated_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS fraud_evaluations (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			chama_id TEXT,
+			transaction_type TEXT NOT NULL,
+			amount REAL NOT NULL,
+			recipient_id TEXT,
+			from_wallet_id TEXT,
+			transaction_id TEXT,
+			score INTEGER NOT NULL DEFAULT 0,
+			decision TEXT NOT NULL CHECK (decision IN ('allow', 'hold', 'block')),
+			status TEXT NOT NULL CHECK (status IN ('cleared', 'pending_review', 'released', 'rejected', 'blocked')),
+			hits TEXT NOT NULL DEFAULT '[]',
+			reviewed_by TEXT,
+			reviewed_at DATETIME,
+			review_notes TEXT,
+			consumed_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id),
+			FOREIGN KEY (reviewed_by) REFERENCES users(id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS fraud_rule_hits (
+			id TEXT PRIMARY KEY,
+			evaluation_id TEXT NOT NULL,
+			rule_id TEXT NOT NULL,
+			score INTEGER NOT NULL,
+			action TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (evaluation_id) REFERENCES fraud_evaluations(id) ON DELETE CASCADE,
+			FOREIGN KEY (rule_id) REFERENCES fraud_rules(id)
+		)`,
+
+		// Created lazily by the security handlers; the device rule reads it from day one
+		`CREATE TABLE IF NOT EXISTS login_sessions (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+			user_id TEXT NOT NULL,
+			device_type TEXT,
+			device_name TEXT,
+			operating_system TEXT,
+			browser TEXT,
+			ip_address TEXT,
+			location TEXT,
+			login_time DATETIME DEFAULT CURRENT_TIMESTAMP,
+			last_activity DATETIME DEFAULT CURRENT_TIMESTAMP,
+			status TEXT DEFAULT 'active',
+			is_current BOOLEAN DEFAULT FALSE,
+			FOREIGN KEY (user_id) REFERENCES users(id)
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_fraud_evaluations_user ON fraud_evaluations(user_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_fraud_evaluations_chama ON fraud_evaluations(chama_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_fraud_evaluations_status ON fraud_evaluations(status, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_fraud_evaluations_transaction ON fraud_evaluations(transaction_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_fraud_rule_hits_rule ON fraud_rule_hits(rule_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_login_sessions_user ON login_sessions(user_id, login_time)`,
+
+		`INSERT OR IGNORE INTO fraud_rules (id, name, description, action, score, params) VALUES
+			('velocity', 'Velocity', 'Too many outgoing transactions, or too much money, within a short window', 'hold', 40,
+				'{"windowMinutes":60,"maxCount":5,"maxAmount":200000}'),
+			('new_recipient_large', 'Large payment to a new recipient', 'A large payment to someone the sender has never paid before', 'score', 40,
+				'{"minAmount":20000}'),
+			('new_device_withdrawal', 'New device then withdrawal', 'Money leaves shortly after a login from a device the user has not used before', 'hold', 60,
+				'{"windowHours":24,"minAmount":5000}'),
+			('official_round_trip', 'Round trip between officials', 'Two officials of the same chama pay each other back and forth', 'hold', 50,
+				'{"windowHours":72}'),
+			('chama_drain', 'Chama wallet drain', 'A large share of a chama wallet leaves within a window, with a lower threshold overnight. Outflows below minAmount are ignored', 'block', 100,
+				'{"windowHours":24,"minAmount":10000,"maxPercent":50,"nightStartHour":22,"nightEndHour":5,"nightMaxPercent":20}')`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createDisputeTables creates disputes raised against transfers and the evidence
+// attached to them
+func (m *MigrationManager) createDisputeTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS disputes (
+			id TEXT PRIMARY KEY,
+			transaction_id TEXT NOT NULL,
+			raised_by TEXT NOT NULL,
+			counterparty_id TEXT NOT NULL,
+			reason TEXT NOT NULL CHECK (reason IN ('wrong_recipient', 'wrong_amount', 'unauthorized', 'duplicate', 'other')),
+			description TEXT NOT NULL,
+			transaction_amount REAL NOT NULL,
+			amount REAL NOT NULL,
+			held_amount REAL NOT NULL DEFAULT 0,
+			hold_wallet_id TEXT,
+			status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'under_review', 'resolved', 'withdrawn')),
+			response_deadline DATETIME NOT NULL,
+			counterparty_accepted BOOLEAN,
+			counterparty_response TEXT,
+			responded_at DATETIME,
+			resolution TEXT CHECK (resolution IN ('full_refund', 'partial_refund', 'no_refund')),
+			refund_amount REAL NOT NULL DEFAULT 0,
+			refund_transaction_id TEXT,
+			resolved_by TEXT,
+			resolved_at DATETIME,
+			resolution_notes TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+			FOREIGN KEY (raised_by) REFERENCES users(id),
+			FOREIGN KEY (counterparty_id) REFERENCES users(id),
+			FOREIGN KEY (hold_wallet_id) REFERENCES wallets(id),
+			FOREIGN KEY (refund_transaction_id) REFERENCES transactions(id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS dispute_evidence (
+			id TEXT PRIMARY KEY,
+			dispute_id TEXT NOT NULL,
+			uploaded_by TEXT NOT NULL,
+			file_name TEXT NOT NULL,
+			file_path TEXT NOT NULL,
+			file_size INTEGER NOT NULL,
+			file_type TEXT NOT NULL,
+			note TEXT,
+			uploaded_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (dispute_id) REFERENCES disputes(id) ON DELETE CASCADE,
+			FOREIGN KEY (uploaded_by) REFERENCES users(id)
+		)`,
+
+		// A transaction can be disputed again only after an earlier dispute was withdrawn
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_disputes_transaction ON disputes(transaction_id) WHERE status != 'withdrawn'`,
+		`CREATE INDEX IF NOT EXISTS idx_disputes_raised_by ON disputes(raised_by, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_disputes_counterparty ON disputes(counterparty_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_disputes_status ON disputes(status, response_deadline)`,
+		`CREATE INDEX IF NOT EXISTS idx_dispute_evidence_dispute ON dispute_evidence(dispute_id)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createStoredFilesTable creates the record of files kept by the storage service and
+// links meeting and welfare documents to them
+func (m *MigrationManager) createStoredFilesTable() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS stored_files (
+			id TEXT PRIMARY KEY,
+			backend TEXT NOT NULL,
+			object_key TEXT NOT NULL UNIQUE,
+			file_name TEXT NOT NULL,
+			content_type TEXT NOT NULL,
+			size INTEGER NOT NULL,
+			checksum TEXT NOT NULL,
+			owner_id TEXT NOT NULL,
+			category TEXT NOT NULL,
+			access_scope TEXT NOT NULL CHECK (access_scope IN ('public', 'authenticated', 'chama', 'chat_room', 'private')),
+			scope_id TEXT,
+			scan_status TEXT NOT NULL CHECK (scan_status IN ('clean', 'unscanned')),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			deleted_at DATETIME,
+			FOREIGN KEY (owner_id) REFERENCES users(id)
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_stored_files_owner ON stored_files(owner_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_stored_files_scope ON stored_files(access_scope, scope_id)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	for _, table := range []string{"meeting_documents", "welfare_request_documents"} {
+		var count int
+		if err := m.db.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM pragma_table_info('%s') WHERE name = 'file_id'`, table)).Scan(&count); err != nil {
+			return fmt.Errorf("failed to check %s.file_id: %w", table, err)
+		}
+		if count > 0 {
+			continue
+		}
+		if _, err := m.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN file_id TEXT REFERENCES stored_files(id)", table)); err != nil {
+			return fmt.Errorf("failed to add %s.file_id: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// addDividendEntitlementColumns adds the record date, computation method and
+// withholding tax rate to dividend declarations, and the gross amount, tax and
+// share basis behind each dividend payment
+func (m *MigrationManager) addDividendEntitlementColumns() error {
+	columns := []struct {
+		table      string
+		name       string
+		definition string
+	}{
+		{"dividend_declarations", "record_date", "DATETIME"},
+		{"dividend_declarations", "computation_method", "TEXT NOT NULL DEFAULT 'snapshot'"},
+		{"dividend_declarations", "period_start", "DATETIME"},
+		{"dividend_declarations", "period_end", "DATETIME"},
+		{"dividend_declarations", "withholding_tax_rate", "REAL NOT NULL DEFAULT 0"},
+		{"dividend_payments", "eligible_shares", "REAL NOT NULL DEFAULT 0"},
+		{"dividend_payments", "gross_amount", "REAL NOT NULL DEFAULT 0"},
+		{"dividend_payments", "withholding_tax", "REAL NOT NULL DEFAULT 0"},
+	}
+
+	for _, column := range columns {
+		var count int
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM pragma_table_info('%s') WHERE name = ?`, column.table)
+		if err := m.db.QueryRow(query, column.name).Scan(&count); err != nil {
+			return fmt.Errorf("failed to check %s.%s: %w", column.table, column.name, err)
+		}
+		if count > 0 {
+			continue
+		}
+		if _, err := m.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", column.table, column.name, column.definition)); err != nil {
+			return fmt.Errorf("failed to add %s.%s: %w", column.table, column.name, err)
+		}
+	}
+
+	// Payments created before withholding tax were paid gross
+	migrations := []string{
+		`UPDATE dividend_payments SET gross_amount = dividend_amount, eligible_shares = shares_eligible WHERE gross_amount = 0`,
+		`UPDATE dividend_declarations SET record_date = declaration_date WHERE record_date IS NULL`,
+		`CREATE INDEX IF NOT EXISTS idx_share_transactions_chama_date ON share_transactions(chama_id, transaction_date)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createDividendReinvestmentTables creates members' standing dividend reinvestment
+// elections and records how much of each dividend payment bought shares
+func (m *MigrationManager) createDividendReinvestmentTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS dividend_reinvestment_elections (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			member_id TEXT NOT NULL,
+			election TEXT NOT NULL CHECK (election IN ('cash', 'reinvest', 'split')),
+			reinvest_percent REAL NOT NULL DEFAULT 0 CHECK (reinvest_percent >= 0 AND reinvest_percent <= 100),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (chama_id, member_id),
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (member_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	columns := []struct {
+		name       string
+		definition string
+	}{
+		{"reinvested_shares", "INTEGER NOT NULL DEFAULT 0"},
+		{"reinvested_amount", "REAL NOT NULL DEFAULT 0"},
+		{"reinvestment_price", "REAL NOT NULL DEFAULT 0"},
+		{"reinvestment_share_id", "TEXT REFERENCES shares(id)"},
+		{"cash_amount", "REAL NOT NULL DEFAULT 0"},
+	}
+
+	for _, column := range columns {
+		var count int
+		if err := m.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('dividend_payments') WHERE name = ?`, column.name).Scan(&count); err != nil {
+			return fmt.Errorf("failed to check dividend_payments.%s: %w", column.name, err)
+		}
+		if count > 0 {
+			continue
+		}
+		if _, err := m.db.Exec(fmt.Sprintf("ALTER TABLE dividend_payments ADD COLUMN %s %s", column.name, column.definition)); err != nil {
+			return fmt.Errorf("failed to add dividend_payments.%s: %w", column.name, err)
+		}
+	}
+
+	// Everything paid before reinvestment plans was paid in cash
+	if _, err := m.db.Exec(`UPDATE dividend_payments SET cash_amount = dividend_amount WHERE payment_status = 'paid' AND cash_amount = 0 AND reinvested_shares = 0`); err != nil {
+		return fmt.Errorf("failed to backfill dividend cash amounts: %w", err)
+	}
+
+	return nil
+}
+
+// createShareCertificatesTable creates the certificates issued for share holdings.
+// Cancelled certificates are kept so that old numbers still verify as cancelled.
+func (m *MigrationManager) createShareCertificatesTable() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS share_certificates (
+			id TEXT PRIMARY KEY,
+			certificate_number TEXT NOT NULL UNIQUE,
+			chama_id TEXT NOT NULL,
+			share_id TEXT NOT NULL,
+			member_id TEXT NOT NULL,
+			shares_count INTEGER NOT NULL CHECK (shares_count > 0),
+			share_class TEXT NOT NULL,
+			issue_date DATETIME NOT NULL,
+			status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'cancelled')),
+			cancelled_at DATETIME,
+			cancellation_reason TEXT,
+			replaced_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (share_id) REFERENCES shares(id) ON DELETE CASCADE,
+			FOREIGN KEY (member_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_share_certificates_share ON share_certificates(share_id, status)`,
+		`CREATE INDEX IF NOT EXISTS idx_share_certificates_member ON share_certificates(chama_id, member_id)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createPortfolioTables creates the investment portfolio of a chama: the assets it
+// holds, their valuations and income, and the recorded net asset value history
+func (m *MigrationManager) createPortfolioTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS portfolio_assets (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			asset_type TEXT NOT NULL CHECK (asset_type IN ('land', 'treasury_bill', 'sacco_deposit', 'money_market_fund', 'equipment', 'other')),
+			description TEXT,
+			acquisition_date DATETIME NOT NULL,
+			acquisition_cost REAL NOT NULL CHECK (acquisition_cost > 0),
+			current_value REAL NOT NULL,
+			last_valued_at DATETIME NOT NULL,
+			maturity_date DATETIME,
+			status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'disposed')),
+			disposal_date DATETIME,
+			disposal_amount REAL,
+			created_by TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS portfolio_valuations (
+			id TEXT PRIMARY KEY,
+			asset_id TEXT NOT NULL,
+			chama_id TEXT NOT NULL,
+			value REAL NOT NULL CHECK (value >= 0),
+			valuation_date DATETIME NOT NULL,
+			source TEXT NOT NULL CHECK (source IN ('cost', 'appraisal', 'market', 'statement', 'disposal')),
+			notes TEXT,
+			recorded_by TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (asset_id) REFERENCES portfolio_assets(id) ON DELETE CASCADE,
+			FOREIGN KEY (recorded_by) REFERENCES users(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS portfolio_entries (
+			id TEXT PRIMARY KEY,
+			asset_id TEXT NOT NULL,
+			chama_id TEXT NOT NULL,
+			entry_type TEXT NOT NULL CHECK (entry_type IN ('income', 'expense')),
+			category TEXT NOT NULL,
+			amount REAL NOT NULL CHECK (amount > 0),
+			entry_date DATETIME NOT NULL,
+			description TEXT,
+			transaction_id TEXT,
+			recorded_by TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (asset_id) REFERENCES portfolio_assets(id) ON DELETE CASCADE,
+			FOREIGN KEY (recorded_by) REFERENCES users(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS chama_nav_history (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			cash_balance REAL NOT NULL DEFAULT 0,
+			loan_book REAL NOT NULL DEFAULT 0,
+			portfolio_value REAL NOT NULL DEFAULT 0,
+			other_assets REAL NOT NULL DEFAULT 0,
+			liabilities REAL NOT NULL DEFAULT 0,
+			net_asset_value REAL NOT NULL,
+			shares_outstanding INTEGER NOT NULL DEFAULT 0,
+			nav_per_share REAL NOT NULL DEFAULT 0,
+			notes TEXT,
+			recorded_by TEXT NOT NULL,
+			recorded_at DATETIME NOT NULL,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_portfolio_assets_chama ON portfolio_assets(chama_id, status)`,
+		`CREATE INDEX IF NOT EXISTS idx_portfolio_valuations_asset ON portfolio_valuations(asset_id, valuation_date)`,
+		`CREATE INDEX IF NOT EXISTS idx_portfolio_entries_asset ON portfolio_entries(asset_id, entry_date)`,
+		`CREATE INDEX IF NOT EXISTS idx_chama_nav_history_chama ON chama_nav_history(chama_id, recorded_at)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createExpenseTables creates chama expense claims, the officials' decisions on them,
+// the rules that set how many approvals a claim needs and annual budget lines
+func (m *MigrationManager) createExpenseTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS chama_budget_lines (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			fiscal_year INTEGER NOT NULL,
+			category TEXT NOT NULL,
+			name TEXT NOT NULL,
+			amount REAL NOT NULL CHECK (amount >= 0),
+			created_by TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (chama_id, fiscal_year, category),
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS chama_expense_approval_rules (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			min_amount REAL NOT NULL DEFAULT 0 CHECK (min_amount >= 0),
+			approvals_required INTEGER NOT NULL DEFAULT 1 CHECK (approvals_required >= 1),
+			receipt_required BOOLEAN NOT NULL DEFAULT TRUE,
+			created_by TEXT NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (chama_id, min_amount),
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS chama_expenses (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			category TEXT NOT NULL CHECK (category IN ('venue', 'refreshments', 'transport', 'stationery', 'communication', 'bank_charges', 'professional_fees', 'other')),
+			budget_line_id TEXT,
+			title TEXT NOT NULL,
+			description TEXT,
+			vendor TEXT,
+			amount REAL NOT NULL CHECK (amount > 0),
+			expense_date DATETIME NOT NULL,
+			meeting_id TEXT,
+			paid_by_claimant BOOLEAN NOT NULL DEFAULT FALSE,
+			receipt_file_id TEXT,
+			status TEXT NOT NULL DEFAULT 'submitted' CHECK (status IN ('submitted', 'approved', 'rejected', 'paid', 'cancelled')),
+			approvals_required INTEGER NOT NULL DEFAULT 1,
+			receipt_required BOOLEAN NOT NULL DEFAULT TRUE,
+			approval_count INTEGER NOT NULL DEFAULT 0,
+			submitted_by TEXT NOT NULL,
+			rejected_by TEXT,
+			rejection_reason TEXT,
+			paid_by TEXT,
+			paid_at DATETIME,
+			transaction_id TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (budget_line_id) REFERENCES chama_budget_lines(id) ON DELETE SET NULL,
+			FOREIGN KEY (receipt_file_id) REFERENCES stored_files(id),
+			FOREIGN KEY (submitted_by) REFERENCES users(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS chama_expense_approvals (
+			id TEXT PRIMARY KEY,
+			expense_id TEXT NOT NULL,
+			approver_id TEXT NOT NULL,
+			decision TEXT NOT NULL CHECK (decision IN ('approved', 'rejected')),
+			comment TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (expense_id, approver_id),
+			FOREIGN KEY (expense_id) REFERENCES chama_expenses(id) ON DELETE CASCADE,
+			FOREIGN KEY (approver_id) REFERENCES users(id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_chama_expenses_chama ON chama_expenses(chama_id, status, expense_date)`,
+		`CREATE INDEX IF NOT EXISTS idx_chama_expenses_budget_line ON chama_expenses(budget_line_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_chama_expense_approvals_expense ON chama_expense_approvals(expense_id)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createBankReconciliationTables creates the tables for reconciling chama bank
+// statements against recorded transactions
+func (m *MigrationManager) createBankReconciliationTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS chama_bank_accounts (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			bank_name TEXT NOT NULL,
+			account_name TEXT NOT NULL,
+			account_number TEXT NOT NULL,
+			currency TEXT NOT NULL DEFAULT 'KES',
+			opening_balance REAL NOT NULL DEFAULT 0,
+			opening_balance_date DATETIME NOT NULL,
+			is_active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_by TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (chama_id, account_number),
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS bank_statements (
+			id TEXT PRIMARY KEY,
+			account_id TEXT NOT NULL,
+			chama_id TEXT NOT NULL,
+			file_id TEXT,
+			file_name TEXT NOT NULL,
+			format TEXT NOT NULL CHECK (format IN ('csv', 'ofx', 'camt053')),
+			checksum TEXT NOT NULL,
+			period_start DATETIME,
+			period_end DATETIME,
+			opening_balance REAL,
+			closing_balance REAL,
+			line_count INTEGER NOT NULL DEFAULT 0,
+			duplicate_count INTEGER NOT NULL DEFAULT 0,
+			imported_by TEXT NOT NULL,
+			imported_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (account_id, checksum),
+			FOREIGN KEY (account_id) REFERENCES chama_bank_accounts(id) ON DELETE CASCADE,
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (file_id) REFERENCES stored_files(id),
+			FOREIGN KEY (imported_by) REFERENCES users(id)
+		)`,
+		`CREATE TABLE IF NOT EXISTS bank_statement_lines (
+			id TEXT PRIMARY KEY,
+			statement_id TEXT NOT NULL,
+			account_id TEXT NOT NULL,
+			chama_id TEXT NOT NULL,
+			line_number INTEGER NOT NULL,
+			transaction_date DATETIME NOT NULL,
+			value_date DATETIME,
+			description TEXT NOT NULL DEFAULT '',
+			reference TEXT,
+			external_id TEXT NOT NULL,
+			amount REAL NOT NULL,
+			balance REAL,
+			status TEXT NOT NULL DEFAULT 'unmatched' CHECK (status IN ('unmatched', 'matched', 'excluded')),
+			match_method TEXT CHECK (match_method IN ('auto', 'manual')),
+			exclusion_reason TEXT,
+			reconciled_by TEXT,
+			reconciled_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (account_id, external_id),
+			FOREIGN KEY (statement_id) REFERENCES bank_statements(id) ON DELETE CASCADE,
+			FOREIGN KEY (account_id) REFERENCES chama_bank_accounts(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS bank_statement_line_matches (
+			id TEXT PRIMARY KEY,
+			line_id TEXT NOT NULL,
+			account_id TEXT NOT NULL,
+			transaction_id TEXT NOT NULL UNIQUE,
+			matched_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (line_id) REFERENCES bank_statement_lines(id) ON DELETE CASCADE,
+			FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_bank_statement_lines_account ON bank_statement_lines(account_id, status, transaction_date)`,
+		`CREATE INDEX IF NOT EXISTS idx_bank_statement_line_matches_line ON bank_statement_line_matches(line_id)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createLedgerAccountsTables creates each chama's chart of accounts and the mapping
+// from chama activity to the accounts it posts to
+func (m *MigrationManager) createLedgerAccountsTables() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS chama_ledger_accounts (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			code TEXT NOT NULL,
+			name TEXT NOT NULL,
+			account_type TEXT NOT NULL CHECK (account_type IN ('asset', 'liability', 'equity', 'income', 'expense')),
+			description TEXT,
+			is_active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (chama_id, code),
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS chama_ledger_mappings (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			entry_type TEXT NOT NULL,
+			account_id TEXT NOT NULL,
+			updated_by TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (chama_id, entry_type),
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (account_id) REFERENCES chama_ledger_accounts(id)
+		)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createFinancialPeriodsTable creates the financial periods table and, for each
+// record the ledger is built from, triggers that reject inserts, changes and
+// deletes dated inside a closed period of the chama it belongs to
+func (m *MigrationManager) createFinancialPeriodsTable() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS chama_financial_periods (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			period_type TEXT NOT NULL CHECK (period_type IN ('monthly', 'annual')),
+			start_date DATETIME NOT NULL,
+			end_date DATETIME NOT NULL,
+			status TEXT NOT NULL CHECK (status IN ('closed', 'reopened')),
+			closed_by TEXT NOT NULL,
+			closed_at DATETIME NOT NULL,
+			reopened_by TEXT,
+			reopened_at DATETIME,
+			reopen_reason TEXT,
+			net_income REAL NOT NULL DEFAULT 0,
+			opening_retained_earnings REAL NOT NULL DEFAULT 0,
+			closing_retained_earnings REAL NOT NULL DEFAULT 0,
+			closing_balances TEXT,
+			closing_entry TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (chama_id, period_type, start_date),
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_chama_financial_periods_status ON chama_financial_periods(chama_id, status)`,
+	}
+
+	// Each locked table names the chama(s) a row belongs to, the date the ledger
+	// posts it on, when the row posts at all, and the columns the ledger reads
+	type periodLock struct {
+		table, chamas, date, posts, columns string
+	}
+	locks := []periodLock{
+		{
+			table: "transactions",
+			chamas: `%[1]s.recipient_id,
+				(SELECT owner_id FROM wallets WHERE id = %[1]s.from_wallet_id AND type = 'chama'),
+				(SELECT owner_id FROM wallets WHERE id = %[1]s.to_wallet_id AND type = 'chama')`,
+			date:    "%[1]s.created_at",
+			posts:   "%[1]s.status = 'completed'",
+			columns: "type, status, amount, created_at, recipient_id, from_wallet_id, to_wallet_id",
+		},
+		{
+			table:   "loans",
+			chamas:  "%[1]s.chama_id",
+			date:    "%[1]s.disbursed_at",
+			posts:   "%[1]s.disbursed_at IS NOT NULL",
+			columns: "chama_id, amount, disbursed_at",
+		},
+		{
+			table:   "loan_payments",
+			chamas:  "(SELECT chama_id FROM loans WHERE id = %[1]s.loan_id)",
+			date:    "%[1]s.paid_at",
+			posts:   "1",
+			columns: "loan_id, amount, principal_amount, interest_amount, paid_at",
+		},
+		{
+			table:   "dividend_payments",
+			chamas:  "(SELECT chama_id FROM dividend_declarations WHERE id = %[1]s.dividend_declaration_id)",
+			date:    "COALESCE(%[1]s.payment_date, %[1]s.updated_at)",
+			posts:   "%[1]s.payment_status = 'paid'",
+			columns: "dividend_declaration_id, payment_status, dividend_amount, reinvested_amount, cash_amount, payment_date",
+		},
+	}
+
+	locked := func(lock periodLock, row string) string {
+		return fmt.Sprintf(`(`+lock.posts+` AND EXISTS (
+			SELECT 1 FROM chama_financial_periods p
+			WHERE p.status = 'closed'
+			AND p.chama_id IN (`+lock.chamas+`)
+			AND julianday(`+lock.date+`) >= julianday(p.start_date)
+			AND julianday(`+lock.date+`) < julianday(p.end_date)
+		))`, row)
+	}
+
+	for _, lock := range locks {
+		migrations = append(migrations,
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %s_period_lock_insert BEFORE INSERT ON %s
+			WHEN %s
+			BEGIN
+				SELECT RAISE(ABORT, 'financial period is closed');
+			END`, lock.table, lock.table, locked(lock, "NEW")),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %s_period_lock_update BEFORE UPDATE OF %s ON %s
+			WHEN %s OR %s
+			BEGIN
+				SELECT RAISE(ABORT, 'financial period is closed');
+			END`, lock.table, lock.columns, lock.table, locked(lock, "OLD"), locked(lock, "NEW")),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %s_period_lock_delete BEFORE DELETE ON %s
+			WHEN %s
+			BEGIN
+				SELECT RAISE(ABORT, 'financial period is closed');
+			END`, lock.table, lock.table, locked(lock, "OLD")),
+		)
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createMemberStatementsTable creates the table of monthly member statements. Each
+// row keeps the statement as generated, so downloads match what was emailed.
+func (m *MigrationManager) createMemberStatementsTable() error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS member_statements (
+			id TEXT PRIMARY KEY,
+			chama_id TEXT NOT NULL,
+			member_id TEXT NOT NULL,
+			period_month TEXT NOT NULL,
+			period_start DATETIME NOT NULL,
+			period_end DATETIME NOT NULL,
+			opening_balance REAL NOT NULL DEFAULT 0,
+			closing_balance REAL NOT NULL DEFAULT 0,
+			statement_data TEXT NOT NULL,
+			emailed_at DATETIME,
+			email_error TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (chama_id, member_id, period_month),
+			FOREIGN KEY (chama_id) REFERENCES chamas(id) ON DELETE CASCADE,
+			FOREIGN KEY (member_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_member_statements_member ON member_statements(member_id, period_month)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := m.db.Exec(migration); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// addKYCAndDisputeFileIDs links KYC documents and dispute evidence to the storage
+// service. Rows saved before it keep their local file_path until they are imported.
+func (m *MigrationManager) addKYCAndDisputeFileIDs() error {
+	for _, table := range []string{"kyc_documents", "dispute_evidence"} {
+		var count int
+		if err := m.db.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM pragma_table_info('%s') WHERE name = 'file_id'`, table)).Scan(&count); err != nil {
+			return fmt.Errorf("failed to check %s.file_id: %w", table, err)
+		}
+		if count > 0 {
+			continue
+		}
+		if _, err := m.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN file_id TEXT REFERENCES stored_files(id)", table)); err != nil {
+			return fmt.Errorf("failed to add %s.file_id: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// addTransactionBankAccount records which chama bank account a transaction went
+// through, so reconciling one account does not count transfers made through another
+func (m *MigrationManager) addTransactionBankAccount() error {
+	var count int
+	if err := m.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('transactions') WHERE name = 'bank_account_id'`).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check transactions.bank_account_id: %w", err)
+	}
+	if count == 0 {
+		if _, err := m.db.Exec("ALTER TABLE transactions ADD COLUMN bank_account_id TEXT REFERENCES chama_bank_accounts(id)"); err != nil {
+			return fmt.Errorf("failed to add transactions.bank_account_id: %w", err)
+		}
+	}
+	if _, err := m.db.Exec("CREATE INDEX IF NOT EXISTS idx_transactions_bank_account ON transactions(bank_account_id)"); err != nil {
+		return fmt.Errorf("failed to execute migration: %w", err)
+	}
+	return nil
+}
@@ -7,6 +7,9 @@ import (
 	"strconv"
 	"time"
 
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -317,15 +320,43 @@ func (h *AccountHandlers) LogSecurityEvent(c *gin.Context) {
 		return
 	}
 
-	// Log the security event (simplified implementation)
-	// In a real implementation, you'd store this in a security_events table
+	eventType, _ := eventData["eventType"].(string)
+	if eventType == "" {
+		eventType, _ = eventData["type"].(string)
+	}
+	if eventType == "" {
+		eventType = "unspecified"
+	}
+
+	// Security events go to the platform audit chain so they cannot be quietly edited later
+	entry, err := services.NewAuditService(h.db).Log(&models.AuditEntry{
+		ChainID:    models.AuditPlatformChain,
+		ActorID:    &userID,
+		Action:     models.AuditActionSecurityEvent,
+		EntityType: "user",
+		EntityID:   userID,
+		Details: map[string]interface{}{
+			"eventType": eventType,
+			"event":     eventData,
+			"ipAddress": c.ClientIP(),
+			"userAgent": c.Request.UserAgent(),
+		},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to log security event: " + err.Error(),
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": map[string]interface{}{
-			"eventId":   fmt.Sprintf("evt_%d", time.Now().Unix()),
+			"eventId":   entry.ID,
 			"logged":    true,
-			"timestamp": time.Now().Format(time.RFC3339),
+			"entryHash": entry.EntryHash,
+			"timestamp": entry.CreatedAt.Format(time.RFC3339),
 		},
 	})
 }
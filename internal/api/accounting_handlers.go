@@ -0,0 +1,493 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountingHandlers handles a chama's chart of accounts, financial statements,
+// accounting exports and financial periods
+type AccountingHandlers struct {
+	accountingService *services.AccountingService
+	periodService     *services.FinancialPeriodService
+}
+
+// NewAccountingHandlers creates a new accounting handlers instance
+func NewAccountingHandlers(db *sql.DB) *AccountingHandlers {
+	return &AccountingHandlers{
+		accountingService: services.NewAccountingService(db),
+		periodService:     services.NewFinancialPeriodService(db),
+	}
+}
+
+// GetChartOfAccounts returns the chama's ledger accounts and entry type mappings
+func (h *AccountingHandlers) GetChartOfAccounts(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	chart, err := h.accountingService.GetChartOfAccounts(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    chart,
+	})
+}
+
+// CreateLedgerAccount adds an account to the chart of accounts
+func (h *AccountingHandlers) CreateLedgerAccount(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.CreateLedgerAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	account, err := h.accountingService.CreateLedgerAccount(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    account,
+		"message": "Ledger account created successfully",
+	})
+}
+
+// UpdateLedgerAccount renames or deactivates a ledger account
+func (h *AccountingHandlers) UpdateLedgerAccount(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.UpdateLedgerAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	account, err := h.accountingService.UpdateLedgerAccount(c.Param("id"), c.Param("accountId"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    account,
+		"message": "Ledger account updated successfully",
+	})
+}
+
+// SetLedgerMapping changes the account an entry type posts to
+func (h *AccountingHandlers) SetLedgerMapping(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.SetLedgerMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	chart, err := h.accountingService.SetLedgerMapping(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    chart,
+		"message": "Ledger mapping updated successfully",
+	})
+}
+
+// GetJournal lists journal entries for a period (?from=YYYY-MM-DD&to=YYYY-MM-DD)
+func (h *AccountingHandlers) GetJournal(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	from, to, ok := parseReportPeriod(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.accountingService.GetJournal(c.Param("id"), userID, from, to)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    entries,
+		"count":   len(entries),
+	})
+}
+
+// GetGeneralLedger returns each account's postings for a period
+// (?from=YYYY-MM-DD&to=YYYY-MM-DD)
+func (h *AccountingHandlers) GetGeneralLedger(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	from, to, ok := parseReportPeriod(c)
+	if !ok {
+		return
+	}
+
+	ledger, err := h.accountingService.GetGeneralLedger(c.Param("id"), userID, from, to)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    ledger,
+	})
+}
+
+// GetTrialBalance returns account balances at the end of a day (?asOf=YYYY-MM-DD)
+func (h *AccountingHandlers) GetTrialBalance(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	asOf, ok := parseAccountingAsOf(c)
+	if !ok {
+		return
+	}
+
+	trialBalance, err := h.accountingService.GetTrialBalance(c.Param("id"), userID, asOf)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    trialBalance,
+	})
+}
+
+// GetIncomeStatement returns income and expenses for a period
+// (?from=YYYY-MM-DD&to=YYYY-MM-DD)
+func (h *AccountingHandlers) GetIncomeStatement(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	from, to, ok := parseReportPeriod(c)
+	if !ok {
+		return
+	}
+
+	statement, err := h.accountingService.GetIncomeStatement(c.Param("id"), userID, from, to)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    statement,
+	})
+}
+
+// GetBalanceSheet returns assets, liabilities and equity at the end of a day
+// (?asOf=YYYY-MM-DD)
+func (h *AccountingHandlers) GetBalanceSheet(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	asOf, ok := parseAccountingAsOf(c)
+	if !ok {
+		return
+	}
+
+	sheet, err := h.accountingService.GetBalanceSheet(c.Param("id"), userID, asOf)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    sheet,
+	})
+}
+
+// ExportReport downloads a report as CSV, or the journal as a QuickBooks or Xero
+// import (?report=journal|general_ledger|trial_balance|income_statement|balance_sheet
+// &format=csv|quickbooks|xero&from=YYYY-MM-DD&to=YYYY-MM-DD)
+func (h *AccountingHandlers) ExportReport(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	from, to, ok := parseReportPeriod(c)
+	if !ok {
+		return
+	}
+
+	data, filename, err := h.accountingService.ExportReport(c.Param("id"), userID,
+		c.DefaultQuery("report", "journal"), c.DefaultQuery("format", models.AccountingExportCSV), from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+// GetFinancialPeriods lists the chama's closed and reopened financial periods
+func (h *AccountingHandlers) GetFinancialPeriods(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	periods, err := h.periodService.GetPeriods(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    periods,
+		"count":   len(periods),
+	})
+}
+
+// GetFinancialPeriod returns a period with its closing balances and closing entry
+func (h *AccountingHandlers) GetFinancialPeriod(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	period, err := h.periodService.GetPeriod(c.Param("id"), c.Param("periodId"), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    period,
+	})
+}
+
+// CloseFinancialPeriod closes a month or year and locks records dated inside it
+func (h *AccountingHandlers) CloseFinancialPeriod(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.CloseFinancialPeriodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	period, err := h.periodService.ClosePeriod(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    period,
+		"message": "Financial period closed successfully",
+	})
+}
+
+// ReopenFinancialPeriod unlocks a closed period, recording the reason in the audit log
+func (h *AccountingHandlers) ReopenFinancialPeriod(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.ReopenFinancialPeriodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	period, err := h.periodService.ReopenPeriod(c.Param("id"), c.Param("periodId"), userID, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    period,
+		"message": "Financial period reopened successfully",
+	})
+}
+
+// parseAccountingAsOf reads ?asOf=YYYY-MM-DD as the end of that day, defaulting to now
+func parseAccountingAsOf(c *gin.Context) (time.Time, bool) {
+	now := time.Now()
+	asOfStr := c.Query("asOf")
+	if asOfStr == "" {
+		return now, true
+	}
+	parsed, err := time.ParseInLocation("2006-01-02", asOfStr, now.Location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid asOf date, expected YYYY-MM-DD"})
+		return now, false
+	}
+	return parsed.AddDate(0, 0, 1), true
+}
@@ -0,0 +1,312 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AttendanceHandlers handles attendance fines, apologies and attendance reports
+type AttendanceHandlers struct {
+	attendanceService *services.AttendanceService
+}
+
+// NewAttendanceHandlers creates a new attendance handlers instance
+func NewAttendanceHandlers(db *sql.DB) *AttendanceHandlers {
+	return &AttendanceHandlers{
+		attendanceService: services.NewAttendanceService(db),
+	}
+}
+
+// GetFineRules retrieves a chama's attendance fine rules
+func (h *AttendanceHandlers) GetFineRules(c *gin.Context) {
+	userID := c.GetString("userID")
+	chamaID := c.Param("id")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	rules, err := h.attendanceService.GetFineRules(chamaID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": rules})
+}
+
+// UpdateFineRules configures a chama's attendance fine rules
+func (h *AttendanceHandlers) UpdateFineRules(c *gin.Context) {
+	userID := c.GetString("userID")
+	chamaID := c.Param("id")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	var req models.UpdateAttendanceFineRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	rules, err := h.attendanceService.UpdateFineRules(chamaID, userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rules,
+		"message": "Attendance fine rules updated successfully",
+	})
+}
+
+// SubmitApology submits an apology for missing a meeting
+func (h *AttendanceHandlers) SubmitApology(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	var req models.SubmitApologyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	apology, err := h.attendanceService.SubmitApology(meetingID, userID, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	message := "Apology submitted and accepted"
+	if apology.Status == models.ApologyStatusLate {
+		message = "Apology submitted after the deadline; officials may still accept it"
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    apology,
+		"message": message,
+	})
+}
+
+// GetMeetingApologies lists the apologies submitted for a meeting
+func (h *AttendanceHandlers) GetMeetingApologies(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	apologies, err := h.attendanceService.GetMeetingApologies(meetingID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    apologies,
+		"count":   len(apologies),
+	})
+}
+
+// ReviewApology accepts or rejects an apology
+func (h *AttendanceHandlers) ReviewApology(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+	apologyID := c.Param("apologyId")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	var req models.ReviewApologyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	if err := h.attendanceService.ReviewApology(meetingID, apologyID, userID, req.Status); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Apology " + string(req.Status),
+	})
+}
+
+// ApplyMeetingFines re-runs the attendance fine rules for an ended meeting
+func (h *AttendanceHandlers) ApplyMeetingFines(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	fines, err := h.attendanceService.ApplyMeetingFinesAsOfficial(meetingID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    fines,
+		"count":   len(fines),
+		"message": "Attendance fines applied",
+	})
+}
+
+// GetChamaFines lists member fines (?status=&memberId=)
+func (h *AttendanceHandlers) GetChamaFines(c *gin.Context) {
+	userID := c.GetString("userID")
+	chamaID := c.Param("id")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	fines, err := h.attendanceService.GetChamaFines(chamaID, userID, c.Query("memberId"), c.Query("status"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    fines,
+		"count":   len(fines),
+	})
+}
+
+// PayFine pays an outstanding fine from the member's wallet
+func (h *AttendanceHandlers) PayFine(c *gin.Context) {
+	userID := c.GetString("userID")
+	chamaID := c.Param("id")
+	fineID := c.Param("fineId")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	fine, err := h.attendanceService.PayFine(chamaID, fineID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    fine,
+		"message": "Fine paid successfully",
+	})
+}
+
+// WaiveFine waives an outstanding fine
+func (h *AttendanceHandlers) WaiveFine(c *gin.Context) {
+	userID := c.GetString("userID")
+	chamaID := c.Param("id")
+	fineID := c.Param("fineId")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	var req models.WaiveFineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	fine, err := h.attendanceService.WaiveFine(chamaID, fineID, userID, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    fine,
+		"message": "Fine waived successfully",
+	})
+}
+
+// GetAttendanceReport returns attendance rates per member (?from=YYYY-MM-DD&to=YYYY-MM-DD).
+// Defaults to the last 12 months.
+func (h *AttendanceHandlers) GetAttendanceReport(c *gin.Context) {
+	userID := c.GetString("userID")
+	chamaID := c.Param("id")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	to := time.Now()
+	from := to.AddDate(-1, 0, 0)
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "from date must be before to date"})
+		return
+	}
+
+	report, err := h.attendanceService.GetAttendanceReport(chamaID, userID, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": report})
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"database/sql"
+	"net/http"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandlers handles the tamper-evident audit log and its signed checkpoints
+type AuditHandlers struct {
+	auditService *services.AuditService
+}
+
+// NewAuditHandlers creates a new audit handlers instance that signs checkpoints
+// with the given key
+func NewAuditHandlers(db *sql.DB, signingKey ed25519.PrivateKey) *AuditHandlers {
+	return &AuditHandlers{
+		auditService: services.NewSigningAuditService(db, signingKey),
+	}
+}
+
+// GetChamaAuditLog lists a chama's audit entries, newest first (?action=&limit=&offset=)
+func (h *AuditHandlers) GetChamaAuditLog(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	limit, offset := shareMarketPagination(c)
+
+	entries, err := h.auditService.GetChamaEntries(c.Param("id"), userID, c.Query("action"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    entries,
+		"count":   len(entries),
+	})
+}
+
+// VerifyChamaAuditLog recomputes the chama's audit chain and reports the first break
+func (h *AuditHandlers) VerifyChamaAuditLog(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	verification, err := h.auditService.VerifyChamaChain(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    verification,
+	})
+}
+
+// GetChamaAuditCheckpoints lists the chama's signed checkpoints
+func (h *AuditHandlers) GetChamaAuditCheckpoints(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	limit, offset := shareMarketPagination(c)
+
+	checkpoints, err := h.auditService.GetChamaCheckpoints(c.Param("id"), userID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    checkpoints,
+		"count":   len(checkpoints),
+	})
+}
+
+// CreateChamaAuditCheckpoint signs the current head of the chama's audit chain
+func (h *AuditHandlers) CreateChamaAuditCheckpoint(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	checkpoint, err := h.auditService.CreateChamaCheckpoint(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    checkpoint,
+		"message": "Audit checkpoint created successfully",
+	})
+}
+
+// VerifyAuditCheckpoint checks a checkpoint a member kept against the live log
+func (h *AuditHandlers) VerifyAuditCheckpoint(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var checkpoint models.AuditCheckpoint
+	if err := c.ShouldBindJSON(&checkpoint); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	if chamaID := c.Param("id"); chamaID != "" && checkpoint.ChainID != chamaID {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Checkpoint does not belong to this chama",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    h.auditService.VerifyCheckpoint(&checkpoint),
+	})
+}
+
+// VerifyPlatformAuditLog verifies the platform-wide audit chain (admin only)
+func (h *AuditHandlers) VerifyPlatformAuditLog(c *gin.Context) {
+	if c.GetString("userRole") != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "Admin access required",
+		})
+		return
+	}
+
+	verification, err := h.auditService.VerifyChain(models.AuditPlatformChain)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    verification,
+	})
+}
+
+// GetAuditPublicKey returns the key members use to check checkpoint signatures offline
+func (h *AuditHandlers) GetAuditPublicKey(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"algorithm": "ed25519",
+			"publicKey": h.auditService.PublicKey(),
+		},
+	})
+}
@@ -0,0 +1,446 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"io"
+	"net/http"
+	"time"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBankStatementSize is the largest statement file accepted for import
+const maxBankStatementSize = 10 * 1024 * 1024
+
+// BankReconciliationHandlers handles chama bank accounts, statement imports and
+// reconciling statement lines against recorded transactions
+type BankReconciliationHandlers struct {
+	reconciliationService *services.BankReconciliationService
+}
+
+// NewBankReconciliationHandlers creates a new bank reconciliation handlers instance
+func NewBankReconciliationHandlers(db *sql.DB) *BankReconciliationHandlers {
+	return &BankReconciliationHandlers{
+		reconciliationService: services.NewBankReconciliationService(db),
+	}
+}
+
+// GetBankAccounts lists the chama's bank accounts
+func (h *BankReconciliationHandlers) GetBankAccounts(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	accounts, err := h.reconciliationService.GetBankAccounts(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    accounts,
+		"count":   len(accounts),
+	})
+}
+
+// CreateBankAccount registers a chama bank account for reconciliation
+func (h *BankReconciliationHandlers) CreateBankAccount(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.CreateBankAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	account, err := h.reconciliationService.CreateBankAccount(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    account,
+		"message": "Bank account added successfully",
+	})
+}
+
+// GetStatements lists the statements imported for an account
+func (h *BankReconciliationHandlers) GetStatements(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	statements, err := h.reconciliationService.GetStatements(c.Param("id"), c.Param("accountId"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    statements,
+		"count":   len(statements),
+	})
+}
+
+// ImportStatement uploads a CSV, OFX or CAMT.053 statement. The file is kept
+// privately alongside the imported lines.
+func (h *BankReconciliationHandlers) ImportStatement(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	if err := c.Request.ParseMultipartForm(maxBankStatementSize); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Failed to parse multipart form: " + err.Error(),
+		})
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "No file provided: " + err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBankStatementSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Failed to read file: " + err.Error(),
+		})
+		return
+	}
+
+	storageService, ok := getStorageService(c)
+	if !ok {
+		return
+	}
+
+	storedFile, err := storageService.Store(&services.StoreFileInput{
+		OwnerID:      userID,
+		FileName:     header.Filename,
+		Category:     models.FileCategoryBankStatement,
+		AccessScope:  models.FileAccessPrivate,
+		AllowedTypes: services.StorageBankStatementTypes,
+		MaxSize:      maxBankStatementSize,
+	}, bytes.NewReader(data))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	result, err := h.reconciliationService.ImportStatement(c.Param("id"), c.Param("accountId"), userID, header.Filename, data, &storedFile.ID)
+	if err != nil {
+		// Clean up uploaded file if the statement cannot be imported
+		storageService.Delete(storedFile.ID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    result,
+		"message": "Bank statement imported successfully",
+	})
+}
+
+// GetStatementLines lists an account's statement lines (?status=unmatched|matched|excluded)
+func (h *BankReconciliationHandlers) GetStatementLines(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	lines, err := h.reconciliationService.GetStatementLines(c.Param("id"), c.Param("accountId"), userID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    lines,
+		"count":   len(lines),
+	})
+}
+
+// AutoMatch re-runs automatic matching, e.g. after missing transactions are recorded
+func (h *BankReconciliationHandlers) AutoMatch(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	matched, err := h.reconciliationService.AutoMatch(c.Param("id"), c.Param("accountId"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"matched": matched},
+		"message": "Automatic matching completed",
+	})
+}
+
+// GetMatchCandidates suggests recorded transactions for a statement line
+func (h *BankReconciliationHandlers) GetMatchCandidates(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	candidates, err := h.reconciliationService.GetMatchCandidates(c.Param("id"), c.Param("accountId"), c.Param("lineId"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    candidates,
+		"count":   len(candidates),
+	})
+}
+
+// MatchLine matches a statement line to one or more recorded transactions
+func (h *BankReconciliationHandlers) MatchLine(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.MatchBankLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	line, err := h.reconciliationService.MatchLine(c.Param("id"), c.Param("accountId"), c.Param("lineId"), userID, req.TransactionIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    line,
+		"message": "Statement line matched",
+	})
+}
+
+// AssignTransaction records which bank account a chama transaction went through
+func (h *BankReconciliationHandlers) AssignTransaction(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	transaction, err := h.reconciliationService.AssignTransaction(c.Param("id"), c.Param("accountId"), c.Param("transactionId"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    transaction,
+		"message": "Transaction assigned to bank account",
+	})
+}
+
+// UnmatchLine undoes a match or exclusion
+func (h *BankReconciliationHandlers) UnmatchLine(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	line, err := h.reconciliationService.UnmatchLine(c.Param("id"), c.Param("accountId"), c.Param("lineId"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    line,
+		"message": "Statement line unmatched",
+	})
+}
+
+// ExcludeLine marks a statement line as explained without a recorded transaction
+func (h *BankReconciliationHandlers) ExcludeLine(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.ExcludeBankLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	line, err := h.reconciliationService.ExcludeLine(c.Param("id"), c.Param("accountId"), c.Param("lineId"), userID, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    line,
+		"message": "Statement line excluded",
+	})
+}
+
+// GetReconciliationReport compares the bank balance with the books
+// (?asOf=YYYY-MM-DD, defaulting to today)
+func (h *BankReconciliationHandlers) GetReconciliationReport(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	asOf := utils.NowEAT()
+	if asOfStr := c.Query("asOf"); asOfStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", asOfStr, utils.EATLocation)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid asOf date, expected YYYY-MM-DD",
+			})
+			return
+		}
+		asOf = parsed
+	}
+
+	report, err := h.reconciliationService.GetReconciliationReport(c.Param("id"), c.Param("accountId"), userID, asOf)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
@@ -570,19 +570,6 @@ func CreateChama(c *gin.Context) {
 	})
 }
 
-func generateMockMonthlyData(average float64) []float64 {
-	data := make([]float64, 12)
-	for i := 0; i < 12; i++ {
-		// Add some variation around the average
-		variation := (float64(i%3) - 1) * 500 // -500, 0, +500 variation
-		data[i] = average + variation
-		if data[i] < 0 {
-			data[i] = 0
-		}
-	}
-	return data
-}
-
 func getStringValue(s *string) string {
 	if s == nil {
 		return ""
@@ -707,7 +694,7 @@ func UpdateChama(c *gin.Context) {
 	}
 
 	// Update chama settings
-	err = chamaService.UpdateChamaSettings(chamaID, &req)
+	err = chamaService.UpdateChamaSettings(chamaID, &req, userID.(string))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -901,8 +888,6 @@ func GetChamaMembers(c *gin.Context) {
 			u.bio, u.occupation, u.created_at as user_created_at,
 			COALESCE(w.balance, 0) as savings_balance,
 			COALESCE(loan_balance.balance, 0) as loan_balance,
-			COALESCE(contrib_stats.monthly_average, 0) as monthly_average,
-			COALESCE(contrib_stats.consistency_rate, 0) as consistency_rate,
 			COALESCE(meeting_stats.meetings_attended, 0) as meetings_attended,
 			COALESCE(meeting_stats.total_meetings, 0) as total_meetings,
 			COALESCE(activity_stats.contributions_made, 0) as contributions_made,
@@ -919,17 +904,6 @@ func GetChamaMembers(c *gin.Context) {
 			WHERE chama_id = ?
 			GROUP BY borrower_id
 		) loan_balance ON u.id = loan_balance.borrower_id
-		LEFT JOIN (
-			SELECT
-				t.initiated_by,
-				AVG(t.amount) as monthly_average,
-				(COUNT(*) * 100.0 / 12) as consistency_rate,
-				COUNT(*) as contributions_made
-			FROM transactions t
-			WHERE t.type = 'contribution'
-			AND t.created_at >= datetime('now', '-12 months')
-			GROUP BY t.initiated_by
-		) contrib_stats ON u.id = contrib_stats.initiated_by
 		LEFT JOIN (
 			SELECT
 				t.initiated_by,
@@ -953,6 +927,14 @@ func GetChamaMembers(c *gin.Context) {
 		ORDER BY cm.joined_at ASC
 	`
 
+	// Contribution history, consistency and last activity come from the member analytics service
+	periodStart, periodEnd, _ := services.ResolveAnalyticsPeriod(12, "", "", time.Now())
+	analytics, err := services.NewMemberAnalyticsService(db).ChamaAnalyticsByMember(chamaID, periodStart, periodEnd)
+	if err != nil {
+		log.Printf("Failed to compute member analytics for chama %s: %v", chamaID, err)
+		analytics = map[string]*models.MemberContributionAnalytics{}
+	}
+
 	rows, err := db.Query(query, chamaID, chamaID, chamaID, chamaID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -972,7 +954,7 @@ func GetChamaMembers(c *gin.Context) {
 			id, chamaID, userID, role, firstName, lastName, email, phone, userStatus                        string
 			joinedAt, userCreatedAt                                                                         string
 			isActive, isEmailVerified, isPhoneVerified                                                      bool
-			totalContributions, rating, savingsBalance, loanBalance, monthlyAverage, consistencyRate        float64
+			totalContributions, rating, savingsBalance, loanBalance                                         float64
 			totalRatings, meetingsAttended, totalMeetings, contributionsMade, loansTaken, guarantorRequests int
 			avatar, lastContribution, businessType, county, town, bio, occupation                           *string
 		)
@@ -983,7 +965,7 @@ func GetChamaMembers(c *gin.Context) {
 			&firstName, &lastName, &email, &phone, &avatar, &userStatus,
 			&isEmailVerified, &isPhoneVerified, &businessType, &county, &town,
 			&bio, &occupation, &userCreatedAt,
-			&savingsBalance, &loanBalance, &monthlyAverage, &consistencyRate,
+			&savingsBalance, &loanBalance,
 			&meetingsAttended, &totalMeetings, &contributionsMade, &loansTaken, &guarantorRequests,
 		)
 		if err != nil {
@@ -999,10 +981,13 @@ func GetChamaMembers(c *gin.Context) {
 		// Determine online status (mock for now - would need real-time tracking)
 		isOnline := userStatus == "active" && (id == "user-1" || id == "user-3" || id == "user-4")
 
-		// Calculate last contribution amount (mock for now)
-		lastContributionAmount := 5000.0
-		if totalContributions > 0 {
-			lastContributionAmount = monthlyAverage
+		memberAnalytics, ok := analytics[userID]
+		if !ok {
+			memberAnalytics = &models.MemberContributionAnalytics{}
+		}
+		last12Months := make([]float64, 0, len(memberAnalytics.Months))
+		for _, month := range memberAnalytics.Months {
+			last12Months = append(last12Months, month.Amount)
 		}
 
 		// Build member object with real data
@@ -1015,7 +1000,7 @@ func GetChamaMembers(c *gin.Context) {
 			"status":                   userStatus,
 			"total_contributions":      totalContributions,
 			"last_contribution_date":   lastContribution,
-			"last_contribution_amount": lastContributionAmount,
+			"last_contribution_amount": memberAnalytics.LastContributionAmount,
 			"attendance_rate":          attendanceRate,
 			"loan_balance":             loanBalance,
 			"savings_balance":          savingsBalance,
@@ -1034,14 +1019,17 @@ func GetChamaMembers(c *gin.Context) {
 				"bio":        bio,
 				"occupation": occupation,
 				"created_at": userCreatedAt,
-				"last_seen":  joinedAt, // Mock - would need real tracking
+				"last_seen":  memberAnalytics.LastActivityAt,
 				"is_online":  isOnline,
 			},
 			"contributions_summary": map[string]interface{}{
-				"total_amount":     totalContributions,
-				"monthly_average":  monthlyAverage,
-				"consistency_rate": consistencyRate,
-				"last_12_months":   generateMockMonthlyData(monthlyAverage), // Mock historical data
+				"total_amount":       totalContributions,
+				"monthly_average":    memberAnalytics.MonthlyAverage,
+				"consistency_rate":   memberAnalytics.ConsistencyRate,
+				"current_streak":     memberAnalytics.CurrentStreak,
+				"rank":               memberAnalytics.Rank,
+				"projected_year_end": memberAnalytics.ProjectedYearEnd,
+				"last_12_months":     last12Months,
 			},
 			"activity_summary": map[string]interface{}{
 				"meetings_attended":  meetingsAttended,
@@ -1748,7 +1736,7 @@ func UpdateChamaMember(c *gin.Context) {
 
 	// Update member role if provided
 	if req.Role != "" {
-		err = chamaService.UpdateMemberRoleSimple(chamaID, memberID, req.Role)
+		err = chamaService.UpdateMemberRoleSimple(chamaID, memberID, req.Role, userID.(string))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"success": false,
@@ -2463,7 +2451,7 @@ func CreateIndividualDisbursement(c *gin.Context) {
 		Timestamp       string  `json:"timestamp" binding:"required"`
 		Status          string  `json:"status" binding:"required"`
 		TransactionID   string  `json:"transactionId" binding:"required"`
-		SecurityHash    string  `json:"securityHash" binding:"required"`
+		SecurityHash    string  `json:"securityHash"` // ignored, computed by the audit log
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -2496,7 +2484,38 @@ func CreateIndividualDisbursement(c *gin.Context) {
 	disburseID := fmt.Sprintf("DISB_%d", time.Now().Unix())
 	now := time.Now()
 
-	_, err := db.(*sql.DB).Exec(
+	if userID := c.GetString("userID"); userID != "" {
+		req.InitiatedByID = userID
+	}
+
+	tx, err := db.(*sql.DB).Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to start transaction",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	req.SecurityHash, err = recordAuditHash(tx, db.(*sql.DB), chamaID, req.InitiatedByID, models.AuditActionMoneyMovement,
+		"disbursement", disburseID, req.Amount, map[string]interface{}{
+			"memberId":      req.MemberID,
+			"type":          req.Type,
+			"category":      req.Category,
+			"fromAccount":   req.FromAccount,
+			"toAccount":     req.ToAccount,
+			"transactionId": req.TransactionID,
+		})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to record disbursement in audit log: " + err.Error(),
+		})
+		return
+	}
+
+	_, err = tx.Exec(
 		query,
 		disburseID, chamaID, req.Type, req.Category, req.MemberID, req.MemberName,
 		req.Amount, req.Purpose, req.PrivateNote, req.FromAccount, req.ToAccount,
@@ -2512,11 +2531,20 @@ func CreateIndividualDisbursement(c *gin.Context) {
 		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to commit transaction",
+		})
+		return
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"message": "Individual disbursement created successfully",
 		"data": gin.H{
-			"id": disburseID,
+			"id":           disburseID,
+			"securityHash": req.SecurityHash,
 		},
 	})
 }
@@ -2546,7 +2574,7 @@ func CreateBulkDisbursement(c *gin.Context) {
 		Timestamp        string                   `json:"timestamp" binding:"required"`
 		Status           string                   `json:"status" binding:"required"`
 		TransactionID    string                   `json:"transactionId" binding:"required"`
-		SecurityHash     string                   `json:"securityHash" binding:"required"`
+		SecurityHash     string                   `json:"securityHash"` // ignored, computed by the audit log
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -2582,6 +2610,27 @@ func CreateBulkDisbursement(c *gin.Context) {
 	bulkID := fmt.Sprintf("BULK_%d", time.Now().Unix())
 	now := time.Now()
 
+	if userID := c.GetString("userID"); userID != "" {
+		req.InitiatedByID = userID
+	}
+
+	req.SecurityHash, err = recordAuditHash(tx, db.(*sql.DB), chamaID, req.InitiatedByID, models.AuditActionMoneyMovement,
+		"bulk_disbursement", bulkID, req.TotalAmount, map[string]interface{}{
+			"type":             req.Type,
+			"category":         req.Category,
+			"dividendPerShare": req.DividendPerShare,
+			"recipients":       len(req.EligibleMembers),
+			"fromAccount":      req.FromAccount,
+			"transactionId":    req.TransactionID,
+		})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to record bulk disbursement in audit log: " + err.Error(),
+		})
+		return
+	}
+
 	bulkQuery := `
 		INSERT INTO bulk_disbursements (
 			id, chama_id, type, category, dividend_per_share, total_amount, description,
@@ -2653,7 +2702,8 @@ func CreateBulkDisbursement(c *gin.Context) {
 		"success": true,
 		"message": "Bulk disbursement created successfully",
 		"data": gin.H{
-			"id": bulkID,
+			"id":           bulkID,
+			"securityHash": req.SecurityHash,
 		},
 	})
 }
@@ -2684,7 +2734,7 @@ func CreateChamaShares(c *gin.Context) {
 		Timestamp         string  `json:"timestamp"`
 		Status            string  `json:"status"`
 		TransactionID     string  `json:"transactionId"`
-		SecurityHash      string  `json:"securityHash"`
+		SecurityHash      string  `json:"securityHash"` // ignored, computed by the audit log
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -2718,7 +2768,35 @@ func CreateChamaShares(c *gin.Context) {
 	offeringID := fmt.Sprintf("OFFER_%d", time.Now().Unix())
 	now := time.Now()
 
-	_, err := db.(*sql.DB).Exec(
+	if userID := c.GetString("userID"); userID != "" {
+		req.CreatedByID = userID
+	}
+
+	tx, err := db.(*sql.DB).Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to start transaction",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	req.SecurityHash, err = recordAuditHash(tx, db.(*sql.DB), chamaID, req.CreatedByID, models.AuditActionDeclaration,
+		"share_offering", offeringID, req.TotalValue, map[string]interface{}{
+			"shareType":     req.ShareType,
+			"totalShares":   req.TotalShares,
+			"pricePerShare": req.PricePerShare,
+		})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to record share offering in audit log: " + err.Error(),
+		})
+		return
+	}
+
+	_, err = tx.Exec(
 		query,
 		offeringID, chamaID, req.ShareType, req.TotalShares, req.PricePerShare,
 		req.MinimumPurchase, req.Description, req.EligibilityCriteria, req.ApprovalRequired,
@@ -2734,11 +2812,20 @@ func CreateChamaShares(c *gin.Context) {
 		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to commit transaction",
+		})
+		return
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"message": "Share offering created successfully",
 		"data": gin.H{
-			"id": offeringID,
+			"id":           offeringID,
+			"securityHash": req.SecurityHash,
 		},
 	})
 }
@@ -2768,7 +2855,7 @@ func DeclareChamaDividends(c *gin.Context) {
 		Timestamp          string  `json:"timestamp"`
 		Status             string  `json:"status"`
 		TransactionID      string  `json:"transactionId"`
-		SecurityHash       string  `json:"securityHash"`
+		SecurityHash       string  `json:"securityHash"` // ignored, computed by the audit log
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -2801,7 +2888,35 @@ func DeclareChamaDividends(c *gin.Context) {
 	declarationID := fmt.Sprintf("DECL_%d", time.Now().Unix())
 	now := time.Now()
 
-	_, err := db.(*sql.DB).Exec(
+	if userID := c.GetString("userID"); userID != "" {
+		req.CreatedByID = userID
+	}
+
+	tx, err := db.(*sql.DB).Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to start transaction",
+		})
+		return
+	}
+	defer tx.Rollback()
+
+	req.SecurityHash, err = recordAuditHash(tx, db.(*sql.DB), chamaID, req.CreatedByID, models.AuditActionDeclaration,
+		"dividend_declaration", declarationID, req.TotalAmount, map[string]interface{}{
+			"dividendType":     req.DividendType,
+			"dividendPerShare": req.DividendPerShare,
+			"paymentDate":      req.PaymentDate,
+		})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to record dividend declaration in audit log: " + err.Error(),
+		})
+		return
+	}
+
+	_, err = tx.Exec(
 		query,
 		declarationID, chamaID, req.DividendType, req.TotalAmount, req.DividendPerShare,
 		req.PaymentDate, req.Description, req.EligibilityCriteria, req.ApprovalRequired,
@@ -2817,11 +2932,40 @@ func DeclareChamaDividends(c *gin.Context) {
 		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to commit transaction",
+		})
+		return
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"message": "Dividend declaration created successfully",
 		"data": gin.H{
-			"id": declarationID,
+			"id":           declarationID,
+			"securityHash": req.SecurityHash,
 		},
 	})
 }
+
+// recordAuditHash appends an entry to the chama's audit log inside tx and returns
+// its hash, which is stored as the record's security hash instead of a client value
+func recordAuditHash(tx *sql.Tx, db *sql.DB, chamaID, actorID string, action models.AuditAction, entityType, entityID string, amount float64, details map[string]interface{}) (string, error) {
+	entry := &models.AuditEntry{
+		ChainID:    chamaID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Amount:     &amount,
+		Details:    details,
+	}
+	if actorID != "" {
+		entry.ActorID = &actorID
+	}
+	if err := services.NewAuditService(db).Record(tx, entry); err != nil {
+		return "", err
+	}
+	return entry.EntryHash, nil
+}
@@ -4,18 +4,15 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
+	"vaultke-backend/internal/models"
 	"vaultke-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 // Helper functions for safe metadata extraction
@@ -1010,63 +1007,67 @@ func handleFileMessage(c *gin.Context, userID, roomID string) {
 	}
 	defer file.Close()
 
-	// Create uploads directory if it doesn't exist
-	uploadsDir := "uploads/chat"
-	if err := os.MkdirAll(uploadsDir, 0o755); err != nil {
+	// Get database connection
+	db, exists := c.Get("db")
+	if !exists {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
-			"error":   "Failed to create uploads directory: " + err.Error(),
+			"error":   "Database connection not available",
 		})
 		return
 	}
+	storageService, ok := getStorageService(c)
+	if !ok {
+		return
+	}
 
-	// Generate unique filename
-	fileExt := filepath.Ext(header.Filename)
-	fileName := fmt.Sprintf("%s_%d%s", uuid.New().String(), time.Now().Unix(), fileExt)
-	filePath := filepath.Join(uploadsDir, fileName)
+	// Use the proper chat service
+	chatService := services.NewChatService(db.(*sql.DB))
 
-	// Save file
-	dst, err := os.Create(filePath)
+	// Attachments are only readable by the room's members, so only members may add them
+	isMember, err := chatService.IsUserMemberOfRoom(roomID, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
-			"error":   "Failed to create file: " + err.Error(),
+			"error":   err.Error(),
 		})
 		return
 	}
-	defer dst.Close()
-
-	if _, err := io.Copy(dst, file); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+	if !isMember {
+		c.JSON(http.StatusForbidden, gin.H{
 			"success": false,
-			"error":   "Failed to save file: " + err.Error(),
+			"error":   "You are not a member of this chat room",
 		})
 		return
 	}
 
-	// Create file URL with full base URL for frontend access
-	fileURL := fmt.Sprintf("https://gitrepoa-1.onrender.com/uploads/chat/%s", fileName)
-
-	// Add file URL to metadata
-	metadata["fileUrl"] = fileURL
-	metadata["fileName"] = header.Filename
-	metadata["fileSize"] = header.Size
-
-	// Get database connection
-	db, exists := c.Get("db")
-	if !exists {
-		c.JSON(http.StatusInternalServerError, gin.H{
+	storedFile, err := storageService.Store(&services.StoreFileInput{
+		OwnerID:      userID,
+		FileName:     header.Filename,
+		Category:     models.FileCategoryChatAttachment,
+		AccessScope:  models.FileAccessChatRoom,
+		ScopeID:      roomID,
+		AllowedTypes: services.StorageDocumentTypes,
+		MaxSize:      10 * 1024 * 1024,
+	}, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
-			"error":   "Database connection not available",
+			"error":   err.Error(),
 		})
 		return
 	}
 
+	// Clients exchange the file URL for a signed download URL
+	metadata["fileId"] = storedFile.ID
+	metadata["fileUrl"] = services.StoredFileURL(storedFile.ID)
+	metadata["fileName"] = header.Filename
+	metadata["fileSize"] = storedFile.Size
+	metadata["fileType"] = storedFile.ContentType
+
 	// Get WebSocket service from context
 	wsService, wsExists := c.Get("wsService")
 
-	// Use the proper chat service
-	chatService := services.NewChatService(db.(*sql.DB))
 
 	// Convert type string to MessageType
 	var msgType services.MessageType
@@ -1083,7 +1084,7 @@ func handleFileMessage(c *gin.Context, userID, roomID string) {
 	message, err := chatService.SendMessage(roomID, userID, msgType, content, metadata, nil)
 	if err != nil {
 		// Clean up uploaded file on error
-		os.Remove(filePath)
+		storageService.Delete(storedFile.ID)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Failed to send message: " + err.Error(),
@@ -7,6 +7,9 @@ import (
 	"net/http"
 	"time"
 
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -291,6 +294,27 @@ func MakeContribution(c *gin.Context) {
 		}
 	}
 
+	// Contributions paid from the member's wallet are screened for fraud before any
+	// money moves
+	fraudService := services.NewFraudService(db.(*sql.DB))
+	var evaluation *models.FraudEvaluation
+	if req.PaymentMethod == "wallet" {
+		var screenErr error
+		evaluation, screenErr = fraudService.Screen(&models.FraudCheck{
+			UserID:          userID.(string),
+			TransactionType: models.TransactionTypeContribution,
+			Amount:          req.Amount,
+			RecipientID:     req.ChamaID,
+		})
+		if screenErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   screenErr.Error(),
+			})
+			return
+		}
+	}
+
 	// Start transaction
 	tx, err := db.(*sql.DB).Begin()
 	if err != nil {
@@ -348,6 +372,15 @@ func MakeContribution(c *gin.Context) {
 			return
 		}
 
+		// Count the contribution against the member's transaction limits
+		if err := services.NewFeeService(db.(*sql.DB)).ReserveLimit(tx, userID.(string), "", req.Amount); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+
 		// Deduct from personal wallet
 		result, err := tx.Exec(`
 			UPDATE wallets
@@ -521,6 +554,23 @@ func MakeContribution(c *gin.Context) {
 		return
 	}
 
+	if err := services.NewAuditService(db.(*sql.DB)).RecordTransaction(tx, req.ChamaID, transactionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to record transaction: " + err.Error(),
+		})
+		return
+	}
+	if evaluation != nil {
+		if err := fraudService.Record(tx, evaluation, transactionID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
 	fmt.Printf("✅ Transaction recorded successfully: %s\n", transactionID)
 
 	// Update member's total contributions only for completed transactions
@@ -571,6 +621,7 @@ func MakeContribution(c *gin.Context) {
 	}
 
 	fmt.Printf("✅ Transaction committed successfully\n")
+	services.InvalidateMemberAnalytics(req.ChamaID)
 
 	// For merry-go-round contributions, automatically check and advance the round
 	if req.Type == "merry-go-round" && transactionStatus == "completed" {
@@ -5,6 +5,9 @@ import (
 	"net/http"
 	"strconv"
 
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -254,7 +257,7 @@ func (h *DisbursementHandlers) ProcessDisbursementBatch(c *gin.Context) {
 
 	// For now, just mark as processed
 	query := `UPDATE disbursement_batches SET status = 'completed', processed_date = CURRENT_TIMESTAMP WHERE id = ? AND chama_id = ?`
-	_, err := h.db.Exec(query, batchID, chamaID)
+	err := h.updateBatchWithAudit(query, []interface{}{batchID, chamaID}, chamaID, batchID, userID, "completed")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -285,7 +288,7 @@ func (h *DisbursementHandlers) ApproveDisbursementBatch(c *gin.Context) {
 
 	// Update batch status to approved
 	query := `UPDATE disbursement_batches SET status = 'approved', approved_by = ? WHERE id = ? AND chama_id = ?`
-	_, err := h.db.Exec(query, userID, batchID, chamaID)
+	err := h.updateBatchWithAudit(query, []interface{}{userID, batchID, chamaID}, chamaID, batchID, userID, "approved")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -299,3 +302,53 @@ func (h *DisbursementHandlers) ApproveDisbursementBatch(c *gin.Context) {
 		"message": "Disbursement batch approved successfully",
 	})
 }
+
+// updateBatchWithAudit applies a batch status change and records it in the chama's audit log
+func (h *DisbursementHandlers) updateBatchWithAudit(query string, args []interface{}, chamaID, batchID, userID, status string) error {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	var totalAmount float64
+	var batchType string
+	err = tx.QueryRow("SELECT total_amount, batch_type FROM disbursement_batches WHERE id = ?", batchID).Scan(&totalAmount, &batchType)
+	if err != nil {
+		return err
+	}
+
+	action := models.AuditActionApproval
+	if status == "completed" {
+		action = models.AuditActionMoneyMovement
+	}
+	err = services.NewAuditService(h.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     action,
+		EntityType: "disbursement_batch",
+		EntityID:   batchID,
+		Amount:     &totalAmount,
+		Details: map[string]interface{}{
+			"batchType": batchType,
+			"status":    status,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,349 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DisputeHandlers handles transaction disputes, their evidence and their resolution
+type DisputeHandlers struct {
+	disputeService *services.DisputeService
+}
+
+// NewDisputeHandlers creates a new dispute handlers instance
+func NewDisputeHandlers(db *sql.DB) *DisputeHandlers {
+	return &DisputeHandlers{
+		disputeService: services.NewDisputeService(db),
+	}
+}
+
+// OpenDispute disputes a transfer the user made to another member
+func (h *DisputeHandlers) OpenDispute(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.OpenDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	dispute, err := h.disputeService.OpenDispute(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    dispute,
+		"message": "Dispute opened. The recipient has been asked to respond.",
+	})
+}
+
+// GetMyDisputes lists disputes the user raised or that were raised against them
+func (h *DisputeHandlers) GetMyDisputes(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	limit, offset := shareMarketPagination(c)
+	disputes, err := h.disputeService.GetMyDisputes(userID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    disputes,
+		"count":   len(disputes),
+	})
+}
+
+// GetDispute returns a dispute and its evidence to either party or an admin
+func (h *DisputeHandlers) GetDispute(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	dispute, err := h.disputeService.GetDispute(c.Param("id"), userID, c.GetString("userRole") == "admin")
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    dispute,
+	})
+}
+
+// UploadDisputeEvidence attaches a screenshot, receipt or other file to an active
+// dispute. The file goes in the "file" field with an optional "note".
+func (h *DisputeHandlers) UploadDisputeEvidence(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "No file provided: " + err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	if header.Size > 10*1024*1024 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "File too large. Maximum size is 10MB",
+		})
+		return
+	}
+
+	note := strings.TrimSpace(c.PostForm("note"))
+	if len(note) > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Note must be at most 1000 characters",
+		})
+		return
+	}
+
+	storageService, ok := getStorageService(c)
+	if !ok {
+		return
+	}
+
+	// Evidence is kept private; GetDisputeEvidence decides which parties may read it
+	storedFile, err := storageService.Store(&services.StoreFileInput{
+		OwnerID:      userID,
+		FileName:     header.Filename,
+		Category:     models.FileCategoryDisputeEvidence,
+		AccessScope:  models.FileAccessPrivate,
+		AllowedTypes: services.StorageEvidenceTypes,
+		MaxSize:      10 * 1024 * 1024,
+	}, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	var notePtr *string
+	if note != "" {
+		notePtr = &note
+	}
+	evidence, err := h.disputeService.AddEvidence(c.Param("id"), userID, c.GetString("userRole") == "admin", &models.DisputeEvidence{
+		FileName: header.Filename,
+		FileID:   &storedFile.ID,
+		FileSize: storedFile.Size,
+		FileType: storedFile.ContentType,
+		Note:     notePtr,
+	})
+	if err != nil {
+		// Clean up uploaded file if the evidence cannot be attached
+		storageService.Delete(storedFile.ID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    evidence,
+		"message": "Evidence uploaded successfully",
+	})
+}
+
+// GetDisputeEvidence streams an evidence file to either party or an admin
+func (h *DisputeHandlers) GetDisputeEvidence(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	evidence, err := h.disputeService.GetEvidence(c.Param("id"), userID, c.GetString("userRole") == "admin")
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	serveProtectedFile(c, evidence.FileID, evidence.FilePath, evidence.FileType)
+}
+
+// RespondToDispute lets the recipient accept a dispute, refunding it straight away, or
+// contest it for an admin to decide
+func (h *DisputeHandlers) RespondToDispute(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.RespondDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	dispute, err := h.disputeService.Respond(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	message := "Dispute contested. An admin will review it."
+	if req.Accept {
+		message = "Dispute accepted and refunded"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    dispute,
+		"message": message,
+	})
+}
+
+// WithdrawDispute drops a dispute the user raised and releases any held funds
+func (h *DisputeHandlers) WithdrawDispute(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	dispute, err := h.disputeService.Withdraw(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    dispute,
+		"message": "Dispute withdrawn",
+	})
+}
+
+// GetDisputeQueue lists disputes waiting for an admin, oldest first (?status=, admin only)
+func (h *DisputeHandlers) GetDisputeQueue(c *gin.Context) {
+	limit, offset := shareMarketPagination(c)
+	disputes, err := h.disputeService.GetDisputeQueue(c.Query("status"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    disputes,
+		"count":   len(disputes),
+	})
+}
+
+// ResolveDispute settles a dispute with a full, partial or no refund (admin only)
+func (h *DisputeHandlers) ResolveDispute(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.ResolveDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	dispute, err := h.disputeService.Resolve(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    dispute,
+		"message": "Dispute resolved",
+	})
+}
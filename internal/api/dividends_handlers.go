@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"net/http"
 	"strconv"
+	"time"
 
 	"vaultke-backend/internal/models"
 	"vaultke-backend/internal/services"
@@ -218,6 +219,188 @@ func (h *DividendsHandlers) ApproveDividend(c *gin.Context) {
 	})
 }
 
+// PreviewDividend shows each member's computed payout under a declaration
+func (h *DividendsHandlers) PreviewDividend(c *gin.Context) {
+	userID := c.GetString("userID")
+	chamaID := c.Param("id")
+	declarationID := c.Param("declarationId")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.DividendResponse{
+			Success: false,
+			Error:   "User not authenticated",
+		})
+		return
+	}
+
+	preview, err := h.dividendsService.PreviewDividend(chamaID, declarationID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.DividendResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DividendResponse{
+		Success: true,
+		Data:    preview,
+	})
+}
+
+// GetDividendTaxReport lists the withholding tax deducted from dividends with a
+// record date in the range (?from=&to=, YYYY-MM-DD, defaulting to this year)
+func (h *DividendsHandlers) GetDividendTaxReport(c *gin.Context) {
+	userID := c.GetString("userID")
+	chamaID := c.Param("id")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.DividendResponse{
+			Success: false,
+			Error:   "User not authenticated",
+		})
+		return
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+	to := from.AddDate(1, 0, 0)
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", fromStr, now.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.DividendResponse{Success: false, Error: "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", toStr, now.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.DividendResponse{Success: false, Error: "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, models.DividendResponse{Success: false, Error: "from date must be before to date"})
+		return
+	}
+
+	report, err := h.dividendsService.GetWithholdingTaxReport(chamaID, userID, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.DividendResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DividendResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// GetReinvestmentElection returns the authenticated member's dividend reinvestment election
+func (h *DividendsHandlers) GetReinvestmentElection(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.DividendResponse{
+			Success: false,
+			Error:   "User not authenticated",
+		})
+		return
+	}
+
+	election, err := h.dividendsService.GetReinvestmentElection(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.DividendResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DividendResponse{
+		Success: true,
+		Data:    election,
+	})
+}
+
+// SetReinvestmentElection sets whether the authenticated member's dividends are paid
+// in cash, reinvested in shares, or split between the two
+func (h *DividendsHandlers) SetReinvestmentElection(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.DividendResponse{
+			Success: false,
+			Error:   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.SetDividendReinvestmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.DividendResponse{
+			Success: false,
+			Error:   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	election, err := h.dividendsService.SetReinvestmentElection(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.DividendResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DividendResponse{
+		Success: true,
+		Data:    election,
+		Message: "Dividend reinvestment election saved",
+	})
+}
+
+// GetReinvestmentStatement returns the authenticated member's annual statement of
+// dividends paid and reinvested (?year=, defaulting to this year)
+func (h *DividendsHandlers) GetReinvestmentStatement(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.DividendResponse{
+			Success: false,
+			Error:   "User not authenticated",
+		})
+		return
+	}
+
+	year := time.Now().Year()
+	if yearStr := c.Query("year"); yearStr != "" {
+		parsed, err := strconv.Atoi(yearStr)
+		if err != nil || parsed < 2000 || parsed > 9999 {
+			c.JSON(http.StatusBadRequest, models.DividendResponse{Success: false, Error: "Invalid year"})
+			return
+		}
+		year = parsed
+	}
+
+	statement, err := h.dividendsService.GetReinvestmentStatement(c.Param("id"), userID, year)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.DividendResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.DividendResponse{
+		Success: true,
+		Data:    statement,
+	})
+}
+
 // ProcessDividendPayments processes dividend payments for a declaration
 func (h *DividendsHandlers) ProcessDividendPayments(c *gin.Context) {
 	userID := c.GetString("userID")
@@ -0,0 +1,530 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExpenseHandlers handles chama expense claims, their receipts and the annual budget
+type ExpenseHandlers struct {
+	expenseService *services.ExpenseService
+}
+
+// NewExpenseHandlers creates a new expense handlers instance
+func NewExpenseHandlers(db *sql.DB) *ExpenseHandlers {
+	return &ExpenseHandlers{
+		expenseService: services.NewExpenseService(db),
+	}
+}
+
+// GetExpenses lists a chama's expenses (?status=&year=)
+func (h *ExpenseHandlers) GetExpenses(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	year := 0
+	if yearStr := c.Query("year"); yearStr != "" {
+		parsed, err := strconv.Atoi(yearStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid year",
+			})
+			return
+		}
+		year = parsed
+	}
+
+	expenses, err := h.expenseService.GetExpenses(c.Param("id"), userID, c.Query("status"), year)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    expenses,
+		"count":   len(expenses),
+	})
+}
+
+// SubmitExpense submits an expense claim for approval
+func (h *ExpenseHandlers) SubmitExpense(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.SubmitExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	expense, err := h.expenseService.SubmitExpense(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    expense,
+		"message": "Expense claim submitted successfully",
+	})
+}
+
+// GetExpense returns an expense with its approval trail
+func (h *ExpenseHandlers) GetExpense(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	expense, err := h.expenseService.GetExpense(c.Param("id"), c.Param("expenseId"), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    expense,
+	})
+}
+
+// UploadReceipt attaches a receipt image or PDF to an expense claim. Receipts are
+// readable by the chama's members, like the claim itself.
+func (h *ExpenseHandlers) UploadReceipt(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Failed to parse multipart form: " + err.Error(),
+		})
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "No file provided: " + err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	storageService, ok := getStorageService(c)
+	if !ok {
+		return
+	}
+
+	storedFile, err := storageService.Store(&services.StoreFileInput{
+		OwnerID:      userID,
+		FileName:     header.Filename,
+		Category:     models.FileCategoryExpenseReceipt,
+		AccessScope:  models.FileAccessChama,
+		ScopeID:      c.Param("id"),
+		AllowedTypes: append([]string{"application/pdf"}, services.StorageImageTypes...),
+		MaxSize:      10 * 1024 * 1024,
+	}, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	expense, err := h.expenseService.AttachReceipt(c.Param("id"), c.Param("expenseId"), userID, storedFile.ID)
+	if err != nil {
+		// Clean up uploaded file if the receipt cannot be attached
+		storageService.Delete(storedFile.ID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"data":       expense,
+		"receiptUrl": services.StoredFileURL(storedFile.ID),
+		"message":    "Receipt uploaded successfully",
+	})
+}
+
+// ApproveExpense records an official's approval of a claim
+func (h *ExpenseHandlers) ApproveExpense(c *gin.Context) {
+	h.reviewExpense(c, h.expenseService.ApproveExpense, "Expense claim approved")
+}
+
+// RejectExpense rejects a claim; the comment is the reason and is required
+func (h *ExpenseHandlers) RejectExpense(c *gin.Context) {
+	h.reviewExpense(c, h.expenseService.RejectExpense, "Expense claim rejected")
+}
+
+func (h *ExpenseHandlers) reviewExpense(c *gin.Context, review func(chamaID, expenseID, userID string, comment *string) (*models.Expense, error), message string) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.ReviewExpenseRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid request data: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	expense, err := review(c.Param("id"), c.Param("expenseId"), userID, req.Comment)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    expense,
+		"message": message,
+	})
+}
+
+// CancelExpense lets the claimant withdraw an unpaid claim
+func (h *ExpenseHandlers) CancelExpense(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	expense, err := h.expenseService.CancelExpense(c.Param("id"), c.Param("expenseId"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    expense,
+		"message": "Expense claim cancelled",
+	})
+}
+
+// PayExpense pays an approved claim from the chama wallet
+func (h *ExpenseHandlers) PayExpense(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	expense, err := h.expenseService.PayExpense(c.Param("id"), c.Param("expenseId"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    expense,
+		"message": "Expense paid successfully",
+	})
+}
+
+// GetBudget lists the budget lines for a year (?year=, defaulting to this year)
+func (h *ExpenseHandlers) GetBudget(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	year, ok := budgetYear(c)
+	if !ok {
+		return
+	}
+
+	lines, err := h.expenseService.GetBudgetLines(c.Param("id"), userID, year)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    lines,
+	})
+}
+
+// SetBudgetLine creates or replaces the budget for a category in a year
+func (h *ExpenseHandlers) SetBudgetLine(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.SetBudgetLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	line, err := h.expenseService.SetBudgetLine(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    line,
+	})
+}
+
+// DeleteBudgetLine removes a budget line
+func (h *ExpenseHandlers) DeleteBudgetLine(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.expenseService.DeleteBudgetLine(c.Param("id"), c.Param("lineId"), userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Budget line deleted",
+	})
+}
+
+// GetBudgetReport compares a year's budget with actual spending (?year=, defaulting
+// to this year)
+func (h *ExpenseHandlers) GetBudgetReport(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	year, ok := budgetYear(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.expenseService.GetBudgetReport(c.Param("id"), userID, year)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// GetApprovalRules lists the chama's expense approval rules
+func (h *ExpenseHandlers) GetApprovalRules(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	rules, err := h.expenseService.GetApprovalRules(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rules,
+		"defaults": gin.H{
+			"approvalsRequired": models.DefaultExpenseApprovalsRequired,
+			"receiptRequired":   models.DefaultExpenseReceiptRequired,
+		},
+	})
+}
+
+// SetApprovalRule creates or replaces the approval rule for a minimum amount
+func (h *ExpenseHandlers) SetApprovalRule(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.SetExpenseApprovalRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.expenseService.SetApprovalRule(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rule,
+	})
+}
+
+// DeleteApprovalRule removes an expense approval rule
+func (h *ExpenseHandlers) DeleteApprovalRule(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.expenseService.DeleteApprovalRule(c.Param("id"), c.Param("ruleId"), userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Approval rule deleted",
+	})
+}
+
+// budgetYear reads ?year=, defaulting to the current year. It writes the error
+// response itself when the year is invalid.
+func budgetYear(c *gin.Context) (int, bool) {
+	yearStr := c.Query("year")
+	if yearStr == "" {
+		return time.Now().Year(), true
+	}
+	year, err := strconv.Atoi(yearStr)
+	if err != nil || year < 2000 || year > 2100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid year",
+		})
+		return 0, false
+	}
+	return year, true
+}
@@ -0,0 +1,309 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeeHandlers handles the fee schedule and per-tier transaction limits
+type FeeHandlers struct {
+	feeService *services.FeeService
+}
+
+// NewFeeHandlers creates a new fee handlers instance
+func NewFeeHandlers(db *sql.DB) *FeeHandlers {
+	return &FeeHandlers{
+		feeService: services.NewFeeService(db),
+	}
+}
+
+// GetFeeQuote prices a transaction (?type=&method=&amount=) and checks it against the
+// user's limits before they confirm it
+func (h *FeeHandlers) GetFeeQuote(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	amount, err := strconv.ParseFloat(c.Query("amount"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "A valid amount is required",
+		})
+		return
+	}
+
+	quote, err := h.feeService.QuoteFee(userID, models.TransactionType(c.Query("type")), models.PaymentMethod(c.Query("method")), amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    quote,
+	})
+}
+
+// GetFeeRules lists the active fee schedule; admins can add ?includeInactive=true
+func (h *FeeHandlers) GetFeeRules(c *gin.Context) {
+	includeInactive := c.Query("includeInactive") == "true" && c.GetString("userRole") == "admin"
+
+	rules, err := h.feeService.GetFeeRules(includeInactive)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rules,
+		"count":   len(rules),
+	})
+}
+
+// CreateFeeRule adds a rule to the fee schedule (admin only)
+func (h *FeeHandlers) CreateFeeRule(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.FeeRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.feeService.CreateFeeRule(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    rule,
+		"message": "Fee rule created successfully",
+	})
+}
+
+// UpdateFeeRule replaces a fee rule's pricing (admin only)
+func (h *FeeHandlers) UpdateFeeRule(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.FeeRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.feeService.UpdateFeeRule(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rule,
+		"message": "Fee rule updated successfully",
+	})
+}
+
+// DeactivateFeeRule takes a rule out of the fee schedule (admin only)
+func (h *FeeHandlers) DeactivateFeeRule(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	if err := h.feeService.DeactivateFeeRule(c.Param("id"), userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Fee rule deactivated successfully",
+	})
+}
+
+// GetLimitTiers lists the transaction limits for each KYC tier
+func (h *FeeHandlers) GetLimitTiers(c *gin.Context) {
+	tiers, err := h.feeService.GetLimitTiers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    tiers,
+		"count":   len(tiers),
+	})
+}
+
+// GetMyLimits shows how much of the user's daily and monthly limits is used
+func (h *FeeHandlers) GetMyLimits(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	limits, err := h.feeService.GetLimitStatus(userID, 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    limits,
+	})
+}
+
+// UpdateLimitTier sets the transaction limits for a KYC tier (admin only)
+func (h *FeeHandlers) UpdateLimitTier(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	tier, err := strconv.Atoi(c.Param("tier"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid tier",
+		})
+		return
+	}
+
+	var req models.UpdateLimitTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	limits, err := h.feeService.UpdateLimitTier(tier, userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    limits,
+		"message": "Transaction limits updated successfully",
+	})
+}
+
+// GetFeeRevenue totals the fees collected (?from=YYYY-MM-DD&to=YYYY-MM-DD, admin only).
+// Defaults to the current month.
+func (h *FeeHandlers) GetFeeRevenue(c *gin.Context) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	to := now
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "from date must be before to date"})
+		return
+	}
+
+	summary, err := h.feeService.GetFeeRevenue(from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    summary,
+	})
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FileHandlers hands out signed download URLs for stored files and serves them
+type FileHandlers struct {
+	storageService *services.StorageService
+}
+
+// NewFileHandlers creates a new file handlers instance
+func NewFileHandlers(storageService *services.StorageService) *FileHandlers {
+	return &FileHandlers{
+		storageService: storageService,
+	}
+}
+
+// GetFileURL checks the user may read a file and returns a short-lived download URL
+func (h *FileHandlers) GetFileURL(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	file, err := h.storageService.Authorize(c.Param("id"), userID, c.GetString("userRole") == "admin")
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    h.storageService.SignURL(file),
+	})
+}
+
+// DownloadFile streams a file to anyone holding a valid signed URL. It is not behind
+// the auth middleware so the URL can be used directly by image views and browsers.
+func (h *FileHandlers) DownloadFile(c *gin.Context) {
+	file, err := h.storageService.VerifyURL(c.Param("id"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	reader, err := h.storageService.Open(file)
+	if err != nil {
+		log.Printf("Failed to open stored file %s: %v", file.ID, err)
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "File not found",
+		})
+		return
+	}
+	defer reader.Close()
+
+	disposition := "attachment"
+	if strings.HasPrefix(file.ContentType, "image/") || file.ContentType == "application/pdf" {
+		disposition = "inline"
+	}
+	c.DataFromReader(http.StatusOK, file.Size, file.ContentType, reader, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType(disposition, map[string]string{"filename": file.FileName}),
+		"Cache-Control":          "private, no-store",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// getStorageService returns the storage service injected by the storage middleware
+func getStorageService(c *gin.Context) (*services.StorageService, bool) {
+	storageService, exists := c.Get("storageService")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "File storage not available",
+		})
+		return nil, false
+	}
+	return storageService.(*services.StorageService), true
+}
+
+// serveProtectedFile streams a file its handler has already authorized, such as a KYC
+// document or dispute evidence. Files saved before the storage service that have not
+// been imported yet are still read from their local path.
+func serveProtectedFile(c *gin.Context, fileID *string, filePath, contentType string) {
+	c.Header("Cache-Control", "no-store")
+	if fileID == nil {
+		c.Header("Content-Type", contentType)
+		c.File(filePath)
+		return
+	}
+
+	storageService, ok := getStorageService(c)
+	if !ok {
+		return
+	}
+	file, reader, err := storageService.OpenChecked(*fileID)
+	if err != nil {
+		log.Printf("Failed to open stored file %s: %v", *fileID, err)
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "File not found",
+		})
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, file.Size, file.ContentType, reader, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("inline", map[string]string{"filename": file.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"time"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FraudHandlers handles the fraud review queue and rule settings. Every route is admin only.
+type FraudHandlers struct {
+	fraudService *services.FraudService
+}
+
+// NewFraudHandlers creates a new fraud handlers instance
+func NewFraudHandlers(db *sql.DB) *FraudHandlers {
+	return &FraudHandlers{
+		fraudService: services.NewFraudService(db),
+	}
+}
+
+// GetFraudReviewQueue lists held transactions, oldest first (?status= for other outcomes)
+func (h *FraudHandlers) GetFraudReviewQueue(c *gin.Context) {
+	limit, offset := shareMarketPagination(c)
+	evaluations, err := h.fraudService.GetReviewQueue(c.Query("status"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    evaluations,
+		"count":   len(evaluations),
+	})
+}
+
+// GetFraudEvaluation returns one evaluation with the rules that matched
+func (h *FraudHandlers) GetFraudEvaluation(c *gin.Context) {
+	evaluation, err := h.fraudService.GetEvaluation(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    evaluation,
+	})
+}
+
+// ReleaseFraudEvaluation lets a held transaction go ahead
+func (h *FraudHandlers) ReleaseFraudEvaluation(c *gin.Context) {
+	h.reviewFraudEvaluation(c, true)
+}
+
+// RejectFraudEvaluation declines a held transaction; notes explaining why are required
+func (h *FraudHandlers) RejectFraudEvaluation(c *gin.Context) {
+	h.reviewFraudEvaluation(c, false)
+}
+
+func (h *FraudHandlers) reviewFraudEvaluation(c *gin.Context, release bool) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.FraudReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	var evaluation *models.FraudEvaluation
+	var err error
+	message := "Transaction released"
+	if release {
+		evaluation, err = h.fraudService.ReleaseEvaluation(c.Param("id"), userID, &req)
+	} else {
+		evaluation, err = h.fraudService.RejectEvaluation(c.Param("id"), userID, &req)
+		message = "Transaction rejected"
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    evaluation,
+		"message": message,
+	})
+}
+
+// GetFraudRules lists the fraud rules and their settings
+func (h *FraudHandlers) GetFraudRules(c *gin.Context) {
+	rules, err := h.fraudService.GetRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rules,
+		"count":   len(rules),
+	})
+}
+
+// UpdateFraudRule changes a rule's action, score, parameters or whether it runs
+func (h *FraudHandlers) UpdateFraudRule(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.UpdateFraudRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	rule, err := h.fraudService.UpdateRule(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rule,
+		"message": "Fraud rule updated",
+	})
+}
+
+// GetFraudRuleStats counts each rule's hits and outcomes (?from=&to=, YYYY-MM-DD,
+// defaulting to the last 30 days)
+func (h *FraudHandlers) GetFraudRuleStats(c *gin.Context) {
+	now := time.Now()
+	from := now.AddDate(0, 0, -30)
+	to := now
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "from date must be before to date"})
+		return
+	}
+
+	stats, err := h.fraudService.GetRuleStats(from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
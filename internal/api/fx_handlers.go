@@ -0,0 +1,378 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FXHandlers handles exchange rates, foreign-currency wallets and conversions
+type FXHandlers struct {
+	fxService *services.FXService
+}
+
+// NewFXHandlers creates a new FX handlers instance
+func NewFXHandlers(db *sql.DB) *FXHandlers {
+	return &FXHandlers{
+		fxService: services.NewFXService(db),
+	}
+}
+
+// GetExchangeRates lists the newest rate for every currency pair
+func (h *FXHandlers) GetExchangeRates(c *gin.Context) {
+	rates, err := h.fxService.GetLatestRates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rates,
+		"count":   len(rates),
+	})
+}
+
+// GetExchangeRateHistory lists the rates published for one pair, newest first
+func (h *FXHandlers) GetExchangeRateHistory(c *gin.Context) {
+	limit, offset := shareMarketPagination(c)
+	rates, err := h.fxService.GetRateHistory(c.Param("base"), c.Param("quote"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rates,
+		"count":   len(rates),
+	})
+}
+
+// SetExchangeRate publishes a rate for a currency pair (admin only)
+func (h *FXHandlers) SetExchangeRate(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.SetExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	rate, err := h.fxService.SetRate(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    rate,
+		"message": "Exchange rate published successfully",
+	})
+}
+
+// ImportExchangeRates publishes a batch of rates (admin only). The batch is either a
+// JSON body or a CSV upload in the "file" field with base,quote,rate[,spread[,effectiveAt]] rows.
+func (h *FXHandlers) ImportExchangeRates(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var rates []models.SetExchangeRateRequest
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Failed to read uploaded file",
+			})
+			return
+		}
+		defer file.Close()
+
+		rates, err = services.ParseRatesCSV(file)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+	} else {
+		var req models.ImportExchangeRatesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid request data: " + err.Error(),
+			})
+			return
+		}
+		rates = req.Rates
+	}
+
+	imported, err := h.fxService.ImportRates(userID, rates)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    imported,
+		"count":   len(imported),
+		"message": "Exchange rates imported successfully",
+	})
+}
+
+// GetFXQuote prices a conversion (?from=&to=&amount=) without moving any money
+func (h *FXHandlers) GetFXQuote(c *gin.Context) {
+	amount, err := strconv.ParseFloat(c.Query("amount"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "A valid amount is required",
+		})
+		return
+	}
+
+	quote, err := h.fxService.GetQuote(c.Query("from"), c.Query("to"), amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    quote,
+	})
+}
+
+// OpenCurrencyWallet opens a wallet for the user in another currency
+func (h *FXHandlers) OpenCurrencyWallet(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.OpenCurrencyWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	wallet, err := h.fxService.OpenCurrencyWallet(userID, req.Currency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    wallet,
+		"message": "Currency wallet opened successfully",
+	})
+}
+
+// GetConsolidatedBalances totals the user's wallets in one currency (?currency=, KES by default)
+func (h *FXHandlers) GetConsolidatedBalances(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	balances, err := h.fxService.GetUserBalances(userID, c.Query("currency"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    balances,
+	})
+}
+
+// ConvertCurrency moves money between two of the user's own wallets
+func (h *FXHandlers) ConvertCurrency(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.ConvertCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	conversion, err := h.fxService.Convert(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    conversion,
+		"message": "Currency converted successfully",
+	})
+}
+
+// FXTransfer sends money to a member or a chama, converting it between currencies
+func (h *FXHandlers) FXTransfer(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.FXTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	conversion, err := h.fxService.Transfer(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    conversion,
+		"message": "Transfer completed successfully",
+	})
+}
+
+// GetChamaConsolidatedBalances totals a chama's wallets in its base currency
+func (h *FXHandlers) GetChamaConsolidatedBalances(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	balances, err := h.fxService.GetChamaBalances(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    balances,
+	})
+}
+
+// SetChamaBaseCurrency changes the currency a chama reports its balances in
+func (h *FXHandlers) SetChamaBaseCurrency(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.SetBaseCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.fxService.SetChamaBaseCurrency(c.Param("id"), userID, req.Currency); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Base currency updated successfully",
+	})
+}
@@ -0,0 +1,370 @@
+package api
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"os"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KYCHandlers handles identity verification and its review by admins
+type KYCHandlers struct {
+	kycService *services.KYCService
+}
+
+// NewKYCHandlers creates a new KYC handlers instance
+func NewKYCHandlers(db *sql.DB) *KYCHandlers {
+	return &KYCHandlers{
+		kycService: services.NewKYCService(db),
+	}
+}
+
+// GetKYCStatus returns the user's verification tier, what it unlocks and any open submission
+func (h *KYCHandlers) GetKYCStatus(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	status, err := h.kycService.GetStatus(userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// CreateKYCSubmission starts a draft submission for a verification tier
+func (h *KYCHandlers) CreateKYCSubmission(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.CreateKYCSubmissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	submission, err := h.kycService.CreateSubmission(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    submission,
+		"message": "KYC submission started. Upload your documents, then submit it for review.",
+	})
+}
+
+// GetKYCSubmission returns a submission to its owner or to an admin
+func (h *KYCHandlers) GetKYCSubmission(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	submission, err := h.kycService.GetSubmission(c.Param("id"), userID, c.GetString("userRole") == "admin")
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    submission,
+	})
+}
+
+// UploadKYCDocument attaches an ID image, selfie or proof of address to a draft
+// submission. The file goes in the "file" field and its kind in "documentType".
+func (h *KYCHandlers) UploadKYCDocument(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	documentType := c.PostForm("documentType")
+	if !models.IsValidKYCDocumentType(documentType) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid document type",
+		})
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "No file provided: " + err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	if header.Size > 10*1024*1024 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "File too large. Maximum size is 10MB",
+		})
+		return
+	}
+
+	storageService, ok := getStorageService(c)
+	if !ok {
+		return
+	}
+
+	// Identity documents are private to the member and the admins who review them. Only
+	// proof of address may be a scanned PDF.
+	allowedTypes := services.StorageIdentityTypes
+	if documentType == string(models.KYCDocumentProofOfAddress) {
+		allowedTypes = services.StorageEvidenceTypes
+	}
+	storedFile, err := storageService.Store(&services.StoreFileInput{
+		OwnerID:      userID,
+		FileName:     header.Filename,
+		Category:     models.FileCategoryKYCDocument,
+		AccessScope:  models.FileAccessPrivate,
+		AllowedTypes: allowedTypes,
+		MaxSize:      10 * 1024 * 1024,
+	}, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	document, replaced, err := h.kycService.AddDocument(c.Param("id"), userID, &models.KYCDocument{
+		DocumentType: models.KYCDocumentType(documentType),
+		FileName:     header.Filename,
+		FileID:       &storedFile.ID,
+		FileSize:     storedFile.Size,
+		FileType:     storedFile.ContentType,
+	})
+	if err != nil {
+		// Clean up uploaded file if the document cannot be attached
+		storageService.Delete(storedFile.ID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if replaced != nil {
+		if replaced.FileID != nil {
+			storageService.Delete(*replaced.FileID)
+		} else if replaced.FilePath != "" {
+			os.Remove(replaced.FilePath)
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    document,
+		"message": "Document uploaded successfully",
+	})
+}
+
+// SubmitKYCSubmission sends a draft submission to the admin review queue
+func (h *KYCHandlers) SubmitKYCSubmission(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	submission, err := h.kycService.Submit(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    submission,
+		"message": "KYC submission sent for review",
+	})
+}
+
+// GetKYCDocument streams a document file to its owner or to an admin
+func (h *KYCHandlers) GetKYCDocument(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	document, err := h.kycService.GetDocument(c.Param("id"), userID, c.GetString("userRole") == "admin")
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	serveProtectedFile(c, document.FileID, document.FilePath, document.FileType)
+}
+
+// GetKYCReviewQueue lists submissions awaiting review, oldest first (?status=, admin only)
+func (h *KYCHandlers) GetKYCReviewQueue(c *gin.Context) {
+	limit, offset := shareMarketPagination(c)
+	submissions, err := h.kycService.GetReviewQueue(c.Query("status"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    submissions,
+		"count":   len(submissions),
+	})
+}
+
+// ApproveKYCSubmission grants a submission's tier to its user (admin only)
+func (h *KYCHandlers) ApproveKYCSubmission(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.ApproveKYCRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	submission, err := h.kycService.Approve(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    submission,
+		"message": "KYC submission approved",
+	})
+}
+
+// RejectKYCSubmission turns a submission down with a reason shown to the user (admin only)
+func (h *KYCHandlers) RejectKYCSubmission(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.RejectKYCRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	submission, err := h.kycService.Reject(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    submission,
+		"message": "KYC submission rejected",
+	})
+}
+
+// GetKYCReviews lists review decisions, optionally by one reviewer (?reviewerId=, admin only)
+func (h *KYCHandlers) GetKYCReviews(c *gin.Context) {
+	limit, offset := shareMarketPagination(c)
+	reviews, err := h.kycService.GetReviews(c.Query("reviewerId"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    reviews,
+		"count":   len(reviews),
+	})
+}
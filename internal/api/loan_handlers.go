@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"time"
 
+	"vaultke-backend/internal/services"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -324,6 +326,15 @@ func CreateLoanApplication(c *gin.Context) {
 		return
 	}
 
+	// Loan size is capped by the borrower's verification tier
+	if err := services.NewKYCService(db.(*sql.DB)).CheckLoanEligibility(userID.(string), req.Amount); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
 	// Start transaction
 	tx, err := db.(*sql.DB).Begin()
 	if err != nil {
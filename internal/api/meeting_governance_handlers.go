@@ -0,0 +1,401 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MeetingGovernanceHandlers handles agenda, motion and resolution HTTP requests
+type MeetingGovernanceHandlers struct {
+	governanceService *services.MeetingGovernanceService
+}
+
+// NewMeetingGovernanceHandlers creates a new meeting governance handlers instance
+func NewMeetingGovernanceHandlers(db *sql.DB) *MeetingGovernanceHandlers {
+	return &MeetingGovernanceHandlers{
+		governanceService: services.NewMeetingGovernanceService(db),
+	}
+}
+
+// GetAgenda retrieves the ordered agenda for a meeting
+func (h *MeetingGovernanceHandlers) GetAgenda(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	items, err := h.governanceService.GetAgenda(meetingID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	totalMinutes := 0
+	for _, item := range items {
+		totalMinutes += item.AllocatedMinutes
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"items":        items,
+			"totalMinutes": totalMinutes,
+		},
+	})
+}
+
+// AddAgendaItem adds an item to a meeting agenda
+func (h *MeetingGovernanceHandlers) AddAgendaItem(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	var req models.CreateAgendaItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	item, err := h.governanceService.AddAgendaItem(meetingID, userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    item,
+		"message": "Agenda item added successfully",
+	})
+}
+
+// UpdateAgendaItem updates an agenda item
+func (h *MeetingGovernanceHandlers) UpdateAgendaItem(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+	itemID := c.Param("itemId")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	var req models.UpdateAgendaItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	item, err := h.governanceService.UpdateAgendaItem(meetingID, itemID, userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    item,
+		"message": "Agenda item updated successfully",
+	})
+}
+
+// DeleteAgendaItem removes an agenda item
+func (h *MeetingGovernanceHandlers) DeleteAgendaItem(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+	itemID := c.Param("itemId")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	if err := h.governanceService.DeleteAgendaItem(meetingID, itemID, userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Agenda item removed successfully",
+	})
+}
+
+// ReorderAgenda changes the order of agenda items
+func (h *MeetingGovernanceHandlers) ReorderAgenda(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	var req models.ReorderAgendaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	items, err := h.governanceService.ReorderAgenda(meetingID, userID, req.ItemIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    items,
+		"message": "Agenda reordered successfully",
+	})
+}
+
+// GetMotions retrieves the motions tabled at a meeting
+func (h *MeetingGovernanceHandlers) GetMotions(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	motions, err := h.governanceService.GetMotions(meetingID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    motions,
+		"count":   len(motions),
+	})
+}
+
+// ProposeMotion tables a new motion
+func (h *MeetingGovernanceHandlers) ProposeMotion(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	var req models.CreateMotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	motion, err := h.governanceService.ProposeMotion(meetingID, userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    motion,
+		"message": "Motion proposed successfully",
+	})
+}
+
+// SecondMotion seconds a proposed motion
+func (h *MeetingGovernanceHandlers) SecondMotion(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+	motionID := c.Param("motionId")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	motion, err := h.governanceService.SecondMotion(meetingID, motionID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    motion,
+		"message": "Motion seconded successfully",
+	})
+}
+
+// WithdrawMotion withdraws a motion before voting opens
+func (h *MeetingGovernanceHandlers) WithdrawMotion(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+	motionID := c.Param("motionId")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	motion, err := h.governanceService.WithdrawMotion(meetingID, motionID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    motion,
+		"message": "Motion withdrawn successfully",
+	})
+}
+
+// OpenMotionVoting opens a live vote on a seconded motion
+func (h *MeetingGovernanceHandlers) OpenMotionVoting(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+	motionID := c.Param("motionId")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	motion, err := h.governanceService.OpenVoting(meetingID, motionID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    motion,
+		"message": "Voting opened",
+	})
+}
+
+// CastMotionVote records a vote on a motion
+func (h *MeetingGovernanceHandlers) CastMotionVote(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+	motionID := c.Param("motionId")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	var req models.CastMotionVoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid request data: " + err.Error()})
+		return
+	}
+
+	motion, err := h.governanceService.CastVote(meetingID, motionID, userID, req.Choice)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    motion,
+		"message": "Vote recorded successfully",
+	})
+}
+
+// CloseMotionVoting closes voting and records a resolution if the motion carried
+func (h *MeetingGovernanceHandlers) CloseMotionVoting(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+	motionID := c.Param("motionId")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	motion, resolution, err := h.governanceService.CloseVoting(meetingID, motionID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"motion":     motion,
+			"resolution": resolution,
+		},
+		"message": "Motion " + string(motion.Status),
+	})
+}
+
+// GetMeetingResolutions retrieves the resolutions passed at a meeting
+func (h *MeetingGovernanceHandlers) GetMeetingResolutions(c *gin.Context) {
+	userID := c.GetString("userID")
+	meetingID := c.Param("id")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	resolutions, err := h.governanceService.GetMeetingResolutions(meetingID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    resolutions,
+		"count":   len(resolutions),
+	})
+}
+
+// SearchChamaResolutions searches a chama's resolutions (?q=)
+func (h *MeetingGovernanceHandlers) SearchChamaResolutions(c *gin.Context) {
+	userID := c.GetString("userID")
+	chamaID := c.Param("id")
+
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "User not authenticated"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	resolutions, err := h.governanceService.SearchResolutions(chamaID, userID, c.Query("q"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    resolutions,
+		"count":   len(resolutions),
+	})
+}
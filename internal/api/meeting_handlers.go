@@ -4,15 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
 	"strconv"
 	"time"
 	// "strings"
+	"vaultke-backend/internal/models"
 	"vaultke-backend/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -1143,9 +1142,19 @@ func EndMeeting(c *gin.Context) {
 		return
 	}
 
+	// Post attendance fines according to the chama's rules
+	finesIssued := 0
+	fines, fineErr := services.NewAttendanceService(db.(*sql.DB)).ApplyMeetingFines(meetingID)
+	if fineErr != nil {
+		log.Printf("Failed to apply attendance fines for meeting %s: %v", meetingID, fineErr)
+	} else {
+		finesIssued = len(fines)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Meeting ended successfully",
+		"success":     true,
+		"message":     "Meeting ended successfully",
+		"finesIssued": finesIssued,
 	})
 }
 
@@ -1591,72 +1600,79 @@ func UploadMeetingDocument(c *gin.Context) {
 		return
 	}
 
-	// Create uploads directory
-	uploadsDir := "./uploads/meetings"
-	if err := os.MkdirAll(uploadsDir, 0o755); err != nil {
+	// Get form values
+	documentType := c.PostForm("documentType")
+	description := c.PostForm("description")
+
+	if documentType == "" {
+		documentType = "meeting_document"
+	}
+
+	// Save document info to database
+	db, exists := c.Get("db")
+	if !exists {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
-			"error":   "Failed to create upload directory: " + err.Error(),
+			"error":   "Database connection not available",
 		})
 		return
 	}
+	storageService, ok := getStorageService(c)
+	if !ok {
+		return
+	}
 
-	// Generate unique filename
-	fileExt := filepath.Ext(header.Filename)
-	fileName := fmt.Sprintf("%s_%d%s", uuid.New().String(), time.Now().Unix(), fileExt)
-	filePath := filepath.Join(uploadsDir, fileName)
-
-	// Save file
-	dst, err := os.Create(filePath)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+	// Documents such as minutes are only readable by the meeting's chama
+	var chamaID string
+	err = db.(*sql.DB).QueryRow(`
+		SELECT m.chama_id FROM meetings m
+		INNER JOIN chama_members cm ON cm.chama_id = m.chama_id AND cm.user_id = ? AND cm.is_active = TRUE
+		WHERE m.id = ?
+	`, userID, meetingID).Scan(&chamaID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{
 			"success": false,
-			"error":   "Failed to create file: " + err.Error(),
+			"error":   "Meeting not found",
 		})
 		return
 	}
-	defer dst.Close()
-
-	if _, err := io.Copy(dst, file); err != nil {
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
-			"error":   "Failed to save file: " + err.Error(),
+			"error":   "Failed to find meeting: " + err.Error(),
 		})
 		return
 	}
 
-	// Create file URL
-	fileURL := fmt.Sprintf("/uploads/meetings/%s", fileName)
-
-	// Get form values
-	documentType := c.PostForm("documentType")
-	description := c.PostForm("description")
-
-	if documentType == "" {
-		documentType = "meeting_document"
-	}
-
-	// Save document info to database
-	db, exists := c.Get("db")
-	if !exists {
-		c.JSON(http.StatusInternalServerError, gin.H{
+	storedFile, err := storageService.Store(&services.StoreFileInput{
+		OwnerID:      userID.(string),
+		FileName:     header.Filename,
+		Category:     models.FileCategoryMeetingDocument,
+		AccessScope:  models.FileAccessChama,
+		ScopeID:      chamaID,
+		AllowedTypes: services.StorageDocumentTypes,
+		MaxSize:      10 * 1024 * 1024,
+	}, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
-			"error":   "Database connection not available",
+			"error":   err.Error(),
 		})
 		return
 	}
+	fileURL := services.StoredFileURL(storedFile.ID)
 
 	documentID := uuid.New().String()
 	_, err = db.(*sql.DB).Exec(`
 		INSERT INTO meeting_documents (
 			id, meeting_id, uploaded_by, file_name, file_path, file_url,
-			file_size, file_type, document_type, description, created_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
-	`, documentID, meetingID, userID, header.Filename, filePath, fileURL,
-		header.Size, header.Header.Get("Content-Type"), documentType, description)
+			file_size, file_type, document_type, description, file_id, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, documentID, meetingID, userID, header.Filename, "", fileURL,
+		storedFile.Size, storedFile.ContentType, documentType, description, storedFile.ID)
 	if err != nil {
 		// Clean up uploaded file if database insert fails
-		os.Remove(filePath)
+		storageService.Delete(storedFile.ID)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Failed to save document info: " + err.Error(),
@@ -1671,8 +1687,9 @@ func UploadMeetingDocument(c *gin.Context) {
 			"id":           documentID,
 			"meetingId":    meetingID,
 			"fileName":     header.Filename,
-			"fileSize":     header.Size,
-			"fileType":     header.Header.Get("Content-Type"),
+			"fileId":       storedFile.ID,
+			"fileSize":     storedFile.Size,
+			"fileType":     storedFile.ContentType,
 			"documentType": documentType,
 			"description":  description,
 			"url":          fileURL,
@@ -1706,7 +1723,7 @@ func GetMeetingDocuments(c *gin.Context) {
 	// Query documents
 	rows, err := db.(*sql.DB).Query(`
 		SELECT id, meeting_id, uploaded_by, file_name, file_url, file_size,
-			   file_type, document_type, description, created_at
+			   file_type, document_type, description, COALESCE(file_id, ''), created_at
 		FROM meeting_documents
 		WHERE meeting_id = ?
 		ORDER BY created_at DESC
@@ -1732,12 +1749,13 @@ func GetMeetingDocuments(c *gin.Context) {
 			FileType     string    `json:"fileType"`
 			DocumentType string    `json:"documentType"`
 			Description  string    `json:"description"`
+			FileID       string    `json:"fileId"`
 			CreatedAt    time.Time `json:"createdAt"`
 		}
 
 		err := rows.Scan(&doc.ID, &doc.MeetingID, &doc.UploadedBy, &doc.FileName,
 			&doc.FileURL, &doc.FileSize, &doc.FileType, &doc.DocumentType,
-			&doc.Description, &doc.CreatedAt)
+			&doc.Description, &doc.FileID, &doc.CreatedAt)
 		if err != nil {
 			continue
 		}
@@ -1748,6 +1766,7 @@ func GetMeetingDocuments(c *gin.Context) {
 			"uploadedBy":   doc.UploadedBy,
 			"name":         doc.FileName,
 			"url":          doc.FileURL,
+			"fileId":       doc.FileID,
 			"size":         doc.FileSize,
 			"type":         doc.FileType,
 			"documentType": doc.DocumentType,
@@ -1796,11 +1815,11 @@ func DeleteMeetingDocument(c *gin.Context) {
 	}
 
 	// Get document info first to delete the file
-	var filePath string
+	var filePath, fileID string
 	err := db.(*sql.DB).QueryRow(`
-		SELECT file_path FROM meeting_documents
+		SELECT file_path, COALESCE(file_id, '') FROM meeting_documents
 		WHERE id = ? AND meeting_id = ?
-	`, documentID, meetingID).Scan(&filePath)
+	`, documentID, meetingID).Scan(&filePath, &fileID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -1830,7 +1849,11 @@ func DeleteMeetingDocument(c *gin.Context) {
 	}
 
 	// Delete physical file
-	if filePath != "" {
+	if fileID != "" {
+		if storageService, exists := c.Get("storageService"); exists {
+			storageService.(*services.StorageService).Delete(fileID)
+		}
+	} else if filePath != "" {
 		os.Remove(filePath) // Ignore error if file doesn't exist
 	}
 
@@ -1911,6 +1934,7 @@ func SaveMeetingMinutes(c *gin.Context) {
 			})
 			return
 		}
+		onMinutesStatusChanged(db.(*sql.DB), meetingID, minutesID, req.Status, userID.(string))
 
 		c.JSON(http.StatusCreated, gin.H{
 			"success": true,
@@ -1944,6 +1968,7 @@ func SaveMeetingMinutes(c *gin.Context) {
 			})
 			return
 		}
+		onMinutesStatusChanged(db.(*sql.DB), meetingID, existingID, req.Status, userID.(string))
 
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
@@ -2030,6 +2055,7 @@ func UpdateMeetingMinutes(c *gin.Context) {
             })
             return
         }
+        onMinutesStatusChanged(db.(*sql.DB), meetingID, minutesID, req.Status, userID.(string))
 
         c.JSON(http.StatusCreated, gin.H{
             "success": true,
@@ -2068,6 +2094,7 @@ func UpdateMeetingMinutes(c *gin.Context) {
         })
         return
     }
+    onMinutesStatusChanged(db.(*sql.DB), meetingID, existingID, req.Status, userID.(string))
 
     c.JSON(http.StatusOK, gin.H{
         "success": true,
@@ -2136,20 +2163,55 @@ func GetMeetingMinutes(c *gin.Context) {
 		return
 	}
 
+	// Include the structured agenda and resolutions alongside the free-text minutes
+	governanceService := services.NewMeetingGovernanceService(db.(*sql.DB))
+	userIDStr := c.GetString("userID")
+	agenda, agendaErr := governanceService.GetAgenda(meetingID, userIDStr)
+	if agendaErr != nil {
+		agenda = []models.AgendaItem{}
+	}
+	resolutions, resErr := governanceService.GetMeetingResolutions(meetingID, userIDStr)
+	if resErr != nil {
+		resolutions = []models.Resolution{}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": map[string]interface{}{
-			"id":        minutes.ID,
-			"meetingId": minutes.MeetingID,
-			"content":   minutes.Content,
-			"status":    minutes.Status,
-			"takenBy":   minutes.TakenBy,
-			"createdAt": minutes.CreatedAt.Format(time.RFC3339),
-			"updatedAt": minutes.UpdatedAt.Format(time.RFC3339),
+			"id":          minutes.ID,
+			"meetingId":   minutes.MeetingID,
+			"content":     minutes.Content,
+			"status":      minutes.Status,
+			"takenBy":     minutes.TakenBy,
+			"agenda":      agenda,
+			"resolutions": resolutions,
+			"createdAt":   minutes.CreatedAt.Format(time.RFC3339),
+			"updatedAt":   minutes.UpdatedAt.Format(time.RFC3339),
 		},
 	})
 }
 
+// onMinutesStatusChanged stamps approval details and links the meeting's resolutions
+// into the minutes once they are approved or published.
+func onMinutesStatusChanged(db *sql.DB, meetingID, minutesID, status, userID string) {
+	if status != "approved" && status != "published" {
+		return
+	}
+
+	_, err := db.Exec(`
+		UPDATE meeting_minutes
+		SET approved_by = COALESCE(approved_by, ?), approved_at = COALESCE(approved_at, CURRENT_TIMESTAMP)
+		WHERE id = ?
+	`, userID, minutesID)
+	if err != nil {
+		log.Printf("Failed to record minutes approval: %v", err)
+	}
+
+	if err := services.NewMeetingGovernanceService(db).LinkResolutionsToMinutes(meetingID, minutesID); err != nil {
+		log.Printf("Failed to link resolutions to minutes: %v", err)
+	}
+}
+
 // CreateMeetingWithCalendar creates a new meeting with Google Calendar integration
 func CreateMeetingWithCalendar(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
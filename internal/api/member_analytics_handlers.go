@@ -0,0 +1,102 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MemberAnalyticsHandlers handles member contribution history and trends
+type MemberAnalyticsHandlers struct {
+	memberAnalyticsService *services.MemberAnalyticsService
+}
+
+// NewMemberAnalyticsHandlers creates a new member analytics handlers instance
+func NewMemberAnalyticsHandlers(db *sql.DB) *MemberAnalyticsHandlers {
+	return &MemberAnalyticsHandlers{
+		memberAnalyticsService: services.NewMemberAnalyticsService(db),
+	}
+}
+
+// GetChamaMemberAnalytics returns every member's contribution analytics, ranked
+// (?months= or ?from=YYYY-MM&to=YYYY-MM)
+func (h *MemberAnalyticsHandlers) GetChamaMemberAnalytics(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	start, end, err := analyticsPeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	analytics, err := h.memberAnalyticsService.GetChamaMemberAnalytics(c.Param("id"), userID, start, end)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    analytics,
+		"count":   len(analytics),
+	})
+}
+
+// GetMemberAnalytics returns one member's contribution history, consistency, streaks,
+// rank and projected year-end savings (?months= or ?from=YYYY-MM&to=YYYY-MM)
+func (h *MemberAnalyticsHandlers) GetMemberAnalytics(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	start, end, err := analyticsPeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	analytics, err := h.memberAnalyticsService.GetMemberAnalytics(c.Param("id"), c.Param("userId"), userID, start, end)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    analytics,
+	})
+}
+
+func analyticsPeriod(c *gin.Context) (time.Time, time.Time, error) {
+	months, _ := strconv.Atoi(c.Query("months"))
+	return services.ResolveAnalyticsPeriod(months, c.Query("from"), c.Query("to"), time.Now())
+}
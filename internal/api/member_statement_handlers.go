@@ -0,0 +1,165 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MemberStatementHandlers handles members' monthly statements and statements for
+// arbitrary date ranges
+type MemberStatementHandlers struct {
+	statementService *services.MemberStatementService
+}
+
+// NewMemberStatementHandlers creates a new member statement handlers instance
+func NewMemberStatementHandlers(db *sql.DB) *MemberStatementHandlers {
+	return &MemberStatementHandlers{
+		statementService: services.NewMemberStatementService(db),
+	}
+}
+
+// GetStatements lists the monthly statements generated for a member, the caller by
+// default. Officials can pass memberId to see another member's.
+func (h *MemberStatementHandlers) GetStatements(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	memberID := c.DefaultQuery("memberId", userID)
+	statements, err := h.statementService.GetStatements(c.Param("id"), memberID, userID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    statements,
+		"count":   len(statements),
+	})
+}
+
+// GetOnDemandStatement builds a statement for the from and to dates (inclusive),
+// as JSON or, with format=pdf, as a PDF download
+func (h *MemberStatementHandlers) GetOnDemandStatement(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	from, to, ok := parseReportPeriod(c)
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "pdf" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "format must be json or pdf",
+		})
+		return
+	}
+
+	memberID := c.DefaultQuery("memberId", userID)
+	statement, err := h.statementService.GetStatement(c.Param("id"), memberID, userID, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if format == "pdf" {
+		h.sendPDF(c, statement)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    statement,
+	})
+}
+
+// GetStatement returns a monthly statement as it was generated
+func (h *MemberStatementHandlers) GetStatement(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	statement, err := h.statementService.GetStoredStatement(c.Param("id"), c.Param("statementId"), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    statement,
+	})
+}
+
+// DownloadStatementPDF returns a monthly statement as the PDF that was emailed
+func (h *MemberStatementHandlers) DownloadStatementPDF(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	statement, err := h.statementService.GetStoredStatement(c.Param("id"), c.Param("statementId"), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	h.sendPDF(c, statement)
+}
+
+func (h *MemberStatementHandlers) sendPDF(c *gin.Context, statement *models.MemberStatement) {
+	document, err := h.statementService.RenderPDF(statement)
+	if err != nil {
+		log.Printf("Failed to render statement for member %s of chama %s: %v", statement.MemberID, statement.ChamaID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to render statement",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, services.StatementFileName(statement)))
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "application/pdf", document)
+}
@@ -8,16 +8,22 @@ import (
 	"strings"
 	"time"
 
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 type MoneyRequestHandlers struct {
-	db *sql.DB
+	db                  *sql.DB
+	moneyRequestService *services.MoneyRequestService
 }
 
 func NewMoneyRequestHandlers(db *sql.DB) *MoneyRequestHandlers {
-	return &MoneyRequestHandlers{db: db}
+	return &MoneyRequestHandlers{
+		db:                  db,
+		moneyRequestService: services.NewMoneyRequestService(db),
+	}
 }
 
 // normalizePhoneNumber removes spaces, dashes and normalizes phone number format
@@ -47,32 +53,6 @@ func normalizePhoneNumber(phone string) string {
 	return normalized
 }
 
-type CreateMoneyRequestRequest struct {
-	Amount      float64 `json:"amount" binding:"required,gt=0"`
-	Reason      string  `json:"reason"`
-	RequestType string  `json:"requestType" binding:"required,oneof=qr_code direct"`
-}
-
-type SendMoneyRequestRequest struct {
-	Amount       float64 `json:"amount" binding:"required,gt=0"`
-	Reason       string  `json:"reason"`
-	TargetUserID string  `json:"targetUserId"`
-	TargetPhone  string  `json:"targetPhone"`
-	RequestType  string  `json:"requestType" binding:"required,oneof=direct"`
-}
-
-type MoneyRequest struct {
-	ID           string    `json:"id"`
-	RequesterID  string    `json:"requesterId"`
-	TargetUserID *string   `json:"targetUserId,omitempty"`
-	Amount       float64   `json:"amount"`
-	Reason       string    `json:"reason"`
-	RequestType  string    `json:"requestType"`
-	Status       string    `json:"status"`
-	CreatedAt    time.Time `json:"createdAt"`
-	ExpiresAt    time.Time `json:"expiresAt"`
-}
-
 // CreateMoneyRequest creates a new money request (for QR codes)
 func (h *MoneyRequestHandlers) CreateMoneyRequest(c *gin.Context) {
 	userID := c.GetString("userID")
@@ -84,7 +64,7 @@ func (h *MoneyRequestHandlers) CreateMoneyRequest(c *gin.Context) {
 		return
 	}
 
-	var req CreateMoneyRequestRequest
+	var req models.CreateMoneyRequestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
@@ -93,37 +73,15 @@ func (h *MoneyRequestHandlers) CreateMoneyRequest(c *gin.Context) {
 		return
 	}
 
-	// Generate unique request ID
-	requestID := uuid.New().String()
-	expiresAt := time.Now().Add(24 * time.Hour) // Expires in 24 hours
-
-	// Insert money request into database
-	query := `
-		INSERT INTO money_requests (id, requester_id, amount, reason, request_type, status, created_at, expires_at)
-		VALUES (?, ?, ?, ?, ?, 'pending', ?, ?)
-	`
-
-	_, err := h.db.Exec(query, requestID, userID, req.Amount, req.Reason, req.RequestType, time.Now(), expiresAt)
+	moneyRequest, err := h.moneyRequestService.CreateRequest(userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
-			"error":   "Failed to create money request: " + err.Error(),
+			"error":   err.Error(),
 		})
 		return
 	}
 
-	// Return the created request
-	moneyRequest := MoneyRequest{
-		ID:          requestID,
-		RequesterID: userID,
-		Amount:      req.Amount,
-		Reason:      req.Reason,
-		RequestType: req.RequestType,
-		Status:      "pending",
-		CreatedAt:   time.Now(),
-		ExpiresAt:   expiresAt,
-	}
-
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    moneyRequest,
@@ -142,7 +100,7 @@ func (h *MoneyRequestHandlers) SendMoneyRequest(c *gin.Context) {
 		return
 	}
 
-	var req SendMoneyRequestRequest
+	var req models.SendMoneyRequestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
@@ -189,56 +147,241 @@ func (h *MoneyRequestHandlers) SendMoneyRequest(c *gin.Context) {
 		return
 	}
 
-	// Generate unique request ID
-	requestID := uuid.New().String()
-	expiresAt := time.Now().Add(24 * time.Hour) // Expires in 24 hours
+	moneyRequest, err := h.moneyRequestService.SendRequest(userID, targetUserID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
 
-	// Insert money request into database
-	query := `
-		INSERT INTO money_requests (id, requester_id, target_user_id, amount, reason, request_type, status, created_at, expires_at)
-		VALUES (?, ?, ?, ?, ?, ?, 'pending', ?, ?)
-	`
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Money request sent successfully",
+		"data": gin.H{
+			"requestId":    moneyRequest.ID,
+			"targetUserId": targetUserID,
+			"request":      moneyRequest,
+		},
+	})
+}
+
+// CreateSplitMoneyRequest splits a shared cost into a request for each participant
+func (h *MoneyRequestHandlers) CreateSplitMoneyRequest(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.CreateSplitMoneyRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	split, err := h.moneyRequestService.CreateSplitRequest(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    split,
+		"message": "Split request sent successfully",
+	})
+}
+
+// GetMoneyRequestSplit returns a split request and how much of it has been paid
+func (h *MoneyRequestHandlers) GetMoneyRequestSplit(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	split, err := h.moneyRequestService.GetSplit(c.Param("splitId"), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    split,
+	})
+}
+
+// GetIncomingMoneyRequests lists requests addressed to the user (?status=&limit=&offset=)
+func (h *MoneyRequestHandlers) GetIncomingMoneyRequests(c *gin.Context) {
+	h.listMoneyRequests(c, h.moneyRequestService.GetIncomingRequests)
+}
+
+// GetOutgoingMoneyRequests lists requests the user has made (?status=&limit=&offset=)
+func (h *MoneyRequestHandlers) GetOutgoingMoneyRequests(c *gin.Context) {
+	h.listMoneyRequests(c, h.moneyRequestService.GetOutgoingRequests)
+}
+
+func (h *MoneyRequestHandlers) listMoneyRequests(c *gin.Context, list func(userID, status string, limit, offset int) ([]models.MoneyRequest, error)) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	limit, offset := shareMarketPagination(c)
 
-	_, err := h.db.Exec(query, requestID, userID, targetUserID, req.Amount, req.Reason, req.RequestType, time.Now(), expiresAt)
+	requests, err := list(userID, c.Query("status"), limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
-			"error":   "Failed to send money request: " + err.Error(),
+			"error":   err.Error(),
 		})
 		return
 	}
 
-	// Create in-app notification for target user
-	notificationID := uuid.New().String()
-	notificationQuery := `
-		INSERT INTO notifications (id, user_id, type, title, message, data, created_at, is_read)
-		VALUES (?, ?, 'money_request', ?, ?, ?, ?, false)
-	`
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    requests,
+		"count":   len(requests),
+	})
+}
 
-	// Get requester name for notification
-	var requesterName string
-	err = h.db.QueryRow("SELECT CONCAT(COALESCE(first_name, ''), ' ', COALESCE(last_name, '')) FROM users WHERE id = ?", userID).Scan(&requesterName)
+// GetMoneyRequest returns a single money request
+func (h *MoneyRequestHandlers) GetMoneyRequest(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	moneyRequest, err := h.moneyRequestService.GetRequest(c.Param("requestId"), userID)
 	if err != nil {
-		requesterName = "Someone"
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
 	}
 
-	notificationTitle := "Money Request"
-	notificationMessage := fmt.Sprintf("%s has requested KES %.2f from you", requesterName, req.Amount)
-	notificationData := fmt.Sprintf(`{"requestId": "%s", "amount": %.2f, "reason": "%s", "requesterId": "%s"}`, requestID, req.Amount, req.Reason, userID)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    moneyRequest,
+	})
+}
+
+// PayMoneyRequest pays a request from the user's wallet in one step
+func (h *MoneyRequestHandlers) PayMoneyRequest(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
 
-	_, err = h.db.Exec(notificationQuery, notificationID, targetUserID, notificationTitle, notificationMessage, notificationData, time.Now())
+	moneyRequest, err := h.moneyRequestService.PayRequest(c.Param("requestId"), userID)
 	if err != nil {
-		// Log error but don't fail the request
-		fmt.Printf("Failed to create notification: %v\n", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Money request sent successfully",
-		"data": gin.H{
-			"requestId":    requestID,
-			"targetUserId": targetUserID,
-		},
+		"data":    moneyRequest,
+		"message": "Money request paid successfully",
+	})
+}
+
+// DeclineMoneyRequest turns down a request addressed to the user
+func (h *MoneyRequestHandlers) DeclineMoneyRequest(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.DeclineMoneyRequestRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid request data: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	moneyRequest, err := h.moneyRequestService.DeclineRequest(c.Param("requestId"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    moneyRequest,
+		"message": "Money request declined",
+	})
+}
+
+// CancelMoneyRequest withdraws a pending request the user made
+func (h *MoneyRequestHandlers) CancelMoneyRequest(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	moneyRequest, err := h.moneyRequestService.CancelRequest(c.Param("requestId"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    moneyRequest,
+		"message": "Money request cancelled",
 	})
 }
 
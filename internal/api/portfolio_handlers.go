@@ -0,0 +1,357 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PortfolioHandlers handles a chama's investment portfolio and net asset value history
+type PortfolioHandlers struct {
+	portfolioService *services.PortfolioService
+}
+
+// NewPortfolioHandlers creates a new portfolio handlers instance
+func NewPortfolioHandlers(db *sql.DB) *PortfolioHandlers {
+	return &PortfolioHandlers{
+		portfolioService: services.NewPortfolioService(db),
+	}
+}
+
+// GetAssets lists the chama's investments (?status=active|disposed)
+func (h *PortfolioHandlers) GetAssets(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" && status != string(models.PortfolioAssetActive) && status != string(models.PortfolioAssetDisposed) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid status, expected active or disposed",
+		})
+		return
+	}
+
+	assets, err := h.portfolioService.GetAssets(c.Param("id"), userID, status)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    assets,
+	})
+}
+
+// CreateAsset records an investment, optionally paying for it from the chama wallet
+func (h *PortfolioHandlers) CreateAsset(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.CreatePortfolioAssetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	asset, err := h.portfolioService.CreateAsset(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    asset,
+		"message": "Investment recorded successfully",
+	})
+}
+
+// GetAsset returns an investment with its valuation and income history
+func (h *PortfolioHandlers) GetAsset(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	asset, err := h.portfolioService.GetAsset(c.Param("id"), c.Param("assetId"), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    asset,
+	})
+}
+
+// RecordValuation records a new value for an investment
+func (h *PortfolioHandlers) RecordValuation(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.RecordPortfolioValuationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	asset, err := h.portfolioService.RecordValuation(c.Param("id"), c.Param("assetId"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    asset,
+		"message": "Valuation recorded successfully",
+	})
+}
+
+// RecordEntry records income from or an expense on an investment
+func (h *PortfolioHandlers) RecordEntry(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.RecordPortfolioEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	entry, err := h.portfolioService.RecordEntry(c.Param("id"), c.Param("assetId"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    entry,
+	})
+}
+
+// DisposeAsset records the sale or maturity of an investment
+func (h *PortfolioHandlers) DisposeAsset(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.DisposePortfolioAssetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	asset, err := h.portfolioService.DisposeAsset(c.Param("id"), c.Param("assetId"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    asset,
+		"message": "Investment disposed of successfully",
+	})
+}
+
+// GetNAVHistory returns the chama's recorded net asset values (?from=&to=, YYYY-MM-DD,
+// defaulting to this year)
+func (h *PortfolioHandlers) GetNAVHistory(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	from, to, ok := parseReportPeriod(c)
+	if !ok {
+		return
+	}
+
+	history, err := h.portfolioService.GetNAVHistory(c.Param("id"), userID, from, to)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    history,
+	})
+}
+
+// RecordNAVSnapshot records the chama's net asset value as it stands now
+func (h *PortfolioHandlers) RecordNAVSnapshot(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.RecordNAVSnapshotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	snapshot, err := h.portfolioService.RecordNAVSnapshot(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    snapshot,
+	})
+}
+
+// GetPerformanceReport reports the portfolio's returns and allocation over a period
+// (?from=&to=, YYYY-MM-DD, defaulting to this year)
+func (h *PortfolioHandlers) GetPerformanceReport(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	from, to, ok := parseReportPeriod(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.portfolioService.GetPerformanceReport(c.Param("id"), userID, from, to)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// parseReportPeriod reads the ?from=&to= query (YYYY-MM-DD, both inclusive) as a
+// half-open range, defaulting to the current year. It writes the error response
+// itself when the range is invalid.
+func parseReportPeriod(c *gin.Context) (time.Time, time.Time, bool) {
+	now := time.Now()
+	from := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+	to := from.AddDate(1, 0, 0)
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", fromStr, now.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid from date, expected YYYY-MM-DD"})
+			return from, to, false
+		}
+		from = parsed
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", toStr, now.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Invalid to date, expected YYYY-MM-DD"})
+			return from, to, false
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "from date must be before to date"})
+		return from, to, false
+	}
+	return from, to, true
+}
@@ -0,0 +1,425 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SavingsHandlers handles savings goals and fixed-term locked savings
+type SavingsHandlers struct {
+	savingsService *services.SavingsService
+}
+
+// NewSavingsHandlers creates a new savings handlers instance
+func NewSavingsHandlers(db *sql.DB) *SavingsHandlers {
+	return &SavingsHandlers{
+		savingsService: services.NewSavingsService(db),
+	}
+}
+
+// CreateSavingsGoal opens a goal-based savings wallet
+func (h *SavingsHandlers) CreateSavingsGoal(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.CreateSavingsGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	goal, err := h.savingsService.CreateGoal(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    goal,
+		"message": "Savings goal created successfully",
+	})
+}
+
+// GetSavingsGoals lists the user's savings goals (?status=)
+func (h *SavingsHandlers) GetSavingsGoals(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	goals, err := h.savingsService.GetGoals(userID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    goals,
+		"count":   len(goals),
+	})
+}
+
+// GetSavingsGoal returns a savings goal with its progress
+func (h *SavingsHandlers) GetSavingsGoal(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	goal, err := h.savingsService.GetGoal(c.Param("goalId"), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    goal,
+	})
+}
+
+// DepositToSavingsGoal moves money from the personal wallet into a goal
+func (h *SavingsHandlers) DepositToSavingsGoal(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.SavingsGoalAmountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	goal, err := h.savingsService.DepositToGoal(c.Param("goalId"), userID, req.Amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    goal,
+		"message": "Deposit successful",
+	})
+}
+
+// WithdrawFromSavingsGoal moves money from a goal back to the personal wallet
+func (h *SavingsHandlers) WithdrawFromSavingsGoal(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.SavingsGoalAmountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	goal, err := h.savingsService.WithdrawFromGoal(c.Param("goalId"), userID, req.Amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    goal,
+		"message": "Withdrawal successful",
+	})
+}
+
+// SetSavingsGoalSweep sets or clears a goal's auto-sweep rule
+func (h *SavingsHandlers) SetSavingsGoalSweep(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.SetSavingsSweepRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	goal, err := h.savingsService.SetSweepRule(c.Param("goalId"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    goal,
+		"message": "Sweep rule updated",
+	})
+}
+
+// CloseSavingsGoal returns a goal's balance to the personal wallet and closes it
+func (h *SavingsHandlers) CloseSavingsGoal(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	goal, err := h.savingsService.CloseGoal(c.Param("goalId"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    goal,
+		"message": "Savings goal closed",
+	})
+}
+
+// CreateFixedSavings locks money away until maturity
+func (h *SavingsHandlers) CreateFixedSavings(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.CreateFixedSavingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	fixed, err := h.savingsService.CreateFixedSavings(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    fixed,
+		"message": "Fixed savings created successfully",
+	})
+}
+
+// GetFixedSavings lists the user's fixed savings (?status=)
+func (h *SavingsHandlers) GetFixedSavings(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	savings, err := h.savingsService.GetFixedSavings(userID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    savings,
+		"count":   len(savings),
+	})
+}
+
+// GetFixedSavingsByID returns one fixed savings deposit
+func (h *SavingsHandlers) GetFixedSavingsByID(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	fixed, err := h.savingsService.GetFixedSavingsByID(c.Param("fixedId"), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    fixed,
+	})
+}
+
+// BreakFixedSavings releases fixed savings before maturity, less the early withdrawal penalty
+func (h *SavingsHandlers) BreakFixedSavings(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	fixed, err := h.savingsService.BreakFixedSavings(c.Param("fixedId"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    fixed,
+		"message": "Fixed savings withdrawn early",
+	})
+}
+
+// GetFixedSavingsRules returns a chama's fixed savings terms
+func (h *SavingsHandlers) GetFixedSavingsRules(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	rules, err := h.savingsService.GetFixedSavingsRules(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rules,
+	})
+}
+
+// UpdateFixedSavingsRules changes a chama's fixed savings interest, penalty and terms
+func (h *SavingsHandlers) UpdateFixedSavingsRules(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.UpdateFixedSavingsRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	rules, err := h.savingsService.UpdateFixedSavingsRules(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rules,
+		"message": "Fixed savings rules updated",
+	})
+}
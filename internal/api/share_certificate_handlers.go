@@ -0,0 +1,140 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShareCertificateHandlers serves signed share certificates and their public verification
+type ShareCertificateHandlers struct {
+	certificateService *services.ShareCertificateService
+}
+
+// NewShareCertificateHandlers creates a new share certificate handlers instance
+func NewShareCertificateHandlers(certificateService *services.ShareCertificateService) *ShareCertificateHandlers {
+	return &ShareCertificateHandlers{
+		certificateService: certificateService,
+	}
+}
+
+// GetMyCertificates lists the user's certificates in a chama, including cancelled ones
+func (h *ShareCertificateHandlers) GetMyCertificates(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	certificates, err := h.certificateService.GetMemberCertificates(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    certificates,
+	})
+}
+
+// GetCertificate returns one certificate to its holder, chama officials or an admin
+func (h *ShareCertificateHandlers) GetCertificate(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	certificate, err := h.certificateService.GetCertificate(c.Param("certificateId"), userID, c.GetString("userRole") == "admin")
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    certificate,
+	})
+}
+
+// DownloadCertificatePDF renders a certificate as a PDF document
+func (h *ShareCertificateHandlers) DownloadCertificatePDF(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	certificate, err := h.certificateService.GetCertificate(c.Param("certificateId"), userID, c.GetString("userRole") == "admin")
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	document, err := h.certificateService.RenderPDF(certificate)
+	if err != nil {
+		log.Printf("Failed to render share certificate %s: %v", certificate.CertificateNumber, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to render certificate",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, certificate.CertificateNumber))
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "application/pdf", document)
+}
+
+// VerifyCertificate is the public endpoint behind certificates' QR codes. It is not
+// behind the auth middleware; the signature in the URL is what proves authenticity.
+func (h *ShareCertificateHandlers) VerifyCertificate(c *gin.Context) {
+	verification, err := h.certificateService.Verify(c.Param("number"), c.Query("sig"))
+	if err != nil {
+		log.Printf("Failed to verify share certificate %s: %v", c.Param("number"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to verify certificate",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    verification,
+	})
+}
+
+// GetCertificatePublicKey returns the key certificates are signed with
+func (h *ShareCertificateHandlers) GetCertificatePublicKey(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"algorithm": "Ed25519",
+			"publicKey": h.certificateService.PublicKey(),
+		},
+	})
+}
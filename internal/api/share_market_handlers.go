@@ -0,0 +1,426 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShareMarketHandlers handles share valuation, redemption, splits and member-to-member trading
+type ShareMarketHandlers struct {
+	shareMarketService *services.ShareMarketService
+}
+
+// NewShareMarketHandlers creates a new share market handlers instance
+func NewShareMarketHandlers(db *sql.DB) *ShareMarketHandlers {
+	return &ShareMarketHandlers{
+		shareMarketService: services.NewShareMarketService(db),
+	}
+}
+
+// SetSharePrice records a new share price, set manually or from net asset value
+func (h *ShareMarketHandlers) SetSharePrice(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.SharesResponse{
+			Success: false,
+			Error:   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.SetSharePriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	price, err := h.shareMarketService.SetSharePrice(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SharesResponse{
+		Success: true,
+		Data:    price,
+		Message: "Share price updated successfully",
+	})
+}
+
+// GetSharePrice returns the chama's current share price
+func (h *ShareMarketHandlers) GetSharePrice(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.SharesResponse{
+			Success: false,
+			Error:   "User not authenticated",
+		})
+		return
+	}
+
+	price, err := h.shareMarketService.GetCurrentPrice(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SharesResponse{
+		Success: true,
+		Data:    price,
+	})
+}
+
+// GetSharePriceHistory returns the chama's share price history
+func (h *ShareMarketHandlers) GetSharePriceHistory(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	limit, offset := shareMarketPagination(c)
+
+	prices, err := h.shareMarketService.GetPriceHistory(c.Param("id"), userID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    prices,
+		"count":   len(prices),
+	})
+}
+
+// GetShareValuation returns the chama's net asset value per share (?otherAssets=&liabilities=)
+func (h *ShareMarketHandlers) GetShareValuation(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.SharesResponse{
+			Success: false,
+			Error:   "User not authenticated",
+		})
+		return
+	}
+
+	var otherAssets, liabilities *float64
+	if value, err := strconv.ParseFloat(c.Query("otherAssets"), 64); err == nil && value >= 0 {
+		otherAssets = &value
+	}
+	if value, err := strconv.ParseFloat(c.Query("liabilities"), 64); err == nil && value >= 0 {
+		liabilities = &value
+	}
+
+	valuation, err := h.shareMarketService.GetValuation(c.Param("id"), userID, otherAssets, liabilities)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SharesResponse{
+		Success: true,
+		Data:    valuation,
+	})
+}
+
+// GetShareMarketSettings returns the chama's lock-in period and which exits are open
+func (h *ShareMarketHandlers) GetShareMarketSettings(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.SharesResponse{
+			Success: false,
+			Error:   "User not authenticated",
+		})
+		return
+	}
+
+	settings, err := h.shareMarketService.GetSettings(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SharesResponse{
+		Success: true,
+		Data:    settings,
+	})
+}
+
+// UpdateShareMarketSettings changes the chama's lock-in period and which exits are open
+func (h *ShareMarketHandlers) UpdateShareMarketSettings(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.SharesResponse{
+			Success: false,
+			Error:   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.UpdateShareMarketSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	settings, err := h.shareMarketService.UpdateSettings(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SharesResponse{
+		Success: true,
+		Data:    settings,
+		Message: "Share market settings updated successfully",
+	})
+}
+
+// RedeemShares sells a member's shares back to the chama at the current price
+func (h *ShareMarketHandlers) RedeemShares(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.SharesResponse{
+			Success: false,
+			Error:   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.RedeemSharesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	redemption, err := h.shareMarketService.RedeemShares(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SharesResponse{
+		Success: true,
+		Data:    redemption,
+		Message: "Shares redeemed successfully",
+	})
+}
+
+// SplitShares splits or consolidates every active share in the chama
+func (h *ShareMarketHandlers) SplitShares(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.SharesResponse{
+			Success: false,
+			Error:   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.SplitSharesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	split, err := h.shareMarketService.SplitShares(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SharesResponse{
+		Success: true,
+		Data:    split,
+		Message: "Shares split successfully",
+	})
+}
+
+// CreateShareListing lists a member's shares for sale to other members
+func (h *ShareMarketHandlers) CreateShareListing(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.SharesResponse{
+			Success: false,
+			Error:   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.CreateShareListingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	listing, err := h.shareMarketService.CreateListing(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SharesResponse{
+		Success: true,
+		Data:    listing,
+		Message: "Shares listed for sale successfully",
+	})
+}
+
+// GetShareListings returns the chama's order book (?status= defaults to open)
+func (h *ShareMarketHandlers) GetShareListings(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	limit, offset := shareMarketPagination(c)
+
+	listings, err := h.shareMarketService.GetListings(c.Param("id"), userID, c.Query("status"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    listings,
+		"count":   len(listings),
+	})
+}
+
+// CancelShareListing withdraws the unsold part of a listing
+func (h *ShareMarketHandlers) CancelShareListing(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.SharesResponse{
+			Success: false,
+			Error:   "User not authenticated",
+		})
+		return
+	}
+
+	listing, err := h.shareMarketService.CancelListing(c.Param("id"), c.Param("listingId"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SharesResponse{
+		Success: true,
+		Data:    listing,
+		Message: "Share listing cancelled",
+	})
+}
+
+// BuyShareListing buys shares from another member's listing with wallet settlement
+func (h *ShareMarketHandlers) BuyShareListing(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.SharesResponse{
+			Success: false,
+			Error:   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.BuyShareListingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	share, err := h.shareMarketService.BuyFromListing(c.Param("id"), c.Param("listingId"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.SharesResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SharesResponse{
+		Success: true,
+		Data:    share,
+		Message: "Shares purchased successfully",
+	})
+}
+
+// shareMarketPagination reads limit/offset query parameters
+func shareMarketPagination(c *gin.Context) (int, int) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	return limit, offset
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StandingOrderHandlers handles recurring transfers from personal wallets
+type StandingOrderHandlers struct {
+	standingOrderService *services.StandingOrderService
+}
+
+// NewStandingOrderHandlers creates a new standing order handlers instance
+func NewStandingOrderHandlers(db *sql.DB) *StandingOrderHandlers {
+	return &StandingOrderHandlers{
+		standingOrderService: services.NewStandingOrderService(db),
+	}
+}
+
+// CreateStandingOrder schedules a recurring contribution to a chama or transfer to a member
+func (h *StandingOrderHandlers) CreateStandingOrder(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.CreateStandingOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	order, err := h.standingOrderService.CreateStandingOrder(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    order,
+		"message": "Standing order created successfully",
+	})
+}
+
+// GetStandingOrders lists the user's standing orders (?status=)
+func (h *StandingOrderHandlers) GetStandingOrders(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	orders, err := h.standingOrderService.GetStandingOrders(userID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    orders,
+		"count":   len(orders),
+	})
+}
+
+// GetStandingOrder returns one of the user's standing orders
+func (h *StandingOrderHandlers) GetStandingOrder(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	order, err := h.standingOrderService.GetStandingOrder(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    order,
+	})
+}
+
+// GetStandingOrderExecutions returns a standing order's run history (?limit=&offset=)
+func (h *StandingOrderHandlers) GetStandingOrderExecutions(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	limit, offset := shareMarketPagination(c)
+
+	executions, err := h.standingOrderService.GetExecutions(c.Param("id"), userID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    executions,
+		"count":   len(executions),
+	})
+}
+
+// PauseStandingOrder stops a standing order until it is resumed
+func (h *StandingOrderHandlers) PauseStandingOrder(c *gin.Context) {
+	h.changeStatus(c, h.standingOrderService.PauseStandingOrder, "Standing order paused")
+}
+
+// ResumeStandingOrder restarts a paused standing order from its next scheduled date
+func (h *StandingOrderHandlers) ResumeStandingOrder(c *gin.Context) {
+	h.changeStatus(c, h.standingOrderService.ResumeStandingOrder, "Standing order resumed")
+}
+
+// CancelStandingOrder permanently stops a standing order
+func (h *StandingOrderHandlers) CancelStandingOrder(c *gin.Context) {
+	h.changeStatus(c, h.standingOrderService.CancelStandingOrder, "Standing order cancelled")
+}
+
+func (h *StandingOrderHandlers) changeStatus(c *gin.Context, change func(orderID, userID string) (*models.StandingOrder, error), message string) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	order, err := change(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    order,
+		"message": message,
+	})
+}
@@ -310,14 +310,6 @@ func TransferMoney(c *gin.Context) {
 		return
 	}
 
-	if req.Amount > 1000000 { // 1M KES limit
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Amount exceeds maximum transfer limit of KES 1,000,000",
-		})
-		return
-	}
-
 	// Get database connection
 	db, exists := c.Get("db")
 	if !exists {
@@ -331,6 +323,24 @@ func TransferMoney(c *gin.Context) {
 	// Create wallet service
 	walletService := services.NewWalletService(db.(*sql.DB))
 
+	// Check the amount fits inside the sender's tier limits
+	limits, err := services.NewFeeService(db.(*sql.DB)).GetLimitStatus(userID.(string), req.Amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if !limits.Allowed {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Transfer exceeds your transaction limits: " + limits.Reason,
+			"data":    limits,
+		})
+		return
+	}
+
 	// Get sender's wallet
 	senderWalletID := "wallet-personal-" + userID.(string)
 	senderWallet, err := walletService.GetWalletByID(senderWalletID)
@@ -448,9 +458,14 @@ func TransferMoney(c *gin.Context) {
 	var recipientName, recipientPhone string
 	db.(*sql.DB).QueryRow("SELECT COALESCE(first_name, '') || ' ' || COALESCE(last_name, ''), phone FROM users WHERE id = ?", recipientUserID).Scan(&recipientName, &recipientPhone)
 
+	message := "Transfer completed successfully"
+	if held, _ := services.NewFraudService(db.(*sql.DB)).IsHeld(processedTransaction.ID); held {
+		message = "Transfer is being reviewed for your security. You will be notified once it goes through."
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Transfer completed successfully",
+		"message": message,
 		"data": gin.H{
 			"transactionId":   processedTransaction.ID,
 			"amount":          req.Amount,
@@ -777,6 +792,14 @@ func DepositMoney(c *gin.Context) {
 		return
 	}
 
+	if err := services.NewAuditService(db.(*sql.DB)).RecordTransaction(tx, "", transactionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to record transaction",
+		})
+		return
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -850,14 +873,6 @@ func WithdrawMoney(c *gin.Context) {
 		return
 	}
 
-	if req.Amount > 300000 { // 300K KES daily limit
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Amount exceeds maximum daily withdrawal limit of KES 300,000",
-		})
-		return
-	}
-
 	// Validate withdrawal method
 	if req.WithdrawMethod != "mpesa" && req.WithdrawMethod != "bank" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -917,15 +932,33 @@ func WithdrawMoney(c *gin.Context) {
 		return
 	}
 
-	// Calculate withdrawal fee (2% for M-Pesa, 1% for bank, min 10 KES)
-	var fee float64
-	if req.WithdrawMethod == "mpesa" {
-		fee = req.Amount * 0.02 // 2%
-	} else {
-		fee = req.Amount * 0.01 // 1%
+	// Check the amount fits inside the user's tier limits
+	feeService := services.NewFeeService(db.(*sql.DB))
+	limits, err := feeService.GetLimitStatus(userID.(string), req.Amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if !limits.Allowed {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Withdrawal exceeds your transaction limits: " + limits.Reason,
+			"data":    limits,
+		})
+		return
 	}
-	if fee < 10 {
-		fee = 10 // Minimum fee
+
+	// Calculate withdrawal fee from the fee schedule
+	fee, err := feeService.CalculateFee(models.TransactionTypeWithdrawal, models.PaymentMethod(req.WithdrawMethod), req.Amount)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to calculate withdrawal fee",
+		})
+		return
 	}
 
 	totalDeduction := req.Amount + fee
@@ -987,8 +1020,19 @@ func WithdrawMoney(c *gin.Context) {
 		return
 	}
 
+	// A withdrawal held for fraud review is paid out only once an admin releases it
+	message := "Withdrawal initiated successfully"
+	held, err := services.NewFraudService(db.(*sql.DB)).IsHeld(processedTransaction.ID)
+	if err != nil {
+		log.Printf("Failed to check fraud review for withdrawal %s: %v", processedTransaction.ID, err)
+		held = true
+	}
+	if held {
+		message = "Withdrawal is being reviewed for your security. You will be notified once it is approved."
+	}
+
 	// For M-Pesa withdrawals, initiate B2C transaction
-	if req.WithdrawMethod == "mpesa" {
+	if req.WithdrawMethod == "mpesa" && !held {
 		// Get configuration
 		cfg, exists := c.Get("config")
 		if !exists {
@@ -1021,13 +1065,13 @@ func WithdrawMoney(c *gin.Context) {
 	}
 
 	// For bank withdrawals, create a pending request for manual processing
-	if req.WithdrawMethod == "bank" {
+	if req.WithdrawMethod == "bank" && !held {
 		log.Printf("🏦 Bank withdrawal initiated: %s to %s-%s for KES %.2f", processedTransaction.ID, req.BankCode, req.BankAccountNumber, req.Amount)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Withdrawal initiated successfully",
+		"message": message,
 		"data": gin.H{
 			"transactionId":     processedTransaction.ID,
 			"amount":            req.Amount,
@@ -3,10 +3,15 @@ package api
 import (
 	"database/sql"
 	"fmt"
+	"log"
 	"math"
 	"net/http"
+	"os"
 	"time"
 
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -321,11 +326,20 @@ func CreateWelfareRequest(c *gin.Context) {
 	}
 
 	// Determine beneficiary - defaults to requester if not specified
-	beneficiaryID := userID
+	beneficiaryID := userID.(string)
 	if req.BeneficiaryID != nil && *req.BeneficiaryID != "" {
 		beneficiaryID = *req.BeneficiaryID
 	}
 
+	// Enforce the chama's per-member yearly welfare limits
+	if err := services.NewWelfareService(db.(*sql.DB)).CheckClaimLimits(req.ChamaID, beneficiaryID, req.Amount, ""); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
 	// Generate welfare request ID
 	welfareID := fmt.Sprintf("welfare-%d", time.Now().UnixNano())
 
@@ -366,27 +380,558 @@ func CreateWelfareRequest(c *gin.Context) {
 	})
 }
 
+// GetWelfareRequest returns a welfare request with its supporting documents
 func GetWelfareRequest(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	welfareService, ok := getWelfareService(c)
+	if !ok {
+		return
+	}
+
+	request, err := welfareService.GetRequest(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Get welfare request endpoint - coming soon",
+		"data":    request,
 	})
 }
 
+// UpdateWelfareRequest lets the requester edit a pending welfare request before voting starts
 func UpdateWelfareRequest(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.UpdateWelfareRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	welfareService, ok := getWelfareService(c)
+	if !ok {
+		return
+	}
+
+	request, err := welfareService.UpdateRequest(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Update welfare request endpoint - coming soon",
+		"data":    request,
+		"message": "Welfare request updated successfully",
 	})
 }
 
+// DeleteWelfareRequest deletes a pending welfare request and its supporting documents
 func DeleteWelfareRequest(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	welfareService, ok := getWelfareService(c)
+	if !ok {
+		return
+	}
+
+	// Stored documents are removed through the storage service once the request is gone
+	var fileIDs []string
+	if request, err := welfareService.GetRequest(c.Param("id"), userID); err == nil {
+		for _, document := range request.Documents {
+			if document.FileID != nil {
+				fileIDs = append(fileIDs, *document.FileID)
+			}
+		}
+	}
+
+	filePaths, err := welfareService.DeleteRequest(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	for _, filePath := range filePaths {
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove welfare document %s: %v", filePath, err)
+		}
+	}
+	if storageService, exists := c.Get("storageService"); exists {
+		for _, fileID := range fileIDs {
+			if err := storageService.(*services.StorageService).Delete(fileID); err != nil {
+				log.Printf("Failed to remove welfare document %s: %v", fileID, err)
+			}
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Delete welfare request endpoint - coming soon",
+		"message": "Welfare request deleted successfully",
 	})
 }
 
+// UploadWelfareDocument attaches a supporting document (e.g. a hospital invoice) to a welfare request
+func UploadWelfareDocument(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	welfareService, ok := getWelfareService(c)
+	if !ok {
+		return
+	}
+
+	if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Failed to parse multipart form: " + err.Error(),
+		})
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "No file provided: " + err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	if header.Size > 10*1024*1024 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "File too large. Maximum size is 10MB",
+		})
+		return
+	}
+
+	storageService, ok := getStorageService(c)
+	if !ok {
+		return
+	}
+
+	// Supporting documents are readable by the chama's members, like the request itself
+	request, err := welfareService.GetRequest(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	storedFile, err := storageService.Store(&services.StoreFileInput{
+		OwnerID:      userID,
+		FileName:     header.Filename,
+		Category:     models.FileCategoryWelfareDocument,
+		AccessScope:  models.FileAccessChama,
+		ScopeID:      request.ChamaID,
+		AllowedTypes: services.StorageDocumentTypes,
+		MaxSize:      10 * 1024 * 1024,
+	}, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	document, err := welfareService.AddDocument(c.Param("id"), userID, &models.WelfareDocument{
+		FileName:     header.Filename,
+		FileURL:      services.StoredFileURL(storedFile.ID),
+		FileSize:     storedFile.Size,
+		FileType:     storedFile.ContentType,
+		DocumentType: c.PostForm("documentType"),
+		Description:  c.PostForm("description"),
+		FileID:       &storedFile.ID,
+	})
+	if err != nil {
+		// Clean up uploaded file if the document cannot be attached
+		storageService.Delete(storedFile.ID)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    document,
+		"message": "Document uploaded successfully",
+	})
+}
+
+// GetWelfareDocuments lists the supporting documents of a welfare request
+func GetWelfareDocuments(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	welfareService, ok := getWelfareService(c)
+	if !ok {
+		return
+	}
+
+	documents, err := welfareService.GetDocuments(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    documents,
+		"count":   len(documents),
+	})
+}
+
+// DisburseWelfareRequest lets an official pay out an approved request from the welfare fund
+func DisburseWelfareRequest(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	welfareService, ok := getWelfareService(c)
+	if !ok {
+		return
+	}
+
+	request, err := welfareService.DisburseRequestAsOfficial(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    request,
+		"message": "Welfare payout disbursed successfully",
+	})
+}
+
+// GetWelfareFund returns the chama welfare fund balance and payouts awaiting funds
+func GetWelfareFund(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	welfareService, ok := getWelfareService(c)
+	if !ok {
+		return
+	}
+
+	fund, err := welfareService.GetFund(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    fund,
+	})
+}
+
+// ContributeToWelfareFund pays into the chama welfare fund from the member's wallet
+func ContributeToWelfareFund(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.WelfareFundContributionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	welfareService, ok := getWelfareService(c)
+	if !ok {
+		return
+	}
+
+	fund, err := welfareService.ContributeToFund(c.Param("id"), userID, req.Amount)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    fund,
+		"message": "Contribution to welfare fund received",
+	})
+}
+
+// GetWelfareSettings returns a chama's per-member welfare claim limits
+func GetWelfareSettings(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	welfareService, ok := getWelfareService(c)
+	if !ok {
+		return
+	}
+
+	settings, err := welfareService.GetSettings(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    settings,
+	})
+}
+
+// UpdateWelfareSettings configures a chama's per-member welfare claim limits
+func UpdateWelfareSettings(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.UpdateWelfareSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	welfareService, ok := getWelfareService(c)
+	if !ok {
+		return
+	}
+
+	settings, err := welfareService.UpdateSettings(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    settings,
+		"message": "Welfare settings updated successfully",
+	})
+}
+
+// GetWelfareApprovalRules lists a chama's approval thresholds per category and urgency
+func GetWelfareApprovalRules(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	welfareService, ok := getWelfareService(c)
+	if !ok {
+		return
+	}
+
+	rules, err := welfareService.GetApprovalRules(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rules,
+		"meta": map[string]interface{}{
+			"defaultApprovalPercentage": models.DefaultWelfareApprovalPercentage,
+		},
+	})
+}
+
+// SetWelfareApprovalRule creates or replaces the approval threshold for a category and urgency
+func SetWelfareApprovalRule(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.SetWelfareApprovalRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	welfareService, ok := getWelfareService(c)
+	if !ok {
+		return
+	}
+
+	rule, err := welfareService.SetApprovalRule(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rule,
+		"message": "Welfare approval rule saved",
+	})
+}
+
+// DeleteWelfareApprovalRule removes an approval threshold
+func DeleteWelfareApprovalRule(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	welfareService, ok := getWelfareService(c)
+	if !ok {
+		return
+	}
+
+	if err := welfareService.DeleteApprovalRule(c.Param("id"), c.Param("ruleId"), userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Welfare approval rule deleted",
+	})
+}
+
+// getWelfareService builds a welfare service from the request's database connection
+func getWelfareService(c *gin.Context) (*services.WelfareService, bool) {
+	db, exists := c.Get("db")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Database connection not available",
+		})
+		return nil, false
+	}
+	return services.NewWelfareService(db.(*sql.DB)), true
+}
+
 func VoteOnWelfareRequest(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("userID")
@@ -612,7 +1157,7 @@ func VoteOnWelfareRequest(c *gin.Context) {
 		return
 	}
 
-	// Check if all members have voted and close voting if necessary
+	// Close voting once the outcome is decided under the chama's approval threshold
 	var totalMembers int
 	err = db.(*sql.DB).QueryRow(`
 		SELECT COUNT(*) FROM chama_members
@@ -622,21 +1167,18 @@ func VoteOnWelfareRequest(c *gin.Context) {
 		// Log error but don't fail the response
 		fmt.Printf("Failed to get total chama members: %v\n", err)
 	} else {
-		totalVotes := yesVotes + noVotes
+		welfareService := services.NewWelfareService(db.(*sql.DB))
+		newStatus, decided, resolveErr := welfareService.ResolveVote(welfareID, yesVotes, noVotes, totalMembers)
+		if resolveErr != nil {
+			fmt.Printf("Failed to resolve welfare vote: %v\n", resolveErr)
+		}
 
-		// Check if all members have voted
-		if totalVotes >= totalMembers {
-			// All members have voted, determine the result
-			var newStatus string
+		if decided {
 			var statusMessage string
-
-			// Simple majority rule: more than 50% yes votes = approved
-			if yesVotes > noVotes {
-				newStatus = "approved"
-				statusMessage = fmt.Sprintf("Approved by majority vote (%d yes, %d no)", yesVotes, noVotes)
+			if newStatus == models.WelfareRequestStatusApproved {
+				statusMessage = fmt.Sprintf("Approved by member vote (%d yes, %d no)", yesVotes, noVotes)
 			} else {
-				newStatus = "rejected"
-				statusMessage = fmt.Sprintf("Rejected by majority vote (%d yes, %d no)", yesVotes, noVotes)
+				statusMessage = fmt.Sprintf("Rejected by member vote (%d yes, %d no)", yesVotes, noVotes)
 			}
 
 			// Update welfare request status
@@ -674,6 +1216,13 @@ func VoteOnWelfareRequest(c *gin.Context) {
 			if err != nil {
 				fmt.Printf("Failed to create vote result notification: %v\n", err)
 			}
+
+			// Pay the beneficiary straight away; bereavement support cannot wait
+			if newStatus == models.WelfareRequestStatusApproved {
+				if _, err := welfareService.ProcessApproval(welfareID, userID.(string)); err != nil {
+					fmt.Printf("Welfare request %s approved but not yet paid: %v\n", welfareID, err)
+				}
+			}
 		}
 	}
 
@@ -1043,6 +1592,9 @@ func CheckAndCloseCompletedWelfareVotes(chamaID string, db *sql.DB) {
 		return
 	}
 
+	welfareService := services.NewWelfareService(db)
+	approved := map[string]string{} // welfare request ID -> requester ID
+
 	for rows.Next() {
 		var welfareID, welfareRequestChamaID, requesterID, status, voteID string
 		err := rows.Scan(&welfareID, &welfareRequestChamaID, &requesterID, &status, &voteID)
@@ -1063,20 +1615,18 @@ func CheckAndCloseCompletedWelfareVotes(chamaID string, db *sql.DB) {
 			continue
 		}
 
-		totalVotes := yesVotes + noVotes
+		newStatus, decided, err := welfareService.ResolveVote(welfareID, yesVotes, noVotes, totalMembers)
+		if err != nil {
+			fmt.Printf("Failed to resolve welfare vote for %s: %v\n", welfareID, err)
+			continue
+		}
 
-		// Check if all members have voted
-		if totalVotes >= totalMembers {
-			// Determine the result
-			var newStatus string
+		if decided {
 			var statusMessage string
-
-			if yesVotes > noVotes {
-				newStatus = "approved"
-				statusMessage = fmt.Sprintf("Approved by majority vote (%d yes, %d no)", yesVotes, noVotes)
+			if newStatus == models.WelfareRequestStatusApproved {
+				statusMessage = fmt.Sprintf("Approved by member vote (%d yes, %d no)", yesVotes, noVotes)
 			} else {
-				newStatus = "rejected"
-				statusMessage = fmt.Sprintf("Rejected by majority vote (%d yes, %d no)", yesVotes, noVotes)
+				statusMessage = fmt.Sprintf("Rejected by member vote (%d yes, %d no)", yesVotes, noVotes)
 			}
 
 			// Update welfare request status
@@ -1117,6 +1667,18 @@ func CheckAndCloseCompletedWelfareVotes(chamaID string, db *sql.DB) {
 			}
 
 			fmt.Printf("Auto-closed welfare vote for request %s with status: %s\n", welfareID, newStatus)
+
+			if newStatus == models.WelfareRequestStatusApproved {
+				approved[welfareID] = requesterID
+			}
+		}
+	}
+	rows.Close()
+
+	// Pay out after the result set is closed so disbursements can write to the database
+	for welfareID, requesterID := range approved {
+		if _, err := welfareService.ProcessApproval(welfareID, requesterID); err != nil {
+			fmt.Printf("Welfare request %s approved but not yet paid: %v\n", welfareID, err)
 		}
 	}
 }
@@ -0,0 +1,312 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RaiseWelfareLevy raises a per-member levy for an approved welfare case
+func RaiseWelfareLevy(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.RaiseWelfareLevyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	levyService, ok := getWelfareLevyService(c)
+	if !ok {
+		return
+	}
+
+	levy, err := levyService.RaiseLevy(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    levy,
+		"message": "Welfare levy raised and members notified",
+	})
+}
+
+// GetWelfareLevies lists a chama's welfare levies (?status=)
+func GetWelfareLevies(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	levyService, ok := getWelfareLevyService(c)
+	if !ok {
+		return
+	}
+
+	levies, err := levyService.GetChamaLevies(c.Param("id"), userID, c.Query("status"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    levies,
+		"count":   len(levies),
+	})
+}
+
+// GetWelfareLevy returns a levy with who has and has not paid
+func GetWelfareLevy(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	levyService, ok := getWelfareLevyService(c)
+	if !ok {
+		return
+	}
+
+	levy, err := levyService.GetLevy(c.Param("id"), c.Param("levyId"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    levy,
+	})
+}
+
+// PayWelfareLevy pays the member's share of a levy from their wallet
+func PayWelfareLevy(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	levyService, ok := getWelfareLevyService(c)
+	if !ok {
+		return
+	}
+
+	assessment, err := levyService.PayAssessment(c.Param("id"), c.Param("levyId"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    assessment,
+		"message": "Welfare levy paid successfully",
+	})
+}
+
+// RemindWelfareLevy reminds members who have not paid a levy
+func RemindWelfareLevy(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	levyService, ok := getWelfareLevyService(c)
+	if !ok {
+		return
+	}
+
+	sent, err := levyService.SendReminders(c.Param("id"), c.Param("levyId"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"remindersSent": sent},
+		"message": "Reminders sent to members who have not paid",
+	})
+}
+
+// CancelWelfareLevy cancels an active levy
+func CancelWelfareLevy(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	levyService, ok := getWelfareLevyService(c)
+	if !ok {
+		return
+	}
+
+	levy, err := levyService.CancelLevy(c.Param("id"), c.Param("levyId"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    levy,
+		"message": "Welfare levy cancelled",
+	})
+}
+
+// GetWelfareLevyConsent returns the member's consent to wallet deductions for levies
+func GetWelfareLevyConsent(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	levyService, ok := getWelfareLevyService(c)
+	if !ok {
+		return
+	}
+
+	consent, err := levyService.GetConsent(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    consent,
+	})
+}
+
+// UpdateWelfareLevyConsent grants or withdraws consent to deduct levies from the member's wallet
+func UpdateWelfareLevyConsent(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
+	var req models.UpdateLevyConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request data: " + err.Error(),
+		})
+		return
+	}
+
+	levyService, ok := getWelfareLevyService(c)
+	if !ok {
+		return
+	}
+
+	consent, err := levyService.UpdateConsent(c.Param("id"), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	message := "Levies will no longer be deducted from your wallet"
+	if consent.Granted {
+		message = "Levies will be deducted from your wallet automatically"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    consent,
+		"message": message,
+	})
+}
+
+// getWelfareLevyService builds a welfare levy service from the request's database connection
+func getWelfareLevyService(c *gin.Context) (*services.WelfareLevyService, bool) {
+	db, exists := c.Get("db")
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Database connection not available",
+		})
+		return nil, false
+	}
+	return services.NewWelfareLevyService(db.(*sql.DB)), true
+}
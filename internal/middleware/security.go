@@ -250,9 +250,13 @@ func FileUploadSecurityMiddleware() gin.HandlerFunc {
 					// Different allowed types based on endpoint
 					var allowedTypes map[string]bool
 
-					// Check if this is a meeting document upload
-					if strings.Contains(c.Request.URL.Path, "/meetings/") && strings.Contains(c.Request.URL.Path, "/documents") {
-						// Allow more file types for meeting documents
+					// Check if this is a document upload (meeting, welfare and KYC documents,
+					// dispute evidence) or a chat attachment. The storage service checks the
+					// actual content of what is kept.
+					path := c.Request.URL.Path
+					if strings.Contains(path, "/documents") || strings.Contains(path, "/evidence") ||
+						(strings.Contains(path, "/chat/") && strings.Contains(path, "/messages")) {
+						// Allow more file types for documents
 						allowedTypes = map[string]bool{
 							"image/jpeg":         true,
 							"image/jpg":          true,
@@ -0,0 +1,266 @@
+package models
+
+import (
+	"time"
+)
+
+// LedgerAccountType is the class of a ledger account. Assets and expenses normally
+// carry debit balances; liabilities, equity and income carry credit balances.
+type LedgerAccountType string
+
+const (
+	LedgerAccountAsset     LedgerAccountType = "asset"
+	LedgerAccountLiability LedgerAccountType = "liability"
+	LedgerAccountEquity    LedgerAccountType = "equity"
+	LedgerAccountIncome    LedgerAccountType = "income"
+	LedgerAccountExpense   LedgerAccountType = "expense"
+)
+
+// DebitNormal reports whether accounts of this type increase with debits
+func (t LedgerAccountType) DebitNormal() bool {
+	return t == LedgerAccountAsset || t == LedgerAccountExpense
+}
+
+// LedgerEntryType is a kind of chama activity that posts to the ledger. A chama's
+// chart of accounts maps each one to the account it posts to; the other side of
+// every entry is the cash account, except welfare fund movements, which never pass
+// through the chama wallet.
+type LedgerEntryType string
+
+const (
+	LedgerEntryCash              LedgerEntryType = "cash"
+	LedgerEntryContribution      LedgerEntryType = "contribution"
+	LedgerEntryShareCapital      LedgerEntryType = "share_capital"
+	LedgerEntryFine              LedgerEntryType = "fine"
+	LedgerEntryLoan              LedgerEntryType = "loan"
+	LedgerEntryLoanRepayment     LedgerEntryType = "loan_repayment"
+	LedgerEntryLoanInterest      LedgerEntryType = "loan_interest"
+	LedgerEntryExpense           LedgerEntryType = "expense"
+	LedgerEntryDividend          LedgerEntryType = "dividend"
+	LedgerEntryInvestment        LedgerEntryType = "investment"
+	LedgerEntryInvestmentIncome  LedgerEntryType = "investment_income"
+	LedgerEntryInvestmentExpense LedgerEntryType = "investment_expense"
+	LedgerEntryRetainedEarnings  LedgerEntryType = "retained_earnings"
+	LedgerEntryWelfareFund       LedgerEntryType = "welfare_fund"
+	LedgerEntryWelfareReserve    LedgerEntryType = "welfare_reserve"
+)
+
+// LedgerAccount is an account in a chama's chart of accounts
+type LedgerAccount struct {
+	ID          string            `json:"id" db:"id"`
+	ChamaID     string            `json:"chamaId" db:"chama_id"`
+	Code        string            `json:"code" db:"code"`
+	Name        string            `json:"name" db:"name"`
+	Type        LedgerAccountType `json:"type" db:"account_type"`
+	Description *string           `json:"description,omitempty" db:"description"`
+	IsActive    bool              `json:"isActive" db:"is_active"`
+	CreatedAt   time.Time         `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time         `json:"updatedAt" db:"updated_at"`
+}
+
+// LedgerAccountMapping records which account an entry type posts to
+type LedgerAccountMapping struct {
+	EntryType   LedgerEntryType `json:"entryType" db:"entry_type"`
+	AccountID   string          `json:"accountId" db:"account_id"`
+	AccountCode string          `json:"accountCode"`
+	AccountName string          `json:"accountName"`
+}
+
+// ChartOfAccounts is a chama's ledger accounts and how its activity maps to them
+type ChartOfAccounts struct {
+	ChamaID  string                 `json:"chamaId"`
+	Accounts []LedgerAccount        `json:"accounts"`
+	Mappings []LedgerAccountMapping `json:"mappings"`
+}
+
+// JournalLine is one side of a journal entry
+type JournalLine struct {
+	AccountCode string            `json:"accountCode"`
+	AccountName string            `json:"accountName"`
+	AccountType LedgerAccountType `json:"accountType"`
+	Debit       float64           `json:"debit"`
+	Credit      float64           `json:"credit"`
+}
+
+// JournalEntry is a balanced double entry derived from a chama record, such as a
+// contribution, a loan disbursement or a dividend payment. Source and SourceID name
+// the record it came from.
+type JournalEntry struct {
+	ID          string          `json:"id"`
+	Date        time.Time       `json:"date"`
+	EntryType   LedgerEntryType `json:"entryType"`
+	Source      string          `json:"source"`
+	SourceID    string          `json:"sourceId"`
+	Reference   *string         `json:"reference,omitempty"`
+	Description string          `json:"description"`
+	Lines       []JournalLine   `json:"lines"`
+}
+
+// GeneralLedgerLine is one posting to an account. Balance is the running balance
+// on the account's normal side.
+type GeneralLedgerLine struct {
+	Date        time.Time `json:"date"`
+	EntryID     string    `json:"entryId"`
+	Reference   *string   `json:"reference,omitempty"`
+	Description string    `json:"description"`
+	Debit       float64   `json:"debit"`
+	Credit      float64   `json:"credit"`
+	Balance     float64   `json:"balance"`
+}
+
+// GeneralLedgerAccount is an account's postings for a period
+type GeneralLedgerAccount struct {
+	Code           string              `json:"code"`
+	Name           string              `json:"name"`
+	Type           LedgerAccountType   `json:"type"`
+	OpeningBalance float64             `json:"openingBalance"`
+	Lines          []GeneralLedgerLine `json:"lines"`
+	TotalDebits    float64             `json:"totalDebits"`
+	TotalCredits   float64             `json:"totalCredits"`
+	ClosingBalance float64             `json:"closingBalance"`
+}
+
+// GeneralLedger lists every account's postings between From and To (exclusive)
+type GeneralLedger struct {
+	ChamaID  string                 `json:"chamaId"`
+	From     time.Time              `json:"from"`
+	To       time.Time              `json:"to"`
+	Accounts []GeneralLedgerAccount `json:"accounts"`
+}
+
+// TrialBalanceLine is an account's closing balance in the debit or credit column
+type TrialBalanceLine struct {
+	Code   string            `json:"code"`
+	Name   string            `json:"name"`
+	Type   LedgerAccountType `json:"type"`
+	Debit  float64           `json:"debit"`
+	Credit float64           `json:"credit"`
+}
+
+// TrialBalance lists account balances at the end of a period. Debits and credits
+// agree whenever every entry balances.
+type TrialBalance struct {
+	ChamaID      string             `json:"chamaId"`
+	AsOf         time.Time          `json:"asOf"`
+	Lines        []TrialBalanceLine `json:"lines"`
+	TotalDebits  float64            `json:"totalDebits"`
+	TotalCredits float64            `json:"totalCredits"`
+	Balanced     bool               `json:"balanced"`
+}
+
+// FinancialStatementLine is an account's amount on an income statement or balance sheet
+type FinancialStatementLine struct {
+	Code   string  `json:"code"`
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+}
+
+// IncomeStatement reports a chama's income and expenses for a period
+type IncomeStatement struct {
+	ChamaID       string                   `json:"chamaId"`
+	From          time.Time                `json:"from"`
+	To            time.Time                `json:"to"`
+	Income        []FinancialStatementLine `json:"income"`
+	Expenses      []FinancialStatementLine `json:"expenses"`
+	TotalIncome   float64                  `json:"totalIncome"`
+	TotalExpenses float64                  `json:"totalExpenses"`
+	NetIncome     float64                  `json:"netIncome"`
+}
+
+// BalanceSheet reports what a chama owns and owes at a date. Income and expenses not
+// yet closed into retained earnings appear in equity as current earnings.
+type BalanceSheet struct {
+	ChamaID          string                   `json:"chamaId"`
+	AsOf             time.Time                `json:"asOf"`
+	Assets           []FinancialStatementLine `json:"assets"`
+	Liabilities      []FinancialStatementLine `json:"liabilities"`
+	Equity           []FinancialStatementLine `json:"equity"`
+	CurrentEarnings  float64                  `json:"currentEarnings"`
+	TotalAssets      float64                  `json:"totalAssets"`
+	TotalLiabilities float64                  `json:"totalLiabilities"`
+	TotalEquity      float64                  `json:"totalEquity"`
+	Balanced         bool                     `json:"balanced"`
+}
+
+// Accounting export formats. QuickBooks and Xero formats are journal imports.
+const (
+	AccountingExportCSV        = "csv"
+	AccountingExportQuickBooks = "quickbooks"
+	AccountingExportXero       = "xero"
+)
+
+// CreateLedgerAccountRequest adds an account to a chama's chart of accounts
+type CreateLedgerAccountRequest struct {
+	Code        string  `json:"code" binding:"required,max=20"`
+	Name        string  `json:"name" binding:"required,max=100"`
+	Type        string  `json:"type" binding:"required,oneof=asset liability equity income expense"`
+	Description *string `json:"description,omitempty" binding:"omitempty,max=500"`
+}
+
+// UpdateLedgerAccountRequest renames or deactivates an account. Its code and type
+// are fixed once created, since past reports refer to them.
+type UpdateLedgerAccountRequest struct {
+	Name        *string `json:"name,omitempty" binding:"omitempty,max=100"`
+	Description *string `json:"description,omitempty" binding:"omitempty,max=500"`
+	IsActive    *bool   `json:"isActive,omitempty"`
+}
+
+// SetLedgerMappingRequest points an entry type at a different account
+type SetLedgerMappingRequest struct {
+	EntryType string `json:"entryType" binding:"required,oneof=cash contribution share_capital fine loan loan_repayment loan_interest expense dividend investment investment_income investment_expense retained_earnings"`
+	AccountID string `json:"accountId" binding:"required"`
+}
+
+// FinancialPeriodType is the length of a financial period
+type FinancialPeriodType string
+
+const (
+	FinancialPeriodMonthly FinancialPeriodType = "monthly"
+	FinancialPeriodAnnual  FinancialPeriodType = "annual"
+)
+
+// FinancialPeriodStatus is whether a period accepts changes. Periods are recorded
+// when first closed; a reopened period accepts changes until it is closed again.
+type FinancialPeriodStatus string
+
+const (
+	FinancialPeriodClosed   FinancialPeriodStatus = "closed"
+	FinancialPeriodReopened FinancialPeriodStatus = "reopened"
+)
+
+// FinancialPeriod is a month or year of a chama's books. While it is closed,
+// transactions, loans, repayments and dividend payments dated inside it cannot be
+// added, changed or removed.
+type FinancialPeriod struct {
+	ID                      string                `json:"id" db:"id"`
+	ChamaID                 string                `json:"chamaId" db:"chama_id"`
+	PeriodType              FinancialPeriodType   `json:"periodType" db:"period_type"`
+	StartDate               time.Time             `json:"startDate" db:"start_date"`
+	EndDate                 time.Time             `json:"endDate" db:"end_date"`
+	Status                  FinancialPeriodStatus `json:"status" db:"status"`
+	ClosedBy                string                `json:"closedBy" db:"closed_by"`
+	ClosedAt                time.Time             `json:"closedAt" db:"closed_at"`
+	ReopenedBy              *string               `json:"reopenedBy,omitempty" db:"reopened_by"`
+	ReopenedAt              *time.Time            `json:"reopenedAt,omitempty" db:"reopened_at"`
+	ReopenReason            *string               `json:"reopenReason,omitempty" db:"reopen_reason"`
+	NetIncome               float64               `json:"netIncome" db:"net_income"`
+	OpeningRetainedEarnings float64               `json:"openingRetainedEarnings" db:"opening_retained_earnings"`
+	ClosingRetainedEarnings float64               `json:"closingRetainedEarnings" db:"closing_retained_earnings"`
+	ClosingBalances         []TrialBalanceLine    `json:"closingBalances,omitempty" db:"closing_balances"`
+	ClosingEntry            *JournalEntry         `json:"closingEntry,omitempty" db:"closing_entry"`
+	CreatedAt               time.Time             `json:"createdAt" db:"created_at"`
+	UpdatedAt               time.Time             `json:"updatedAt" db:"updated_at"`
+}
+
+// CloseFinancialPeriodRequest closes a month (Month 1-12) or a year (Month omitted)
+type CloseFinancialPeriodRequest struct {
+	PeriodType string `json:"periodType" binding:"required,oneof=monthly annual"`
+	Year       int    `json:"year" binding:"required,min=2000,max=2100"`
+	Month      int    `json:"month,omitempty" binding:"omitempty,min=1,max=12"`
+}
+
+// ReopenFinancialPeriodRequest reopens a closed period; the reason is kept in the
+// audit log
+type ReopenFinancialPeriodRequest struct {
+	Reason string `json:"reason" binding:"required,min=10,max=500"`
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"time"
+)
+
+// ApologyStatus represents the status of a meeting apology
+type ApologyStatus string
+
+const (
+	ApologyStatusAccepted ApologyStatus = "accepted"
+	ApologyStatusLate     ApologyStatus = "late"
+	ApologyStatusRejected ApologyStatus = "rejected"
+)
+
+// FineType represents the reason a member was fined
+type FineType string
+
+const (
+	FineTypeAbsence  FineType = "absence"
+	FineTypeLateness FineType = "lateness"
+)
+
+// FineStatus represents the status of a member fine
+type FineStatus string
+
+const (
+	FineStatusOutstanding FineStatus = "outstanding"
+	FineStatusPaid        FineStatus = "paid"
+	FineStatusWaived      FineStatus = "waived"
+)
+
+// AttendanceFineRules represents a chama's attendance fine configuration
+type AttendanceFineRules struct {
+	ChamaID              string     `json:"chamaId" db:"chama_id"`
+	IsEnabled            bool       `json:"isEnabled" db:"is_enabled"`
+	AbsenceFine          float64    `json:"absenceFine" db:"absence_fine"`
+	ExcusedAbsenceFine   float64    `json:"excusedAbsenceFine" db:"excused_absence_fine"`
+	LatenessFine         float64    `json:"latenessFine" db:"lateness_fine"`
+	LatenessGraceMinutes int        `json:"latenessGraceMinutes" db:"lateness_grace_minutes"`
+	ApologyDeadlineHours int        `json:"apologyDeadlineHours" db:"apology_deadline_hours"`
+	UpdatedBy            *string    `json:"updatedBy,omitempty" db:"updated_by"`
+	UpdatedAt            *time.Time `json:"updatedAt,omitempty" db:"updated_at"`
+}
+
+// MeetingApology represents an apology for absence submitted by a member
+type MeetingApology struct {
+	ID          string        `json:"id" db:"id"`
+	MeetingID   string        `json:"meetingId" db:"meeting_id"`
+	ChamaID     string        `json:"chamaId" db:"chama_id"`
+	UserID      string        `json:"userId" db:"user_id"`
+	MemberName  string        `json:"memberName,omitempty"`
+	Reason      string        `json:"reason" db:"reason"`
+	Status      ApologyStatus `json:"status" db:"status"`
+	SubmittedAt time.Time     `json:"submittedAt" db:"submitted_at"`
+	ReviewedBy  *string       `json:"reviewedBy,omitempty" db:"reviewed_by"`
+	ReviewedAt  *time.Time    `json:"reviewedAt,omitempty" db:"reviewed_at"`
+}
+
+// MemberFine represents a fine posted to a member's chama account
+type MemberFine struct {
+	ID            string     `json:"id" db:"id"`
+	ChamaID       string     `json:"chamaId" db:"chama_id"`
+	UserID        string     `json:"userId" db:"user_id"`
+	MemberName    string     `json:"memberName,omitempty"`
+	MeetingID     *string    `json:"meetingId,omitempty" db:"meeting_id"`
+	FineType      FineType   `json:"fineType" db:"fine_type"`
+	Amount        float64    `json:"amount" db:"amount"`
+	Reason        string     `json:"reason" db:"reason"`
+	Status        FineStatus `json:"status" db:"status"`
+	TransactionID *string    `json:"transactionId,omitempty" db:"transaction_id"`
+	PaidAt        *time.Time `json:"paidAt,omitempty" db:"paid_at"`
+	WaivedBy      *string    `json:"waivedBy,omitempty" db:"waived_by"`
+	WaivedAt      *time.Time `json:"waivedAt,omitempty" db:"waived_at"`
+	WaiverReason  *string    `json:"waiverReason,omitempty" db:"waiver_reason"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// MemberAttendanceStats represents a member's attendance over a period
+type MemberAttendanceStats struct {
+	UserID           string  `json:"userId"`
+	MemberName       string  `json:"memberName"`
+	Role             string  `json:"role"`
+	MeetingsHeld     int     `json:"meetingsHeld"`
+	Attended         int     `json:"attended"`
+	Late             int     `json:"late"`
+	Excused          int     `json:"excused"`
+	Absent           int     `json:"absent"`
+	AttendanceRate   float64 `json:"attendanceRate"`
+	FinesIssued      float64 `json:"finesIssued"`
+	FinesOutstanding float64 `json:"finesOutstanding"`
+}
+
+// AttendanceReport represents chama attendance analytics for a period
+type AttendanceReport struct {
+	ChamaID           string                  `json:"chamaId"`
+	From              time.Time               `json:"from"`
+	To                time.Time               `json:"to"`
+	MeetingsHeld      int                     `json:"meetingsHeld"`
+	AverageAttendance float64                 `json:"averageAttendance"`
+	TotalFinesIssued  float64                 `json:"totalFinesIssued"`
+	TotalFinesPaid    float64                 `json:"totalFinesPaid"`
+	Members           []MemberAttendanceStats `json:"members"`
+}
+
+// UpdateAttendanceFineRulesRequest represents the request to configure attendance fines
+type UpdateAttendanceFineRulesRequest struct {
+	IsEnabled            *bool    `json:"isEnabled,omitempty"`
+	AbsenceFine          *float64 `json:"absenceFine,omitempty" binding:"omitempty,min=0"`
+	ExcusedAbsenceFine   *float64 `json:"excusedAbsenceFine,omitempty" binding:"omitempty,min=0"`
+	LatenessFine         *float64 `json:"latenessFine,omitempty" binding:"omitempty,min=0"`
+	LatenessGraceMinutes *int     `json:"latenessGraceMinutes,omitempty" binding:"omitempty,min=0,max=240"`
+	ApologyDeadlineHours *int     `json:"apologyDeadlineHours,omitempty" binding:"omitempty,min=0,max=168"`
+}
+
+// SubmitApologyRequest represents the request to submit an apology for absence
+type SubmitApologyRequest struct {
+	Reason string `json:"reason" binding:"required,min=3,max=500"`
+}
+
+// ReviewApologyRequest represents the request to accept or reject an apology
+type ReviewApologyRequest struct {
+	Status ApologyStatus `json:"status" binding:"required,oneof=accepted rejected"`
+}
+
+// WaiveFineRequest represents the request to waive a fine
+type WaiveFineRequest struct {
+	Reason string `json:"reason" binding:"required,min=3,max=500"`
+}
+
+// IsValidFineStatus checks if the fine status is valid
+func IsValidFineStatus(status string) bool {
+	switch FineStatus(status) {
+	case FineStatusOutstanding, FineStatusPaid, FineStatusWaived:
+		return true
+	default:
+		return false
+	}
+}
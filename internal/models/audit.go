@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditPlatformChain is the audit chain for events that do not belong to a chama
+const AuditPlatformChain = "platform"
+
+// AuditGenesisHash is the previous hash of the first entry in every audit chain
+const AuditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// AuditAction represents the kind of event recorded in the audit log
+type AuditAction string
+
+const (
+	AuditActionMoneyMovement  AuditAction = "money_movement"
+	AuditActionDeclaration    AuditAction = "declaration"
+	AuditActionRoleChange     AuditAction = "role_change"
+	AuditActionApproval       AuditAction = "approval"
+	AuditActionSettingsChange AuditAction = "settings_change"
+	AuditActionSecurityEvent  AuditAction = "security_event"
+)
+
+// AuditEntry represents one append-only, hash-chained audit log entry.
+// EntryHash covers every field plus PreviousHash, so editing or removing
+// an entry breaks the chain from that point on.
+type AuditEntry struct {
+	ID           string                 `json:"id" db:"id"`
+	ChainID      string                 `json:"chainId" db:"chain_id"`
+	Sequence     int64                  `json:"sequence" db:"sequence"`
+	ActorID      *string                `json:"actorId,omitempty" db:"actor_id"`
+	ActorName    string                 `json:"actorName,omitempty"`
+	Action       AuditAction            `json:"action" db:"action"`
+	EntityType   string                 `json:"entityType" db:"entity_type"`
+	EntityID     string                 `json:"entityId" db:"entity_id"`
+	Amount       *float64               `json:"amount,omitempty" db:"amount"`
+	Details      map[string]interface{} `json:"details,omitempty" db:"details"`
+	PreviousHash string                 `json:"previousHash" db:"previous_hash"`
+	EntryHash    string                 `json:"entryHash" db:"entry_hash"`
+	CreatedAt    time.Time              `json:"createdAt" db:"created_at"`
+}
+
+// AuditCheckpoint is a signed statement of the head of an audit chain that
+// members can keep and later check against the live log
+type AuditCheckpoint struct {
+	ID        string    `json:"id" db:"id"`
+	ChainID   string    `json:"chainId" db:"chain_id" binding:"required"`
+	Sequence  int64     `json:"sequence" db:"sequence" binding:"required"`
+	EntryHash string    `json:"entryHash" db:"entry_hash" binding:"required"`
+	Signature string    `json:"signature" db:"signature" binding:"required"`
+	PublicKey string    `json:"publicKey" db:"public_key" binding:"required"`
+	CreatedBy *string   `json:"createdBy,omitempty" db:"created_by"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at" binding:"required"`
+}
+
+// AuditChainBreak describes the first entry where an audit chain fails verification
+type AuditChainBreak struct {
+	Sequence int64  `json:"sequence"`
+	EntryID  string `json:"entryId,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// AuditVerification reports the result of recomputing an audit chain
+type AuditVerification struct {
+	ChainID            string           `json:"chainId"`
+	Valid              bool             `json:"valid"`
+	EntriesChecked     int              `json:"entriesChecked"`
+	CheckpointsChecked int              `json:"checkpointsChecked"`
+	HeadSequence       int64            `json:"headSequence"`
+	HeadHash           string           `json:"headHash"`
+	FirstBreak         *AuditChainBreak `json:"firstBreak,omitempty"`
+	VerifiedAt         time.Time        `json:"verifiedAt"`
+}
+
+// AuditCheckpointVerification reports whether a kept checkpoint is genuine and still matches the log
+type AuditCheckpointVerification struct {
+	SignatureValid bool   `json:"signatureValid"`
+	MatchesChain   bool   `json:"matchesChain"`
+	Reason         string `json:"reason,omitempty"`
+}
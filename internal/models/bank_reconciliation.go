@@ -0,0 +1,173 @@
+package models
+
+import (
+	"time"
+)
+
+// BankStatementFormat is the file format a bank statement was imported from
+type BankStatementFormat string
+
+const (
+	BankStatementCSV     BankStatementFormat = "csv"
+	BankStatementOFX     BankStatementFormat = "ofx"
+	BankStatementCAMT053 BankStatementFormat = "camt053"
+)
+
+// BankLineStatus represents whether a statement line has been reconciled. An excluded
+// line is one officials have explained without a recorded transaction, such as a
+// transfer between the chama's own accounts.
+type BankLineStatus string
+
+const (
+	BankLineUnmatched BankLineStatus = "unmatched"
+	BankLineMatched   BankLineStatus = "matched"
+	BankLineExcluded  BankLineStatus = "excluded"
+)
+
+// BankMatchMethod records how a statement line was matched
+type BankMatchMethod string
+
+const (
+	BankMatchAuto   BankMatchMethod = "auto"
+	BankMatchManual BankMatchMethod = "manual"
+)
+
+// ChamaBankAccount is a bank account a chama holds outside VaultKe. The opening
+// balance is the bank balance on the day reconciliation starts.
+type ChamaBankAccount struct {
+	ID                 string    `json:"id" db:"id"`
+	ChamaID            string    `json:"chamaId" db:"chama_id"`
+	BankName           string    `json:"bankName" db:"bank_name"`
+	AccountName        string    `json:"accountName" db:"account_name"`
+	AccountNumber      string    `json:"accountNumber" db:"account_number"`
+	Currency           string    `json:"currency" db:"currency"`
+	OpeningBalance     float64   `json:"openingBalance" db:"opening_balance"`
+	OpeningBalanceDate time.Time `json:"openingBalanceDate" db:"opening_balance_date"`
+	IsActive           bool      `json:"isActive" db:"is_active"`
+	CreatedBy          string    `json:"createdBy" db:"created_by"`
+	CreatedAt          time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt          time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// BankStatement is one imported statement file
+type BankStatement struct {
+	ID             string              `json:"id" db:"id"`
+	AccountID      string              `json:"accountId" db:"account_id"`
+	ChamaID        string              `json:"chamaId" db:"chama_id"`
+	FileID         *string             `json:"fileId,omitempty" db:"file_id"`
+	FileName       string              `json:"fileName" db:"file_name"`
+	Format         BankStatementFormat `json:"format" db:"format"`
+	PeriodStart    *time.Time          `json:"periodStart,omitempty" db:"period_start"`
+	PeriodEnd      *time.Time          `json:"periodEnd,omitempty" db:"period_end"`
+	OpeningBalance *float64            `json:"openingBalance,omitempty" db:"opening_balance"`
+	ClosingBalance *float64            `json:"closingBalance,omitempty" db:"closing_balance"`
+	LineCount      int                 `json:"lineCount" db:"line_count"`
+	DuplicateCount int                 `json:"duplicateCount" db:"duplicate_count"`
+	ImportedBy     string              `json:"importedBy" db:"imported_by"`
+	ImportedAt     time.Time           `json:"importedAt" db:"imported_at"`
+}
+
+// BankStatementLine is one entry on a bank statement. Amount is positive for money
+// into the account and negative for money out.
+type BankStatementLine struct {
+	ID              string           `json:"id" db:"id"`
+	StatementID     string           `json:"statementId" db:"statement_id"`
+	AccountID       string           `json:"accountId" db:"account_id"`
+	ChamaID         string           `json:"chamaId" db:"chama_id"`
+	LineNumber      int              `json:"lineNumber" db:"line_number"`
+	TransactionDate time.Time        `json:"transactionDate" db:"transaction_date"`
+	ValueDate       *time.Time       `json:"valueDate,omitempty" db:"value_date"`
+	Description     string           `json:"description" db:"description"`
+	Reference       *string          `json:"reference,omitempty" db:"reference"`
+	ExternalID      string           `json:"externalId" db:"external_id"`
+	Amount          float64          `json:"amount" db:"amount"`
+	Balance         *float64         `json:"balance,omitempty" db:"balance"`
+	Status          BankLineStatus   `json:"status" db:"status"`
+	MatchMethod     *BankMatchMethod `json:"matchMethod,omitempty" db:"match_method"`
+	ExclusionReason *string          `json:"exclusionReason,omitempty" db:"exclusion_reason"`
+	ReconciledBy    *string          `json:"reconciledBy,omitempty" db:"reconciled_by"`
+	ReconciledAt    *time.Time       `json:"reconciledAt,omitempty" db:"reconciled_at"`
+	TransactionIDs  []string         `json:"transactionIds"`
+	CreatedAt       time.Time        `json:"createdAt" db:"created_at"`
+}
+
+// BookTransaction is a recorded chama transaction as the reconciliation sees it.
+// SignedAmount is positive for money into the chama and negative for money out.
+type BookTransaction struct {
+	ID            string    `json:"id"`
+	Type          string    `json:"type"`
+	Description   *string   `json:"description,omitempty"`
+	Reference     *string   `json:"reference,omitempty"`
+	PaymentMethod string    `json:"paymentMethod"`
+	BankAccountID *string   `json:"bankAccountId,omitempty"` // the bank account it went through, once assigned
+	Amount        float64   `json:"amount"`
+	SignedAmount  float64   `json:"signedAmount"`
+	Date          time.Time `json:"date"`
+}
+
+// BankMatchCandidate is a recorded transaction that could explain a statement line,
+// ranked by how closely its amount, date and reference agree
+type BankMatchCandidate struct {
+	BookTransaction
+	Score          int  `json:"score"`
+	AmountMatches  bool `json:"amountMatches"`
+	DaysApart      int  `json:"daysApart"`
+	ReferenceMatch bool `json:"referenceMatch"`
+}
+
+// BankStatementImport is the outcome of importing a statement. Duplicates are lines
+// already imported from an overlapping statement; lines dated before the account's
+// opening balance are skipped because the opening balance already includes them.
+type BankStatementImport struct {
+	Statement     BankStatement `json:"statement"`
+	Imported      int           `json:"imported"`
+	Duplicates    int           `json:"duplicates"`
+	BeforeOpening int           `json:"beforeOpening"`
+	AutoMatched   int           `json:"autoMatched"`
+}
+
+// BankReconciliationReport compares a bank account's balance with the chama's books
+// as of a date. The bank balance is the one the bank printed on the latest statement
+// line, or the opening balance plus imported lines when statements carry no running
+// balance. Outstanding book items are bank transfers recorded in VaultKe that have
+// not yet appeared on a statement; unmatched bank lines are the reverse.
+// UnexplainedDifference is what remains once both are taken into account; it is
+// non-zero when statement lines are missing, such as a gap between imports.
+type BankReconciliationReport struct {
+	AccountID             string              `json:"accountId"`
+	ChamaID               string              `json:"chamaId"`
+	AsOf                  time.Time           `json:"asOf"`
+	BankBalance           float64             `json:"bankBalance"`
+	BookBalance           float64             `json:"bookBalance"`
+	Difference            float64             `json:"difference"`
+	UnmatchedBankLines    []BankStatementLine `json:"unmatchedBankLines"`
+	UnmatchedBankTotal    float64             `json:"unmatchedBankTotal"`
+	ExcludedBankTotal     float64             `json:"excludedBankTotal"`
+	OutstandingBookItems  []BookTransaction   `json:"outstandingBookItems"`
+	OutstandingBookTotal  float64             `json:"outstandingBookTotal"`
+	UnexplainedDifference float64             `json:"unexplainedDifference"`
+	MatchedLines          int                 `json:"matchedLines"`
+	Reconciled            bool                `json:"reconciled"`
+}
+
+// CreateBankAccountRequest registers a chama bank account for reconciliation
+type CreateBankAccountRequest struct {
+	BankName           string    `json:"bankName" binding:"required,max=100"`
+	AccountName        string    `json:"accountName" binding:"required,max=200"`
+	AccountNumber      string    `json:"accountNumber" binding:"required,max=50"`
+	Currency           string    `json:"currency" binding:"omitempty,len=3"`
+	OpeningBalance     float64   `json:"openingBalance"`
+	OpeningBalanceDate time.Time `json:"openingBalanceDate" binding:"required"`
+}
+
+// MatchBankLineRequest matches a statement line to one or more recorded
+// transactions whose amounts add up to it, e.g. several contributions banked as
+// one deposit
+type MatchBankLineRequest struct {
+	TransactionIDs []string `json:"transactionIds" binding:"required,min=1,max=50"`
+}
+
+// ExcludeBankLineRequest explains a statement line that has no recorded transaction
+type ExcludeBankLineRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"time"
+)
+
+// Currencies wallets can be held in. KES is the settlement currency: personal and chama
+// wallets, fees and limits are all in shillings unless stated otherwise.
+const (
+	CurrencyKES = "KES"
+	CurrencyUGX = "UGX"
+	CurrencyTZS = "TZS"
+	CurrencyUSD = "USD"
+)
+
+// DefaultCurrency is the currency of wallets that do not ask for another one
+const DefaultCurrency = CurrencyKES
+
+// SupportedCurrencies lists every currency a wallet or exchange rate may use
+var SupportedCurrencies = []string{CurrencyKES, CurrencyUGX, CurrencyTZS, CurrencyUSD}
+
+// IsSupportedCurrency reports whether code is one of SupportedCurrencies
+func IsSupportedCurrency(code string) bool {
+	for _, currency := range SupportedCurrencies {
+		if currency == code {
+			return true
+		}
+	}
+	return false
+}
+
+// ExchangeRateSource records where a rate came from
+type ExchangeRateSource string
+
+const (
+	ExchangeRateSourceManual ExchangeRateSource = "manual"
+	ExchangeRateSourceImport ExchangeRateSource = "import"
+)
+
+// ExchangeRate is the mid-market price of one unit of BaseCurrency in QuoteCurrency.
+// SpreadPercent is taken off the mid rate when money is converted at this rate.
+type ExchangeRate struct {
+	ID            string             `json:"id" db:"id"`
+	BaseCurrency  string             `json:"baseCurrency" db:"base_currency"`
+	QuoteCurrency string             `json:"quoteCurrency" db:"quote_currency"`
+	Rate          float64            `json:"rate" db:"rate"`
+	SpreadPercent float64            `json:"spreadPercent" db:"spread_percent"`
+	Source        ExchangeRateSource `json:"source" db:"source"`
+	EffectiveAt   time.Time          `json:"effectiveAt" db:"effective_at"`
+	CreatedBy     string             `json:"createdBy" db:"created_by"`
+	CreatedAt     time.Time          `json:"createdAt" db:"created_at"`
+}
+
+// FXQuote is the price of converting Amount of FromCurrency into ToCurrency. AppliedRate
+// is MidRate less the spread; SpreadAmount is what the spread costs, in ToCurrency.
+type FXQuote struct {
+	FromCurrency    string    `json:"fromCurrency"`
+	ToCurrency      string    `json:"toCurrency"`
+	Amount          float64   `json:"amount"`
+	MidRate         float64   `json:"midRate"`
+	SpreadPercent   float64   `json:"spreadPercent"`
+	AppliedRate     float64   `json:"appliedRate"`
+	ConvertedAmount float64   `json:"convertedAmount"`
+	SpreadAmount    float64   `json:"spreadAmount"`
+	RateDate        time.Time `json:"rateDate"`
+}
+
+// CurrencyConversion is a completed conversion between two wallets
+type CurrencyConversion struct {
+	TransactionID string   `json:"transactionId"`
+	FromWalletID  string   `json:"fromWalletId"`
+	ToWalletID    string   `json:"toWalletId"`
+	RecipientID   *string  `json:"recipientId,omitempty"`
+	Quote         *FXQuote `json:"quote"`
+}
+
+// CurrencyBalance is one currency's balance expressed in a reporting currency. Rate and
+// ConvertedBalance are nil when no exchange rate is available for the currency.
+type CurrencyBalance struct {
+	Currency         string   `json:"currency"`
+	Balance          float64  `json:"balance"`
+	WalletCount      int      `json:"walletCount"`
+	Rate             *float64 `json:"rate,omitempty"`
+	ConvertedBalance *float64 `json:"convertedBalance,omitempty"`
+}
+
+// ConsolidatedBalances totals balances held in several currencies in BaseCurrency at
+// mid-market rates. Currencies without a rate are listed in MissingRates and left out of
+// Total.
+type ConsolidatedBalances struct {
+	OwnerID      string             `json:"ownerId"`
+	BaseCurrency string             `json:"baseCurrency"`
+	Balances     []*CurrencyBalance `json:"balances"`
+	Total        float64            `json:"total"`
+	MissingRates []string           `json:"missingRates,omitempty"`
+	GeneratedAt  time.Time          `json:"generatedAt"`
+}
+
+// SetExchangeRateRequest represents an admin publishing a rate for a currency pair
+type SetExchangeRateRequest struct {
+	BaseCurrency  string  `json:"baseCurrency" binding:"required,len=3"`
+	QuoteCurrency string  `json:"quoteCurrency" binding:"required,len=3"`
+	Rate          float64 `json:"rate" binding:"required,gt=0"`
+	SpreadPercent float64 `json:"spreadPercent" binding:"min=0,max=10"`
+	EffectiveAt   string  `json:"effectiveAt,omitempty"` // RFC3339, defaults to now
+}
+
+// ImportExchangeRatesRequest represents a batch of rates loaded from a rates feed
+type ImportExchangeRatesRequest struct {
+	Rates []SetExchangeRateRequest `json:"rates" binding:"required,min=1,dive"`
+}
+
+// OpenCurrencyWalletRequest represents opening a wallet in another currency
+type OpenCurrencyWalletRequest struct {
+	Currency string `json:"currency" binding:"required,len=3"`
+}
+
+// ConvertCurrencyRequest represents moving money between two of a user's own wallets
+type ConvertCurrencyRequest struct {
+	FromCurrency string  `json:"fromCurrency" binding:"required,len=3"`
+	ToCurrency   string  `json:"toCurrency" binding:"required,len=3"`
+	Amount       float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// FXTransferRequest represents sending money to another member, converting it into
+// ToCurrency on the way, or contributing it to a chama, whose wallet is held in KES.
+// Exactly one of RecipientID and ChamaID is set; ToCurrency defaults to FromCurrency.
+type FXTransferRequest struct {
+	RecipientID  string  `json:"recipientId,omitempty"`
+	ChamaID      string  `json:"chamaId,omitempty"`
+	FromCurrency string  `json:"fromCurrency" binding:"required,len=3"`
+	ToCurrency   string  `json:"toCurrency,omitempty"`
+	Amount       float64 `json:"amount" binding:"required,gt=0"`
+	Description  string  `json:"description" binding:"max=200"`
+}
+
+// SetBaseCurrencyRequest represents an official changing a chama's reporting currency
+type SetBaseCurrencyRequest struct {
+	Currency string `json:"currency" binding:"required,len=3"`
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"time"
+)
+
+// DisputeStatus represents where a dispute is in its lifecycle
+type DisputeStatus string
+
+const (
+	DisputeStatusOpen        DisputeStatus = "open"         // waiting for the counterparty to respond
+	DisputeStatusUnderReview DisputeStatus = "under_review" // contested or unanswered, waiting for an admin
+	DisputeStatusResolved    DisputeStatus = "resolved"
+	DisputeStatusWithdrawn   DisputeStatus = "withdrawn"
+)
+
+// DisputeReason represents why a member disputes a transaction
+type DisputeReason string
+
+const (
+	DisputeReasonWrongRecipient DisputeReason = "wrong_recipient"
+	DisputeReasonWrongAmount    DisputeReason = "wrong_amount"
+	DisputeReasonUnauthorized   DisputeReason = "unauthorized"
+	DisputeReasonDuplicate      DisputeReason = "duplicate"
+	DisputeReasonOther          DisputeReason = "other"
+)
+
+// DisputeResolution represents how a dispute was settled
+type DisputeResolution string
+
+const (
+	DisputeResolutionFullRefund    DisputeResolution = "full_refund"
+	DisputeResolutionPartialRefund DisputeResolution = "partial_refund"
+	DisputeResolutionNoRefund      DisputeResolution = "no_refund"
+)
+
+// Dispute is a member's claim against a transfer they made. The disputed amount is
+// held from the counterparty's wallet until the counterparty accepts or an admin
+// resolves it; any refund is posted as a refund transaction linked to the original.
+type Dispute struct {
+	ID                   string             `json:"id" db:"id"`
+	TransactionID        string             `json:"transactionId" db:"transaction_id"`
+	RaisedBy             string             `json:"raisedBy" db:"raised_by"`
+	RaisedByName         string             `json:"raisedByName,omitempty"`
+	CounterpartyID       string             `json:"counterpartyId" db:"counterparty_id"`
+	CounterpartyName     string             `json:"counterpartyName,omitempty"`
+	Reason               DisputeReason      `json:"reason" db:"reason"`
+	Description          string             `json:"description" db:"description"`
+	TransactionAmount    float64            `json:"transactionAmount" db:"transaction_amount"`
+	Amount               float64            `json:"amount" db:"amount"`
+	HeldAmount           float64            `json:"heldAmount" db:"held_amount"`
+	HoldWalletID         *string            `json:"holdWalletId,omitempty" db:"hold_wallet_id"`
+	Status               DisputeStatus      `json:"status" db:"status"`
+	ResponseDeadline     time.Time          `json:"responseDeadline" db:"response_deadline"`
+	CounterpartyAccepted *bool              `json:"counterpartyAccepted,omitempty" db:"counterparty_accepted"`
+	CounterpartyResponse *string            `json:"counterpartyResponse,omitempty" db:"counterparty_response"`
+	RespondedAt          *time.Time         `json:"respondedAt,omitempty" db:"responded_at"`
+	Resolution           *DisputeResolution `json:"resolution,omitempty" db:"resolution"`
+	RefundAmount         float64            `json:"refundAmount" db:"refund_amount"`
+	RefundTransactionID  *string            `json:"refundTransactionId,omitempty" db:"refund_transaction_id"`
+	ResolvedBy           *string            `json:"resolvedBy,omitempty" db:"resolved_by"`
+	ResolvedAt           *time.Time         `json:"resolvedAt,omitempty" db:"resolved_at"`
+	ResolutionNotes      *string            `json:"resolutionNotes,omitempty" db:"resolution_notes"`
+	Evidence             []*DisputeEvidence `json:"evidence"`
+	CreatedAt            time.Time          `json:"createdAt" db:"created_at"`
+	UpdatedAt            time.Time          `json:"updatedAt" db:"updated_at"`
+}
+
+// IsActive reports whether the dispute is still waiting to be settled
+func (d *Dispute) IsActive() bool {
+	return d.Status == DisputeStatusOpen || d.Status == DisputeStatusUnderReview
+}
+
+// DisputeEvidence is a file either party or an admin attached to a dispute
+type DisputeEvidence struct {
+	ID         string    `json:"id" db:"id"`
+	DisputeID  string    `json:"disputeId" db:"dispute_id"`
+	UploadedBy string    `json:"uploadedBy" db:"uploaded_by"`
+	FileName   string    `json:"fileName" db:"file_name"`
+	FilePath   string    `json:"-" db:"file_path"`
+	FileID     *string   `json:"-" db:"file_id"` // set for evidence kept by the storage service
+	FileSize   int64     `json:"fileSize" db:"file_size"`
+	FileType   string    `json:"fileType" db:"file_type"`
+	Note       *string   `json:"note,omitempty" db:"note"`
+	UploadedAt time.Time `json:"uploadedAt" db:"uploaded_at"`
+}
+
+// OpenDisputeRequest represents a member disputing one of their transfers. Amount
+// defaults to the whole transaction.
+type OpenDisputeRequest struct {
+	TransactionID string   `json:"transactionId" binding:"required"`
+	Reason        string   `json:"reason" binding:"required,oneof=wrong_recipient wrong_amount unauthorized duplicate other"`
+	Description   string   `json:"description" binding:"required,max=2000"`
+	Amount        *float64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// RespondDisputeRequest represents the counterparty accepting or contesting a dispute
+type RespondDisputeRequest struct {
+	Accept  bool   `json:"accept"`
+	Message string `json:"message" binding:"max=2000"`
+}
+
+// ResolveDisputeRequest represents an admin settling a dispute. RefundAmount is only
+// used for partial refunds.
+type ResolveDisputeRequest struct {
+	Resolution   string   `json:"resolution" binding:"required,oneof=full_refund partial_refund no_refund"`
+	RefundAmount *float64 `json:"refundAmount" binding:"omitempty,gt=0"`
+	Notes        string   `json:"notes" binding:"required,max=1000"`
+}
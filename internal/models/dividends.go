@@ -23,6 +23,26 @@ const (
 	DividendPaymentFailed  DividendPaymentStatus = "failed"
 )
 
+// DividendComputationMethod decides which shareholding a dividend is paid on
+type DividendComputationMethod string
+
+const (
+	// DividendMethodSnapshot pays on the shares each member held at the record date
+	DividendMethodSnapshot DividendComputationMethod = "snapshot"
+	// DividendMethodTimeWeighted pays on each member's average holding over the
+	// financial period, so shares bought late in the period earn proportionally less
+	DividendMethodTimeWeighted DividendComputationMethod = "time_weighted"
+)
+
+// DividendElection is how a member wants their dividends paid
+type DividendElection string
+
+const (
+	DividendElectionCash     DividendElection = "cash"
+	DividendElectionReinvest DividendElection = "reinvest" // all of it buys shares
+	DividendElectionSplit    DividendElection = "split"    // ReinvestPercent of it buys shares
+)
+
 // DividendDeclaration represents a dividend declaration for a chama
 type DividendDeclaration struct {
 	ID                  string                    `json:"id" db:"id"`
@@ -36,6 +56,11 @@ type DividendDeclaration struct {
 	ApprovedBy          *string                   `json:"approvedBy,omitempty" db:"approved_by"`
 	Description         *string                   `json:"description,omitempty" db:"description"`
 	DividendType        string                    `json:"dividendType,omitempty" db:"dividend_type"`
+	RecordDate          *time.Time                `json:"recordDate,omitempty" db:"record_date"`
+	ComputationMethod   DividendComputationMethod `json:"computationMethod" db:"computation_method"`
+	PeriodStart         *time.Time                `json:"periodStart,omitempty" db:"period_start"`
+	PeriodEnd           *time.Time                `json:"periodEnd,omitempty" db:"period_end"`
+	WithholdingTaxRate  float64                   `json:"withholdingTaxRate" db:"withholding_tax_rate"` // percent
 	CreatedAt           time.Time                 `json:"createdAt" db:"created_at"`
 	UpdatedAt           time.Time                 `json:"updatedAt" db:"updated_at"`
 }
@@ -51,13 +76,22 @@ type DividendDeclarationWithDetails struct {
 	PendingAmount     float64 `json:"pendingAmount"`
 }
 
-// DividendPayment represents an individual dividend payment to a member
+// DividendPayment represents an individual dividend payment to a member.
+// DividendAmount is the net amount due after withholding tax; once paid it is
+// split between ReinvestedAmount, which bought shares, and CashAmount.
 type DividendPayment struct {
 	ID                     string                `json:"id" db:"id"`
 	DividendDeclarationID  string                `json:"dividendDeclarationId" db:"dividend_declaration_id"`
 	MemberID               string                `json:"memberId" db:"member_id"`
 	SharesEligible         int                   `json:"sharesEligible" db:"shares_eligible"`
+	EligibleShares         float64               `json:"eligibleShares" db:"eligible_shares"`
+	GrossAmount            float64               `json:"grossAmount" db:"gross_amount"`
+	WithholdingTax         float64               `json:"withholdingTax" db:"withholding_tax"`
 	DividendAmount         float64               `json:"dividendAmount" db:"dividend_amount"`
+	ReinvestedShares       int                   `json:"reinvestedShares" db:"reinvested_shares"`
+	ReinvestedAmount       float64               `json:"reinvestedAmount" db:"reinvested_amount"`
+	ReinvestmentShareID    *string               `json:"reinvestmentShareId,omitempty" db:"reinvestment_share_id"`
+	CashAmount             float64               `json:"cashAmount" db:"cash_amount"`
 	PaymentStatus          DividendPaymentStatus `json:"paymentStatus" db:"payment_status"`
 	PaymentDate            *time.Time            `json:"paymentDate,omitempty" db:"payment_date"`
 	PaymentMethod          *string               `json:"paymentMethod,omitempty" db:"payment_method"`
@@ -74,13 +108,20 @@ type DividendPaymentWithMemberInfo struct {
 	MemberPhone string `json:"memberPhone"`
 }
 
-// CreateDividendDeclarationRequest represents the request to declare dividends
+// CreateDividendDeclarationRequest represents the request to declare dividends.
+// RecordDate defaults to the declaration time. Time-weighted dividends need
+// PeriodStart; PeriodEnd defaults to the record date.
 type CreateDividendDeclarationRequest struct {
 	DividendPerShare    float64    `json:"dividendPerShare" binding:"required,min=0"`
 	TotalDividendAmount float64    `json:"totalDividendAmount" binding:"required,min=0"`
 	PaymentDate         *time.Time `json:"paymentDate,omitempty"`
 	Description         *string    `json:"description,omitempty" binding:"omitempty,max=500"`
 	DividendType        string     `json:"DividendType,omitempty" binding:"omitempty,oneof=cash share"`
+	RecordDate          *time.Time `json:"recordDate,omitempty"`
+	ComputationMethod   string     `json:"computationMethod,omitempty" binding:"omitempty,oneof=snapshot time_weighted"`
+	PeriodStart         *time.Time `json:"periodStart,omitempty"`
+	PeriodEnd           *time.Time `json:"periodEnd,omitempty"`
+	WithholdingTaxRate  float64    `json:"withholdingTaxRate,omitempty" binding:"omitempty,min=0,max=100"`
 }
 
 // UpdateDividendDeclarationRequest represents the request to update dividend declaration
@@ -92,10 +133,115 @@ type UpdateDividendDeclarationRequest struct {
 	Description         *string                    `json:"description,omitempty" binding:"omitempty,max=500"`
 }
 
-// ProcessDividendPaymentsRequest represents the request to process dividend payments
+// ProcessDividendPaymentsRequest represents the request to process dividend payments.
+// Reinvested dividends buy shares from ReinvestmentOfferingID, or from the chama's
+// latest active offering when it is not given.
 type ProcessDividendPaymentsRequest struct {
-	PaymentMethod string `json:"paymentMethod" binding:"required,oneof=bank_transfer mobile_money cash"`
-	PaymentDate   *time.Time `json:"paymentDate,omitempty"`
+	PaymentMethod          string     `json:"paymentMethod" binding:"required,oneof=bank_transfer mobile_money cash"`
+	PaymentDate            *time.Time `json:"paymentDate,omitempty"`
+	ReinvestmentOfferingID *string    `json:"reinvestmentOfferingId,omitempty"`
+}
+
+// DividendReinvestmentElection is a member's standing instruction for their
+// dividends in a chama. Members without one are paid in cash.
+type DividendReinvestmentElection struct {
+	ChamaID         string           `json:"chamaId" db:"chama_id"`
+	MemberID        string           `json:"memberId" db:"member_id"`
+	Election        DividendElection `json:"election" db:"election"`
+	ReinvestPercent float64          `json:"reinvestPercent" db:"reinvest_percent"`
+	UpdatedAt       *time.Time       `json:"updatedAt,omitempty" db:"updated_at"`
+}
+
+// SetDividendReinvestmentRequest changes a member's reinvestment election.
+// ReinvestPercent is only used, and required, for a split election.
+type SetDividendReinvestmentRequest struct {
+	Election        string  `json:"election" binding:"required,oneof=cash reinvest split"`
+	ReinvestPercent float64 `json:"reinvestPercent,omitempty" binding:"omitempty,gt=0,lt=100"`
+}
+
+// DividendReinvestmentEntry is one dividend payment in a reinvestment statement
+type DividendReinvestmentEntry struct {
+	PaymentID        string    `json:"paymentId"`
+	DeclarationID    string    `json:"declarationId"`
+	PaymentDate      time.Time `json:"paymentDate"`
+	NetDividend      float64   `json:"netDividend"`
+	SharesAcquired   int       `json:"sharesAcquired"`
+	PricePerShare    float64   `json:"pricePerShare"`
+	ReinvestedAmount float64   `json:"reinvestedAmount"`
+	CashAmount       float64   `json:"cashAmount"`
+}
+
+// DividendReinvestmentStatement summarises a member's dividends paid in a year and
+// how much of them was reinvested
+type DividendReinvestmentStatement struct {
+	ChamaID             string                       `json:"chamaId"`
+	MemberID            string                       `json:"memberId"`
+	MemberName          string                       `json:"memberName"`
+	Year                int                          `json:"year"`
+	Election            DividendReinvestmentElection `json:"election"`
+	Entries             []DividendReinvestmentEntry  `json:"entries"`
+	TotalDividends      float64                      `json:"totalDividends"`
+	TotalReinvested     float64                      `json:"totalReinvested"`
+	TotalCash           float64                      `json:"totalCash"`
+	TotalSharesAcquired int                          `json:"totalSharesAcquired"`
+}
+
+// DividendEntitlement is what one member is due under a declaration
+type DividendEntitlement struct {
+	MemberID           string  `json:"memberId"`
+	MemberName         string  `json:"memberName"`
+	SharesAtRecordDate int     `json:"sharesAtRecordDate"`
+	EligibleShares     float64 `json:"eligibleShares"` // the holding the dividend is paid on
+	GrossAmount        float64 `json:"grossAmount"`
+	WithholdingTax     float64 `json:"withholdingTax"`
+	NetAmount          float64 `json:"netAmount"`
+}
+
+// DividendPreview shows each member's payout under a declaration before it is
+// approved. A preview taken before the record date is provisional: it uses
+// today's holdings and will change if shares move before then.
+type DividendPreview struct {
+	DeclarationID       string                    `json:"declarationId"`
+	ChamaID             string                    `json:"chamaId"`
+	ComputationMethod   DividendComputationMethod `json:"computationMethod"`
+	RecordDate          time.Time                 `json:"recordDate"`
+	PeriodStart         *time.Time                `json:"periodStart,omitempty"`
+	PeriodEnd           *time.Time                `json:"periodEnd,omitempty"`
+	DividendPerShare    float64                   `json:"dividendPerShare"`
+	WithholdingTaxRate  float64                   `json:"withholdingTaxRate"`
+	DeclaredAmount      float64                   `json:"declaredAmount"`
+	Provisional         bool                      `json:"provisional"`
+	Entitlements        []DividendEntitlement     `json:"entitlements"`
+	TotalEligibleShares float64                   `json:"totalEligibleShares"`
+	TotalGross          float64                   `json:"totalGross"`
+	TotalWithholdingTax float64                   `json:"totalWithholdingTax"`
+	TotalNet            float64                   `json:"totalNet"`
+}
+
+// DividendTaxReportRow is the tax withheld from one member's dividend payment
+type DividendTaxReportRow struct {
+	DeclarationID  string                `json:"declarationId"`
+	RecordDate     time.Time             `json:"recordDate"`
+	MemberID       string                `json:"memberId"`
+	MemberName     string                `json:"memberName"`
+	TaxRate        float64               `json:"taxRate"`
+	GrossAmount    float64               `json:"grossAmount"`
+	WithholdingTax float64               `json:"withholdingTax"`
+	NetAmount      float64               `json:"netAmount"`
+	PaymentStatus  DividendPaymentStatus `json:"paymentStatus"`
+	PaymentDate    *time.Time            `json:"paymentDate,omitempty"`
+}
+
+// DividendTaxReport lists the withholding tax deducted from approved dividends
+// with a record date in [From, To), for remitting to the tax authority
+type DividendTaxReport struct {
+	ChamaID             string                 `json:"chamaId"`
+	From                time.Time              `json:"from"`
+	To                  time.Time              `json:"to"`
+	Rows                []DividendTaxReportRow `json:"rows"`
+	TotalGross          float64                `json:"totalGross"`
+	TotalWithholdingTax float64                `json:"totalWithholdingTax"`
+	TotalNet            float64                `json:"totalNet"`
 }
 
 // DividendSummary represents dividend summary for a member
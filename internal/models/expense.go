@@ -0,0 +1,171 @@
+package models
+
+import (
+	"time"
+)
+
+// ExpenseCategory classifies chama running costs. Budget lines are set per category.
+type ExpenseCategory string
+
+const (
+	ExpenseCategoryVenue            ExpenseCategory = "venue"
+	ExpenseCategoryRefreshments     ExpenseCategory = "refreshments"
+	ExpenseCategoryTransport        ExpenseCategory = "transport"
+	ExpenseCategoryStationery       ExpenseCategory = "stationery"
+	ExpenseCategoryCommunication    ExpenseCategory = "communication"
+	ExpenseCategoryBankCharges      ExpenseCategory = "bank_charges"
+	ExpenseCategoryProfessionalFees ExpenseCategory = "professional_fees"
+	ExpenseCategoryOther            ExpenseCategory = "other"
+)
+
+// ExpenseStatus represents where an expense claim is in its approval and payment
+type ExpenseStatus string
+
+const (
+	ExpenseStatusSubmitted ExpenseStatus = "submitted"
+	ExpenseStatusApproved  ExpenseStatus = "approved"
+	ExpenseStatusRejected  ExpenseStatus = "rejected"
+	ExpenseStatusPaid      ExpenseStatus = "paid"
+	ExpenseStatusCancelled ExpenseStatus = "cancelled"
+)
+
+// Expense is a claim for a chama running cost, such as a meeting's venue hire. When
+// the claimant paid out of pocket, payment reimburses their personal wallet;
+// otherwise it is recorded as paid out of the chama wallet to the vendor.
+type Expense struct {
+	ID                string          `json:"id" db:"id"`
+	ChamaID           string          `json:"chamaId" db:"chama_id"`
+	Category          ExpenseCategory `json:"category" db:"category"`
+	BudgetLineID      *string         `json:"budgetLineId,omitempty" db:"budget_line_id"`
+	Title             string          `json:"title" db:"title"`
+	Description       *string         `json:"description,omitempty" db:"description"`
+	Vendor            *string         `json:"vendor,omitempty" db:"vendor"`
+	Amount            float64         `json:"amount" db:"amount"`
+	ExpenseDate       time.Time       `json:"expenseDate" db:"expense_date"`
+	MeetingID         *string         `json:"meetingId,omitempty" db:"meeting_id"`
+	PaidByClaimant    bool            `json:"paidByClaimant" db:"paid_by_claimant"`
+	ReceiptFileID     *string         `json:"receiptFileId,omitempty" db:"receipt_file_id"`
+	Status            ExpenseStatus   `json:"status" db:"status"`
+	ApprovalsRequired int             `json:"approvalsRequired" db:"approvals_required"`
+	ReceiptRequired   bool            `json:"receiptRequired" db:"receipt_required"`
+	ApprovalCount     int             `json:"approvalCount" db:"approval_count"`
+	SubmittedBy       string          `json:"submittedBy" db:"submitted_by"`
+	SubmitterName     string          `json:"submitterName,omitempty"`
+	RejectedBy        *string         `json:"rejectedBy,omitempty" db:"rejected_by"`
+	RejectionReason   *string         `json:"rejectionReason,omitempty" db:"rejection_reason"`
+	PaidBy            *string         `json:"paidBy,omitempty" db:"paid_by"`
+	PaidAt            *time.Time      `json:"paidAt,omitempty" db:"paid_at"`
+	TransactionID     *string         `json:"transactionId,omitempty" db:"transaction_id"`
+	CreatedAt         time.Time       `json:"createdAt" db:"created_at"`
+	UpdatedAt         time.Time       `json:"updatedAt" db:"updated_at"`
+}
+
+// ExpenseApproval is one official's decision on an expense claim
+type ExpenseApproval struct {
+	ID           string    `json:"id" db:"id"`
+	ExpenseID    string    `json:"expenseId" db:"expense_id"`
+	ApproverID   string    `json:"approverId" db:"approver_id"`
+	ApproverName string    `json:"approverName,omitempty"`
+	Decision     string    `json:"decision" db:"decision"`
+	Comment      *string   `json:"comment,omitempty" db:"comment"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// ExpenseDetail is an expense with its approval trail
+type ExpenseDetail struct {
+	Expense
+	Approvals []ExpenseApproval `json:"approvals"`
+}
+
+// ExpenseBudgetLine is the amount a chama budgets for a category in a year
+type ExpenseBudgetLine struct {
+	ID         string          `json:"id" db:"id"`
+	ChamaID    string          `json:"chamaId" db:"chama_id"`
+	FiscalYear int             `json:"fiscalYear" db:"fiscal_year"`
+	Category   ExpenseCategory `json:"category" db:"category"`
+	Name       string          `json:"name" db:"name"`
+	Amount     float64         `json:"amount" db:"amount"`
+	CreatedBy  string          `json:"createdBy" db:"created_by"`
+	CreatedAt  time.Time       `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time       `json:"updatedAt" db:"updated_at"`
+}
+
+// ExpenseApprovalRule sets how many officials must approve claims of at least
+// MinAmount. The rule with the highest MinAmount not above a claim applies; a chama
+// without rules needs one official other than the claimant.
+type ExpenseApprovalRule struct {
+	ID                string    `json:"id" db:"id"`
+	ChamaID           string    `json:"chamaId" db:"chama_id"`
+	MinAmount         float64   `json:"minAmount" db:"min_amount"`
+	ApprovalsRequired int       `json:"approvalsRequired" db:"approvals_required"`
+	ReceiptRequired   bool      `json:"receiptRequired" db:"receipt_required"`
+	CreatedBy         string    `json:"createdBy" db:"created_by"`
+	UpdatedAt         time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// Default expense approval when a chama has not configured any rules
+const (
+	DefaultExpenseApprovalsRequired = 1
+	DefaultExpenseReceiptRequired   = true
+)
+
+// BudgetVsActualLine compares a category's budget with what was spent. Committed is
+// approved but not yet paid; Variance is budget less actual and committed.
+type BudgetVsActualLine struct {
+	Category     ExpenseCategory `json:"category"`
+	BudgetLineID *string         `json:"budgetLineId,omitempty"`
+	Name         string          `json:"name"`
+	Budgeted     float64         `json:"budgeted"`
+	Actual       float64         `json:"actual"`
+	Committed    float64         `json:"committed"`
+	Variance     float64         `json:"variance"`
+	PercentUsed  float64         `json:"percentUsed"`
+	OverBudget   bool            `json:"overBudget"`
+	ExpenseCount int             `json:"expenseCount"`
+}
+
+// BudgetVsActualReport compares a chama's budget for a year with its expenses.
+// Categories with spending but no budget line appear with a zero budget.
+type BudgetVsActualReport struct {
+	ChamaID        string               `json:"chamaId"`
+	FiscalYear     int                  `json:"fiscalYear"`
+	Lines          []BudgetVsActualLine `json:"lines"`
+	TotalBudgeted  float64              `json:"totalBudgeted"`
+	TotalActual    float64              `json:"totalActual"`
+	TotalCommitted float64              `json:"totalCommitted"`
+	TotalVariance  float64              `json:"totalVariance"`
+	PercentUsed    float64              `json:"percentUsed"`
+}
+
+// SubmitExpenseRequest submits an expense claim
+type SubmitExpenseRequest struct {
+	Category       string    `json:"category" binding:"required,oneof=venue refreshments transport stationery communication bank_charges professional_fees other"`
+	Title          string    `json:"title" binding:"required,max=200"`
+	Description    *string   `json:"description,omitempty"`
+	Vendor         *string   `json:"vendor,omitempty" binding:"omitempty,max=200"`
+	Amount         float64   `json:"amount" binding:"required,gt=0"`
+	ExpenseDate    time.Time `json:"expenseDate" binding:"required"`
+	MeetingID      *string   `json:"meetingId,omitempty"`
+	PaidByClaimant bool      `json:"paidByClaimant"`
+}
+
+// ReviewExpenseRequest carries an official's comment when approving or the reason
+// when rejecting
+type ReviewExpenseRequest struct {
+	Comment *string `json:"comment,omitempty" binding:"omitempty,max=500"`
+}
+
+// SetBudgetLineRequest creates or replaces the budget for a category in a year
+type SetBudgetLineRequest struct {
+	FiscalYear int     `json:"fiscalYear" binding:"required,min=2000,max=2100"`
+	Category   string  `json:"category" binding:"required,oneof=venue refreshments transport stationery communication bank_charges professional_fees other"`
+	Name       string  `json:"name" binding:"omitempty,max=200"`
+	Amount     float64 `json:"amount" binding:"min=0"`
+}
+
+// SetExpenseApprovalRuleRequest creates or replaces the rule for a minimum amount
+type SetExpenseApprovalRuleRequest struct {
+	MinAmount         float64 `json:"minAmount" binding:"min=0"`
+	ApprovalsRequired int     `json:"approvalsRequired" binding:"required,min=1,max=3"`
+	ReceiptRequired   bool    `json:"receiptRequired"`
+}
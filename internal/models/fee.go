@@ -0,0 +1,137 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// FeeCalculation represents how a fee rule prices a transaction
+type FeeCalculation string
+
+const (
+	FeeCalculationFlat       FeeCalculation = "flat"
+	FeeCalculationPercentage FeeCalculation = "percentage"
+	FeeCalculationBanded     FeeCalculation = "banded"
+)
+
+// PlatformFeeWalletID is the platform account that collected fees are posted to
+const PlatformFeeWalletID = "wallet-platform-fees"
+
+// FeeBand prices amounts from MinAmount up to, but not including, MaxAmount. A nil
+// MaxAmount leaves the band open-ended.
+type FeeBand struct {
+	MinAmount  float64  `json:"minAmount" binding:"min=0"`
+	MaxAmount  *float64 `json:"maxAmount,omitempty" binding:"omitempty,gt=0"`
+	FlatFee    float64  `json:"flatFee" binding:"min=0"`
+	Percentage float64  `json:"percentage" binding:"min=0,max=100"`
+}
+
+// FeeRule is one line of the fee schedule. An empty TransactionType or PaymentMethod
+// matches any; when several active rules match, the most specific one applies.
+type FeeRule struct {
+	ID              string         `json:"id" db:"id"`
+	Name            string         `json:"name" db:"name"`
+	TransactionType string         `json:"transactionType,omitempty" db:"transaction_type"`
+	PaymentMethod   string         `json:"paymentMethod,omitempty" db:"payment_method"`
+	Calculation     FeeCalculation `json:"calculation" db:"calculation"`
+	FlatFee         float64        `json:"flatFee" db:"flat_fee"`
+	Percentage      float64        `json:"percentage" db:"percentage"`
+	MinFee          float64        `json:"minFee" db:"min_fee"`
+	MaxFee          *float64       `json:"maxFee,omitempty" db:"max_fee"`
+	Bands           []FeeBand      `json:"bands,omitempty" db:"bands"`
+	IsActive        bool           `json:"isActive" db:"is_active"`
+	CreatedBy       string         `json:"createdBy" db:"created_by"`
+	UpdatedBy       *string        `json:"updatedBy,omitempty" db:"updated_by"`
+	CreatedAt       time.Time      `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time      `json:"updatedAt" db:"updated_at"`
+}
+
+// GetBandsJSON returns the fee bands as a JSON string
+func (r *FeeRule) GetBandsJSON() (string, error) {
+	if len(r.Bands) == 0 {
+		return "[]", nil
+	}
+	data, err := json.Marshal(r.Bands)
+	return string(data), err
+}
+
+// TransactionLimitTier holds the outgoing limits for users at one KYC tier. A wallet's
+// own daily or monthly limit applies instead when it is lower.
+type TransactionLimitTier struct {
+	Tier                   int        `json:"tier" db:"tier"`
+	Name                   string     `json:"name" db:"name"`
+	SingleTransactionLimit float64    `json:"singleTransactionLimit" db:"single_transaction_limit"`
+	DailyLimit             float64    `json:"dailyLimit" db:"daily_limit"`
+	MonthlyLimit           float64    `json:"monthlyLimit" db:"monthly_limit"`
+	MaxLoanAmount          float64    `json:"maxLoanAmount" db:"max_loan_amount"`
+	UpdatedBy              *string    `json:"updatedBy,omitempty" db:"updated_by"`
+	UpdatedAt              *time.Time `json:"updatedAt,omitempty" db:"updated_at"`
+}
+
+// LimitStatus is how much of a user's outgoing limits is used and whether a further
+// amount fits inside them
+type LimitStatus struct {
+	Tier                   int     `json:"tier"`
+	TierName               string  `json:"tierName"`
+	SingleTransactionLimit float64 `json:"singleTransactionLimit"`
+	DailyLimit             float64 `json:"dailyLimit"`
+	DailyUsed              float64 `json:"dailyUsed"`
+	DailyRemaining         float64 `json:"dailyRemaining"`
+	MonthlyLimit           float64 `json:"monthlyLimit"`
+	MonthlyUsed            float64 `json:"monthlyUsed"`
+	MonthlyRemaining       float64 `json:"monthlyRemaining"`
+	Allowed                bool    `json:"allowed"`
+	Reason                 string  `json:"reason,omitempty"`
+}
+
+// FeeQuote is what a transaction will cost before the user confirms it
+type FeeQuote struct {
+	TransactionType string       `json:"transactionType"`
+	PaymentMethod   string       `json:"paymentMethod,omitempty"`
+	Amount          float64      `json:"amount"`
+	Fee             float64      `json:"fee"`
+	TotalDebit      float64      `json:"totalDebit"`
+	RuleID          *string      `json:"ruleId,omitempty"`
+	RuleName        string       `json:"ruleName,omitempty"`
+	Limits          *LimitStatus `json:"limits,omitempty"`
+}
+
+// FeeRevenueLine totals the fees collected on one transaction type
+type FeeRevenueLine struct {
+	TransactionType string  `json:"transactionType"`
+	Count           int     `json:"count"`
+	Total           float64 `json:"total"`
+}
+
+// FeeRevenueSummary totals fees posted to the platform account over a period
+type FeeRevenueSummary struct {
+	From        time.Time         `json:"from"`
+	To          time.Time         `json:"to"`
+	Total       float64           `json:"total"`
+	Lines       []*FeeRevenueLine `json:"lines"`
+	FeesBalance float64           `json:"feesBalance"`
+}
+
+// FeeRuleRequest represents an admin creating or replacing a fee rule
+type FeeRuleRequest struct {
+	Name            string    `json:"name" binding:"required,max=100"`
+	TransactionType string    `json:"transactionType,omitempty"`
+	PaymentMethod   string    `json:"paymentMethod,omitempty"`
+	Calculation     string    `json:"calculation" binding:"required,oneof=flat percentage banded"`
+	FlatFee         float64   `json:"flatFee" binding:"min=0"`
+	Percentage      float64   `json:"percentage" binding:"min=0,max=100"`
+	MinFee          float64   `json:"minFee" binding:"min=0"`
+	MaxFee          *float64  `json:"maxFee,omitempty" binding:"omitempty,min=0"`
+	Bands           []FeeBand `json:"bands,omitempty" binding:"dive"`
+	IsActive        *bool     `json:"isActive,omitempty"`
+}
+
+// UpdateLimitTierRequest represents an admin changing one tier's outgoing limits
+type UpdateLimitTierRequest struct {
+	Name                   string  `json:"name" binding:"max=50"`
+	SingleTransactionLimit float64 `json:"singleTransactionLimit" binding:"required,gt=0"`
+	DailyLimit             float64 `json:"dailyLimit" binding:"required,gt=0"`
+	MonthlyLimit           float64 `json:"monthlyLimit" binding:"required,gt=0"`
+	// MaxLoanAmount is left unchanged when omitted; 0 makes the tier ineligible for loans
+	MaxLoanAmount *float64 `json:"maxLoanAmount,omitempty" binding:"omitempty,min=0"`
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// FraudAction is what a fraud rule does when it matches
+type FraudAction string
+
+const (
+	FraudActionScore FraudAction = "score" // only adds to the evaluation's score
+	FraudActionHold  FraudAction = "hold"  // holds the transaction for admin review
+	FraudActionBlock FraudAction = "block" // refuses the transaction outright
+)
+
+// FraudDecision is the outcome of evaluating an outgoing transaction
+type FraudDecision string
+
+const (
+	FraudDecisionAllow FraudDecision = "allow"
+	FraudDecisionHold  FraudDecision = "hold"
+	FraudDecisionBlock FraudDecision = "block"
+)
+
+// FraudEvaluationStatus tracks an evaluation through the admin review queue
+type FraudEvaluationStatus string
+
+const (
+	FraudStatusCleared       FraudEvaluationStatus = "cleared"
+	FraudStatusPendingReview FraudEvaluationStatus = "pending_review"
+	FraudStatusReleased      FraudEvaluationStatus = "released"
+	FraudStatusRejected      FraudEvaluationStatus = "rejected"
+	FraudStatusBlocked       FraudEvaluationStatus = "blocked"
+)
+
+// Fraud rule IDs. Each is implemented in the fraud service; admins tune its action,
+// score and parameters but cannot add new kinds of rule.
+const (
+	FraudRuleVelocity            = "velocity"
+	FraudRuleNewRecipientLarge   = "new_recipient_large"
+	FraudRuleNewDeviceWithdrawal = "new_device_withdrawal"
+	FraudRuleOfficialRoundTrip   = "official_round_trip"
+	FraudRuleChamaDrain          = "chama_drain"
+)
+
+// FraudRule is one check run against every outgoing transaction
+type FraudRule struct {
+	ID          string             `json:"id" db:"id"`
+	Name        string             `json:"name" db:"name"`
+	Description string             `json:"description" db:"description"`
+	IsActive    bool               `json:"isActive" db:"is_active"`
+	Action      FraudAction        `json:"action" db:"action"`
+	Score       int                `json:"score" db:"score"`
+	Params      map[string]float64 `json:"params" db:"params"`
+	UpdatedBy   *string            `json:"updatedBy,omitempty" db:"updated_by"`
+	UpdatedAt   *time.Time         `json:"updatedAt,omitempty" db:"updated_at"`
+}
+
+// Param returns a rule parameter, or fallback when an admin has removed it
+func (r *FraudRule) Param(name string, fallback float64) float64 {
+	if value, ok := r.Params[name]; ok {
+		return value
+	}
+	return fallback
+}
+
+// FraudRuleHit is one rule that matched a transaction and why
+type FraudRuleHit struct {
+	RuleID string      `json:"ruleId"`
+	Name   string      `json:"name"`
+	Action FraudAction `json:"action"`
+	Score  int         `json:"score"`
+	Reason string      `json:"reason"`
+}
+
+// FraudCheck describes an outgoing transaction about to be made
+type FraudCheck struct {
+	UserID          string
+	ChamaID         string // set when the money leaves a chama wallet
+	TransactionType TransactionType
+	Amount          float64
+	RecipientID     string // the user or chama being paid, if any
+	FromWalletID    string
+	// Reviewable is set when the transaction is created pending, so a hold can wait
+	// for review on the transaction itself instead of refusing it
+	Reviewable bool
+}
+
+// FraudEvaluation records the rules engine's decision on one outgoing transaction
+type FraudEvaluation struct {
+	ID              string                `json:"id" db:"id"`
+	UserID          string                `json:"userId" db:"user_id"`
+	UserName        string                `json:"userName,omitempty"`
+	ChamaID         *string               `json:"chamaId,omitempty" db:"chama_id"`
+	TransactionType TransactionType       `json:"transactionType" db:"transaction_type"`
+	Amount          float64               `json:"amount" db:"amount"`
+	RecipientID     *string               `json:"recipientId,omitempty" db:"recipient_id"`
+	FromWalletID    *string               `json:"fromWalletId,omitempty" db:"from_wallet_id"`
+	TransactionID   *string               `json:"transactionId,omitempty" db:"transaction_id"`
+	Score           int                   `json:"score" db:"score"`
+	Decision        FraudDecision         `json:"decision" db:"decision"`
+	Status          FraudEvaluationStatus `json:"status" db:"status"`
+	Hits            []FraudRuleHit        `json:"hits" db:"hits"`
+	ReviewedBy      *string               `json:"reviewedBy,omitempty" db:"reviewed_by"`
+	ReviewedAt      *time.Time            `json:"reviewedAt,omitempty" db:"reviewed_at"`
+	ReviewNotes     *string               `json:"reviewNotes,omitempty" db:"review_notes"`
+	ConsumedAt      *time.Time            `json:"consumedAt,omitempty" db:"consumed_at"`
+	CreatedAt       time.Time             `json:"createdAt" db:"created_at"`
+}
+
+// GetHitsJSON returns the rule hits as a JSON string
+func (e *FraudEvaluation) GetHitsJSON() (string, error) {
+	if e.Hits == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(e.Hits)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// FraudRuleStats summarises how often a rule has matched over a period
+type FraudRuleStats struct {
+	RuleID    string      `json:"ruleId"`
+	Name      string      `json:"name"`
+	Action    FraudAction `json:"action"`
+	IsActive  bool        `json:"isActive"`
+	Hits      int         `json:"hits"`
+	Held      int         `json:"held"`
+	Blocked   int         `json:"blocked"`
+	Released  int         `json:"released"`
+	Rejected  int         `json:"rejected"`
+	LastHitAt *time.Time  `json:"lastHitAt,omitempty"`
+}
+
+// UpdateFraudRuleRequest represents an admin tuning a fraud rule
+type UpdateFraudRuleRequest struct {
+	IsActive *bool              `json:"isActive"`
+	Action   string             `json:"action" binding:"omitempty,oneof=score hold block"`
+	Score    *int               `json:"score" binding:"omitempty,min=0,max=100"`
+	Params   map[string]float64 `json:"params"`
+}
+
+// FraudReviewRequest represents an admin releasing or rejecting a held transaction
+type FraudReviewRequest struct {
+	Notes string `json:"notes" binding:"max=1000"`
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"time"
+)
+
+// KYCStatus represents the state of a KYC submission
+type KYCStatus string
+
+const (
+	KYCStatusDraft      KYCStatus = "draft"
+	KYCStatusPending    KYCStatus = "pending"
+	KYCStatusApproved   KYCStatus = "approved"
+	KYCStatusRejected   KYCStatus = "rejected"
+	KYCStatusExpired    KYCStatus = "expired"
+	KYCStatusSuperseded KYCStatus = "superseded"
+)
+
+// KYCDocumentType represents what an uploaded KYC document shows
+type KYCDocumentType string
+
+const (
+	KYCDocumentIDFront        KYCDocumentType = "national_id_front"
+	KYCDocumentIDBack         KYCDocumentType = "national_id_back"
+	KYCDocumentSelfie         KYCDocumentType = "selfie"
+	KYCDocumentProofOfAddress KYCDocumentType = "proof_of_address"
+)
+
+// KYC tiers. Tier 0 is every user who has not been verified; each tier's transaction
+// limits and loan ceiling live in transaction_limit_tiers.
+const (
+	KYCTierUnverified = 0
+	KYCTierBasic      = 1
+	KYCTierVerified   = 2
+	KYCTierEnhanced   = 3
+)
+
+// KYCRequiredDocuments lists the documents a submission for tier must include
+func KYCRequiredDocuments(tier int) []KYCDocumentType {
+	switch tier {
+	case KYCTierBasic:
+		return []KYCDocumentType{KYCDocumentIDFront}
+	case KYCTierVerified:
+		return []KYCDocumentType{KYCDocumentIDFront, KYCDocumentIDBack, KYCDocumentSelfie}
+	case KYCTierEnhanced:
+		return []KYCDocumentType{KYCDocumentIDFront, KYCDocumentIDBack, KYCDocumentSelfie, KYCDocumentProofOfAddress}
+	}
+	return nil
+}
+
+// IsValidKYCDocumentType reports whether documentType is a known KYC document type
+func IsValidKYCDocumentType(documentType string) bool {
+	switch KYCDocumentType(documentType) {
+	case KYCDocumentIDFront, KYCDocumentIDBack, KYCDocumentSelfie, KYCDocumentProofOfAddress:
+		return true
+	}
+	return false
+}
+
+// KYCSubmission is one request by a user to be verified at a tier. It is filled in as a
+// draft, submitted for review, then approved or rejected by an admin.
+type KYCSubmission struct {
+	ID               string         `json:"id" db:"id"`
+	UserID           string         `json:"userId" db:"user_id"`
+	UserName         string         `json:"userName,omitempty"`
+	RequestedTier    int            `json:"requestedTier" db:"requested_tier"`
+	Status           KYCStatus      `json:"status" db:"status"`
+	FullName         string         `json:"fullName" db:"full_name"`
+	NationalIDNumber string         `json:"nationalIdNumber" db:"national_id_number"`
+	DateOfBirth      time.Time      `json:"dateOfBirth" db:"date_of_birth"`
+	Documents        []*KYCDocument `json:"documents"`
+	SubmittedAt      *time.Time     `json:"submittedAt,omitempty" db:"submitted_at"`
+	ReviewedBy       *string        `json:"reviewedBy,omitempty" db:"reviewed_by"`
+	ReviewedAt       *time.Time     `json:"reviewedAt,omitempty" db:"reviewed_at"`
+	RejectionReason  *string        `json:"rejectionReason,omitempty" db:"rejection_reason"`
+	ReviewNotes      *string        `json:"reviewNotes,omitempty" db:"review_notes"`
+	ExpiresAt        *time.Time     `json:"expiresAt,omitempty" db:"expires_at"`
+	CreatedAt        time.Time      `json:"createdAt" db:"created_at"`
+	UpdatedAt        time.Time      `json:"updatedAt" db:"updated_at"`
+}
+
+// KYCDocument is an identity image attached to a submission. Files are kept out of the
+// public uploads directory and are only served to the owner and admins.
+type KYCDocument struct {
+	ID           string          `json:"id" db:"id"`
+	SubmissionID string          `json:"submissionId" db:"submission_id"`
+	UserID       string          `json:"userId" db:"user_id"`
+	DocumentType KYCDocumentType `json:"documentType" db:"document_type"`
+	FileName     string          `json:"fileName" db:"file_name"`
+	FilePath     string          `json:"-" db:"file_path"`
+	FileID       *string         `json:"-" db:"file_id"` // set for documents kept by the storage service
+	FileSize     int64           `json:"fileSize" db:"file_size"`
+	FileType     string          `json:"fileType" db:"file_type"`
+	UploadedAt   time.Time       `json:"uploadedAt" db:"uploaded_at"`
+}
+
+// KYCReview records one admin decision on a submission
+type KYCReview struct {
+	ID           string    `json:"id" db:"id"`
+	SubmissionID string    `json:"submissionId" db:"submission_id"`
+	UserID       string    `json:"userId" db:"user_id"`
+	ReviewerID   string    `json:"reviewerId" db:"reviewer_id"`
+	ReviewerName string    `json:"reviewerName,omitempty"`
+	Decision     KYCStatus `json:"decision" db:"decision"`
+	Tier         int       `json:"tier" db:"tier"`
+	Reason       *string   `json:"reason,omitempty" db:"reason"`
+	Notes        *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// KYCStatusSummary is a user's current verification tier, what it unlocks and any
+// submission still in progress
+type KYCStatusSummary struct {
+	Tier                int            `json:"tier"`
+	TierName            string         `json:"tierName"`
+	ExpiresAt           *time.Time     `json:"expiresAt,omitempty"`
+	ReverificationDue   bool           `json:"reverificationDue"`
+	MaxLoanAmount       float64        `json:"maxLoanAmount"`
+	Limits              *LimitStatus   `json:"limits,omitempty"`
+	OpenSubmission      *KYCSubmission `json:"openSubmission,omitempty"`
+	LastRejectionReason *string        `json:"lastRejectionReason,omitempty"`
+}
+
+// CreateKYCSubmissionRequest represents a user starting a KYC submission
+type CreateKYCSubmissionRequest struct {
+	RequestedTier    int    `json:"requestedTier" binding:"required,min=1,max=3"`
+	FullName         string `json:"fullName" binding:"required,max=200"`
+	NationalIDNumber string `json:"nationalIdNumber" binding:"required,min=5,max=20"`
+	DateOfBirth      string `json:"dateOfBirth" binding:"required"` // YYYY-MM-DD
+}
+
+// ApproveKYCRequest represents an admin approving a submission
+type ApproveKYCRequest struct {
+	Notes string `json:"notes" binding:"max=1000"`
+}
+
+// RejectKYCRequest represents an admin rejecting a submission
+type RejectKYCRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+	Notes  string `json:"notes" binding:"max=1000"`
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"time"
+)
+
+// AgendaItemStatus represents the status of an agenda item
+type AgendaItemStatus string
+
+const (
+	AgendaItemStatusPending    AgendaItemStatus = "pending"
+	AgendaItemStatusInProgress AgendaItemStatus = "in_progress"
+	AgendaItemStatusCompleted  AgendaItemStatus = "completed"
+	AgendaItemStatusDeferred   AgendaItemStatus = "deferred"
+)
+
+// MotionStatus represents the status of a motion
+type MotionStatus string
+
+const (
+	MotionStatusProposed  MotionStatus = "proposed"
+	MotionStatusSeconded  MotionStatus = "seconded"
+	MotionStatusVoting    MotionStatus = "voting"
+	MotionStatusCarried   MotionStatus = "carried"
+	MotionStatusDefeated  MotionStatus = "defeated"
+	MotionStatusWithdrawn MotionStatus = "withdrawn"
+)
+
+// MotionVoteChoice represents a member's vote on a motion
+type MotionVoteChoice string
+
+const (
+	MotionVoteFor     MotionVoteChoice = "for"
+	MotionVoteAgainst MotionVoteChoice = "against"
+	MotionVoteAbstain MotionVoteChoice = "abstain"
+)
+
+// AgendaItem represents an ordered item on a meeting agenda
+type AgendaItem struct {
+	ID               string           `json:"id" db:"id"`
+	MeetingID        string           `json:"meetingId" db:"meeting_id"`
+	Position         int              `json:"position" db:"position"`
+	Title            string           `json:"title" db:"title"`
+	Description      *string          `json:"description,omitempty" db:"description"`
+	PresenterID      *string          `json:"presenterId,omitempty" db:"presenter_id"`
+	PresenterName    *string          `json:"presenterName,omitempty"`
+	AllocatedMinutes int              `json:"allocatedMinutes" db:"allocated_minutes"`
+	Status           AgendaItemStatus `json:"status" db:"status"`
+	CreatedBy        string           `json:"createdBy" db:"created_by"`
+	CreatedAt        time.Time        `json:"createdAt" db:"created_at"`
+	UpdatedAt        time.Time        `json:"updatedAt" db:"updated_at"`
+}
+
+// Motion represents a motion tabled during a meeting
+type Motion struct {
+	ID             string       `json:"id" db:"id"`
+	MeetingID      string       `json:"meetingId" db:"meeting_id"`
+	ChamaID        string       `json:"chamaId" db:"chama_id"`
+	AgendaItemID   *string      `json:"agendaItemId,omitempty" db:"agenda_item_id"`
+	Title          string       `json:"title" db:"title"`
+	Description    *string      `json:"description,omitempty" db:"description"`
+	ProposedBy     string       `json:"proposedBy" db:"proposed_by"`
+	SecondedBy     *string      `json:"secondedBy,omitempty" db:"seconded_by"`
+	SecondedAt     *time.Time   `json:"secondedAt,omitempty" db:"seconded_at"`
+	Status         MotionStatus `json:"status" db:"status"`
+	VotesFor       int          `json:"votesFor" db:"votes_for"`
+	VotesAgainst   int          `json:"votesAgainst" db:"votes_against"`
+	Abstentions    int          `json:"abstentions" db:"abstentions"`
+	VotingOpenedAt *time.Time   `json:"votingOpenedAt,omitempty" db:"voting_opened_at"`
+	VotingClosedAt *time.Time   `json:"votingClosedAt,omitempty" db:"voting_closed_at"`
+	CreatedAt      time.Time    `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time    `json:"updatedAt" db:"updated_at"`
+}
+
+// Resolution represents a decision carried at a meeting
+type Resolution struct {
+	ID               string    `json:"id" db:"id"`
+	ChamaID          string    `json:"chamaId" db:"chama_id"`
+	MeetingID        string    `json:"meetingId" db:"meeting_id"`
+	MotionID         *string   `json:"motionId,omitempty" db:"motion_id"`
+	MinutesID        *string   `json:"minutesId,omitempty" db:"minutes_id"`
+	ResolutionNumber string    `json:"resolutionNumber" db:"resolution_number"`
+	Title            string    `json:"title" db:"title"`
+	Text             string    `json:"text" db:"text"`
+	VotesFor         int       `json:"votesFor" db:"votes_for"`
+	VotesAgainst     int       `json:"votesAgainst" db:"votes_against"`
+	Abstentions      int       `json:"abstentions" db:"abstentions"`
+	PassedAt         time.Time `json:"passedAt" db:"passed_at"`
+	RecordedBy       string    `json:"recordedBy" db:"recorded_by"`
+	MeetingTitle     string    `json:"meetingTitle,omitempty"`
+	CreatedAt        time.Time `json:"createdAt" db:"created_at"`
+}
+
+// CreateAgendaItemRequest represents the request to add an agenda item
+type CreateAgendaItemRequest struct {
+	Title            string  `json:"title" binding:"required,min=1,max=200"`
+	Description      *string `json:"description,omitempty" binding:"omitempty,max=2000"`
+	PresenterID      *string `json:"presenterId,omitempty"`
+	AllocatedMinutes int     `json:"allocatedMinutes" binding:"omitempty,min=0,max=600"`
+	Position         *int    `json:"position,omitempty" binding:"omitempty,min=1"`
+}
+
+// UpdateAgendaItemRequest represents the request to update an agenda item
+type UpdateAgendaItemRequest struct {
+	Title            *string           `json:"title,omitempty" binding:"omitempty,min=1,max=200"`
+	Description      *string           `json:"description,omitempty" binding:"omitempty,max=2000"`
+	PresenterID      *string           `json:"presenterId,omitempty"`
+	AllocatedMinutes *int              `json:"allocatedMinutes,omitempty" binding:"omitempty,min=0,max=600"`
+	Status           *AgendaItemStatus `json:"status,omitempty" binding:"omitempty,oneof=pending in_progress completed deferred"`
+}
+
+// ReorderAgendaRequest represents the request to reorder agenda items
+type ReorderAgendaRequest struct {
+	ItemIDs []string `json:"itemIds" binding:"required,min=1"`
+}
+
+// CreateMotionRequest represents the request to propose a motion
+type CreateMotionRequest struct {
+	Title        string  `json:"title" binding:"required,min=1,max=200"`
+	Description  *string `json:"description,omitempty" binding:"omitempty,max=2000"`
+	AgendaItemID *string `json:"agendaItemId,omitempty"`
+}
+
+// CastMotionVoteRequest represents the request to vote on a motion
+type CastMotionVoteRequest struct {
+	Choice MotionVoteChoice `json:"choice" binding:"required,oneof=for against abstain"`
+}
+
+// MotionWithVoters represents a motion with the present members eligible to vote
+type MotionWithVoters struct {
+	Motion
+	EligibleVoters int               `json:"eligibleVoters"`
+	UserVote       *MotionVoteChoice `json:"userVote,omitempty"`
+}
+
+// IsValidMotionVoteChoice checks if the vote choice is valid
+func IsValidMotionVoteChoice(choice string) bool {
+	switch MotionVoteChoice(choice) {
+	case MotionVoteFor, MotionVoteAgainst, MotionVoteAbstain:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsClosed checks if the motion can no longer change
+func (m *Motion) IsClosed() bool {
+	return m.Status == MotionStatusCarried || m.Status == MotionStatusDefeated || m.Status == MotionStatusWithdrawn
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// MemberContributionMonth is one month of a member's contribution history
+type MemberContributionMonth struct {
+	Month    string  `json:"month"` // YYYY-MM
+	Amount   float64 `json:"amount"`
+	Count    int     `json:"count"`
+	Expected float64 `json:"expected"`
+	Met      bool    `json:"met"`
+}
+
+// MemberContributionAnalytics summarises a member's real contribution record in a chama
+// over a period. Months before the member joined are not counted against them.
+type MemberContributionAnalytics struct {
+	ChamaID                string                    `json:"chamaId"`
+	UserID                 string                    `json:"userId"`
+	PeriodStart            string                    `json:"periodStart"` // YYYY-MM
+	PeriodEnd              string                    `json:"periodEnd"`   // YYYY-MM
+	Months                 []MemberContributionMonth `json:"months"`
+	TotalContributed       float64                   `json:"totalContributed"`
+	ContributionCount      int                       `json:"contributionCount"`
+	MonthlyAverage         float64                   `json:"monthlyAverage"`
+	ExpectedTotal          float64                   `json:"expectedTotal"`
+	MonthsExpected         int                       `json:"monthsExpected"`
+	MonthsMet              int                       `json:"monthsMet"`
+	ConsistencyRate        float64                   `json:"consistencyRate"`
+	CurrentStreak          int                       `json:"currentStreak"`
+	LongestStreak          int                       `json:"longestStreak"`
+	Rank                   int                       `json:"rank"`
+	RankOutOf              int                       `json:"rankOutOf"`
+	YearToDate             float64                   `json:"yearToDate"`
+	ProjectedYearEnd       float64                   `json:"projectedYearEnd"`
+	LastContributionAt     *time.Time                `json:"lastContributionAt,omitempty"`
+	LastContributionAmount float64                   `json:"lastContributionAmount"`
+	LastActivityAt         *time.Time                `json:"lastActivityAt,omitempty"`
+	GeneratedAt            time.Time                 `json:"generatedAt"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+)
+
+// Member statement activity categories
+const (
+	StatementActivityContribution  = "contribution"
+	StatementActivityFine          = "fine"
+	StatementActivitySharePurchase = "share_purchase"
+	StatementActivityLoan          = "loan"
+	StatementActivityRepayment     = "loan_repayment"
+	StatementActivityDividend      = "dividend"
+)
+
+// MemberStatementActivity is one dated item on a member statement
+type MemberStatementActivity struct {
+	Date        time.Time `json:"date"`
+	Category    string    `json:"category"`
+	Description string    `json:"description"`
+	Reference   *string   `json:"reference,omitempty"`
+	Amount      float64   `json:"amount"`
+}
+
+// MemberStatementHolding is a share holding on record at the end of a statement period
+type MemberStatementHolding struct {
+	ShareType   string  `json:"shareType"`
+	SharesOwned int     `json:"sharesOwned"`
+	ShareValue  float64 `json:"shareValue"`
+	TotalValue  float64 `json:"totalValue"`
+}
+
+// MemberStatement summarises a member's dealings with a chama between PeriodStart
+// and PeriodEnd (exclusive). The balance is the member's savings, meaning their
+// contributions other than fines and welfare, less the loan principal they owe, so
+// the closing balance is the opening balance plus contributions, less loans
+// disbursed, plus principal repaid.
+type MemberStatement struct {
+	ID                  string                    `json:"id,omitempty"`
+	ChamaID             string                    `json:"chamaId"`
+	ChamaName           string                    `json:"chamaName"`
+	MemberID            string                    `json:"memberId"`
+	MemberName          string                    `json:"memberName"`
+	PeriodStart         time.Time                 `json:"periodStart"`
+	PeriodEnd           time.Time                 `json:"periodEnd"`
+	GeneratedAt         time.Time                 `json:"generatedAt"`
+	OpeningBalance      float64                   `json:"openingBalance"`
+	OpeningSavings      float64                   `json:"openingSavings"`
+	OpeningLoanBalance  float64                   `json:"openingLoanBalance"`
+	Contributions       float64                   `json:"contributions"`
+	Fines               float64                   `json:"fines"`
+	SharePurchases      float64                   `json:"sharePurchases"`
+	LoansDisbursed      float64                   `json:"loansDisbursed"`
+	LoanRepayments      float64                   `json:"loanRepayments"`
+	PrincipalRepaid     float64                   `json:"principalRepaid"`
+	InterestPaid        float64                   `json:"interestPaid"`
+	DividendsEarned     float64                   `json:"dividendsEarned"`
+	DividendsReinvested float64                   `json:"dividendsReinvested"`
+	ClosingSavings      float64                   `json:"closingSavings"`
+	ClosingLoanBalance  float64                   `json:"closingLoanBalance"`
+	ClosingBalance      float64                   `json:"closingBalance"`
+	Holdings            []MemberStatementHolding  `json:"holdings"`
+	TotalShareValue     float64                   `json:"totalShareValue"`
+	Activity            []MemberStatementActivity `json:"activity"`
+}
+
+// MemberStatementRecord is a monthly statement kept for download, with whether it
+// was emailed
+type MemberStatementRecord struct {
+	ID             string     `json:"id" db:"id"`
+	ChamaID        string     `json:"chamaId" db:"chama_id"`
+	MemberID       string     `json:"memberId" db:"member_id"`
+	PeriodMonth    string     `json:"periodMonth" db:"period_month"`
+	PeriodStart    time.Time  `json:"periodStart" db:"period_start"`
+	PeriodEnd      time.Time  `json:"periodEnd" db:"period_end"`
+	OpeningBalance float64    `json:"openingBalance" db:"opening_balance"`
+	ClosingBalance float64    `json:"closingBalance" db:"closing_balance"`
+	EmailedAt      *time.Time `json:"emailedAt,omitempty" db:"emailed_at"`
+	EmailError     *string    `json:"emailError,omitempty" db:"email_error"`
+	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
+}
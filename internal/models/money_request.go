@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+)
+
+// MoneyRequestStatus represents the state of a request for money between users
+type MoneyRequestStatus string
+
+const (
+	MoneyRequestStatusPending   MoneyRequestStatus = "pending"
+	MoneyRequestStatusPaid      MoneyRequestStatus = "paid"
+	MoneyRequestStatusDeclined  MoneyRequestStatus = "declined"
+	MoneyRequestStatusExpired   MoneyRequestStatus = "expired"
+	MoneyRequestStatusCancelled MoneyRequestStatus = "cancelled"
+)
+
+// MoneyRequestType represents how a money request was raised
+type MoneyRequestType string
+
+const (
+	MoneyRequestTypeQRCode MoneyRequestType = "qr_code"
+	MoneyRequestTypeDirect MoneyRequestType = "direct"
+	MoneyRequestTypeSplit  MoneyRequestType = "split"
+)
+
+// MoneyRequest represents one user asking another for money. QR code requests have
+// no target and can be paid by whoever scans them; a split creates one request per
+// participant sharing a SplitID.
+type MoneyRequest struct {
+	ID             string             `json:"id" db:"id"`
+	RequesterID    string             `json:"requesterId" db:"requester_id"`
+	RequesterName  string             `json:"requesterName,omitempty"`
+	TargetUserID   *string            `json:"targetUserId,omitempty" db:"target_user_id"`
+	TargetName     string             `json:"targetName,omitempty"`
+	Amount         float64            `json:"amount" db:"amount"`
+	Reason         string             `json:"reason" db:"reason"`
+	RequestType    MoneyRequestType   `json:"requestType" db:"request_type"`
+	Status         MoneyRequestStatus `json:"status" db:"status"`
+	SplitID        *string            `json:"splitId,omitempty" db:"split_id"`
+	TransactionID  *string            `json:"transactionId,omitempty" db:"transaction_id"`
+	PaidBy         *string            `json:"paidBy,omitempty" db:"paid_by"`
+	PaidAt         *time.Time         `json:"paidAt,omitempty" db:"paid_at"`
+	DeclineReason  *string            `json:"declineReason,omitempty" db:"decline_reason"`
+	RespondedAt    *time.Time         `json:"respondedAt,omitempty" db:"responded_at"`
+	ReminderSentAt *time.Time         `json:"reminderSentAt,omitempty" db:"reminder_sent_at"`
+	ExpiresAt      time.Time          `json:"expiresAt" db:"expires_at"`
+	CreatedAt      time.Time          `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time          `json:"updatedAt" db:"updated_at"`
+}
+
+// MoneyRequestSplit summarises a shared expense split across several members
+type MoneyRequestSplit struct {
+	SplitID     string         `json:"splitId"`
+	RequesterID string         `json:"requesterId"`
+	Reason      string         `json:"reason"`
+	TotalAmount float64        `json:"totalAmount"`
+	PaidAmount  float64        `json:"paidAmount"`
+	Outstanding float64        `json:"outstanding"`
+	Requests    []MoneyRequest `json:"requests"`
+}
+
+// CreateMoneyRequestRequest represents a QR code request anyone can pay
+type CreateMoneyRequestRequest struct {
+	Amount         float64 `json:"amount" binding:"required,gt=0"`
+	Reason         string  `json:"reason"`
+	RequestType    string  `json:"requestType" binding:"required,oneof=qr_code direct"`
+	ExpiresInHours int     `json:"expiresInHours,omitempty" binding:"omitempty,min=1,max=720"`
+}
+
+// SendMoneyRequestRequest represents a request sent directly to one user
+type SendMoneyRequestRequest struct {
+	Amount         float64 `json:"amount" binding:"required,gt=0"`
+	Reason         string  `json:"reason"`
+	TargetUserID   string  `json:"targetUserId"`
+	TargetPhone    string  `json:"targetPhone"`
+	RequestType    string  `json:"requestType" binding:"required,oneof=direct"`
+	ExpiresInHours int     `json:"expiresInHours,omitempty" binding:"omitempty,min=1,max=720"`
+}
+
+// SplitParticipant is one member's share of a split request. When no amount is
+// given the total is divided equally.
+type SplitParticipant struct {
+	UserID string   `json:"userId" binding:"required"`
+	Amount *float64 `json:"amount,omitempty" binding:"omitempty,gt=0"`
+}
+
+// CreateSplitMoneyRequestRequest represents splitting a shared cost across members.
+// With IncludeSelf the requester carries an equal share that is not requested.
+type CreateSplitMoneyRequestRequest struct {
+	TotalAmount    float64            `json:"totalAmount" binding:"required,gt=0"`
+	Reason         string             `json:"reason" binding:"required"`
+	Participants   []SplitParticipant `json:"participants" binding:"required,min=1,dive"`
+	IncludeSelf    bool               `json:"includeSelf"`
+	ExpiresInHours int                `json:"expiresInHours,omitempty" binding:"omitempty,min=1,max=720"`
+}
+
+// DeclineMoneyRequestRequest represents the recipient turning a request down
+type DeclineMoneyRequestRequest struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,211 @@
+package models
+
+import (
+	"time"
+)
+
+// PortfolioAssetType represents what a chama's pooled money is invested in
+type PortfolioAssetType string
+
+const (
+	PortfolioAssetLand            PortfolioAssetType = "land"
+	PortfolioAssetTreasuryBill    PortfolioAssetType = "treasury_bill"
+	PortfolioAssetSaccoDeposit    PortfolioAssetType = "sacco_deposit"
+	PortfolioAssetMoneyMarketFund PortfolioAssetType = "money_market_fund"
+	PortfolioAssetEquipment       PortfolioAssetType = "equipment"
+	PortfolioAssetOther           PortfolioAssetType = "other"
+)
+
+// PortfolioAssetStatus represents whether the chama still holds an asset
+type PortfolioAssetStatus string
+
+const (
+	PortfolioAssetActive   PortfolioAssetStatus = "active"
+	PortfolioAssetDisposed PortfolioAssetStatus = "disposed"
+)
+
+// PortfolioValuationSource records where a valuation came from
+type PortfolioValuationSource string
+
+const (
+	PortfolioValuationCost      PortfolioValuationSource = "cost"      // the acquisition cost
+	PortfolioValuationAppraisal PortfolioValuationSource = "appraisal" // e.g. a land valuer's report
+	PortfolioValuationMarket    PortfolioValuationSource = "market"    // a quoted price
+	PortfolioValuationStatement PortfolioValuationSource = "statement" // a fund or SACCO statement
+	PortfolioValuationDisposal  PortfolioValuationSource = "disposal"  // the sale proceeds
+)
+
+// PortfolioEntryType separates income an asset earned from what it cost to hold
+type PortfolioEntryType string
+
+const (
+	PortfolioEntryIncome  PortfolioEntryType = "income"
+	PortfolioEntryExpense PortfolioEntryType = "expense"
+)
+
+// PortfolioAsset is an investment held by a chama. CurrentValue is the latest valuation.
+type PortfolioAsset struct {
+	ID              string               `json:"id" db:"id"`
+	ChamaID         string               `json:"chamaId" db:"chama_id"`
+	Name            string               `json:"name" db:"name"`
+	AssetType       PortfolioAssetType   `json:"assetType" db:"asset_type"`
+	Description     *string              `json:"description,omitempty" db:"description"`
+	AcquisitionDate time.Time            `json:"acquisitionDate" db:"acquisition_date"`
+	AcquisitionCost float64              `json:"acquisitionCost" db:"acquisition_cost"`
+	CurrentValue    float64              `json:"currentValue" db:"current_value"`
+	LastValuedAt    time.Time            `json:"lastValuedAt" db:"last_valued_at"`
+	MaturityDate    *time.Time           `json:"maturityDate,omitempty" db:"maturity_date"`
+	Status          PortfolioAssetStatus `json:"status" db:"status"`
+	DisposalDate    *time.Time           `json:"disposalDate,omitempty" db:"disposal_date"`
+	DisposalAmount  *float64             `json:"disposalAmount,omitempty" db:"disposal_amount"`
+	TotalIncome     float64              `json:"totalIncome"`
+	TotalExpenses   float64              `json:"totalExpenses"`
+	UnrealizedGain  float64              `json:"unrealizedGain"`
+	CreatedBy       string               `json:"createdBy" db:"created_by"`
+	CreatedAt       time.Time            `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time            `json:"updatedAt" db:"updated_at"`
+}
+
+// PortfolioValuation is a point in an asset's value history
+type PortfolioValuation struct {
+	ID            string                   `json:"id" db:"id"`
+	AssetID       string                   `json:"assetId" db:"asset_id"`
+	ChamaID       string                   `json:"chamaId" db:"chama_id"`
+	Value         float64                  `json:"value" db:"value"`
+	ValuationDate time.Time                `json:"valuationDate" db:"valuation_date"`
+	Source        PortfolioValuationSource `json:"source" db:"source"`
+	Notes         *string                  `json:"notes,omitempty" db:"notes"`
+	RecordedBy    string                   `json:"recordedBy" db:"recorded_by"`
+	CreatedAt     time.Time                `json:"createdAt" db:"created_at"`
+}
+
+// PortfolioEntry is income received from, or an expense paid for, an asset.
+// TransactionID is set when the money went through the chama wallet.
+type PortfolioEntry struct {
+	ID            string             `json:"id" db:"id"`
+	AssetID       string             `json:"assetId" db:"asset_id"`
+	ChamaID       string             `json:"chamaId" db:"chama_id"`
+	EntryType     PortfolioEntryType `json:"entryType" db:"entry_type"`
+	Category      string             `json:"category" db:"category"`
+	Amount        float64            `json:"amount" db:"amount"`
+	EntryDate     time.Time          `json:"entryDate" db:"entry_date"`
+	Description   *string            `json:"description,omitempty" db:"description"`
+	TransactionID *string            `json:"transactionId,omitempty" db:"transaction_id"`
+	RecordedBy    string             `json:"recordedBy" db:"recorded_by"`
+	CreatedAt     time.Time          `json:"createdAt" db:"created_at"`
+}
+
+// PortfolioAssetDetail is an asset with its full valuation and income history
+type PortfolioAssetDetail struct {
+	PortfolioAsset
+	Valuations []PortfolioValuation `json:"valuations"`
+	Entries    []PortfolioEntry     `json:"entries"`
+}
+
+// NAVSnapshot is a recorded net asset value of a chama, the basis of its NAV-per-share history
+type NAVSnapshot struct {
+	ID                string    `json:"id" db:"id"`
+	ChamaID           string    `json:"chamaId" db:"chama_id"`
+	CashBalance       float64   `json:"cashBalance" db:"cash_balance"`
+	LoanBook          float64   `json:"loanBook" db:"loan_book"`
+	PortfolioValue    float64   `json:"portfolioValue" db:"portfolio_value"`
+	OtherAssets       float64   `json:"otherAssets" db:"other_assets"`
+	Liabilities       float64   `json:"liabilities" db:"liabilities"`
+	NetAssetValue     float64   `json:"netAssetValue" db:"net_asset_value"`
+	SharesOutstanding int       `json:"sharesOutstanding" db:"shares_outstanding"`
+	NAVPerShare       float64   `json:"navPerShare" db:"nav_per_share"`
+	Notes             *string   `json:"notes,omitempty" db:"notes"`
+	RecordedBy        string    `json:"recordedBy" db:"recorded_by"`
+	RecordedAt        time.Time `json:"recordedAt" db:"recorded_at"`
+}
+
+// PortfolioAssetPerformance is one asset's return over a reporting period. The
+// starting basis is its value at the start of the period, or its cost if it was
+// bought during the period; the ending value is its sale proceeds if it was sold.
+type PortfolioAssetPerformance struct {
+	AssetID       string               `json:"assetId"`
+	Name          string               `json:"name"`
+	AssetType     PortfolioAssetType   `json:"assetType"`
+	Status        PortfolioAssetStatus `json:"status"`
+	StartBasis    float64              `json:"startBasis"`
+	EndValue      float64              `json:"endValue"`
+	CapitalGain   float64              `json:"capitalGain"`
+	Income        float64              `json:"income"`
+	Expenses      float64              `json:"expenses"`
+	TotalReturn   float64              `json:"totalReturn"`
+	ReturnPercent float64              `json:"returnPercent"`
+}
+
+// PortfolioAllocation is the share of the portfolio held in one asset type
+type PortfolioAllocation struct {
+	AssetType PortfolioAssetType `json:"assetType"`
+	Value     float64            `json:"value"`
+	Percent   float64            `json:"percent"`
+}
+
+// PortfolioPerformanceReport summarises a chama's investments over a period
+type PortfolioPerformanceReport struct {
+	ChamaID          string                      `json:"chamaId"`
+	From             time.Time                   `json:"from"`
+	To               time.Time                   `json:"to"`
+	Assets           []PortfolioAssetPerformance `json:"assets"`
+	Allocation       []PortfolioAllocation       `json:"allocation"`
+	TotalStartBasis  float64                     `json:"totalStartBasis"`
+	TotalEndValue    float64                     `json:"totalEndValue"`
+	TotalIncome      float64                     `json:"totalIncome"`
+	TotalExpenses    float64                     `json:"totalExpenses"`
+	TotalReturn      float64                     `json:"totalReturn"`
+	ReturnPercent    float64                     `json:"returnPercent"`
+	NAVHistory       []NAVSnapshot               `json:"navHistory"`
+	NAVPerShareStart *float64                    `json:"navPerShareStart,omitempty"`
+	NAVPerShareEnd   *float64                    `json:"navPerShareEnd,omitempty"`
+}
+
+// CreatePortfolioAssetRequest records an asset the chama holds. With PayFromWallet
+// the acquisition cost is paid out of the chama wallet; otherwise the asset is
+// recorded as already owned, e.g. land bought before the chama used VaultKe.
+type CreatePortfolioAssetRequest struct {
+	Name            string     `json:"name" binding:"required,max=200"`
+	AssetType       string     `json:"assetType" binding:"required,oneof=land treasury_bill sacco_deposit money_market_fund equipment other"`
+	Description     *string    `json:"description,omitempty"`
+	AcquisitionDate time.Time  `json:"acquisitionDate" binding:"required"`
+	AcquisitionCost float64    `json:"acquisitionCost" binding:"required,gt=0"`
+	CurrentValue    *float64   `json:"currentValue,omitempty" binding:"omitempty,min=0"`
+	MaturityDate    *time.Time `json:"maturityDate,omitempty"`
+	PayFromWallet   bool       `json:"payFromWallet"`
+}
+
+// RecordPortfolioValuationRequest records a new value for an asset
+type RecordPortfolioValuationRequest struct {
+	Value         float64   `json:"value" binding:"min=0"`
+	ValuationDate time.Time `json:"valuationDate" binding:"required"`
+	Source        string    `json:"source" binding:"required,oneof=appraisal market statement"`
+	Notes         *string   `json:"notes,omitempty"`
+}
+
+// RecordPortfolioEntryRequest records income from or an expense on an asset, such
+// as rent, T-bill interest or land rates. With ThroughWallet the amount is credited
+// to or paid from the chama wallet.
+type RecordPortfolioEntryRequest struct {
+	EntryType     string    `json:"entryType" binding:"required,oneof=income expense"`
+	Category      string    `json:"category" binding:"required,max=50"`
+	Amount        float64   `json:"amount" binding:"required,gt=0"`
+	EntryDate     time.Time `json:"entryDate" binding:"required"`
+	Description   *string   `json:"description,omitempty"`
+	ThroughWallet bool      `json:"throughWallet"`
+}
+
+// DisposePortfolioAssetRequest records the sale or maturity of an asset
+type DisposePortfolioAssetRequest struct {
+	Amount       float64   `json:"amount" binding:"min=0"`
+	DisposalDate time.Time `json:"disposalDate" binding:"required"`
+	Notes        *string   `json:"notes,omitempty"`
+	CreditWallet bool      `json:"creditWallet"`
+}
+
+// RecordNAVSnapshotRequest records the chama's current net asset value
+type RecordNAVSnapshotRequest struct {
+	OtherAssets *float64 `json:"otherAssets,omitempty" binding:"omitempty,min=0"`
+	Liabilities *float64 `json:"liabilities,omitempty" binding:"omitempty,min=0"`
+	Notes       *string  `json:"notes,omitempty"`
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"time"
+)
+
+// SavingsGoalStatus represents the state of a goal-based savings wallet
+type SavingsGoalStatus string
+
+const (
+	SavingsGoalStatusActive   SavingsGoalStatus = "active"
+	SavingsGoalStatusAchieved SavingsGoalStatus = "achieved"
+	SavingsGoalStatusClosed   SavingsGoalStatus = "closed"
+)
+
+// SavingsSweepType represents how money is swept from the personal wallet into a goal
+type SavingsSweepType string
+
+const (
+	// SavingsSweepFixed moves a fixed amount every period
+	SavingsSweepFixed SavingsSweepType = "fixed"
+	// SavingsSweepExcess moves whatever the personal wallet holds above a threshold
+	SavingsSweepExcess SavingsSweepType = "excess"
+)
+
+// SavingsGoal is a ring-fenced sub-wallet saving towards a target amount and date.
+// Progress and RequiredPerMonth are computed when the goal is read.
+type SavingsGoal struct {
+	ID               string            `json:"id" db:"id"`
+	WalletID         string            `json:"walletId" db:"wallet_id"`
+	UserID           string            `json:"userId" db:"user_id"`
+	Name             string            `json:"name" db:"name"`
+	TargetAmount     float64           `json:"targetAmount" db:"target_amount"`
+	TargetDate       *time.Time        `json:"targetDate,omitempty" db:"target_date"`
+	Balance          float64           `json:"balance"`
+	Progress         float64           `json:"progress"` // percent of target saved
+	AmountRemaining  float64           `json:"amountRemaining"`
+	RequiredPerMonth float64           `json:"requiredPerMonth,omitempty"`
+	Status           SavingsGoalStatus `json:"status" db:"status"`
+	SweepType        *SavingsSweepType `json:"sweepType,omitempty" db:"sweep_type"`
+	SweepAmount      *float64          `json:"sweepAmount,omitempty" db:"sweep_amount"`
+	SweepFrequency   *string           `json:"sweepFrequency,omitempty" db:"sweep_frequency"`
+	SweepDay         *int              `json:"sweepDay,omitempty" db:"sweep_day"`
+	NextSweepAt      *time.Time        `json:"nextSweepAt,omitempty" db:"next_sweep_at"`
+	LastSweepAt      *time.Time        `json:"lastSweepAt,omitempty" db:"last_sweep_at"`
+	AchievedAt       *time.Time        `json:"achievedAt,omitempty" db:"achieved_at"`
+	ClosedAt         *time.Time        `json:"closedAt,omitempty" db:"closed_at"`
+	CreatedAt        time.Time         `json:"createdAt" db:"created_at"`
+	UpdatedAt        time.Time         `json:"updatedAt" db:"updated_at"`
+}
+
+// FixedSavingsStatus represents the state of a fixed-term savings deposit
+type FixedSavingsStatus string
+
+const (
+	FixedSavingsStatusActive  FixedSavingsStatus = "active"
+	FixedSavingsStatusMatured FixedSavingsStatus = "matured"
+	FixedSavingsStatusBroken  FixedSavingsStatus = "broken"
+)
+
+// FixedSavings is money locked in its own wallet until MaturityDate. Breaking it early
+// forfeits EarlyWithdrawalPenaltyRate percent of the principal; deposits made under a
+// chama's rules earn simple interest at InterestRate, paid by the chama at maturity.
+type FixedSavings struct {
+	ID                         string             `json:"id" db:"id"`
+	WalletID                   string             `json:"walletId" db:"wallet_id"`
+	UserID                     string             `json:"userId" db:"user_id"`
+	ChamaID                    *string            `json:"chamaId,omitempty" db:"chama_id"`
+	Name                       string             `json:"name" db:"name"`
+	Principal                  float64            `json:"principal" db:"principal"`
+	TermMonths                 int                `json:"termMonths" db:"term_months"`
+	InterestRate               float64            `json:"interestRate" db:"interest_rate"` // annual percent
+	EarlyWithdrawalPenaltyRate float64            `json:"earlyWithdrawalPenaltyRate" db:"early_withdrawal_penalty_rate"`
+	ExpectedInterest           float64            `json:"expectedInterest"`
+	StartDate                  time.Time          `json:"startDate" db:"start_date"`
+	MaturityDate               time.Time          `json:"maturityDate" db:"maturity_date"`
+	Status                     FixedSavingsStatus `json:"status" db:"status"`
+	InterestPaid               float64            `json:"interestPaid" db:"interest_paid"`
+	PenaltyCharged             float64            `json:"penaltyCharged" db:"penalty_charged"`
+	PayoutAmount               float64            `json:"payoutAmount" db:"payout_amount"`
+	PayoutTransactionID        *string            `json:"payoutTransactionId,omitempty" db:"payout_transaction_id"`
+	ClosedAt                   *time.Time         `json:"closedAt,omitempty" db:"closed_at"`
+	CreatedAt                  time.Time          `json:"createdAt" db:"created_at"`
+}
+
+// FixedSavingsRules are a chama's terms for fixed-term savings its members lock with it
+type FixedSavingsRules struct {
+	ChamaID                    string     `json:"chamaId" db:"chama_id"`
+	Enabled                    bool       `json:"enabled" db:"enabled"`
+	AnnualInterestRate         float64    `json:"annualInterestRate" db:"annual_interest_rate"`
+	EarlyWithdrawalPenaltyRate float64    `json:"earlyWithdrawalPenaltyRate" db:"early_withdrawal_penalty_rate"`
+	MinTermMonths              int        `json:"minTermMonths" db:"min_term_months"`
+	MaxTermMonths              int        `json:"maxTermMonths" db:"max_term_months"`
+	UpdatedBy                  *string    `json:"updatedBy,omitempty" db:"updated_by"`
+	UpdatedAt                  *time.Time `json:"updatedAt,omitempty" db:"updated_at"`
+}
+
+// CreateSavingsGoalRequest represents opening a savings goal. TargetDate is YYYY-MM-DD.
+type CreateSavingsGoalRequest struct {
+	Name           string   `json:"name" binding:"required,max=100"`
+	TargetAmount   float64  `json:"targetAmount" binding:"required,gt=0"`
+	TargetDate     string   `json:"targetDate,omitempty"`
+	InitialDeposit *float64 `json:"initialDeposit,omitempty" binding:"omitempty,gt=0"`
+}
+
+// SavingsGoalAmountRequest represents moving money into or out of a goal
+type SavingsGoalAmountRequest struct {
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// SetSavingsSweepRequest sets or clears a goal's auto-sweep rule. For fixed sweeps
+// Amount is moved each period; for excess sweeps it is the personal balance to keep.
+// Day is the day of the month, or the weekday (0 = Sunday) for weekly sweeps.
+type SetSavingsSweepRequest struct {
+	SweepType string  `json:"sweepType" binding:"required,oneof=fixed excess none"`
+	Amount    float64 `json:"amount" binding:"omitempty,gt=0"`
+	Frequency string  `json:"frequency" binding:"omitempty,oneof=weekly monthly"`
+	Day       *int    `json:"day,omitempty" binding:"omitempty,min=0,max=31"`
+}
+
+// CreateFixedSavingsRequest represents locking money away for a fixed term, optionally
+// under a chama's fixed savings rules
+type CreateFixedSavingsRequest struct {
+	Name       string  `json:"name" binding:"max=100"`
+	Amount     float64 `json:"amount" binding:"required,gt=0"`
+	TermMonths int     `json:"termMonths" binding:"required,min=1,max=120"`
+	ChamaID    string  `json:"chamaId,omitempty"`
+}
+
+// UpdateFixedSavingsRulesRequest represents an official changing a chama's fixed savings terms
+type UpdateFixedSavingsRulesRequest struct {
+	Enabled                    *bool    `json:"enabled,omitempty"`
+	AnnualInterestRate         *float64 `json:"annualInterestRate,omitempty" binding:"omitempty,min=0,max=30"`
+	EarlyWithdrawalPenaltyRate *float64 `json:"earlyWithdrawalPenaltyRate,omitempty" binding:"omitempty,min=0,max=50"`
+	MinTermMonths              *int     `json:"minTermMonths,omitempty" binding:"omitempty,min=1,max=120"`
+	MaxTermMonths              *int     `json:"maxTermMonths,omitempty" binding:"omitempty,min=1,max=120"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+)
+
+// ShareCertificateStatus is whether a certificate still represents a holding
+type ShareCertificateStatus string
+
+const (
+	ShareCertificateActive    ShareCertificateStatus = "active"
+	ShareCertificateCancelled ShareCertificateStatus = "cancelled"
+)
+
+// ShareCertificate is the signed certificate issued for a share holding. Any
+// transfer, split or redemption that changes the holding cancels the certificate
+// and issues a replacement under a new number.
+type ShareCertificate struct {
+	ID                 string                 `json:"id" db:"id"`
+	CertificateNumber  string                 `json:"certificateNumber" db:"certificate_number"`
+	ChamaID            string                 `json:"chamaId" db:"chama_id"`
+	ChamaName          string                 `json:"chamaName"`
+	ShareID            string                 `json:"shareId" db:"share_id"`
+	MemberID           string                 `json:"memberId" db:"member_id"`
+	HolderName         string                 `json:"holderName"`
+	SharesCount        int                    `json:"sharesCount" db:"shares_count"`
+	ShareClass         string                 `json:"shareClass" db:"share_class"`
+	IssueDate          time.Time              `json:"issueDate" db:"issue_date"`
+	Status             ShareCertificateStatus `json:"status" db:"status"`
+	CancelledAt        *time.Time             `json:"cancelledAt,omitempty" db:"cancelled_at"`
+	CancellationReason *string                `json:"cancellationReason,omitempty" db:"cancellation_reason"`
+	ReplacedBy         *string                `json:"replacedBy,omitempty" db:"replaced_by"`
+	Signature          string                 `json:"signature"`
+	VerificationURL    string                 `json:"verificationUrl"`
+	CreatedAt          time.Time              `json:"createdAt" db:"created_at"`
+}
+
+// ShareCertificateVerification is what the public verification endpoint reveals: the
+// details printed on the certificate and whether it is still valid, nothing about
+// the holder's other holdings
+type ShareCertificateVerification struct {
+	CertificateNumber string                 `json:"certificateNumber"`
+	Valid             bool                   `json:"valid"`
+	Status            ShareCertificateStatus `json:"status,omitempty"`
+	Reason            string                 `json:"reason,omitempty"`
+	ChamaName         string                 `json:"chamaName,omitempty"`
+	HolderName        string                 `json:"holderName,omitempty"`
+	SharesCount       int                    `json:"sharesCount,omitempty"`
+	ShareClass        string                 `json:"shareClass,omitempty"`
+	IssueDate         *time.Time             `json:"issueDate,omitempty"`
+	CancelledAt       *time.Time             `json:"cancelledAt,omitempty"`
+}
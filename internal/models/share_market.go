@@ -0,0 +1,146 @@
+package models
+
+import (
+	"time"
+)
+
+// SharePriceSource represents how a chama share price was determined
+type SharePriceSource string
+
+const (
+	SharePriceSourceManual SharePriceSource = "manual"
+	SharePriceSourceNAV    SharePriceSource = "nav"
+	SharePriceSourceSplit  SharePriceSource = "split"
+)
+
+// ShareListingStatus represents the status of a member's sell order
+type ShareListingStatus string
+
+const (
+	ShareListingStatusOpen      ShareListingStatus = "open"
+	ShareListingStatusFilled    ShareListingStatus = "filled"
+	ShareListingStatusCancelled ShareListingStatus = "cancelled"
+)
+
+// SharePrice represents a point in a chama's share price history
+type SharePrice struct {
+	ID                string           `json:"id" db:"id"`
+	ChamaID           string           `json:"chamaId" db:"chama_id"`
+	Price             float64          `json:"price" db:"price"`
+	Source            SharePriceSource `json:"source" db:"source"`
+	NetAssetValue     *float64         `json:"netAssetValue,omitempty" db:"net_asset_value"`
+	SharesOutstanding int              `json:"sharesOutstanding" db:"shares_outstanding"`
+	Notes             *string          `json:"notes,omitempty" db:"notes"`
+	SetBy             string           `json:"setBy" db:"set_by"`
+	EffectiveAt       time.Time        `json:"effectiveAt" db:"effective_at"`
+}
+
+// ShareValuation breaks down a chama's net asset value per share
+type ShareValuation struct {
+	ChamaID           string   `json:"chamaId"`
+	CashBalance       float64  `json:"cashBalance"`
+	LoanBook          float64  `json:"loanBook"`
+	PortfolioValue    float64  `json:"portfolioValue"`
+	OtherAssets       float64  `json:"otherAssets"`
+	Liabilities       float64  `json:"liabilities"`
+	NetAssetValue     float64  `json:"netAssetValue"`
+	SharesOutstanding int      `json:"sharesOutstanding"`
+	NAVPerShare       float64  `json:"navPerShare"`
+	CurrentPrice      *float64 `json:"currentPrice,omitempty"`
+}
+
+// ShareMarketSettings represents a chama's rules for share exits.
+// A zero lock-in means shares can be redeemed or sold as soon as they are bought.
+type ShareMarketSettings struct {
+	ChamaID                string     `json:"chamaId" db:"chama_id"`
+	LockInDays             int        `json:"lockInDays" db:"lock_in_days"`
+	RedemptionEnabled      bool       `json:"redemptionEnabled" db:"redemption_enabled"`
+	SecondaryMarketEnabled bool       `json:"secondaryMarketEnabled" db:"secondary_market_enabled"`
+	UpdatedBy              *string    `json:"updatedBy,omitempty" db:"updated_by"`
+	UpdatedAt              *time.Time `json:"updatedAt,omitempty" db:"updated_at"`
+}
+
+// ShareRedemption represents the outcome of a member selling shares back to the chama
+type ShareRedemption struct {
+	ShareID       string    `json:"shareId"`
+	SharesCount   int       `json:"sharesCount"`
+	PricePerShare float64   `json:"pricePerShare"`
+	TotalAmount   float64   `json:"totalAmount"`
+	SharesLeft    int       `json:"sharesLeft"`
+	TransactionID string    `json:"transactionId"`
+	RedeemedAt    time.Time `json:"redeemedAt"`
+}
+
+// ShareSplit represents a split or consolidation of every active share in a chama
+type ShareSplit struct {
+	ID           string    `json:"id" db:"id"`
+	ChamaID      string    `json:"chamaId" db:"chama_id"`
+	RatioFrom    int       `json:"ratioFrom" db:"ratio_from"`
+	RatioTo      int       `json:"ratioTo" db:"ratio_to"`
+	SharesBefore int       `json:"sharesBefore" db:"shares_before"`
+	SharesAfter  int       `json:"sharesAfter" db:"shares_after"`
+	ExecutedBy   string    `json:"executedBy" db:"executed_by"`
+	Notes        *string   `json:"notes,omitempty" db:"notes"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// ShareListing represents shares a member has offered for sale to other members
+type ShareListing struct {
+	ID                string             `json:"id" db:"id"`
+	ChamaID           string             `json:"chamaId" db:"chama_id"`
+	SellerID          string             `json:"sellerId" db:"seller_id"`
+	SellerName        string             `json:"sellerName,omitempty"`
+	ShareID           string             `json:"shareId" db:"share_id"`
+	ShareName         string             `json:"shareName,omitempty"`
+	Quantity          int                `json:"quantity" db:"quantity"`
+	RemainingQuantity int                `json:"remainingQuantity" db:"remaining_quantity"`
+	PricePerShare     float64            `json:"pricePerShare" db:"price_per_share"`
+	Status            ShareListingStatus `json:"status" db:"status"`
+	Notes             *string            `json:"notes,omitempty" db:"notes"`
+	CreatedAt         time.Time          `json:"createdAt" db:"created_at"`
+	UpdatedAt         time.Time          `json:"updatedAt" db:"updated_at"`
+}
+
+// SetSharePriceRequest represents an official setting the share price.
+// With source "nav" the price is computed from the chama's net asset value.
+type SetSharePriceRequest struct {
+	Source      SharePriceSource `json:"source" binding:"required,oneof=manual nav"`
+	Price       *float64         `json:"price,omitempty" binding:"omitempty,gt=0"`
+	OtherAssets *float64         `json:"otherAssets,omitempty" binding:"omitempty,min=0"`
+	Liabilities *float64         `json:"liabilities,omitempty" binding:"omitempty,min=0"`
+	Notes       *string          `json:"notes,omitempty"`
+}
+
+// UpdateShareMarketSettingsRequest represents the request to configure share exits
+type UpdateShareMarketSettingsRequest struct {
+	LockInDays             *int  `json:"lockInDays,omitempty" binding:"omitempty,min=0,max=3650"`
+	RedemptionEnabled      *bool `json:"redemptionEnabled,omitempty"`
+	SecondaryMarketEnabled *bool `json:"secondaryMarketEnabled,omitempty"`
+}
+
+// RedeemSharesRequest represents a member selling shares back to the chama
+type RedeemSharesRequest struct {
+	ShareID     string `json:"shareId" binding:"required"`
+	SharesCount int    `json:"sharesCount" binding:"required,min=1"`
+}
+
+// SplitSharesRequest represents an official splitting every share RatioFrom-for-RatioTo,
+// e.g. 1 → 2 doubles holdings and halves the share value
+type SplitSharesRequest struct {
+	RatioFrom int     `json:"ratioFrom" binding:"required,min=1"`
+	RatioTo   int     `json:"ratioTo" binding:"required,min=1"`
+	Notes     *string `json:"notes,omitempty"`
+}
+
+// CreateShareListingRequest represents a member listing shares for sale
+type CreateShareListingRequest struct {
+	ShareID       string  `json:"shareId" binding:"required"`
+	Quantity      int     `json:"quantity" binding:"required,min=1"`
+	PricePerShare float64 `json:"pricePerShare" binding:"required,gt=0"`
+	Notes         *string `json:"notes,omitempty"`
+}
+
+// BuyShareListingRequest represents a member buying shares from a listing
+type BuyShareListingRequest struct {
+	Quantity int `json:"quantity" binding:"required,min=1"`
+}
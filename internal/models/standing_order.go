@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+)
+
+// StandingOrderStatus represents the lifecycle state of a standing order
+type StandingOrderStatus string
+
+const (
+	StandingOrderStatusActive    StandingOrderStatus = "active"
+	StandingOrderStatusPaused    StandingOrderStatus = "paused"
+	StandingOrderStatusCompleted StandingOrderStatus = "completed"
+	StandingOrderStatusCancelled StandingOrderStatus = "cancelled"
+)
+
+// StandingOrderTarget represents where a standing order sends money
+type StandingOrderTarget string
+
+const (
+	StandingOrderTargetChama  StandingOrderTarget = "chama"
+	StandingOrderTargetMember StandingOrderTarget = "member"
+)
+
+// StandingOrderFrequency represents how often a standing order runs
+type StandingOrderFrequency string
+
+const (
+	StandingOrderFrequencyWeekly  StandingOrderFrequency = "weekly"
+	StandingOrderFrequencyMonthly StandingOrderFrequency = "monthly"
+)
+
+// StandingOrderExecutionStatus represents the outcome of one run of a standing order
+type StandingOrderExecutionStatus string
+
+const (
+	StandingOrderExecutionSucceeded StandingOrderExecutionStatus = "succeeded"
+	StandingOrderExecutionRetrying  StandingOrderExecutionStatus = "retrying"
+	StandingOrderExecutionMissed    StandingOrderExecutionStatus = "missed"
+	StandingOrderExecutionFailed    StandingOrderExecutionStatus = "failed"
+)
+
+// StandingOrder is a recurring transfer from a member's personal wallet, either into a
+// chama as a contribution or to another member. Monthly orders run on DayOfMonth (clamped
+// to the last day of short months); weekly orders run on DayOfWeek (0 = Sunday).
+type StandingOrder struct {
+	ID                string                 `json:"id" db:"id"`
+	UserID            string                 `json:"userId" db:"user_id"`
+	TargetType        StandingOrderTarget    `json:"targetType" db:"target_type"`
+	ChamaID           *string                `json:"chamaId,omitempty" db:"chama_id"`
+	ChamaName         string                 `json:"chamaName,omitempty"`
+	RecipientID       *string                `json:"recipientId,omitempty" db:"recipient_id"`
+	RecipientName     string                 `json:"recipientName,omitempty"`
+	Amount            float64                `json:"amount" db:"amount"`
+	Description       string                 `json:"description" db:"description"`
+	Frequency         StandingOrderFrequency `json:"frequency" db:"frequency"`
+	DayOfMonth        *int                   `json:"dayOfMonth,omitempty" db:"day_of_month"`
+	DayOfWeek         *int                   `json:"dayOfWeek,omitempty" db:"day_of_week"`
+	Status            StandingOrderStatus    `json:"status" db:"status"`
+	StartDate         time.Time              `json:"startDate" db:"start_date"`
+	EndDate           *time.Time             `json:"endDate,omitempty" db:"end_date"`
+	MaxExecutions     *int                   `json:"maxExecutions,omitempty" db:"max_executions"`
+	ExecutionCount    int                    `json:"executionCount" db:"execution_count"`
+	MaxRetries        int                    `json:"maxRetries" db:"max_retries"`
+	RetryCount        int                    `json:"retryCount" db:"retry_count"`
+	NextRunAt         *time.Time             `json:"nextRunAt,omitempty" db:"next_run_at"`
+	RetryAt           *time.Time             `json:"retryAt,omitempty" db:"retry_at"`
+	LastRunAt         *time.Time             `json:"lastRunAt,omitempty" db:"last_run_at"`
+	LastFailureReason *string                `json:"lastFailureReason,omitempty" db:"last_failure_reason"`
+	CreatedAt         time.Time              `json:"createdAt" db:"created_at"`
+	UpdatedAt         time.Time              `json:"updatedAt" db:"updated_at"`
+}
+
+// StandingOrderExecution records one attempt to run a standing order
+type StandingOrderExecution struct {
+	ID              string                       `json:"id" db:"id"`
+	StandingOrderID string                       `json:"standingOrderId" db:"standing_order_id"`
+	ScheduledFor    time.Time                    `json:"scheduledFor" db:"scheduled_for"`
+	Attempt         int                          `json:"attempt" db:"attempt"`
+	Status          StandingOrderExecutionStatus `json:"status" db:"status"`
+	Amount          float64                      `json:"amount" db:"amount"`
+	TransactionID   *string                      `json:"transactionId,omitempty" db:"transaction_id"`
+	FailureReason   *string                      `json:"failureReason,omitempty" db:"failure_reason"`
+	ExecutedAt      time.Time                    `json:"executedAt" db:"executed_at"`
+}
+
+// CreateStandingOrderRequest represents scheduling a recurring transfer. StartDate
+// (YYYY-MM-DD) defaults to today; EndDate and MaxExecutions are both optional.
+type CreateStandingOrderRequest struct {
+	TargetType    string  `json:"targetType" binding:"required,oneof=chama member"`
+	ChamaID       string  `json:"chamaId"`
+	RecipientID   string  `json:"recipientId"`
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	Description   string  `json:"description" binding:"max=200"`
+	Frequency     string  `json:"frequency" binding:"required,oneof=weekly monthly"`
+	DayOfMonth    *int    `json:"dayOfMonth,omitempty" binding:"omitempty,min=1,max=31"`
+	DayOfWeek     *int    `json:"dayOfWeek,omitempty" binding:"omitempty,min=0,max=6"`
+	StartDate     string  `json:"startDate,omitempty"`
+	EndDate       string  `json:"endDate,omitempty"`
+	MaxExecutions *int    `json:"maxExecutions,omitempty" binding:"omitempty,min=1"`
+	MaxRetries    *int    `json:"maxRetries,omitempty" binding:"omitempty,min=0,max=5"`
+}
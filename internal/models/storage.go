@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+)
+
+// FileAccessScope decides who may download a stored file besides its owner and admins
+type FileAccessScope string
+
+const (
+	FileAccessPublic        FileAccessScope = "public"        // anyone, e.g. avatars
+	FileAccessAuthenticated FileAccessScope = "authenticated" // any signed-in user
+	FileAccessChama         FileAccessScope = "chama"         // active members of the chama in ScopeID
+	FileAccessChatRoom      FileAccessScope = "chat_room"     // active members of the chat room in ScopeID
+	FileAccessPrivate       FileAccessScope = "private"       // the owner and admins only
+)
+
+// FileCategory records what a stored file was uploaded for
+type FileCategory string
+
+const (
+	FileCategoryMeetingDocument FileCategory = "meeting_document"
+	FileCategoryChatAttachment  FileCategory = "chat_attachment"
+	FileCategoryWelfareDocument FileCategory = "welfare_document"
+	FileCategoryExpenseReceipt  FileCategory = "expense_receipt"
+	FileCategoryBankStatement   FileCategory = "bank_statement"
+	FileCategoryKYCDocument     FileCategory = "kyc_document"
+	FileCategoryDisputeEvidence FileCategory = "dispute_evidence"
+)
+
+// FileScanStatus is the outcome of virus scanning a file when it was stored. Infected
+// files are refused, so they never reach storage.
+type FileScanStatus string
+
+const (
+	FileScanClean     FileScanStatus = "clean"
+	FileScanUnscanned FileScanStatus = "unscanned" // no scanner is configured
+)
+
+// StoredFile is an uploaded file kept by the storage service. Files are never served
+// from a public directory; downloads go through short-lived signed URLs issued after
+// an access check.
+type StoredFile struct {
+	ID          string          `json:"id" db:"id"`
+	Backend     string          `json:"-" db:"backend"`
+	ObjectKey   string          `json:"-" db:"object_key"`
+	FileName    string          `json:"fileName" db:"file_name"`
+	ContentType string          `json:"contentType" db:"content_type"`
+	Size        int64           `json:"size" db:"size"`
+	Checksum    string          `json:"checksum" db:"checksum"`
+	OwnerID     string          `json:"ownerId" db:"owner_id"`
+	Category    FileCategory    `json:"category" db:"category"`
+	AccessScope FileAccessScope `json:"accessScope" db:"access_scope"`
+	ScopeID     *string         `json:"scopeId,omitempty" db:"scope_id"`
+	ScanStatus  FileScanStatus  `json:"scanStatus" db:"scan_status"`
+	CreatedAt   time.Time       `json:"createdAt" db:"created_at"`
+	DeletedAt   *time.Time      `json:"-" db:"deleted_at"`
+}
+
+// SignedFileURL is a download link for a stored file that stops working at ExpiresAt
+type SignedFileURL struct {
+	FileID    string    `json:"fileId"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
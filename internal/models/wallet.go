@@ -9,9 +9,13 @@ import (
 type WalletType string
 
 const (
-	WalletTypePersonal WalletType = "personal"
-	WalletTypeChama    WalletType = "chama"
-	WalletTypeBusiness WalletType = "business"
+	WalletTypePersonal     WalletType = "personal"
+	WalletTypeChama        WalletType = "chama"
+	WalletTypeBusiness     WalletType = "business"
+	WalletTypeSavingsGoal  WalletType = "savings_goal"
+	WalletTypeFixedSavings WalletType = "fixed_savings"
+	WalletTypeCurrency     WalletType = "currency"     // held in a currency other than KES
+	WalletTypeDisputeHold  WalletType = "dispute_hold" // money held from a counterparty while a dispute is open
 )
 
 // TransactionType represents the type of transaction
@@ -27,6 +31,15 @@ const (
 	TransactionTypePurchase       TransactionType = "purchase"
 	TransactionTypeRefund         TransactionType = "refund"
 	TransactionTypeFee            TransactionType = "fee"
+
+	// Chama investment portfolio movements through the chama wallet
+	TransactionTypeInvestment        TransactionType = "investment"
+	TransactionTypeInvestmentIncome  TransactionType = "investment_income"
+	TransactionTypeInvestmentExpense TransactionType = "investment_expense"
+	TransactionTypeInvestmentSale    TransactionType = "investment_sale"
+
+	// Chama running costs paid from the chama wallet
+	TransactionTypeExpense TransactionType = "expense"
 )
 
 // TransactionStatus represents the status of a transaction
@@ -0,0 +1,206 @@
+package models
+
+import (
+	"time"
+)
+
+// WelfareRequestStatus represents the lifecycle status of a welfare request
+type WelfareRequestStatus string
+
+const (
+	WelfareRequestStatusPending   WelfareRequestStatus = "pending"
+	WelfareRequestStatusApproved  WelfareRequestStatus = "approved"
+	WelfareRequestStatusRejected  WelfareRequestStatus = "rejected"
+	WelfareRequestStatusDisbursed WelfareRequestStatus = "disbursed"
+)
+
+// WelfareRuleAny matches every category or urgency in a welfare approval rule
+const WelfareRuleAny = "any"
+
+// DefaultWelfareApprovalPercentage is the share of active members whose yes votes
+// approve a welfare request when the chama has not configured a rule
+const DefaultWelfareApprovalPercentage = 50.0
+
+// WelfareRequest represents a member's request for welfare support
+type WelfareRequest struct {
+	ID                        string               `json:"id" db:"id"`
+	ChamaID                   string               `json:"chamaId" db:"chama_id"`
+	RequesterID               string               `json:"requesterId" db:"requester_id"`
+	RequesterName             string               `json:"requesterName,omitempty"`
+	BeneficiaryID             string               `json:"beneficiaryId" db:"beneficiary_id"`
+	BeneficiaryName           string               `json:"beneficiaryName,omitempty"`
+	Title                     string               `json:"title" db:"title"`
+	Description               string               `json:"description" db:"description"`
+	Amount                    float64              `json:"amount" db:"amount"`
+	Category                  string               `json:"category" db:"category"`
+	Urgency                   string               `json:"urgency" db:"urgency"`
+	Status                    WelfareRequestStatus `json:"status" db:"status"`
+	VotesFor                  int                  `json:"votesFor" db:"votes_for"`
+	VotesAgainst              int                  `json:"votesAgainst" db:"votes_against"`
+	ApprovalPercentage        float64              `json:"approvalPercentage"`
+	DisbursedAmount           float64              `json:"disbursedAmount" db:"disbursed_amount"`
+	DisbursedAt               *time.Time           `json:"disbursedAt,omitempty" db:"disbursed_at"`
+	DisbursementTransactionID *string              `json:"disbursementTransactionId,omitempty" db:"disbursement_transaction_id"`
+	Documents                 []WelfareDocument    `json:"documents,omitempty"`
+	CreatedAt                 time.Time            `json:"createdAt" db:"created_at"`
+	UpdatedAt                 time.Time            `json:"updatedAt" db:"updated_at"`
+}
+
+// WelfareDocument represents a supporting document attached to a welfare request
+type WelfareDocument struct {
+	ID               string    `json:"id" db:"id"`
+	WelfareRequestID string    `json:"welfareRequestId" db:"welfare_request_id"`
+	UploadedBy       string    `json:"uploadedBy" db:"uploaded_by"`
+	FileName         string    `json:"fileName" db:"file_name"`
+	FilePath         string    `json:"-" db:"file_path"`
+	FileURL          string    `json:"url" db:"file_url"`
+	FileSize         int64     `json:"fileSize" db:"file_size"`
+	FileType         string    `json:"fileType" db:"file_type"`
+	DocumentType     string    `json:"documentType" db:"document_type"`
+	Description      string    `json:"description" db:"description"`
+	FileID           *string   `json:"fileId,omitempty" db:"file_id"` // set for documents kept by the storage service
+	CreatedAt        time.Time `json:"createdAt" db:"created_at"`
+}
+
+// WelfareApprovalRule sets the approval threshold for a category and urgency.
+// Category and Urgency may be WelfareRuleAny to match every value.
+type WelfareApprovalRule struct {
+	ID                 string    `json:"id" db:"id"`
+	ChamaID            string    `json:"chamaId" db:"chama_id"`
+	Category           string    `json:"category" db:"category"`
+	Urgency            string    `json:"urgency" db:"urgency"`
+	ApprovalPercentage float64   `json:"approvalPercentage" db:"approval_percentage"`
+	AutoDisburse       bool      `json:"autoDisburse" db:"auto_disburse"`
+	CreatedBy          string    `json:"createdBy" db:"created_by"`
+	UpdatedAt          time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// WelfareSettings represents a chama's per-member welfare claim limits.
+// A zero limit means no limit.
+type WelfareSettings struct {
+	ChamaID          string     `json:"chamaId" db:"chama_id"`
+	MaxClaimsPerYear int        `json:"maxClaimsPerYear" db:"max_claims_per_year"`
+	MaxAmountPerYear float64    `json:"maxAmountPerYear" db:"max_amount_per_year"`
+	UpdatedBy        *string    `json:"updatedBy,omitempty" db:"updated_by"`
+	UpdatedAt        *time.Time `json:"updatedAt,omitempty" db:"updated_at"`
+}
+
+// WelfareFund represents a chama's standing welfare fund that approved requests are paid from
+type WelfareFund struct {
+	ID               string  `json:"id" db:"id"`
+	ChamaID          string  `json:"chamaId" db:"chama_id"`
+	Name             string  `json:"name" db:"name"`
+	Balance          float64 `json:"balance" db:"current_amount"`
+	TotalDisbursed   float64 `json:"totalDisbursed"`
+	AwaitingPayout   int     `json:"awaitingPayout"`
+	AwaitingAmount   float64 `json:"awaitingAmount"`
+	ContributionRate float64 `json:"contributionPerMember" db:"contribution_per_member"`
+}
+
+// UpdateWelfareRequestRequest represents the request to edit a pending welfare request
+type UpdateWelfareRequestRequest struct {
+	Title         *string  `json:"title,omitempty" binding:"omitempty,min=3,max=200"`
+	Description   *string  `json:"description,omitempty" binding:"omitempty,min=3"`
+	Amount        *float64 `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	Category      *string  `json:"category,omitempty" binding:"omitempty,min=2,max=50"`
+	Urgency       *string  `json:"urgency,omitempty" binding:"omitempty,oneof=low medium high emergency"`
+	BeneficiaryID *string  `json:"beneficiaryId,omitempty"`
+}
+
+// SetWelfareApprovalRuleRequest represents the request to configure an approval threshold
+type SetWelfareApprovalRuleRequest struct {
+	Category           string  `json:"category" binding:"required,min=2,max=50"`
+	Urgency            string  `json:"urgency" binding:"required,oneof=any low medium high emergency"`
+	ApprovalPercentage float64 `json:"approvalPercentage" binding:"required,gt=0,lt=100"`
+	AutoDisburse       *bool   `json:"autoDisburse,omitempty"`
+}
+
+// UpdateWelfareSettingsRequest represents the request to configure welfare claim limits
+type UpdateWelfareSettingsRequest struct {
+	MaxClaimsPerYear *int     `json:"maxClaimsPerYear,omitempty" binding:"omitempty,min=0,max=100"`
+	MaxAmountPerYear *float64 `json:"maxAmountPerYear,omitempty" binding:"omitempty,min=0"`
+}
+
+// WelfareFundContributionRequest represents a member paying into the chama welfare fund
+type WelfareFundContributionRequest struct {
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// WelfareLevyStatus represents the status of a welfare levy
+type WelfareLevyStatus string
+
+const (
+	WelfareLevyStatusActive    WelfareLevyStatus = "active"
+	WelfareLevyStatusClosed    WelfareLevyStatus = "closed"
+	WelfareLevyStatusCancelled WelfareLevyStatus = "cancelled"
+)
+
+// LevyAssessmentStatus represents the status of a member's share of a levy
+type LevyAssessmentStatus string
+
+const (
+	LevyAssessmentStatusPending   LevyAssessmentStatus = "pending"
+	LevyAssessmentStatusPaid      LevyAssessmentStatus = "paid"
+	LevyAssessmentStatusCancelled LevyAssessmentStatus = "cancelled"
+)
+
+// WelfareLevy represents a per-member assessment raised for an approved welfare case
+type WelfareLevy struct {
+	ID                    string                  `json:"id" db:"id"`
+	ChamaID               string                  `json:"chamaId" db:"chama_id"`
+	WelfareRequestID      string                  `json:"welfareRequestId" db:"welfare_request_id"`
+	Title                 string                  `json:"title" db:"title"`
+	AmountPerMember       float64                 `json:"amountPerMember" db:"amount_per_member"`
+	TargetAmount          float64                 `json:"targetAmount" db:"target_amount"`
+	CollectedAmount       float64                 `json:"collectedAmount" db:"collected_amount"`
+	Status                WelfareLevyStatus       `json:"status" db:"status"`
+	DueDate               *time.Time              `json:"dueDate,omitempty" db:"due_date"`
+	ReminderIntervalHours int                     `json:"reminderIntervalHours" db:"reminder_interval_hours"`
+	LastReminderAt        *time.Time              `json:"lastReminderAt,omitempty" db:"last_reminder_at"`
+	CreatedBy             string                  `json:"createdBy" db:"created_by"`
+	ClosedAt              *time.Time              `json:"closedAt,omitempty" db:"closed_at"`
+	PaidCount             int                     `json:"paidCount"`
+	PendingCount          int                     `json:"pendingCount"`
+	Assessments           []WelfareLevyAssessment `json:"assessments,omitempty"`
+	CreatedAt             time.Time               `json:"createdAt" db:"created_at"`
+	UpdatedAt             time.Time               `json:"updatedAt" db:"updated_at"`
+}
+
+// WelfareLevyAssessment represents what one member owes against a levy
+type WelfareLevyAssessment struct {
+	ID            string               `json:"id" db:"id"`
+	LevyID        string               `json:"levyId" db:"levy_id"`
+	UserID        string               `json:"userId" db:"user_id"`
+	MemberName    string               `json:"memberName,omitempty"`
+	Amount        float64              `json:"amount" db:"amount"`
+	Status        LevyAssessmentStatus `json:"status" db:"status"`
+	PaymentMethod *string              `json:"paymentMethod,omitempty" db:"payment_method"`
+	TransactionID *string              `json:"transactionId,omitempty" db:"transaction_id"`
+	RemindersSent int                  `json:"remindersSent" db:"reminders_sent"`
+	PaidAt        *time.Time           `json:"paidAt,omitempty" db:"paid_at"`
+}
+
+// WelfareLevyConsent records a member's standing consent to have levies deducted from their wallet
+type WelfareLevyConsent struct {
+	ChamaID   string     `json:"chamaId" db:"chama_id"`
+	UserID    string     `json:"userId" db:"user_id"`
+	Granted   bool       `json:"granted"`
+	MaxAmount float64    `json:"maxAmount" db:"max_amount"`
+	GrantedAt *time.Time `json:"grantedAt,omitempty" db:"granted_at"`
+}
+
+// RaiseWelfareLevyRequest represents an official raising a levy for an approved welfare case
+type RaiseWelfareLevyRequest struct {
+	WelfareRequestID      string     `json:"welfareRequestId" binding:"required"`
+	AmountPerMember       *float64   `json:"amountPerMember,omitempty" binding:"omitempty,gt=0"`
+	TargetAmount          *float64   `json:"targetAmount,omitempty" binding:"omitempty,gt=0"`
+	DueDate               *time.Time `json:"dueDate,omitempty"`
+	ReminderIntervalHours *int       `json:"reminderIntervalHours,omitempty" binding:"omitempty,min=1,max=168"`
+	IncludeBeneficiary    bool       `json:"includeBeneficiary"`
+}
+
+// UpdateLevyConsentRequest represents a member granting or withdrawing wallet deduction consent
+type UpdateLevyConsentRequest struct {
+	Granted   *bool    `json:"granted" binding:"required"`
+	MaxAmount *float64 `json:"maxAmount,omitempty" binding:"omitempty,min=0"`
+}
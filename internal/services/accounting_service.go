@@ -0,0 +1,1104 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// defaultLedgerAccount is an account every chart of accounts starts with, and the
+// entry types that post to it until officials choose otherwise
+type defaultLedgerAccount struct {
+	code       string
+	name       string
+	kind       models.LedgerAccountType
+	entryTypes []models.LedgerEntryType
+}
+
+var defaultLedgerAccounts = []defaultLedgerAccount{
+	{"1000", "Cash and Bank", models.LedgerAccountAsset, []models.LedgerEntryType{models.LedgerEntryCash}},
+	{"1200", "Loans to Members", models.LedgerAccountAsset, []models.LedgerEntryType{models.LedgerEntryLoan, models.LedgerEntryLoanRepayment}},
+	{"1300", "Investments", models.LedgerAccountAsset, []models.LedgerEntryType{models.LedgerEntryInvestment}},
+	{"1400", "Welfare Fund", models.LedgerAccountAsset, []models.LedgerEntryType{models.LedgerEntryWelfareFund}},
+	{"3000", "Share Capital", models.LedgerAccountEquity, []models.LedgerEntryType{models.LedgerEntryShareCapital}},
+	{"3100", "Member Contributions", models.LedgerAccountEquity, []models.LedgerEntryType{models.LedgerEntryContribution}},
+	{"3200", "Retained Earnings", models.LedgerAccountEquity, []models.LedgerEntryType{models.LedgerEntryRetainedEarnings}},
+	{"3300", "Dividends Paid", models.LedgerAccountEquity, []models.LedgerEntryType{models.LedgerEntryDividend}},
+	{"3400", "Welfare Reserve", models.LedgerAccountEquity, []models.LedgerEntryType{models.LedgerEntryWelfareReserve}},
+	{"4000", "Loan Interest Income", models.LedgerAccountIncome, []models.LedgerEntryType{models.LedgerEntryLoanInterest}},
+	{"4100", "Fines and Penalties", models.LedgerAccountIncome, []models.LedgerEntryType{models.LedgerEntryFine}},
+	{"4200", "Investment Income", models.LedgerAccountIncome, []models.LedgerEntryType{models.LedgerEntryInvestmentIncome}},
+	{"5000", "Operating Expenses", models.LedgerAccountExpense, []models.LedgerEntryType{models.LedgerEntryExpense}},
+	{"5100", "Investment Expenses", models.LedgerAccountExpense, []models.LedgerEntryType{models.LedgerEntryInvestmentExpense}},
+}
+
+// ledgerMappingTypes lists the account types each entry type may post to, so a
+// remapping cannot, say, send contributions to an expense account
+var ledgerMappingTypes = map[models.LedgerEntryType][]models.LedgerAccountType{
+	models.LedgerEntryCash:              {models.LedgerAccountAsset},
+	models.LedgerEntryContribution:      {models.LedgerAccountEquity, models.LedgerAccountLiability},
+	models.LedgerEntryShareCapital:      {models.LedgerAccountEquity},
+	models.LedgerEntryFine:              {models.LedgerAccountIncome},
+	models.LedgerEntryLoan:              {models.LedgerAccountAsset},
+	models.LedgerEntryLoanRepayment:     {models.LedgerAccountAsset},
+	models.LedgerEntryLoanInterest:      {models.LedgerAccountIncome},
+	models.LedgerEntryExpense:           {models.LedgerAccountExpense},
+	models.LedgerEntryDividend:          {models.LedgerAccountEquity},
+	models.LedgerEntryInvestment:        {models.LedgerAccountAsset},
+	models.LedgerEntryInvestmentIncome:  {models.LedgerAccountIncome},
+	models.LedgerEntryInvestmentExpense: {models.LedgerAccountExpense},
+	models.LedgerEntryRetainedEarnings:  {models.LedgerAccountEquity},
+	models.LedgerEntryWelfareFund:       {models.LedgerAccountAsset},
+	models.LedgerEntryWelfareReserve:    {models.LedgerAccountEquity, models.LedgerAccountLiability},
+}
+
+// AccountingService keeps each chama's chart of accounts and produces its ledger and
+// financial statements. The ledger is not stored: journal entries are derived from
+// the chama's transactions, loans and dividend payments whenever a report is run, so
+// reports always agree with the records they summarise.
+type AccountingService struct {
+	db *sql.DB
+}
+
+// NewAccountingService creates a new accounting service
+func NewAccountingService(db *sql.DB) *AccountingService {
+	return &AccountingService{db: db}
+}
+
+// GetChartOfAccounts returns the chama's accounts and mappings, creating the default
+// chart the first time it is needed
+func (s *AccountingService) GetChartOfAccounts(chamaID, userID string) (*models.ChartOfAccounts, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	if err := s.ensureChartOfAccounts(chamaID); err != nil {
+		return nil, err
+	}
+	return s.getChartOfAccounts(chamaID)
+}
+
+// CreateLedgerAccount adds an account to the chama's chart of accounts
+func (s *AccountingService) CreateLedgerAccount(chamaID, userID string, req *models.CreateLedgerAccountRequest) (*models.LedgerAccount, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can manage the chart of accounts")
+	}
+	if err := s.ensureChartOfAccounts(chamaID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	account := &models.LedgerAccount{
+		ID:          uuid.New().String(),
+		ChamaID:     chamaID,
+		Code:        strings.TrimSpace(req.Code),
+		Name:        strings.TrimSpace(req.Name),
+		Type:        models.LedgerAccountType(req.Type),
+		Description: req.Description,
+		IsActive:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	_, err = tx.Exec(`
+		INSERT INTO chama_ledger_accounts (id, chama_id, code, name, account_type, description, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, TRUE, ?, ?)
+	`, account.ID, chamaID, account.Code, account.Name, account.Type, account.Description, now, now)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return nil, fmt.Errorf("account code %s is already in use", account.Code)
+		}
+		return nil, fmt.Errorf("failed to create ledger account: %w", err)
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "ledger_account",
+		EntityID:   account.ID,
+		Details: map[string]interface{}{
+			"code": account.Code,
+			"name": account.Name,
+			"type": account.Type,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return account, nil
+}
+
+// UpdateLedgerAccount renames or deactivates an account. An account that activity
+// still posts to cannot be deactivated.
+func (s *AccountingService) UpdateLedgerAccount(chamaID, accountID, userID string, req *models.UpdateLedgerAccountRequest) (*models.LedgerAccount, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can manage the chart of accounts")
+	}
+	account, err := s.getLedgerAccount(chamaID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil && strings.TrimSpace(*req.Name) != "" {
+		account.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		account.Description = optionalString(*req.Description)
+	}
+	if req.IsActive != nil {
+		if !*req.IsActive {
+			var mapped int
+			err := s.db.QueryRow("SELECT COUNT(*) FROM chama_ledger_mappings WHERE chama_id = ? AND account_id = ?", chamaID, accountID).Scan(&mapped)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check ledger mappings: %w", err)
+			}
+			if mapped > 0 {
+				return nil, fmt.Errorf("map its entry types to another account before deactivating this account")
+			}
+		}
+		account.IsActive = *req.IsActive
+	}
+	account.UpdatedAt = time.Now()
+
+	_, err = s.db.Exec(`
+		UPDATE chama_ledger_accounts SET name = ?, description = ?, is_active = ?, updated_at = ? WHERE id = ?
+	`, account.Name, account.Description, account.IsActive, account.UpdatedAt, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update ledger account: %w", err)
+	}
+	return account, nil
+}
+
+// SetLedgerMapping points an entry type at another account of a suitable type.
+// Reports are derived, so past periods are restated under the new mapping.
+func (s *AccountingService) SetLedgerMapping(chamaID, userID string, req *models.SetLedgerMappingRequest) (*models.ChartOfAccounts, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can manage the chart of accounts")
+	}
+	if err := s.ensureChartOfAccounts(chamaID); err != nil {
+		return nil, err
+	}
+	entryType := models.LedgerEntryType(req.EntryType)
+	allowed, ok := ledgerMappingTypes[entryType]
+	if !ok {
+		return nil, fmt.Errorf("unknown entry type %s", req.EntryType)
+	}
+	account, err := s.getLedgerAccount(chamaID, req.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.IsActive {
+		return nil, fmt.Errorf("account %s is inactive", account.Code)
+	}
+	suitable := false
+	for _, kind := range allowed {
+		suitable = suitable || account.Type == kind
+	}
+	if !suitable {
+		return nil, fmt.Errorf("%s entries cannot post to %s account %s", entryType, account.Type, account.Code)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO chama_ledger_mappings (id, chama_id, entry_type, account_id, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(chama_id, entry_type) DO UPDATE SET
+			account_id = excluded.account_id, updated_by = excluded.updated_by, updated_at = excluded.updated_at
+	`, uuid.New().String(), chamaID, entryType, account.ID, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to set ledger mapping: %w", err)
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "ledger_mapping",
+		EntityID:   string(entryType),
+		Details: map[string]interface{}{
+			"accountId":   account.ID,
+			"accountCode": account.Code,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.getChartOfAccounts(chamaID)
+}
+
+// GetJournal returns the journal entries dated from from up to, but not including, to
+func (s *AccountingService) GetJournal(chamaID, userID string, from, to time.Time) ([]models.JournalEntry, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	ledger, err := s.loadLedger(chamaID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []models.JournalEntry{}
+	for _, entry := range ledger.entries {
+		if !entry.Date.Before(from) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// GetGeneralLedger returns each account's postings for a period, with the balances
+// brought forward and carried down
+func (s *AccountingService) GetGeneralLedger(chamaID, userID string, from, to time.Time) (*models.GeneralLedger, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	ledger, err := s.loadLedger(chamaID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := map[string]*models.GeneralLedgerAccount{}
+	account := func(line models.JournalLine) *models.GeneralLedgerAccount {
+		if accounts[line.AccountCode] == nil {
+			accounts[line.AccountCode] = &models.GeneralLedgerAccount{
+				Code:  line.AccountCode,
+				Name:  line.AccountName,
+				Type:  line.AccountType,
+				Lines: []models.GeneralLedgerLine{},
+			}
+		}
+		return accounts[line.AccountCode]
+	}
+
+	for _, entry := range ledger.entries {
+		for _, line := range entry.Lines {
+			ledgerAccount := account(line)
+			movement := line.Debit - line.Credit
+			if !line.AccountType.DebitNormal() {
+				movement = -movement
+			}
+			if entry.Date.Before(from) {
+				ledgerAccount.OpeningBalance += movement
+				ledgerAccount.ClosingBalance += movement
+				continue
+			}
+			ledgerAccount.ClosingBalance += movement
+			ledgerAccount.TotalDebits += line.Debit
+			ledgerAccount.TotalCredits += line.Credit
+			ledgerAccount.Lines = append(ledgerAccount.Lines, models.GeneralLedgerLine{
+				Date:        entry.Date,
+				EntryID:     entry.ID,
+				Reference:   entry.Reference,
+				Description: entry.Description,
+				Debit:       line.Debit,
+				Credit:      line.Credit,
+				Balance:     roundCurrency(ledgerAccount.ClosingBalance),
+			})
+		}
+	}
+
+	report := &models.GeneralLedger{ChamaID: chamaID, From: from, To: to, Accounts: []models.GeneralLedgerAccount{}}
+	for _, ledgerAccount := range accounts {
+		ledgerAccount.OpeningBalance = roundCurrency(ledgerAccount.OpeningBalance)
+		ledgerAccount.ClosingBalance = roundCurrency(ledgerAccount.ClosingBalance)
+		ledgerAccount.TotalDebits = roundCurrency(ledgerAccount.TotalDebits)
+		ledgerAccount.TotalCredits = roundCurrency(ledgerAccount.TotalCredits)
+		if len(ledgerAccount.Lines) == 0 && ledgerAccount.OpeningBalance == 0 {
+			continue
+		}
+		report.Accounts = append(report.Accounts, *ledgerAccount)
+	}
+	sort.Slice(report.Accounts, func(i, j int) bool { return report.Accounts[i].Code < report.Accounts[j].Code })
+	return report, nil
+}
+
+// GetTrialBalance lists account balances before asOf
+func (s *AccountingService) GetTrialBalance(chamaID, userID string, asOf time.Time) (*models.TrialBalance, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	ledger, err := s.loadLedger(chamaID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.TrialBalance{ChamaID: chamaID, AsOf: asOf}
+	report.Lines, report.TotalDebits, report.TotalCredits = trialBalanceLines(ledger.balances(time.Time{}, true))
+	report.Balanced = math.Abs(report.TotalDebits-report.TotalCredits) < 0.005
+	return report, nil
+}
+
+// trialBalanceLines puts each non-zero balance in the debit or credit column and
+// totals the columns
+func trialBalanceLines(balances []ledgerBalance) ([]models.TrialBalanceLine, float64, float64) {
+	lines := []models.TrialBalanceLine{}
+	var debits, credits float64
+	for _, balance := range balances {
+		net := roundCurrency(balance.debits - balance.credits)
+		if net == 0 {
+			continue
+		}
+		line := models.TrialBalanceLine{Code: balance.code, Name: balance.name, Type: balance.kind}
+		if net > 0 {
+			line.Debit = net
+		} else {
+			line.Credit = -net
+		}
+		debits += line.Debit
+		credits += line.Credit
+		lines = append(lines, line)
+	}
+	return lines, roundCurrency(debits), roundCurrency(credits)
+}
+
+// GetIncomeStatement reports income and expenses dated from from up to to. Year-end
+// closing entries are left out, since they move the year's result to retained
+// earnings rather than earn or spend anything.
+func (s *AccountingService) GetIncomeStatement(chamaID, userID string, from, to time.Time) (*models.IncomeStatement, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	ledger, err := s.loadLedger(chamaID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.IncomeStatement{
+		ChamaID:  chamaID,
+		From:     from,
+		To:       to,
+		Income:   []models.FinancialStatementLine{},
+		Expenses: []models.FinancialStatementLine{},
+	}
+	for _, balance := range ledger.balances(from, false) {
+		switch balance.kind {
+		case models.LedgerAccountIncome:
+			amount := roundCurrency(balance.credits - balance.debits)
+			if amount != 0 {
+				report.Income = append(report.Income, models.FinancialStatementLine{Code: balance.code, Name: balance.name, Amount: amount})
+				report.TotalIncome += amount
+			}
+		case models.LedgerAccountExpense:
+			amount := roundCurrency(balance.debits - balance.credits)
+			if amount != 0 {
+				report.Expenses = append(report.Expenses, models.FinancialStatementLine{Code: balance.code, Name: balance.name, Amount: amount})
+				report.TotalExpenses += amount
+			}
+		}
+	}
+	report.TotalIncome = roundCurrency(report.TotalIncome)
+	report.TotalExpenses = roundCurrency(report.TotalExpenses)
+	report.NetIncome = roundCurrency(report.TotalIncome - report.TotalExpenses)
+	return report, nil
+}
+
+// GetBalanceSheet reports assets, liabilities and equity before asOf
+func (s *AccountingService) GetBalanceSheet(chamaID, userID string, asOf time.Time) (*models.BalanceSheet, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	ledger, err := s.loadLedger(chamaID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.BalanceSheet{
+		ChamaID:     chamaID,
+		AsOf:        asOf,
+		Assets:      []models.FinancialStatementLine{},
+		Liabilities: []models.FinancialStatementLine{},
+		Equity:      []models.FinancialStatementLine{},
+	}
+	for _, balance := range ledger.balances(time.Time{}, true) {
+		debitBalance := roundCurrency(balance.debits - balance.credits)
+		line := models.FinancialStatementLine{Code: balance.code, Name: balance.name, Amount: -debitBalance}
+		switch balance.kind {
+		case models.LedgerAccountAsset:
+			line.Amount = debitBalance
+			if line.Amount != 0 {
+				report.Assets = append(report.Assets, line)
+				report.TotalAssets += line.Amount
+			}
+		case models.LedgerAccountLiability:
+			if line.Amount != 0 {
+				report.Liabilities = append(report.Liabilities, line)
+				report.TotalLiabilities += line.Amount
+			}
+		case models.LedgerAccountEquity:
+			if line.Amount != 0 {
+				report.Equity = append(report.Equity, line)
+				report.TotalEquity += line.Amount
+			}
+		default:
+			report.CurrentEarnings -= debitBalance
+		}
+	}
+	report.CurrentEarnings = roundCurrency(report.CurrentEarnings)
+	report.TotalAssets = roundCurrency(report.TotalAssets)
+	report.TotalLiabilities = roundCurrency(report.TotalLiabilities)
+	report.TotalEquity = roundCurrency(report.TotalEquity + report.CurrentEarnings)
+	report.Balanced = math.Abs(report.TotalAssets-report.TotalLiabilities-report.TotalEquity) < 0.005
+	return report, nil
+}
+
+// ExportReport renders a report for download. CSV is available for every report;
+// the QuickBooks and Xero formats are journal imports, so they export the journal
+// for the period whichever report is asked for.
+func (s *AccountingService) ExportReport(chamaID, userID, report, format string, from, to time.Time) ([]byte, string, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	period := fmt.Sprintf("%s_%s", from.Format("20060102"), to.AddDate(0, 0, -1).Format("20060102"))
+
+	if format == models.AccountingExportQuickBooks || format == models.AccountingExportXero {
+		entries, err := s.GetJournal(chamaID, userID, from, to)
+		if err != nil {
+			return nil, "", err
+		}
+		if format == models.AccountingExportQuickBooks {
+			writeQuickBooksJournal(writer, entries)
+		} else {
+			writeXeroJournal(writer, entries)
+		}
+		writer.Flush()
+		return buffer.Bytes(), fmt.Sprintf("journal_%s_%s.csv", format, period), writer.Error()
+	}
+	if format != models.AccountingExportCSV {
+		return nil, "", fmt.Errorf("unsupported export format %s", format)
+	}
+
+	switch report {
+	case "journal":
+		entries, err := s.GetJournal(chamaID, userID, from, to)
+		if err != nil {
+			return nil, "", err
+		}
+		writer.Write([]string{"Date", "Entry", "Reference", "Description", "Account Code", "Account", "Debit", "Credit"})
+		for _, entry := range entries {
+			for _, line := range entry.Lines {
+				writer.Write([]string{formatLedgerDate(entry.Date), entry.ID, stringValue(entry.Reference), entry.Description,
+					line.AccountCode, line.AccountName, formatLedgerAmount(line.Debit), formatLedgerAmount(line.Credit)})
+			}
+		}
+	case "general_ledger":
+		ledger, err := s.GetGeneralLedger(chamaID, userID, from, to)
+		if err != nil {
+			return nil, "", err
+		}
+		writer.Write([]string{"Account Code", "Account", "Date", "Entry", "Reference", "Description", "Debit", "Credit", "Balance"})
+		for _, account := range ledger.Accounts {
+			writer.Write([]string{account.Code, account.Name, formatLedgerDate(from), "", "", "Balance brought forward", "", "", formatLedgerAmount(account.OpeningBalance)})
+			for _, line := range account.Lines {
+				writer.Write([]string{account.Code, account.Name, formatLedgerDate(line.Date), line.EntryID, stringValue(line.Reference),
+					line.Description, formatLedgerAmount(line.Debit), formatLedgerAmount(line.Credit), formatLedgerAmount(line.Balance)})
+			}
+			writer.Write([]string{account.Code, account.Name, formatLedgerDate(to.AddDate(0, 0, -1)), "", "", "Balance carried down",
+				formatLedgerAmount(account.TotalDebits), formatLedgerAmount(account.TotalCredits), formatLedgerAmount(account.ClosingBalance)})
+		}
+	case "trial_balance":
+		trialBalance, err := s.GetTrialBalance(chamaID, userID, to)
+		if err != nil {
+			return nil, "", err
+		}
+		writer.Write([]string{"Account Code", "Account", "Type", "Debit", "Credit"})
+		for _, line := range trialBalance.Lines {
+			writer.Write([]string{line.Code, line.Name, string(line.Type), formatLedgerAmount(line.Debit), formatLedgerAmount(line.Credit)})
+		}
+		writer.Write([]string{"", "Total", "", formatLedgerAmount(trialBalance.TotalDebits), formatLedgerAmount(trialBalance.TotalCredits)})
+		period = to.AddDate(0, 0, -1).Format("20060102")
+	case "income_statement":
+		statement, err := s.GetIncomeStatement(chamaID, userID, from, to)
+		if err != nil {
+			return nil, "", err
+		}
+		writer.Write([]string{"Section", "Account Code", "Account", "Amount"})
+		for _, line := range statement.Income {
+			writer.Write([]string{"Income", line.Code, line.Name, formatLedgerAmount(line.Amount)})
+		}
+		writer.Write([]string{"Income", "", "Total income", formatLedgerAmount(statement.TotalIncome)})
+		for _, line := range statement.Expenses {
+			writer.Write([]string{"Expenses", line.Code, line.Name, formatLedgerAmount(line.Amount)})
+		}
+		writer.Write([]string{"Expenses", "", "Total expenses", formatLedgerAmount(statement.TotalExpenses)})
+		writer.Write([]string{"", "", "Net income", formatLedgerAmount(statement.NetIncome)})
+	case "balance_sheet":
+		sheet, err := s.GetBalanceSheet(chamaID, userID, to)
+		if err != nil {
+			return nil, "", err
+		}
+		writer.Write([]string{"Section", "Account Code", "Account", "Amount"})
+		for _, line := range sheet.Assets {
+			writer.Write([]string{"Assets", line.Code, line.Name, formatLedgerAmount(line.Amount)})
+		}
+		writer.Write([]string{"Assets", "", "Total assets", formatLedgerAmount(sheet.TotalAssets)})
+		for _, line := range sheet.Liabilities {
+			writer.Write([]string{"Liabilities", line.Code, line.Name, formatLedgerAmount(line.Amount)})
+		}
+		writer.Write([]string{"Liabilities", "", "Total liabilities", formatLedgerAmount(sheet.TotalLiabilities)})
+		for _, line := range sheet.Equity {
+			writer.Write([]string{"Equity", line.Code, line.Name, formatLedgerAmount(line.Amount)})
+		}
+		writer.Write([]string{"Equity", "", "Current earnings", formatLedgerAmount(sheet.CurrentEarnings)})
+		writer.Write([]string{"Equity", "", "Total equity", formatLedgerAmount(sheet.TotalEquity)})
+		period = to.AddDate(0, 0, -1).Format("20060102")
+	default:
+		return nil, "", fmt.Errorf("unknown report %s", report)
+	}
+
+	writer.Flush()
+	return buffer.Bytes(), fmt.Sprintf("%s_%s.csv", report, period), writer.Error()
+}
+
+// writeQuickBooksJournal writes journal entries in QuickBooks Online's journal entry
+// import layout. Lines of an entry share its journal number, and accounts are
+// matched by name.
+func writeQuickBooksJournal(writer *csv.Writer, entries []models.JournalEntry) {
+	writer.Write([]string{"JournalNo", "JournalDate", "AccountName", "Debits", "Credits", "Description", "Memo"})
+	for _, entry := range entries {
+		for _, line := range entry.Lines {
+			writer.Write([]string{journalNumber(entry), formatLedgerDate(entry.Date), line.AccountName,
+				formatLedgerAmount(line.Debit), formatLedgerAmount(line.Credit), entry.Description, stringValue(entry.Reference)})
+		}
+	}
+}
+
+// writeXeroJournal writes journal entries in Xero's manual journal import layout,
+// where debits are positive amounts and credits negative ones
+func writeXeroJournal(writer *csv.Writer, entries []models.JournalEntry) {
+	writer.Write([]string{"*Narration", "*Date", "Description", "*AccountCode", "*TaxRate", "*Amount",
+		"TrackingName1", "TrackingOption1", "TrackingName2", "TrackingOption2"})
+	for _, entry := range entries {
+		narration := journalNumber(entry) + " " + entry.Description
+		for _, line := range entry.Lines {
+			writer.Write([]string{narration, formatLedgerDate(entry.Date), entry.Description, line.AccountCode,
+				"Tax Exempt", strconv.FormatFloat(roundCurrency(line.Debit-line.Credit), 'f', 2, 64), "", "", "", ""})
+		}
+	}
+}
+
+// journalNumber is a short, stable number for an entry, built from its source
+func journalNumber(entry models.JournalEntry) string {
+	prefixes := map[string]string{"transaction": "TX", "loan": "LN", "loan_payment": "LP", "dividend_payment": "DV", periodCloseSource: "YE"}
+	prefix, ok := prefixes[entry.Source]
+	if !ok {
+		prefix = strings.ToUpper(entry.Source)
+	}
+	id := entry.SourceID
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return prefix + "-" + id
+}
+
+func formatLedgerDate(date time.Time) string {
+	return date.In(utils.EATLocation).Format("02/01/2006")
+}
+
+func formatLedgerAmount(amount float64) string {
+	if amount == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(roundCurrency(amount), 'f', 2, 64)
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// chamaLedger is a chama's chart of accounts and the journal entries dated before a
+// cutoff, in date order
+type chamaLedger struct {
+	accounts map[string]models.LedgerAccount
+	mappings map[models.LedgerEntryType]models.LedgerAccount
+	entries  []models.JournalEntry
+}
+
+// ledgerPosting is one side of an entry to be built: a positive amount is a debit
+// and a negative amount a credit to the account the entry type maps to
+type ledgerPosting struct {
+	entryType models.LedgerEntryType
+	amount    float64
+}
+
+// add appends a journal entry, leaving out zero postings
+func (l *chamaLedger) add(date time.Time, entryType models.LedgerEntryType, source, sourceID string, reference *string, description string, postings ...ledgerPosting) {
+	entry := models.JournalEntry{
+		ID:          source + ":" + sourceID,
+		Date:        date,
+		EntryType:   entryType,
+		Source:      source,
+		SourceID:    sourceID,
+		Reference:   reference,
+		Description: description,
+	}
+	for _, posting := range postings {
+		amount := roundCurrency(posting.amount)
+		if amount == 0 {
+			continue
+		}
+		account := l.mappings[posting.entryType]
+		line := models.JournalLine{AccountCode: account.Code, AccountName: account.Name, AccountType: account.Type}
+		if amount > 0 {
+			line.Debit = amount
+		} else {
+			line.Credit = -amount
+		}
+		entry.Lines = append(entry.Lines, line)
+	}
+	if len(entry.Lines) > 0 {
+		l.entries = append(l.entries, entry)
+	}
+}
+
+// ledgerBalance is the debits and credits posted to an account
+type ledgerBalance struct {
+	code, name      string
+	kind            models.LedgerAccountType
+	debits, credits float64
+}
+
+// balances totals postings per account for entries dated from from onwards, in
+// account code order, optionally leaving out year-end closing entries
+func (l *chamaLedger) balances(from time.Time, closing bool) []ledgerBalance {
+	byCode := map[string]*ledgerBalance{}
+	for _, entry := range l.entries {
+		if entry.Date.Before(from) || (!closing && entry.Source == periodCloseSource) {
+			continue
+		}
+		for _, line := range entry.Lines {
+			balance := byCode[line.AccountCode]
+			if balance == nil {
+				balance = &ledgerBalance{code: line.AccountCode, name: line.AccountName, kind: line.AccountType}
+				byCode[line.AccountCode] = balance
+			}
+			balance.debits += line.Debit
+			balance.credits += line.Credit
+		}
+	}
+
+	balances := make([]ledgerBalance, 0, len(byCode))
+	for _, balance := range byCode {
+		balances = append(balances, *balance)
+	}
+	sort.Slice(balances, func(i, j int) bool { return balances[i].code < balances[j].code })
+	return balances
+}
+
+// loadLedger derives the chama's journal entries dated before to. Cash moves in the
+// chama's favour are debited to the cash account; the other side goes to the
+// account the activity maps to.
+func (s *AccountingService) loadLedger(chamaID string, to time.Time) (*chamaLedger, error) {
+	if err := s.ensureChartOfAccounts(chamaID); err != nil {
+		return nil, err
+	}
+	chart, err := s.getChartOfAccounts(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	ledger := &chamaLedger{
+		accounts: map[string]models.LedgerAccount{},
+		mappings: map[models.LedgerEntryType]models.LedgerAccount{},
+	}
+	for _, account := range chart.Accounts {
+		ledger.accounts[account.ID] = account
+	}
+	for _, mapping := range chart.Mappings {
+		ledger.mappings[mapping.EntryType] = ledger.accounts[mapping.AccountID]
+	}
+
+	if err := s.loadTransactionEntries(ledger, chamaID, to); err != nil {
+		return nil, err
+	}
+	if err := s.loadLoanEntries(ledger, chamaID, to); err != nil {
+		return nil, err
+	}
+	if err := s.loadDividendEntries(ledger, chamaID, to); err != nil {
+		return nil, err
+	}
+	if err := s.loadClosingEntries(ledger, chamaID, to); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(ledger.entries, func(i, j int) bool {
+		return ledger.entries[i].Date.Before(ledger.entries[j].Date)
+	})
+	return ledger, nil
+}
+
+// loadTransactionEntries posts completed chama transactions. Fines are paid as
+// contributions marked as penalties. Welfare contributions and levies go to the
+// welfare fund rather than the chama wallet, so they and welfare payouts post to the
+// welfare fund and its reserve instead of cash. Transactions that do not change the
+// chama's position, such as merry-go-round payouts to members, are not posted.
+func (s *AccountingService) loadTransactionEntries(ledger *chamaLedger, chamaID string, to time.Time) error {
+	var walletID string
+	err := s.db.QueryRow("SELECT id FROM wallets WHERE owner_id = ? AND type = 'chama'", chamaID).Scan(&walletID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get chama wallet: %w", err)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, type, amount, description, reference, created_at,
+			CASE WHEN json_valid(metadata) THEN json_extract(metadata, '$.contributionType') END
+		FROM transactions
+		WHERE status = 'completed' AND (
+			recipient_id = ? OR from_wallet_id = ? OR to_wallet_id = ?
+			OR (type IN ('share_redemption', 'welfare_disbursement') AND json_valid(metadata) AND json_extract(metadata, '$.chamaId') = ?)
+		)
+		AND type IN ('contribution', 'share_purchase', 'share_redemption', 'welfare_disbursement', 'expense',
+			'investment', 'investment_income', 'investment_expense', 'investment_sale')
+	`, chamaID, walletID, walletID, chamaID)
+	if err != nil {
+		return fmt.Errorf("failed to get chama transactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, txType string
+		var amount float64
+		var description, reference, contributionType sql.NullString
+		var date time.Time
+		if err := rows.Scan(&id, &txType, &amount, &description, &reference, &date, &contributionType); err != nil {
+			return fmt.Errorf("failed to scan chama transaction: %w", err)
+		}
+		if !date.Before(to) {
+			continue
+		}
+
+		text := description.String
+		if text == "" {
+			text = strings.ReplaceAll(txType, "_", " ")
+		}
+		ref := nullStringPtr(reference)
+		cash := models.LedgerEntryCash
+		switch txType {
+		case "contribution":
+			if contributionType.String == "welfare" || contributionType.String == "welfare_levy" {
+				ledger.add(date, models.LedgerEntryWelfareReserve, "transaction", id, ref, text,
+					ledgerPosting{models.LedgerEntryWelfareFund, amount}, ledgerPosting{models.LedgerEntryWelfareReserve, -amount})
+			} else if contributionType.String == "penalty" {
+				ledger.add(date, models.LedgerEntryFine, "transaction", id, ref, text,
+					ledgerPosting{cash, amount}, ledgerPosting{models.LedgerEntryFine, -amount})
+			} else {
+				ledger.add(date, models.LedgerEntryContribution, "transaction", id, ref, text,
+					ledgerPosting{cash, amount}, ledgerPosting{models.LedgerEntryContribution, -amount})
+			}
+		case "share_purchase":
+			ledger.add(date, models.LedgerEntryShareCapital, "transaction", id, ref, text,
+				ledgerPosting{cash, amount}, ledgerPosting{models.LedgerEntryShareCapital, -amount})
+		case "share_redemption":
+			ledger.add(date, models.LedgerEntryShareCapital, "transaction", id, ref, text,
+				ledgerPosting{models.LedgerEntryShareCapital, amount}, ledgerPosting{cash, -amount})
+		case "welfare_disbursement":
+			ledger.add(date, models.LedgerEntryWelfareReserve, "transaction", id, ref, text,
+				ledgerPosting{models.LedgerEntryWelfareReserve, amount}, ledgerPosting{models.LedgerEntryWelfareFund, -amount})
+		case "expense":
+			ledger.add(date, models.LedgerEntryExpense, "transaction", id, ref, text,
+				ledgerPosting{models.LedgerEntryExpense, amount}, ledgerPosting{cash, -amount})
+		case "investment":
+			ledger.add(date, models.LedgerEntryInvestment, "transaction", id, ref, text,
+				ledgerPosting{models.LedgerEntryInvestment, amount}, ledgerPosting{cash, -amount})
+		case "investment_sale":
+			ledger.add(date, models.LedgerEntryInvestment, "transaction", id, ref, text,
+				ledgerPosting{cash, amount}, ledgerPosting{models.LedgerEntryInvestment, -amount})
+		case "investment_income":
+			ledger.add(date, models.LedgerEntryInvestmentIncome, "transaction", id, ref, text,
+				ledgerPosting{cash, amount}, ledgerPosting{models.LedgerEntryInvestmentIncome, -amount})
+		case "investment_expense":
+			ledger.add(date, models.LedgerEntryInvestmentExpense, "transaction", id, ref, text,
+				ledgerPosting{models.LedgerEntryInvestmentExpense, amount}, ledgerPosting{cash, -amount})
+		}
+	}
+	return rows.Err()
+}
+
+// loadLoanEntries posts loan disbursements and repayments, splitting repayments into
+// principal, which reduces the loan, and interest, which is income
+func (s *AccountingService) loadLoanEntries(ledger *chamaLedger, chamaID string, to time.Time) error {
+	rows, err := s.db.Query(`
+		SELECT l.id, l.amount, l.purpose, l.disbursed_at, COALESCE(u.first_name || ' ' || u.last_name, '')
+		FROM loans l
+		LEFT JOIN users u ON u.id = l.borrower_id
+		WHERE l.chama_id = ? AND l.disbursed_at IS NOT NULL
+	`, chamaID)
+	if err != nil {
+		return fmt.Errorf("failed to get loans: %w", err)
+	}
+	for rows.Next() {
+		var id, purpose, borrower string
+		var amount float64
+		var disbursedAt time.Time
+		if err := rows.Scan(&id, &amount, &purpose, &disbursedAt, &borrower); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan loan: %w", err)
+		}
+		if disbursedAt.Before(to) {
+			ledger.add(disbursedAt, models.LedgerEntryLoan, "loan", id, nil,
+				strings.TrimSpace(fmt.Sprintf("Loan to %s: %s", borrower, purpose)),
+				ledgerPosting{models.LedgerEntryLoan, amount}, ledgerPosting{models.LedgerEntryCash, -amount})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read loans: %w", err)
+	}
+
+	rows, err = s.db.Query(`
+		SELECT p.id, p.amount, p.interest_amount, p.reference, p.paid_at, COALESCE(u.first_name || ' ' || u.last_name, '')
+		FROM loan_payments p
+		JOIN loans l ON l.id = p.loan_id
+		LEFT JOIN users u ON u.id = l.borrower_id
+		WHERE l.chama_id = ?
+	`, chamaID)
+	if err != nil {
+		return fmt.Errorf("failed to get loan payments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, borrower string
+		var amount, interest float64
+		var reference sql.NullString
+		var paidAt time.Time
+		if err := rows.Scan(&id, &amount, &interest, &reference, &paidAt, &borrower); err != nil {
+			return fmt.Errorf("failed to scan loan payment: %w", err)
+		}
+		if !paidAt.Before(to) {
+			continue
+		}
+		ledger.add(paidAt, models.LedgerEntryLoanRepayment, "loan_payment", id, nullStringPtr(reference),
+			"Loan repayment by "+borrower,
+			ledgerPosting{models.LedgerEntryCash, amount},
+			ledgerPosting{models.LedgerEntryLoanRepayment, -(amount - interest)},
+			ledgerPosting{models.LedgerEntryLoanInterest, -interest})
+	}
+	return rows.Err()
+}
+
+// loadDividendEntries posts paid dividends. The part a member reinvested buys shares
+// instead of leaving as cash, so it is credited to share capital.
+func (s *AccountingService) loadDividendEntries(ledger *chamaLedger, chamaID string, to time.Time) error {
+	rows, err := s.db.Query(`
+		SELECT p.id, p.dividend_amount, p.reinvested_amount, p.transaction_reference,
+			p.payment_date, p.updated_at, COALESCE(u.first_name || ' ' || u.last_name, '')
+		FROM dividend_payments p
+		JOIN dividend_declarations d ON d.id = p.dividend_declaration_id
+		LEFT JOIN users u ON u.id = p.member_id
+		WHERE d.chama_id = ? AND p.payment_status = 'paid'
+	`, chamaID)
+	if err != nil {
+		return fmt.Errorf("failed to get dividend payments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, member string
+		var amount, reinvested float64
+		var reference sql.NullString
+		var paymentDate sql.NullTime
+		var paidAt time.Time
+		if err := rows.Scan(&id, &amount, &reinvested, &reference, &paymentDate, &paidAt, &member); err != nil {
+			return fmt.Errorf("failed to scan dividend payment: %w", err)
+		}
+		if paymentDate.Valid {
+			paidAt = paymentDate.Time
+		}
+		if !paidAt.Before(to) {
+			continue
+		}
+		ledger.add(paidAt, models.LedgerEntryDividend, "dividend_payment", id, nullStringPtr(reference),
+			"Dividend paid to "+member,
+			ledgerPosting{models.LedgerEntryDividend, amount},
+			ledgerPosting{models.LedgerEntryCash, -(amount - reinvested)},
+			ledgerPosting{models.LedgerEntryShareCapital, -reinvested})
+	}
+	return rows.Err()
+}
+
+// loadClosingEntries adds the closing entries of closed years, which move each
+// year's income, expenses and dividends to retained earnings
+func (s *AccountingService) loadClosingEntries(ledger *chamaLedger, chamaID string, to time.Time) error {
+	rows, err := s.db.Query(`
+		SELECT closing_entry FROM chama_financial_periods
+		WHERE chama_id = ? AND period_type = ? AND status = ? AND closing_entry IS NOT NULL
+	`, chamaID, models.FinancialPeriodAnnual, models.FinancialPeriodClosed)
+	if err != nil {
+		return fmt.Errorf("failed to get closing entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var closingEntry string
+		if err := rows.Scan(&closingEntry); err != nil {
+			return fmt.Errorf("failed to scan closing entry: %w", err)
+		}
+		var entry models.JournalEntry
+		if err := json.Unmarshal([]byte(closingEntry), &entry); err != nil {
+			return fmt.Errorf("failed to parse closing entry: %w", err)
+		}
+		if entry.Date.Before(to) {
+			ledger.entries = append(ledger.entries, entry)
+		}
+	}
+	return rows.Err()
+}
+
+// ensureChartOfAccounts creates any default accounts and mappings the chama is
+// missing. Existing accounts and mappings are left as officials set them.
+func (s *AccountingService) ensureChartOfAccounts(chamaID string) error {
+	var mapped int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM chama_ledger_mappings WHERE chama_id = ?", chamaID).Scan(&mapped); err != nil {
+		return fmt.Errorf("failed to check chart of accounts: %w", err)
+	}
+	if mapped >= len(ledgerMappingTypes) {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, account := range defaultLedgerAccounts {
+		_, err := tx.Exec(`
+			INSERT INTO chama_ledger_accounts (id, chama_id, code, name, account_type, is_active, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, TRUE, ?, ?)
+			ON CONFLICT(chama_id, code) DO NOTHING
+		`, uuid.New().String(), chamaID, account.code, account.name, account.kind, now, now)
+		if err != nil {
+			return fmt.Errorf("failed to create ledger account: %w", err)
+		}
+
+		for _, entryType := range account.entryTypes {
+			_, err := tx.Exec(`
+				INSERT INTO chama_ledger_mappings (id, chama_id, entry_type, account_id, updated_at)
+				SELECT ?, ?, ?, id, ? FROM chama_ledger_accounts WHERE chama_id = ? AND code = ?
+				ON CONFLICT(chama_id, entry_type) DO NOTHING
+			`, uuid.New().String(), chamaID, entryType, now, chamaID, account.code)
+			if err != nil {
+				return fmt.Errorf("failed to create ledger mapping: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *AccountingService) getChartOfAccounts(chamaID string) (*models.ChartOfAccounts, error) {
+	rows, err := s.db.Query(`
+		SELECT id, chama_id, code, name, account_type, description, is_active, created_at, updated_at
+		FROM chama_ledger_accounts WHERE chama_id = ?
+		ORDER BY code
+	`, chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger accounts: %w", err)
+	}
+	defer rows.Close()
+
+	chart := &models.ChartOfAccounts{ChamaID: chamaID, Accounts: []models.LedgerAccount{}, Mappings: []models.LedgerAccountMapping{}}
+	byID := map[string]models.LedgerAccount{}
+	for rows.Next() {
+		account, err := scanLedgerAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		chart.Accounts = append(chart.Accounts, *account)
+		byID[account.ID] = *account
+	}
+	rows.Close()
+
+	mappingRows, err := s.db.Query("SELECT entry_type, account_id FROM chama_ledger_mappings WHERE chama_id = ? ORDER BY entry_type", chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger mappings: %w", err)
+	}
+	defer mappingRows.Close()
+
+	for mappingRows.Next() {
+		var mapping models.LedgerAccountMapping
+		if err := mappingRows.Scan(&mapping.EntryType, &mapping.AccountID); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger mapping: %w", err)
+		}
+		mapping.AccountCode = byID[mapping.AccountID].Code
+		mapping.AccountName = byID[mapping.AccountID].Name
+		chart.Mappings = append(chart.Mappings, mapping)
+	}
+	return chart, nil
+}
+
+func (s *AccountingService) getLedgerAccount(chamaID, accountID string) (*models.LedgerAccount, error) {
+	account, err := scanLedgerAccount(s.db.QueryRow(`
+		SELECT id, chama_id, code, name, account_type, description, is_active, created_at, updated_at
+		FROM chama_ledger_accounts WHERE id = ? AND chama_id = ?
+	`, accountID, chamaID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("ledger account not found")
+	}
+	return account, err
+}
+
+func scanLedgerAccount(row interface{ Scan(...interface{}) error }) (*models.LedgerAccount, error) {
+	var account models.LedgerAccount
+	var description sql.NullString
+	err := row.Scan(&account.ID, &account.ChamaID, &account.Code, &account.Name, &account.Type,
+		&description, &account.IsActive, &account.CreatedAt, &account.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan ledger account: %w", err)
+	}
+	account.Description = nullStringPtr(description)
+	return &account, nil
+}
+
+func (s *AccountingService) isMember(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow("SELECT 1 FROM chama_members WHERE user_id = ? AND chama_id = ? AND is_active = TRUE", userID, chamaID).Scan(&exists)
+	return err == nil
+}
+
+func (s *AccountingService) isOfficial(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM chama_members
+		WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+		AND role IN ('chairperson', 'secretary', 'treasurer')
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
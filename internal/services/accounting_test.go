@@ -0,0 +1,420 @@
+package services_test
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/internal/utils"
+	"vaultke-backend/test/helpers"
+)
+
+type AccountingTestSuite struct {
+	suite.Suite
+	testDB      *helpers.TestDatabase
+	db          *sql.DB
+	service     *services.AccountingService
+	treasurerID string
+	memberID    string
+	chamaID     string
+	walletID    string
+}
+
+func (suite *AccountingTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewAccountingService(suite.db)
+
+	suite.treasurerID = suite.testDB.AddTestUser(suite.T(), "Treasurer")
+	suite.memberID = suite.testDB.AddTestUser(suite.T(), "Member")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.treasurerID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.treasurerID, "treasurer")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.memberID, "member")
+
+	suite.walletID = uuid.New().String()
+	_, err := suite.db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'chama', ?, 0)", suite.walletID, suite.chamaID)
+	suite.Require().NoError(err)
+}
+
+func ledgerDate(month time.Month, day int) time.Time {
+	return time.Date(2024, month, day, 10, 0, 0, 0, utils.EATLocation)
+}
+
+// record inserts a completed chama transaction; outgoing ones leave the chama wallet
+func (suite *AccountingTestSuite) record(txType string, amount float64, metadata string, outgoing bool, at time.Time) {
+	var fromWallet, meta interface{}
+	if outgoing {
+		fromWallet = suite.walletID
+	}
+	if metadata != "" {
+		meta = metadata
+	}
+	_, err := suite.db.Exec(`
+		INSERT INTO transactions (
+			id, from_wallet_id, type, status, amount, currency, description, payment_method,
+			initiated_by, recipient_id, metadata, created_at, updated_at
+		) VALUES (?, ?, ?, 'completed', ?, 'KES', ?, 'mpesa', ?, ?, ?, ?, ?)
+	`, uuid.New().String(), fromWallet, txType, amount, txType, suite.memberID, suite.chamaID, meta, at, at)
+	suite.Require().NoError(err)
+}
+
+// seedActivity records a month of chama activity:
+//
+//	contributions 15,000, a fine of 200 and share purchases of 3,000
+//	a loan of 8,000 repaid 1,100 (100 interest) and an expense of 500
+//	and, in April, a dividend of 1,000 of which 400 was reinvested
+func (suite *AccountingTestSuite) seedActivity() {
+	suite.record("contribution", 10000, "", false, ledgerDate(time.March, 1))
+	suite.record("contribution", 5000, `{"contributionType":"regular"}`, false, ledgerDate(time.March, 1))
+	suite.record("contribution", 200, `{"contributionType":"penalty"}`, false, ledgerDate(time.March, 2))
+	suite.record("share_purchase", 3000, "", false, ledgerDate(time.March, 3))
+	suite.record("expense", 500, "", true, ledgerDate(time.March, 10))
+	suite.record("withdrawal", 999, "", false, ledgerDate(time.March, 11))
+
+	loanID := uuid.New().String()
+	_, err := suite.db.Exec(`
+		INSERT INTO loans (id, borrower_id, chama_id, type, amount, duration, purpose, status, disbursed_at, required_guarantors)
+		VALUES (?, ?, ?, 'normal', 8000, 6, 'School fees', 'active', ?, 0)
+	`, loanID, suite.memberID, suite.chamaID, ledgerDate(time.March, 5))
+	suite.Require().NoError(err)
+	_, err = suite.db.Exec(`
+		INSERT INTO loans (id, borrower_id, chama_id, type, amount, duration, purpose, status, required_guarantors)
+		VALUES (?, ?, ?, 'normal', 4000, 6, 'Not yet disbursed', 'approved', 0)
+	`, uuid.New().String(), suite.memberID, suite.chamaID)
+	suite.Require().NoError(err)
+	_, err = suite.db.Exec(`
+		INSERT INTO loan_payments (id, loan_id, amount, principal_amount, interest_amount, payment_method, reference, paid_at)
+		VALUES (?, ?, 1100, 1000, 100, 'mpesa', 'LP123', ?)
+	`, uuid.New().String(), loanID, ledgerDate(time.March, 20))
+	suite.Require().NoError(err)
+
+	declarationID := uuid.New().String()
+	_, err = suite.db.Exec(`
+		INSERT INTO dividend_declarations (id, chama_id, dividend_per_share, total_amount, status)
+		VALUES (?, ?, 10, 1000, 'paid')
+	`, declarationID, suite.chamaID)
+	suite.Require().NoError(err)
+	_, err = suite.db.Exec(`
+		INSERT INTO dividend_payments (id, dividend_declaration_id, member_id, shares_eligible, dividend_amount,
+			payment_status, payment_date, reinvested_amount, cash_amount)
+		VALUES (?, ?, ?, 100, 1000, 'paid', ?, 400, 600)
+	`, uuid.New().String(), declarationID, suite.memberID, ledgerDate(time.April, 5))
+	suite.Require().NoError(err)
+}
+
+func linesByCode(lines []models.FinancialStatementLine) map[string]float64 {
+	amounts := map[string]float64{}
+	for _, line := range lines {
+		amounts[line.Code] = line.Amount
+	}
+	return amounts
+}
+
+func (suite *AccountingTestSuite) TestDefaultChartAndMappings() {
+	chart, err := suite.service.GetChartOfAccounts(suite.chamaID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Len(chart.Accounts, 14)
+	suite.Len(chart.Mappings, 15)
+
+	// Fetching again does not duplicate the defaults
+	chart, err = suite.service.GetChartOfAccounts(suite.chamaID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Len(chart.Accounts, 14)
+
+	_, err = suite.service.CreateLedgerAccount(suite.chamaID, suite.memberID, &models.CreateLedgerAccountRequest{
+		Code: "2000", Name: "Member Deposits", Type: "liability",
+	})
+	suite.Error(err)
+
+	deposits, err := suite.service.CreateLedgerAccount(suite.chamaID, suite.treasurerID, &models.CreateLedgerAccountRequest{
+		Code: "2000", Name: "Member Deposits", Type: "liability",
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.CreateLedgerAccount(suite.chamaID, suite.treasurerID, &models.CreateLedgerAccountRequest{
+		Code: "2000", Name: "Duplicate", Type: "liability",
+	})
+	suite.Error(err)
+
+	// Expenses cannot post to a liability account
+	_, err = suite.service.SetLedgerMapping(suite.chamaID, suite.treasurerID, &models.SetLedgerMappingRequest{
+		EntryType: "expense", AccountID: deposits.ID,
+	})
+	suite.Error(err)
+
+	chart, err = suite.service.SetLedgerMapping(suite.chamaID, suite.treasurerID, &models.SetLedgerMappingRequest{
+		EntryType: "contribution", AccountID: deposits.ID,
+	})
+	suite.Require().NoError(err)
+	for _, mapping := range chart.Mappings {
+		if mapping.EntryType == models.LedgerEntryContribution {
+			suite.Equal("2000", mapping.AccountCode)
+		}
+	}
+
+	// A mapped account cannot be deactivated
+	inactive := false
+	_, err = suite.service.UpdateLedgerAccount(suite.chamaID, deposits.ID, suite.treasurerID, &models.UpdateLedgerAccountRequest{IsActive: &inactive})
+	suite.Error(err)
+
+	// Contributions held as deposits are now reported as a liability
+	suite.seedActivity()
+	sheet, err := suite.service.GetBalanceSheet(suite.chamaID, suite.memberID, ledgerDate(time.May, 1))
+	suite.Require().NoError(err)
+	suite.Equal(15000.0, linesByCode(sheet.Liabilities)["2000"])
+	suite.True(sheet.Balanced)
+}
+
+func (suite *AccountingTestSuite) TestFinancialStatements() {
+	suite.seedActivity()
+	marchStart, aprilStart, mayStart := time.Date(2024, time.March, 1, 0, 0, 0, 0, utils.EATLocation), ledgerDate(time.April, 1), ledgerDate(time.May, 1)
+
+	trialBalance, err := suite.service.GetTrialBalance(suite.chamaID, suite.memberID, mayStart)
+	suite.Require().NoError(err)
+	suite.True(trialBalance.Balanced)
+	suite.Equal(18700.0, trialBalance.TotalDebits)
+	suite.Equal(18700.0, trialBalance.TotalCredits)
+
+	statement, err := suite.service.GetIncomeStatement(suite.chamaID, suite.memberID, marchStart, aprilStart)
+	suite.Require().NoError(err)
+	suite.Equal(300.0, statement.TotalIncome)
+	suite.Equal(500.0, statement.TotalExpenses)
+	suite.Equal(-200.0, statement.NetIncome)
+	suite.Equal(200.0, linesByCode(statement.Income)["4100"])
+	suite.Equal(100.0, linesByCode(statement.Income)["4000"])
+
+	sheet, err := suite.service.GetBalanceSheet(suite.chamaID, suite.memberID, mayStart)
+	suite.Require().NoError(err)
+	suite.True(sheet.Balanced)
+	assets := linesByCode(sheet.Assets)
+	suite.Equal(10200.0, assets["1000"])
+	suite.Equal(7000.0, assets["1200"])
+	equity := linesByCode(sheet.Equity)
+	suite.Equal(15000.0, equity["3100"])
+	suite.Equal(3400.0, equity["3000"])
+	suite.Equal(-1000.0, equity["3300"])
+	suite.Equal(-200.0, sheet.CurrentEarnings)
+	suite.Equal(17200.0, sheet.TotalEquity)
+
+	ledger, err := suite.service.GetGeneralLedger(suite.chamaID, suite.memberID, aprilStart, mayStart)
+	suite.Require().NoError(err)
+	for _, account := range ledger.Accounts {
+		if account.Code == "1000" {
+			suite.Equal(10800.0, account.OpeningBalance)
+			suite.Require().Len(account.Lines, 1)
+			suite.Equal(600.0, account.Lines[0].Credit)
+			suite.Equal(10200.0, account.ClosingBalance)
+		}
+	}
+
+	journal, err := suite.service.GetJournal(suite.chamaID, suite.memberID, marchStart, aprilStart)
+	suite.Require().NoError(err)
+	suite.Len(journal, 7)
+	for _, entry := range journal {
+		var debits, credits float64
+		for _, line := range entry.Lines {
+			debits += line.Debit
+			credits += line.Credit
+		}
+		suite.InDelta(debits, credits, 0.001, entry.ID)
+	}
+
+	outsiderID := suite.testDB.AddTestUser(suite.T(), "Outsider")
+	_, err = suite.service.GetTrialBalance(suite.chamaID, outsiderID, mayStart)
+	suite.Error(err)
+}
+
+func (suite *AccountingTestSuite) TestCashReconcilesWithChamaWallet() {
+	suite.record("contribution", 10000, "", false, time.Now().Add(-time.Hour))
+	suite.record("share_purchase", 3000, "", false, time.Now().Add(-time.Hour))
+	_, err := suite.db.Exec("UPDATE wallets SET balance = 13000 WHERE id = ?", suite.walletID)
+	suite.Require().NoError(err)
+
+	shareID := uuid.New().String()
+	_, err = suite.db.Exec(`
+		INSERT INTO shares (id, chama_id, member_id, name, share_type, shares_owned, share_value, total_value, purchase_date, status)
+		VALUES (?, ?, ?, 'Ordinary', 'ordinary', 30, 100, 3000, ?, 'active')
+	`, shareID, suite.chamaID, suite.memberID, time.Now().AddDate(-1, 0, 0))
+	suite.Require().NoError(err)
+	_, err = services.NewShareMarketService(suite.db).RedeemShares(suite.chamaID, suite.memberID, &models.RedeemSharesRequest{ShareID: shareID, SharesCount: 10})
+	suite.Require().NoError(err)
+
+	// Welfare money moves between members and the welfare fund, never the chama wallet
+	welfare := services.NewWelfareService(suite.db)
+	_, err = suite.db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'personal', ?, 5000)", uuid.New().String(), suite.treasurerID)
+	suite.Require().NoError(err)
+	_, err = welfare.ContributeToFund(suite.chamaID, suite.treasurerID, 4000)
+	suite.Require().NoError(err)
+	requestID := "welfare-" + uuid.New().String()
+	_, err = suite.db.Exec(`
+		INSERT INTO welfare_requests (id, chama_id, requester_id, beneficiary_id, title, description, amount, category, urgency, status)
+		VALUES (?, ?, ?, ?, 'Hospital bill', 'Admitted after an accident', 1500, 'medical', 'high', 'approved')
+	`, requestID, suite.chamaID, suite.memberID, suite.memberID)
+	suite.Require().NoError(err)
+	_, err = welfare.DisburseRequest(requestID, suite.treasurerID)
+	suite.Require().NoError(err)
+
+	report, err := suite.service.GetTrialBalance(suite.chamaID, suite.memberID, time.Now().Add(time.Hour))
+	suite.Require().NoError(err)
+	suite.True(report.Balanced)
+	net := map[string]float64{}
+	for _, line := range report.Lines {
+		net[line.Code] = line.Debit - line.Credit
+	}
+
+	var walletBalance, fundBalance float64
+	suite.Require().NoError(suite.db.QueryRow("SELECT balance FROM wallets WHERE id = ?", suite.walletID).Scan(&walletBalance))
+	suite.Require().NoError(suite.db.QueryRow("SELECT current_amount FROM welfare_funds WHERE chama_id = ?", suite.chamaID).Scan(&fundBalance))
+	suite.Equal(12000.0, walletBalance)
+	suite.Equal(walletBalance, net["1000"], "ledger cash matches the chama wallet")
+	suite.Equal(-2000.0, net["3000"], "redeemed shares leave share capital")
+	suite.Equal(-10000.0, net["3100"])
+	suite.Equal(fundBalance, net["1400"], "the welfare fund account matches the fund")
+	suite.Equal(-2500.0, net["3400"])
+}
+
+func (suite *AccountingTestSuite) TestExports() {
+	suite.seedActivity()
+	from, to := time.Date(2024, time.March, 1, 0, 0, 0, 0, utils.EATLocation), ledgerDate(time.May, 1)
+
+	data, filename, err := suite.service.ExportReport(suite.chamaID, suite.memberID, "trial_balance", models.AccountingExportCSV, from, to)
+	suite.Require().NoError(err)
+	suite.True(strings.HasPrefix(filename, "trial_balance_"))
+	rows, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	suite.Require().NoError(err)
+	suite.Equal([]string{"", "Total", "", "18700.00", "18700.00"}, rows[len(rows)-1])
+
+	data, _, err = suite.service.ExportReport(suite.chamaID, suite.memberID, "journal", models.AccountingExportQuickBooks, from, to)
+	suite.Require().NoError(err)
+	rows, err = csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	suite.Require().NoError(err)
+	suite.Equal("JournalNo", rows[0][0])
+	suite.Equal("01/03/2024", rows[1][1])
+
+	data, _, err = suite.service.ExportReport(suite.chamaID, suite.memberID, "journal", models.AccountingExportXero, from, to)
+	suite.Require().NoError(err)
+	rows, err = csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	suite.Require().NoError(err)
+	suite.Equal("*AccountCode", rows[0][3])
+	var total float64
+	for _, row := range rows[1:] {
+		amount, err := strconv.ParseFloat(row[5], 64)
+		suite.Require().NoError(err)
+		total += amount
+	}
+	suite.InDelta(0, total, 0.001)
+
+	_, _, err = suite.service.ExportReport(suite.chamaID, suite.memberID, "journal", "sage", from, to)
+	suite.Error(err)
+}
+
+func (suite *AccountingTestSuite) TestYearEndCloseLocksPeriod() {
+	suite.seedActivity()
+	periods := services.NewFinancialPeriodService(suite.db)
+	yearEnd := time.Date(2025, time.January, 1, 0, 0, 0, 0, utils.EATLocation)
+
+	_, err := periods.ClosePeriod(suite.chamaID, suite.memberID, &models.CloseFinancialPeriodRequest{PeriodType: "monthly", Year: 2024, Month: 3})
+	suite.Error(err)
+	_, err = periods.ClosePeriod(suite.chamaID, suite.treasurerID, &models.CloseFinancialPeriodRequest{PeriodType: "monthly", Year: 2099, Month: 1})
+	suite.Error(err)
+
+	march, err := periods.ClosePeriod(suite.chamaID, suite.treasurerID, &models.CloseFinancialPeriodRequest{PeriodType: "monthly", Year: 2024, Month: 3})
+	suite.Require().NoError(err)
+	suite.Equal(models.FinancialPeriodClosed, march.Status)
+	suite.Equal(-200.0, march.NetIncome)
+	suite.Nil(march.ClosingEntry)
+	suite.NotEmpty(march.ClosingBalances)
+	_, err = periods.ClosePeriod(suite.chamaID, suite.treasurerID, &models.CloseFinancialPeriodRequest{PeriodType: "monthly", Year: 2024, Month: 3})
+	suite.Error(err)
+
+	// Completed records dated inside the closed month are locked; pending ones are not
+	_, err = suite.db.Exec(`
+		INSERT INTO transactions (id, type, status, amount, currency, payment_method, initiated_by, recipient_id, created_at, updated_at)
+		VALUES (?, 'contribution', 'completed', 100, 'KES', 'mpesa', ?, ?, ?, ?)
+	`, uuid.New().String(), suite.memberID, suite.chamaID, ledgerDate(time.March, 15), ledgerDate(time.March, 15))
+	suite.ErrorContains(err, "financial period is closed")
+	_, err = suite.db.Exec("UPDATE transactions SET amount = 1 WHERE type = 'expense'")
+	suite.ErrorContains(err, "financial period is closed")
+	_, err = suite.db.Exec("DELETE FROM loan_payments")
+	suite.ErrorContains(err, "financial period is closed")
+	_, err = suite.db.Exec(`
+		INSERT INTO transactions (id, type, status, amount, currency, payment_method, initiated_by, recipient_id, created_at, updated_at)
+		VALUES (?, 'contribution', 'pending', 100, 'KES', 'mpesa', ?, ?, ?, ?)
+	`, uuid.New().String(), suite.memberID, suite.chamaID, ledgerDate(time.March, 15), ledgerDate(time.March, 15))
+	suite.NoError(err)
+
+	year, err := periods.ClosePeriod(suite.chamaID, suite.treasurerID, &models.CloseFinancialPeriodRequest{PeriodType: "annual", Year: 2024})
+	suite.Require().NoError(err)
+	suite.Equal(-200.0, year.NetIncome)
+	suite.Equal(0.0, year.OpeningRetainedEarnings)
+	suite.Equal(-1200.0, year.ClosingRetainedEarnings)
+	suite.Require().NotNil(year.ClosingEntry)
+
+	// Income, expenses and dividends are carried into retained earnings
+	sheet, err := suite.service.GetBalanceSheet(suite.chamaID, suite.memberID, yearEnd)
+	suite.Require().NoError(err)
+	suite.True(sheet.Balanced)
+	suite.Equal(0.0, sheet.CurrentEarnings)
+	suite.Equal(-1200.0, linesByCode(sheet.Equity)["3200"])
+	suite.NotContains(linesByCode(sheet.Equity), "3300")
+	suite.Equal(17200.0, sheet.TotalEquity)
+
+	trialBalance, err := suite.service.GetTrialBalance(suite.chamaID, suite.memberID, yearEnd)
+	suite.Require().NoError(err)
+	suite.True(trialBalance.Balanced)
+	suite.Equal(18400.0, trialBalance.TotalDebits)
+
+	statement, err := suite.service.GetIncomeStatement(suite.chamaID, suite.memberID, time.Date(2024, time.January, 1, 0, 0, 0, 0, utils.EATLocation), yearEnd)
+	suite.Require().NoError(err)
+	suite.Equal(-200.0, statement.NetIncome)
+
+	// The year must be reopened before the month it contains is editable again
+	_, err = periods.ReopenPeriod(suite.chamaID, march.ID, suite.memberID, "Correcting a misposted expense")
+	suite.Error(err)
+	_, err = periods.ReopenPeriod(suite.chamaID, march.ID, suite.treasurerID, "Correcting a misposted expense")
+	suite.Require().NoError(err)
+	_, err = suite.db.Exec("UPDATE transactions SET amount = 450 WHERE type = 'expense'")
+	suite.ErrorContains(err, "financial period is closed")
+
+	_, err = periods.ClosePeriod(suite.chamaID, suite.treasurerID, &models.CloseFinancialPeriodRequest{PeriodType: "monthly", Year: 2024, Month: 4})
+	suite.Error(err)
+
+	reopened, err := periods.ReopenPeriod(suite.chamaID, year.ID, suite.treasurerID, "Correcting a misposted expense")
+	suite.Require().NoError(err)
+	suite.Equal(models.FinancialPeriodReopened, reopened.Status)
+	suite.Nil(reopened.ClosingEntry)
+	_, err = suite.db.Exec("UPDATE transactions SET amount = 450 WHERE type = 'expense'")
+	suite.NoError(err)
+
+	sheet, err = suite.service.GetBalanceSheet(suite.chamaID, suite.memberID, yearEnd)
+	suite.Require().NoError(err)
+	suite.Equal(-150.0, sheet.CurrentEarnings)
+	suite.True(sheet.Balanced)
+
+	var reasons int
+	err = suite.db.QueryRow(`
+		SELECT COUNT(*) FROM audit_log WHERE entity_type = 'financial_period' AND details LIKE '%Correcting a misposted expense%'
+	`).Scan(&reasons)
+	suite.Require().NoError(err)
+	suite.Equal(2, reasons)
+
+	// Closing again recomputes the carried-forward earnings
+	year, err = periods.ClosePeriod(suite.chamaID, suite.treasurerID, &models.CloseFinancialPeriodRequest{PeriodType: "annual", Year: 2024})
+	suite.Require().NoError(err)
+	suite.Equal(-1150.0, year.ClosingRetainedEarnings)
+
+	list, err := periods.GetPeriods(suite.chamaID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Len(list, 2)
+}
+
+func TestAccountingTestSuite(t *testing.T) {
+	suite.Run(t, new(AccountingTestSuite))
+}
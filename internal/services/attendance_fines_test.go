@@ -0,0 +1,250 @@
+package services_test
+
+import (
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/internal/utils"
+	"vaultke-backend/test/helpers"
+)
+
+type AttendanceFinesTestSuite struct {
+	suite.Suite
+	testDB    *helpers.TestDatabase
+	db        *sql.DB
+	service   *services.AttendanceService
+	chamaID   string
+	chairID   string
+	lateID    string
+	excusedID string
+	absentID  string
+	meetingID string
+}
+
+func (suite *AttendanceFinesTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewAttendanceService(suite.db)
+
+	suite.chairID = suite.testDB.AddTestUser(suite.T(), "Chair")
+	suite.lateID = suite.testDB.AddTestUser(suite.T(), "Late")
+	suite.excusedID = suite.testDB.AddTestUser(suite.T(), "Excused")
+	suite.absentID = suite.testDB.AddTestUser(suite.T(), "Absent")
+
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.lateID, "member")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.excusedID, "member")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.absentID, "member")
+
+	// Members joined well before the meeting
+	_, err := suite.db.Exec("UPDATE chama_members SET joined_at = ? WHERE chama_id = ?", time.Now().AddDate(-1, 0, 0), suite.chamaID)
+	suite.Require().NoError(err)
+
+	start := time.Now().Add(-2 * time.Hour)
+	suite.meetingID = uuid.New().String()
+	_, err = suite.db.Exec(`
+		INSERT INTO meetings (id, chama_id, title, scheduled_at, started_at, status, created_by)
+		VALUES (?, ?, 'Monthly meeting', ?, ?, 'active', ?)
+	`, suite.meetingID, suite.chamaID, start, start, suite.chairID)
+	suite.Require().NoError(err)
+
+	suite.markAttendance(suite.chairID, true, start.Add(2*time.Minute))
+	suite.markAttendance(suite.lateID, true, start.Add(40*time.Minute))
+	suite.markAttendance(suite.absentID, false, start)
+
+	enabled := true
+	absence, excused, lateness, grace := 200.0, 50.0, 100.0, 10
+	_, err = suite.service.UpdateFineRules(suite.chamaID, suite.chairID, &models.UpdateAttendanceFineRulesRequest{
+		IsEnabled:            &enabled,
+		AbsenceFine:          &absence,
+		ExcusedAbsenceFine:   &excused,
+		LatenessFine:         &lateness,
+		LatenessGraceMinutes: &grace,
+	})
+	suite.Require().NoError(err)
+}
+
+func (suite *AttendanceFinesTestSuite) markAttendance(userID string, present bool, joinedAt time.Time) {
+	_, err := suite.db.Exec(`
+		INSERT INTO meeting_attendance (id, meeting_id, user_id, attendance_type, is_present, joined_at)
+		VALUES (?, ?, ?, 'physical', ?, ?)
+	`, uuid.New().String(), suite.meetingID, userID, present, joinedAt)
+	suite.Require().NoError(err)
+}
+
+func (suite *AttendanceFinesTestSuite) endMeeting() {
+	_, err := suite.db.Exec("UPDATE meetings SET status = 'ended', ended_at = ? WHERE id = ?", time.Now(), suite.meetingID)
+	suite.Require().NoError(err)
+}
+
+func (suite *AttendanceFinesTestSuite) TestOnlyOfficialsConfigureRules() {
+	enabled := false
+	_, err := suite.service.UpdateFineRules(suite.chamaID, suite.absentID, &models.UpdateAttendanceFineRulesRequest{IsEnabled: &enabled})
+	suite.Error(err)
+}
+
+func (suite *AttendanceFinesTestSuite) TestFinesPostedAfterMeetingEnds() {
+	// An apology submitted well before the deadline would be accepted; simulate it here
+	_, err := suite.db.Exec(`
+		INSERT INTO meeting_apologies (id, meeting_id, chama_id, user_id, reason, status)
+		VALUES (?, ?, ?, ?, 'Travelling', 'accepted')
+	`, uuid.New().String(), suite.meetingID, suite.chamaID, suite.excusedID)
+	suite.Require().NoError(err)
+
+	_, err = suite.service.ApplyMeetingFines(suite.meetingID)
+	suite.Error(err, "fines wait until the meeting has ended")
+
+	suite.endMeeting()
+	fines, err := suite.service.ApplyMeetingFines(suite.meetingID)
+	suite.Require().NoError(err)
+	suite.Require().Len(fines, 3)
+
+	byUser := map[string]models.MemberFine{}
+	for _, fine := range fines {
+		byUser[fine.UserID] = fine
+	}
+	suite.Equal(models.FineTypeLateness, byUser[suite.lateID].FineType)
+	suite.Equal(100.0, byUser[suite.lateID].Amount)
+	suite.Equal(50.0, byUser[suite.excusedID].Amount)
+	suite.Equal(200.0, byUser[suite.absentID].Amount)
+	suite.NotContains(byUser, suite.chairID)
+
+	again, err := suite.service.ApplyMeetingFines(suite.meetingID)
+	suite.Require().NoError(err)
+	suite.Empty(again, "fines are only posted once per meeting")
+}
+
+func (suite *AttendanceFinesTestSuite) TestPayAndWaiveFines() {
+	suite.endMeeting()
+	_, err := suite.service.ApplyMeetingFines(suite.meetingID)
+	suite.Require().NoError(err)
+
+	fines, err := suite.service.GetChamaFines(suite.chamaID, suite.absentID, "", "outstanding", 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(fines, 1, "members only see their own fines")
+
+	_, err = suite.service.PayFine(suite.chamaID, fines[0].ID, suite.absentID)
+	suite.Error(err, "no wallet to pay from")
+
+	_, err = suite.db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'personal', ?, 1000)", uuid.New().String(), suite.absentID)
+	suite.Require().NoError(err)
+
+	paid, err := suite.service.PayFine(suite.chamaID, fines[0].ID, suite.absentID)
+	suite.Require().NoError(err)
+	suite.Equal(models.FineStatusPaid, paid.Status)
+	suite.NotNil(paid.TransactionID)
+
+	var balance, chamaBalance float64
+	suite.Require().NoError(suite.db.QueryRow("SELECT balance FROM wallets WHERE owner_id = ? AND type = 'personal'", suite.absentID).Scan(&balance))
+	suite.Require().NoError(suite.db.QueryRow("SELECT balance FROM wallets WHERE owner_id = ? AND type = 'chama'", suite.chamaID).Scan(&chamaBalance))
+	suite.Equal(800.0, balance)
+	suite.Equal(200.0, chamaBalance)
+
+	var toWalletID string
+	var totalFunds float64
+	suite.Require().NoError(suite.db.QueryRow("SELECT to_wallet_id FROM transactions WHERE id = ?", *paid.TransactionID).Scan(&toWalletID))
+	suite.Require().NoError(suite.db.QueryRow("SELECT total_funds FROM chamas WHERE id = ?", suite.chamaID).Scan(&totalFunds))
+	suite.Equal("wallet-"+suite.chamaID, toWalletID, "fines go into the chama's own wallet")
+	suite.Equal(200.0, totalFunds)
+
+	// The late member and the member without an apology still owe
+	all, err := suite.service.GetChamaFines(suite.chamaID, suite.chairID, "", "outstanding", 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(all, 2)
+
+	_, err = suite.service.WaiveFine(suite.chamaID, all[0].ID, suite.lateID, "Not allowed")
+	suite.Error(err)
+	waived, err := suite.service.WaiveFine(suite.chamaID, all[0].ID, suite.chairID, "Traffic accident")
+	suite.Require().NoError(err)
+	suite.Equal(models.FineStatusWaived, waived.Status)
+}
+
+func (suite *AttendanceFinesTestSuite) TestFineIsSettledOnlyOnce() {
+	suite.endMeeting()
+	_, err := suite.service.ApplyMeetingFines(suite.meetingID)
+	suite.Require().NoError(err)
+	fines, err := suite.service.GetChamaFines(suite.chamaID, suite.absentID, "", "outstanding", 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(fines, 1)
+
+	_, err = suite.db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'personal', ?, 1000)", uuid.New().String(), suite.absentID)
+	suite.Require().NoError(err)
+
+	// Concurrent requests for the same fine settle it once
+	const attempts = 5
+	var wg sync.WaitGroup
+	var paid int32
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := suite.service.PayFine(suite.chamaID, fines[0].ID, suite.absentID); err == nil {
+				atomic.AddInt32(&paid, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	suite.Equal(int32(1), paid)
+
+	var balance float64
+	suite.Require().NoError(suite.db.QueryRow("SELECT balance FROM wallets WHERE owner_id = ? AND type = 'personal'", suite.absentID).Scan(&balance))
+	suite.Equal(800.0, balance, "the fine is only taken once")
+
+	_, err = suite.service.WaiveFine(suite.chamaID, fines[0].ID, suite.chairID, "Too late")
+	suite.Error(err, "a paid fine can't be waived")
+}
+
+func (suite *AttendanceFinesTestSuite) TestAttendanceReport() {
+	suite.endMeeting()
+
+	report, err := suite.service.GetAttendanceReport(suite.chamaID, suite.chairID, time.Now().AddDate(0, -1, 0), time.Now().Add(time.Hour))
+	suite.Require().NoError(err)
+	suite.Equal(1, report.MeetingsHeld)
+
+	byUser := map[string]models.MemberAttendanceStats{}
+	for _, member := range report.Members {
+		byUser[member.UserID] = member
+	}
+	suite.Equal(100.0, byUser[suite.chairID].AttendanceRate)
+	suite.Equal(1, byUser[suite.lateID].Late)
+	suite.Equal(1, byUser[suite.absentID].Absent)
+	suite.Equal(0.0, byUser[suite.absentID].AttendanceRate)
+	suite.Equal(50.0, report.AverageAttendance)
+
+	// A window given in EAT still finds a meeting stored in UTC
+	var scheduledAt time.Time
+	suite.Require().NoError(suite.db.QueryRow("SELECT scheduled_at FROM meetings WHERE id = ?", suite.meetingID).Scan(&scheduledAt))
+	_, err = suite.db.Exec("UPDATE meetings SET scheduled_at = ? WHERE id = ?", scheduledAt.UTC(), suite.meetingID)
+	suite.Require().NoError(err)
+	local := scheduledAt.In(utils.EATLocation)
+	report, err = suite.service.GetAttendanceReport(suite.chamaID, suite.chairID, local.Add(-time.Minute), local.Add(time.Minute))
+	suite.Require().NoError(err)
+	suite.Equal(1, report.MeetingsHeld)
+}
+
+func (suite *AttendanceFinesTestSuite) TestLateApologyIsFlagged() {
+	upcoming := uuid.New().String()
+	_, err := suite.db.Exec(`
+		INSERT INTO meetings (id, chama_id, title, scheduled_at, status, created_by)
+		VALUES (?, ?, 'Next meeting', ?, 'scheduled', ?)
+	`, upcoming, suite.chamaID, time.Now().Add(30*time.Minute), suite.chairID)
+	suite.Require().NoError(err)
+
+	apology, err := suite.service.SubmitApology(upcoming, suite.absentID, "Stuck at work")
+	suite.Require().NoError(err)
+	suite.Equal(models.ApologyStatusLate, apology.Status)
+
+	suite.Require().NoError(suite.service.ReviewApology(upcoming, apology.ID, suite.chairID, models.ApologyStatusAccepted))
+}
+
+func TestAttendanceFines(t *testing.T) {
+	suite.Run(t, new(AttendanceFinesTestSuite))
+}
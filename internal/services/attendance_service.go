@@ -0,0 +1,754 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"vaultke-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// AttendanceService handles attendance fines, apologies and attendance analytics
+type AttendanceService struct {
+	db *sql.DB
+}
+
+// NewAttendanceService creates a new attendance service
+func NewAttendanceService(db *sql.DB) *AttendanceService {
+	return &AttendanceService{db: db}
+}
+
+// GetFineRules returns a chama's attendance fine rules, or disabled defaults if none are configured
+func (s *AttendanceService) GetFineRules(chamaID, userID string) (*models.AttendanceFineRules, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	return s.getFineRules(chamaID)
+}
+
+// UpdateFineRules creates or updates a chama's attendance fine rules
+func (s *AttendanceService) UpdateFineRules(chamaID, userID string, req *models.UpdateAttendanceFineRulesRequest) (*models.AttendanceFineRules, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can configure attendance fines")
+	}
+
+	rules, err := s.getFineRules(chamaID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.IsEnabled != nil {
+		rules.IsEnabled = *req.IsEnabled
+	}
+	if req.AbsenceFine != nil {
+		rules.AbsenceFine = *req.AbsenceFine
+	}
+	if req.ExcusedAbsenceFine != nil {
+		rules.ExcusedAbsenceFine = *req.ExcusedAbsenceFine
+	}
+	if req.LatenessFine != nil {
+		rules.LatenessFine = *req.LatenessFine
+	}
+	if req.LatenessGraceMinutes != nil {
+		rules.LatenessGraceMinutes = *req.LatenessGraceMinutes
+	}
+	if req.ApologyDeadlineHours != nil {
+		rules.ApologyDeadlineHours = *req.ApologyDeadlineHours
+	}
+
+	now := time.Now()
+	_, err = s.db.Exec(`
+		INSERT INTO attendance_fine_rules (
+			chama_id, is_enabled, absence_fine, excused_absence_fine, lateness_fine,
+			lateness_grace_minutes, apology_deadline_hours, updated_by, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(chama_id) DO UPDATE SET
+			is_enabled = excluded.is_enabled,
+			absence_fine = excluded.absence_fine,
+			excused_absence_fine = excluded.excused_absence_fine,
+			lateness_fine = excluded.lateness_fine,
+			lateness_grace_minutes = excluded.lateness_grace_minutes,
+			apology_deadline_hours = excluded.apology_deadline_hours,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`, chamaID, rules.IsEnabled, rules.AbsenceFine, rules.ExcusedAbsenceFine, rules.LatenessFine,
+		rules.LatenessGraceMinutes, rules.ApologyDeadlineHours, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save attendance fine rules: %w", err)
+	}
+
+	return s.getFineRules(chamaID)
+}
+
+// SubmitApology records a member's apology for missing a meeting. Apologies received
+// before the chama's apology deadline are accepted automatically; later ones are marked late.
+func (s *AttendanceService) SubmitApology(meetingID, userID, reason string) (*models.MeetingApology, error) {
+	var chamaID, status string
+	var scheduledAt time.Time
+	err := s.db.QueryRow(`
+		SELECT chama_id, status, scheduled_at FROM meetings WHERE id = ?
+	`, meetingID).Scan(&chamaID, &status, &scheduledAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("meeting not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meeting: %w", err)
+	}
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	if status == "ended" || status == "cancelled" {
+		return nil, fmt.Errorf("cannot submit an apology for a meeting that has %s", status)
+	}
+
+	rules, err := s.getFineRules(chamaID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	apologyStatus := models.ApologyStatusAccepted
+	deadline := scheduledAt.Add(-time.Duration(rules.ApologyDeadlineHours) * time.Hour)
+	if now.After(deadline) {
+		apologyStatus = models.ApologyStatusLate
+	}
+
+	apology := &models.MeetingApology{
+		ID:          uuid.New().String(),
+		MeetingID:   meetingID,
+		ChamaID:     chamaID,
+		UserID:      userID,
+		Reason:      reason,
+		Status:      apologyStatus,
+		SubmittedAt: now,
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO meeting_apologies (id, meeting_id, chama_id, user_id, reason, status, submitted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, apology.ID, apology.MeetingID, apology.ChamaID, apology.UserID, apology.Reason, apology.Status, apology.SubmittedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit apology (an apology may already exist for this meeting): %w", err)
+	}
+
+	return apology, nil
+}
+
+// GetMeetingApologies returns the apologies submitted for a meeting
+func (s *AttendanceService) GetMeetingApologies(meetingID, userID string) ([]models.MeetingApology, error) {
+	chamaID, err := s.getMeetingChamaID(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	rows, err := s.db.Query(`
+		SELECT a.id, a.meeting_id, a.chama_id, a.user_id, u.first_name || ' ' || u.last_name,
+			a.reason, a.status, a.submitted_at, a.reviewed_by, a.reviewed_at
+		FROM meeting_apologies a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.meeting_id = ?
+		ORDER BY a.submitted_at ASC
+	`, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get apologies: %w", err)
+	}
+	defer rows.Close()
+
+	apologies := []models.MeetingApology{}
+	for rows.Next() {
+		var a models.MeetingApology
+		if err := rows.Scan(&a.ID, &a.MeetingID, &a.ChamaID, &a.UserID, &a.MemberName,
+			&a.Reason, &a.Status, &a.SubmittedAt, &a.ReviewedBy, &a.ReviewedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan apology: %w", err)
+		}
+		apologies = append(apologies, a)
+	}
+
+	return apologies, nil
+}
+
+// ReviewApology lets an official accept or reject an apology
+func (s *AttendanceService) ReviewApology(meetingID, apologyID, userID string, status models.ApologyStatus) error {
+	chamaID, err := s.getMeetingChamaID(meetingID)
+	if err != nil {
+		return err
+	}
+	if !s.isOfficial(userID, chamaID) {
+		return fmt.Errorf("only chama officials can review apologies")
+	}
+	if status != models.ApologyStatusAccepted && status != models.ApologyStatusRejected {
+		return fmt.Errorf("invalid apology status")
+	}
+
+	result, err := s.db.Exec(`
+		UPDATE meeting_apologies SET status = ?, reviewed_by = ?, reviewed_at = ?
+		WHERE id = ? AND meeting_id = ?
+	`, status, userID, time.Now(), apologyID, meetingID)
+	if err != nil {
+		return fmt.Errorf("failed to review apology: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("apology not found")
+	}
+
+	return nil
+}
+
+// ApplyMeetingFines posts attendance fines for an ended meeting according to the chama's rules.
+// It is safe to call more than once; a member is fined at most once per fine type per meeting.
+func (s *AttendanceService) ApplyMeetingFines(meetingID string) ([]models.MemberFine, error) {
+	var chamaID, title, status string
+	var scheduledAt time.Time
+	var startedAt sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT chama_id, title, status, scheduled_at, started_at FROM meetings WHERE id = ?
+	`, meetingID).Scan(&chamaID, &title, &status, &scheduledAt, &startedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("meeting not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meeting: %w", err)
+	}
+	if status != "ended" {
+		return nil, fmt.Errorf("fines can only be applied once the meeting has ended")
+	}
+
+	rules, err := s.getFineRules(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	if !rules.IsEnabled {
+		return []models.MemberFine{}, nil
+	}
+
+	startTime := scheduledAt
+	if startedAt.Valid {
+		startTime = startedAt.Time
+	}
+	lateAfter := startTime.Add(time.Duration(rules.LatenessGraceMinutes) * time.Minute)
+
+	rows, err := s.db.Query(`
+		SELECT cm.user_id, cm.joined_at, COALESCE(ma.is_present, FALSE), ma.joined_at, ap.status
+		FROM chama_members cm
+		LEFT JOIN meeting_attendance ma ON ma.meeting_id = ? AND ma.user_id = cm.user_id
+		LEFT JOIN meeting_apologies ap ON ap.meeting_id = ? AND ap.user_id = cm.user_id
+		WHERE cm.chama_id = ? AND cm.is_active = TRUE
+	`, meetingID, meetingID, chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member attendance: %w", err)
+	}
+
+	type attendanceRow struct {
+		userID        string
+		isPresent     bool
+		joinedAt      sql.NullTime
+		apologyStatus sql.NullString
+	}
+	var attendance []attendanceRow
+	for rows.Next() {
+		var r attendanceRow
+		var memberSince time.Time
+		if err := rows.Scan(&r.userID, &memberSince, &r.isPresent, &r.joinedAt, &r.apologyStatus); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan attendance: %w", err)
+		}
+		// Members who joined after the meeting was scheduled are not expected to attend
+		if memberSince.After(scheduledAt) {
+			continue
+		}
+		attendance = append(attendance, r)
+	}
+	rows.Close()
+
+	var pending []models.MemberFine
+	for _, r := range attendance {
+		switch {
+		case !r.isPresent:
+			amount := rules.AbsenceFine
+			reason := fmt.Sprintf("Absent from %s", title)
+			if r.apologyStatus.Valid && r.apologyStatus.String == string(models.ApologyStatusAccepted) {
+				amount = rules.ExcusedAbsenceFine
+				reason = fmt.Sprintf("Absent with apology from %s", title)
+			}
+			if amount > 0 {
+				pending = append(pending, s.newFine(chamaID, r.userID, meetingID, models.FineTypeAbsence, amount, reason))
+			}
+		case rules.LatenessFine > 0 && r.joinedAt.Valid && r.joinedAt.Time.After(lateAfter):
+			minutesLate := int(r.joinedAt.Time.Sub(startTime).Minutes())
+			reason := fmt.Sprintf("Late by %d minutes to %s", minutesLate, title)
+			pending = append(pending, s.newFine(chamaID, r.userID, meetingID, models.FineTypeLateness, rules.LatenessFine, reason))
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var posted []models.MemberFine
+	for _, fine := range pending {
+		result, err := tx.Exec(`
+			INSERT OR IGNORE INTO member_fines (
+				id, chama_id, user_id, meeting_id, fine_type, amount, reason, status, created_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, fine.ID, fine.ChamaID, fine.UserID, fine.MeetingID, fine.FineType, fine.Amount,
+			fine.Reason, fine.Status, fine.CreatedAt, fine.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to post fine: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			posted = append(posted, fine)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	notificationService := NewNotificationService(s.db, nil)
+	for _, fine := range posted {
+		message := fmt.Sprintf("A fine of KES %.2f has been posted to your chama account: %s", fine.Amount, fine.Reason)
+		if err := notificationService.CreateInAppNotification(fine.UserID, "chama", "attendance_fine", "Attendance Fine", message, map[string]interface{}{
+			"chamaId":   fine.ChamaID,
+			"meetingId": meetingID,
+			"fineId":    fine.ID,
+			"amount":    fine.Amount,
+		}); err != nil {
+			log.Printf("Failed to notify member %s of fine: %v", fine.UserID, err)
+		}
+	}
+
+	log.Printf("💸 Posted %d attendance fines for meeting %s", len(posted), meetingID)
+	return posted, nil
+}
+
+// ApplyMeetingFinesAsOfficial re-runs the fine rules for an ended meeting on an official's request,
+// e.g. after the rules were configured or attendance was corrected.
+func (s *AttendanceService) ApplyMeetingFinesAsOfficial(meetingID, userID string) ([]models.MemberFine, error) {
+	chamaID, err := s.getMeetingChamaID(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can apply attendance fines")
+	}
+	return s.ApplyMeetingFines(meetingID)
+}
+
+// GetChamaFines lists fines in a chama. Officials see every member's fines; other members see their own.
+func (s *AttendanceService) GetChamaFines(chamaID, userID, memberID, status string, limit, offset int) ([]models.MemberFine, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	if !s.isOfficial(userID, chamaID) {
+		memberID = userID
+	}
+
+	query := `
+		SELECT f.id, f.chama_id, f.user_id, u.first_name || ' ' || u.last_name, f.meeting_id,
+			f.fine_type, f.amount, f.reason, f.status, f.transaction_id, f.paid_at,
+			f.waived_by, f.waived_at, f.waiver_reason, f.created_at, f.updated_at
+		FROM member_fines f
+		JOIN users u ON u.id = f.user_id
+		WHERE f.chama_id = ?
+	`
+	args := []interface{}{chamaID}
+	if memberID != "" {
+		query += " AND f.user_id = ?"
+		args = append(args, memberID)
+	}
+	if status != "" {
+		if !models.IsValidFineStatus(status) {
+			return nil, fmt.Errorf("invalid fine status")
+		}
+		query += " AND f.status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY f.created_at DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fines: %w", err)
+	}
+	defer rows.Close()
+
+	fines := []models.MemberFine{}
+	for rows.Next() {
+		var f models.MemberFine
+		if err := rows.Scan(&f.ID, &f.ChamaID, &f.UserID, &f.MemberName, &f.MeetingID,
+			&f.FineType, &f.Amount, &f.Reason, &f.Status, &f.TransactionID, &f.PaidAt,
+			&f.WaivedBy, &f.WaivedAt, &f.WaiverReason, &f.CreatedAt, &f.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan fine: %w", err)
+		}
+		fines = append(fines, f)
+	}
+
+	return fines, nil
+}
+
+// PayFine settles an outstanding fine from the member's personal wallet into the chama wallet
+func (s *AttendanceService) PayFine(chamaID, fineID, userID string) (*models.MemberFine, error) {
+	fine, err := s.getFine(fineID)
+	if err != nil {
+		return nil, err
+	}
+	if fine.ChamaID != chamaID || fine.UserID != userID {
+		return nil, fmt.Errorf("fine not found")
+	}
+	if fine.Status != models.FineStatusOutstanding {
+		return nil, fmt.Errorf("fine is already %s", fine.Status)
+	}
+
+	evaluation, err := screenOutgoing(s.db, &models.FraudCheck{
+		UserID:          userID,
+		TransactionType: models.TransactionTypeContribution,
+		Amount:          fine.Amount,
+		RecipientID:     chamaID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Claim the fine before any money moves so a concurrent payment or waiver
+	// can't settle it a second time
+	now := time.Now()
+	transactionID := uuid.New().String()
+	result, err := tx.Exec(`
+		UPDATE member_fines SET status = ?, transaction_id = ?, paid_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, models.FineStatusPaid, transactionID, now, now, fineID, models.FineStatusOutstanding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update fine: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows != 1 {
+		return nil, fmt.Errorf("fine is no longer outstanding")
+	}
+
+	if err := reserveTransactionLimit(tx, userID, fineID, fine.Amount); err != nil {
+		return nil, err
+	}
+	fromWalletID, err := debitPersonalWallet(tx, userID, fine.Amount)
+	if err != nil {
+		return nil, err
+	}
+	toWalletID, err := creditChamaWallet(tx, chamaID, fine.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"chamaId":          chamaID,
+		"contributionType": "penalty",
+		"fineId":           fine.ID,
+		"fineType":         fine.FineType,
+		"meetingId":        fine.MeetingID,
+	})
+	_, err = tx.Exec(`
+		INSERT INTO transactions (
+			id, from_wallet_id, to_wallet_id, type, amount, currency, description, status, payment_method,
+			initiated_by, recipient_id, metadata, created_at, updated_at
+		) VALUES (?, ?, ?, 'contribution', ?, 'KES', ?, 'completed', 'wallet', ?, ?, ?, ?, ?)
+	`, transactionID, fromWalletID, toWalletID, fine.Amount, "Fine payment: "+fine.Reason, userID, chamaID, string(metadata), now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record fine payment: %w", err)
+	}
+
+	if err := NewAuditService(s.db).RecordTransaction(tx, chamaID, transactionID); err != nil {
+		return nil, err
+	}
+	if err := recordFraudEvaluation(tx, evaluation, transactionID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	InvalidateMemberAnalytics(chamaID)
+
+	return s.getFine(fineID)
+}
+
+// WaiveFine lets an official waive an outstanding fine
+func (s *AttendanceService) WaiveFine(chamaID, fineID, userID, reason string) (*models.MemberFine, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can waive fines")
+	}
+
+	fine, err := s.getFine(fineID)
+	if err != nil {
+		return nil, err
+	}
+	if fine.ChamaID != chamaID {
+		return nil, fmt.Errorf("fine not found")
+	}
+	if fine.Status != models.FineStatusOutstanding {
+		return nil, fmt.Errorf("fine is already %s", fine.Status)
+	}
+
+	now := time.Now()
+	result, err := s.db.Exec(`
+		UPDATE member_fines SET status = ?, waived_by = ?, waived_at = ?, waiver_reason = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, models.FineStatusWaived, userID, now, reason, now, fineID, models.FineStatusOutstanding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to waive fine: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows != 1 {
+		return nil, fmt.Errorf("fine is no longer outstanding")
+	}
+
+	return s.getFine(fineID)
+}
+
+// GetAttendanceReport returns per-member attendance rates for meetings ended within a period
+func (s *AttendanceService) GetAttendanceReport(chamaID, userID string, from, to time.Time) (*models.AttendanceReport, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	rules, err := s.getFineRules(chamaID)
+	if err != nil {
+		return nil, err
+	}
+
+	type meetingRow struct {
+		id          string
+		scheduledAt time.Time
+		startTime   time.Time
+	}
+	rows, err := s.db.Query(`
+		SELECT id, scheduled_at, started_at FROM meetings
+		WHERE chama_id = ? AND status = 'ended'
+		  AND julianday(scheduled_at) >= julianday(?) AND julianday(scheduled_at) < julianday(?)
+	`, chamaID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meetings: %w", err)
+	}
+	var meetings []meetingRow
+	for rows.Next() {
+		var m meetingRow
+		var startedAt sql.NullTime
+		if err := rows.Scan(&m.id, &m.scheduledAt, &startedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan meeting: %w", err)
+		}
+		m.startTime = m.scheduledAt
+		if startedAt.Valid {
+			m.startTime = startedAt.Time
+		}
+		meetings = append(meetings, m)
+	}
+	rows.Close()
+
+	memberRows, err := s.db.Query(`
+		SELECT cm.user_id, u.first_name || ' ' || u.last_name, cm.role, cm.joined_at
+		FROM chama_members cm
+		JOIN users u ON u.id = cm.user_id
+		WHERE cm.chama_id = ? AND cm.is_active = TRUE
+		ORDER BY u.first_name, u.last_name
+	`, chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get members: %w", err)
+	}
+	type memberRow struct {
+		stats    models.MemberAttendanceStats
+		joinedAt time.Time
+	}
+	var members []*memberRow
+	for memberRows.Next() {
+		m := &memberRow{}
+		if err := memberRows.Scan(&m.stats.UserID, &m.stats.MemberName, &m.stats.Role, &m.joinedAt); err != nil {
+			memberRows.Close()
+			return nil, fmt.Errorf("failed to scan member: %w", err)
+		}
+		members = append(members, m)
+	}
+	memberRows.Close()
+
+	report := &models.AttendanceReport{
+		ChamaID:      chamaID,
+		From:         from,
+		To:           to,
+		MeetingsHeld: len(meetings),
+		Members:      []models.MemberAttendanceStats{},
+	}
+
+	grace := time.Duration(rules.LatenessGraceMinutes) * time.Minute
+	for _, meeting := range meetings {
+		for _, member := range members {
+			if member.joinedAt.After(meeting.scheduledAt) {
+				continue
+			}
+			member.stats.MeetingsHeld++
+
+			var isPresent bool
+			var joinedAt sql.NullTime
+			err := s.db.QueryRow(`
+				SELECT is_present, joined_at FROM meeting_attendance WHERE meeting_id = ? AND user_id = ?
+			`, meeting.id, member.stats.UserID).Scan(&isPresent, &joinedAt)
+			if err != nil && err != sql.ErrNoRows {
+				return nil, fmt.Errorf("failed to get attendance: %w", err)
+			}
+
+			if isPresent {
+				member.stats.Attended++
+				if joinedAt.Valid && joinedAt.Time.After(meeting.startTime.Add(grace)) {
+					member.stats.Late++
+				}
+				continue
+			}
+
+			var apologyStatus string
+			err = s.db.QueryRow(`
+				SELECT status FROM meeting_apologies WHERE meeting_id = ? AND user_id = ?
+			`, meeting.id, member.stats.UserID).Scan(&apologyStatus)
+			if err != nil && err != sql.ErrNoRows {
+				return nil, fmt.Errorf("failed to get apology: %w", err)
+			}
+			if apologyStatus == string(models.ApologyStatusAccepted) {
+				member.stats.Excused++
+			} else {
+				member.stats.Absent++
+			}
+		}
+	}
+
+	var rateTotal float64
+	for _, member := range members {
+		err := s.db.QueryRow(`
+			SELECT COALESCE(SUM(amount), 0),
+				COALESCE(SUM(CASE WHEN status = 'outstanding' THEN amount ELSE 0 END), 0)
+			FROM member_fines
+			WHERE chama_id = ? AND user_id = ?
+			  AND julianday(created_at) >= julianday(?) AND julianday(created_at) < julianday(?)
+		`, chamaID, member.stats.UserID, from, to).Scan(&member.stats.FinesIssued, &member.stats.FinesOutstanding)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get member fines: %w", err)
+		}
+
+		if member.stats.MeetingsHeld > 0 {
+			member.stats.AttendanceRate = roundTo2(float64(member.stats.Attended) / float64(member.stats.MeetingsHeld) * 100)
+		}
+		rateTotal += member.stats.AttendanceRate
+		report.Members = append(report.Members, member.stats)
+	}
+	if len(members) > 0 {
+		report.AverageAttendance = roundTo2(rateTotal / float64(len(members)))
+	}
+
+	err = s.db.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0), COALESCE(SUM(CASE WHEN status = 'paid' THEN amount ELSE 0 END), 0)
+		FROM member_fines
+		WHERE chama_id = ? AND julianday(created_at) >= julianday(?) AND julianday(created_at) < julianday(?)
+	`, chamaID, from, to).Scan(&report.TotalFinesIssued, &report.TotalFinesPaid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fine totals: %w", err)
+	}
+
+	return report, nil
+}
+
+// Helper functions
+
+func (s *AttendanceService) newFine(chamaID, userID, meetingID string, fineType models.FineType, amount float64, reason string) models.MemberFine {
+	now := time.Now()
+	return models.MemberFine{
+		ID:        uuid.New().String(),
+		ChamaID:   chamaID,
+		UserID:    userID,
+		MeetingID: &meetingID,
+		FineType:  fineType,
+		Amount:    amount,
+		Reason:    reason,
+		Status:    models.FineStatusOutstanding,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func (s *AttendanceService) getFineRules(chamaID string) (*models.AttendanceFineRules, error) {
+	rules := &models.AttendanceFineRules{
+		ChamaID:              chamaID,
+		LatenessGraceMinutes: 15,
+		ApologyDeadlineHours: 2,
+	}
+	err := s.db.QueryRow(`
+		SELECT is_enabled, absence_fine, excused_absence_fine, lateness_fine,
+			lateness_grace_minutes, apology_deadline_hours, updated_by, updated_at
+		FROM attendance_fine_rules WHERE chama_id = ?
+	`, chamaID).Scan(&rules.IsEnabled, &rules.AbsenceFine, &rules.ExcusedAbsenceFine, &rules.LatenessFine,
+		&rules.LatenessGraceMinutes, &rules.ApologyDeadlineHours, &rules.UpdatedBy, &rules.UpdatedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get attendance fine rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (s *AttendanceService) getFine(fineID string) (*models.MemberFine, error) {
+	var f models.MemberFine
+	err := s.db.QueryRow(`
+		SELECT id, chama_id, user_id, meeting_id, fine_type, amount, reason, status, transaction_id,
+			paid_at, waived_by, waived_at, waiver_reason, created_at, updated_at
+		FROM member_fines WHERE id = ?
+	`, fineID).Scan(&f.ID, &f.ChamaID, &f.UserID, &f.MeetingID, &f.FineType, &f.Amount, &f.Reason,
+		&f.Status, &f.TransactionID, &f.PaidAt, &f.WaivedBy, &f.WaivedAt, &f.WaiverReason,
+		&f.CreatedAt, &f.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("fine not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fine: %w", err)
+	}
+	return &f, nil
+}
+
+func (s *AttendanceService) getMeetingChamaID(meetingID string) (string, error) {
+	var chamaID string
+	err := s.db.QueryRow("SELECT chama_id FROM meetings WHERE id = ?", meetingID).Scan(&chamaID)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("meeting not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get meeting: %w", err)
+	}
+	return chamaID, nil
+}
+
+func (s *AttendanceService) isMember(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM chama_members
+		WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
+
+func (s *AttendanceService) isOfficial(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM chama_members
+		WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+		AND role IN ('chairperson', 'secretary', 'treasurer')
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
+
+func roundTo2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,185 @@
+package services_test
+
+import (
+	"crypto/ed25519"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type AuditLogTestSuite struct {
+	suite.Suite
+	testDB   *helpers.TestDatabase
+	db       *sql.DB
+	service  *services.AuditService
+	chamaID  string
+	chairID  string
+	memberID string
+}
+
+func (suite *AuditLogTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewSigningAuditService(suite.db, testAuditSigningKey(suite.T()))
+
+	suite.chairID = suite.testDB.AddTestUser(suite.T(), "Chair")
+	suite.memberID = suite.testDB.AddTestUser(suite.T(), "Member")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.memberID, "member")
+}
+
+// redeem runs a settings change, a price change and a redemption through the share market
+func (suite *AuditLogTestSuite) redeem() {
+	shareID := uuid.New().String()
+	_, err := suite.db.Exec(`
+		INSERT INTO shares (id, chama_id, member_id, name, share_type, shares_owned, share_value, total_value, purchase_date, status)
+		VALUES (?, ?, ?, 'Ordinary', 'ordinary', 10, 100, 1000, ?, 'active')
+	`, shareID, suite.chamaID, suite.memberID, time.Now().AddDate(0, -1, 0))
+	suite.Require().NoError(err)
+	_, err = suite.db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'chama', ?, 5000)", uuid.New().String(), suite.chamaID)
+	suite.Require().NoError(err)
+
+	market := services.NewShareMarketService(suite.db)
+	lockIn := 7
+	_, err = market.UpdateSettings(suite.chamaID, suite.chairID, &models.UpdateShareMarketSettingsRequest{LockInDays: &lockIn})
+	suite.Require().NoError(err)
+	price := 100.0
+	_, err = market.SetSharePrice(suite.chamaID, suite.chairID, &models.SetSharePriceRequest{Source: models.SharePriceSourceManual, Price: &price})
+	suite.Require().NoError(err)
+	_, err = market.RedeemShares(suite.chamaID, suite.memberID, &models.RedeemSharesRequest{ShareID: shareID, SharesCount: 4})
+	suite.Require().NoError(err)
+}
+
+func (suite *AuditLogTestSuite) TestChainRecordsChangesAndVerifies() {
+	suite.redeem()
+
+	err := services.NewChamaService(suite.db).UpdateMemberRoleSimple(suite.chamaID, suite.memberID, "treasurer", suite.chairID)
+	suite.Require().NoError(err)
+
+	entries, err := suite.service.GetChamaEntries(suite.chamaID, suite.memberID, "", 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(entries, 4)
+	suite.Equal(models.AuditActionRoleChange, entries[0].Action)
+	suite.Equal(models.AuditActionMoneyMovement, entries[1].Action)
+	suite.Require().NotNil(entries[1].Amount)
+	suite.Equal(400.0, *entries[1].Amount)
+	suite.Equal(entries[2].EntryHash, entries[1].PreviousHash)
+	suite.Equal(models.AuditGenesisHash, entries[3].PreviousHash)
+
+	movements, err := suite.service.GetChamaEntries(suite.chamaID, suite.memberID, string(models.AuditActionMoneyMovement), 50, 0)
+	suite.Require().NoError(err)
+	suite.Len(movements, 1)
+
+	_, err = suite.service.GetChamaEntries(suite.chamaID, suite.testDB.AddTestUser(suite.T(), "Outsider"), "", 50, 0)
+	suite.Error(err, "only members can read the audit log")
+
+	verification, err := suite.service.VerifyChamaChain(suite.chamaID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.True(verification.Valid)
+	suite.Equal(4, verification.EntriesChecked)
+	suite.Equal(int64(4), verification.HeadSequence)
+}
+
+func (suite *AuditLogTestSuite) TestLogIsAppendOnly() {
+	suite.redeem()
+
+	_, err := suite.db.Exec("UPDATE audit_log SET amount = 1 WHERE chain_id = ?", suite.chamaID)
+	suite.Error(err)
+	_, err = suite.db.Exec("DELETE FROM audit_log WHERE chain_id = ?", suite.chamaID)
+	suite.Error(err)
+}
+
+func (suite *AuditLogTestSuite) TestTamperingReportsFirstBreak() {
+	suite.redeem()
+
+	_, err := suite.db.Exec("DROP TRIGGER audit_log_no_update")
+	suite.Require().NoError(err)
+	_, err = suite.db.Exec("UPDATE audit_log SET amount = 40 WHERE chain_id = ? AND sequence = 3", suite.chamaID)
+	suite.Require().NoError(err)
+
+	verification, err := suite.service.VerifyChain(suite.chamaID)
+	suite.Require().NoError(err)
+	suite.False(verification.Valid)
+	suite.Require().NotNil(verification.FirstBreak)
+	suite.Equal(int64(3), verification.FirstBreak.Sequence)
+	suite.Equal(2, verification.EntriesChecked)
+}
+
+func (suite *AuditLogTestSuite) TestCheckpointsAreSignedAndVerified() {
+	suite.redeem()
+
+	_, err := suite.service.CreateChamaCheckpoint(suite.chamaID, suite.memberID)
+	suite.Error(err, "only officials create checkpoints")
+
+	checkpoint, err := suite.service.CreateChamaCheckpoint(suite.chamaID, suite.chairID)
+	suite.Require().NoError(err)
+	suite.Equal(int64(3), checkpoint.Sequence)
+	suite.Equal(suite.service.PublicKey(), checkpoint.PublicKey)
+
+	result := suite.service.VerifyCheckpoint(checkpoint)
+	suite.True(result.SignatureValid)
+	suite.True(result.MatchesChain)
+
+	forged := *checkpoint
+	forged.Sequence = 2
+	result = suite.service.VerifyCheckpoint(&forged)
+	suite.False(result.SignatureValid)
+
+	_, err = suite.db.Exec("DROP TRIGGER audit_log_no_delete")
+	suite.Require().NoError(err)
+	_, err = suite.db.Exec("DELETE FROM audit_log WHERE chain_id = ? AND sequence = 3", suite.chamaID)
+	suite.Require().NoError(err)
+
+	result = suite.service.VerifyCheckpoint(checkpoint)
+	suite.True(result.SignatureValid)
+	suite.False(result.MatchesChain)
+
+	verification, err := suite.service.VerifyChain(suite.chamaID)
+	suite.Require().NoError(err)
+	suite.False(verification.Valid, "the checkpoint shows the tail was removed")
+	suite.Equal(int64(3), verification.FirstBreak.Sequence)
+}
+
+func (suite *AuditLogTestSuite) TestCheckpointSigningNeedsAConfiguredKey() {
+	_, err := services.NewAuditSigningKey("")
+	suite.Error(err, "there is no default key")
+	_, err = services.NewAuditSigningKey("too-short")
+	suite.Error(err)
+
+	suite.redeem()
+	unsigned := services.NewAuditService(suite.db)
+	_, err = unsigned.CreateChamaCheckpoint(suite.chamaID, suite.chairID)
+	suite.Error(err)
+	suite.Empty(unsigned.PublicKey())
+
+	checkpoint, err := suite.service.CreateChamaCheckpoint(suite.chamaID, suite.chairID)
+	suite.Require().NoError(err)
+	suite.False(unsigned.VerifyCheckpoint(checkpoint).SignatureValid)
+
+	otherKey, err := services.NewAuditSigningKey("another-deployment-audit-signing-secret")
+	suite.Require().NoError(err)
+	result := services.NewSigningAuditService(suite.db, otherKey).VerifyCheckpoint(checkpoint)
+	suite.False(result.SignatureValid, "a checkpoint only verifies against the key that signed it")
+}
+
+func TestAuditLog(t *testing.T) {
+	suite.Run(t, new(AuditLogTestSuite))
+}
+
+// testAuditSigningKey returns the checkpoint signing key used by the tests
+func testAuditSigningKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+
+	key, err := services.NewAuditSigningKey("test-audit-signing-secret-0123456789")
+	if err != nil {
+		t.Fatalf("Failed to derive audit signing key: %v", err)
+	}
+	return key
+}
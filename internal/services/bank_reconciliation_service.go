@@ -0,0 +1,1084 @@
+package services
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// bankMatchDateWindow is how many days apart a statement line and a recorded
+// transaction can be and still be matched automatically. Banks often post a
+// deposit a day or two after it was recorded.
+const bankMatchDateWindow = 3
+
+// bankCandidateDateWindow is how far around a statement line candidates are offered
+// for manual matching
+const bankCandidateDateWindow = 14
+
+// BankReconciliationService imports statements for chama bank accounts and matches
+// their lines against the transactions recorded in VaultKe
+type BankReconciliationService struct {
+	db *sql.DB
+}
+
+// NewBankReconciliationService creates a new bank reconciliation service
+func NewBankReconciliationService(db *sql.DB) *BankReconciliationService {
+	return &BankReconciliationService{db: db}
+}
+
+// CreateBankAccount registers a chama bank account. Reconciliation starts from the
+// opening balance on the opening date.
+func (s *BankReconciliationService) CreateBankAccount(chamaID, userID string, req *models.CreateBankAccountRequest) (*models.ChamaBankAccount, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can manage bank accounts")
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = "KES"
+	}
+	opening := req.OpeningBalanceDate.In(utils.EATLocation)
+	now := time.Now()
+	account := &models.ChamaBankAccount{
+		ID:                 uuid.New().String(),
+		ChamaID:            chamaID,
+		BankName:           strings.TrimSpace(req.BankName),
+		AccountName:        strings.TrimSpace(req.AccountName),
+		AccountNumber:      strings.TrimSpace(req.AccountNumber),
+		Currency:           currency,
+		OpeningBalance:     roundCurrency(req.OpeningBalance),
+		OpeningBalanceDate: time.Date(opening.Year(), opening.Month(), opening.Day(), 0, 0, 0, 0, utils.EATLocation),
+		IsActive:           true,
+		CreatedBy:          userID,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO chama_bank_accounts (
+			id, chama_id, bank_name, account_name, account_number, currency, opening_balance,
+			opening_balance_date, is_active, created_by, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, TRUE, ?, ?, ?)
+	`, account.ID, chamaID, account.BankName, account.AccountName, account.AccountNumber, account.Currency,
+		account.OpeningBalance, account.OpeningBalanceDate, userID, now, now)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return nil, fmt.Errorf("this bank account is already registered")
+		}
+		return nil, fmt.Errorf("failed to create bank account: %w", err)
+	}
+	return account, nil
+}
+
+// GetBankAccounts returns a chama's bank accounts
+func (s *BankReconciliationService) GetBankAccounts(chamaID, userID string) ([]models.ChamaBankAccount, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can view bank accounts")
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, chama_id, bank_name, account_name, account_number, currency, opening_balance,
+			opening_balance_date, is_active, created_by, created_at, updated_at
+		FROM chama_bank_accounts WHERE chama_id = ?
+		ORDER BY bank_name, account_number
+	`, chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := []models.ChamaBankAccount{}
+	for rows.Next() {
+		var account models.ChamaBankAccount
+		err := rows.Scan(&account.ID, &account.ChamaID, &account.BankName, &account.AccountName,
+			&account.AccountNumber, &account.Currency, &account.OpeningBalance, &account.OpeningBalanceDate,
+			&account.IsActive, &account.CreatedBy, &account.CreatedAt, &account.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bank account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+// ImportStatement parses a statement file and adds its lines to the account. Lines
+// already imported from an overlapping statement are skipped, and new lines are
+// matched automatically where a single recorded transaction clearly explains them.
+func (s *BankReconciliationService) ImportStatement(chamaID, accountID, userID, fileName string, data []byte, fileID *string) (*models.BankStatementImport, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can import bank statements")
+	}
+	account, err := s.getAccount(chamaID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.IsActive {
+		return nil, fmt.Errorf("this bank account is no longer active")
+	}
+
+	parsed, err := parseBankStatement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	var exists int
+	if err := s.db.QueryRow("SELECT 1 FROM bank_statements WHERE account_id = ? AND checksum = ?", accountID, checksum).Scan(&exists); err == nil {
+		return nil, fmt.Errorf("this statement has already been imported")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	statementID := uuid.New().String()
+	_, err = tx.Exec(`
+		INSERT INTO bank_statements (
+			id, account_id, chama_id, file_id, file_name, format, checksum, period_start, period_end,
+			opening_balance, closing_balance, imported_by, imported_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, statementID, accountID, chamaID, fileID, fileName, parsed.Format, checksum, parsed.PeriodStart,
+		parsed.PeriodEnd, parsed.OpeningBalance, parsed.ClosingBalance, userID, now)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return nil, fmt.Errorf("this statement has already been imported")
+		}
+		return nil, fmt.Errorf("failed to record bank statement: %w", err)
+	}
+
+	result := &models.BankStatementImport{}
+	for i, line := range parsed.Lines {
+		if line.Date.Before(account.OpeningBalanceDate) {
+			result.BeforeOpening++
+			continue
+		}
+		inserted, err := tx.Exec(`
+			INSERT INTO bank_statement_lines (
+				id, statement_id, account_id, chama_id, line_number, transaction_date, value_date,
+				description, reference, external_id, amount, balance, status, created_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 'unmatched', ?)
+			ON CONFLICT(account_id, external_id) DO NOTHING
+		`, uuid.New().String(), statementID, accountID, chamaID, i+1, line.Date, line.ValueDate,
+			line.Description, optionalString(line.Reference), line.ExternalID, line.Amount, line.Balance, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record statement line: %w", err)
+		}
+		if affected, _ := inserted.RowsAffected(); affected == 0 {
+			result.Duplicates++
+			continue
+		}
+		result.Imported++
+	}
+
+	_, err = tx.Exec("UPDATE bank_statements SET line_count = ?, duplicate_count = ? WHERE id = ?",
+		result.Imported, result.Duplicates, statementID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update bank statement: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if result.AutoMatched, err = s.autoMatch(account); err != nil {
+		return nil, err
+	}
+	statement, err := s.getStatement(accountID, statementID)
+	if err != nil {
+		return nil, err
+	}
+	result.Statement = *statement
+	return result, nil
+}
+
+// GetStatements returns the statements imported for an account, newest first
+func (s *BankReconciliationService) GetStatements(chamaID, accountID, userID string) ([]models.BankStatement, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can view bank statements")
+	}
+	if _, err := s.getAccount(chamaID, accountID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(bankStatementSelect+" WHERE account_id = ? ORDER BY imported_at DESC", accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank statements: %w", err)
+	}
+	defer rows.Close()
+
+	statements := []models.BankStatement{}
+	for rows.Next() {
+		statement, err := scanBankStatement(rows)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, *statement)
+	}
+	return statements, nil
+}
+
+// GetStatementLines returns an account's statement lines in date order, optionally
+// only those with a status
+func (s *BankReconciliationService) GetStatementLines(chamaID, accountID, userID, status string) ([]models.BankStatementLine, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can view bank statements")
+	}
+	if _, err := s.getAccount(chamaID, accountID); err != nil {
+		return nil, err
+	}
+	return s.getLines(accountID, status)
+}
+
+// AutoMatch matches an account's unmatched lines. It runs after every import and
+// can be run again once missing transactions have been recorded.
+func (s *BankReconciliationService) AutoMatch(chamaID, accountID, userID string) (int, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return 0, fmt.Errorf("only chama officials can reconcile bank statements")
+	}
+	account, err := s.getAccount(chamaID, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return s.autoMatch(account)
+}
+
+// autoMatch pairs each unmatched line with a recorded transaction of the same amount
+// and direction within a few days. Sharing a reference and falling on the same day
+// make a candidate stronger; a line is only matched when one candidate is clearly
+// best, and anything less certain is left for an official.
+func (s *BankReconciliationService) autoMatch(account *models.ChamaBankAccount) (int, error) {
+	lines, err := s.getLines(account.ID, string(models.BankLineUnmatched))
+	if err != nil {
+		return 0, err
+	}
+	if len(lines) == 0 {
+		return 0, nil
+	}
+	entries, err := s.bookEntries(account)
+	if err != nil {
+		return 0, err
+	}
+
+	used := map[string]bool{}
+	matches := map[string]string{}
+	for _, line := range lines {
+		bestScore, tied := -1, false
+		var best *bookEntry
+		for i := range entries {
+			entry := &entries[i]
+			if entry.matched || used[entry.ID] || !entry.belongsTo(account.ID) || math.Abs(entry.SignedAmount-line.Amount) >= 0.005 {
+				continue
+			}
+			days := daysApart(line.TransactionDate, entry.Date)
+			if days > bankMatchDateWindow {
+				continue
+			}
+			score := bankMatchDateWindow - days
+			if referenceMatches(line, entry.BookTransaction) {
+				score += bankMatchDateWindow + 1
+			}
+			switch {
+			case score > bestScore:
+				bestScore, best, tied = score, entry, false
+			case score == bestScore:
+				tied = true
+			}
+		}
+		if best != nil && !tied {
+			used[best.ID] = true
+			matches[line.ID] = best.ID
+		}
+	}
+	if len(matches) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	matched := 0
+	for lineID, transactionID := range matches {
+		ok, err := s.applyMatch(tx, account.ID, lineID, []string{transactionID}, nil, models.BankMatchAuto)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			matched++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return matched, nil
+}
+
+// GetMatchCandidates suggests recorded transactions for a statement line, best first.
+// Transactions smaller than the line are included because several of them may make
+// it up, such as contributions banked together.
+func (s *BankReconciliationService) GetMatchCandidates(chamaID, accountID, lineID, userID string) ([]models.BankMatchCandidate, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can reconcile bank statements")
+	}
+	account, err := s.getAccount(chamaID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	line, err := s.getLine(accountID, lineID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.bookEntries(account)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []models.BankMatchCandidate{}
+	for _, entry := range entries {
+		if entry.matched || !entry.belongsTo(account.ID) || (entry.SignedAmount > 0) != (line.Amount > 0) {
+			continue
+		}
+		if math.Abs(entry.SignedAmount) > math.Abs(line.Amount)+0.005 {
+			continue
+		}
+		days := daysApart(line.TransactionDate, entry.Date)
+		if days > bankCandidateDateWindow {
+			continue
+		}
+
+		candidate := models.BankMatchCandidate{
+			BookTransaction: entry.BookTransaction,
+			AmountMatches:   math.Abs(entry.SignedAmount-line.Amount) < 0.005,
+			DaysApart:       days,
+			ReferenceMatch:  referenceMatches(*line, entry.BookTransaction),
+		}
+		if candidate.AmountMatches {
+			candidate.Score += 5
+		}
+		if candidate.ReferenceMatch {
+			candidate.Score += 4
+		}
+		if days <= bankMatchDateWindow {
+			candidate.Score += bankMatchDateWindow - days
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].DaysApart < candidates[j].DaysApart
+	})
+	if len(candidates) > 20 {
+		candidates = candidates[:20]
+	}
+	return candidates, nil
+}
+
+// MatchLine matches a statement line to recorded transactions chosen by an official.
+// The transactions must move money the same way as the line and add up to it.
+func (s *BankReconciliationService) MatchLine(chamaID, accountID, lineID, userID string, transactionIDs []string) (*models.BankStatementLine, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can reconcile bank statements")
+	}
+	account, err := s.getAccount(chamaID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	line, err := s.getLine(accountID, lineID)
+	if err != nil {
+		return nil, err
+	}
+	if line.Status != models.BankLineUnmatched {
+		return nil, fmt.Errorf("statement line is already %s", line.Status)
+	}
+
+	entries, err := s.bookEntries(account)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]bookEntry, len(entries))
+	for _, entry := range entries {
+		byID[entry.ID] = entry
+	}
+
+	seen := map[string]bool{}
+	ids := []string{}
+	total := 0.0
+	for _, id := range transactionIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		entry, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("transaction %s is not a completed transaction of this chama", id)
+		}
+		if entry.matched {
+			return nil, fmt.Errorf("transaction %s is already matched to a statement line", id)
+		}
+		if !entry.belongsTo(accountID) {
+			return nil, fmt.Errorf("transaction %s went through another bank account", id)
+		}
+		if (entry.SignedAmount > 0) != (line.Amount > 0) {
+			return nil, fmt.Errorf("transaction %s moves money the other way to the statement line", id)
+		}
+		ids = append(ids, id)
+		total += entry.SignedAmount
+	}
+	if math.Abs(total-line.Amount) >= 0.005 {
+		return nil, fmt.Errorf("the transactions total %.2f but the statement line is %.2f", roundCurrency(total), line.Amount)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ok, err := s.applyMatch(tx, accountID, lineID, ids, &userID, models.BankMatchManual)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("statement line is no longer unmatched")
+	}
+
+	amount := math.Abs(line.Amount)
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionDeclaration,
+		EntityType: "bank_statement_line",
+		EntityID:   lineID,
+		Amount:     &amount,
+		Details: map[string]interface{}{
+			"decision":       "matched",
+			"accountId":      accountID,
+			"transactionIds": ids,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.getLine(accountID, lineID)
+}
+
+// UnmatchLine returns a matched or excluded line to unmatched, freeing its
+// transactions for other lines
+func (s *BankReconciliationService) UnmatchLine(chamaID, accountID, lineID, userID string) (*models.BankStatementLine, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can reconcile bank statements")
+	}
+	if _, err := s.getAccount(chamaID, accountID); err != nil {
+		return nil, err
+	}
+	line, err := s.getLine(accountID, lineID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE bank_statement_lines
+		SET status = 'unmatched', match_method = NULL, exclusion_reason = NULL, reconciled_by = NULL, reconciled_at = NULL
+		WHERE id = ? AND status IN ('matched', 'excluded')
+	`, lineID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmatch statement line: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("statement line is not matched")
+	}
+	if _, err := tx.Exec("DELETE FROM bank_statement_line_matches WHERE line_id = ?", lineID); err != nil {
+		return nil, fmt.Errorf("failed to remove statement line matches: %w", err)
+	}
+
+	amount := math.Abs(line.Amount)
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionDeclaration,
+		EntityType: "bank_statement_line",
+		EntityID:   lineID,
+		Amount:     &amount,
+		Details: map[string]interface{}{
+			"decision":       "unmatched",
+			"accountId":      accountID,
+			"previousStatus": line.Status,
+			"transactionIds": line.TransactionIDs,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.getLine(accountID, lineID)
+}
+
+// ExcludeLine marks a line as explained without a recorded transaction, such as a
+// transfer between the chama's own accounts
+func (s *BankReconciliationService) ExcludeLine(chamaID, accountID, lineID, userID, reason string) (*models.BankStatementLine, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can reconcile bank statements")
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("a reason is required to exclude a statement line")
+	}
+	if _, err := s.getAccount(chamaID, accountID); err != nil {
+		return nil, err
+	}
+	line, err := s.getLine(accountID, lineID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE bank_statement_lines
+		SET status = 'excluded', exclusion_reason = ?, reconciled_by = ?, reconciled_at = ?
+		WHERE id = ? AND status = 'unmatched'
+	`, reason, userID, time.Now(), lineID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exclude statement line: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("statement line is already %s", line.Status)
+	}
+
+	amount := math.Abs(line.Amount)
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionDeclaration,
+		EntityType: "bank_statement_line",
+		EntityID:   lineID,
+		Amount:     &amount,
+		Details: map[string]interface{}{
+			"decision":  "excluded",
+			"accountId": accountID,
+			"reason":    reason,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.getLine(accountID, lineID)
+}
+
+// AssignTransaction records which of the chama's bank accounts a transaction went
+// through. Once assigned, it is only matched against that account's statements and,
+// while unbanked, is outstanding on that account alone. Matched transactions cannot
+// be moved.
+func (s *BankReconciliationService) AssignTransaction(chamaID, accountID, transactionID, userID string) (*models.BookTransaction, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can reconcile bank statements")
+	}
+	account, err := s.getAccount(chamaID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.bookEntries(account)
+	if err != nil {
+		return nil, err
+	}
+	var entry *bookEntry
+	for i := range entries {
+		if entries[i].ID == transactionID {
+			entry = &entries[i]
+			break
+		}
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("transaction %s is not a completed transaction of this chama", transactionID)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE transactions SET bank_account_id = ?
+		WHERE id = ? AND NOT EXISTS (SELECT 1 FROM bank_statement_line_matches WHERE transaction_id = ?)
+	`, accountID, transactionID, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign transaction: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("transaction %s is already matched to a statement line", transactionID)
+	}
+
+	details := map[string]interface{}{
+		"decision":  "assigned",
+		"accountId": accountID,
+	}
+	if entry.BankAccountID != nil {
+		details["previousAccountId"] = *entry.BankAccountID
+	}
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionDeclaration,
+		EntityType: "transaction",
+		EntityID:   transactionID,
+		Amount:     &entry.Amount,
+		Details:    details,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	entry.BankAccountID = &accountID
+	return &entry.BookTransaction, nil
+}
+
+// GetReconciliationReport compares the bank balance with the chama's books at the
+// end of asOf
+func (s *BankReconciliationService) GetReconciliationReport(chamaID, accountID, userID string, asOf time.Time) (*models.BankReconciliationReport, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can view bank reconciliations")
+	}
+	account, err := s.getAccount(chamaID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	asOf = asOf.In(utils.EATLocation)
+	day := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, utils.EATLocation)
+	cutoff := day.AddDate(0, 0, 1)
+
+	lines, err := s.getLines(accountID, "")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.bookEntries(account)
+	if err != nil {
+		return nil, err
+	}
+	var accounts int
+	err = s.db.QueryRow("SELECT COUNT(*) FROM chama_bank_accounts WHERE chama_id = ? AND is_active = TRUE", chamaID).Scan(&accounts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count bank accounts: %w", err)
+	}
+	bookAmounts := make(map[string]float64, len(entries))
+	for _, entry := range entries {
+		bookAmounts[entry.ID] = entry.SignedAmount
+	}
+
+	report := &models.BankReconciliationReport{
+		AccountID:            accountID,
+		ChamaID:              chamaID,
+		AsOf:                 day,
+		UnmatchedBankLines:   []models.BankStatementLine{},
+		OutstandingBookItems: []models.BookTransaction{},
+	}
+
+	// Lines are in statement order, so the last printed balance seen is the latest
+	statementTotal := account.OpeningBalance
+	var printed *float64
+	book := account.OpeningBalance
+	for _, line := range lines {
+		if !line.TransactionDate.Before(cutoff) {
+			continue
+		}
+		statementTotal += line.Amount
+		if line.Balance != nil {
+			printed = line.Balance
+		}
+		switch line.Status {
+		case models.BankLineMatched:
+			report.MatchedLines++
+			for _, id := range line.TransactionIDs {
+				book += bookAmounts[id]
+			}
+		case models.BankLineExcluded:
+			report.ExcludedBankTotal += line.Amount
+		default:
+			report.UnmatchedBankLines = append(report.UnmatchedBankLines, line)
+			report.UnmatchedBankTotal += line.Amount
+		}
+	}
+
+	// A bank transfer not yet on a statement is outstanding on the account it went
+	// through. Transfers that were never assigned to one can only be placed when the
+	// chama has a single account.
+	for _, entry := range entries {
+		if entry.matched || entry.PaymentMethod != string(models.PaymentMethodBankTransfer) || !entry.Date.Before(cutoff) {
+			continue
+		}
+		if (entry.BankAccountID == nil && accounts > 1) || !entry.belongsTo(accountID) {
+			continue
+		}
+		report.OutstandingBookItems = append(report.OutstandingBookItems, entry.BookTransaction)
+		report.OutstandingBookTotal += entry.SignedAmount
+		book += entry.SignedAmount
+	}
+
+	report.BankBalance = roundCurrency(statementTotal)
+	if printed != nil {
+		report.BankBalance = roundCurrency(*printed)
+	}
+	report.BookBalance = roundCurrency(book)
+	report.UnmatchedBankTotal = roundCurrency(report.UnmatchedBankTotal)
+	report.ExcludedBankTotal = roundCurrency(report.ExcludedBankTotal)
+	report.OutstandingBookTotal = roundCurrency(report.OutstandingBookTotal)
+	report.Difference = roundCurrency(report.BankBalance - report.BookBalance)
+	report.UnexplainedDifference = roundCurrency(report.Difference - report.UnmatchedBankTotal -
+		report.ExcludedBankTotal + report.OutstandingBookTotal)
+	report.Reconciled = report.UnexplainedDifference == 0 && len(report.UnmatchedBankLines) == 0 &&
+		len(report.OutstandingBookItems) == 0
+	return report, nil
+}
+
+// applyMatch claims an unmatched line and links it to transactions. It reports false
+// when the line was matched or excluded in the meantime.
+func (s *BankReconciliationService) applyMatch(tx *sql.Tx, accountID, lineID string, transactionIDs []string, userID *string, method models.BankMatchMethod) (bool, error) {
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE bank_statement_lines
+		SET status = 'matched', match_method = ?, reconciled_by = ?, reconciled_at = ?
+		WHERE id = ? AND account_id = ? AND status = 'unmatched'
+	`, method, userID, now, lineID, accountID)
+	if err != nil {
+		return false, fmt.Errorf("failed to match statement line: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return false, nil
+	}
+
+	for _, transactionID := range transactionIDs {
+		_, err := tx.Exec(`
+			INSERT INTO bank_statement_line_matches (id, line_id, account_id, transaction_id, matched_by, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, uuid.New().String(), lineID, accountID, transactionID, userID, now)
+		if err != nil {
+			if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+				return false, fmt.Errorf("transaction %s is already matched to a statement line", transactionID)
+			}
+			return false, fmt.Errorf("failed to record statement line match: %w", err)
+		}
+	}
+	return true, nil
+}
+
+// bookEntry is a recorded chama transaction and whether a statement line already
+// accounts for it
+type bookEntry struct {
+	models.BookTransaction
+	matched bool
+}
+
+// belongsTo reports whether the entry can appear on an account's statements: it went
+// through that account or has not been assigned to one
+func (e *bookEntry) belongsTo(accountID string) bool {
+	return e.BankAccountID == nil || *e.BankAccountID == accountID
+}
+
+// bookEntries returns the chama's completed transactions since the account's opening
+// date. Money leaving the chama wallet is negative; everything else paid to the
+// chama, such as contributions, is positive.
+func (s *BankReconciliationService) bookEntries(account *models.ChamaBankAccount) ([]bookEntry, error) {
+	var walletID string
+	err := s.db.QueryRow("SELECT id FROM wallets WHERE owner_id = ? AND type = 'chama'", account.ChamaID).Scan(&walletID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get chama wallet: %w", err)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT t.id, t.type, t.description, t.reference, COALESCE(t.payment_method, ''), t.bank_account_id,
+			t.amount, t.from_wallet_id, t.created_at, m.line_id
+		FROM transactions t
+		LEFT JOIN bank_statement_line_matches m ON m.transaction_id = t.id
+		WHERE t.status = 'completed' AND (t.recipient_id = ? OR t.from_wallet_id = ? OR t.to_wallet_id = ?)
+		ORDER BY t.created_at
+	`, account.ChamaID, walletID, walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chama transactions: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []bookEntry{}
+	for rows.Next() {
+		var entry bookEntry
+		var description, reference, bankAccountID, fromWallet, lineID sql.NullString
+		err := rows.Scan(&entry.ID, &entry.Type, &description, &reference, &entry.PaymentMethod, &bankAccountID,
+			&entry.Amount, &fromWallet, &entry.Date, &lineID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan chama transaction: %w", err)
+		}
+		if entry.Date.Before(account.OpeningBalanceDate) {
+			continue
+		}
+		entry.Description = nullStringPtr(description)
+		entry.Reference = nullStringPtr(reference)
+		entry.BankAccountID = nullStringPtr(bankAccountID)
+		entry.SignedAmount = entry.Amount
+		if walletID != "" && fromWallet.Valid && fromWallet.String == walletID {
+			entry.SignedAmount = -entry.Amount
+		}
+		entry.matched = lineID.Valid
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// referenceMatches reports whether a transaction's reference appears on a statement
+// line. Short references are ignored since they match by chance.
+func referenceMatches(line models.BankStatementLine, transaction models.BookTransaction) bool {
+	if transaction.Reference == nil || len(strings.TrimSpace(*transaction.Reference)) < 4 {
+		return false
+	}
+	reference := strings.ToLower(strings.TrimSpace(*transaction.Reference))
+	text := strings.ToLower(line.Description)
+	if line.Reference != nil {
+		text += " " + strings.ToLower(*line.Reference)
+	}
+	return strings.Contains(text, reference)
+}
+
+// daysApart counts the calendar days in EAT between two times
+func daysApart(a, b time.Time) int {
+	a, b = a.In(utils.EATLocation), b.In(utils.EATLocation)
+	dayA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	dayB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(math.Abs(dayA.Sub(dayB).Hours() / 24))
+}
+
+func (s *BankReconciliationService) getAccount(chamaID, accountID string) (*models.ChamaBankAccount, error) {
+	var account models.ChamaBankAccount
+	err := s.db.QueryRow(`
+		SELECT id, chama_id, bank_name, account_name, account_number, currency, opening_balance,
+			opening_balance_date, is_active, created_by, created_at, updated_at
+		FROM chama_bank_accounts WHERE id = ? AND chama_id = ?
+	`, accountID, chamaID).Scan(&account.ID, &account.ChamaID, &account.BankName, &account.AccountName,
+		&account.AccountNumber, &account.Currency, &account.OpeningBalance, &account.OpeningBalanceDate,
+		&account.IsActive, &account.CreatedBy, &account.CreatedAt, &account.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("bank account not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank account: %w", err)
+	}
+	return &account, nil
+}
+
+const bankStatementSelect = `
+	SELECT id, account_id, chama_id, file_id, file_name, format, period_start, period_end,
+		opening_balance, closing_balance, line_count, duplicate_count, imported_by, imported_at
+	FROM bank_statements`
+
+func scanBankStatement(row interface{ Scan(...interface{}) error }) (*models.BankStatement, error) {
+	var statement models.BankStatement
+	var fileID sql.NullString
+	var periodStart, periodEnd sql.NullTime
+	var opening, closing sql.NullFloat64
+	err := row.Scan(&statement.ID, &statement.AccountID, &statement.ChamaID, &fileID, &statement.FileName,
+		&statement.Format, &periodStart, &periodEnd, &opening, &closing, &statement.LineCount,
+		&statement.DuplicateCount, &statement.ImportedBy, &statement.ImportedAt)
+	if err != nil {
+		return nil, err
+	}
+	statement.FileID = nullStringPtr(fileID)
+	if periodStart.Valid {
+		statement.PeriodStart = &periodStart.Time
+	}
+	if periodEnd.Valid {
+		statement.PeriodEnd = &periodEnd.Time
+	}
+	if opening.Valid {
+		statement.OpeningBalance = &opening.Float64
+	}
+	if closing.Valid {
+		statement.ClosingBalance = &closing.Float64
+	}
+	return &statement, nil
+}
+
+func (s *BankReconciliationService) getStatement(accountID, statementID string) (*models.BankStatement, error) {
+	statement, err := scanBankStatement(s.db.QueryRow(bankStatementSelect+" WHERE id = ? AND account_id = ?", statementID, accountID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("bank statement not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank statement: %w", err)
+	}
+	return statement, nil
+}
+
+const bankLineSelect = `
+	SELECT l.id, l.statement_id, l.account_id, l.chama_id, l.line_number, l.transaction_date, l.value_date,
+		l.description, l.reference, l.external_id, l.amount, l.balance, l.status, l.match_method,
+		l.exclusion_reason, l.reconciled_by, l.reconciled_at, l.created_at
+	FROM bank_statement_lines l
+	JOIN bank_statements st ON st.id = l.statement_id`
+
+// getLines returns an account's lines in the order the bank reported them: by date,
+// then by statement and position within it
+func (s *BankReconciliationService) getLines(accountID, status string) ([]models.BankStatementLine, error) {
+	query := bankLineSelect + " WHERE l.account_id = ?"
+	args := []interface{}{accountID}
+	if status != "" {
+		query += " AND l.status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY l.transaction_date, st.imported_at, l.line_number"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []models.BankStatementLine{}
+	for rows.Next() {
+		line, err := scanBankLine(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan statement line: %w", err)
+		}
+		lines = append(lines, *line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get statement lines: %w", err)
+	}
+	rows.Close()
+
+	matches, err := s.lineMatches(accountID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lines {
+		lines[i].TransactionIDs = matches[lines[i].ID]
+		if lines[i].TransactionIDs == nil {
+			lines[i].TransactionIDs = []string{}
+		}
+	}
+	return lines, nil
+}
+
+func (s *BankReconciliationService) getLine(accountID, lineID string) (*models.BankStatementLine, error) {
+	line, err := scanBankLine(s.db.QueryRow(bankLineSelect+" WHERE l.id = ? AND l.account_id = ?", lineID, accountID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("statement line not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement line: %w", err)
+	}
+
+	rows, err := s.db.Query("SELECT transaction_id FROM bank_statement_line_matches WHERE line_id = ? ORDER BY created_at", lineID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement line matches: %w", err)
+	}
+	defer rows.Close()
+
+	line.TransactionIDs = []string{}
+	for rows.Next() {
+		var transactionID string
+		if err := rows.Scan(&transactionID); err != nil {
+			return nil, fmt.Errorf("failed to scan statement line match: %w", err)
+		}
+		line.TransactionIDs = append(line.TransactionIDs, transactionID)
+	}
+	return line, nil
+}
+
+func (s *BankReconciliationService) lineMatches(accountID string) (map[string][]string, error) {
+	rows, err := s.db.Query("SELECT line_id, transaction_id FROM bank_statement_line_matches WHERE account_id = ? ORDER BY created_at", accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement line matches: %w", err)
+	}
+	defer rows.Close()
+
+	matches := map[string][]string{}
+	for rows.Next() {
+		var lineID, transactionID string
+		if err := rows.Scan(&lineID, &transactionID); err != nil {
+			return nil, fmt.Errorf("failed to scan statement line match: %w", err)
+		}
+		matches[lineID] = append(matches[lineID], transactionID)
+	}
+	return matches, nil
+}
+
+func scanBankLine(row interface{ Scan(...interface{}) error }) (*models.BankStatementLine, error) {
+	var line models.BankStatementLine
+	var valueDate, reconciledAt sql.NullTime
+	var reference, matchMethod, exclusionReason, reconciledBy sql.NullString
+	var balance sql.NullFloat64
+	err := row.Scan(&line.ID, &line.StatementID, &line.AccountID, &line.ChamaID, &line.LineNumber,
+		&line.TransactionDate, &valueDate, &line.Description, &reference, &line.ExternalID, &line.Amount,
+		&balance, &line.Status, &matchMethod, &exclusionReason, &reconciledBy, &reconciledAt, &line.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if valueDate.Valid {
+		line.ValueDate = &valueDate.Time
+	}
+	if reconciledAt.Valid {
+		line.ReconciledAt = &reconciledAt.Time
+	}
+	if balance.Valid {
+		line.Balance = &balance.Float64
+	}
+	if matchMethod.Valid {
+		method := models.BankMatchMethod(matchMethod.String)
+		line.MatchMethod = &method
+	}
+	line.Reference = nullStringPtr(reference)
+	line.ExclusionReason = nullStringPtr(exclusionReason)
+	line.ReconciledBy = nullStringPtr(reconciledBy)
+	return &line, nil
+}
+
+func (s *BankReconciliationService) isOfficial(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM chama_members
+		WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+		AND role IN ('chairperson', 'secretary', 'treasurer')
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
@@ -0,0 +1,347 @@
+package services_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/internal/utils"
+	"vaultke-backend/test/helpers"
+)
+
+type BankReconciliationTestSuite struct {
+	suite.Suite
+	testDB      *helpers.TestDatabase
+	db          *sql.DB
+	service     *services.BankReconciliationService
+	treasurerID string
+	memberID    string
+	chamaID     string
+	walletID    string
+}
+
+func (suite *BankReconciliationTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewBankReconciliationService(suite.db)
+
+	suite.treasurerID = suite.testDB.AddTestUser(suite.T(), "Treasurer")
+	suite.memberID = suite.testDB.AddTestUser(suite.T(), "Member")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.treasurerID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.treasurerID, "treasurer")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.memberID, "member")
+
+	suite.walletID = uuid.New().String()
+	_, err := suite.db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'chama', ?, 0)", suite.walletID, suite.chamaID)
+	suite.Require().NoError(err)
+}
+
+func (suite *BankReconciliationTestSuite) createAccount(number string) *models.ChamaBankAccount {
+	account, err := suite.service.CreateBankAccount(suite.chamaID, suite.treasurerID, &models.CreateBankAccountRequest{
+		BankName:           "Equity Bank",
+		AccountName:        "Umoja Chama",
+		AccountNumber:      number,
+		OpeningBalance:     10000,
+		OpeningBalanceDate: time.Date(2024, time.March, 1, 0, 0, 0, 0, utils.EATLocation),
+	})
+	suite.Require().NoError(err)
+	return account
+}
+
+// record inserts a completed chama transaction; outgoing ones leave the chama wallet
+func (suite *BankReconciliationTestSuite) record(txType string, amount float64, method, reference string, outgoing bool, day int) string {
+	id := uuid.New().String()
+	var fromWallet interface{}
+	if outgoing {
+		fromWallet = suite.walletID
+	}
+	var ref interface{}
+	if reference != "" {
+		ref = reference
+	}
+	date := time.Date(2024, time.March, day, 10, 0, 0, 0, utils.EATLocation)
+	_, err := suite.db.Exec(`
+		INSERT INTO transactions (
+			id, from_wallet_id, type, status, amount, currency, description, reference, payment_method,
+			initiated_by, recipient_id, created_at, updated_at
+		) VALUES (?, ?, ?, 'completed', ?, 'KES', ?, ?, ?, ?, ?, ?, ?)
+	`, id, fromWallet, txType, amount, txType, ref, method, suite.treasurerID, suite.chamaID, date, date)
+	suite.Require().NoError(err)
+	return id
+}
+
+func (suite *BankReconciliationTestSuite) linesByAmount(accountID string) map[float64]models.BankStatementLine {
+	lines, err := suite.service.GetStatementLines(suite.chamaID, accountID, suite.treasurerID, "")
+	suite.Require().NoError(err)
+	byAmount := map[float64]models.BankStatementLine{}
+	for _, line := range lines {
+		byAmount[line.Amount] = line
+	}
+	return byAmount
+}
+
+const marchStatementCSV = `Account Statement,Umoja Chama
+Account Number,0123456789
+Date,Description,Reference,Money Out,Money In,Balance
+04/03/2024,DEPOSIT ABC123,ABC123,,"2,000.00","12,000.00"
+06/03/2024,CASH DEPOSIT,,,"2,500.00","14,500.00"
+08/03/2024,CHQ 0042 VENUE HIRE,CHQ0042,800.00,,"13,700.00"
+15/03/2024,LEDGER FEE,,50.00,,"13,650.00"
+,Total,,850.00,"4,500.00",
+`
+
+const lateMarchStatementCSV = `Date,Description,Reference,Money Out,Money In,Balance
+15/03/2024,LEDGER FEE,,50.00,,"13,650.00"
+29/03/2024,TRANSFER FROM MEMBER,DEP5000,,"5,000.00","18,650.00"
+`
+
+func (suite *BankReconciliationTestSuite) TestImportMatchAndReconcile() {
+	account := suite.createAccount("0123456789")
+	_, err := suite.service.CreateBankAccount(suite.chamaID, suite.memberID, &models.CreateBankAccountRequest{
+		BankName: "KCB", AccountName: "Umoja", AccountNumber: "999", OpeningBalanceDate: time.Now(),
+	})
+	suite.Error(err, "only officials manage bank accounts")
+
+	deposit := suite.record("contribution", 2000, "bank_transfer", "ABC123", false, 4)
+	cashA := suite.record("contribution", 1500, "cash", "", false, 5)
+	cashB := suite.record("contribution", 1000, "cash", "", false, 5)
+	venue := suite.record("expense", 800, "bank_transfer", "", true, 7)
+	transfer := suite.record("contribution", 5000, "bank_transfer", "", false, 28)
+
+	_, err = suite.service.ImportStatement(suite.chamaID, account.ID, suite.memberID, "march.csv", []byte(marchStatementCSV), nil)
+	suite.Error(err, "only officials import statements")
+
+	imported, err := suite.service.ImportStatement(suite.chamaID, account.ID, suite.treasurerID, "march.csv", []byte(marchStatementCSV), nil)
+	suite.Require().NoError(err)
+	suite.Equal(4, imported.Imported, "the preamble and totals rows are skipped")
+	suite.Equal(2, imported.AutoMatched, "the deposit by reference and the cheque a day after it was recorded")
+	suite.Equal(models.BankStatementCSV, imported.Statement.Format)
+
+	_, err = suite.service.ImportStatement(suite.chamaID, account.ID, suite.treasurerID, "march.csv", []byte(marchStatementCSV), nil)
+	suite.Error(err, "the same file is imported once")
+
+	lines := suite.linesByAmount(account.ID)
+	suite.Equal([]string{deposit}, lines[2000].TransactionIDs)
+	suite.Equal([]string{venue}, lines[-800].TransactionIDs)
+	suite.Equal(models.BankLineUnmatched, lines[2500].Status, "no single transaction explains the banked cash")
+
+	report, err := suite.service.GetReconciliationReport(suite.chamaID, account.ID, suite.treasurerID, time.Date(2024, time.March, 31, 0, 0, 0, 0, utils.EATLocation))
+	suite.Require().NoError(err)
+	suite.Equal(13650.0, report.BankBalance)
+	suite.Equal(16200.0, report.BookBalance)
+	suite.Equal(2450.0, report.UnmatchedBankTotal)
+	suite.Require().Len(report.OutstandingBookItems, 1)
+	suite.Equal(transfer, report.OutstandingBookItems[0].ID)
+	suite.Zero(report.UnexplainedDifference)
+	suite.False(report.Reconciled)
+
+	// The banked cash is two contributions
+	candidates, err := suite.service.GetMatchCandidates(suite.chamaID, account.ID, lines[2500].ID, suite.treasurerID)
+	suite.Require().NoError(err)
+	candidateIDs := map[string]bool{}
+	for _, candidate := range candidates {
+		candidateIDs[candidate.ID] = true
+	}
+	suite.True(candidateIDs[cashA] && candidateIDs[cashB])
+	suite.False(candidateIDs[venue], "money out is not offered for a deposit")
+
+	_, err = suite.service.MatchLine(suite.chamaID, account.ID, lines[2500].ID, suite.treasurerID, []string{cashA})
+	suite.Error(err, "the transactions must add up to the line")
+	_, err = suite.service.MatchLine(suite.chamaID, account.ID, lines[2500].ID, suite.treasurerID, []string{cashA, cashB, deposit})
+	suite.Error(err, "a transaction is matched once")
+	matched, err := suite.service.MatchLine(suite.chamaID, account.ID, lines[2500].ID, suite.treasurerID, []string{cashA, cashB})
+	suite.Require().NoError(err)
+	suite.Equal(models.BankLineMatched, matched.Status)
+	suite.Equal(models.BankMatchManual, *matched.MatchMethod)
+	suite.Len(matched.TransactionIDs, 2)
+
+	_, err = suite.service.ExcludeLine(suite.chamaID, account.ID, lines[-50].ID, suite.treasurerID, " ")
+	suite.Error(err, "a reason is required")
+	excluded, err := suite.service.ExcludeLine(suite.chamaID, account.ID, lines[-50].ID, suite.treasurerID, "Monthly ledger fee")
+	suite.Require().NoError(err)
+	suite.Equal(models.BankLineExcluded, excluded.Status)
+
+	// An overlapping statement adds only the new line, which matches the outstanding transfer
+	overlap, err := suite.service.ImportStatement(suite.chamaID, account.ID, suite.treasurerID, "late-march.csv", []byte(lateMarchStatementCSV), nil)
+	suite.Require().NoError(err)
+	suite.Equal(1, overlap.Imported)
+	suite.Equal(1, overlap.Duplicates)
+	suite.Equal(1, overlap.AutoMatched)
+
+	report, err = suite.service.GetReconciliationReport(suite.chamaID, account.ID, suite.treasurerID, time.Date(2024, time.March, 31, 0, 0, 0, 0, utils.EATLocation))
+	suite.Require().NoError(err)
+	suite.Equal(18650.0, report.BankBalance)
+	suite.Equal(18700.0, report.BookBalance)
+	suite.Equal(-50.0, report.Difference)
+	suite.Equal(-50.0, report.ExcludedBankTotal)
+	suite.Empty(report.OutstandingBookItems)
+	suite.Zero(report.UnexplainedDifference)
+	suite.True(report.Reconciled)
+	suite.Equal(4, report.MatchedLines)
+
+	unmatched, err := suite.service.UnmatchLine(suite.chamaID, account.ID, lines[2500].ID, suite.treasurerID)
+	suite.Require().NoError(err)
+	suite.Equal(models.BankLineUnmatched, unmatched.Status)
+	suite.Empty(unmatched.TransactionIDs)
+	report, err = suite.service.GetReconciliationReport(suite.chamaID, account.ID, suite.treasurerID, time.Date(2024, time.March, 31, 0, 0, 0, 0, utils.EATLocation))
+	suite.Require().NoError(err)
+	suite.False(report.Reconciled)
+	suite.Equal(2500.0, report.UnmatchedBankTotal)
+}
+
+const sampleOFX = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>KES
+<BANKTRANLIST>
+<DTSTART>20240301
+<DTEND>20240331
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240304120000[+3:EAT]
+<TRNAMT>2000.00
+<FITID>FT2406400001
+<NAME>DEPOSIT
+<MEMO>ABC123
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240308
+<TRNAMT>-800.00
+<FITID>FT2406800002
+<NAME>CHEQUE
+<CHECKNUM>0042
+</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL><BALAMT>11200.00<DTASOF>20240331</LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const sampleCAMT053 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <Stmt>
+      <FrToDt><FrDtTm>2024-03-01T00:00:00</FrDtTm><ToDtTm>2024-03-31T23:59:59</ToDtTm></FrToDt>
+      <Bal><Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp><Amt Ccy="KES">10000.00</Amt><CdtDbtInd>CRDT</CdtDbtInd></Bal>
+      <Bal><Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp><Amt Ccy="KES">11200.00</Amt><CdtDbtInd>CRDT</CdtDbtInd></Bal>
+      <Ntry>
+        <Amt Ccy="KES">2000.00</Amt><CdtDbtInd>CRDT</CdtDbtInd>
+        <BookgDt><Dt>2024-03-04</Dt></BookgDt><ValDt><Dt>2024-03-05</Dt></ValDt>
+        <AcctSvcrRef>EQ-0001</AcctSvcrRef>
+        <NtryDtls><TxDtls><Refs><EndToEndId>ABC123</EndToEndId></Refs><RmtInf><Ustrd>Member deposit</Ustrd></RmtInf></TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="KES">800.00</Amt><CdtDbtInd>DBIT</CdtDbtInd>
+        <BookgDt><DtTm>2024-03-08T09:30:00</DtTm></BookgDt>
+        <AcctSvcrRef>EQ-0002</AcctSvcrRef>
+        <AddtlNtryInf>Cheque 0042</AddtlNtryInf>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>
+`
+
+func (suite *BankReconciliationTestSuite) TestOFXAndCAMT053Statements() {
+	for _, statement := range []struct {
+		number, fileName, content string
+		format                    models.BankStatementFormat
+	}{
+		{"111", "march.ofx", sampleOFX, models.BankStatementOFX},
+		{"222", "march.xml", sampleCAMT053, models.BankStatementCAMT053},
+	} {
+		account := suite.createAccount(statement.number)
+		imported, err := suite.service.ImportStatement(suite.chamaID, account.ID, suite.treasurerID, statement.fileName, []byte(statement.content), nil)
+		suite.Require().NoError(err, statement.fileName)
+		suite.Equal(statement.format, imported.Statement.Format)
+		suite.Equal(2, imported.Imported)
+		suite.Require().NotNil(imported.Statement.ClosingBalance)
+		suite.Equal(11200.0, *imported.Statement.ClosingBalance)
+		suite.Require().NotNil(imported.Statement.PeriodStart)
+		suite.Equal("2024-03-01", imported.Statement.PeriodStart.In(utils.EATLocation).Format("2006-01-02"))
+
+		lines := suite.linesByAmount(account.ID)
+		suite.Require().Len(lines, 2, statement.fileName)
+		suite.Equal(4, lines[2000].TransactionDate.In(utils.EATLocation).Day())
+		suite.Equal(8, lines[-800].TransactionDate.In(utils.EATLocation).Day())
+		text := func(line models.BankStatementLine) string {
+			if line.Reference == nil {
+				return line.Description
+			}
+			return line.Description + " " + *line.Reference
+		}
+		suite.Contains(text(lines[2000]), "ABC123")
+		suite.Contains(text(lines[-800]), "0042")
+	}
+}
+
+func (suite *BankReconciliationTestSuite) TestUnreadableStatementsAreRejected() {
+	account := suite.createAccount("0123456789")
+
+	_, err := suite.service.ImportStatement(suite.chamaID, account.ID, suite.treasurerID, "notes.csv", []byte("Name,Phone\nJane,0700000000\n"), nil)
+	suite.Error(err, "a CSV without date and amount columns is not a statement")
+
+	_, err = suite.service.ImportStatement(suite.chamaID, account.ID, suite.treasurerID, "empty.csv", []byte("Date,Details,Amount\n"), nil)
+	suite.Error(err, "a statement needs transactions")
+
+	// Lines before the opening balance are already part of it
+	old := "Transaction Date,Narrative,Amount\n28-Feb-2024,Old deposit,100\n02-Mar-2024,New deposit,(250.00)\n"
+	imported, err := suite.service.ImportStatement(suite.chamaID, account.ID, suite.treasurerID, "old.csv", []byte(old), nil)
+	suite.Require().NoError(err)
+	suite.Equal(1, imported.Imported)
+	suite.Equal(1, imported.BeforeOpening)
+	lines := suite.linesByAmount(account.ID)
+	suite.Contains(lines, -250.0)
+}
+
+func (suite *BankReconciliationTestSuite) TestTransfersBelongToTheirOwnAccount() {
+	current := suite.createAccount("0123456789")
+	savings := suite.createAccount("9876543210")
+	transfer := suite.record("contribution", 5000, "bank_transfer", "", false, 28)
+	endOfMarch := time.Date(2024, time.March, 31, 0, 0, 0, 0, utils.EATLocation)
+
+	for _, account := range []*models.ChamaBankAccount{current, savings} {
+		report, err := suite.service.GetReconciliationReport(suite.chamaID, account.ID, suite.treasurerID, endOfMarch)
+		suite.Require().NoError(err)
+		suite.Empty(report.OutstandingBookItems, "an unassigned transfer is not outstanding on every account")
+	}
+
+	_, err := suite.service.AssignTransaction(suite.chamaID, current.ID, transfer, suite.memberID)
+	suite.Error(err, "only officials assign transactions")
+	assigned, err := suite.service.AssignTransaction(suite.chamaID, current.ID, transfer, suite.treasurerID)
+	suite.Require().NoError(err)
+	suite.Equal(current.ID, *assigned.BankAccountID)
+
+	report, err := suite.service.GetReconciliationReport(suite.chamaID, current.ID, suite.treasurerID, endOfMarch)
+	suite.Require().NoError(err)
+	suite.Require().Len(report.OutstandingBookItems, 1)
+	suite.Equal(transfer, report.OutstandingBookItems[0].ID)
+	report, err = suite.service.GetReconciliationReport(suite.chamaID, savings.ID, suite.treasurerID, endOfMarch)
+	suite.Require().NoError(err)
+	suite.Empty(report.OutstandingBookItems)
+
+	imported, err := suite.service.ImportStatement(suite.chamaID, savings.ID, suite.treasurerID, "savings.csv", []byte(lateMarchStatementCSV), nil)
+	suite.Require().NoError(err)
+	suite.Zero(imported.AutoMatched, "a transfer through one account is not matched on another")
+	line := suite.linesByAmount(savings.ID)[5000]
+	_, err = suite.service.MatchLine(suite.chamaID, savings.ID, line.ID, suite.treasurerID, []string{transfer})
+	suite.Error(err)
+
+	imported, err = suite.service.ImportStatement(suite.chamaID, current.ID, suite.treasurerID, "current.csv", []byte(lateMarchStatementCSV), nil)
+	suite.Require().NoError(err)
+	suite.Equal(1, imported.AutoMatched)
+	_, err = suite.service.AssignTransaction(suite.chamaID, savings.ID, transfer, suite.treasurerID)
+	suite.Error(err, "matched transactions stay on their account")
+}
+
+func TestBankReconciliationSuite(t *testing.T) {
+	suite.Run(t, new(BankReconciliationTestSuite))
+}
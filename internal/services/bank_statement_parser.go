@@ -0,0 +1,550 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/utils"
+)
+
+// parsedStatement is a bank statement read from a file, before it is stored
+type parsedStatement struct {
+	Format         models.BankStatementFormat
+	PeriodStart    *time.Time
+	PeriodEnd      *time.Time
+	OpeningBalance *float64
+	ClosingBalance *float64
+	Lines          []parsedStatementLine
+}
+
+// parsedStatementLine is one statement entry. Amount is positive for credits.
+// ExternalID identifies the entry across statements so an overlapping statement
+// does not import it twice: the bank's own transaction ID where the format has one,
+// otherwise a hash of the entry's fields.
+type parsedStatementLine struct {
+	Date        time.Time
+	ValueDate   *time.Time
+	Description string
+	Reference   string
+	Amount      float64
+	Balance     *float64
+	ExternalID  string
+}
+
+// parseBankStatement reads a CSV, OFX or CAMT.053 statement, telling the format
+// apart from the content rather than the file name
+func parseBankStatement(data []byte) (*parsedStatement, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	head := data
+	if len(head) > 2048 {
+		head = head[:2048]
+	}
+
+	var statement *parsedStatement
+	var err error
+	switch {
+	case bytes.Contains(head, []byte("BkToCstmrStmt")):
+		statement, err = parseCAMT053(data)
+	case bytes.Contains(head, []byte("OFXHEADER")) || bytes.Contains(bytes.ToUpper(head), []byte("<OFX>")):
+		statement, err = parseOFX(data)
+	default:
+		statement, err = parseStatementCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(statement.Lines) == 0 {
+		return nil, fmt.Errorf("the statement has no transactions")
+	}
+
+	for _, line := range statement.Lines {
+		date := line.Date
+		if statement.PeriodStart == nil || date.Before(*statement.PeriodStart) {
+			statement.PeriodStart = &date
+		}
+		if statement.PeriodEnd == nil || date.After(*statement.PeriodEnd) {
+			statement.PeriodEnd = &date
+		}
+	}
+	return statement, nil
+}
+
+// assignLineIDs gives lines without a bank transaction ID a hash of their fields.
+// Identical entries on the same day, such as two equal deposits, are told apart by
+// the order they appear in.
+func assignLineIDs(lines []parsedStatementLine) {
+	seen := map[string]int{}
+	for i := range lines {
+		if lines[i].ExternalID != "" {
+			continue
+		}
+		balance := ""
+		if lines[i].Balance != nil {
+			balance = strconv.FormatFloat(*lines[i].Balance, 'f', 2, 64)
+		}
+		key := strings.Join([]string{
+			lines[i].Date.Format("2006-01-02"),
+			strconv.FormatFloat(lines[i].Amount, 'f', 2, 64),
+			strings.ToLower(strings.Join(strings.Fields(lines[i].Description), " ")),
+			strings.ToLower(lines[i].Reference),
+			balance,
+		}, "|")
+		seen[key]++
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s#%d", key, seen[key])))
+		lines[i].ExternalID = "h:" + hex.EncodeToString(sum[:16])
+	}
+}
+
+// statementDateLayouts are the date formats seen on Kenyan bank exports. Day-first
+// layouts come before month-first ones, so 03/04/2024 is the 3rd of April.
+var statementDateLayouts = []string{
+	"2006-01-02",
+	"02/01/2006",
+	"2/1/2006",
+	"02-01-2006",
+	"2-1-2006",
+	"02.01.2006",
+	"2006/01/02",
+	"02 Jan 2006",
+	"2 Jan 2006",
+	"02-Jan-2006",
+	"2-Jan-2006",
+	"02-Jan-06",
+	"02 January 2006",
+	"2 January 2006",
+	"Jan 2, 2006",
+	"02/01/06",
+	"20060102",
+}
+
+// parseStatementDate reads a statement date as a day in EAT. A time of day after
+// the date is ignored.
+func parseStatementDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	candidates := []string{value}
+	if i := strings.IndexAny(value, " T"); i > 0 {
+		candidates = append(candidates, value[:i])
+	}
+	for _, candidate := range candidates {
+		for _, layout := range statementDateLayouts {
+			if parsed, err := time.ParseInLocation(layout, candidate, utils.EATLocation); err == nil {
+				return parsed, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseStatementAmount reads an amount such as "1,250.00", "(300.00)", "-300",
+// "KES 1,000" or "300.00 DR"
+func parseStatementAmount(value string) (float64, bool) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" || value == "-" {
+		return 0, false
+	}
+
+	sign := 1.0
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		sign = -1
+		value = strings.Trim(value, "()")
+	}
+	if strings.HasSuffix(value, "DR") {
+		sign = -sign
+		value = strings.TrimSuffix(value, "DR")
+	} else {
+		value = strings.TrimSuffix(value, "CR")
+	}
+	for _, currency := range []string{"KES", "KSH", "USD", "EUR", "GBP"} {
+		value = strings.ReplaceAll(value, currency, "")
+	}
+	value = strings.NewReplacer(",", "", " ", "", "\u00a0", "").Replace(value)
+	value = strings.TrimPrefix(value, "+")
+	if strings.HasPrefix(value, "-") {
+		sign = -sign
+		value = strings.TrimPrefix(value, "-")
+	}
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+	return sign * amount, true
+}
+
+// statementColumns are the positions of the CSV columns the parser understands, or
+// -1 when a statement does not have one
+type statementColumns struct {
+	date, valueDate, description, reference, amount, debit, credit, balance int
+}
+
+// detectStatementColumns recognises a header row by its column names. Banks label
+// the same column differently, so names are matched loosely.
+func detectStatementColumns(header []string) (statementColumns, bool) {
+	columns := statementColumns{-1, -1, -1, -1, -1, -1, -1, -1}
+	set := func(target *int, index int) {
+		if *target == -1 {
+			*target = index
+		}
+	}
+	containsAny := func(name string, words ...string) bool {
+		for _, word := range words {
+			if strings.Contains(name, word) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i, cell := range header {
+		name := strings.ToLower(strings.Join(strings.Fields(cell), " "))
+		switch {
+		case name == "":
+		case strings.Contains(name, "value") && strings.Contains(name, "date"):
+			set(&columns.valueDate, i)
+		case containsAny(name, "date", "completion time"):
+			set(&columns.date, i)
+		case strings.Contains(name, "balance"):
+			set(&columns.balance, i)
+		case containsAny(name, "debit", "withdraw", "money out", "paid out") || name == "dr":
+			set(&columns.debit, i)
+		case containsAny(name, "credit", "deposit", "money in", "paid in") || name == "cr":
+			set(&columns.credit, i)
+		case containsAny(name, "reference", "ref", "cheque", "receipt"):
+			set(&columns.reference, i)
+		case containsAny(name, "description", "narrative", "narration", "details", "particulars", "memo", "remarks"):
+			set(&columns.description, i)
+		case strings.Contains(name, "amount"):
+			set(&columns.amount, i)
+		}
+	}
+
+	hasAmount := columns.amount != -1 || columns.debit != -1 || columns.credit != -1
+	return columns, columns.date != -1 && hasAmount
+}
+
+// parseStatementCSV reads a CSV export. Rows above the header, such as the account
+// details many banks print first, and rows without a date, such as totals, are
+// skipped.
+func parseStatementCSV(data []byte) (*parsedStatement, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	firstLine := data
+	if i := bytes.IndexByte(firstLine, '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	statement := &parsedStatement{Format: models.BankStatementCSV}
+	var columns statementColumns
+	headerFound := false
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV statement: %w", err)
+		}
+
+		if !headerFound {
+			columns, headerFound = detectStatementColumns(record)
+			continue
+		}
+
+		cell := func(index int) string {
+			if index < 0 || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+
+		date, ok := parseStatementDate(cell(columns.date))
+		if !ok {
+			continue
+		}
+
+		var amount float64
+		if columns.amount != -1 {
+			if amount, ok = parseStatementAmount(cell(columns.amount)); !ok {
+				continue
+			}
+		} else {
+			credit, hasCredit := parseStatementAmount(cell(columns.credit))
+			debit, hasDebit := parseStatementAmount(cell(columns.debit))
+			if !hasCredit && !hasDebit {
+				continue
+			}
+			if debit < 0 {
+				debit = -debit
+			}
+			amount = credit - debit
+		}
+		if roundCurrency(amount) == 0 {
+			continue
+		}
+
+		line := parsedStatementLine{
+			Date:        date,
+			Description: cell(columns.description),
+			Reference:   cell(columns.reference),
+			Amount:      roundCurrency(amount),
+		}
+		if valueDate, ok := parseStatementDate(cell(columns.valueDate)); ok {
+			line.ValueDate = &valueDate
+		}
+		if balance, ok := parseStatementAmount(cell(columns.balance)); ok {
+			line.Balance = &balance
+		}
+		statement.Lines = append(statement.Lines, line)
+	}
+
+	if !headerFound {
+		return nil, fmt.Errorf("could not find the date and amount columns in the CSV statement")
+	}
+	assignLineIDs(statement.Lines)
+	return statement, nil
+}
+
+var (
+	ofxTransactionPattern = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+	ofxLedgerPattern      = regexp.MustCompile(`(?is)<LEDGERBAL>(.*?)</LEDGERBAL>`)
+)
+
+// ofxValue reads an OFX element. OFX 1 (SGML) leaves elements unclosed, so the
+// value runs to the next tag or line break.
+func ofxValue(block, tag string) string {
+	pattern := regexp.MustCompile(`(?i)<` + tag + `>([^<\r\n]*)`)
+	match := pattern.FindStringSubmatch(block)
+	if match == nil {
+		return ""
+	}
+	return strings.TrimSpace(match[1])
+}
+
+// parseOFXDate reads the date part of an OFX timestamp such as 20240315120000[+3:EAT]
+func parseOFXDate(value string) (time.Time, bool) {
+	if len(value) < 8 {
+		return time.Time{}, false
+	}
+	parsed, err := time.ParseInLocation("20060102", value[:8], utils.EATLocation)
+	return parsed, err == nil
+}
+
+// parseOFX reads an OFX 1 or OFX 2 bank statement download
+func parseOFX(data []byte) (*parsedStatement, error) {
+	content := string(data)
+	statement := &parsedStatement{Format: models.BankStatementOFX}
+
+	for _, match := range ofxTransactionPattern.FindAllStringSubmatch(content, -1) {
+		block := match[1]
+		date, ok := parseOFXDate(ofxValue(block, "DTPOSTED"))
+		if !ok {
+			return nil, fmt.Errorf("OFX transaction has an invalid DTPOSTED date")
+		}
+		amount, ok := parseStatementAmount(ofxValue(block, "TRNAMT"))
+		if !ok {
+			return nil, fmt.Errorf("OFX transaction has an invalid TRNAMT amount")
+		}
+
+		description := ofxValue(block, "NAME")
+		if memo := ofxValue(block, "MEMO"); memo != "" && memo != description {
+			description = strings.TrimSpace(description + " " + memo)
+		}
+		reference := ofxValue(block, "REFNUM")
+		if reference == "" {
+			reference = ofxValue(block, "CHECKNUM")
+		}
+
+		line := parsedStatementLine{
+			Date:        date,
+			Description: description,
+			Reference:   reference,
+			Amount:      roundCurrency(amount),
+		}
+		if fitID := ofxValue(block, "FITID"); fitID != "" {
+			line.ExternalID = "ofx:" + fitID
+		}
+		if valueDate, ok := parseOFXDate(ofxValue(block, "DTAVAIL")); ok {
+			line.ValueDate = &valueDate
+		}
+		statement.Lines = append(statement.Lines, line)
+	}
+
+	if start, ok := parseOFXDate(ofxValue(content, "DTSTART")); ok {
+		statement.PeriodStart = &start
+	}
+	if end, ok := parseOFXDate(ofxValue(content, "DTEND")); ok {
+		statement.PeriodEnd = &end
+	}
+	if ledger := ofxLedgerPattern.FindStringSubmatch(content); ledger != nil {
+		if balance, ok := parseStatementAmount(ofxValue(ledger[1], "BALAMT")); ok {
+			statement.ClosingBalance = &balance
+		}
+	}
+
+	assignLineIDs(statement.Lines)
+	return statement, nil
+}
+
+// camtDate is an ISO 20022 date, given either as a date or a date and time
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+func (d camtDate) parse() (time.Time, bool) {
+	if d.Date != "" {
+		return parseStatementDate(d.Date)
+	}
+	return parseStatementDate(d.DateTime)
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+// camtDocument holds the parts of a CAMT.053 statement the reconciliation uses.
+// Tags are matched without their namespace, so every version of the schema reads.
+type camtDocument struct {
+	Statements []struct {
+		FromTo struct {
+			From string `xml:"FrDtTm"`
+			To   string `xml:"ToDtTm"`
+		} `xml:"FrToDt"`
+		Balances []struct {
+			Code      string     `xml:"Tp>CdOrPrtry>Cd"`
+			Amount    camtAmount `xml:"Amt"`
+			Indicator string     `xml:"CdtDbtInd"`
+		} `xml:"Bal"`
+		Entries []struct {
+			EntryRef      string     `xml:"NtryRef"`
+			Amount        camtAmount `xml:"Amt"`
+			Indicator     string     `xml:"CdtDbtInd"`
+			BookingDate   camtDate   `xml:"BookgDt"`
+			ValueDate     camtDate   `xml:"ValDt"`
+			ServicerRef   string     `xml:"AcctSvcrRef"`
+			AdditionalInf string     `xml:"AddtlNtryInf"`
+			Details       []struct {
+				EndToEndID  string   `xml:"Refs>EndToEndId"`
+				ServicerRef string   `xml:"Refs>AcctSvcrRef"`
+				Remittance  []string `xml:"RmtInf>Ustrd"`
+			} `xml:"NtryDtls>TxDtls"`
+		} `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+// camtSignedAmount applies a CRDT/DBIT indicator to an amount
+func camtSignedAmount(amount camtAmount, indicator string) (float64, bool) {
+	value, ok := parseStatementAmount(amount.Value)
+	if !ok {
+		return 0, false
+	}
+	if strings.EqualFold(strings.TrimSpace(indicator), "DBIT") {
+		value = -value
+	}
+	return value, true
+}
+
+// parseCAMT053 reads an ISO 20022 CAMT.053 end-of-day statement. A file with
+// several statements is read as one.
+func parseCAMT053(data []byte) (*parsedStatement, error) {
+	var document camtDocument
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to read CAMT.053 statement: %w", err)
+	}
+
+	statement := &parsedStatement{Format: models.BankStatementCAMT053}
+	for _, stmt := range document.Statements {
+		for _, balance := range stmt.Balances {
+			amount, ok := camtSignedAmount(balance.Amount, balance.Indicator)
+			if !ok {
+				continue
+			}
+			switch balance.Code {
+			case "OPBD", "PRCD":
+				if statement.OpeningBalance == nil {
+					statement.OpeningBalance = &amount
+				}
+			case "CLBD":
+				statement.ClosingBalance = &amount
+			}
+		}
+		if start, ok := parseStatementDate(stmt.FromTo.From); ok {
+			if statement.PeriodStart == nil || start.Before(*statement.PeriodStart) {
+				statement.PeriodStart = &start
+			}
+		}
+		if end, ok := parseStatementDate(stmt.FromTo.To); ok {
+			if statement.PeriodEnd == nil || end.After(*statement.PeriodEnd) {
+				statement.PeriodEnd = &end
+			}
+		}
+
+		for _, entry := range stmt.Entries {
+			amount, ok := camtSignedAmount(entry.Amount, entry.Indicator)
+			if !ok {
+				return nil, fmt.Errorf("CAMT.053 entry has an invalid amount")
+			}
+			date, ok := entry.BookingDate.parse()
+			if !ok {
+				if date, ok = entry.ValueDate.parse(); !ok {
+					return nil, fmt.Errorf("CAMT.053 entry has no booking date")
+				}
+			}
+
+			var descriptions []string
+			reference := ""
+			servicerRef := entry.ServicerRef
+			for _, detail := range entry.Details {
+				descriptions = append(descriptions, detail.Remittance...)
+				if reference == "" && detail.EndToEndID != "" && detail.EndToEndID != "NOTPROVIDED" {
+					reference = detail.EndToEndID
+				}
+				if servicerRef == "" {
+					servicerRef = detail.ServicerRef
+				}
+			}
+			if entry.AdditionalInf != "" {
+				descriptions = append(descriptions, entry.AdditionalInf)
+			}
+			if reference == "" {
+				reference = entry.EntryRef
+			}
+
+			line := parsedStatementLine{
+				Date:        date,
+				Description: strings.TrimSpace(strings.Join(descriptions, " ")),
+				Reference:   strings.TrimSpace(reference),
+				Amount:      roundCurrency(amount),
+			}
+			if servicerRef != "" {
+				line.ExternalID = "camt:" + servicerRef
+			}
+			if valueDate, ok := entry.ValueDate.parse(); ok {
+				line.ValueDate = &valueDate
+			}
+			statement.Lines = append(statement.Lines, line)
+		}
+	}
+
+	assignLineIDs(statement.Lines)
+	return statement, nil
+}
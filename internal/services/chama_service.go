@@ -641,25 +641,53 @@ func (s *ChamaService) UpdateMemberRole(chamaID, userID string, newRole models.C
 		return fmt.Errorf("only chairperson can update member roles")
 	}
 
-	// Update role
-	query := "UPDATE chama_members SET role = ? WHERE chama_id = ? AND user_id = ?"
-	_, err = s.db.Exec(query, newRole, chamaID, userID)
+	return s.UpdateMemberRoleSimple(chamaID, userID, string(newRole), updatedBy)
+}
+
+// UpdateMemberRoleSimple updates a member's role in a chama (for API compatibility)
+// and records the change in the chama's audit log
+func (s *ChamaService) UpdateMemberRoleSimple(chamaID, userID, newRole, changedBy string) error {
+	tx, err := s.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to update member role: %w", err)
+		return fmt.Errorf("failed to start transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	return nil
-}
+	var oldRole string
+	err = tx.QueryRow("SELECT role FROM chama_members WHERE chama_id = ? AND user_id = ?", chamaID, userID).Scan(&oldRole)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("member not found")
+		}
+		return fmt.Errorf("failed to get member role: %w", err)
+	}
 
-// UpdateMemberRoleSimple updates a member's role in a chama (for API compatibility)
-func (s *ChamaService) UpdateMemberRoleSimple(chamaID, userID, newRole string) error {
 	// Update role directly with string
-	query := "UPDATE chama_members SET role = ?, updated_at = ? WHERE chama_id = ? AND user_id = ?"
-	_, err := s.db.Exec(query, newRole, time.Now(), chamaID, userID)
+	query := "UPDATE chama_members SET role = ? WHERE chama_id = ? AND user_id = ?"
+	_, err = tx.Exec(query, newRole, chamaID, userID)
 	if err != nil {
 		return fmt.Errorf("failed to update member role: %w", err)
 	}
 
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &changedBy,
+		Action:     models.AuditActionRoleChange,
+		EntityType: "chama_member",
+		EntityID:   userID,
+		Details: map[string]interface{}{
+			"oldRole": oldRole,
+			"newRole": newRole,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
@@ -1287,7 +1315,7 @@ func (s *ChamaService) GetUserRoleInChama(chamaID, userID string) (string, error
 }
 
 // UpdateChamaSettings updates chama settings (only for chairperson)
-func (s *ChamaService) UpdateChamaSettings(chamaID string, updates interface{}) error {
+func (s *ChamaService) UpdateChamaSettings(chamaID string, updates interface{}, updatedBy string) error {
 	// Start transaction
 	tx, err := s.db.Begin()
 	if err != nil {
@@ -1372,6 +1400,11 @@ func (s *ChamaService) UpdateChamaSettings(chamaID string, updates interface{})
 		return fmt.Errorf("no fields to update")
 	}
 
+	changedFields := make([]string, 0, len(setParts))
+	for _, part := range setParts {
+		changedFields = append(changedFields, strings.TrimSuffix(part, " = ?"))
+	}
+
 	// Add updated_at timestamp
 	setParts = append(setParts, "updated_at = ?")
 	args = append(args, time.Now())
@@ -1387,6 +1420,18 @@ func (s *ChamaService) UpdateChamaSettings(chamaID string, updates interface{})
 		return fmt.Errorf("failed to update chama: %w", err)
 	}
 
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &updatedBy,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "chama",
+		EntityID:   chamaID,
+		Details:    map[string]interface{}{"fields": changedFields},
+	})
+	if err != nil {
+		return err
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -0,0 +1,736 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"vaultke-backend/internal/models"
+)
+
+const (
+	// disputeFilingWindow is how long after a transfer it can still be disputed
+	disputeFilingWindow = 90 * 24 * time.Hour
+	// disputeResponseWindow is how long the counterparty has to respond before the
+	// dispute goes to an admin
+	disputeResponseWindow = 7 * 24 * time.Hour
+	// maxDisputeEvidence is the most files that can be attached to one dispute
+	maxDisputeEvidence = 10
+)
+
+// DisputeService handles disputes raised against transfers, the provisional hold on
+// the counterparty's funds and the refunds that settle them
+type DisputeService struct {
+	db *sql.DB
+}
+
+// NewDisputeService creates a new dispute service
+func NewDisputeService(db *sql.DB) *DisputeService {
+	return &DisputeService{db: db}
+}
+
+// OpenDispute disputes a transfer the user made to another member. Up to the disputed
+// amount is moved from the counterparty's wallet into a locked hold wallet, and the
+// counterparty is asked to respond.
+func (s *DisputeService) OpenDispute(userID string, req *models.OpenDisputeRequest) (*models.Dispute, error) {
+	var transactionType, status, currency, initiatedBy, fromOwner, toOwner, toType string
+	var transactionAmount float64
+	var createdAt time.Time
+	err := s.db.QueryRow(`
+		SELECT t.type, t.status, t.amount, COALESCE(t.currency, 'KES'), t.initiated_by, t.created_at,
+			COALESCE(fw.owner_id, ''), COALESCE(tw.owner_id, ''), COALESCE(tw.type, '')
+		FROM transactions t
+		LEFT JOIN wallets fw ON fw.id = t.from_wallet_id
+		LEFT JOIN wallets tw ON tw.id = t.to_wallet_id
+		WHERE t.id = ?
+	`, req.TransactionID).Scan(&transactionType, &status, &transactionAmount, &currency, &initiatedBy, &createdAt,
+		&fromOwner, &toOwner, &toType)
+	if err == sql.ErrNoRows || (err == nil && initiatedBy != userID && fromOwner != userID) {
+		return nil, fmt.Errorf("transaction not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	if transactionType != string(models.TransactionTypeTransfer) || currency != models.DefaultCurrency ||
+		toType != string(models.WalletTypePersonal) || toOwner == "" || toOwner == userID {
+		return nil, fmt.Errorf("only transfers you made to another member can be disputed; contact support about other transactions")
+	}
+	if status != string(models.TransactionStatusCompleted) {
+		return nil, fmt.Errorf("only completed transfers can be disputed")
+	}
+	if time.Since(createdAt) > disputeFilingWindow {
+		return nil, fmt.Errorf("transfers older than %d days cannot be disputed", int(disputeFilingWindow.Hours()/24))
+	}
+
+	amount := transactionAmount
+	if req.Amount != nil {
+		amount = roundCurrency(*req.Amount)
+	}
+	if amount > transactionAmount {
+		return nil, fmt.Errorf("the disputed amount cannot exceed the transfer of KES %.2f", transactionAmount)
+	}
+
+	var existing int
+	err = s.db.QueryRow("SELECT COUNT(*) FROM disputes WHERE transaction_id = ? AND status != ?",
+		req.TransactionID, models.DisputeStatusWithdrawn).Scan(&existing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing disputes: %w", err)
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("this transfer has already been disputed")
+	}
+
+	now := time.Now()
+	dispute := &models.Dispute{
+		ID:                uuid.New().String(),
+		TransactionID:     req.TransactionID,
+		RaisedBy:          userID,
+		CounterpartyID:    toOwner,
+		Reason:            models.DisputeReason(req.Reason),
+		Description:       strings.TrimSpace(req.Description),
+		TransactionAmount: transactionAmount,
+		Amount:            amount,
+		Status:            models.DisputeStatusOpen,
+		ResponseDeadline:  now.Add(disputeResponseWindow),
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.hold(tx, dispute); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO disputes (
+			id, transaction_id, raised_by, counterparty_id, reason, description, transaction_amount, amount,
+			held_amount, hold_wallet_id, status, response_deadline, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, dispute.ID, dispute.TransactionID, dispute.RaisedBy, dispute.CounterpartyID, dispute.Reason, dispute.Description,
+		dispute.TransactionAmount, dispute.Amount, dispute.HeldAmount, dispute.HoldWalletID, dispute.Status,
+		dispute.ResponseDeadline, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dispute: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	message := fmt.Sprintf("%s has disputed a transfer of KES %.2f they sent you.", s.userName(userID), dispute.Amount)
+	if dispute.HeldAmount > 0 {
+		message += fmt.Sprintf(" KES %.2f has been put on hold in your wallet until it is settled.", dispute.HeldAmount)
+	}
+	message += fmt.Sprintf(" Please respond by %s.", dispute.ResponseDeadline.Format("2 Jan 2006"))
+	s.notify(dispute.CounterpartyID, "Transfer Disputed", message, dispute)
+
+	return s.GetDispute(dispute.ID, userID, false)
+}
+
+// GetDispute returns a dispute with its evidence to either party or an admin
+func (s *DisputeService) GetDispute(disputeID, userID string, isAdmin bool) (*models.Dispute, error) {
+	dispute, err := getDispute(s.db, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && dispute.RaisedBy != userID && dispute.CounterpartyID != userID {
+		return nil, fmt.Errorf("dispute not found")
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, dispute_id, uploaded_by, file_name, file_path, file_id, file_size, file_type, note, uploaded_at
+		FROM dispute_evidence WHERE dispute_id = ? ORDER BY uploaded_at
+	`, disputeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dispute evidence: %w", err)
+	}
+	defer rows.Close()
+
+	dispute.Evidence = []*models.DisputeEvidence{}
+	for rows.Next() {
+		evidence, err := scanDisputeEvidence(rows)
+		if err != nil {
+			return nil, err
+		}
+		dispute.Evidence = append(dispute.Evidence, evidence)
+	}
+	return dispute, nil
+}
+
+// GetMyDisputes lists disputes the user raised or that were raised against them
+func (s *DisputeService) GetMyDisputes(userID string, limit, offset int) ([]*models.Dispute, error) {
+	return s.queryDisputes(`
+		WHERE d.raised_by = ? OR d.counterparty_id = ?
+		ORDER BY d.created_at DESC
+		LIMIT ? OFFSET ?
+	`, userID, userID, limit, offset)
+}
+
+// GetDisputeQueue lists disputes in a status, oldest first. It defaults to the
+// disputes waiting for an admin.
+func (s *DisputeService) GetDisputeQueue(status string, limit, offset int) ([]*models.Dispute, error) {
+	if status == "" {
+		status = string(models.DisputeStatusUnderReview)
+	}
+	switch models.DisputeStatus(status) {
+	case models.DisputeStatusOpen, models.DisputeStatusUnderReview, models.DisputeStatusResolved, models.DisputeStatusWithdrawn:
+	default:
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+	return s.queryDisputes(`
+		WHERE d.status = ?
+		ORDER BY d.created_at ASC
+		LIMIT ? OFFSET ?
+	`, status, limit, offset)
+}
+
+// AddEvidence attaches a file to an active dispute. Either party or an admin may add
+// evidence.
+func (s *DisputeService) AddEvidence(disputeID, userID string, isAdmin bool, evidence *models.DisputeEvidence) (*models.DisputeEvidence, error) {
+	dispute, err := s.GetDispute(disputeID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !dispute.IsActive() {
+		return nil, fmt.Errorf("evidence cannot be added to a %s dispute", dispute.Status)
+	}
+	if len(dispute.Evidence) >= maxDisputeEvidence {
+		return nil, fmt.Errorf("a dispute can have at most %d evidence files", maxDisputeEvidence)
+	}
+
+	evidence.ID = uuid.New().String()
+	evidence.DisputeID = disputeID
+	evidence.UploadedBy = userID
+	evidence.UploadedAt = time.Now()
+	_, err = s.db.Exec(`
+		INSERT INTO dispute_evidence (id, dispute_id, uploaded_by, file_name, file_path, file_id, file_size, file_type, note, uploaded_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, evidence.ID, evidence.DisputeID, evidence.UploadedBy, evidence.FileName, evidence.FilePath, evidence.FileID,
+		evidence.FileSize, evidence.FileType, evidence.Note, evidence.UploadedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save dispute evidence: %w", err)
+	}
+	return evidence, nil
+}
+
+// GetEvidence returns an evidence file's details to either party or an admin
+func (s *DisputeService) GetEvidence(evidenceID, userID string, isAdmin bool) (*models.DisputeEvidence, error) {
+	evidence, err := scanDisputeEvidence(s.db.QueryRow(`
+		SELECT id, dispute_id, uploaded_by, file_name, file_path, file_id, file_size, file_type, note, uploaded_at
+		FROM dispute_evidence WHERE id = ?
+	`, evidenceID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("evidence not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.GetDispute(evidence.DisputeID, userID, isAdmin); err != nil {
+		return nil, fmt.Errorf("evidence not found")
+	}
+	return evidence, nil
+}
+
+// Respond records the counterparty's answer. Accepting refunds the disputed amount
+// straight away; contesting sends the dispute to an admin.
+func (s *DisputeService) Respond(disputeID, userID string, req *models.RespondDisputeRequest) (*models.Dispute, error) {
+	dispute, err := s.GetDispute(disputeID, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.CounterpartyID != userID {
+		return nil, fmt.Errorf("only the recipient of the transfer can respond to this dispute")
+	}
+	if dispute.Status != models.DisputeStatusOpen {
+		return nil, fmt.Errorf("dispute is not awaiting your response")
+	}
+	message := strings.TrimSpace(req.Message)
+	if !req.Accept && message == "" {
+		return nil, fmt.Errorf("please explain why you are contesting the dispute")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	nextStatus := models.DisputeStatusUnderReview
+	if req.Accept {
+		nextStatus = models.DisputeStatusOpen
+	}
+	result, err := tx.Exec(`
+		UPDATE disputes SET counterparty_accepted = ?, counterparty_response = ?, responded_at = ?, status = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, req.Accept, optionalString(message), now, nextStatus, now, disputeID, models.DisputeStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record dispute response: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("dispute is not awaiting your response")
+	}
+
+	if req.Accept {
+		notes := "Accepted by the recipient"
+		if err := s.settle(tx, dispute, models.DisputeResolutionFullRefund, dispute.Amount, userID, notes); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if req.Accept {
+		s.notify(dispute.RaisedBy, "Dispute Resolved",
+			fmt.Sprintf("%s accepted your dispute. KES %.2f has been refunded to your wallet.", s.userName(userID), dispute.Amount), dispute)
+	} else {
+		s.notify(dispute.RaisedBy, "Dispute Contested",
+			fmt.Sprintf("%s contested your dispute. Our team will review it and let you both know the outcome.", s.userName(userID)), dispute)
+	}
+	return s.GetDispute(disputeID, userID, false)
+}
+
+// Resolve settles a dispute with a full, partial or no refund. Held funds go back to
+// the counterparty and any refund is then taken from their wallet.
+func (s *DisputeService) Resolve(disputeID, adminID string, req *models.ResolveDisputeRequest) (*models.Dispute, error) {
+	dispute, err := s.GetDispute(disputeID, adminID, true)
+	if err != nil {
+		return nil, err
+	}
+	if !dispute.IsActive() {
+		return nil, fmt.Errorf("dispute is already %s", dispute.Status)
+	}
+	if dispute.RaisedBy == adminID || dispute.CounterpartyID == adminID {
+		return nil, fmt.Errorf("you cannot resolve a dispute you are part of")
+	}
+	notes := strings.TrimSpace(req.Notes)
+	if notes == "" {
+		return nil, fmt.Errorf("resolution notes are required")
+	}
+
+	resolution := models.DisputeResolution(req.Resolution)
+	refund := 0.0
+	switch resolution {
+	case models.DisputeResolutionFullRefund:
+		refund = dispute.Amount
+	case models.DisputeResolutionPartialRefund:
+		if req.RefundAmount == nil {
+			return nil, fmt.Errorf("refund amount is required for a partial refund")
+		}
+		refund = roundCurrency(*req.RefundAmount)
+		if refund >= dispute.Amount {
+			return nil, fmt.Errorf("a partial refund must be less than the disputed KES %.2f", dispute.Amount)
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.settle(tx, dispute, resolution, refund, adminID, notes); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	outcome := "was closed without a refund"
+	if refund > 0 {
+		outcome = fmt.Sprintf("was resolved with a refund of KES %.2f", refund)
+	}
+	s.notify(dispute.RaisedBy, "Dispute Resolved", fmt.Sprintf("Your dispute of KES %.2f %s. %s", dispute.Amount, outcome, notes), dispute)
+	s.notify(dispute.CounterpartyID, "Dispute Resolved", fmt.Sprintf("The dispute against a transfer you received %s. %s", outcome, notes), dispute)
+	return s.GetDispute(disputeID, adminID, true)
+}
+
+// Withdraw lets the member who raised a dispute drop it, releasing any held funds
+func (s *DisputeService) Withdraw(disputeID, userID string) (*models.Dispute, error) {
+	dispute, err := s.GetDispute(disputeID, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.RaisedBy != userID {
+		return nil, fmt.Errorf("only the member who raised a dispute can withdraw it")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE disputes SET status = ?, resolved_at = ?, updated_at = ? WHERE id = ? AND status IN (?, ?)
+	`, models.DisputeStatusWithdrawn, now, now, disputeID, models.DisputeStatusOpen, models.DisputeStatusUnderReview)
+	if err != nil {
+		return nil, fmt.Errorf("failed to withdraw dispute: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("dispute is already %s", dispute.Status)
+	}
+	if err := s.releaseHold(tx, dispute); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.notify(dispute.CounterpartyID, "Dispute Withdrawn",
+		fmt.Sprintf("%s withdrew their dispute. Any funds held have been returned to your wallet.", s.userName(userID)), dispute)
+	return s.GetDispute(disputeID, userID, false)
+}
+
+// ProcessOverdueDisputes sends disputes the counterparty has not answered in time to
+// the admin queue. It is run by the notification scheduler.
+func (s *DisputeService) ProcessOverdueDisputes() {
+	now := time.Now()
+	rows, err := s.db.Query("SELECT id FROM disputes WHERE status = ? AND julianday(response_deadline) <= julianday(?)", models.DisputeStatusOpen, now)
+	if err != nil {
+		log.Printf("Failed to get overdue disputes: %v", err)
+		return
+	}
+	var disputeIDs []string
+	for rows.Next() {
+		var disputeID string
+		if err := rows.Scan(&disputeID); err == nil {
+			disputeIDs = append(disputeIDs, disputeID)
+		}
+	}
+	rows.Close()
+
+	for _, disputeID := range disputeIDs {
+		result, err := s.db.Exec("UPDATE disputes SET status = ?, updated_at = ? WHERE id = ? AND status = ?",
+			models.DisputeStatusUnderReview, now, disputeID, models.DisputeStatusOpen)
+		if err != nil {
+			log.Printf("Failed to escalate dispute %s: %v", disputeID, err)
+			continue
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			continue
+		}
+		dispute, err := getDispute(s.db, disputeID)
+		if err != nil {
+			log.Printf("Failed to get escalated dispute %s: %v", disputeID, err)
+			continue
+		}
+		s.notify(dispute.RaisedBy, "Dispute Under Review",
+			"The recipient did not respond to your dispute in time, so our team will now review it.", dispute)
+		s.notify(dispute.CounterpartyID, "Dispute Under Review",
+			"You did not respond to a dispute in time, so our team will now review it. You can still add evidence.", dispute)
+	}
+}
+
+// hold moves up to the disputed amount from the counterparty's personal wallet into a
+// locked hold wallet. Whatever the wallet holds is taken if it cannot cover it all.
+func (s *DisputeService) hold(tx *sql.Tx, dispute *models.Dispute) error {
+	var personalWalletID string
+	var balance float64
+	var locked bool
+	err := tx.QueryRow("SELECT id, balance, COALESCE(is_locked, FALSE) FROM wallets WHERE owner_id = ? AND type = 'personal'",
+		dispute.CounterpartyID).Scan(&personalWalletID, &balance, &locked)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get counterparty wallet: %w", err)
+	}
+	held := roundCurrency(math.Min(dispute.Amount, balance))
+	if locked || held <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	result, err := tx.Exec("UPDATE wallets SET balance = balance - ?, updated_at = ? WHERE id = ? AND balance >= ?",
+		held, now, personalWalletID, held)
+	if err != nil {
+		return fmt.Errorf("failed to hold disputed funds: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errInsufficientBalance
+	}
+
+	holdWalletID := uuid.New().String()
+	_, err = tx.Exec(`
+		INSERT INTO wallets (id, type, owner_id, balance, currency, is_active, is_locked, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 'KES', TRUE, TRUE, ?, ?)
+	`, holdWalletID, models.WalletTypeDisputeHold, dispute.CounterpartyID, held, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create dispute hold wallet: %w", err)
+	}
+
+	if _, err := s.recordTransaction(tx, personalWalletID, holdWalletID, models.TransactionTypeTransfer, held,
+		"Funds held for dispute", dispute.TransactionID, dispute.CounterpartyID, dispute.CounterpartyID, dispute); err != nil {
+		return err
+	}
+
+	dispute.HeldAmount = held
+	dispute.HoldWalletID = &holdWalletID
+	return nil
+}
+
+// releaseHold returns held funds to the counterparty's personal wallet and retires the
+// hold wallet
+func (s *DisputeService) releaseHold(tx *sql.Tx, dispute *models.Dispute) error {
+	if dispute.HoldWalletID == nil {
+		return nil
+	}
+	var balance float64
+	if err := tx.QueryRow("SELECT balance FROM wallets WHERE id = ?", *dispute.HoldWalletID).Scan(&balance); err != nil {
+		return fmt.Errorf("failed to get dispute hold wallet: %w", err)
+	}
+	_, err := tx.Exec("UPDATE wallets SET balance = 0, is_active = FALSE, updated_at = ? WHERE id = ?", time.Now(), *dispute.HoldWalletID)
+	if err != nil {
+		return fmt.Errorf("failed to close dispute hold wallet: %w", err)
+	}
+	if balance <= 0 {
+		return nil
+	}
+
+	personalWalletID, err := creditPersonalWallet(tx, dispute.CounterpartyID, balance)
+	if err != nil {
+		return err
+	}
+	_, err = s.recordTransaction(tx, *dispute.HoldWalletID, personalWalletID, models.TransactionTypeTransfer, balance,
+		"Dispute hold released", dispute.TransactionID, dispute.CounterpartyID, dispute.CounterpartyID, dispute)
+	return err
+}
+
+// settle closes an active dispute inside tx: held funds are released, then any refund
+// is taken from the counterparty's wallet and posted as a refund of the original transfer
+func (s *DisputeService) settle(tx *sql.Tx, dispute *models.Dispute, resolution models.DisputeResolution, refund float64, actorID, notes string) error {
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE disputes SET status = ?, resolution = ?, refund_amount = ?, resolved_by = ?, resolved_at = ?,
+			resolution_notes = ?, updated_at = ?
+		WHERE id = ? AND status IN (?, ?)
+	`, models.DisputeStatusResolved, resolution, refund, actorID, now, notes, now, dispute.ID,
+		models.DisputeStatusOpen, models.DisputeStatusUnderReview)
+	if err != nil {
+		return fmt.Errorf("failed to resolve dispute: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("dispute is no longer open")
+	}
+
+	if err := s.releaseHold(tx, dispute); err != nil {
+		return err
+	}
+
+	var refundTransactionID string
+	if refund > 0 {
+		fromWalletID, err := debitPersonalWallet(tx, dispute.CounterpartyID, refund)
+		if errors.Is(err, errInsufficientBalance) {
+			return fmt.Errorf("the recipient's wallet cannot cover a refund of KES %.2f", refund)
+		}
+		if err != nil {
+			return err
+		}
+		toWalletID, err := creditPersonalWallet(tx, dispute.RaisedBy, refund)
+		if err != nil {
+			return err
+		}
+		refundTransactionID, err = s.recordTransaction(tx, fromWalletID, toWalletID, models.TransactionTypeRefund, refund,
+			"Refund of disputed transfer", dispute.TransactionID, actorID, dispute.RaisedBy, dispute)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE disputes SET refund_transaction_id = ? WHERE id = ?", refundTransactionID, dispute.ID); err != nil {
+			return fmt.Errorf("failed to link refund to dispute: %w", err)
+		}
+	}
+
+	return NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ActorID:    &actorID,
+		Action:     models.AuditActionApproval,
+		EntityType: "dispute",
+		EntityID:   dispute.ID,
+		Details: map[string]interface{}{
+			"resolution":          resolution,
+			"transactionId":       dispute.TransactionID,
+			"disputedAmount":      dispute.Amount,
+			"refundAmount":        refund,
+			"refundTransactionId": refundTransactionID,
+			"notes":               notes,
+		},
+	})
+}
+
+// recordTransaction inserts a completed transaction referencing the disputed transfer
+// and appends it to the audit log
+func (s *DisputeService) recordTransaction(tx *sql.Tx, fromWalletID, toWalletID string, transactionType models.TransactionType, amount float64, description, reference, initiatedBy, recipientID string, dispute *models.Dispute) (string, error) {
+	transactionID := uuid.New().String()
+	now := time.Now()
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"disputeId":             dispute.ID,
+		"originalTransactionId": dispute.TransactionID,
+	})
+	_, err := tx.Exec(`
+		INSERT INTO transactions (
+			id, from_wallet_id, to_wallet_id, type, status, amount, currency, description,
+			reference, payment_method, metadata, initiated_by, recipient_id, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, 'KES', ?, ?, ?, ?, ?, ?, ?, ?)
+	`, transactionID, fromWalletID, toWalletID, transactionType, models.TransactionStatusCompleted, amount,
+		description, reference, models.PaymentMethodWalletTransfer, string(metadata), initiatedBy, recipientID, now, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to record transaction: %w", err)
+	}
+
+	if err := NewAuditService(s.db).RecordTransaction(tx, "", transactionID); err != nil {
+		return "", err
+	}
+	return transactionID, nil
+}
+
+func (s *DisputeService) queryDisputes(where string, args ...interface{}) ([]*models.Dispute, error) {
+	rows, err := s.db.Query(`
+		SELECT `+disputeColumns+`
+		FROM disputes d
+		LEFT JOIN users r ON r.id = d.raised_by
+		LEFT JOIN users c ON c.id = d.counterparty_id
+	`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disputes: %w", err)
+	}
+	defer rows.Close()
+
+	disputes := []*models.Dispute{}
+	for rows.Next() {
+		dispute, err := scanDispute(rows)
+		if err != nil {
+			return nil, err
+		}
+		disputes = append(disputes, dispute)
+	}
+	return disputes, nil
+}
+
+func (s *DisputeService) userName(userID string) string {
+	var name string
+	if err := s.db.QueryRow("SELECT first_name || ' ' || last_name FROM users WHERE id = ?", userID).Scan(&name); err != nil {
+		return "A member"
+	}
+	return name
+}
+
+func (s *DisputeService) notify(userID, title, message string, dispute *models.Dispute) {
+	err := NewNotificationService(s.db, nil).CreateInAppNotification(userID, "transaction", "dispute", title, message, map[string]interface{}{
+		"disputeId":     dispute.ID,
+		"transactionId": dispute.TransactionID,
+	})
+	if err != nil {
+		log.Printf("Failed to send dispute notification to %s: %v", userID, err)
+	}
+}
+
+const disputeColumns = `d.id, d.transaction_id, d.raised_by, COALESCE(r.first_name || ' ' || r.last_name, ''),
+	d.counterparty_id, COALESCE(c.first_name || ' ' || c.last_name, ''), d.reason, d.description,
+	d.transaction_amount, d.amount, d.held_amount, d.hold_wallet_id, d.status, d.response_deadline,
+	d.counterparty_accepted, d.counterparty_response, d.responded_at, d.resolution, d.refund_amount,
+	d.refund_transaction_id, d.resolved_by, d.resolved_at, d.resolution_notes, d.created_at, d.updated_at`
+
+func getDispute(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, disputeID string) (*models.Dispute, error) {
+	dispute, err := scanDispute(q.QueryRow(`
+		SELECT `+disputeColumns+`
+		FROM disputes d
+		LEFT JOIN users r ON r.id = d.raised_by
+		LEFT JOIN users c ON c.id = d.counterparty_id
+		WHERE d.id = ?
+	`, disputeID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("dispute not found")
+	}
+	return dispute, err
+}
+
+func scanDispute(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.Dispute, error) {
+	dispute := &models.Dispute{}
+	var holdWalletID, response, resolution, refundTransactionID, resolvedBy, notes sql.NullString
+	var accepted sql.NullBool
+	var respondedAt, resolvedAt sql.NullTime
+	err := row.Scan(&dispute.ID, &dispute.TransactionID, &dispute.RaisedBy, &dispute.RaisedByName,
+		&dispute.CounterpartyID, &dispute.CounterpartyName, &dispute.Reason, &dispute.Description,
+		&dispute.TransactionAmount, &dispute.Amount, &dispute.HeldAmount, &holdWalletID, &dispute.Status,
+		&dispute.ResponseDeadline, &accepted, &response, &respondedAt, &resolution, &dispute.RefundAmount,
+		&refundTransactionID, &resolvedBy, &resolvedAt, &notes, &dispute.CreatedAt, &dispute.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan dispute: %w", err)
+	}
+	if holdWalletID.Valid {
+		dispute.HoldWalletID = &holdWalletID.String
+	}
+	if accepted.Valid {
+		dispute.CounterpartyAccepted = &accepted.Bool
+	}
+	if response.Valid {
+		dispute.CounterpartyResponse = &response.String
+	}
+	if respondedAt.Valid {
+		dispute.RespondedAt = &respondedAt.Time
+	}
+	if resolution.Valid {
+		value := models.DisputeResolution(resolution.String)
+		dispute.Resolution = &value
+	}
+	if refundTransactionID.Valid {
+		dispute.RefundTransactionID = &refundTransactionID.String
+	}
+	if resolvedBy.Valid {
+		dispute.ResolvedBy = &resolvedBy.String
+	}
+	if resolvedAt.Valid {
+		dispute.ResolvedAt = &resolvedAt.Time
+	}
+	if notes.Valid {
+		dispute.ResolutionNotes = &notes.String
+	}
+	return dispute, nil
+}
+
+func scanDisputeEvidence(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.DisputeEvidence, error) {
+	evidence := &models.DisputeEvidence{}
+	var fileID, note sql.NullString
+	err := row.Scan(&evidence.ID, &evidence.DisputeID, &evidence.UploadedBy, &evidence.FileName, &evidence.FilePath,
+		&fileID, &evidence.FileSize, &evidence.FileType, &note, &evidence.UploadedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan dispute evidence: %w", err)
+	}
+	if fileID.Valid {
+		evidence.FileID = &fileID.String
+	}
+	if note.Valid {
+		evidence.Note = &note.String
+	}
+	return evidence, nil
+}
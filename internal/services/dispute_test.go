@@ -0,0 +1,209 @@
+package services_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type DisputeTestSuite struct {
+	suite.Suite
+	testDB      *helpers.TestDatabase
+	db          *sql.DB
+	service     *services.DisputeService
+	wallets     *services.WalletService
+	adminID     string
+	senderID    string
+	recipientID string
+}
+
+func (suite *DisputeTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewDisputeService(suite.db)
+	suite.wallets = services.NewWalletService(suite.db)
+	suite.adminID = suite.testDB.AddTestUser(suite.T(), "Admin")
+	suite.senderID = suite.testDB.AddTestUser(suite.T(), "Sender")
+	suite.recipientID = suite.testDB.AddTestUser(suite.T(), "Recipient")
+	suite.fund(suite.senderID, 10000)
+	suite.fund(suite.recipientID, 0)
+}
+
+func (suite *DisputeTestSuite) fund(ownerID string, balance float64) {
+	_, err := suite.db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'personal', ?, ?)", "wallet-"+ownerID, ownerID, balance)
+	suite.Require().NoError(err)
+}
+
+func (suite *DisputeTestSuite) balance(ownerID string) float64 {
+	var balance float64
+	err := suite.db.QueryRow("SELECT balance FROM wallets WHERE id = ?", "wallet-"+ownerID).Scan(&balance)
+	suite.Require().NoError(err)
+	return balance
+}
+
+func (suite *DisputeTestSuite) send(amount float64) string {
+	fromWalletID := "wallet-" + suite.senderID
+	toWalletID := "wallet-" + suite.recipientID
+	transaction, err := suite.wallets.CreateTransaction(&models.TransactionCreation{
+		FromWalletID:  &fromWalletID,
+		ToWalletID:    &toWalletID,
+		Type:          models.TransactionTypeTransfer,
+		Amount:        amount,
+		PaymentMethod: models.PaymentMethodWalletTransfer,
+		Metadata:      map[string]interface{}{},
+	}, suite.senderID)
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.wallets.ProcessTransaction(transaction.ID))
+	return transaction.ID
+}
+
+func (suite *DisputeTestSuite) notifications(userID, title string) int {
+	var count int
+	err := suite.db.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = ? AND title = ?", userID, title).Scan(&count)
+	suite.Require().NoError(err)
+	return count
+}
+
+func (suite *DisputeTestSuite) TestAcceptedDisputeRefundsMisSentTransfer() {
+	transactionID := suite.send(3000)
+	sentBalance := suite.balance(suite.senderID)
+
+	_, err := suite.service.OpenDispute(suite.recipientID, &models.OpenDisputeRequest{
+		TransactionID: transactionID, Reason: "wrong_recipient", Description: "Not mine",
+	})
+	suite.Require().Error(err, "only the payer can dispute a transfer")
+
+	dispute, err := suite.service.OpenDispute(suite.senderID, &models.OpenDisputeRequest{
+		TransactionID: transactionID, Reason: "wrong_recipient", Description: "Sent to the wrong number",
+	})
+	suite.Require().NoError(err)
+	suite.Equal(models.DisputeStatusOpen, dispute.Status)
+	suite.Equal(3000.0, dispute.Amount)
+	suite.Equal(3000.0, dispute.HeldAmount)
+	suite.Equal("Recipient User", dispute.CounterpartyName)
+	suite.Equal(0.0, suite.balance(suite.recipientID), "the disputed amount is held")
+	suite.Equal(1, suite.notifications(suite.recipientID, "Transfer Disputed"))
+
+	_, err = suite.service.OpenDispute(suite.senderID, &models.OpenDisputeRequest{
+		TransactionID: transactionID, Reason: "duplicate", Description: "Again",
+	})
+	suite.Require().Error(err, "a transfer is disputed once")
+
+	_, err = suite.service.Respond(dispute.ID, suite.senderID, &models.RespondDisputeRequest{Accept: true})
+	suite.Require().Error(err, "only the recipient responds")
+
+	resolved, err := suite.service.Respond(dispute.ID, suite.recipientID, &models.RespondDisputeRequest{Accept: true})
+	suite.Require().NoError(err)
+	suite.Equal(models.DisputeStatusResolved, resolved.Status)
+	suite.Require().NotNil(resolved.Resolution)
+	suite.Equal(models.DisputeResolutionFullRefund, *resolved.Resolution)
+	suite.Require().NotNil(resolved.RefundTransactionID)
+	suite.Equal(sentBalance+3000, suite.balance(suite.senderID), "the transfer fee is not refunded")
+	suite.Equal(0.0, suite.balance(suite.recipientID))
+	suite.Equal(1, suite.notifications(suite.senderID, "Dispute Resolved"))
+
+	var transactionType, reference string
+	err = suite.db.QueryRow("SELECT type, reference FROM transactions WHERE id = ?", *resolved.RefundTransactionID).Scan(&transactionType, &reference)
+	suite.Require().NoError(err)
+	suite.Equal(string(models.TransactionTypeRefund), transactionType)
+	suite.Equal(transactionID, reference, "the refund is linked to the original transfer")
+
+	var holdBalance float64
+	var active bool
+	err = suite.db.QueryRow("SELECT balance, is_active FROM wallets WHERE id = ?", *resolved.HoldWalletID).Scan(&holdBalance, &active)
+	suite.Require().NoError(err)
+	suite.Equal(0.0, holdBalance)
+	suite.False(active)
+}
+
+func (suite *DisputeTestSuite) TestContestedDisputeResolvedWithPartialRefund() {
+	transactionID := suite.send(5000)
+	sentBalance := suite.balance(suite.senderID)
+	// The recipient has already spent part of the money
+	_, err := suite.db.Exec("UPDATE wallets SET balance = 2000 WHERE id = ?", "wallet-"+suite.recipientID)
+	suite.Require().NoError(err)
+
+	dispute, err := suite.service.OpenDispute(suite.senderID, &models.OpenDisputeRequest{
+		TransactionID: transactionID, Reason: "wrong_amount", Description: "Meant to send 1000",
+	})
+	suite.Require().NoError(err)
+	suite.Equal(2000.0, dispute.HeldAmount, "only what the wallet holds can be held")
+
+	_, err = suite.service.Respond(dispute.ID, suite.recipientID, &models.RespondDisputeRequest{Accept: false})
+	suite.Require().Error(err, "contesting needs a reason")
+	contested, err := suite.service.Respond(dispute.ID, suite.recipientID, &models.RespondDisputeRequest{Message: "It was payment for goods"})
+	suite.Require().NoError(err)
+	suite.Equal(models.DisputeStatusUnderReview, contested.Status)
+	suite.Equal(1, suite.notifications(suite.senderID, "Dispute Contested"))
+
+	queue, err := suite.service.GetDisputeQueue("", 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(queue, 1)
+
+	_, err = suite.service.Resolve(dispute.ID, suite.adminID, &models.ResolveDisputeRequest{
+		Resolution: "partial_refund", RefundAmount: floatPtr(5000), Notes: "Split",
+	})
+	suite.Require().Error(err, "a partial refund is less than the disputed amount")
+	_, err = suite.service.Resolve(dispute.ID, suite.senderID, &models.ResolveDisputeRequest{Resolution: "full_refund", Notes: "Mine"})
+	suite.Require().Error(err, "parties cannot resolve their own dispute")
+
+	resolved, err := suite.service.Resolve(dispute.ID, suite.adminID, &models.ResolveDisputeRequest{
+		Resolution: "partial_refund", RefundAmount: floatPtr(1500), Notes: "Goods partly delivered",
+	})
+	suite.Require().NoError(err)
+	suite.Equal(1500.0, resolved.RefundAmount)
+	suite.Equal(sentBalance+1500, suite.balance(suite.senderID))
+	suite.Equal(500.0, suite.balance(suite.recipientID))
+	suite.Equal(1, suite.notifications(suite.recipientID, "Dispute Resolved"))
+
+	var audited int
+	err = suite.db.QueryRow("SELECT COUNT(*) FROM audit_log WHERE entity_type = 'dispute' AND entity_id = ?", dispute.ID).Scan(&audited)
+	suite.Require().NoError(err)
+	suite.Equal(1, audited)
+
+	_, err = suite.service.Resolve(dispute.ID, suite.adminID, &models.ResolveDisputeRequest{Resolution: "no_refund", Notes: "Again"})
+	suite.Require().Error(err, "a dispute is resolved once")
+}
+
+func (suite *DisputeTestSuite) TestUnansweredDisputeEscalatesAndWithdrawReleasesHold() {
+	transactionID := suite.send(4000)
+	dispute, err := suite.service.OpenDispute(suite.senderID, &models.OpenDisputeRequest{
+		TransactionID: transactionID, Reason: "unauthorized", Description: "I did not send this", Amount: floatPtr(1000),
+	})
+	suite.Require().NoError(err)
+	suite.Equal(3000.0, suite.balance(suite.recipientID))
+
+	_, err = suite.db.Exec("UPDATE disputes SET response_deadline = ? WHERE id = ?", time.Now().Add(-time.Minute), dispute.ID)
+	suite.Require().NoError(err)
+	suite.service.ProcessOverdueDisputes()
+
+	escalated, err := suite.service.GetDispute(dispute.ID, suite.senderID, false)
+	suite.Require().NoError(err)
+	suite.Equal(models.DisputeStatusUnderReview, escalated.Status)
+	suite.Equal(1, suite.notifications(suite.recipientID, "Dispute Under Review"))
+
+	_, err = suite.service.GetDispute(dispute.ID, suite.adminID, false)
+	suite.Require().Error(err, "outsiders cannot see a dispute")
+
+	_, err = suite.service.Withdraw(dispute.ID, suite.recipientID)
+	suite.Require().Error(err, "only the raiser withdraws")
+	withdrawn, err := suite.service.Withdraw(dispute.ID, suite.senderID)
+	suite.Require().NoError(err)
+	suite.Equal(models.DisputeStatusWithdrawn, withdrawn.Status)
+	suite.Equal(4000.0, suite.balance(suite.recipientID), "held funds go back")
+	suite.Equal(1, suite.notifications(suite.recipientID, "Dispute Withdrawn"))
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func TestDisputeSuite(t *testing.T) {
+	suite.Run(t, new(DisputeTestSuite))
+}
@@ -0,0 +1,218 @@
+package services_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type DividendEntitlementTestSuite struct {
+	suite.Suite
+	testDB  *helpers.TestDatabase
+	db      *sql.DB
+	service *services.DividendsService
+	shares  *services.SharesService
+	chairID string
+	aliceID string
+	bobID   string
+	carolID string
+	chamaID string
+	now     time.Time
+}
+
+func (suite *DividendEntitlementTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewDividendsService(suite.db)
+	suite.shares = services.NewSharesService(suite.db)
+	suite.now = time.Now()
+
+	suite.chairID = suite.testDB.AddTestUser(suite.T(), "Chair")
+	suite.aliceID = suite.testDB.AddTestUser(suite.T(), "Alice")
+	suite.bobID = suite.testDB.AddTestUser(suite.T(), "Bob")
+	suite.carolID = suite.testDB.AddTestUser(suite.T(), "Carol")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.aliceID, "member")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.bobID, "member")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.carolID, "member")
+}
+
+func (suite *DividendEntitlementTestSuite) buy(memberID string, count int, at time.Time) {
+	_, err := suite.shares.CreateShares(suite.chamaID, &models.CreateShareRequest{
+		MemberID:     memberID,
+		Name:         "Ordinary",
+		ShareType:    models.ShareTypeOrdinary,
+		SharesCount:  count,
+		ShareValue:   100,
+		PurchaseDate: at,
+	})
+	suite.Require().NoError(err)
+}
+
+func (suite *DividendEntitlementTestSuite) declare(req *models.CreateDividendDeclarationRequest) *models.DividendDeclaration {
+	req.TotalDividendAmount = 1
+	declaration, err := suite.service.DeclareDividend(suite.chamaID, suite.chairID, req)
+	suite.Require().NoError(err)
+	return declaration
+}
+
+func (suite *DividendEntitlementTestSuite) entitlements(preview *models.DividendPreview) map[string]models.DividendEntitlement {
+	byMember := make(map[string]models.DividendEntitlement)
+	for _, entitlement := range preview.Entitlements {
+		byMember[entitlement.MemberID] = entitlement
+	}
+	return byMember
+}
+
+func (suite *DividendEntitlementTestSuite) TestSnapshotPaysOnHoldingsAtRecordDateLessTax() {
+	suite.buy(suite.aliceID, 100, suite.now.AddDate(0, -10, 0))
+	suite.buy(suite.bobID, 100, suite.now.AddDate(0, 0, -1))
+
+	recordDate := suite.now.AddDate(0, 0, -30)
+	declaration := suite.declare(&models.CreateDividendDeclarationRequest{
+		DividendPerShare:   10,
+		RecordDate:         &recordDate,
+		WithholdingTaxRate: 5,
+	})
+	suite.Equal(models.DividendMethodSnapshot, declaration.ComputationMethod)
+
+	_, err := suite.service.PreviewDividend(suite.chamaID, declaration.ID, suite.aliceID)
+	suite.Error(err, "ordinary members cannot preview")
+
+	preview, err := suite.service.PreviewDividend(suite.chamaID, declaration.ID, suite.chairID)
+	suite.Require().NoError(err)
+	suite.False(preview.Provisional)
+	suite.Require().Len(preview.Entitlements, 1, "shares bought after the record date earn nothing")
+	alice := preview.Entitlements[0]
+	suite.Equal(suite.aliceID, alice.MemberID)
+	suite.Equal(100, alice.SharesAtRecordDate)
+	suite.Equal(1000.0, alice.GrossAmount)
+	suite.Equal(50.0, alice.WithholdingTax)
+	suite.Equal(950.0, alice.NetAmount)
+
+	_, err = suite.service.ApproveDividend(declaration.ID, suite.chairID)
+	suite.Require().NoError(err)
+	var payments int
+	var gross, tax, net float64
+	suite.Require().NoError(suite.db.QueryRow(`
+		SELECT COUNT(*), SUM(gross_amount), SUM(withholding_tax), SUM(dividend_amount)
+		FROM dividend_payments WHERE dividend_declaration_id = ?
+	`, declaration.ID).Scan(&payments, &gross, &tax, &net))
+	suite.Equal(1, payments)
+	suite.Equal(1000.0, gross)
+	suite.Equal(50.0, tax)
+	suite.Equal(950.0, net)
+
+	_, err = suite.service.ApproveDividend(declaration.ID, suite.chairID)
+	suite.Error(err, "a declaration is approved once")
+
+	report, err := suite.service.GetWithholdingTaxReport(suite.chamaID, suite.chairID, recordDate.AddDate(0, 0, -1), recordDate.AddDate(0, 0, 1))
+	suite.Require().NoError(err)
+	suite.Require().Len(report.Rows, 1)
+	suite.Equal("Alice User", report.Rows[0].MemberName)
+	suite.Equal(5.0, report.Rows[0].TaxRate)
+	suite.Equal(50.0, report.TotalWithholdingTax)
+	suite.Equal(950.0, report.TotalNet)
+
+	report, err = suite.service.GetWithholdingTaxReport(suite.chamaID, suite.chairID, recordDate.AddDate(0, 0, 1), suite.now)
+	suite.Require().NoError(err)
+	suite.Empty(report.Rows, "the report is filtered by record date")
+}
+
+func (suite *DividendEntitlementTestSuite) TestTimeWeightedAveragesHoldingOverThePeriod() {
+	start := suite.now.AddDate(0, 0, -400)
+	end := start.AddDate(0, 0, 300)
+
+	suite.buy(suite.aliceID, 100, start.AddDate(0, 0, -10))
+	suite.buy(suite.bobID, 100, start.AddDate(0, 0, 150))
+
+	// Carol held 40 shares for the first quarter of the period, then redeemed them
+	_, err := suite.db.Exec(`
+		INSERT INTO shares (id, chama_id, member_id, name, share_type, shares_owned, share_value, total_value, purchase_date, status)
+		VALUES (?, ?, ?, 'Ordinary', 'ordinary', 0, 100, 0, ?, 'redeemed')
+	`, uuid.New().String(), suite.chamaID, suite.carolID, start.AddDate(0, 0, -5))
+	suite.Require().NoError(err)
+	for _, movement := range []struct {
+		from, to interface{}
+		kind     models.ShareTransactionType
+		at       time.Time
+	}{
+		{nil, suite.carolID, models.ShareTransactionPurchase, start.AddDate(0, 0, -5)},
+		{suite.carolID, nil, models.ShareTransactionRedemption, start.AddDate(0, 0, 75)},
+	} {
+		_, err = suite.db.Exec(`
+			INSERT INTO share_transactions (id, chama_id, from_member_id, to_member_id, transaction_type, shares_count, share_value, total_amount, transaction_date, status)
+			VALUES (?, ?, ?, ?, ?, 40, 100, 4000, ?, 'completed')
+		`, uuid.New().String(), suite.chamaID, movement.from, movement.to, movement.kind, movement.at)
+		suite.Require().NoError(err)
+	}
+
+	// Shares bought after the period count for nothing
+	suite.buy(suite.carolID, 500, end.AddDate(0, 0, 10))
+
+	declaration := suite.declare(&models.CreateDividendDeclarationRequest{
+		DividendPerShare:  10,
+		RecordDate:        &end,
+		ComputationMethod: string(models.DividendMethodTimeWeighted),
+		PeriodStart:       &start,
+	})
+
+	preview, err := suite.service.PreviewDividend(suite.chamaID, declaration.ID, suite.chairID)
+	suite.Require().NoError(err)
+	byMember := suite.entitlements(preview)
+	suite.Require().Len(byMember, 3)
+	suite.Equal(100.0, byMember[suite.aliceID].EligibleShares)
+	suite.Equal(1000.0, byMember[suite.aliceID].GrossAmount)
+	suite.Equal(50.0, byMember[suite.bobID].EligibleShares, "held for half the period")
+	suite.Equal(100, byMember[suite.bobID].SharesAtRecordDate)
+	suite.Equal(500.0, byMember[suite.bobID].NetAmount)
+	suite.Equal(10.0, byMember[suite.carolID].EligibleShares, "held for a quarter of the period")
+	suite.Equal(0, byMember[suite.carolID].SharesAtRecordDate)
+	suite.Equal(1600.0, preview.TotalGross)
+	suite.Equal(0.0, preview.TotalWithholdingTax)
+}
+
+func (suite *DividendEntitlementTestSuite) TestFutureRecordDateIsProvisionalAndSplitsRewind() {
+	suite.buy(suite.aliceID, 10, suite.now.AddDate(0, -6, 0))
+
+	market := services.NewShareMarketService(suite.db)
+	price := 100.0
+	_, err := market.SetSharePrice(suite.chamaID, suite.chairID, &models.SetSharePriceRequest{Source: models.SharePriceSourceManual, Price: &price})
+	suite.Require().NoError(err)
+	_, err = market.SplitShares(suite.chamaID, suite.chairID, &models.SplitSharesRequest{RatioFrom: 1, RatioTo: 2})
+	suite.Require().NoError(err)
+
+	beforeSplit := suite.now.AddDate(0, -1, 0)
+	declaration := suite.declare(&models.CreateDividendDeclarationRequest{DividendPerShare: 10, RecordDate: &beforeSplit})
+	preview, err := suite.service.PreviewDividend(suite.chamaID, declaration.ID, suite.chairID)
+	suite.Require().NoError(err)
+	suite.Require().Len(preview.Entitlements, 1)
+	suite.Equal(10, preview.Entitlements[0].SharesAtRecordDate, "shares issued by a later split are not counted")
+
+	nextMonth := suite.now.AddDate(0, 1, 0)
+	future := suite.declare(&models.CreateDividendDeclarationRequest{DividendPerShare: 10, RecordDate: &nextMonth})
+	preview, err = suite.service.PreviewDividend(suite.chamaID, future.ID, suite.chairID)
+	suite.Require().NoError(err)
+	suite.True(preview.Provisional)
+	suite.Equal(20, preview.Entitlements[0].SharesAtRecordDate)
+	_, err = suite.service.ApproveDividend(future.ID, suite.chairID)
+	suite.Error(err, "cannot approve before the record date")
+
+	_, err = suite.service.DeclareDividend(suite.chamaID, suite.chairID, &models.CreateDividendDeclarationRequest{
+		DividendPerShare:    10,
+		TotalDividendAmount: 1,
+		ComputationMethod:   string(models.DividendMethodTimeWeighted),
+	})
+	suite.Error(err, "time-weighted dividends need a period")
+}
+
+func TestDividendEntitlementSuite(t *testing.T) {
+	suite.Run(t, new(DividendEntitlementTestSuite))
+}
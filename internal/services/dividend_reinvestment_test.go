@@ -0,0 +1,175 @@
+package services_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type DividendReinvestmentTestSuite struct {
+	suite.Suite
+	testDB   *helpers.TestDatabase
+	db       *sql.DB
+	service  *services.DividendsService
+	chairID  string
+	aliceID  string
+	bobID    string
+	carolID  string
+	outsider string
+	chamaID  string
+}
+
+func (suite *DividendReinvestmentTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewDividendsService(suite.db)
+
+	suite.chairID = suite.testDB.AddTestUser(suite.T(), "Chair")
+	suite.aliceID = suite.testDB.AddTestUser(suite.T(), "Alice")
+	suite.bobID = suite.testDB.AddTestUser(suite.T(), "Bob")
+	suite.carolID = suite.testDB.AddTestUser(suite.T(), "Carol")
+	suite.outsider = suite.testDB.AddTestUser(suite.T(), "Outsider")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.chairID)
+
+	shares := services.NewSharesService(suite.db)
+	for memberID, count := range map[string]int{suite.aliceID: 100, suite.bobID: 100, suite.carolID: 30} {
+		suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, memberID, "member")
+		_, err := shares.CreateShares(suite.chamaID, &models.CreateShareRequest{
+			MemberID:     memberID,
+			Name:         "Ordinary",
+			ShareType:    models.ShareTypeOrdinary,
+			SharesCount:  count,
+			ShareValue:   80,
+			PurchaseDate: time.Now().AddDate(-1, 0, 0),
+		})
+		suite.Require().NoError(err)
+	}
+}
+
+func (suite *DividendReinvestmentTestSuite) seedOffering(available int, price float64) string {
+	id := uuid.New().String()
+	_, err := suite.db.Exec(`
+		INSERT INTO share_offerings (
+			id, chama_id, name, share_type, total_shares, price_per_share, total_value,
+			created_by, created_by_id, timestamp, status, transaction_id, security_hash
+		) VALUES (?, ?, 'Ordinary 2026', 'ordinary', ?, ?, ?, 'Chair User', ?, ?, 'active', ?, 'hash')
+	`, id, suite.chamaID, available, price, float64(available)*price, suite.chairID, time.Now(), uuid.New().String())
+	suite.Require().NoError(err)
+	return id
+}
+
+// payDividend declares, approves and processes a 10-per-share dividend with 5% withholding tax
+func (suite *DividendReinvestmentTestSuite) payDividend(offeringID *string) string {
+	recordDate := time.Now().Add(-time.Hour)
+	declaration, err := suite.service.DeclareDividend(suite.chamaID, suite.chairID, &models.CreateDividendDeclarationRequest{
+		DividendPerShare:    10,
+		TotalDividendAmount: 2300,
+		RecordDate:          &recordDate,
+		WithholdingTaxRate:  5,
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.ApproveDividend(declaration.ID, suite.chairID)
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.service.ProcessDividendPayments(declaration.ID, &models.ProcessDividendPaymentsRequest{
+		PaymentMethod:          "mobile_money",
+		ReinvestmentOfferingID: offeringID,
+	}))
+	return declaration.ID
+}
+
+func (suite *DividendReinvestmentTestSuite) payment(declarationID, memberID string) (shares int, reinvested, cash float64) {
+	suite.Require().NoError(suite.db.QueryRow(`
+		SELECT reinvested_shares, reinvested_amount, cash_amount FROM dividend_payments
+		WHERE dividend_declaration_id = ? AND member_id = ? AND payment_status = 'paid'
+	`, declarationID, memberID).Scan(&shares, &reinvested, &cash))
+	return shares, reinvested, cash
+}
+
+func (suite *DividendReinvestmentTestSuite) TestElectionsAreAppliedWhenPaymentsAreProcessed() {
+	_, err := suite.service.SetReinvestmentElection(suite.chamaID, suite.aliceID, &models.SetDividendReinvestmentRequest{Election: "reinvest"})
+	suite.Require().NoError(err)
+	_, err = suite.service.SetReinvestmentElection(suite.chamaID, suite.bobID, &models.SetDividendReinvestmentRequest{Election: "split"})
+	suite.Error(err, "a split needs a percentage")
+	election, err := suite.service.SetReinvestmentElection(suite.chamaID, suite.bobID, &models.SetDividendReinvestmentRequest{Election: "split", ReinvestPercent: 50})
+	suite.Require().NoError(err)
+	suite.Equal(50.0, election.ReinvestPercent)
+	_, err = suite.service.SetReinvestmentElection(suite.chamaID, suite.outsider, &models.SetDividendReinvestmentRequest{Election: "reinvest"})
+	suite.Error(err, "only members have elections")
+
+	carol, err := suite.service.GetReinvestmentElection(suite.chamaID, suite.carolID)
+	suite.Require().NoError(err)
+	suite.Equal(models.DividendElectionCash, carol.Election, "members are paid in cash by default")
+
+	offeringID := suite.seedOffering(1000, 90)
+	declarationID := suite.payDividend(nil)
+
+	// Alice's 950 net buys 10 shares at 90 with 50 left over in cash
+	shares, reinvested, cash := suite.payment(declarationID, suite.aliceID)
+	suite.Equal(10, shares)
+	suite.Equal(900.0, reinvested)
+	suite.Equal(50.0, cash)
+
+	// Bob reinvests half of 950: 5 shares for 450, the rest in cash
+	shares, reinvested, cash = suite.payment(declarationID, suite.bobID)
+	suite.Equal(5, shares)
+	suite.Equal(450.0, reinvested)
+	suite.Equal(500.0, cash)
+
+	shares, reinvested, cash = suite.payment(declarationID, suite.carolID)
+	suite.Equal(0, shares)
+	suite.Equal(0.0, reinvested)
+	suite.Equal(285.0, cash)
+
+	var available, aliceShares, purchases int
+	suite.Require().NoError(suite.db.QueryRow("SELECT total_shares FROM share_offerings WHERE id = ?", offeringID).Scan(&available))
+	suite.Equal(985, available)
+	suite.Require().NoError(suite.db.QueryRow("SELECT SUM(shares_owned) FROM shares WHERE member_id = ? AND status = 'active'", suite.aliceID).Scan(&aliceShares))
+	suite.Equal(110, aliceShares)
+	suite.Require().NoError(suite.db.QueryRow("SELECT COUNT(*) FROM share_transactions WHERE to_member_id = ? AND transaction_type = 'purchase'", suite.aliceID).Scan(&purchases))
+	suite.Equal(2, purchases, "reinvested shares are in the share register")
+
+	statement, err := suite.service.GetReinvestmentStatement(suite.chamaID, suite.aliceID, time.Now().Year())
+	suite.Require().NoError(err)
+	suite.Equal(models.DividendElectionReinvest, statement.Election.Election)
+	suite.Require().Len(statement.Entries, 1)
+	suite.Equal(90.0, statement.Entries[0].PricePerShare)
+	suite.Equal(950.0, statement.TotalDividends)
+	suite.Equal(900.0, statement.TotalReinvested)
+	suite.Equal(50.0, statement.TotalCash)
+	suite.Equal(10, statement.TotalSharesAcquired)
+
+	statement, err = suite.service.GetReinvestmentStatement(suite.chamaID, suite.aliceID, time.Now().Year()-1)
+	suite.Require().NoError(err)
+	suite.Empty(statement.Entries)
+}
+
+func (suite *DividendReinvestmentTestSuite) TestReinvestmentIsLimitedByTheOffering() {
+	_, err := suite.service.SetReinvestmentElection(suite.chamaID, suite.aliceID, &models.SetDividendReinvestmentRequest{Election: "reinvest"})
+	suite.Require().NoError(err)
+	_, err = suite.service.SetReinvestmentElection(suite.chamaID, suite.bobID, &models.SetDividendReinvestmentRequest{Election: "reinvest"})
+	suite.Require().NoError(err)
+
+	// Without an open offering everyone is paid in cash
+	declarationID := suite.payDividend(nil)
+	shares, _, cash := suite.payment(declarationID, suite.aliceID)
+	suite.Equal(0, shares)
+	suite.Equal(950.0, cash)
+
+	offeringID := suite.seedOffering(4, 90)
+	declarationID = suite.payDividend(&offeringID)
+	aliceShares, _, aliceCash := suite.payment(declarationID, suite.aliceID)
+	bobShares, _, bobCash := suite.payment(declarationID, suite.bobID)
+	suite.Equal(4, aliceShares+bobShares, "no more shares are sold than the offering has")
+	suite.Equal(1900.0-4*90, aliceCash+bobCash)
+}
+
+func TestDividendReinvestmentSuite(t *testing.T) {
+	suite.Run(t, new(DividendReinvestmentTestSuite))
+}
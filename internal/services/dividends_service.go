@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"math"
+	"sort"
 	"time"
 
 	"vaultke-backend/internal/models"
@@ -42,6 +44,29 @@ func (s *DividendsService) DeclareDividend(chamaID, declaredBy string, req *mode
 	declarationID := uuid.New().String()
 	now := time.Now()
 
+	method := models.DividendMethodSnapshot
+	if req.ComputationMethod != "" {
+		method = models.DividendComputationMethod(req.ComputationMethod)
+	}
+	recordDate := now
+	if req.RecordDate != nil {
+		recordDate = *req.RecordDate
+	}
+	var periodStart, periodEnd *time.Time
+	if method == models.DividendMethodTimeWeighted {
+		if req.PeriodStart == nil {
+			return nil, fmt.Errorf("time-weighted dividends need the start of the financial period")
+		}
+		end := recordDate
+		if req.PeriodEnd != nil {
+			end = *req.PeriodEnd
+		}
+		if !req.PeriodStart.Before(end) {
+			return nil, fmt.Errorf("the financial period must start before it ends")
+		}
+		periodStart, periodEnd = req.PeriodStart, &end
+	}
+
 	declaration := &models.DividendDeclaration{
 		ID:                  declarationID,
 		ChamaID:             chamaID,
@@ -53,6 +78,11 @@ func (s *DividendsService) DeclareDividend(chamaID, declaredBy string, req *mode
 		DeclaredBy:          declaredBy,
 		Description:         req.Description,
 		DividendType:        req.DividendType,
+		RecordDate:          &recordDate,
+		ComputationMethod:   method,
+		PeriodStart:         periodStart,
+		PeriodEnd:           periodEnd,
+		WithholdingTaxRate:  req.WithholdingTaxRate,
 		CreatedAt:           now,
 		UpdatedAt:           now,
 	}
@@ -67,8 +97,9 @@ func (s *DividendsService) DeclareDividend(chamaID, declaredBy string, req *mode
 	query := `
 		INSERT INTO dividend_declarations (
 			id, chama_id, declaration_date, dividend_per_share, total_amount,
-			payment_date, status, declared_by, description, dividend_type, created_at, updated_at
-		) VALUES (?, ?, CURRENT_TIMESTAMP, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			payment_date, status, declared_by, description, dividend_type, record_date,
+			computation_method, period_start, period_end, withholding_tax_rate, created_at, updated_at
+		) VALUES (?, ?, CURRENT_TIMESTAMP, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := s.db.Exec(
@@ -82,6 +113,11 @@ func (s *DividendsService) DeclareDividend(chamaID, declaredBy string, req *mode
 		declaration.DeclaredBy,
 		declaration.Description,
 		dividendType,
+		declaration.RecordDate,
+		declaration.ComputationMethod,
+		declaration.PeriodStart,
+		declaration.PeriodEnd,
+		declaration.WithholdingTaxRate,
 		declaration.CreatedAt,
 		declaration.UpdatedAt,
 	)
@@ -90,11 +126,8 @@ func (s *DividendsService) DeclareDividend(chamaID, declaredBy string, req *mode
 		return nil, fmt.Errorf("failed to declare dividend: %w", err)
 	}
 
-	// Create individual dividend payments for eligible members
-	err = s.createDividendPayments(declarationID, chamaID)
-	if err != nil {
-		log.Printf("Warning: Failed to create dividend payments: %v", err)
-	}
+	// Individual payments are computed from the share register when the
+	// declaration is approved, once the record date has passed
 
 	log.Printf("Declared dividend %s for chama %s by user %s", declarationID, chamaID, declaredBy)
 	return declaration, nil
@@ -105,7 +138,8 @@ func (s *DividendsService) GetChamaDividendDeclarations(chamaID string, limit, o
 	query := `
 		SELECT dd.id, dd.chama_id, dd.declaration_date, dd.dividend_per_share,
 			   dd.total_amount, dd.payment_date, dd.status, dd.declared_by,
-			   dd.approved_by, dd.description, dd.created_at, dd.updated_at,
+			   dd.approved_by, dd.description, dd.record_date, dd.computation_method,
+			   dd.period_start, dd.period_end, dd.withholding_tax_rate, dd.created_at, dd.updated_at,
 			   u1.first_name, u1.last_name, u2.first_name, u2.last_name
 		FROM dividend_declarations dd
 		JOIN users u1 ON dd.declared_by = u1.id
@@ -138,6 +172,11 @@ func (s *DividendsService) GetChamaDividendDeclarations(chamaID string, limit, o
 			&declaration.DeclaredBy,
 			&declaration.ApprovedBy,
 			&declaration.Description,
+			&declaration.RecordDate,
+			&declaration.ComputationMethod,
+			&declaration.PeriodStart,
+			&declaration.PeriodEnd,
+			&declaration.WithholdingTaxRate,
 			&declaration.CreatedAt,
 			&declaration.UpdatedAt,
 			&declaredByFirstName,
@@ -172,7 +211,8 @@ func (s *DividendsService) GetChamaDividendDeclarations(chamaID string, limit, o
 	return declarations, nil
 }
 
-// ApproveDividend approves a dividend declaration
+// ApproveDividend approves a dividend declaration and fixes each member's payment
+// from the share register as it stood at the record date
 func (s *DividendsService) ApproveDividend(declarationID, approvedBy string) (*models.DividendDeclaration, error) {
 	// Get existing declaration
 	declaration, err := s.getDividendDeclarationByID(declarationID)
@@ -190,18 +230,79 @@ func (s *DividendsService) ApproveDividend(declarationID, approvedBy string) (*m
 		return nil, fmt.Errorf("user does not have permission to approve dividends")
 	}
 
-	// Update status
 	now := time.Now()
-	query := `
-		UPDATE dividend_declarations 
-		SET status = ?, approved_by = ?, updated_at = ?
-		WHERE id = ?
-	`
+	preview, err := s.buildDividendPreview(declaration, now)
+	if err != nil {
+		return nil, err
+	}
+	if preview.Provisional {
+		return nil, fmt.Errorf("dividend cannot be approved before its record date and the end of its financial period")
+	}
+	if len(preview.Entitlements) == 0 {
+		return nil, fmt.Errorf("no member is entitled to this dividend")
+	}
 
-	_, err = s.db.Exec(query, models.DividendStatusApproved, approvedBy, now, declarationID)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE dividend_declarations
+		SET status = ?, approved_by = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, models.DividendStatusApproved, approvedBy, now, declarationID, models.DividendStatusDeclared)
 	if err != nil {
 		return nil, fmt.Errorf("failed to approve dividend: %w", err)
 	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("dividend declaration has already been approved")
+	}
+
+	// Declarations made before record dates existed had payments created up front
+	if _, err := tx.Exec("DELETE FROM dividend_payments WHERE dividend_declaration_id = ?", declarationID); err != nil {
+		return nil, fmt.Errorf("failed to clear dividend payments: %w", err)
+	}
+
+	for _, entitlement := range preview.Entitlements {
+		_, err = tx.Exec(`
+			INSERT INTO dividend_payments (
+				id, dividend_declaration_id, member_id, shares_eligible, eligible_shares,
+				gross_amount, withholding_tax, dividend_amount, payment_status, created_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, uuid.New().String(), declarationID, entitlement.MemberID, entitlement.SharesAtRecordDate,
+			entitlement.EligibleShares, entitlement.GrossAmount, entitlement.WithholdingTax,
+			entitlement.NetAmount, models.DividendPaymentPending, now, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dividend payment: %w", err)
+		}
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    declaration.ChamaID,
+		ActorID:    &approvedBy,
+		Action:     models.AuditActionApproval,
+		EntityType: "dividend_declaration",
+		EntityID:   declarationID,
+		Amount:     &preview.TotalGross,
+		Details: map[string]interface{}{
+			"dividendPerShare":    declaration.DividendPerShare,
+			"computationMethod":   preview.ComputationMethod,
+			"recordDate":          preview.RecordDate,
+			"recipients":          len(preview.Entitlements),
+			"totalWithholdingTax": preview.TotalWithholdingTax,
+			"totalNet":            preview.TotalNet,
+			"status":              models.DividendStatusApproved,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
 	declaration.Status = models.DividendStatusApproved
 	declaration.ApprovedBy = &approvedBy
@@ -211,6 +312,95 @@ func (s *DividendsService) ApproveDividend(declarationID, approvedBy string) (*m
 	return declaration, nil
 }
 
+// PreviewDividend computes what each member would be paid under a declaration,
+// without recording anything
+func (s *DividendsService) PreviewDividend(chamaID, declarationID, userID string) (*models.DividendPreview, error) {
+	declaration, err := s.getDividendDeclarationByID(declarationID)
+	if err != nil {
+		return nil, err
+	}
+	if declaration.ChamaID != chamaID {
+		return nil, fmt.Errorf("dividend declaration not found")
+	}
+	if !s.canDeclareDividends(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can preview dividends")
+	}
+
+	return s.buildDividendPreview(declaration, time.Now())
+}
+
+// GetWithholdingTaxReport lists the tax withheld from approved and paid dividends
+// whose record date falls in [from, to)
+func (s *DividendsService) GetWithholdingTaxReport(chamaID, userID string, from, to time.Time) (*models.DividendTaxReport, error) {
+	if !s.canDeclareDividends(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can view the dividend tax report")
+	}
+
+	rows, err := s.db.Query(`
+		SELECT dd.id, dd.record_date, dd.declaration_date, dd.withholding_tax_rate,
+			   dp.member_id, u.first_name, u.last_name, dp.gross_amount, dp.withholding_tax,
+			   dp.dividend_amount, dp.payment_status, dp.payment_date
+		FROM dividend_payments dp
+		JOIN dividend_declarations dd ON dp.dividend_declaration_id = dd.id
+		JOIN users u ON dp.member_id = u.id
+		WHERE dd.chama_id = ? AND dd.status IN (?, ?)
+		ORDER BY dd.declaration_date, u.first_name, u.last_name
+	`, chamaID, models.DividendStatusApproved, models.DividendStatusPaid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dividend payments: %w", err)
+	}
+	defer rows.Close()
+
+	report := &models.DividendTaxReport{
+		ChamaID: chamaID,
+		From:    from,
+		To:      to,
+		Rows:    []models.DividendTaxReportRow{},
+	}
+	for rows.Next() {
+		var row models.DividendTaxReportRow
+		var firstName, lastName string
+		var recordDate sql.NullTime
+		err := rows.Scan(
+			&row.DeclarationID,
+			&recordDate,
+			&row.RecordDate,
+			&row.TaxRate,
+			&row.MemberID,
+			&firstName,
+			&lastName,
+			&row.GrossAmount,
+			&row.WithholdingTax,
+			&row.NetAmount,
+			&row.PaymentStatus,
+			&row.PaymentDate,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dividend payment: %w", err)
+		}
+		if recordDate.Valid {
+			row.RecordDate = recordDate.Time
+		}
+		if row.RecordDate.Before(from) || !row.RecordDate.Before(to) {
+			continue
+		}
+
+		row.MemberName = firstName + " " + lastName
+		report.Rows = append(report.Rows, row)
+		report.TotalGross += row.GrossAmount
+		report.TotalWithholdingTax += row.WithholdingTax
+		report.TotalNet += row.NetAmount
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dividend payments: %w", err)
+	}
+
+	report.TotalGross = roundCurrency(report.TotalGross)
+	report.TotalWithholdingTax = roundCurrency(report.TotalWithholdingTax)
+	report.TotalNet = roundCurrency(report.TotalNet)
+	return report, nil
+}
+
 // ProcessDividendPayments processes dividend payments for a declaration
 func (s *DividendsService) ProcessDividendPayments(declarationID string, req *models.ProcessDividendPaymentsRequest) error {
 	// Get declaration
@@ -234,6 +424,12 @@ func (s *DividendsService) ProcessDividendPayments(declarationID string, req *mo
 		return fmt.Errorf("no pending payments found")
 	}
 
+	// Members who elected to reinvest buy shares from an active offering
+	offering, err := s.reinvestmentOffering(declaration.ChamaID, req.ReinvestmentOfferingID)
+	if err != nil {
+		return err
+	}
+
 	// Process each payment
 	successCount := 0
 	paymentDate := time.Now()
@@ -242,7 +438,7 @@ func (s *DividendsService) ProcessDividendPayments(declarationID string, req *mo
 	}
 
 	for _, payment := range payments {
-		err := s.processSingleDividendPayment(&payment, req.PaymentMethod, paymentDate)
+		err := s.processSingleDividendPayment(declaration.ChamaID, &payment, req.PaymentMethod, paymentDate, offering)
 		if err != nil {
 			log.Printf("Failed to process payment %s: %v", payment.ID, err)
 		} else {
@@ -266,7 +462,9 @@ func (s *DividendsService) ProcessDividendPayments(declarationID string, req *mo
 func (s *DividendsService) GetMemberDividendHistory(chamaID, memberID string, limit, offset int) ([]models.DividendPaymentWithMemberInfo, error) {
 	query := `
 		SELECT dp.id, dp.dividend_declaration_id, dp.member_id, dp.shares_eligible,
-			   dp.dividend_amount, dp.payment_status, dp.payment_date, dp.payment_method,
+			   dp.eligible_shares, dp.gross_amount, dp.withholding_tax,
+			   dp.dividend_amount, dp.reinvested_shares, dp.reinvested_amount,
+			   dp.reinvestment_share_id, dp.cash_amount, dp.payment_status, dp.payment_date, dp.payment_method,
 			   dp.transaction_reference, dp.created_at, dp.updated_at,
 			   u.first_name, u.last_name, u.email, u.phone
 		FROM dividend_payments dp
@@ -293,7 +491,14 @@ func (s *DividendsService) GetMemberDividendHistory(chamaID, memberID string, li
 			&payment.DividendDeclarationID,
 			&payment.MemberID,
 			&payment.SharesEligible,
+			&payment.EligibleShares,
+			&payment.GrossAmount,
+			&payment.WithholdingTax,
 			&payment.DividendAmount,
+			&payment.ReinvestedShares,
+			&payment.ReinvestedAmount,
+			&payment.ReinvestmentShareID,
+			&payment.CashAmount,
 			&payment.PaymentStatus,
 			&payment.PaymentDate,
 			&payment.PaymentMethod,
@@ -316,11 +521,128 @@ func (s *DividendsService) GetMemberDividendHistory(chamaID, memberID string, li
 	return payments, nil
 }
 
+// GetReinvestmentElection returns how a member has asked for their dividends in a
+// chama to be paid
+func (s *DividendsService) GetReinvestmentElection(chamaID, memberID string) (*models.DividendReinvestmentElection, error) {
+	if !s.isActiveMember(memberID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	return s.getReinvestmentElection(chamaID, memberID)
+}
+
+// SetReinvestmentElection records a member's standing election, applied to every
+// dividend paid from then on
+func (s *DividendsService) SetReinvestmentElection(chamaID, memberID string, req *models.SetDividendReinvestmentRequest) (*models.DividendReinvestmentElection, error) {
+	if !s.isActiveMember(memberID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	election := models.DividendElection(req.Election)
+	var percent float64
+	switch election {
+	case models.DividendElectionReinvest:
+		percent = 100
+	case models.DividendElectionSplit:
+		if req.ReinvestPercent <= 0 || req.ReinvestPercent >= 100 {
+			return nil, fmt.Errorf("a split election needs a reinvestment percentage between 0 and 100")
+		}
+		percent = req.ReinvestPercent
+	}
+
+	now := time.Now()
+	_, err := s.db.Exec(`
+		INSERT INTO dividend_reinvestment_elections (id, chama_id, member_id, election, reinvest_percent, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(chama_id, member_id) DO UPDATE SET
+			election = excluded.election,
+			reinvest_percent = excluded.reinvest_percent,
+			updated_at = excluded.updated_at
+	`, uuid.New().String(), chamaID, memberID, election, percent, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save reinvestment election: %w", err)
+	}
+
+	return &models.DividendReinvestmentElection{
+		ChamaID:         chamaID,
+		MemberID:        memberID,
+		Election:        election,
+		ReinvestPercent: percent,
+		UpdatedAt:       &now,
+	}, nil
+}
+
+// GetReinvestmentStatement lists a member's dividends paid in a calendar year and
+// how much of each was reinvested
+func (s *DividendsService) GetReinvestmentStatement(chamaID, memberID string, year int) (*models.DividendReinvestmentStatement, error) {
+	election, err := s.GetReinvestmentElection(chamaID, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT dp.id, dp.dividend_declaration_id, dp.payment_date, dp.dividend_amount,
+			   dp.reinvested_shares, dp.reinvestment_price, dp.reinvested_amount, dp.cash_amount
+		FROM dividend_payments dp
+		JOIN dividend_declarations dd ON dp.dividend_declaration_id = dd.id
+		WHERE dd.chama_id = ? AND dp.member_id = ? AND dp.payment_status = ?
+		ORDER BY dp.payment_date
+	`, chamaID, memberID, models.DividendPaymentPaid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dividend payments: %w", err)
+	}
+	defer rows.Close()
+
+	statement := &models.DividendReinvestmentStatement{
+		ChamaID:    chamaID,
+		MemberID:   memberID,
+		MemberName: s.memberName(memberID),
+		Year:       year,
+		Election:   *election,
+		Entries:    []models.DividendReinvestmentEntry{},
+	}
+	for rows.Next() {
+		var entry models.DividendReinvestmentEntry
+		var paymentDate sql.NullTime
+		err := rows.Scan(
+			&entry.PaymentID,
+			&entry.DeclarationID,
+			&paymentDate,
+			&entry.NetDividend,
+			&entry.SharesAcquired,
+			&entry.PricePerShare,
+			&entry.ReinvestedAmount,
+			&entry.CashAmount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dividend payment: %w", err)
+		}
+		if !paymentDate.Valid || paymentDate.Time.Year() != year {
+			continue
+		}
+
+		entry.PaymentDate = paymentDate.Time
+		statement.Entries = append(statement.Entries, entry)
+		statement.TotalDividends += entry.NetDividend
+		statement.TotalReinvested += entry.ReinvestedAmount
+		statement.TotalCash += entry.CashAmount
+		statement.TotalSharesAcquired += entry.SharesAcquired
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dividend payments: %w", err)
+	}
+
+	statement.TotalDividends = roundCurrency(statement.TotalDividends)
+	statement.TotalReinvested = roundCurrency(statement.TotalReinvested)
+	statement.TotalCash = roundCurrency(statement.TotalCash)
+	return statement, nil
+}
+
 // Helper methods
 func (s *DividendsService) getDividendDeclarationByID(declarationID string) (*models.DividendDeclaration, error) {
 	query := `
 		SELECT id, chama_id, declaration_date, dividend_per_share, total_amount,
-			   payment_date, status, declared_by, approved_by, description, created_at, updated_at
+			   payment_date, status, declared_by, approved_by, description, record_date,
+			   computation_method, period_start, period_end, withholding_tax_rate, created_at, updated_at
 		FROM dividend_declarations WHERE id = ?
 	`
 
@@ -336,6 +658,11 @@ func (s *DividendsService) getDividendDeclarationByID(declarationID string) (*mo
 		&declaration.DeclaredBy,
 		&declaration.ApprovedBy,
 		&declaration.Description,
+		&declaration.RecordDate,
+		&declaration.ComputationMethod,
+		&declaration.PeriodStart,
+		&declaration.PeriodEnd,
+		&declaration.WithholdingTaxRate,
 		&declaration.CreatedAt,
 		&declaration.UpdatedAt,
 	)
@@ -350,71 +677,234 @@ func (s *DividendsService) getDividendDeclarationByID(declarationID string) (*mo
 	return &declaration, nil
 }
 
-func (s *DividendsService) createDividendPayments(declarationID, chamaID string) error {
-	// Get all active shareholders in the chama
-	query := `
-		SELECT s.member_id, SUM(s.shares_owned) as total_shares
-		FROM shares s
-		WHERE s.chama_id = ? AND s.status = 'active'
-		GROUP BY s.member_id
-	`
+// buildDividendPreview works out each member's entitlement under a declaration.
+// Dates after asOf are brought back to asOf and the preview marked provisional.
+func (s *DividendsService) buildDividendPreview(declaration *models.DividendDeclaration, asOf time.Time) (*models.DividendPreview, error) {
+	preview := &models.DividendPreview{
+		DeclarationID:      declaration.ID,
+		ChamaID:            declaration.ChamaID,
+		ComputationMethod:  declaration.ComputationMethod,
+		RecordDate:         declaration.DeclarationDate,
+		PeriodStart:        declaration.PeriodStart,
+		PeriodEnd:          declaration.PeriodEnd,
+		DividendPerShare:   declaration.DividendPerShare,
+		WithholdingTaxRate: declaration.WithholdingTaxRate,
+		DeclaredAmount:     declaration.TotalDividendAmount,
+		Entitlements:       []models.DividendEntitlement{},
+	}
+	if declaration.RecordDate != nil {
+		preview.RecordDate = *declaration.RecordDate
+	}
+
+	recordDate := preview.RecordDate
+	if recordDate.After(asOf) {
+		recordDate = asOf
+		preview.Provisional = true
+	}
 
-	rows, err := s.db.Query(query, chamaID)
+	current, movements, err := s.shareRegister(declaration.ChamaID)
 	if err != nil {
-		return fmt.Errorf("failed to get shareholders: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
+	held := holdingsAt(current, movements, recordDate)
 
-	// Get dividend per share
-	declaration, err := s.getDividendDeclarationByID(declarationID)
+	eligible := make(map[string]float64, len(held))
+	if declaration.ComputationMethod == models.DividendMethodTimeWeighted {
+		if declaration.PeriodStart == nil || declaration.PeriodEnd == nil {
+			return nil, fmt.Errorf("time-weighted dividend has no financial period")
+		}
+		periodEnd := *declaration.PeriodEnd
+		if periodEnd.After(asOf) {
+			periodEnd = asOf
+			preview.Provisional = true
+		}
+		if !declaration.PeriodStart.Before(periodEnd) {
+			return nil, fmt.Errorf("the financial period has not started yet")
+		}
+		for memberID, average := range averageHoldings(current, movements, *declaration.PeriodStart, periodEnd) {
+			eligible[memberID] = math.Round(average*10000) / 10000
+		}
+	} else {
+		for memberID, shares := range held {
+			if shares > 0 {
+				eligible[memberID] = float64(shares)
+			}
+		}
+	}
+
+	for memberID, shares := range eligible {
+		gross := roundCurrency(shares * declaration.DividendPerShare)
+		if gross <= 0 {
+			continue
+		}
+		tax := roundCurrency(gross * declaration.WithholdingTaxRate / 100)
+
+		entitlement := models.DividendEntitlement{
+			MemberID:       memberID,
+			MemberName:     s.memberName(memberID),
+			EligibleShares: shares,
+			GrossAmount:    gross,
+			WithholdingTax: tax,
+			NetAmount:      roundCurrency(gross - tax),
+		}
+		if held[memberID] > 0 {
+			entitlement.SharesAtRecordDate = held[memberID]
+		}
+		preview.Entitlements = append(preview.Entitlements, entitlement)
+		preview.TotalEligibleShares += shares
+		preview.TotalGross += entitlement.GrossAmount
+		preview.TotalWithholdingTax += entitlement.WithholdingTax
+		preview.TotalNet += entitlement.NetAmount
+	}
+
+	sort.Slice(preview.Entitlements, func(i, j int) bool {
+		if preview.Entitlements[i].MemberName != preview.Entitlements[j].MemberName {
+			return preview.Entitlements[i].MemberName < preview.Entitlements[j].MemberName
+		}
+		return preview.Entitlements[i].MemberID < preview.Entitlements[j].MemberID
+	})
+	preview.TotalEligibleShares = math.Round(preview.TotalEligibleShares*10000) / 10000
+	preview.TotalGross = roundCurrency(preview.TotalGross)
+	preview.TotalWithholdingTax = roundCurrency(preview.TotalWithholdingTax)
+	preview.TotalNet = roundCurrency(preview.TotalNet)
+	return preview, nil
+}
+
+// shareMovement is one change to a member's holding, taken from share_transactions
+type shareMovement struct {
+	memberID string
+	delta    int
+	at       time.Time
+}
+
+// shareRegister returns each member's current holding of active shares and the
+// chama's completed share movements, latest first. Holdings at an earlier date are
+// found by undoing the movements made after it. Dividend certificates are claims
+// on a past dividend rather than shares, so they earn nothing themselves.
+func (s *DividendsService) shareRegister(chamaID string) (map[string]int, []shareMovement, error) {
+	rows, err := s.db.Query(`
+		SELECT member_id, SUM(shares_owned)
+		FROM shares
+		WHERE chama_id = ? AND status = 'active' AND share_type != 'dividend'
+		GROUP BY member_id
+	`, chamaID)
 	if err != nil {
-		return err
+		return nil, nil, fmt.Errorf("failed to get shareholders: %w", err)
 	}
+	defer rows.Close()
 
-	// Create payment records for each shareholder
+	current := make(map[string]int)
 	for rows.Next() {
 		var memberID string
-		var totalShares int
+		var shares int
+		if err := rows.Scan(&memberID, &shares); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan shareholder: %w", err)
+		}
+		current[memberID] = shares
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read shareholders: %w", err)
+	}
 
-		err := rows.Scan(&memberID, &totalShares)
-		if err != nil {
-			log.Printf("Error scanning shareholder: %v", err)
-			continue
+	movementRows, err := s.db.Query(`
+		SELECT from_member_id, to_member_id, shares_count, transaction_date
+		FROM share_transactions
+		WHERE chama_id = ? AND status = ?
+	`, chamaID, models.ShareTransactionCompleted)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get share transactions: %w", err)
+	}
+	defer movementRows.Close()
+
+	var movements []shareMovement
+	for movementRows.Next() {
+		var fromMemberID, toMemberID sql.NullString
+		var count int
+		var at time.Time
+		if err := movementRows.Scan(&fromMemberID, &toMemberID, &count, &at); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan share transaction: %w", err)
+		}
+		if fromMemberID.Valid && fromMemberID.String != "" {
+			movements = append(movements, shareMovement{memberID: fromMemberID.String, delta: -count, at: at})
+		}
+		if toMemberID.Valid && toMemberID.String != "" {
+			movements = append(movements, shareMovement{memberID: toMemberID.String, delta: count, at: at})
 		}
+	}
+	if err := movementRows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read share transactions: %w", err)
+	}
 
-		dividendAmount := declaration.CalculateDividendAmount(totalShares)
-		if dividendAmount <= 0 {
-			continue
+	sort.SliceStable(movements, func(i, j int) bool {
+		return movements[i].at.After(movements[j].at)
+	})
+	return current, movements, nil
+}
+
+// holdingsAt rewinds current holdings to how they stood at the given time.
+// Movements are expected latest first.
+func holdingsAt(current map[string]int, movements []shareMovement, at time.Time) map[string]int {
+	holdings := make(map[string]int, len(current))
+	for memberID, shares := range current {
+		holdings[memberID] = shares
+	}
+	for _, movement := range movements {
+		if !movement.at.After(at) {
+			break
 		}
+		holdings[movement.memberID] -= movement.delta
+	}
+	return holdings
+}
 
-		paymentID := uuid.New().String()
-		now := time.Now()
+// averageHoldings returns each member's holding averaged over time between start
+// and end. Movements are expected latest first.
+func averageHoldings(current map[string]int, movements []shareMovement, start, end time.Time) map[string]float64 {
+	holdings := holdingsAt(current, movements, end)
+	heldUntil := make(map[string]time.Time)
+	shareSeconds := make(map[string]float64)
 
-		insertQuery := `
-			INSERT INTO dividend_payments (
-				id, dividend_declaration_id, member_id, shares_eligible,
-				dividend_amount, payment_status, created_at, updated_at
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-		`
-
-		_, err = s.db.Exec(
-			insertQuery,
-			paymentID,
-			declarationID,
-			memberID,
-			totalShares,
-			dividendAmount,
-			models.DividendPaymentPending,
-			now,
-			now,
-		)
+	for _, movement := range movements {
+		if movement.at.After(end) {
+			continue
+		}
+		if !movement.at.After(start) {
+			break
+		}
+		until, ok := heldUntil[movement.memberID]
+		if !ok {
+			until = end
+		}
+		if held := holdings[movement.memberID]; held > 0 {
+			shareSeconds[movement.memberID] += float64(held) * until.Sub(movement.at).Seconds()
+		}
+		holdings[movement.memberID] -= movement.delta
+		heldUntil[movement.memberID] = movement.at
+	}
 
-		if err != nil {
-			log.Printf("Failed to create dividend payment for member %s: %v", memberID, err)
+	period := end.Sub(start).Seconds()
+	averages := make(map[string]float64, len(holdings))
+	for memberID, held := range holdings {
+		until, ok := heldUntil[memberID]
+		if !ok {
+			until = end
+		}
+		if held > 0 {
+			shareSeconds[memberID] += float64(held) * until.Sub(start).Seconds()
+		}
+		if shareSeconds[memberID] > 0 {
+			averages[memberID] = shareSeconds[memberID] / period
 		}
 	}
+	return averages
+}
 
-	return nil
+func (s *DividendsService) memberName(userID string) string {
+	var firstName, lastName string
+	if err := s.db.QueryRow("SELECT first_name, last_name FROM users WHERE id = ?", userID).Scan(&firstName, &lastName); err != nil {
+		return ""
+	}
+	return firstName + " " + lastName
 }
 
 type dividendPaymentStats struct {
@@ -427,10 +917,10 @@ type dividendPaymentStats struct {
 func (s *DividendsService) getDividendPaymentStats(declarationID string) (*dividendPaymentStats, error) {
 	query := `
 		SELECT
-			SUM(shares_eligible) as total_shares,
+			COALESCE(SUM(shares_eligible), 0) as total_shares,
 			COUNT(*) as total_recipients,
-			SUM(CASE WHEN payment_status = 'paid' THEN dividend_amount ELSE 0 END) as paid_amount,
-			SUM(CASE WHEN payment_status = 'pending' THEN dividend_amount ELSE 0 END) as pending_amount
+			COALESCE(SUM(CASE WHEN payment_status = 'paid' THEN dividend_amount ELSE 0 END), 0) as paid_amount,
+			COALESCE(SUM(CASE WHEN payment_status = 'pending' THEN dividend_amount ELSE 0 END), 0) as pending_amount
 		FROM dividend_payments
 		WHERE dividend_declaration_id = ?
 	`
@@ -452,8 +942,9 @@ func (s *DividendsService) getDividendPaymentStats(declarationID string) (*divid
 
 func (s *DividendsService) getPendingDividendPayments(declarationID string) ([]models.DividendPayment, error) {
 	query := `
-		SELECT id, dividend_declaration_id, member_id, shares_eligible, dividend_amount,
-			   payment_status, payment_date, payment_method, transaction_reference,
+		SELECT id, dividend_declaration_id, member_id, shares_eligible, eligible_shares,
+			   gross_amount, withholding_tax, dividend_amount, reinvested_shares, reinvested_amount,
+			   reinvestment_share_id, cash_amount, payment_status, payment_date, payment_method, transaction_reference,
 			   created_at, updated_at
 		FROM dividend_payments
 		WHERE dividend_declaration_id = ? AND payment_status = 'pending'
@@ -474,7 +965,14 @@ func (s *DividendsService) getPendingDividendPayments(declarationID string) ([]m
 			&payment.DividendDeclarationID,
 			&payment.MemberID,
 			&payment.SharesEligible,
+			&payment.EligibleShares,
+			&payment.GrossAmount,
+			&payment.WithholdingTax,
 			&payment.DividendAmount,
+			&payment.ReinvestedShares,
+			&payment.ReinvestedAmount,
+			&payment.ReinvestmentShareID,
+			&payment.CashAmount,
 			&payment.PaymentStatus,
 			&payment.PaymentDate,
 			&payment.PaymentMethod,
@@ -491,44 +989,183 @@ func (s *DividendsService) getPendingDividendPayments(declarationID string) ([]m
 	return payments, nil
 }
 
-func (s *DividendsService) processSingleDividendPayment(payment *models.DividendPayment, paymentMethod string, paymentDate time.Time) error {
-	// Generate transaction reference
-	transactionRef := fmt.Sprintf("DIV-%s-%d", payment.ID[:8], time.Now().Unix())
+// processSingleDividendPayment pays one member's dividend, reinvesting the share of
+// it their election asks for. Whole shares are bought from the offering at its
+// price and whatever is left over is paid in cash.
+func (s *DividendsService) processSingleDividendPayment(chamaID string, payment *models.DividendPayment, paymentMethod string, paymentDate time.Time, offering *models.ShareOffering) error {
+	election, err := s.getReinvestmentElection(chamaID, payment.MemberID)
+	if err != nil {
+		return err
+	}
 
-	// Update payment record
-	query := `
-		UPDATE dividend_payments
-		SET payment_status = ?, payment_date = ?, payment_method = ?,
-			transaction_reference = ?, updated_at = ?
-		WHERE id = ?
-	`
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	_, err := s.db.Exec(
-		query,
-		models.DividendPaymentPaid,
-		paymentDate,
-		paymentMethod,
-		transactionRef,
-		time.Now(),
-		payment.ID,
-	)
+	now := time.Now()
+	var sharesBought int
+	var price float64
+	var shareID *string
+	toReinvest := roundCurrency(payment.DividendAmount * election.ReinvestPercent / 100)
+	if toReinvest > 0 && offering != nil && offering.PricePerShare > 0 {
+		price = offering.PricePerShare
+		sharesBought = int(math.Floor(toReinvest/price + 1e-9))
+
+		var available int
+		err = tx.QueryRow("SELECT total_shares FROM share_offerings WHERE id = ? AND status = 'active'", offering.ID).Scan(&available)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to check share offering: %w", err)
+		}
+		if sharesBought > available {
+			sharesBought = available
+		}
+	}
+
+	if sharesBought > 0 {
+		result, err := tx.Exec(`
+			UPDATE share_offerings SET total_shares = total_shares - ?, updated_at = ?
+			WHERE id = ? AND status = 'active' AND total_shares >= ?
+		`, sharesBought, now, offering.ID, sharesBought)
+		if err != nil {
+			return fmt.Errorf("failed to update share offering: %w", err)
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return fmt.Errorf("share offering no longer has %d shares available", sharesBought)
+		}
+
+		sharesService := NewSharesService(s.db)
+		certificateNumber := sharesService.generateCertificateNumber()
+		id := uuid.New().String()
+		shareID = &id
+		_, err = tx.Exec(`
+			INSERT INTO shares (
+				id, chama_id, member_id, name, share_type, shares_owned, share_value,
+				total_value, purchase_date, certificate_number, status, created_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, id, chamaID, payment.MemberID, offering.Name, offering.ShareType, sharesBought, price,
+			float64(sharesBought)*price, now, certificateNumber, models.ShareStatusActive, now, now)
+		if err != nil {
+			return fmt.Errorf("failed to create reinvested shares: %w", err)
+		}
 
+		description := fmt.Sprintf("Dividend reinvestment from offering %s", offering.Name)
+		err = sharesService.createShareTransactionInTx(tx, chamaID, &models.CreateShareTransactionRequest{
+			ToMemberID:      &payment.MemberID,
+			TransactionType: models.ShareTransactionPurchase,
+			SharesCount:     sharesBought,
+			ShareValue:      price,
+			TransactionDate: now,
+			Description:     &description,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record share transaction: %w", err)
+		}
+
+		if err := syncShareCertificates(tx, "holding updated", id); err != nil {
+			return err
+		}
+	}
+
+	reinvested := roundCurrency(float64(sharesBought) * price)
+	cash := roundCurrency(payment.DividendAmount - reinvested)
+
+	// Generate transaction reference
+	transactionRef := fmt.Sprintf("DIV-%s-%d", payment.ID[:8], now.Unix())
+
+	result, err := tx.Exec(`
+		UPDATE dividend_payments
+		SET payment_status = ?, payment_date = ?, payment_method = ?, transaction_reference = ?,
+			reinvested_shares = ?, reinvested_amount = ?, reinvestment_price = ?,
+			reinvestment_share_id = ?, cash_amount = ?, updated_at = ?
+		WHERE id = ? AND payment_status = ?
+	`, models.DividendPaymentPaid, paymentDate, paymentMethod, transactionRef,
+		sharesBought, reinvested, price, shareID, cash, now,
+		payment.ID, models.DividendPaymentPending)
 	if err != nil {
 		return fmt.Errorf("failed to update payment: %w", err)
 	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("dividend payment has already been processed")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
-	// Here you would integrate with actual payment processing
-	// For now, we'll just mark as paid
-	log.Printf("Processed dividend payment %s for member %s: %f", payment.ID, payment.MemberID, payment.DividendAmount)
+	// The cash part is paid out through paymentMethod outside the platform
+	log.Printf("Processed dividend payment %s for member %s: %.2f reinvested in %d shares, %.2f in cash",
+		payment.ID, payment.MemberID, reinvested, sharesBought, cash)
 	return nil
 }
 
+// reinvestmentOffering returns the offering reinvested dividends buy from: the one
+// asked for, or the chama's latest active offering. It is nil when the chama has
+// none, in which case everyone is paid in cash.
+func (s *DividendsService) reinvestmentOffering(chamaID string, offeringID *string) (*models.ShareOffering, error) {
+	sharesService := NewSharesService(s.db)
+	if offeringID != nil && *offeringID != "" {
+		offering, err := sharesService.getShareOfferingByID(*offeringID)
+		if err != nil {
+			return nil, fmt.Errorf("share offering not found: %w", err)
+		}
+		if offering.ChamaID != chamaID || offering.Status != "active" {
+			return nil, fmt.Errorf("share offering is not open in this chama")
+		}
+		return offering, nil
+	}
+
+	var latestID string
+	err := s.db.QueryRow(`
+		SELECT id FROM share_offerings
+		WHERE chama_id = ? AND status = 'active' AND total_shares > 0
+		ORDER BY created_at DESC LIMIT 1
+	`, chamaID).Scan(&latestID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find an active share offering: %w", err)
+	}
+	return sharesService.getShareOfferingByID(latestID)
+}
+
 func (s *DividendsService) updateDividendDeclarationStatus(declarationID string, status models.DividendDeclarationStatus) error {
 	query := `UPDATE dividend_declarations SET status = ?, updated_at = ? WHERE id = ?`
 	_, err := s.db.Exec(query, status, time.Now(), declarationID)
 	return err
 }
 
+// getReinvestmentElection returns a member's election, defaulting to cash
+func (s *DividendsService) getReinvestmentElection(chamaID, memberID string) (*models.DividendReinvestmentElection, error) {
+	election := &models.DividendReinvestmentElection{
+		ChamaID:  chamaID,
+		MemberID: memberID,
+		Election: models.DividendElectionCash,
+	}
+	var updatedAt time.Time
+	err := s.db.QueryRow(`
+		SELECT election, reinvest_percent, updated_at
+		FROM dividend_reinvestment_elections
+		WHERE chama_id = ? AND member_id = ?
+	`, chamaID, memberID).Scan(&election.Election, &election.ReinvestPercent, &updatedAt)
+	if err == sql.ErrNoRows {
+		return election, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reinvestment election: %w", err)
+	}
+	election.UpdatedAt = &updatedAt
+	return election, nil
+}
+
+func (s *DividendsService) isActiveMember(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow("SELECT 1 FROM chama_members WHERE user_id = ? AND chama_id = ? AND is_active = TRUE", userID, chamaID).Scan(&exists)
+	return err == nil
+}
+
 func (s *DividendsService) canDeclareDividends(userID, chamaID string) bool {
 	// Check if user is a chama official (chairperson, secretary, or treasurer)
 	query := `
@@ -1,8 +1,14 @@
 package services
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 	"os"
 	"strings"
 	"vaultke-backend/internal/utils"
@@ -512,3 +518,81 @@ func (s *EmailService) SendEmailVerificationEmail(to, verificationCode, userName
 
 	return s.sendEmail(to, message)
 }
+
+// EmailAttachment is a file sent with an email
+type EmailAttachment struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// SendMemberStatementEmail sends a member their periodic chama statement as a PDF attachment
+func (s *EmailService) SendMemberStatementEmail(toEmail, memberName, chamaName, period string, statement *EmailAttachment) error {
+	// Validate configuration
+	if s.smtpHost == "" || s.smtpPort == "" || s.smtpUsername == "" || s.smtpPassword == "" {
+		fmt.Printf("🔧 DEVELOPMENT MODE: Simulating member statement email send\n")
+		fmt.Printf("📧 Would send %s statement for %s to: %s (%s, %d bytes)\n", chamaName, period, toEmail, statement.FileName, len(statement.Content))
+
+		// Return success in development mode when SMTP is not configured
+		return nil
+	}
+
+	if memberName == "" {
+		memberName = "VaultKe User"
+	}
+	content := fmt.Sprintf(`
+		<p style="font-size: 18px; color: #1e293b; margin-bottom: 24px;">Hello <span class="highlight">%s</span>,</p>
+
+		<p>Your statement from <strong>%s</strong> for <strong>%s</strong> is attached. It shows your contributions, fines, loans, repayments, dividends and share holdings for the period, with your opening and closing balances.</p>
+
+		<p>You can also download this and earlier statements at any time from the chama's statements page in the VaultKe app.</p>
+
+		<p style="margin-top: 30px;">Best regards,<br><span class="highlight">The VaultKe Team</span></p>
+	`, html.EscapeString(memberName), html.EscapeString(chamaName), html.EscapeString(period))
+	body := utils.GetEmailTemplate("Your Chama Statement", content, "", "")
+
+	subject := mime.QEncoding.Encode("UTF-8", fmt.Sprintf("VaultKe - %s statement for %s", chamaName, period))
+	message, err := multipartEmailMessage(toEmail, subject, body, statement)
+	if err != nil {
+		return err
+	}
+	return s.sendEmail(toEmail, message)
+}
+
+// multipartEmailMessage builds a MIME message with an HTML body and one attachment
+func multipartEmailMessage(toEmail, subject, htmlBody string, attachment *EmailAttachment) (string, error) {
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+
+	fmt.Fprintf(&buffer, "To: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=%s\r\n\r\n",
+		toEmail, subject, writer.Boundary())
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=UTF-8"},
+		"Content-Transfer-Encoding": {"8bit"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to build email body: %w", err)
+	}
+	part.Write([]byte(htmlBody))
+
+	part, err = writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {fmt.Sprintf("%s; name=%q", attachment.ContentType, attachment.FileName)},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", attachment.FileName)},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to build email attachment: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(attachment.Content)
+	for len(encoded) > 76 {
+		part.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	part.Write([]byte(encoded + "\r\n"))
+
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to build email message: %w", err)
+	}
+	return buffer.String(), nil
+}
@@ -0,0 +1,972 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"vaultke-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ExpenseService manages chama expense claims: submission by officials, approval
+// under the chama's rules, payment from the chama wallet and budget tracking
+type ExpenseService struct {
+	db *sql.DB
+}
+
+// NewExpenseService creates a new expense service
+func NewExpenseService(db *sql.DB) *ExpenseService {
+	return &ExpenseService{db: db}
+}
+
+// SubmitExpense records an expense claim. The approval rule in force when it is
+// submitted decides how many officials other than the claimant must approve it.
+func (s *ExpenseService) SubmitExpense(chamaID, userID string, req *models.SubmitExpenseRequest) (*models.Expense, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can submit expense claims")
+	}
+	if req.ExpenseDate.After(time.Now()) {
+		return nil, fmt.Errorf("expense date cannot be in the future")
+	}
+
+	rule, err := s.resolveRule(chamaID, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// Officials cannot approve their own claims, so a rule asking for more approvers
+	// than the chama has is capped at the officials available
+	var approvers int
+	err = s.db.QueryRow(`
+		SELECT COUNT(*) FROM chama_members
+		WHERE chama_id = ? AND user_id != ? AND is_active = TRUE
+		AND role IN ('chairperson', 'secretary', 'treasurer')
+	`, chamaID, userID).Scan(&approvers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count chama officials: %w", err)
+	}
+	if approvers == 0 {
+		return nil, fmt.Errorf("the chama has no other official to approve this claim")
+	}
+	required := rule.ApprovalsRequired
+	if required > approvers {
+		required = approvers
+	}
+
+	now := time.Now()
+	expense := &models.Expense{
+		ID:                uuid.New().String(),
+		ChamaID:           chamaID,
+		Category:          models.ExpenseCategory(req.Category),
+		Title:             req.Title,
+		Description:       req.Description,
+		Vendor:            req.Vendor,
+		Amount:            roundCurrency(req.Amount),
+		ExpenseDate:       req.ExpenseDate,
+		MeetingID:         req.MeetingID,
+		PaidByClaimant:    req.PaidByClaimant,
+		Status:            models.ExpenseStatusSubmitted,
+		ApprovalsRequired: required,
+		ReceiptRequired:   rule.ReceiptRequired,
+		SubmittedBy:       userID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	var budgetLineID string
+	err = s.db.QueryRow("SELECT id FROM chama_budget_lines WHERE chama_id = ? AND fiscal_year = ? AND category = ?",
+		chamaID, req.ExpenseDate.Year(), req.Category).Scan(&budgetLineID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get budget line: %w", err)
+	}
+	if err == nil {
+		expense.BudgetLineID = &budgetLineID
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO chama_expenses (
+			id, chama_id, category, budget_line_id, title, description, vendor, amount, expense_date,
+			meeting_id, paid_by_claimant, status, approvals_required, receipt_required, approval_count,
+			submitted_by, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0, ?, ?, ?)
+	`, expense.ID, expense.ChamaID, expense.Category, expense.BudgetLineID, expense.Title, expense.Description,
+		expense.Vendor, expense.Amount, expense.ExpenseDate, expense.MeetingID, expense.PaidByClaimant, expense.Status,
+		expense.ApprovalsRequired, expense.ReceiptRequired, expense.SubmittedBy, expense.CreatedAt, expense.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit expense: %w", err)
+	}
+
+	s.notifyOfficials(expense, "Expense Claim Submitted",
+		fmt.Sprintf("\"%s\" for KES %.2f is waiting for approval", expense.Title, expense.Amount))
+
+	log.Printf("Expense %s of %.2f submitted for chama %s by %s", expense.ID, expense.Amount, chamaID, userID)
+	return expense, nil
+}
+
+// AttachReceipt links an uploaded receipt to a claim. Only the claimant or an official
+// can attach one, and not once the claim has been paid or closed.
+func (s *ExpenseService) AttachReceipt(chamaID, expenseID, userID, fileID string) (*models.Expense, error) {
+	expense, err := s.getExpense(chamaID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if expense.SubmittedBy != userID && !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only the claimant or a chama official can attach a receipt")
+	}
+
+	result, err := s.db.Exec(`
+		UPDATE chama_expenses SET receipt_file_id = ?, updated_at = ?
+		WHERE id = ? AND status IN ('submitted', 'approved')
+	`, fileID, time.Now(), expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach receipt: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("a receipt can only be attached to an open claim")
+	}
+
+	return s.getExpense(chamaID, expenseID)
+}
+
+// GetExpenses lists a chama's expenses, newest first. Every member can see them.
+// An empty status returns all; a zero year returns every year.
+func (s *ExpenseService) GetExpenses(chamaID, userID, status string, year int) ([]models.Expense, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	query := expenseSelect + " WHERE e.chama_id = ?"
+	args := []interface{}{chamaID}
+	if status != "" {
+		query += " AND e.status = ?"
+		args = append(args, status)
+	}
+
+	expenses, err := s.queryExpenses(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dates are compared in Go: the driver's stored format does not sort reliably
+	filtered := []models.Expense{}
+	for _, expense := range expenses {
+		if year == 0 || expense.ExpenseDate.Year() == year {
+			filtered = append(filtered, expense)
+		}
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].ExpenseDate.After(filtered[j].ExpenseDate)
+	})
+	return filtered, nil
+}
+
+// GetExpense returns an expense with its approval trail
+func (s *ExpenseService) GetExpense(chamaID, expenseID, userID string) (*models.ExpenseDetail, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	expense, err := s.getExpense(chamaID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT a.id, a.expense_id, a.approver_id, COALESCE(u.first_name || ' ' || u.last_name, ''),
+			a.decision, a.comment, a.created_at
+		FROM chama_expense_approvals a
+		LEFT JOIN users u ON u.id = a.approver_id
+		WHERE a.expense_id = ?
+	`, expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expense approvals: %w", err)
+	}
+	defer rows.Close()
+
+	detail := &models.ExpenseDetail{Expense: *expense, Approvals: []models.ExpenseApproval{}}
+	for rows.Next() {
+		var approval models.ExpenseApproval
+		var comment sql.NullString
+		err := rows.Scan(&approval.ID, &approval.ExpenseID, &approval.ApproverID, &approval.ApproverName,
+			&approval.Decision, &comment, &approval.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan expense approval: %w", err)
+		}
+		if comment.Valid {
+			approval.Comment = &comment.String
+		}
+		detail.Approvals = append(detail.Approvals, approval)
+	}
+	sort.Slice(detail.Approvals, func(i, j int) bool {
+		return detail.Approvals[i].CreatedAt.Before(detail.Approvals[j].CreatedAt)
+	})
+	return detail, nil
+}
+
+// ApproveExpense records an official's approval. The claim is approved once it has
+// the approvals its rule requires.
+func (s *ExpenseService) ApproveExpense(chamaID, expenseID, userID string, comment *string) (*models.Expense, error) {
+	expense, err := s.reviewable(chamaID, expenseID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if expense.ReceiptRequired && expense.ReceiptFileID == nil {
+		return nil, fmt.Errorf("a receipt must be attached before this claim can be approved")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.insertDecision(tx, expenseID, userID, "approved", comment); err != nil {
+		return nil, err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE chama_expenses
+		SET approval_count = approval_count + 1,
+			status = CASE WHEN approval_count + 1 >= approvals_required THEN 'approved' ELSE status END,
+			updated_at = ?
+		WHERE id = ? AND status = 'submitted'
+	`, time.Now(), expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve expense: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("expense claim is no longer awaiting approval")
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionApproval,
+		EntityType: "expense",
+		EntityID:   expenseID,
+		Amount:     &expense.Amount,
+		Details: map[string]interface{}{
+			"decision": "approved",
+			"category": expense.Category,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.getExpense(chamaID, expenseID)
+}
+
+// RejectExpense rejects a claim. A single official's rejection closes it.
+func (s *ExpenseService) RejectExpense(chamaID, expenseID, userID string, reason *string) (*models.Expense, error) {
+	if reason == nil || strings.TrimSpace(*reason) == "" {
+		return nil, fmt.Errorf("a reason is required to reject an expense claim")
+	}
+	expense, err := s.reviewable(chamaID, expenseID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.insertDecision(tx, expenseID, userID, "rejected", reason); err != nil {
+		return nil, err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE chama_expenses SET status = 'rejected', rejected_by = ?, rejection_reason = ?, updated_at = ?
+		WHERE id = ? AND status = 'submitted'
+	`, userID, *reason, time.Now(), expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject expense: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("expense claim is no longer awaiting approval")
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionApproval,
+		EntityType: "expense",
+		EntityID:   expenseID,
+		Amount:     &expense.Amount,
+		Details: map[string]interface{}{
+			"decision": "rejected",
+			"reason":   *reason,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.getExpense(chamaID, expenseID)
+}
+
+// CancelExpense lets the claimant withdraw a claim that has not been paid
+func (s *ExpenseService) CancelExpense(chamaID, expenseID, userID string) (*models.Expense, error) {
+	expense, err := s.getExpense(chamaID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if expense.SubmittedBy != userID {
+		return nil, fmt.Errorf("only the claimant can cancel an expense claim")
+	}
+
+	result, err := s.db.Exec(`
+		UPDATE chama_expenses SET status = 'cancelled', updated_at = ?
+		WHERE id = ? AND status IN ('submitted', 'approved')
+	`, time.Now(), expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel expense: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("only open expense claims can be cancelled")
+	}
+
+	return s.getExpense(chamaID, expenseID)
+}
+
+// PayExpense pays an approved claim from the chama wallet. A claimant who paid out
+// of pocket is reimbursed into their personal wallet. The payment is published to
+// the chama's transparency log.
+func (s *ExpenseService) PayExpense(chamaID, expenseID, userID string) (*models.Expense, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can pay expense claims")
+	}
+
+	expense, err := s.getExpense(chamaID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if expense.Status != models.ExpenseStatusApproved {
+		return nil, fmt.Errorf("only approved expense claims can be paid")
+	}
+
+	// The payout leaves the chama wallet, so it is screened like any other chama outflow
+	check := &models.FraudCheck{
+		UserID:          userID,
+		ChamaID:         chamaID,
+		TransactionType: models.TransactionTypeExpense,
+		Amount:          expense.Amount,
+	}
+	if expense.PaidByClaimant {
+		check.RecipientID = expense.SubmittedBy
+	}
+	evaluation, err := screenOutgoing(s.db, check)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE chama_expenses SET status = 'paid', paid_by = ?, paid_at = ?, updated_at = ?
+		WHERE id = ? AND status = 'approved'
+	`, userID, now, now, expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark expense as paid: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("expense claim has already been paid")
+	}
+
+	var claimantWalletID *string
+	if expense.PaidByClaimant {
+		walletID, err := creditPersonalWallet(tx, expense.SubmittedBy, expense.Amount)
+		if err != nil {
+			return nil, err
+		}
+		claimantWalletID = &walletID
+	}
+
+	description := fmt.Sprintf("Expense: %s", expense.Title)
+	transactionID, err := postChamaWalletTransaction(tx, chamaID, userID, models.TransactionTypeExpense, -expense.Amount, claimantWalletID, description, map[string]interface{}{
+		"expenseId":      expense.ID,
+		"category":       expense.Category,
+		"paidByClaimant": expense.PaidByClaimant,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := recordFraudEvaluation(tx, evaluation, transactionID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("UPDATE chama_expenses SET transaction_id = ? WHERE id = ?", transactionID, expenseID); err != nil {
+		return nil, fmt.Errorf("failed to link expense payment: %w", err)
+	}
+
+	transparencyDescription := fmt.Sprintf("%s expense", strings.ReplaceAll(string(expense.Category), "_", " "))
+	if expense.Vendor != nil {
+		transparencyDescription += " paid to " + *expense.Vendor
+	}
+	_, err = tx.Exec(`
+		INSERT INTO financial_transparency_log (
+			id, chama_id, activity_type, title, description, amount, currency, transaction_type,
+			reference_id, reference_type, performed_by, visibility, created_at
+		) VALUES (?, ?, 'expense', ?, ?, ?, 'KES', 'debit', ?, 'expense', ?, 'all_members', ?)
+	`, uuid.New().String(), chamaID, expense.Title, transparencyDescription, expense.Amount, expense.ID, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record expense in transparency log: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Expense %s of %.2f paid from chama %s wallet by %s", expenseID, expense.Amount, chamaID, userID)
+	return s.getExpense(chamaID, expenseID)
+}
+
+// SetBudgetLine creates or replaces the budget for a category in a year. Claims
+// already submitted for that category and year are linked to it.
+func (s *ExpenseService) SetBudgetLine(chamaID, userID string, req *models.SetBudgetLineRequest) (*models.ExpenseBudgetLine, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can set the budget")
+	}
+
+	now := time.Now()
+	line := &models.ExpenseBudgetLine{
+		ID:         uuid.New().String(),
+		ChamaID:    chamaID,
+		FiscalYear: req.FiscalYear,
+		Category:   models.ExpenseCategory(req.Category),
+		Name:       req.Name,
+		Amount:     roundCurrency(req.Amount),
+		CreatedBy:  userID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if line.Name == "" {
+		line.Name = expenseCategoryLabel(line.Category)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO chama_budget_lines (id, chama_id, fiscal_year, category, name, amount, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(chama_id, fiscal_year, category) DO UPDATE SET
+			name = excluded.name, amount = excluded.amount, updated_at = excluded.updated_at
+	`, line.ID, line.ChamaID, line.FiscalYear, line.Category, line.Name, line.Amount, line.CreatedBy, line.CreatedAt, line.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save budget line: %w", err)
+	}
+
+	err = tx.QueryRow(`
+		SELECT id, created_by, created_at FROM chama_budget_lines WHERE chama_id = ? AND fiscal_year = ? AND category = ?
+	`, chamaID, line.FiscalYear, line.Category).Scan(&line.ID, &line.CreatedBy, &line.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget line: %w", err)
+	}
+
+	rows, err := tx.Query("SELECT id, expense_date FROM chama_expenses WHERE chama_id = ? AND category = ? AND budget_line_id IS NULL",
+		chamaID, line.Category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unbudgeted expenses: %w", err)
+	}
+	var unlinked []string
+	for rows.Next() {
+		var expenseID string
+		var expenseDate time.Time
+		if err := rows.Scan(&expenseID, &expenseDate); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan expense: %w", err)
+		}
+		if expenseDate.Year() == line.FiscalYear {
+			unlinked = append(unlinked, expenseID)
+		}
+	}
+	rows.Close()
+	for _, expenseID := range unlinked {
+		if _, err := tx.Exec("UPDATE chama_expenses SET budget_line_id = ? WHERE id = ?", line.ID, expenseID); err != nil {
+			return nil, fmt.Errorf("failed to link expense to budget line: %w", err)
+		}
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "budget_line",
+		EntityID:   line.ID,
+		Amount:     &line.Amount,
+		Details: map[string]interface{}{
+			"fiscalYear": line.FiscalYear,
+			"category":   line.Category,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return line, nil
+}
+
+// GetBudgetLines lists a chama's budget for a year
+func (s *ExpenseService) GetBudgetLines(chamaID, userID string, year int) ([]models.ExpenseBudgetLine, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	return s.budgetLines(chamaID, year)
+}
+
+// DeleteBudgetLine removes a budget line; its expenses become unbudgeted
+func (s *ExpenseService) DeleteBudgetLine(chamaID, lineID, userID string) error {
+	if !s.isOfficial(userID, chamaID) {
+		return fmt.Errorf("only chama officials can set the budget")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE chama_expenses SET budget_line_id = NULL WHERE budget_line_id = ?", lineID); err != nil {
+		return fmt.Errorf("failed to unlink expenses: %w", err)
+	}
+	result, err := tx.Exec("DELETE FROM chama_budget_lines WHERE id = ? AND chama_id = ?", lineID, chamaID)
+	if err != nil {
+		return fmt.Errorf("failed to delete budget line: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("budget line not found")
+	}
+
+	return tx.Commit()
+}
+
+// GetApprovalRules lists a chama's expense approval rules by minimum amount
+func (s *ExpenseService) GetApprovalRules(chamaID, userID string) ([]models.ExpenseApprovalRule, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, chama_id, min_amount, approvals_required, receipt_required, created_by, updated_at
+		FROM chama_expense_approval_rules
+		WHERE chama_id = ?
+		ORDER BY min_amount
+	`, chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expense approval rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []models.ExpenseApprovalRule{}
+	for rows.Next() {
+		var rule models.ExpenseApprovalRule
+		err := rows.Scan(&rule.ID, &rule.ChamaID, &rule.MinAmount, &rule.ApprovalsRequired, &rule.ReceiptRequired,
+			&rule.CreatedBy, &rule.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan expense approval rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// SetApprovalRule creates or replaces the rule for claims of at least MinAmount
+func (s *ExpenseService) SetApprovalRule(chamaID, userID string, req *models.SetExpenseApprovalRuleRequest) (*models.ExpenseApprovalRule, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can configure expense approval rules")
+	}
+
+	rule := &models.ExpenseApprovalRule{
+		ID:                uuid.New().String(),
+		ChamaID:           chamaID,
+		MinAmount:         roundCurrency(req.MinAmount),
+		ApprovalsRequired: req.ApprovalsRequired,
+		ReceiptRequired:   req.ReceiptRequired,
+		CreatedBy:         userID,
+		UpdatedAt:         time.Now(),
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO chama_expense_approval_rules (id, chama_id, min_amount, approvals_required, receipt_required, created_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(chama_id, min_amount) DO UPDATE SET
+			approvals_required = excluded.approvals_required, receipt_required = excluded.receipt_required,
+			created_by = excluded.created_by, updated_at = excluded.updated_at
+	`, rule.ID, rule.ChamaID, rule.MinAmount, rule.ApprovalsRequired, rule.ReceiptRequired, rule.CreatedBy, rule.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save expense approval rule: %w", err)
+	}
+
+	err = tx.QueryRow("SELECT id FROM chama_expense_approval_rules WHERE chama_id = ? AND min_amount = ?", chamaID, rule.MinAmount).Scan(&rule.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expense approval rule: %w", err)
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "expense_approval_rule",
+		EntityID:   rule.ID,
+		Amount:     &rule.MinAmount,
+		Details: map[string]interface{}{
+			"approvalsRequired": rule.ApprovalsRequired,
+			"receiptRequired":   rule.ReceiptRequired,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteApprovalRule removes a rule so the next lower rule applies to its amounts
+func (s *ExpenseService) DeleteApprovalRule(chamaID, ruleID, userID string) error {
+	if !s.isOfficial(userID, chamaID) {
+		return fmt.Errorf("only chama officials can configure expense approval rules")
+	}
+
+	result, err := s.db.Exec("DELETE FROM chama_expense_approval_rules WHERE id = ? AND chama_id = ?", ruleID, chamaID)
+	if err != nil {
+		return fmt.Errorf("failed to delete expense approval rule: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("approval rule not found")
+	}
+	return nil
+}
+
+// GetBudgetReport compares a year's budget with approved and paid expenses
+func (s *ExpenseService) GetBudgetReport(chamaID, userID string, year int) (*models.BudgetVsActualReport, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	lines, err := s.budgetLines(chamaID, year)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := s.queryExpenses(expenseSelect+" WHERE e.chama_id = ? AND e.status IN ('approved', 'paid')", chamaID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.BudgetVsActualReport{
+		ChamaID:    chamaID,
+		FiscalYear: year,
+		Lines:      []models.BudgetVsActualLine{},
+	}
+	byCategory := make(map[models.ExpenseCategory]*models.BudgetVsActualLine)
+	var order []models.ExpenseCategory
+	for _, line := range lines {
+		lineID := line.ID
+		byCategory[line.Category] = &models.BudgetVsActualLine{
+			Category:     line.Category,
+			BudgetLineID: &lineID,
+			Name:         line.Name,
+			Budgeted:     line.Amount,
+		}
+		order = append(order, line.Category)
+	}
+
+	for _, expense := range expenses {
+		if expense.ExpenseDate.Year() != year {
+			continue
+		}
+		line, ok := byCategory[expense.Category]
+		if !ok {
+			line = &models.BudgetVsActualLine{
+				Category: expense.Category,
+				Name:     expenseCategoryLabel(expense.Category),
+			}
+			byCategory[expense.Category] = line
+			order = append(order, expense.Category)
+		}
+		if expense.Status == models.ExpenseStatusPaid {
+			line.Actual += expense.Amount
+		} else {
+			line.Committed += expense.Amount
+		}
+		line.ExpenseCount++
+	}
+
+	for _, category := range order {
+		line := byCategory[category]
+		line.Actual = roundCurrency(line.Actual)
+		line.Committed = roundCurrency(line.Committed)
+		line.Variance = roundCurrency(line.Budgeted - line.Actual - line.Committed)
+		line.OverBudget = line.Variance < 0
+		if line.Budgeted > 0 {
+			line.PercentUsed = roundCurrency((line.Actual + line.Committed) / line.Budgeted * 100)
+		}
+
+		report.Lines = append(report.Lines, *line)
+		report.TotalBudgeted += line.Budgeted
+		report.TotalActual += line.Actual
+		report.TotalCommitted += line.Committed
+	}
+
+	report.TotalBudgeted = roundCurrency(report.TotalBudgeted)
+	report.TotalActual = roundCurrency(report.TotalActual)
+	report.TotalCommitted = roundCurrency(report.TotalCommitted)
+	report.TotalVariance = roundCurrency(report.TotalBudgeted - report.TotalActual - report.TotalCommitted)
+	if report.TotalBudgeted > 0 {
+		report.PercentUsed = roundCurrency((report.TotalActual + report.TotalCommitted) / report.TotalBudgeted * 100)
+	}
+	return report, nil
+}
+
+// Helper functions
+
+const expenseSelect = `
+	SELECT e.id, e.chama_id, e.category, e.budget_line_id, e.title, e.description, e.vendor, e.amount,
+		e.expense_date, e.meeting_id, e.paid_by_claimant, e.receipt_file_id, e.status, e.approvals_required,
+		e.receipt_required, e.approval_count, e.submitted_by, COALESCE(u.first_name || ' ' || u.last_name, ''),
+		e.rejected_by, e.rejection_reason, e.paid_by, e.paid_at, e.transaction_id, e.created_at, e.updated_at
+	FROM chama_expenses e
+	LEFT JOIN users u ON u.id = e.submitted_by`
+
+func (s *ExpenseService) queryExpenses(query string, args ...interface{}) ([]models.Expense, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expenses: %w", err)
+	}
+	defer rows.Close()
+
+	expenses := []models.Expense{}
+	for rows.Next() {
+		expense, err := scanExpense(rows)
+		if err != nil {
+			return nil, err
+		}
+		expenses = append(expenses, *expense)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read expenses: %w", err)
+	}
+	return expenses, nil
+}
+
+func scanExpense(row interface{ Scan(...interface{}) error }) (*models.Expense, error) {
+	expense := &models.Expense{}
+	var budgetLineID, description, vendor, meetingID, receiptFileID sql.NullString
+	var rejectedBy, rejectionReason, paidBy, transactionID sql.NullString
+	var paidAt sql.NullTime
+	err := row.Scan(&expense.ID, &expense.ChamaID, &expense.Category, &budgetLineID, &expense.Title, &description,
+		&vendor, &expense.Amount, &expense.ExpenseDate, &meetingID, &expense.PaidByClaimant, &receiptFileID,
+		&expense.Status, &expense.ApprovalsRequired, &expense.ReceiptRequired, &expense.ApprovalCount,
+		&expense.SubmittedBy, &expense.SubmitterName, &rejectedBy, &rejectionReason, &paidBy, &paidAt,
+		&transactionID, &expense.CreatedAt, &expense.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan expense: %w", err)
+	}
+
+	expense.BudgetLineID = nullStringPtr(budgetLineID)
+	expense.Description = nullStringPtr(description)
+	expense.Vendor = nullStringPtr(vendor)
+	expense.MeetingID = nullStringPtr(meetingID)
+	expense.ReceiptFileID = nullStringPtr(receiptFileID)
+	expense.RejectedBy = nullStringPtr(rejectedBy)
+	expense.RejectionReason = nullStringPtr(rejectionReason)
+	expense.PaidBy = nullStringPtr(paidBy)
+	expense.TransactionID = nullStringPtr(transactionID)
+	if paidAt.Valid {
+		expense.PaidAt = &paidAt.Time
+	}
+	return expense, nil
+}
+
+func nullStringPtr(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
+
+func (s *ExpenseService) getExpense(chamaID, expenseID string) (*models.Expense, error) {
+	expense, err := scanExpense(s.db.QueryRow(expenseSelect+" WHERE e.id = ? AND e.chama_id = ?", expenseID, chamaID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("expense not found")
+	}
+	return expense, err
+}
+
+// reviewable returns a claim an official may approve or reject: one still awaiting
+// approval that they did not submit
+func (s *ExpenseService) reviewable(chamaID, expenseID, userID string) (*models.Expense, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can review expense claims")
+	}
+	expense, err := s.getExpense(chamaID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if expense.SubmittedBy == userID {
+		return nil, fmt.Errorf("you cannot review your own expense claim")
+	}
+	if expense.Status != models.ExpenseStatusSubmitted {
+		return nil, fmt.Errorf("expense claim is no longer awaiting approval")
+	}
+	return expense, nil
+}
+
+func (s *ExpenseService) insertDecision(tx *sql.Tx, expenseID, userID, decision string, comment *string) error {
+	var existing int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM chama_expense_approvals WHERE expense_id = ? AND approver_id = ?", expenseID, userID).Scan(&existing); err != nil {
+		return fmt.Errorf("failed to check expense approvals: %w", err)
+	}
+	if existing > 0 {
+		return fmt.Errorf("you have already reviewed this expense claim")
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO chama_expense_approvals (id, expense_id, approver_id, decision, comment, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, uuid.New().String(), expenseID, userID, decision, comment, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record expense decision: %w", err)
+	}
+	return nil
+}
+
+// resolveRule finds the rule for an amount: the one with the highest minimum not
+// above it, or the default when none applies
+func (s *ExpenseService) resolveRule(chamaID string, amount float64) (*models.ExpenseApprovalRule, error) {
+	rule := &models.ExpenseApprovalRule{
+		ChamaID:           chamaID,
+		ApprovalsRequired: models.DefaultExpenseApprovalsRequired,
+		ReceiptRequired:   models.DefaultExpenseReceiptRequired,
+	}
+	err := s.db.QueryRow(`
+		SELECT id, min_amount, approvals_required, receipt_required, created_by, updated_at
+		FROM chama_expense_approval_rules
+		WHERE chama_id = ? AND min_amount <= ?
+		ORDER BY min_amount DESC
+		LIMIT 1
+	`, chamaID, amount).Scan(&rule.ID, &rule.MinAmount, &rule.ApprovalsRequired, &rule.ReceiptRequired, &rule.CreatedBy, &rule.UpdatedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get expense approval rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (s *ExpenseService) budgetLines(chamaID string, year int) ([]models.ExpenseBudgetLine, error) {
+	rows, err := s.db.Query(`
+		SELECT id, chama_id, fiscal_year, category, name, amount, created_by, created_at, updated_at
+		FROM chama_budget_lines
+		WHERE chama_id = ? AND fiscal_year = ?
+		ORDER BY category
+	`, chamaID, year)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []models.ExpenseBudgetLine{}
+	for rows.Next() {
+		var line models.ExpenseBudgetLine
+		err := rows.Scan(&line.ID, &line.ChamaID, &line.FiscalYear, &line.Category, &line.Name, &line.Amount,
+			&line.CreatedBy, &line.CreatedAt, &line.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan budget line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// expenseCategoryLabel turns a category such as bank_charges into "Bank charges"
+func expenseCategoryLabel(category models.ExpenseCategory) string {
+	label := strings.ReplaceAll(string(category), "_", " ")
+	if label == "" {
+		return label
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+func (s *ExpenseService) notifyOfficials(expense *models.Expense, title, message string) {
+	rows, err := s.db.Query(`
+		SELECT user_id FROM chama_members
+		WHERE chama_id = ? AND user_id != ? AND is_active = TRUE AND role IN ('chairperson', 'secretary', 'treasurer')
+	`, expense.ChamaID, expense.SubmittedBy)
+	if err != nil {
+		log.Printf("Failed to load officials for expense notification: %v", err)
+		return
+	}
+
+	var officials []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err == nil {
+			officials = append(officials, userID)
+		}
+	}
+	rows.Close()
+
+	notificationService := NewNotificationService(s.db, nil)
+	for _, officialID := range officials {
+		if err := notificationService.CreateInAppNotification(officialID, "chama", "expense_claim", title, message, map[string]interface{}{
+			"chamaId":   expense.ChamaID,
+			"expenseId": expense.ID,
+			"amount":    expense.Amount,
+		}); err != nil {
+			log.Printf("Failed to notify official %s about expense claim: %v", officialID, err)
+		}
+	}
+}
+
+func (s *ExpenseService) isMember(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow("SELECT 1 FROM chama_members WHERE user_id = ? AND chama_id = ? AND is_active = TRUE", userID, chamaID).Scan(&exists)
+	return err == nil
+}
+
+func (s *ExpenseService) isOfficial(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM chama_members
+		WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+		AND role IN ('chairperson', 'secretary', 'treasurer')
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
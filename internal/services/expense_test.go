@@ -0,0 +1,245 @@
+package services_test
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type ExpenseTestSuite struct {
+	suite.Suite
+	testDB      *helpers.TestDatabase
+	db          *sql.DB
+	service     *services.ExpenseService
+	storage     *services.StorageService
+	treasurerID string
+	chairID     string
+	secretaryID string
+	memberID    string
+	chamaID     string
+}
+
+func (suite *ExpenseTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewExpenseService(suite.db)
+	suite.storage = newTestStorageService(suite.T(), suite.db, services.NewLocalStorageBackend(suite.T().TempDir()), storageTestSecret)
+
+	suite.treasurerID = suite.testDB.AddTestUser(suite.T(), "Treasurer")
+	suite.chairID = suite.testDB.AddTestUser(suite.T(), "Chair")
+	suite.secretaryID = suite.testDB.AddTestUser(suite.T(), "Secretary")
+	suite.memberID = suite.testDB.AddTestUser(suite.T(), "Member")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.treasurerID, "treasurer")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.secretaryID, "secretary")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.memberID, "member")
+
+	_, err := suite.db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'chama', ?, 20000)", uuid.New().String(), suite.chamaID)
+	suite.Require().NoError(err)
+}
+
+func (suite *ExpenseTestSuite) submit(category string, amount float64, paidByClaimant bool) *models.Expense {
+	expense, err := suite.service.SubmitExpense(suite.chamaID, suite.treasurerID, &models.SubmitExpenseRequest{
+		Category:       category,
+		Title:          "Monthly meeting " + category,
+		Amount:         amount,
+		ExpenseDate:    time.Now(),
+		PaidByClaimant: paidByClaimant,
+	})
+	suite.Require().NoError(err)
+	return expense
+}
+
+func (suite *ExpenseTestSuite) attachReceipt(expenseID string) {
+	file, err := suite.storage.Store(&services.StoreFileInput{
+		OwnerID:      suite.treasurerID,
+		FileName:     "receipt.pdf",
+		Category:     models.FileCategoryExpenseReceipt,
+		AccessScope:  models.FileAccessChama,
+		ScopeID:      suite.chamaID,
+		AllowedTypes: []string{"application/pdf"},
+		MaxSize:      1024 * 1024,
+	}, bytes.NewReader([]byte("%PDF-1.4\n% receipt\n")))
+	suite.Require().NoError(err)
+	_, err = suite.service.AttachReceipt(suite.chamaID, expenseID, suite.treasurerID, file.ID)
+	suite.Require().NoError(err)
+}
+
+func (suite *ExpenseTestSuite) balance(ownerID, walletType string) float64 {
+	var balance float64
+	suite.Require().NoError(suite.db.QueryRow("SELECT balance FROM wallets WHERE owner_id = ? AND type = ?", ownerID, walletType).Scan(&balance))
+	return balance
+}
+
+func (suite *ExpenseTestSuite) TestClaimIsApprovedAndReimbursed() {
+	_, err := suite.service.SubmitExpense(suite.chamaID, suite.memberID, &models.SubmitExpenseRequest{
+		Category: "venue", Title: "Hall", Amount: 100, ExpenseDate: time.Now(),
+	})
+	suite.Error(err, "only officials submit claims")
+
+	expense := suite.submit("venue", 3000, true)
+	suite.Equal(1, expense.ApprovalsRequired, "the default rule needs one other official")
+	suite.True(expense.ReceiptRequired)
+
+	_, err = suite.service.ApproveExpense(suite.chamaID, expense.ID, suite.treasurerID, nil)
+	suite.Error(err, "claimants cannot approve their own claims")
+	_, err = suite.service.ApproveExpense(suite.chamaID, expense.ID, suite.chairID, nil)
+	suite.Error(err, "a receipt is required first")
+	_, err = suite.service.PayExpense(suite.chamaID, expense.ID, suite.chairID)
+	suite.Error(err, "unapproved claims cannot be paid")
+
+	suite.attachReceipt(expense.ID)
+	approved, err := suite.service.ApproveExpense(suite.chamaID, expense.ID, suite.chairID, nil)
+	suite.Require().NoError(err)
+	suite.Equal(models.ExpenseStatusApproved, approved.Status)
+
+	_, err = suite.service.PayExpense(suite.chamaID, expense.ID, suite.memberID)
+	suite.Error(err, "only officials pay claims")
+	paid, err := suite.service.PayExpense(suite.chamaID, expense.ID, suite.chairID)
+	suite.Require().NoError(err)
+	suite.Equal(models.ExpenseStatusPaid, paid.Status)
+	suite.Require().NotNil(paid.TransactionID)
+	suite.Equal(17000.0, suite.balance(suite.chamaID, "chama"))
+	suite.Equal(3000.0, suite.balance(suite.treasurerID, "personal"), "the claimant is reimbursed")
+
+	_, err = suite.service.PayExpense(suite.chamaID, expense.ID, suite.chairID)
+	suite.Error(err, "a claim is paid once")
+
+	var toWallet sql.NullString
+	var txType string
+	suite.Require().NoError(suite.db.QueryRow("SELECT type, to_wallet_id FROM transactions WHERE id = ?", *paid.TransactionID).Scan(&txType, &toWallet))
+	suite.Equal("expense", txType)
+	suite.True(toWallet.Valid)
+
+	var screened int
+	suite.Require().NoError(suite.db.QueryRow("SELECT COUNT(*) FROM fraud_evaluations WHERE transaction_id = ? AND chama_id = ?", *paid.TransactionID, suite.chamaID).Scan(&screened))
+	suite.Equal(1, screened, "the payout is screened as a chama outflow")
+
+	var logged int
+	suite.Require().NoError(suite.db.QueryRow(`
+		SELECT COUNT(*) FROM financial_transparency_log WHERE chama_id = ? AND activity_type = 'expense' AND reference_id = ?
+	`, suite.chamaID, expense.ID).Scan(&logged))
+	suite.Equal(1, logged)
+
+	detail, err := suite.service.GetExpense(suite.chamaID, expense.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Require().Len(detail.Approvals, 1)
+	suite.Equal("Chair User", detail.Approvals[0].ApproverName)
+}
+
+func (suite *ExpenseTestSuite) TestApprovalRulesScaleWithAmount() {
+	_, err := suite.service.SetApprovalRule(suite.chamaID, suite.memberID, &models.SetExpenseApprovalRuleRequest{ApprovalsRequired: 1})
+	suite.Error(err)
+	_, err = suite.service.SetApprovalRule(suite.chamaID, suite.chairID, &models.SetExpenseApprovalRuleRequest{
+		MinAmount: 0, ApprovalsRequired: 1, ReceiptRequired: false,
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.SetApprovalRule(suite.chamaID, suite.chairID, &models.SetExpenseApprovalRuleRequest{
+		MinAmount: 5000, ApprovalsRequired: 3, ReceiptRequired: true,
+	})
+	suite.Require().NoError(err)
+
+	small := suite.submit("refreshments", 800, false)
+	suite.Equal(1, small.ApprovalsRequired)
+	suite.False(small.ReceiptRequired)
+	approved, err := suite.service.ApproveExpense(suite.chamaID, small.ID, suite.secretaryID, nil)
+	suite.Require().NoError(err)
+	suite.Equal(models.ExpenseStatusApproved, approved.Status, "no receipt needed for small claims")
+
+	large := suite.submit("professional_fees", 8000, false)
+	suite.Equal(2, large.ApprovalsRequired, "capped at the two officials who are not the claimant")
+	suite.attachReceipt(large.ID)
+	pending, err := suite.service.ApproveExpense(suite.chamaID, large.ID, suite.chairID, nil)
+	suite.Require().NoError(err)
+	suite.Equal(models.ExpenseStatusSubmitted, pending.Status)
+	_, err = suite.service.ApproveExpense(suite.chamaID, large.ID, suite.chairID, nil)
+	suite.Error(err, "an official approves once")
+	approved, err = suite.service.ApproveExpense(suite.chamaID, large.ID, suite.secretaryID, nil)
+	suite.Require().NoError(err)
+	suite.Equal(models.ExpenseStatusApproved, approved.Status)
+
+	rejected := suite.submit("transport", 600, false)
+	_, err = suite.service.RejectExpense(suite.chamaID, rejected.ID, suite.chairID, nil)
+	suite.Error(err, "a reason is required")
+	reason := "Not chama business"
+	closed, err := suite.service.RejectExpense(suite.chamaID, rejected.ID, suite.chairID, &reason)
+	suite.Require().NoError(err)
+	suite.Equal(models.ExpenseStatusRejected, closed.Status)
+	_, err = suite.service.ApproveExpense(suite.chamaID, rejected.ID, suite.secretaryID, nil)
+	suite.Error(err)
+}
+
+func (suite *ExpenseTestSuite) TestBudgetVersusActual() {
+	year := time.Now().Year()
+	_, err := suite.service.SetBudgetLine(suite.chamaID, suite.memberID, &models.SetBudgetLineRequest{FiscalYear: year, Category: "venue", Amount: 36000})
+	suite.Error(err)
+
+	// A claim submitted before its budget line exists is linked when the line is set
+	early := suite.submit("venue", 3000, false)
+	suite.Nil(early.BudgetLineID)
+	line, err := suite.service.SetBudgetLine(suite.chamaID, suite.chairID, &models.SetBudgetLineRequest{FiscalYear: year, Category: "venue", Amount: 36000})
+	suite.Require().NoError(err)
+	suite.Equal("Venue", line.Name)
+	detail, err := suite.service.GetExpense(suite.chamaID, early.ID, suite.chairID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(detail.BudgetLineID)
+	suite.Equal(line.ID, *detail.BudgetLineID)
+
+	_, err = suite.service.SetBudgetLine(suite.chamaID, suite.chairID, &models.SetBudgetLineRequest{FiscalYear: year, Category: "refreshments", Amount: 1000})
+	suite.Require().NoError(err)
+
+	suite.attachReceipt(early.ID)
+	_, err = suite.service.ApproveExpense(suite.chamaID, early.ID, suite.chairID, nil)
+	suite.Require().NoError(err)
+	_, err = suite.service.PayExpense(suite.chamaID, early.ID, suite.chairID)
+	suite.Require().NoError(err)
+
+	snacks := suite.submit("refreshments", 1500, false)
+	suite.attachReceipt(snacks.ID)
+	_, err = suite.service.ApproveExpense(suite.chamaID, snacks.ID, suite.secretaryID, nil)
+	suite.Require().NoError(err)
+
+	fare := suite.submit("transport", 400, false)
+	suite.attachReceipt(fare.ID)
+	_, err = suite.service.ApproveExpense(suite.chamaID, fare.ID, suite.secretaryID, nil)
+	suite.Require().NoError(err)
+	_, err = suite.service.PayExpense(suite.chamaID, fare.ID, suite.chairID)
+	suite.Require().NoError(err)
+
+	suite.submit("stationery", 200, false) // still awaiting approval, so not counted
+
+	report, err := suite.service.GetBudgetReport(suite.chamaID, suite.memberID, year)
+	suite.Require().NoError(err)
+	lines := map[models.ExpenseCategory]models.BudgetVsActualLine{}
+	for _, line := range report.Lines {
+		lines[line.Category] = line
+	}
+	suite.Len(lines, 3)
+
+	suite.Equal(3000.0, lines["venue"].Actual)
+	suite.Equal(33000.0, lines["venue"].Variance)
+	suite.InDelta(8.33, lines["venue"].PercentUsed, 0.001)
+
+	suite.Equal(1500.0, lines["refreshments"].Committed)
+	suite.True(lines["refreshments"].OverBudget)
+
+	suite.Zero(lines["transport"].Budgeted, "unbudgeted spending is still reported")
+	suite.Equal(400.0, lines["transport"].Actual)
+
+	suite.Equal(37000.0, report.TotalBudgeted)
+	suite.Equal(3400.0, report.TotalActual)
+	suite.Equal(1500.0, report.TotalCommitted)
+	suite.Equal(32100.0, report.TotalVariance)
+}
+
+func TestExpenseSuite(t *testing.T) {
+	suite.Run(t, new(ExpenseTestSuite))
+}
@@ -0,0 +1,13 @@
+package services
+
+import "time"
+
+// SetSchedulerInterval shortens the scheduler's tick so tests can drive it
+func SetSchedulerInterval(ns *NotificationScheduler, interval time.Duration) {
+	ns.interval = interval
+}
+
+// PrependSchedulerJob makes job the first thing the scheduler runs on each tick
+func PrependSchedulerJob(ns *NotificationScheduler, name string, job func()) {
+	ns.jobs = append([]schedulerJob{{name, job}}, ns.jobs...)
+}
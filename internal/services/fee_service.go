@@ -0,0 +1,698 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"vaultke-backend/internal/models"
+)
+
+// errTransactionLimitExceeded is wrapped by every failure to fit an outgoing amount
+// inside the user's tier or wallet limits
+var errTransactionLimitExceeded = errors.New("transaction limit exceeded")
+
+// FeeService handles the fee schedule and per-tier outgoing transaction limits
+type FeeService struct {
+	db *sql.DB
+}
+
+// NewFeeService creates a new fee service
+func NewFeeService(db *sql.DB) *FeeService {
+	return &FeeService{db: db}
+}
+
+// GetFeeRules lists the fee schedule, most specific rules first
+func (s *FeeService) GetFeeRules(includeInactive bool) ([]*models.FeeRule, error) {
+	query := `
+		SELECT id, name, transaction_type, payment_method, calculation, flat_fee, percentage, min_fee,
+			   max_fee, bands, is_active, created_by, updated_by, created_at, updated_at
+		FROM fee_rules
+	`
+	if !includeInactive {
+		query += " WHERE is_active = TRUE"
+	}
+	query += " ORDER BY transaction_type = '', payment_method = '', transaction_type, payment_method, updated_at DESC"
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []*models.FeeRule{}
+	for rows.Next() {
+		rule, err := scanFeeRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read fee rules: %w", err)
+	}
+	return rules, nil
+}
+
+// CreateFeeRule adds a rule to the fee schedule. It applies to the next matching
+// transaction; fees already charged are not recalculated.
+func (s *FeeService) CreateFeeRule(adminID string, req *models.FeeRuleRequest) (*models.FeeRule, error) {
+	now := time.Now()
+	rule := &models.FeeRule{
+		ID:        uuid.New().String(),
+		IsActive:  true,
+		CreatedBy: adminID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := applyFeeRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	bands, err := rule.GetBandsJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize fee bands: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO fee_rules (
+			id, name, transaction_type, payment_method, calculation, flat_fee, percentage, min_fee,
+			max_fee, bands, is_active, created_by, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rule.ID, rule.Name, rule.TransactionType, rule.PaymentMethod, rule.Calculation, rule.FlatFee, rule.Percentage,
+		rule.MinFee, rule.MaxFee, bands, rule.IsActive, rule.CreatedBy, rule.CreatedAt, rule.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fee rule: %w", err)
+	}
+
+	if err := s.auditRule(tx, adminID, rule, "created"); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return rule, nil
+}
+
+// UpdateFeeRule replaces a fee rule's pricing
+func (s *FeeService) UpdateFeeRule(ruleID, adminID string, req *models.FeeRuleRequest) (*models.FeeRule, error) {
+	rule, err := s.getFeeRule(ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyFeeRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	bands, err := rule.GetBandsJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize fee bands: %w", err)
+	}
+	rule.UpdatedBy = &adminID
+	rule.UpdatedAt = time.Now()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE fee_rules
+		SET name = ?, transaction_type = ?, payment_method = ?, calculation = ?, flat_fee = ?, percentage = ?,
+			min_fee = ?, max_fee = ?, bands = ?, is_active = ?, updated_by = ?, updated_at = ?
+		WHERE id = ?
+	`, rule.Name, rule.TransactionType, rule.PaymentMethod, rule.Calculation, rule.FlatFee, rule.Percentage,
+		rule.MinFee, rule.MaxFee, bands, rule.IsActive, adminID, rule.UpdatedAt, ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update fee rule: %w", err)
+	}
+
+	if err := s.auditRule(tx, adminID, rule, "updated"); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return rule, nil
+}
+
+// DeactivateFeeRule takes a rule out of the schedule. It is kept so that past fees can
+// still be traced to it.
+func (s *FeeService) DeactivateFeeRule(ruleID, adminID string) error {
+	rule, err := s.getFeeRule(ruleID)
+	if err != nil {
+		return err
+	}
+	if !rule.IsActive {
+		return fmt.Errorf("fee rule is already inactive")
+	}
+	rule.IsActive = false
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE fee_rules SET is_active = FALSE, updated_by = ?, updated_at = ? WHERE id = ?", adminID, time.Now(), ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate fee rule: %w", err)
+	}
+	if err := s.auditRule(tx, adminID, rule, "deactivated"); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CalculateFee prices a transaction against the fee schedule
+func (s *FeeService) CalculateFee(transactionType models.TransactionType, paymentMethod models.PaymentMethod, amount float64) (float64, error) {
+	fee, _, err := calculateFee(s.db, transactionType, paymentMethod, amount)
+	return fee, err
+}
+
+// QuoteFee tells the user what a transaction will cost and, for money leaving their
+// wallet, whether it fits inside their limits
+func (s *FeeService) QuoteFee(userID string, transactionType models.TransactionType, paymentMethod models.PaymentMethod, amount float64) (*models.FeeQuote, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+	if !isKnownTransactionType(string(transactionType)) {
+		return nil, fmt.Errorf("unknown transaction type: %s", transactionType)
+	}
+
+	fee, rule, err := calculateFee(s.db, transactionType, paymentMethod, amount)
+	if err != nil {
+		return nil, err
+	}
+	quote := &models.FeeQuote{
+		TransactionType: string(transactionType),
+		PaymentMethod:   string(paymentMethod),
+		Amount:          amount,
+		Fee:             fee,
+		TotalDebit:      roundCurrency(amount + fee),
+	}
+	if rule != nil {
+		quote.RuleID = &rule.ID
+		quote.RuleName = rule.Name
+	}
+
+	if isOutgoingTransactionType(transactionType) {
+		quote.Limits, err = limitStatus(s.db, userID, amount)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return quote, nil
+}
+
+// GetLimitStatus reports how much of the user's limits is used and whether amount fits
+func (s *FeeService) GetLimitStatus(userID string, amount float64) (*models.LimitStatus, error) {
+	return limitStatus(s.db, userID, amount)
+}
+
+// ReserveLimit counts an outgoing amount against the user's limits inside tx, failing
+// when it does not fit. Handlers that move money out of a wallet themselves call it.
+func (s *FeeService) ReserveLimit(tx *sql.Tx, userID, reference string, amount float64) error {
+	return reserveTransactionLimit(tx, userID, reference, amount)
+}
+
+// GetLimitTiers lists the outgoing limits for each KYC tier
+func (s *FeeService) GetLimitTiers() ([]*models.TransactionLimitTier, error) {
+	rows, err := s.db.Query(`
+		SELECT tier, name, single_transaction_limit, daily_limit, monthly_limit, max_loan_amount, updated_by, updated_at
+		FROM transaction_limit_tiers ORDER BY tier
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get limit tiers: %w", err)
+	}
+	defer rows.Close()
+
+	tiers := []*models.TransactionLimitTier{}
+	for rows.Next() {
+		tier := &models.TransactionLimitTier{}
+		var updatedBy sql.NullString
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&tier.Tier, &tier.Name, &tier.SingleTransactionLimit, &tier.DailyLimit,
+			&tier.MonthlyLimit, &tier.MaxLoanAmount, &updatedBy, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan limit tier: %w", err)
+		}
+		if updatedBy.Valid {
+			tier.UpdatedBy = &updatedBy.String
+		}
+		if updatedAt.Valid {
+			tier.UpdatedAt = &updatedAt.Time
+		}
+		tiers = append(tiers, tier)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read limit tiers: %w", err)
+	}
+	return tiers, nil
+}
+
+// UpdateLimitTier sets the outgoing limits for a KYC tier, adding the tier if it is new
+func (s *FeeService) UpdateLimitTier(tier int, adminID string, req *models.UpdateLimitTierRequest) (*models.TransactionLimitTier, error) {
+	if tier < 0 || tier > 9 {
+		return nil, fmt.Errorf("tier must be between 0 and 9")
+	}
+	if req.SingleTransactionLimit > req.DailyLimit {
+		return nil, fmt.Errorf("singleTransactionLimit cannot exceed dailyLimit")
+	}
+	if req.DailyLimit > req.MonthlyLimit {
+		return nil, fmt.Errorf("dailyLimit cannot exceed monthlyLimit")
+	}
+
+	var name string
+	var maxLoanAmount float64
+	err := s.db.QueryRow("SELECT name, max_loan_amount FROM transaction_limit_tiers WHERE tier = ?", tier).Scan(&name, &maxLoanAmount)
+	if err == sql.ErrNoRows {
+		name = fmt.Sprintf("Tier %d", tier)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get limit tier: %w", err)
+	}
+	if req.Name != "" {
+		name = req.Name
+	}
+	if req.MaxLoanAmount != nil {
+		maxLoanAmount = *req.MaxLoanAmount
+	}
+
+	now := time.Now()
+	result := &models.TransactionLimitTier{
+		Tier:                   tier,
+		Name:                   name,
+		SingleTransactionLimit: req.SingleTransactionLimit,
+		DailyLimit:             req.DailyLimit,
+		MonthlyLimit:           req.MonthlyLimit,
+		MaxLoanAmount:          maxLoanAmount,
+		UpdatedBy:              &adminID,
+		UpdatedAt:              &now,
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO transaction_limit_tiers (tier, name, single_transaction_limit, daily_limit, monthly_limit, max_loan_amount, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(tier) DO UPDATE SET
+			name = excluded.name,
+			single_transaction_limit = excluded.single_transaction_limit,
+			daily_limit = excluded.daily_limit,
+			monthly_limit = excluded.monthly_limit,
+			max_loan_amount = excluded.max_loan_amount,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`, tier, name, req.SingleTransactionLimit, req.DailyLimit, req.MonthlyLimit, maxLoanAmount, adminID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update limit tier: %w", err)
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ActorID:    &adminID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "transaction_limit_tier",
+		EntityID:   fmt.Sprintf("%d", tier),
+		Details: map[string]interface{}{
+			"name":                   name,
+			"singleTransactionLimit": req.SingleTransactionLimit,
+			"dailyLimit":             req.DailyLimit,
+			"monthlyLimit":           req.MonthlyLimit,
+			"maxLoanAmount":          maxLoanAmount,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return result, nil
+}
+
+// GetFeeRevenue totals the fees posted to the platform account between from and to,
+// grouped by the type of transaction that was charged
+func (s *FeeService) GetFeeRevenue(from, to time.Time) (*models.FeeRevenueSummary, error) {
+	rows, err := s.db.Query(`
+		SELECT COALESCE(json_extract(metadata, '$.chargedTransactionType'), 'unknown'), COUNT(*), COALESCE(SUM(amount), 0)
+		FROM transactions
+		WHERE type = ? AND to_wallet_id = ? AND status = ? AND created_at >= ? AND created_at < ?
+		GROUP BY 1 ORDER BY 1
+	`, models.TransactionTypeFee, models.PlatformFeeWalletID, models.TransactionStatusCompleted, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee revenue: %w", err)
+	}
+	defer rows.Close()
+
+	summary := &models.FeeRevenueSummary{From: from, To: to, Lines: []*models.FeeRevenueLine{}}
+	for rows.Next() {
+		line := &models.FeeRevenueLine{}
+		if err := rows.Scan(&line.TransactionType, &line.Count, &line.Total); err != nil {
+			return nil, fmt.Errorf("failed to scan fee revenue: %w", err)
+		}
+		line.Total = roundCurrency(line.Total)
+		summary.Total += line.Total
+		summary.Lines = append(summary.Lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read fee revenue: %w", err)
+	}
+	summary.Total = roundCurrency(summary.Total)
+
+	err = s.db.QueryRow("SELECT COALESCE(balance, 0) FROM wallets WHERE id = ?", models.PlatformFeeWalletID).Scan(&summary.FeesBalance)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get platform fee balance: %w", err)
+	}
+	return summary, nil
+}
+
+func (s *FeeService) getFeeRule(ruleID string) (*models.FeeRule, error) {
+	row := s.db.QueryRow(`
+		SELECT id, name, transaction_type, payment_method, calculation, flat_fee, percentage, min_fee,
+			   max_fee, bands, is_active, created_by, updated_by, created_at, updated_at
+		FROM fee_rules WHERE id = ?
+	`, ruleID)
+	rule, err := scanFeeRule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("fee rule not found")
+	}
+	return rule, err
+}
+
+func (s *FeeService) auditRule(tx *sql.Tx, adminID string, rule *models.FeeRule, change string) error {
+	return NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ActorID:    &adminID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "fee_rule",
+		EntityID:   rule.ID,
+		Details: map[string]interface{}{
+			"change":          change,
+			"name":            rule.Name,
+			"transactionType": rule.TransactionType,
+			"paymentMethod":   rule.PaymentMethod,
+			"calculation":     rule.Calculation,
+			"flatFee":         rule.FlatFee,
+			"percentage":      rule.Percentage,
+			"minFee":          rule.MinFee,
+			"maxFee":          rule.MaxFee,
+			"bands":           rule.Bands,
+			"isActive":        rule.IsActive,
+		},
+	})
+}
+
+// applyFeeRuleRequest validates req and copies it onto rule
+func applyFeeRuleRequest(rule *models.FeeRule, req *models.FeeRuleRequest) error {
+	if req.TransactionType != "" && !isKnownTransactionType(req.TransactionType) {
+		return fmt.Errorf("unknown transaction type: %s", req.TransactionType)
+	}
+	if req.MaxFee != nil && *req.MaxFee < req.MinFee {
+		return fmt.Errorf("maxFee cannot be less than minFee")
+	}
+
+	calculation := models.FeeCalculation(req.Calculation)
+	switch calculation {
+	case models.FeeCalculationFlat:
+		if req.FlatFee <= 0 {
+			return fmt.Errorf("flat fees need a flatFee greater than 0")
+		}
+	case models.FeeCalculationPercentage:
+		if req.Percentage <= 0 {
+			return fmt.Errorf("percentage fees need a percentage greater than 0")
+		}
+	case models.FeeCalculationBanded:
+		if len(req.Bands) == 0 {
+			return fmt.Errorf("banded fees need at least one band")
+		}
+		sort.Slice(req.Bands, func(i, j int) bool { return req.Bands[i].MinAmount < req.Bands[j].MinAmount })
+		for i, band := range req.Bands {
+			if band.MaxAmount != nil && *band.MaxAmount <= band.MinAmount {
+				return fmt.Errorf("band %d: maxAmount must be greater than minAmount", i+1)
+			}
+			if i < len(req.Bands)-1 && (band.MaxAmount == nil || *band.MaxAmount > req.Bands[i+1].MinAmount) {
+				return fmt.Errorf("band %d overlaps the next band", i+1)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown fee calculation: %s", req.Calculation)
+	}
+
+	rule.Name = req.Name
+	rule.TransactionType = req.TransactionType
+	rule.PaymentMethod = req.PaymentMethod
+	rule.Calculation = calculation
+	rule.FlatFee = req.FlatFee
+	rule.Percentage = req.Percentage
+	rule.MinFee = req.MinFee
+	rule.MaxFee = req.MaxFee
+	rule.Bands = req.Bands
+	if calculation != models.FeeCalculationBanded {
+		rule.Bands = nil
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	return nil
+}
+
+// calculateFee finds the most specific active rule for the transaction type and payment
+// method and prices amount with it. No matching rule means no fee.
+func calculateFee(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, transactionType models.TransactionType, paymentMethod models.PaymentMethod, amount float64) (float64, *models.FeeRule, error) {
+	row := q.QueryRow(`
+		SELECT id, name, transaction_type, payment_method, calculation, flat_fee, percentage, min_fee,
+			   max_fee, bands, is_active, created_by, updated_by, created_at, updated_at
+		FROM fee_rules
+		WHERE is_active = TRUE
+		AND transaction_type IN (?, '') AND payment_method IN (?, '')
+		ORDER BY transaction_type = '', payment_method = '', updated_at DESC
+		LIMIT 1
+	`, transactionType, paymentMethod)
+	rule, err := scanFeeRule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var fee float64
+	switch rule.Calculation {
+	case models.FeeCalculationFlat:
+		fee = rule.FlatFee
+	case models.FeeCalculationPercentage:
+		fee = amount * rule.Percentage / 100
+	case models.FeeCalculationBanded:
+		for _, band := range rule.Bands {
+			if amount >= band.MinAmount && (band.MaxAmount == nil || amount < *band.MaxAmount) {
+				fee = band.FlatFee + amount*band.Percentage/100
+				break
+			}
+		}
+	}
+	fee = math.Max(fee, rule.MinFee)
+	if rule.MaxFee != nil {
+		fee = math.Min(fee, *rule.MaxFee)
+	}
+	return roundCurrency(fee), rule, nil
+}
+
+// limitStatus works out the user's tier limits, tightened by any lower limit set on
+// their personal wallet, and how much of them this day and month have used
+func limitStatus(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, userID string, amount float64) (*models.LimitStatus, error) {
+	var userTier int
+	err := q.QueryRow("SELECT COALESCE(kyc_tier, 0) FROM users WHERE id = ?", userID).Scan(&userTier)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user tier: %w", err)
+	}
+
+	status := &models.LimitStatus{}
+	err = q.QueryRow(`
+		SELECT tier, name, single_transaction_limit, daily_limit, monthly_limit
+		FROM transaction_limit_tiers WHERE tier <= ? ORDER BY tier DESC LIMIT 1
+	`, userTier).Scan(&status.Tier, &status.TierName, &status.SingleTransactionLimit, &status.DailyLimit, &status.MonthlyLimit)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("no transaction limits are configured for tier %d", userTier)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tier limits: %w", err)
+	}
+
+	var walletDaily, walletMonthly sql.NullFloat64
+	err = q.QueryRow("SELECT daily_limit, monthly_limit FROM wallets WHERE owner_id = ? AND type = 'personal'", userID).
+		Scan(&walletDaily, &walletMonthly)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get wallet limits: %w", err)
+	}
+	if walletDaily.Valid && walletDaily.Float64 > 0 && walletDaily.Float64 < status.DailyLimit {
+		status.DailyLimit = walletDaily.Float64
+	}
+	if walletMonthly.Valid && walletMonthly.Float64 > 0 && walletMonthly.Float64 < status.MonthlyLimit {
+		status.MonthlyLimit = walletMonthly.Float64
+	}
+
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	err = q.QueryRow(`
+		SELECT COALESCE(SUM(CASE WHEN created_at >= ? THEN amount ELSE 0 END), 0), COALESCE(SUM(amount), 0)
+		FROM transaction_limit_usage WHERE user_id = ? AND created_at >= ?
+	`, startOfDay, userID, startOfMonth).Scan(&status.DailyUsed, &status.MonthlyUsed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get limit usage: %w", err)
+	}
+	status.DailyUsed = roundCurrency(status.DailyUsed)
+	status.MonthlyUsed = roundCurrency(status.MonthlyUsed)
+	status.DailyRemaining = roundCurrency(math.Max(0, status.DailyLimit-status.DailyUsed))
+	status.MonthlyRemaining = roundCurrency(math.Max(0, status.MonthlyLimit-status.MonthlyUsed))
+
+	status.Allowed = true
+	switch {
+	case amount > status.SingleTransactionLimit:
+		status.Reason = fmt.Sprintf("amount exceeds the single transaction limit of KES %.2f", status.SingleTransactionLimit)
+	case amount > status.DailyRemaining:
+		status.Reason = fmt.Sprintf("amount exceeds your remaining daily limit of KES %.2f", status.DailyRemaining)
+	case amount > status.MonthlyRemaining:
+		status.Reason = fmt.Sprintf("amount exceeds your remaining monthly limit of KES %.2f", status.MonthlyRemaining)
+	}
+	if status.Reason != "" {
+		status.Allowed = false
+	}
+	return status, nil
+}
+
+// reserveTransactionLimit counts amount against the user's limits inside tx, so it is
+// released again if the transaction rolls back
+func reserveTransactionLimit(tx *sql.Tx, userID, reference string, amount float64) error {
+	status, err := limitStatus(tx, userID, amount)
+	if err != nil {
+		return err
+	}
+	if !status.Allowed {
+		return fmt.Errorf("%w: %s", errTransactionLimitExceeded, status.Reason)
+	}
+
+	var ref *string
+	if reference != "" {
+		ref = &reference
+	}
+	_, err = tx.Exec(`
+		INSERT INTO transaction_limit_usage (id, user_id, reference, amount, created_at) VALUES (?, ?, ?, ?, ?)
+	`, uuid.New().String(), userID, ref, amount, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record limit usage: %w", err)
+	}
+	return nil
+}
+
+// postFeeRevenue moves a fee already taken from the payer's wallet into the platform fee
+// account and records it as a fee transaction against the charged transaction
+func (s *FeeService) postFeeRevenue(tx *sql.Tx, charged *models.Transaction) error {
+	now := time.Now()
+	_, err := tx.Exec(`
+		INSERT INTO wallets (id, owner_id, type, balance, currency, is_active, is_locked, created_at, updated_at)
+		VALUES (?, 'platform', 'platform', 0, 'KES', TRUE, FALSE, ?, ?)
+		ON CONFLICT(id) DO NOTHING
+	`, models.PlatformFeeWalletID, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to open platform fee wallet: %w", err)
+	}
+	_, err = tx.Exec("UPDATE wallets SET balance = balance + ?, updated_at = ? WHERE id = ?", charged.Fees, now, models.PlatformFeeWalletID)
+	if err != nil {
+		return fmt.Errorf("failed to credit platform fee wallet: %w", err)
+	}
+
+	transactionID := uuid.New().String()
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"chargedTransactionId":   charged.ID,
+		"chargedTransactionType": charged.Type,
+		"paymentMethod":          charged.PaymentMethod,
+	})
+	description := fmt.Sprintf("Fee for %s", charged.Type)
+	_, err = tx.Exec(`
+		INSERT INTO transactions (
+			id, from_wallet_id, to_wallet_id, type, status, amount, currency, description,
+			reference, payment_method, metadata, initiated_by, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, 'KES', ?, ?, ?, ?, ?, ?, ?)
+	`, transactionID, charged.FromWalletID, models.PlatformFeeWalletID, models.TransactionTypeFee, models.TransactionStatusCompleted,
+		charged.Fees, description, charged.ID, models.PaymentMethodWalletTransfer, string(metadata), charged.InitiatedBy, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to record fee: %w", err)
+	}
+	return NewAuditService(s.db).RecordTransaction(tx, "", transactionID)
+}
+
+// isOutgoingTransactionType reports whether a transaction of this type takes money out
+// of the initiating user's wallet and so counts against their limits
+func isOutgoingTransactionType(transactionType models.TransactionType) bool {
+	switch transactionType {
+	case models.TransactionTypeWithdrawal, models.TransactionTypeTransfer, models.TransactionTypeContribution,
+		models.TransactionTypeLoanRepayment, models.TransactionTypePurchase:
+		return true
+	}
+	return false
+}
+
+func isKnownTransactionType(transactionType string) bool {
+	switch models.TransactionType(transactionType) {
+	case models.TransactionTypeDeposit, models.TransactionTypeWithdrawal, models.TransactionTypeTransfer,
+		models.TransactionTypeContribution, models.TransactionTypeLoan, models.TransactionTypeLoanRepayment,
+		models.TransactionTypePurchase, models.TransactionTypeRefund, models.TransactionTypeFee:
+		return true
+	}
+	return false
+}
+
+func scanFeeRule(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.FeeRule, error) {
+	rule := &models.FeeRule{}
+	var maxFee sql.NullFloat64
+	var updatedBy sql.NullString
+	var bands string
+	err := row.Scan(&rule.ID, &rule.Name, &rule.TransactionType, &rule.PaymentMethod, &rule.Calculation, &rule.FlatFee,
+		&rule.Percentage, &rule.MinFee, &maxFee, &bands, &rule.IsActive, &rule.CreatedBy, &updatedBy,
+		&rule.CreatedAt, &rule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan fee rule: %w", err)
+	}
+	if maxFee.Valid {
+		rule.MaxFee = &maxFee.Float64
+	}
+	if updatedBy.Valid {
+		rule.UpdatedBy = &updatedBy.String
+	}
+	if bands != "" && bands != "[]" {
+		if err := json.Unmarshal([]byte(bands), &rule.Bands); err != nil {
+			return nil, fmt.Errorf("failed to parse fee bands: %w", err)
+		}
+	}
+	return rule, nil
+}
@@ -0,0 +1,263 @@
+package services_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type FeeTestSuite struct {
+	suite.Suite
+	testDB   *helpers.TestDatabase
+	db       *sql.DB
+	service  *services.FeeService
+	wallets  *services.WalletService
+	adminID  string
+	memberID string
+	walletID string
+}
+
+func (suite *FeeTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewFeeService(suite.db)
+	suite.wallets = services.NewWalletService(suite.db)
+
+	suite.adminID = suite.testDB.AddTestUser(suite.T(), "Admin")
+	suite.memberID = suite.testDB.AddTestUser(suite.T(), "Member")
+	suite.walletID = "wallet-personal-" + suite.memberID
+	_, err := suite.db.Exec(`
+		INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'personal', ?, 500000)
+	`, suite.walletID, suite.memberID)
+	suite.Require().NoError(err)
+}
+
+func (suite *FeeTestSuite) fee(transactionType models.TransactionType, method models.PaymentMethod, amount float64) float64 {
+	fee, err := suite.service.CalculateFee(transactionType, method, amount)
+	suite.Require().NoError(err)
+	return fee
+}
+
+func (suite *FeeTestSuite) withdraw(amount float64) (*models.Transaction, error) {
+	return suite.wallets.CreateTransaction(&models.TransactionCreation{
+		FromWalletID:  &suite.walletID,
+		Type:          models.TransactionTypeWithdrawal,
+		Amount:        amount,
+		PaymentMethod: models.PaymentMethodMpesa,
+		Metadata:      map[string]interface{}{},
+	}, suite.memberID)
+}
+
+func (suite *FeeTestSuite) TestMostSpecificRuleApplies() {
+	suite.Equal(10.0, suite.fee(models.TransactionTypeWithdrawal, models.PaymentMethodMpesa, 100), "minimum fee")
+	suite.Equal(20.0, suite.fee(models.TransactionTypeWithdrawal, models.PaymentMethodMpesa, 1000))
+	suite.Equal(50.0, suite.fee(models.TransactionTypeWithdrawal, models.PaymentMethod("bank"), 5000))
+	suite.Equal(5.0, suite.fee(models.TransactionTypeWithdrawal, models.PaymentMethodCash, 5000))
+	suite.Equal(2.0, suite.fee(models.TransactionTypeTransfer, models.PaymentMethodWalletTransfer, 5000))
+	suite.Equal(0.0, suite.fee(models.TransactionTypeDeposit, models.PaymentMethodMpesa, 5000))
+
+	maxFee := 100.0
+	upper := 1000.0
+	rule, err := suite.service.CreateFeeRule(suite.adminID, &models.FeeRuleRequest{
+		Name:            "Banded wallet transfers",
+		TransactionType: "transfer",
+		PaymentMethod:   "wallet_transfer",
+		Calculation:     "banded",
+		MaxFee:          &maxFee,
+		Bands: []models.FeeBand{
+			{MinAmount: 1000, Percentage: 0.5},
+			{MinAmount: 0, MaxAmount: &upper},
+		},
+	})
+	suite.Require().NoError(err)
+	suite.Equal(0.0, rule.Bands[0].MinAmount, "bands are kept in order")
+
+	suite.Equal(0.0, suite.fee(models.TransactionTypeTransfer, models.PaymentMethodWalletTransfer, 500))
+	suite.Equal(25.0, suite.fee(models.TransactionTypeTransfer, models.PaymentMethodWalletTransfer, 5000))
+	suite.Equal(100.0, suite.fee(models.TransactionTypeTransfer, models.PaymentMethodWalletTransfer, 100000), "capped at maxFee")
+	suite.Equal(2.0, suite.fee(models.TransactionTypeTransfer, models.PaymentMethodMpesa, 5000), "other methods keep the generic rule")
+
+	_, err = suite.service.CreateFeeRule(suite.adminID, &models.FeeRuleRequest{
+		Name:        "Overlapping",
+		Calculation: "banded",
+		Bands:       []models.FeeBand{{MinAmount: 0, MaxAmount: &maxFee}, {MinAmount: 50, FlatFee: 1}},
+	})
+	suite.Require().Error(err)
+	_, err = suite.service.CreateFeeRule(suite.adminID, &models.FeeRuleRequest{
+		Name: "Unknown", TransactionType: "bribe", Calculation: "flat", FlatFee: 1,
+	})
+	suite.Require().Error(err)
+
+	suite.Require().NoError(suite.service.DeactivateFeeRule(rule.ID, suite.adminID))
+	suite.Equal(2.0, suite.fee(models.TransactionTypeTransfer, models.PaymentMethodWalletTransfer, 5000))
+
+	rules, err := suite.service.GetFeeRules(false)
+	suite.Require().NoError(err)
+	suite.Len(rules, 4)
+	rules, err = suite.service.GetFeeRules(true)
+	suite.Require().NoError(err)
+	suite.Len(rules, 5)
+
+	var audited int
+	err = suite.db.QueryRow("SELECT COUNT(*) FROM audit_log WHERE entity_type = 'fee_rule' AND entity_id = ?", rule.ID).Scan(&audited)
+	suite.Require().NoError(err)
+	suite.Equal(2, audited)
+}
+
+func (suite *FeeTestSuite) TestQuoteIncludesLimits() {
+	quote, err := suite.service.QuoteFee(suite.memberID, models.TransactionTypeWithdrawal, models.PaymentMethodMpesa, 80000)
+	suite.Require().NoError(err)
+	suite.Equal(1600.0, quote.Fee)
+	suite.Equal(81600.0, quote.TotalDebit)
+	suite.Require().NotNil(quote.RuleID)
+	suite.Equal("fee-default-withdrawal-mpesa", *quote.RuleID)
+	suite.Require().NotNil(quote.Limits)
+	suite.False(quote.Limits.Allowed)
+	suite.Contains(quote.Limits.Reason, "single transaction limit")
+
+	quote, err = suite.service.QuoteFee(suite.memberID, models.TransactionTypeDeposit, models.PaymentMethodMpesa, 80000)
+	suite.Require().NoError(err)
+	suite.Nil(quote.Limits, "deposits are not limited")
+
+	_, err = suite.service.QuoteFee(suite.memberID, models.TransactionType("bribe"), "", 100)
+	suite.Require().Error(err)
+}
+
+func (suite *FeeTestSuite) TestLimitsFollowTierAndWallet() {
+	_, err := suite.withdraw(60000)
+	suite.Require().NoError(err)
+	_, err = suite.withdraw(60000)
+	suite.Require().NoError(err)
+	_, err = suite.withdraw(60000)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "daily limit")
+
+	status, err := suite.service.GetLimitStatus(suite.memberID, 0)
+	suite.Require().NoError(err)
+	suite.Equal(0, status.Tier)
+	suite.Equal(120000.0, status.DailyUsed)
+	suite.Equal(30000.0, status.DailyRemaining)
+
+	_, err = suite.db.Exec("UPDATE users SET kyc_tier = 2 WHERE id = ?", suite.memberID)
+	suite.Require().NoError(err)
+	_, err = suite.withdraw(60000)
+	suite.Require().NoError(err, "a higher tier raises the limits")
+
+	_, err = suite.db.Exec("UPDATE wallets SET daily_limit = 200000 WHERE id = ?", suite.walletID)
+	suite.Require().NoError(err)
+	_, err = suite.withdraw(60000)
+	suite.Require().Error(err, "a lower wallet limit still applies")
+
+	_, err = suite.service.UpdateLimitTier(0, suite.adminID, &models.UpdateLimitTierRequest{
+		SingleTransactionLimit: 5000, DailyLimit: 1000, MonthlyLimit: 10000,
+	})
+	suite.Require().Error(err)
+	tier, err := suite.service.UpdateLimitTier(0, suite.adminID, &models.UpdateLimitTierRequest{
+		SingleTransactionLimit: 1000, DailyLimit: 5000, MonthlyLimit: 10000,
+	})
+	suite.Require().NoError(err)
+	suite.Equal("Unverified", tier.Name)
+
+	tiers, err := suite.service.GetLimitTiers()
+	suite.Require().NoError(err)
+	suite.Require().Len(tiers, 4)
+	suite.Equal(1000.0, tiers[0].SingleTransactionLimit)
+}
+
+func (suite *FeeTestSuite) TestFeesArePostedToPlatformAccount() {
+	transaction, err := suite.withdraw(1000)
+	suite.Require().NoError(err)
+	suite.Equal(20.0, transaction.Fees)
+	suite.Require().NoError(suite.wallets.ProcessTransaction(transaction.ID))
+
+	var balance, platform float64
+	suite.Require().NoError(suite.db.QueryRow("SELECT balance FROM wallets WHERE id = ?", suite.walletID).Scan(&balance))
+	suite.Require().NoError(suite.db.QueryRow("SELECT balance FROM wallets WHERE id = ?", models.PlatformFeeWalletID).Scan(&platform))
+	suite.Equal(498980.0, balance)
+	suite.Equal(20.0, platform)
+
+	var reference string
+	err = suite.db.QueryRow("SELECT reference FROM transactions WHERE type = 'fee' AND to_wallet_id = ?", models.PlatformFeeWalletID).Scan(&reference)
+	suite.Require().NoError(err)
+	suite.Equal(transaction.ID, reference)
+
+	revenue, err := suite.service.GetFeeRevenue(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	suite.Require().NoError(err)
+	suite.Equal(20.0, revenue.Total)
+	suite.Equal(20.0, revenue.FeesBalance)
+	suite.Require().Len(revenue.Lines, 1)
+	suite.Equal("withdrawal", revenue.Lines[0].TransactionType)
+}
+
+func (suite *FeeTestSuite) TestStandingOrdersRetryWhenOverLimit() {
+	chairID := suite.testDB.AddTestUser(suite.T(), "Chair")
+	chamaID := suite.testDB.AddTestChama(suite.T(), chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), chamaID, suite.memberID, "member")
+
+	_, err := suite.service.UpdateLimitTier(0, suite.adminID, &models.UpdateLimitTierRequest{
+		SingleTransactionLimit: 1000, DailyLimit: 1000, MonthlyLimit: 1000,
+	})
+	suite.Require().NoError(err)
+
+	orders := services.NewStandingOrderService(suite.db)
+	day := 5
+	order, err := orders.CreateStandingOrder(suite.memberID, &models.CreateStandingOrderRequest{
+		TargetType: "chama",
+		ChamaID:    chamaID,
+		Amount:     2000,
+		Frequency:  "monthly",
+		DayOfMonth: &day,
+	})
+	suite.Require().NoError(err)
+	_, err = suite.db.Exec("UPDATE standing_orders SET next_run_at = ? WHERE id = ?", time.Now().Add(-time.Minute), order.ID)
+	suite.Require().NoError(err)
+	orders.ProcessDueStandingOrders()
+
+	retrying, err := orders.GetStandingOrder(order.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(models.StandingOrderStatusActive, retrying.Status)
+	suite.Equal(1, retrying.RetryCount)
+	suite.Require().NotNil(retrying.LastFailureReason)
+	suite.Contains(*retrying.LastFailureReason, "transaction limit exceeded")
+}
+
+func (suite *FeeTestSuite) TestWalletDebitsShareTheDailyLimit() {
+	chairID := suite.testDB.AddTestUser(suite.T(), "Chair")
+	chamaID := suite.testDB.AddTestChama(suite.T(), chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), chamaID, suite.memberID, "member")
+
+	_, err := suite.service.UpdateLimitTier(0, suite.adminID, &models.UpdateLimitTierRequest{
+		SingleTransactionLimit: 1000, DailyLimit: 1000, MonthlyLimit: 10000,
+	})
+	suite.Require().NoError(err)
+
+	welfare := services.NewWelfareService(suite.db)
+	_, err = welfare.ContributeToFund(chamaID, suite.memberID, 600)
+	suite.Require().NoError(err)
+
+	savings := services.NewSavingsService(suite.db)
+	goal, err := savings.CreateGoal(suite.memberID, &models.CreateSavingsGoalRequest{Name: "School fees", TargetAmount: 5000})
+	suite.Require().NoError(err)
+	_, err = savings.DepositToGoal(goal.ID, suite.memberID, 600)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "transaction limit exceeded")
+
+	_, err = savings.CreateFixedSavings(suite.memberID, &models.CreateFixedSavingsRequest{Amount: 400, TermMonths: 6})
+	suite.Require().NoError(err)
+	_, err = welfare.ContributeToFund(chamaID, suite.memberID, 100)
+	suite.Error(err, "the day's limit is used up across welfare and savings")
+
+	status, err := suite.service.GetLimitStatus(suite.memberID, 0)
+	suite.Require().NoError(err)
+	suite.Equal(1000.0, status.DailyUsed)
+}
+
+func TestFeeSuite(t *testing.T) {
+	suite.Run(t, new(FeeTestSuite))
+}
@@ -0,0 +1,408 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// periodCloseSource is the journal entry source of year-end closing entries
+const periodCloseSource = "period_close"
+
+// FinancialPeriodService closes and reopens a chama's monthly and annual financial
+// periods. Closing snapshots the trial balance and locks the period: database
+// triggers reject any transaction, loan, repayment or dividend payment dated inside
+// it until an official reopens the period. Closing a year also records the entry
+// that carries its income, expenses and dividends into retained earnings.
+type FinancialPeriodService struct {
+	db         *sql.DB
+	accounting *AccountingService
+}
+
+// NewFinancialPeriodService creates a new financial period service
+func NewFinancialPeriodService(db *sql.DB) *FinancialPeriodService {
+	return &FinancialPeriodService{
+		db:         db,
+		accounting: NewAccountingService(db),
+	}
+}
+
+// GetPeriods lists the chama's closed and reopened periods, latest first
+func (s *FinancialPeriodService) GetPeriods(chamaID, userID string) ([]models.FinancialPeriod, error) {
+	if !s.accounting.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+financialPeriodColumns+` FROM chama_financial_periods
+		WHERE chama_id = ?
+		ORDER BY start_date DESC, period_type
+	`, chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get financial periods: %w", err)
+	}
+	defer rows.Close()
+
+	periods := []models.FinancialPeriod{}
+	for rows.Next() {
+		period, err := scanFinancialPeriod(rows)
+		if err != nil {
+			return nil, err
+		}
+		// The listing leaves out the snapshots; fetch a period for its detail
+		period.ClosingBalances = nil
+		period.ClosingEntry = nil
+		periods = append(periods, *period)
+	}
+	return periods, nil
+}
+
+// GetPeriod returns a period with its closing balances and closing entry
+func (s *FinancialPeriodService) GetPeriod(chamaID, periodID, userID string) (*models.FinancialPeriod, error) {
+	if !s.accounting.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	return s.getPeriod(chamaID, periodID)
+}
+
+// ClosePeriod closes a month or year. Only the treasurer closes the books, and only
+// once the period has ended. Periods of the same type close in order, so a reopened
+// period must be closed again before any later one.
+func (s *FinancialPeriodService) ClosePeriod(chamaID, userID string, req *models.CloseFinancialPeriodRequest) (*models.FinancialPeriod, error) {
+	if !s.isTreasurer(userID, chamaID) {
+		return nil, fmt.Errorf("only the chama treasurer can close a financial period")
+	}
+
+	periodType := models.FinancialPeriodType(req.PeriodType)
+	var start, end time.Time
+	switch periodType {
+	case models.FinancialPeriodMonthly:
+		if req.Month == 0 {
+			return nil, fmt.Errorf("month is required to close a monthly period")
+		}
+		start = time.Date(req.Year, time.Month(req.Month), 1, 0, 0, 0, 0, utils.EATLocation)
+		end = start.AddDate(0, 1, 0)
+	case models.FinancialPeriodAnnual:
+		start = time.Date(req.Year, time.January, 1, 0, 0, 0, 0, utils.EATLocation)
+		end = start.AddDate(1, 0, 0)
+	default:
+		return nil, fmt.Errorf("unknown period type %s", req.PeriodType)
+	}
+	if end.After(utils.NowEAT()) {
+		return nil, fmt.Errorf("the period has not ended yet")
+	}
+
+	periodID := uuid.New().String()
+	rows, err := s.db.Query(`
+		SELECT id, start_date, status FROM chama_financial_periods WHERE chama_id = ? AND period_type = ?
+	`, chamaID, periodType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get financial periods: %w", err)
+	}
+	for rows.Next() {
+		var id string
+		var periodStart time.Time
+		var status models.FinancialPeriodStatus
+		if err := rows.Scan(&id, &periodStart, &status); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan financial period: %w", err)
+		}
+		switch {
+		case periodStart.Equal(start) && status == models.FinancialPeriodClosed:
+			rows.Close()
+			return nil, fmt.Errorf("the period is already closed")
+		case periodStart.Equal(start):
+			periodID = id
+		case periodStart.Before(start) && status == models.FinancialPeriodReopened:
+			rows.Close()
+			return nil, fmt.Errorf("close the reopened period starting %s first", periodStart.In(utils.EATLocation).Format("2006-01-02"))
+		}
+	}
+	rows.Close()
+
+	ledger, err := s.accounting.loadLedger(chamaID, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var netIncome float64
+	for _, balance := range ledger.balances(start, false) {
+		if balance.kind == models.LedgerAccountIncome || balance.kind == models.LedgerAccountExpense {
+			netIncome += balance.credits - balance.debits
+		}
+	}
+	netIncome = roundCurrency(netIncome)
+
+	retainedEarnings := ledger.mappings[models.LedgerEntryRetainedEarnings]
+	retainedBalance := func() float64 {
+		for _, balance := range ledger.balances(time.Time{}, true) {
+			if balance.code == retainedEarnings.Code {
+				return roundCurrency(balance.credits - balance.debits)
+			}
+		}
+		return 0
+	}
+	openingRetained := retainedBalance()
+
+	var closingEntry *models.JournalEntry
+	if periodType == models.FinancialPeriodAnnual {
+		closingEntry = s.closingEntry(ledger, periodID, start, end)
+		if closingEntry != nil {
+			ledger.entries = append(ledger.entries, *closingEntry)
+		}
+	}
+	closingRetained := retainedBalance()
+	closingBalances, _, _ := trialBalanceLines(ledger.balances(time.Time{}, true))
+
+	balancesJSON, err := json.Marshal(closingBalances)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize closing balances: %w", err)
+	}
+	var entryJSON interface{}
+	if closingEntry != nil {
+		data, err := json.Marshal(closingEntry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize closing entry: %w", err)
+		}
+		entryJSON = string(data)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		INSERT INTO chama_financial_periods (
+			id, chama_id, period_type, start_date, end_date, status, closed_by, closed_at, net_income,
+			opening_retained_earnings, closing_retained_earnings, closing_balances, closing_entry, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(chama_id, period_type, start_date) DO UPDATE SET
+			status = excluded.status, closed_by = excluded.closed_by, closed_at = excluded.closed_at,
+			net_income = excluded.net_income, opening_retained_earnings = excluded.opening_retained_earnings,
+			closing_retained_earnings = excluded.closing_retained_earnings, closing_balances = excluded.closing_balances,
+			closing_entry = excluded.closing_entry, updated_at = excluded.updated_at
+		WHERE chama_financial_periods.status <> 'closed'
+	`, periodID, chamaID, periodType, start, end, models.FinancialPeriodClosed, userID, now, netIncome,
+		openingRetained, closingRetained, string(balancesJSON), entryJSON, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close financial period: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("the period is already closed")
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionApproval,
+		EntityType: "financial_period",
+		EntityID:   periodID,
+		Amount:     &netIncome,
+		Details: map[string]interface{}{
+			"event":                   "closed",
+			"periodType":              periodType,
+			"startDate":               start.Format("2006-01-02"),
+			"endDate":                 end.AddDate(0, 0, -1).Format("2006-01-02"),
+			"closingRetainedEarnings": closingRetained,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.getPeriod(chamaID, periodID)
+}
+
+// ReopenPeriod unlocks a closed period so records dated inside it can be corrected.
+// Any official may reopen a period, but must give a reason, which is kept in the
+// audit log. Later closed periods of the same type must be reopened first, since
+// their balances carry this one's forward.
+func (s *FinancialPeriodService) ReopenPeriod(chamaID, periodID, userID, reason string) (*models.FinancialPeriod, error) {
+	if !s.accounting.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can reopen a financial period")
+	}
+	period, err := s.getPeriod(chamaID, periodID)
+	if err != nil {
+		return nil, err
+	}
+	if period.Status != models.FinancialPeriodClosed {
+		return nil, fmt.Errorf("the period is not closed")
+	}
+
+	rows, err := s.db.Query(`
+		SELECT start_date FROM chama_financial_periods WHERE chama_id = ? AND period_type = ? AND status = ?
+	`, chamaID, period.PeriodType, models.FinancialPeriodClosed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get financial periods: %w", err)
+	}
+	for rows.Next() {
+		var periodStart time.Time
+		if err := rows.Scan(&periodStart); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan financial period: %w", err)
+		}
+		if periodStart.After(period.StartDate) {
+			rows.Close()
+			return nil, fmt.Errorf("reopen the later period starting %s first", periodStart.In(utils.EATLocation).Format("2006-01-02"))
+		}
+	}
+	rows.Close()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE chama_financial_periods
+		SET status = ?, reopened_by = ?, reopened_at = ?, reopen_reason = ?, closing_entry = NULL, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, models.FinancialPeriodReopened, userID, now, reason, now, periodID, models.FinancialPeriodClosed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reopen financial period: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("the period is not closed")
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "financial_period",
+		EntityID:   periodID,
+		Details: map[string]interface{}{
+			"event":      "reopened",
+			"periodType": period.PeriodType,
+			"startDate":  period.StartDate.In(utils.EATLocation).Format("2006-01-02"),
+			"reason":     reason,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.getPeriod(chamaID, periodID)
+}
+
+// closingEntry zeroes every income and expense account, and the dividends account,
+// against retained earnings. It is dated the last second of the year so the year's
+// closing balances include it. Years already closed have zero balances left; the
+// results of earlier years that were never closed are carried forward with this one.
+func (s *FinancialPeriodService) closingEntry(ledger *chamaLedger, periodID string, start, end time.Time) *models.JournalEntry {
+	dividends := ledger.mappings[models.LedgerEntryDividend]
+	retainedEarnings := ledger.mappings[models.LedgerEntryRetainedEarnings]
+
+	entry := &models.JournalEntry{
+		ID:          periodCloseSource + ":" + periodID,
+		Date:        end.Add(-time.Second),
+		EntryType:   models.LedgerEntryRetainedEarnings,
+		Source:      periodCloseSource,
+		SourceID:    periodID,
+		Description: fmt.Sprintf("Closing entry for %d", start.Year()),
+	}
+	var retained float64
+	for _, balance := range ledger.balances(time.Time{}, true) {
+		if balance.kind != models.LedgerAccountIncome && balance.kind != models.LedgerAccountExpense && balance.code != dividends.Code {
+			continue
+		}
+		net := roundCurrency(balance.debits - balance.credits)
+		if net == 0 {
+			continue
+		}
+		line := models.JournalLine{AccountCode: balance.code, AccountName: balance.name, AccountType: balance.kind}
+		if net > 0 {
+			line.Credit = net
+		} else {
+			line.Debit = -net
+		}
+		retained -= net
+		entry.Lines = append(entry.Lines, line)
+	}
+	if len(entry.Lines) == 0 {
+		return nil
+	}
+
+	line := models.JournalLine{AccountCode: retainedEarnings.Code, AccountName: retainedEarnings.Name, AccountType: retainedEarnings.Type}
+	if retained = roundCurrency(retained); retained >= 0 {
+		line.Credit = retained
+	} else {
+		line.Debit = -retained
+	}
+	entry.Lines = append(entry.Lines, line)
+	return entry
+}
+
+const financialPeriodColumns = `id, chama_id, period_type, start_date, end_date, status, closed_by, closed_at,
+	reopened_by, reopened_at, reopen_reason, net_income, opening_retained_earnings, closing_retained_earnings,
+	closing_balances, closing_entry, created_at, updated_at`
+
+func (s *FinancialPeriodService) getPeriod(chamaID, periodID string) (*models.FinancialPeriod, error) {
+	period, err := scanFinancialPeriod(s.db.QueryRow(`
+		SELECT `+financialPeriodColumns+` FROM chama_financial_periods WHERE id = ? AND chama_id = ?
+	`, periodID, chamaID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("financial period not found")
+	}
+	return period, err
+}
+
+func scanFinancialPeriod(row interface{ Scan(...interface{}) error }) (*models.FinancialPeriod, error) {
+	var period models.FinancialPeriod
+	var reopenedBy, reopenReason, closingBalances, closingEntry sql.NullString
+	var reopenedAt sql.NullTime
+	err := row.Scan(&period.ID, &period.ChamaID, &period.PeriodType, &period.StartDate, &period.EndDate,
+		&period.Status, &period.ClosedBy, &period.ClosedAt, &reopenedBy, &reopenedAt, &reopenReason,
+		&period.NetIncome, &period.OpeningRetainedEarnings, &period.ClosingRetainedEarnings,
+		&closingBalances, &closingEntry, &period.CreatedAt, &period.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan financial period: %w", err)
+	}
+
+	period.ReopenedBy = nullStringPtr(reopenedBy)
+	period.ReopenReason = nullStringPtr(reopenReason)
+	if reopenedAt.Valid {
+		period.ReopenedAt = &reopenedAt.Time
+	}
+	if closingBalances.Valid {
+		if err := json.Unmarshal([]byte(closingBalances.String), &period.ClosingBalances); err != nil {
+			return nil, fmt.Errorf("failed to parse closing balances: %w", err)
+		}
+	}
+	if closingEntry.Valid {
+		period.ClosingEntry = &models.JournalEntry{}
+		if err := json.Unmarshal([]byte(closingEntry.String), period.ClosingEntry); err != nil {
+			return nil, fmt.Errorf("failed to parse closing entry: %w", err)
+		}
+	}
+	return &period, nil
+}
+
+func (s *FinancialPeriodService) isTreasurer(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM chama_members
+		WHERE user_id = ? AND chama_id = ? AND is_active = TRUE AND role = 'treasurer'
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
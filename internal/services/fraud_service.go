@@ -0,0 +1,871 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/utils"
+)
+
+var (
+	// errTransactionHeld is returned when an outgoing transaction waits for admin review
+	errTransactionHeld = errors.New("transaction held for review")
+	// errTransactionBlocked is returned when the rules engine refuses a transaction
+	errTransactionBlocked = errors.New("transaction blocked")
+)
+
+const (
+	// fraudHoldScore and fraudBlockScore are the combined rule scores at which a
+	// transaction is held or blocked even though no single rule asked for it
+	fraudHoldScore  = 60
+	fraudBlockScore = 100
+
+	// fraudReleaseWindow is how long a member has to retry a transaction an admin released
+	fraudReleaseWindow = 24 * time.Hour
+)
+
+// fraudTimeLayout is how the SQLite driver writes time.Time values
+const fraudTimeLayout = "2006-01-02 15:04:05.999999999-07:00"
+
+// FraudService runs the fraud rules over outgoing transactions and manages the review
+// queue for the transactions they hold
+type FraudService struct {
+	db *sql.DB
+}
+
+// NewFraudService creates a new fraud service
+func NewFraudService(db *sql.DB) *FraudService {
+	return &FraudService{db: db}
+}
+
+// Screen evaluates an outgoing transaction before it is made. Handlers that move money
+// out of a wallet themselves call it before opening their transaction, then Record the
+// evaluation inside it.
+func (s *FraudService) Screen(check *models.FraudCheck) (*models.FraudEvaluation, error) {
+	return screenOutgoing(s.db, check)
+}
+
+// Record saves an allowed evaluation against the transaction it let through
+func (s *FraudService) Record(tx *sql.Tx, evaluation *models.FraudEvaluation, transactionID string) error {
+	return recordFraudEvaluation(tx, evaluation, transactionID)
+}
+
+// IsHeld reports whether a pending transaction is waiting for fraud review
+func (s *FraudService) IsHeld(transactionID string) (bool, error) {
+	var held bool
+	err := s.db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM fraud_evaluations WHERE transaction_id = ? AND status = ?)
+	`, transactionID, models.FraudStatusPendingReview).Scan(&held)
+	if err != nil {
+		return false, fmt.Errorf("failed to check fraud review: %w", err)
+	}
+	return held, nil
+}
+
+// GetReviewQueue lists evaluations in a status, oldest first. It defaults to the
+// transactions waiting for review.
+func (s *FraudService) GetReviewQueue(status string, limit, offset int) ([]*models.FraudEvaluation, error) {
+	if status == "" {
+		status = string(models.FraudStatusPendingReview)
+	}
+	switch models.FraudEvaluationStatus(status) {
+	case models.FraudStatusCleared, models.FraudStatusPendingReview, models.FraudStatusReleased,
+		models.FraudStatusRejected, models.FraudStatusBlocked:
+	default:
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+fraudEvaluationColumns+`
+		FROM fraud_evaluations e
+		LEFT JOIN users u ON u.id = e.user_id
+		WHERE e.status = ?
+		ORDER BY e.created_at ASC
+		LIMIT ? OFFSET ?
+	`, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fraud review queue: %w", err)
+	}
+	defer rows.Close()
+
+	evaluations := []*models.FraudEvaluation{}
+	for rows.Next() {
+		evaluation, err := scanFraudEvaluation(rows)
+		if err != nil {
+			return nil, err
+		}
+		evaluations = append(evaluations, evaluation)
+	}
+	return evaluations, nil
+}
+
+// GetEvaluation returns one evaluation with the rules that matched
+func (s *FraudService) GetEvaluation(evaluationID string) (*models.FraudEvaluation, error) {
+	return getFraudEvaluation(s.db, evaluationID)
+}
+
+// ReleaseEvaluation lets a held transaction go ahead. A pending transaction can then be
+// processed; one that was refused may be retried by the member within a day.
+func (s *FraudService) ReleaseEvaluation(evaluationID, reviewerID string, req *models.FraudReviewRequest) (*models.FraudEvaluation, error) {
+	return s.review(evaluationID, reviewerID, models.FraudStatusReleased, strings.TrimSpace(req.Notes))
+}
+
+// RejectEvaluation confirms a held transaction as fraudulent and cancels it if it is
+// still pending
+func (s *FraudService) RejectEvaluation(evaluationID, reviewerID string, req *models.FraudReviewRequest) (*models.FraudEvaluation, error) {
+	notes := strings.TrimSpace(req.Notes)
+	if notes == "" {
+		return nil, fmt.Errorf("notes are required to reject a held transaction")
+	}
+	return s.review(evaluationID, reviewerID, models.FraudStatusRejected, notes)
+}
+
+func (s *FraudService) review(evaluationID, reviewerID string, status models.FraudEvaluationStatus, notes string) (*models.FraudEvaluation, error) {
+	evaluation, err := getFraudEvaluation(s.db, evaluationID)
+	if err != nil {
+		return nil, err
+	}
+	if evaluation.UserID == reviewerID {
+		return nil, fmt.Errorf("you cannot review your own transaction")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE fraud_evaluations SET status = ?, reviewed_by = ?, reviewed_at = ?, review_notes = ?
+		WHERE id = ? AND status = ?
+	`, status, reviewerID, now, optionalString(notes), evaluationID, models.FraudStatusPendingReview)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review fraud evaluation: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("transaction is not awaiting review")
+	}
+
+	if status == models.FraudStatusRejected && evaluation.TransactionID != nil {
+		_, err = tx.Exec("UPDATE transactions SET status = ?, updated_at = ? WHERE id = ? AND status = ?",
+			models.TransactionStatusCancelled, now, *evaluation.TransactionID, models.TransactionStatusPending)
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel held transaction: %w", err)
+		}
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ActorID:    &reviewerID,
+		Action:     models.AuditActionApproval,
+		EntityType: "fraud_evaluation",
+		EntityID:   evaluationID,
+		Details: map[string]interface{}{
+			"decision":      status,
+			"userId":        evaluation.UserID,
+			"amount":        evaluation.Amount,
+			"transactionId": evaluation.TransactionID,
+			"notes":         notes,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	evaluation.Status = status
+	evaluation.ReviewedBy = &reviewerID
+	evaluation.ReviewedAt = &now
+	evaluation.ReviewNotes = optionalString(notes)
+
+	what := fmt.Sprintf("Your %s of KES %.2f", evaluation.TransactionType, evaluation.Amount)
+	if status == models.FraudStatusReleased {
+		message := what + " has passed its security review and will now go ahead."
+		if evaluation.TransactionID == nil {
+			message = what + " has passed its security review. You can now make it again within 24 hours."
+		}
+		s.notify(evaluation.UserID, "Transaction Approved", message, evaluation)
+	} else {
+		s.notify(evaluation.UserID, "Transaction Declined", what+" was declined after a security review. Contact support if you did not expect this.", evaluation)
+	}
+	return evaluation, nil
+}
+
+// GetRules lists the fraud rules and their settings
+func (s *FraudService) GetRules() ([]*models.FraudRule, error) {
+	return fraudRules(s.db, false)
+}
+
+// UpdateRule changes a rule's action, score, parameters or whether it runs. Parameters
+// can only be changed, not added, since each rule reads a fixed set of them.
+func (s *FraudService) UpdateRule(ruleID, adminID string, req *models.UpdateFraudRuleRequest) (*models.FraudRule, error) {
+	rule, err := getFraudRule(s.db, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+	if req.Action != "" {
+		rule.Action = models.FraudAction(req.Action)
+	}
+	if req.Score != nil {
+		rule.Score = *req.Score
+	}
+	for name, value := range req.Params {
+		if _, ok := rule.Params[name]; !ok {
+			return nil, fmt.Errorf("unknown parameter %q for rule %s", name, rule.ID)
+		}
+		if value < 0 {
+			return nil, fmt.Errorf("parameter %q cannot be negative", name)
+		}
+		rule.Params[name] = value
+	}
+	params, err := json.Marshal(rule.Params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize rule parameters: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = tx.Exec(`
+		UPDATE fraud_rules SET is_active = ?, action = ?, score = ?, params = ?, updated_by = ?, updated_at = ?
+		WHERE id = ?
+	`, rule.IsActive, rule.Action, rule.Score, string(params), adminID, now, rule.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update fraud rule: %w", err)
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ActorID:    &adminID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "fraud_rule",
+		EntityID:   rule.ID,
+		Details: map[string]interface{}{
+			"isActive": rule.IsActive,
+			"action":   rule.Action,
+			"score":    rule.Score,
+			"params":   rule.Params,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	rule.UpdatedBy = &adminID
+	rule.UpdatedAt = &now
+	return rule, nil
+}
+
+// GetRuleStats counts each rule's hits between from and to, with how the transactions
+// they matched were decided and reviewed
+func (s *FraudService) GetRuleStats(from, to time.Time) ([]*models.FraudRuleStats, error) {
+	rows, err := s.db.Query(`
+		SELECT r.id, r.name, r.action, r.is_active, COUNT(h.id),
+			COALESCE(SUM(CASE WHEN e.decision = 'hold' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN e.decision = 'block' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN e.status = 'released' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN e.status = 'rejected' THEN 1 ELSE 0 END), 0),
+			MAX(h.created_at)
+		FROM fraud_rules r
+		LEFT JOIN fraud_rule_hits h ON h.rule_id = r.id AND h.created_at >= ? AND h.created_at < ?
+		LEFT JOIN fraud_evaluations e ON e.id = h.evaluation_id
+		GROUP BY r.id, r.name, r.action, r.is_active
+		ORDER BY COUNT(h.id) DESC, r.id
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fraud rule stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []*models.FraudRuleStats{}
+	for rows.Next() {
+		stat := &models.FraudRuleStats{}
+		var lastHit sql.NullString
+		if err := rows.Scan(&stat.RuleID, &stat.Name, &stat.Action, &stat.IsActive, &stat.Hits, &stat.Held,
+			&stat.Blocked, &stat.Released, &stat.Rejected, &lastHit); err != nil {
+			return nil, fmt.Errorf("failed to scan fraud rule stats: %w", err)
+		}
+		if lastHit.Valid {
+			if parsed, err := time.Parse(fraudTimeLayout, lastHit.String); err == nil {
+				stat.LastHitAt = &parsed
+			}
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+func (s *FraudService) notify(userID, title, message string, evaluation *models.FraudEvaluation) {
+	notifyFraud(s.db, userID, title, message, evaluation)
+}
+
+// screenOutgoing runs the active rules over an outgoing transaction. Allowed
+// transactions get an evaluation for the caller to record alongside them. Held and
+// blocked ones are recorded here, since the caller's transaction will not commit, and
+// come back as an error - except a hold on a reviewable transaction, which the caller
+// records against the pending transaction instead. It must be called before the caller
+// opens its own database transaction.
+func screenOutgoing(db *sql.DB, check *models.FraudCheck) (*models.FraudEvaluation, error) {
+	now := time.Now()
+
+	// A transaction an admin released goes through once, without being evaluated again
+	if !check.Reviewable {
+		released, err := releasedFraudEvaluation(db, check, now)
+		if err != nil {
+			return nil, err
+		}
+		if released != nil {
+			return released, nil
+		}
+	}
+
+	evaluation := &models.FraudEvaluation{
+		ID:              uuid.New().String(),
+		UserID:          check.UserID,
+		ChamaID:         optionalString(check.ChamaID),
+		TransactionType: check.TransactionType,
+		Amount:          roundCurrency(check.Amount),
+		RecipientID:     optionalString(check.RecipientID),
+		FromWalletID:    optionalString(check.FromWalletID),
+		Hits:            []models.FraudRuleHit{},
+		CreatedAt:       now,
+	}
+
+	rules, err := fraudRules(db, true)
+	if err != nil {
+		return nil, err
+	}
+	held, blocked := false, false
+	for _, rule := range rules {
+		reason, err := evaluateFraudRule(db, rule, check, now)
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			continue
+		}
+		evaluation.Hits = append(evaluation.Hits, models.FraudRuleHit{
+			RuleID: rule.ID,
+			Name:   rule.Name,
+			Action: rule.Action,
+			Score:  rule.Score,
+			Reason: reason,
+		})
+		evaluation.Score += rule.Score
+		held = held || rule.Action == models.FraudActionHold
+		blocked = blocked || rule.Action == models.FraudActionBlock
+	}
+
+	switch {
+	case blocked || evaluation.Score >= fraudBlockScore:
+		evaluation.Decision = models.FraudDecisionBlock
+		evaluation.Status = models.FraudStatusBlocked
+	case held || evaluation.Score >= fraudHoldScore:
+		evaluation.Decision = models.FraudDecisionHold
+		evaluation.Status = models.FraudStatusPendingReview
+		if check.Reviewable {
+			return evaluation, nil
+		}
+	default:
+		evaluation.Decision = models.FraudDecisionAllow
+		evaluation.Status = models.FraudStatusCleared
+		return evaluation, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err := insertFraudEvaluation(tx, evaluation); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	notifyFraudDecision(db, evaluation)
+
+	if evaluation.Decision == models.FraudDecisionBlock {
+		return evaluation, fmt.Errorf("%w for your security. Contact support if you believe this is a mistake", errTransactionBlocked)
+	}
+	return evaluation, fmt.Errorf("%w: it will be checked by our team and you will be notified of the outcome", errTransactionHeld)
+}
+
+// recordFraudEvaluation saves an evaluation against the transaction it was made for,
+// inside that transaction's database transaction. A released evaluation is used up.
+func recordFraudEvaluation(tx *sql.Tx, evaluation *models.FraudEvaluation, transactionID string) error {
+	if evaluation.Status == models.FraudStatusReleased {
+		result, err := tx.Exec(`
+			UPDATE fraud_evaluations SET consumed_at = ?, transaction_id = ?
+			WHERE id = ? AND consumed_at IS NULL AND transaction_id IS NULL
+		`, time.Now(), transactionID, evaluation.ID)
+		if err != nil {
+			return fmt.Errorf("failed to use released fraud evaluation: %w", err)
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return fmt.Errorf("%w: the approval for this transaction has already been used", errTransactionHeld)
+		}
+		return nil
+	}
+
+	evaluation.TransactionID = optionalString(transactionID)
+	return insertFraudEvaluation(tx, evaluation)
+}
+
+func insertFraudEvaluation(tx *sql.Tx, evaluation *models.FraudEvaluation) error {
+	hits, err := evaluation.GetHitsJSON()
+	if err != nil {
+		return fmt.Errorf("failed to serialize fraud rule hits: %w", err)
+	}
+	_, err = tx.Exec(`
+		INSERT INTO fraud_evaluations (
+			id, user_id, chama_id, transaction_type, amount, recipient_id, from_wallet_id,
+			transaction_id, score, decision, status, hits, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, evaluation.ID, evaluation.UserID, evaluation.ChamaID, evaluation.TransactionType, evaluation.Amount,
+		evaluation.RecipientID, evaluation.FromWalletID, evaluation.TransactionID, evaluation.Score,
+		evaluation.Decision, evaluation.Status, hits, evaluation.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record fraud evaluation: %w", err)
+	}
+
+	for _, hit := range evaluation.Hits {
+		_, err = tx.Exec(`
+			INSERT INTO fraud_rule_hits (id, evaluation_id, rule_id, score, action, created_at) VALUES (?, ?, ?, ?, ?, ?)
+		`, uuid.New().String(), evaluation.ID, hit.RuleID, hit.Score, hit.Action, evaluation.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to record fraud rule hit: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkFraudHold fails while a pending transaction is waiting for review or after its
+// review rejected it
+func checkFraudHold(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, transactionID string) error {
+	var status models.FraudEvaluationStatus
+	err := q.QueryRow(`
+		SELECT status FROM fraud_evaluations WHERE transaction_id = ? AND status IN (?, ?)
+	`, transactionID, models.FraudStatusPendingReview, models.FraudStatusRejected).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check fraud review: %w", err)
+	}
+	if status == models.FraudStatusRejected {
+		return fmt.Errorf("transaction was declined by security review")
+	}
+	return fmt.Errorf("%w: it is awaiting security review", errTransactionHeld)
+}
+
+// releasedFraudEvaluation finds an unused admin release for the same transaction
+func releasedFraudEvaluation(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, check *models.FraudCheck, now time.Time) (*models.FraudEvaluation, error) {
+	var evaluationID string
+	err := q.QueryRow(`
+		SELECT id FROM fraud_evaluations
+		WHERE user_id = ? AND transaction_type = ? AND ABS(amount - ?) < 0.005
+			AND COALESCE(recipient_id, '') = ? AND COALESCE(chama_id, '') = ?
+			AND status = ? AND transaction_id IS NULL AND consumed_at IS NULL AND reviewed_at >= ?
+		ORDER BY reviewed_at DESC LIMIT 1
+	`, check.UserID, check.TransactionType, roundCurrency(check.Amount), check.RecipientID, check.ChamaID,
+		models.FraudStatusReleased, now.Add(-fraudReleaseWindow)).Scan(&evaluationID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check released transactions: %w", err)
+	}
+	return getFraudEvaluation(q, evaluationID)
+}
+
+// evaluateFraudRule returns why rule matches the transaction, or "" when it does not
+func evaluateFraudRule(db *sql.DB, rule *models.FraudRule, check *models.FraudCheck, now time.Time) (string, error) {
+	switch rule.ID {
+	case models.FraudRuleVelocity:
+		window := time.Duration(rule.Param("windowMinutes", 60)) * time.Minute
+		var count int
+		var total float64
+		err := db.QueryRow(`
+			SELECT COUNT(*), COALESCE(SUM(amount), 0) FROM fraud_evaluations
+			WHERE user_id = ? AND created_at >= ? AND status IN (?, ?, ?)
+		`, check.UserID, now.Add(-window), models.FraudStatusCleared, models.FraudStatusPendingReview,
+			models.FraudStatusReleased).Scan(&count, &total)
+		if err != nil {
+			return "", fmt.Errorf("failed to check transaction velocity: %w", err)
+		}
+		if maxCount := rule.Param("maxCount", 5); float64(count+1) > maxCount {
+			return fmt.Sprintf("%d outgoing transactions within %.0f minutes", count+1, window.Minutes()), nil
+		}
+		if maxAmount := rule.Param("maxAmount", 200000); total+check.Amount > maxAmount {
+			return fmt.Sprintf("KES %.2f sent within %.0f minutes", total+check.Amount, window.Minutes()), nil
+		}
+
+	case models.FraudRuleNewRecipientLarge:
+		if check.RecipientID == "" || check.RecipientID == check.UserID || check.Amount < rule.Param("minAmount", 20000) {
+			return "", nil
+		}
+		paid, err := hasPaid(db, check.UserID, check.RecipientID, time.Time{})
+		if err != nil || paid {
+			return "", err
+		}
+		return fmt.Sprintf("first payment of KES %.2f to this recipient", check.Amount), nil
+
+	case models.FraudRuleNewDeviceWithdrawal:
+		if (check.TransactionType != models.TransactionTypeWithdrawal && check.TransactionType != models.TransactionTypeTransfer) ||
+			check.Amount < rule.Param("minAmount", 5000) {
+			return "", nil
+		}
+		window := time.Duration(rule.Param("windowHours", 24)) * time.Hour
+		device, err := newLoginDevice(db, check.UserID, now.Add(-window))
+		if err != nil || device == "" {
+			return "", err
+		}
+		return fmt.Sprintf("%s within %.0f hours of a first login from %s", check.TransactionType, window.Hours(), device), nil
+
+	case models.FraudRuleOfficialRoundTrip:
+		if check.RecipientID == "" || check.RecipientID == check.UserID || check.RecipientID == check.ChamaID {
+			return "", nil
+		}
+		var chamaName string
+		err := db.QueryRow(`
+			SELECT c.name FROM chama_members a
+			JOIN chama_members b ON b.chama_id = a.chama_id
+			JOIN chamas c ON c.id = a.chama_id
+			WHERE a.user_id = ? AND b.user_id = ? AND a.is_active = TRUE AND b.is_active = TRUE
+				AND a.role IN ('chairperson', 'secretary', 'treasurer') AND b.role IN ('chairperson', 'secretary', 'treasurer')
+			LIMIT 1
+		`, check.UserID, check.RecipientID).Scan(&chamaName)
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to check official roles: %w", err)
+		}
+		window := time.Duration(rule.Param("windowHours", 72)) * time.Hour
+		paid, err := hasPaid(db, check.RecipientID, check.UserID, now.Add(-window))
+		if err != nil || !paid {
+			return "", err
+		}
+		return fmt.Sprintf("officials of %s paying each other back within %.0f hours", chamaName, window.Hours()), nil
+
+	case models.FraudRuleChamaDrain:
+		if check.ChamaID == "" {
+			return "", nil
+		}
+		window := time.Duration(rule.Param("windowHours", 24)) * time.Hour
+		var balance, outflow float64
+		err := db.QueryRow("SELECT COALESCE(SUM(balance), 0) FROM wallets WHERE owner_id = ? AND type = 'chama'", check.ChamaID).Scan(&balance)
+		if err != nil {
+			return "", fmt.Errorf("failed to get chama balance: %w", err)
+		}
+		err = db.QueryRow(`
+			SELECT COALESCE(SUM(amount), 0) FROM fraud_evaluations
+			WHERE chama_id = ? AND created_at >= ? AND status IN (?, ?, ?)
+		`, check.ChamaID, now.Add(-window), models.FraudStatusCleared, models.FraudStatusPendingReview,
+			models.FraudStatusReleased).Scan(&outflow)
+		if err != nil {
+			return "", fmt.Errorf("failed to get chama outflows: %w", err)
+		}
+		// The balance before the window is what is left plus what has gone
+		opening := balance + outflow
+		if opening <= 0 {
+			return "", nil
+		}
+		// Small chamas paying out small sums are not worth holding up
+		if outflow+check.Amount < rule.Param("minAmount", 10000) {
+			return "", nil
+		}
+		percent := (outflow + check.Amount) / opening * 100
+		limit := rule.Param("maxPercent", 50)
+		overnight := isNightHour(utils.ToEAT(now).Hour(), int(rule.Param("nightStartHour", 22)), int(rule.Param("nightEndHour", 5)))
+		if overnight {
+			limit = rule.Param("nightMaxPercent", 20)
+		}
+		if percent <= limit {
+			return "", nil
+		}
+		reason := fmt.Sprintf("%.0f%% of the chama wallet leaving within %.0f hours (limit %.0f%%)", percent, window.Hours(), limit)
+		if overnight {
+			reason += " overnight"
+		}
+		return reason, nil
+	}
+	return "", nil
+}
+
+// hasPaid reports whether fromUserID has completed a payment to toID, a user or a chama,
+// since the given time
+func hasPaid(db *sql.DB, fromUserID, toID string, since time.Time) (bool, error) {
+	var paid bool
+	err := db.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM transactions t
+			LEFT JOIN wallets w ON w.id = t.to_wallet_id
+			WHERE t.initiated_by = ? AND t.status = ? AND (t.recipient_id = ? OR w.owner_id = ?) AND t.created_at >= ?
+		)
+	`, fromUserID, models.TransactionStatusCompleted, toID, toID, since).Scan(&paid)
+	if err != nil {
+		return false, fmt.Errorf("failed to check payment history: %w", err)
+	}
+	return paid, nil
+}
+
+// newLoginDevice returns a device the user first logged in from since the given time,
+// or "" when every recent login was from a device seen before. Users with no earlier
+// logins have no known devices, so nothing counts as new for them.
+func newLoginDevice(db *sql.DB, userID string, since time.Time) (string, error) {
+	// login_time is written by SQLite's CURRENT_TIMESTAMP, in UTC
+	cutoff := since.UTC().Format("2006-01-02 15:04:05")
+
+	var earlier int
+	err := db.QueryRow("SELECT COUNT(*) FROM login_sessions WHERE user_id = ? AND login_time < ?", userID, cutoff).Scan(&earlier)
+	if err != nil {
+		return "", fmt.Errorf("failed to check login history: %w", err)
+	}
+	if earlier == 0 {
+		return "", nil
+	}
+
+	var device string
+	err = db.QueryRow(`
+		SELECT COALESCE(NULLIF(TRIM(COALESCE(s.device_name, '') || ' ' || COALESCE(s.browser, '')), ''), 'an unknown device')
+		FROM login_sessions s
+		WHERE s.user_id = ? AND s.login_time >= ?
+			AND NOT EXISTS (
+				SELECT 1 FROM login_sessions p
+				WHERE p.user_id = s.user_id AND p.login_time < ?
+					AND COALESCE(p.device_name, '') = COALESCE(s.device_name, '')
+					AND COALESCE(p.operating_system, '') = COALESCE(s.operating_system, '')
+					AND COALESCE(p.browser, '') = COALESCE(s.browser, '')
+			)
+		ORDER BY s.login_time DESC LIMIT 1
+	`, userID, cutoff, cutoff).Scan(&device)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check login devices: %w", err)
+	}
+	return device, nil
+}
+
+// isNightHour reports whether hour falls in [start, end), which may wrap past midnight
+func isNightHour(hour, start, end int) bool {
+	if start <= end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+// notifyFraudDecision tells the member their transaction was held or blocked and, when
+// the money was leaving a chama wallet, alerts the chama's other officials
+func notifyFraudDecision(db *sql.DB, evaluation *models.FraudEvaluation) {
+	what := fmt.Sprintf("Your %s of KES %.2f", evaluation.TransactionType, evaluation.Amount)
+	outcome := "held for review"
+	if evaluation.Decision == models.FraudDecisionBlock {
+		outcome = "blocked"
+		notifyFraud(db, evaluation.UserID, "Transaction Blocked", what+" was blocked because it looks unusual. Contact support if you made it.", evaluation)
+	} else {
+		notifyFraud(db, evaluation.UserID, "Transaction Under Review", what+" has been held for a security review. We will let you know once it has been checked.", evaluation)
+	}
+
+	if evaluation.ChamaID == nil {
+		return
+	}
+	var chamaName, userName string
+	db.QueryRow("SELECT name FROM chamas WHERE id = ?", *evaluation.ChamaID).Scan(&chamaName)
+	db.QueryRow("SELECT first_name || ' ' || last_name FROM users WHERE id = ?", evaluation.UserID).Scan(&userName)
+
+	rows, err := db.Query(`
+		SELECT user_id FROM chama_members
+		WHERE chama_id = ? AND is_active = TRUE AND role IN ('chairperson', 'secretary', 'treasurer') AND user_id != ?
+	`, *evaluation.ChamaID, evaluation.UserID)
+	if err != nil {
+		log.Printf("Failed to get officials of chama %s for fraud alert: %v", *evaluation.ChamaID, err)
+		return
+	}
+	var officials []string
+	for rows.Next() {
+		var officialID string
+		if err := rows.Scan(&officialID); err == nil {
+			officials = append(officials, officialID)
+		}
+	}
+	rows.Close()
+
+	message := fmt.Sprintf("A %s of KES %.2f from the %s wallet by %s was %s.", evaluation.TransactionType, evaluation.Amount, chamaName, userName, outcome)
+	for _, officialID := range officials {
+		notifyFraud(db, officialID, "Chama Wallet Alert", message, evaluation)
+	}
+}
+
+func notifyFraud(db *sql.DB, userID, title, message string, evaluation *models.FraudEvaluation) {
+	err := NewNotificationService(db, nil).CreateInAppNotification(userID, "alert", "security", title, message, map[string]interface{}{
+		"evaluationId":  evaluation.ID,
+		"transactionId": evaluation.TransactionID,
+		"decision":      evaluation.Decision,
+		"amount":        evaluation.Amount,
+	})
+	if err != nil {
+		log.Printf("Failed to send fraud notification to %s: %v", userID, err)
+	}
+}
+
+// fraudRules lists the fraud rules, optionally only the active ones
+func fraudRules(q interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}, activeOnly bool) ([]*models.FraudRule, error) {
+	query := "SELECT id, name, description, is_active, action, score, params, updated_by, updated_at FROM fraud_rules"
+	if activeOnly {
+		query += " WHERE is_active = TRUE"
+	}
+	rows, err := q.Query(query + " ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fraud rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []*models.FraudRule{}
+	for rows.Next() {
+		rule, err := scanFraudRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func getFraudRule(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, ruleID string) (*models.FraudRule, error) {
+	rule, err := scanFraudRule(q.QueryRow(`
+		SELECT id, name, description, is_active, action, score, params, updated_by, updated_at FROM fraud_rules WHERE id = ?
+	`, ruleID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("fraud rule not found")
+	}
+	return rule, err
+}
+
+func scanFraudRule(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.FraudRule, error) {
+	rule := &models.FraudRule{}
+	var params string
+	var updatedBy sql.NullString
+	var updatedAt sql.NullTime
+	err := row.Scan(&rule.ID, &rule.Name, &rule.Description, &rule.IsActive, &rule.Action, &rule.Score,
+		&params, &updatedBy, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan fraud rule: %w", err)
+	}
+	if err := json.Unmarshal([]byte(params), &rule.Params); err != nil {
+		return nil, fmt.Errorf("failed to parse parameters of fraud rule %s: %w", rule.ID, err)
+	}
+	if rule.Params == nil {
+		rule.Params = map[string]float64{}
+	}
+	if updatedBy.Valid {
+		rule.UpdatedBy = &updatedBy.String
+	}
+	if updatedAt.Valid {
+		rule.UpdatedAt = &updatedAt.Time
+	}
+	return rule, nil
+}
+
+const fraudEvaluationColumns = `e.id, e.user_id, COALESCE(u.first_name || ' ' || u.last_name, ''), e.chama_id,
+	e.transaction_type, e.amount, e.recipient_id, e.from_wallet_id, e.transaction_id, e.score, e.decision,
+	e.status, e.hits, e.reviewed_by, e.reviewed_at, e.review_notes, e.consumed_at, e.created_at`
+
+func getFraudEvaluation(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, evaluationID string) (*models.FraudEvaluation, error) {
+	evaluation, err := scanFraudEvaluation(q.QueryRow(`
+		SELECT `+fraudEvaluationColumns+`
+		FROM fraud_evaluations e
+		LEFT JOIN users u ON u.id = e.user_id
+		WHERE e.id = ?
+	`, evaluationID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("fraud evaluation not found")
+	}
+	return evaluation, err
+}
+
+func scanFraudEvaluation(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.FraudEvaluation, error) {
+	evaluation := &models.FraudEvaluation{}
+	var chamaID, recipientID, fromWalletID, transactionID, reviewedBy, reviewNotes sql.NullString
+	var reviewedAt, consumedAt sql.NullTime
+	var hits string
+	err := row.Scan(&evaluation.ID, &evaluation.UserID, &evaluation.UserName, &chamaID, &evaluation.TransactionType,
+		&evaluation.Amount, &recipientID, &fromWalletID, &transactionID, &evaluation.Score, &evaluation.Decision,
+		&evaluation.Status, &hits, &reviewedBy, &reviewedAt, &reviewNotes, &consumedAt, &evaluation.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan fraud evaluation: %w", err)
+	}
+	if err := json.Unmarshal([]byte(hits), &evaluation.Hits); err != nil {
+		return nil, fmt.Errorf("failed to parse fraud rule hits: %w", err)
+	}
+	if chamaID.Valid {
+		evaluation.ChamaID = &chamaID.String
+	}
+	if recipientID.Valid {
+		evaluation.RecipientID = &recipientID.String
+	}
+	if fromWalletID.Valid {
+		evaluation.FromWalletID = &fromWalletID.String
+	}
+	if transactionID.Valid {
+		evaluation.TransactionID = &transactionID.String
+	}
+	if reviewedBy.Valid {
+		evaluation.ReviewedBy = &reviewedBy.String
+	}
+	if reviewNotes.Valid {
+		evaluation.ReviewNotes = &reviewNotes.String
+	}
+	if reviewedAt.Valid {
+		evaluation.ReviewedAt = &reviewedAt.Time
+	}
+	if consumedAt.Valid {
+		evaluation.ConsumedAt = &consumedAt.Time
+	}
+	return evaluation, nil
+}
@@ -0,0 +1,238 @@
+package services_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type FraudTestSuite struct {
+	suite.Suite
+	testDB   *helpers.TestDatabase
+	db       *sql.DB
+	service  *services.FraudService
+	wallets  *services.WalletService
+	adminID  string
+	memberID string
+}
+
+func (suite *FraudTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewFraudService(suite.db)
+	suite.wallets = services.NewWalletService(suite.db)
+	suite.adminID = suite.testDB.AddTestUser(suite.T(), "Admin")
+	suite.memberID = suite.testDB.AddTestUser(suite.T(), "Member")
+	suite.fund(suite.memberID, "personal", 100000)
+}
+
+func (suite *FraudTestSuite) fund(ownerID, walletType string, balance float64) string {
+	walletID := "wallet-" + walletType + "-" + ownerID
+	_, err := suite.db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, ?, ?, ?)", walletID, walletType, ownerID, balance)
+	suite.Require().NoError(err)
+	return walletID
+}
+
+func (suite *FraudTestSuite) transfer(userID, fromWalletID, toWalletID string, amount float64) (*models.Transaction, error) {
+	return suite.wallets.CreateTransaction(&models.TransactionCreation{
+		FromWalletID:  &fromWalletID,
+		ToWalletID:    &toWalletID,
+		Type:          models.TransactionTypeTransfer,
+		Amount:        amount,
+		PaymentMethod: models.PaymentMethodWalletTransfer,
+		Metadata:      map[string]interface{}{},
+	}, userID)
+}
+
+func (suite *FraudTestSuite) notifications(userID, title string) int {
+	var count int
+	err := suite.db.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = ? AND title = ?", userID, title).Scan(&count)
+	suite.Require().NoError(err)
+	return count
+}
+
+func (suite *FraudTestSuite) TestVelocityHoldsPendingTransactionUntilReleased() {
+	maxCount := 2.0
+	_, err := suite.service.UpdateRule(models.FraudRuleVelocity, suite.adminID, &models.UpdateFraudRuleRequest{
+		Params: map[string]float64{"maxCount": maxCount},
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.UpdateRule(models.FraudRuleVelocity, suite.adminID, &models.UpdateFraudRuleRequest{
+		Params: map[string]float64{"maxCuont": 1},
+	})
+	suite.Require().Error(err, "unknown parameters are rejected")
+
+	friendID := suite.testDB.AddTestUser(suite.T(), "Friend")
+	fromWalletID := "wallet-personal-" + suite.memberID
+	toWalletID := suite.fund(friendID, "personal", 0)
+
+	for i := 0; i < 2; i++ {
+		transaction, err := suite.transfer(suite.memberID, fromWalletID, toWalletID, 1000)
+		suite.Require().NoError(err)
+		suite.False(transaction.RequiresApproval)
+		suite.Require().NoError(suite.wallets.ProcessTransaction(transaction.ID))
+	}
+
+	held, err := suite.transfer(suite.memberID, fromWalletID, toWalletID, 1000)
+	suite.Require().NoError(err, "pending transactions wait for review instead of failing")
+	suite.True(held.RequiresApproval)
+	isHeld, err := suite.service.IsHeld(held.ID)
+	suite.Require().NoError(err)
+	suite.True(isHeld)
+	suite.Require().Error(suite.wallets.ProcessTransaction(held.ID))
+	suite.Equal(1, suite.notifications(suite.memberID, "Transaction Under Review"))
+
+	queue, err := suite.service.GetReviewQueue("", 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(queue, 1)
+	suite.Equal(models.FraudDecisionHold, queue[0].Decision)
+	suite.Equal("Member User", queue[0].UserName)
+	suite.Require().Len(queue[0].Hits, 1)
+	suite.Equal(models.FraudRuleVelocity, queue[0].Hits[0].RuleID)
+
+	_, err = suite.service.ReleaseEvaluation(queue[0].ID, suite.memberID, &models.FraudReviewRequest{})
+	suite.Require().Error(err, "members cannot release their own transactions")
+	_, err = suite.service.RejectEvaluation(queue[0].ID, suite.adminID, &models.FraudReviewRequest{Notes: " "})
+	suite.Require().Error(err, "rejections need notes")
+
+	released, err := suite.service.ReleaseEvaluation(queue[0].ID, suite.adminID, &models.FraudReviewRequest{Notes: "Known recipient"})
+	suite.Require().NoError(err)
+	suite.Equal(models.FraudStatusReleased, released.Status)
+	suite.Require().NoError(suite.wallets.ProcessTransaction(held.ID))
+	suite.Equal(1, suite.notifications(suite.memberID, "Transaction Approved"))
+
+	_, err = suite.service.ReleaseEvaluation(queue[0].ID, suite.adminID, &models.FraudReviewRequest{})
+	suite.Require().Error(err, "an evaluation is reviewed once")
+
+	stats, err := suite.service.GetRuleStats(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	suite.Require().NoError(err)
+	suite.Require().Len(stats, 5)
+	suite.Equal(models.FraudRuleVelocity, stats[0].RuleID)
+	suite.Equal(1, stats[0].Hits)
+	suite.Equal(1, stats[0].Held)
+	suite.Equal(1, stats[0].Released)
+	suite.NotNil(stats[0].LastHitAt)
+
+	var audited int
+	err = suite.db.QueryRow("SELECT COUNT(*) FROM audit_log WHERE entity_type IN ('fraud_rule', 'fraud_evaluation')").Scan(&audited)
+	suite.Require().NoError(err)
+	suite.Equal(2, audited)
+}
+
+func (suite *FraudTestSuite) TestChamaDrainIsBlockedAndOfficialsAlerted() {
+	chairID := suite.testDB.AddTestUser(suite.T(), "Chair")
+	treasurerID := suite.testDB.AddTestUser(suite.T(), "Treasurer")
+	chamaID := suite.testDB.AddTestChama(suite.T(), chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), chamaID, treasurerID, "treasurer")
+	chamaWalletID := suite.fund(chamaID, "chama", 100000)
+	treasurerWalletID := suite.fund(treasurerID, "personal", 0)
+
+	_, err := suite.transfer(treasurerID, chamaWalletID, treasurerWalletID, 60000)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "transaction blocked")
+
+	var transactions int
+	suite.Require().NoError(suite.db.QueryRow("SELECT COUNT(*) FROM transactions").Scan(&transactions))
+	suite.Equal(0, transactions)
+	suite.Equal(1, suite.notifications(chairID, "Chama Wallet Alert"))
+	suite.Equal(0, suite.notifications(treasurerID, "Chama Wallet Alert"))
+	suite.Equal(1, suite.notifications(treasurerID, "Transaction Blocked"))
+
+	blocked, err := suite.service.GetReviewQueue(string(models.FraudStatusBlocked), 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(blocked, 1)
+	suite.Require().NotNil(blocked[0].ChamaID)
+	suite.Equal(chamaID, *blocked[0].ChamaID)
+	suite.Equal(models.FraudRuleChamaDrain, blocked[0].Hits[0].RuleID)
+
+	// A small payment still goes through, and counts towards the next one
+	small, err := suite.transfer(treasurerID, chamaWalletID, treasurerWalletID, 10000)
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.wallets.ProcessTransaction(small.ID))
+	_, err = suite.transfer(treasurerID, chamaWalletID, treasurerWalletID, 45000)
+	suite.Require().Error(err, "55% of the opening balance would leave within a day")
+}
+
+func (suite *FraudTestSuite) TestNewDeviceHoldsPaymentUntilReleasedForRetry() {
+	requesterID := suite.testDB.AddTestUser(suite.T(), "Requester")
+	suite.fund(requesterID, "personal", 0)
+	requests := services.NewMoneyRequestService(suite.db)
+
+	login := func(device string, at time.Time) {
+		_, err := suite.db.Exec(`
+			INSERT INTO login_sessions (user_id, device_name, operating_system, browser, login_time) VALUES (?, ?, 'Android', 'App', ?)
+		`, suite.memberID, device, at.UTC().Format("2006-01-02 15:04:05"))
+		suite.Require().NoError(err)
+	}
+	login("Pixel 7", time.Now().AddDate(0, 0, -10))
+	login("Pixel 7", time.Now().Add(-time.Hour))
+
+	request, err := requests.SendRequest(requesterID, suite.memberID, &models.SendMoneyRequestRequest{Amount: 8000, Reason: "Rent", RequestType: "direct"})
+	suite.Require().NoError(err)
+	request2, err := requests.SendRequest(requesterID, suite.memberID, &models.SendMoneyRequestRequest{Amount: 8000, Reason: "Rent", RequestType: "direct"})
+	suite.Require().NoError(err)
+
+	login("Unknown Tablet", time.Now().Add(-10*time.Minute))
+	_, err = requests.PayRequest(request.ID, suite.memberID)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "held for review")
+
+	pending, err := requests.GetRequest(request.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(models.MoneyRequestStatusPending, pending.Status, "nothing moved while held")
+
+	queue, err := suite.service.GetReviewQueue("", 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(queue, 1)
+	suite.Equal(models.FraudRuleNewDeviceWithdrawal, queue[0].Hits[0].RuleID)
+	suite.Contains(queue[0].Hits[0].Reason, "Unknown Tablet")
+
+	_, err = suite.service.ReleaseEvaluation(queue[0].ID, suite.adminID, &models.FraudReviewRequest{})
+	suite.Require().NoError(err)
+
+	paid, err := requests.PayRequest(request.ID, suite.memberID)
+	suite.Require().NoError(err, "a released payment can be retried")
+	suite.Equal(models.MoneyRequestStatusPaid, paid.Status)
+
+	_, err = requests.PayRequest(request2.ID, suite.memberID)
+	suite.Require().Error(err, "a release covers one payment only")
+}
+
+func (suite *FraudTestSuite) TestRoundTripBetweenOfficialsIsHeld() {
+	chairID := suite.testDB.AddTestUser(suite.T(), "Chair")
+	chamaID := suite.testDB.AddTestChama(suite.T(), chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), chamaID, suite.memberID, "treasurer")
+	chairWalletID := suite.fund(chairID, "personal", 0)
+	memberWalletID := "wallet-personal-" + suite.memberID
+
+	out, err := suite.transfer(suite.memberID, memberWalletID, chairWalletID, 3000)
+	suite.Require().NoError(err)
+	suite.False(out.RequiresApproval)
+	suite.Require().NoError(suite.wallets.ProcessTransaction(out.ID))
+
+	back, err := suite.transfer(chairID, chairWalletID, memberWalletID, 2000)
+	suite.Require().NoError(err)
+	suite.True(back.RequiresApproval)
+
+	queue, err := suite.service.GetReviewQueue("", 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(queue, 1)
+	suite.Equal(models.FraudRuleOfficialRoundTrip, queue[0].Hits[0].RuleID)
+
+	_, err = suite.service.RejectEvaluation(queue[0].ID, suite.adminID, &models.FraudReviewRequest{Notes: "Officials cycling funds"})
+	suite.Require().NoError(err)
+	cancelled, err := suite.wallets.GetTransactionByID(back.ID)
+	suite.Require().NoError(err)
+	suite.Equal(models.TransactionStatusCancelled, cancelled.Status)
+	suite.Equal(1, suite.notifications(chairID, "Transaction Declined"))
+}
+
+func TestFraudSuite(t *testing.T) {
+	suite.Run(t, new(FraudTestSuite))
+}
@@ -0,0 +1,776 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"vaultke-backend/internal/models"
+)
+
+// fxRateMaxAge is how old the newest rate for a pair may be before conversions at it are
+// refused, so a stale feed cannot be traded against
+var fxRateMaxAge = parseFXRateMaxAge(getEnvOrDefault("FX_RATE_MAX_AGE_HOURS", "72"))
+
+func parseFXRateMaxAge(value string) time.Duration {
+	hours, err := strconv.Atoi(value)
+	if err != nil || hours <= 0 {
+		log.Printf("Invalid FX_RATE_MAX_AGE_HOURS %q, using 72", value)
+		hours = 72
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// fxRate is a resolved mid rate between two currencies, possibly inverted or crossed
+// through KES, with the spread to charge on it
+type fxRate struct {
+	mid           float64
+	spreadPercent float64
+	effectiveAt   time.Time
+}
+
+// FXService handles exchange rates, foreign-currency wallets and currency conversion
+type FXService struct {
+	db *sql.DB
+}
+
+// NewFXService creates a new FX service
+func NewFXService(db *sql.DB) *FXService {
+	return &FXService{db: db}
+}
+
+// SetRate publishes a rate for a currency pair. Older rates are kept so that past
+// conversions can be explained.
+func (s *FXService) SetRate(adminID string, req *models.SetExchangeRateRequest) (*models.ExchangeRate, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rate, err := s.insertRate(tx, adminID, req, models.ExchangeRateSourceManual)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return rate, nil
+}
+
+// ImportRates publishes a batch of rates from a feed. The batch is all or nothing.
+func (s *FXService) ImportRates(adminID string, rates []models.SetExchangeRateRequest) ([]*models.ExchangeRate, error) {
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("no exchange rates to import")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	imported := make([]*models.ExchangeRate, 0, len(rates))
+	for i := range rates {
+		rate, err := s.insertRate(tx, adminID, &rates[i], models.ExchangeRateSourceImport)
+		if err != nil {
+			return nil, fmt.Errorf("rate %d: %w", i+1, err)
+		}
+		imported = append(imported, rate)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return imported, nil
+}
+
+// ParseRatesCSV reads rates in the form base,quote,rate[,spreadPercent[,effectiveAt]].
+// A header row starting with "base" is skipped.
+func ParseRatesCSV(r io.Reader) ([]models.SetExchangeRateRequest, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+
+	rates := []models.SetExchangeRateRequest{}
+	for i, record := range records {
+		if i == 0 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "base") {
+			continue
+		}
+		if len(record) < 3 || len(record) > 5 {
+			return nil, fmt.Errorf("line %d: expected base,quote,rate[,spreadPercent[,effectiveAt]]", i+1)
+		}
+
+		rate := models.SetExchangeRateRequest{
+			BaseCurrency:  strings.TrimSpace(record[0]),
+			QuoteCurrency: strings.TrimSpace(record[1]),
+		}
+		if rate.Rate, err = strconv.ParseFloat(strings.TrimSpace(record[2]), 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate %q", i+1, record[2])
+		}
+		if len(record) > 3 && strings.TrimSpace(record[3]) != "" {
+			if rate.SpreadPercent, err = strconv.ParseFloat(strings.TrimSpace(record[3]), 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid spread %q", i+1, record[3])
+			}
+		}
+		if len(record) > 4 {
+			rate.EffectiveAt = strings.TrimSpace(record[4])
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// GetLatestRates returns the newest rate published for every currency pair
+func (s *FXService) GetLatestRates() ([]*models.ExchangeRate, error) {
+	rows, err := s.db.Query(`
+		SELECT id, base_currency, quote_currency, rate, spread_percent, source, effective_at, created_by, created_at
+		FROM exchange_rates r
+		WHERE effective_at <= ? AND id = (
+			SELECT id FROM exchange_rates
+			WHERE base_currency = r.base_currency AND quote_currency = r.quote_currency AND effective_at <= ?
+			ORDER BY effective_at DESC, created_at DESC LIMIT 1
+		)
+		ORDER BY base_currency, quote_currency
+	`, time.Now(), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	return scanExchangeRates(rows)
+}
+
+// GetRateHistory returns the rates published for a pair, newest first
+func (s *FXService) GetRateHistory(base, quote string, limit, offset int) ([]*models.ExchangeRate, error) {
+	rows, err := s.db.Query(`
+		SELECT id, base_currency, quote_currency, rate, spread_percent, source, effective_at, created_by, created_at
+		FROM exchange_rates
+		WHERE base_currency = ? AND quote_currency = ?
+		ORDER BY effective_at DESC, created_at DESC
+		LIMIT ? OFFSET ?
+	`, strings.ToUpper(base), strings.ToUpper(quote), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate history: %w", err)
+	}
+	defer rows.Close()
+
+	return scanExchangeRates(rows)
+}
+
+// GetQuote prices converting amount of one currency into another at the current rate
+func (s *FXService) GetQuote(fromCurrency, toCurrency string, amount float64) (*models.FXQuote, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+	return s.quote(s.db, strings.ToUpper(fromCurrency), strings.ToUpper(toCurrency), amount)
+}
+
+// OpenCurrencyWallet opens a wallet for the user in a currency other than KES. Opening
+// a wallet the user already has returns it unchanged.
+func (s *FXService) OpenCurrencyWallet(userID, currency string) (*models.Wallet, error) {
+	currency = strings.ToUpper(currency)
+	if !models.IsSupportedCurrency(currency) {
+		return nil, fmt.Errorf("unsupported currency: %s", currency)
+	}
+	if currency == models.DefaultCurrency {
+		return nil, fmt.Errorf("your personal wallet is already held in %s", models.DefaultCurrency)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	walletID, err := currencyWallet(tx, userID, currency, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return NewWalletService(s.db).GetWalletByID(walletID)
+}
+
+// Convert moves money between two of the user's own wallets in different currencies
+func (s *FXService) Convert(userID string, req *models.ConvertCurrencyRequest) (*models.CurrencyConversion, error) {
+	fromCurrency := strings.ToUpper(req.FromCurrency)
+	toCurrency := strings.ToUpper(req.ToCurrency)
+	if fromCurrency == toCurrency {
+		return nil, fmt.Errorf("choose two different currencies to convert between")
+	}
+	return s.transfer(userID, userID, "", fromCurrency, toCurrency, req.Amount, "Currency conversion")
+}
+
+// Transfer sends money to another member's wallet in ToCurrency, or contributes it to a
+// chama's KES wallet, converting it on the way when the currencies differ
+func (s *FXService) Transfer(userID string, req *models.FXTransferRequest) (*models.CurrencyConversion, error) {
+	if (req.RecipientID == "") == (req.ChamaID == "") {
+		return nil, fmt.Errorf("specify either a recipient or a chama")
+	}
+
+	fromCurrency := strings.ToUpper(req.FromCurrency)
+	toCurrency := strings.ToUpper(req.ToCurrency)
+	if toCurrency == "" {
+		toCurrency = fromCurrency
+	}
+
+	if req.ChamaID != "" {
+		if req.ToCurrency != "" && toCurrency != models.DefaultCurrency {
+			return nil, fmt.Errorf("chama contributions are paid into the chama's %s wallet", models.DefaultCurrency)
+		}
+		if !s.isMember(userID, req.ChamaID) {
+			return nil, fmt.Errorf("user is not a member of this chama")
+		}
+		description := req.Description
+		if description == "" {
+			description = "Chama contribution"
+		}
+		return s.transfer(userID, "", req.ChamaID, fromCurrency, models.DefaultCurrency, req.Amount, description)
+	}
+
+	if req.RecipientID == userID {
+		return nil, fmt.Errorf("use a conversion to move money between your own wallets")
+	}
+	var status string
+	err := s.db.QueryRow("SELECT status FROM users WHERE id = ?", req.RecipientID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("recipient not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recipient: %w", err)
+	}
+	if status != "active" {
+		return nil, fmt.Errorf("recipient account is not active")
+	}
+
+	description := req.Description
+	if description == "" {
+		description = "Money transfer"
+	}
+	return s.transfer(userID, req.RecipientID, "", fromCurrency, toCurrency, req.Amount, description)
+}
+
+// GetUserBalances consolidates every wallet the user holds in a currency (personal and
+// foreign-currency wallets) into baseCurrency, KES when empty
+func (s *FXService) GetUserBalances(userID, baseCurrency string) (*models.ConsolidatedBalances, error) {
+	if baseCurrency == "" {
+		baseCurrency = models.DefaultCurrency
+	}
+	return s.consolidate(userID, []models.WalletType{models.WalletTypePersonal, models.WalletTypeCurrency}, strings.ToUpper(baseCurrency))
+}
+
+// GetChamaBalances consolidates the chama's wallets into its base currency
+func (s *FXService) GetChamaBalances(chamaID, userID string) (*models.ConsolidatedBalances, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	baseCurrency, err := s.getBaseCurrency(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	return s.consolidate(chamaID, []models.WalletType{models.WalletTypeChama, models.WalletTypeCurrency}, baseCurrency)
+}
+
+// SetChamaBaseCurrency changes the currency a chama's balances are reported in
+func (s *FXService) SetChamaBaseCurrency(chamaID, userID, currency string) error {
+	currency = strings.ToUpper(currency)
+	if !models.IsSupportedCurrency(currency) {
+		return fmt.Errorf("unsupported currency: %s", currency)
+	}
+	if !s.isOfficial(userID, chamaID) {
+		return fmt.Errorf("only chama officials can change the base currency")
+	}
+
+	previous, err := s.getBaseCurrency(chamaID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE chamas SET base_currency = ?, updated_at = ? WHERE id = ?", currency, time.Now(), chamaID)
+	if err != nil {
+		return fmt.Errorf("failed to update base currency: %w", err)
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "chama_base_currency",
+		EntityID:   chamaID,
+		Details: map[string]interface{}{
+			"from": previous,
+			"to":   currency,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// transfer debits the sender's wallet in fromCurrency and credits either the recipient's
+// wallet in toCurrency or, when chamaID is set, the chama's KES wallet as a contribution.
+// The applied rate and spread are stored on the transaction.
+func (s *FXService) transfer(userID, recipientID, chamaID, fromCurrency, toCurrency string, amount float64, description string) (*models.CurrencyConversion, error) {
+	amount = roundCurrency(amount)
+
+	// Moving money between the user's own wallets is not screened for fraud
+	var evaluation *models.FraudEvaluation
+	if recipientID != userID {
+		valueKES, err := s.valueInKES(s.db, fromCurrency, amount)
+		if err != nil {
+			return nil, err
+		}
+		check := &models.FraudCheck{
+			UserID:          userID,
+			TransactionType: models.TransactionTypeTransfer,
+			Amount:          valueKES,
+			RecipientID:     recipientID,
+		}
+		if chamaID != "" {
+			check.TransactionType = models.TransactionTypeContribution
+			check.RecipientID = chamaID
+		}
+		if evaluation, err = screenOutgoing(s.db, check); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	quote, err := s.quote(tx, fromCurrency, toCurrency, amount)
+	if err != nil {
+		return nil, err
+	}
+	valueKES, err := s.valueInKES(tx, fromCurrency, amount)
+	if err != nil {
+		return nil, err
+	}
+	if quote.ConvertedAmount <= 0 {
+		return nil, fmt.Errorf("amount is too small to convert")
+	}
+	// Moving money between the user's own wallets does not count against their limits
+	if recipientID != userID {
+		if err := reserveTransactionLimit(tx, userID, "", valueKES); err != nil {
+			return nil, err
+		}
+	}
+
+	fromWalletID, err := debitCurrencyWallet(tx, userID, fromCurrency, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	var toWalletID string
+	transactionType := models.TransactionTypeTransfer
+	metadata := map[string]interface{}{}
+	if chamaID != "" {
+		toWalletID, err = creditChamaWallet(tx, chamaID, quote.ConvertedAmount)
+		transactionType = models.TransactionTypeContribution
+		metadata["contributionType"] = "regular"
+		metadata["chamaId"] = chamaID
+	} else {
+		toWalletID, err = creditCurrencyWallet(tx, recipientID, toCurrency, quote.ConvertedAmount)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if fromCurrency != toCurrency {
+		metadata["midRate"] = quote.MidRate
+		metadata["spreadAmount"] = quote.SpreadAmount
+	}
+
+	transactionID := uuid.New().String()
+	now := time.Now()
+	metadataJSON, _ := json.Marshal(metadata)
+	var recipient *string
+	if recipientID != "" && recipientID != userID {
+		recipient = &recipientID
+	} else if chamaID != "" {
+		recipient = &chamaID
+	}
+	_, err = tx.Exec(`
+		INSERT INTO transactions (
+			id, from_wallet_id, to_wallet_id, type, status, amount, currency, description,
+			payment_method, metadata, initiated_by, recipient_id, fx_rate, fx_spread,
+			converted_amount, converted_currency, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, transactionID, fromWalletID, toWalletID, transactionType, models.TransactionStatusCompleted, amount,
+		fromCurrency, description, models.PaymentMethodWalletTransfer, string(metadataJSON), userID, recipient,
+		quote.AppliedRate, quote.SpreadPercent, quote.ConvertedAmount, toCurrency, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record transaction: %w", err)
+	}
+	if err := NewAuditService(s.db).RecordTransaction(tx, chamaID, transactionID); err != nil {
+		return nil, err
+	}
+	if evaluation != nil {
+		if err := recordFraudEvaluation(tx, evaluation, transactionID); err != nil {
+			return nil, err
+		}
+	}
+
+	if chamaID != "" {
+		_, err = tx.Exec(`
+			UPDATE chama_members
+			SET total_contributions = total_contributions + ?, last_contribution = ?
+			WHERE chama_id = ? AND user_id = ?
+		`, quote.ConvertedAmount, now, chamaID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update member contributions: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if recipient != nil && chamaID == "" {
+		message := fmt.Sprintf("You have received %s %.2f", toCurrency, quote.ConvertedAmount)
+		if fromCurrency != toCurrency {
+			message += fmt.Sprintf(" (converted from %s %.2f at %.6g)", fromCurrency, amount, quote.AppliedRate)
+		}
+		err := NewNotificationService(s.db, nil).CreateInAppNotification(recipientID, "transaction", "wallet",
+			"Money Received", message, map[string]interface{}{"transactionId": transactionID})
+		if err != nil {
+			log.Printf("Failed to notify FX transfer recipient %s: %v", recipientID, err)
+		}
+	}
+	if chamaID != "" {
+		InvalidateMemberAnalytics(chamaID)
+	}
+
+	return &models.CurrencyConversion{
+		TransactionID: transactionID,
+		FromWalletID:  fromWalletID,
+		ToWalletID:    toWalletID,
+		RecipientID:   recipient,
+		Quote:         quote,
+	}, nil
+}
+
+// quote prices a conversion using the rates visible to q
+func (s *FXService) quote(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, fromCurrency, toCurrency string, amount float64) (*models.FXQuote, error) {
+	rate, err := s.resolveRate(q, fromCurrency, toCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := rate.mid * (1 - rate.spreadPercent/100)
+	converted := roundCurrency(amount * applied)
+	return &models.FXQuote{
+		FromCurrency:    fromCurrency,
+		ToCurrency:      toCurrency,
+		Amount:          amount,
+		MidRate:         roundRate(rate.mid),
+		SpreadPercent:   rate.spreadPercent,
+		AppliedRate:     roundRate(applied),
+		ConvertedAmount: converted,
+		SpreadAmount:    roundCurrency(amount*rate.mid - converted),
+		RateDate:        rate.effectiveAt,
+	}, nil
+}
+
+// resolveRate finds the rate from one currency to another: the direct pair, the inverse
+// of the opposite pair, or a cross rate through KES. Spreads on both legs of a cross are
+// compounded.
+func (s *FXService) resolveRate(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, fromCurrency, toCurrency string) (*fxRate, error) {
+	for _, currency := range []string{fromCurrency, toCurrency} {
+		if !models.IsSupportedCurrency(currency) {
+			return nil, fmt.Errorf("unsupported currency: %s", currency)
+		}
+	}
+	if fromCurrency == toCurrency {
+		return &fxRate{mid: 1, effectiveAt: time.Now()}, nil
+	}
+
+	rate, err := s.pairRate(q, fromCurrency, toCurrency)
+	if err != nil {
+		return nil, err
+	}
+	if rate == nil && fromCurrency != models.DefaultCurrency && toCurrency != models.DefaultCurrency {
+		first, err := s.pairRate(q, fromCurrency, models.DefaultCurrency)
+		if err != nil {
+			return nil, err
+		}
+		second, err := s.pairRate(q, models.DefaultCurrency, toCurrency)
+		if err != nil {
+			return nil, err
+		}
+		if first != nil && second != nil {
+			rate = &fxRate{
+				mid:           first.mid * second.mid,
+				spreadPercent: roundRate(100 - (100-first.spreadPercent)*(100-second.spreadPercent)/100),
+				effectiveAt:   first.effectiveAt,
+			}
+			if second.effectiveAt.Before(first.effectiveAt) {
+				rate.effectiveAt = second.effectiveAt
+			}
+		}
+	}
+	if rate == nil {
+		return nil, fmt.Errorf("no exchange rate available for %s/%s", fromCurrency, toCurrency)
+	}
+	if time.Since(rate.effectiveAt) > fxRateMaxAge {
+		return nil, fmt.Errorf("the %s/%s exchange rate is out of date", fromCurrency, toCurrency)
+	}
+	return rate, nil
+}
+
+// pairRate returns the newest rate for a pair, inverting the opposite pair when only
+// that has been published, or nil when neither exists
+func (s *FXService) pairRate(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, fromCurrency, toCurrency string) (*fxRate, error) {
+	var base string
+	rate := &fxRate{}
+	err := q.QueryRow(`
+		SELECT base_currency, rate, spread_percent, effective_at FROM exchange_rates
+		WHERE ((base_currency = ? AND quote_currency = ?) OR (base_currency = ? AND quote_currency = ?))
+		AND effective_at <= ?
+		ORDER BY effective_at DESC, created_at DESC LIMIT 1
+	`, fromCurrency, toCurrency, toCurrency, fromCurrency, time.Now()).Scan(&base, &rate.mid, &rate.spreadPercent, &rate.effectiveAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	if base != fromCurrency {
+		rate.mid = 1 / rate.mid
+	}
+	return rate, nil
+}
+
+// valueInKES values amount in shillings at the mid rate, which is what is screened and
+// counted against the sender's limits
+func (s *FXService) valueInKES(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, currency string, amount float64) (float64, error) {
+	rate, err := s.resolveRate(q, currency, models.DefaultCurrency)
+	if err != nil {
+		return 0, err
+	}
+	return roundCurrency(amount * rate.mid), nil
+}
+
+// consolidate groups the owner's wallets of the given types by currency and values each
+// group in baseCurrency at the mid rate
+func (s *FXService) consolidate(ownerID string, walletTypes []models.WalletType, baseCurrency string) (*models.ConsolidatedBalances, error) {
+	if !models.IsSupportedCurrency(baseCurrency) {
+		return nil, fmt.Errorf("unsupported currency: %s", baseCurrency)
+	}
+
+	placeholders := make([]string, len(walletTypes))
+	args := []interface{}{ownerID}
+	for i, walletType := range walletTypes {
+		placeholders[i] = "?"
+		args = append(args, walletType)
+	}
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT COALESCE(currency, 'KES'), COALESCE(SUM(balance), 0), COUNT(*)
+		FROM wallets
+		WHERE owner_id = ? AND type IN (%s) AND COALESCE(is_active, TRUE) = TRUE
+		GROUP BY COALESCE(currency, 'KES')
+	`, strings.Join(placeholders, ", ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet balances: %w", err)
+	}
+	defer rows.Close()
+
+	result := &models.ConsolidatedBalances{
+		OwnerID:      ownerID,
+		BaseCurrency: baseCurrency,
+		Balances:     []*models.CurrencyBalance{},
+		GeneratedAt:  time.Now(),
+	}
+	for rows.Next() {
+		balance := &models.CurrencyBalance{}
+		if err := rows.Scan(&balance.Currency, &balance.Balance, &balance.WalletCount); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet balance: %w", err)
+		}
+		balance.Balance = roundCurrency(balance.Balance)
+		result.Balances = append(result.Balances, balance)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read wallet balances: %w", err)
+	}
+	rows.Close()
+
+	for _, balance := range result.Balances {
+		rate, err := s.resolveRate(s.db, balance.Currency, baseCurrency)
+		if err != nil {
+			result.MissingRates = append(result.MissingRates, balance.Currency)
+			continue
+		}
+		mid := roundRate(rate.mid)
+		converted := roundCurrency(balance.Balance * rate.mid)
+		balance.Rate = &mid
+		balance.ConvertedBalance = &converted
+		result.Total += converted
+	}
+	result.Total = roundCurrency(result.Total)
+
+	sort.Slice(result.Balances, func(i, j int) bool {
+		return result.Balances[i].Currency < result.Balances[j].Currency
+	})
+	return result, nil
+}
+
+func (s *FXService) insertRate(tx *sql.Tx, adminID string, req *models.SetExchangeRateRequest, source models.ExchangeRateSource) (*models.ExchangeRate, error) {
+	base := strings.ToUpper(strings.TrimSpace(req.BaseCurrency))
+	quote := strings.ToUpper(strings.TrimSpace(req.QuoteCurrency))
+	for _, currency := range []string{base, quote} {
+		if !models.IsSupportedCurrency(currency) {
+			return nil, fmt.Errorf("unsupported currency: %s", currency)
+		}
+	}
+	if base == quote {
+		return nil, fmt.Errorf("base and quote currencies must differ")
+	}
+	if req.Rate <= 0 || math.IsInf(req.Rate, 0) || math.IsNaN(req.Rate) {
+		return nil, fmt.Errorf("rate must be greater than 0")
+	}
+	if req.SpreadPercent < 0 || req.SpreadPercent > 10 {
+		return nil, fmt.Errorf("spread must be between 0 and 10 percent")
+	}
+
+	now := time.Now()
+	effectiveAt := now
+	if req.EffectiveAt != "" {
+		parsed, err := time.Parse(time.RFC3339, req.EffectiveAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid effectiveAt, expected RFC3339")
+		}
+		effectiveAt = parsed
+	}
+
+	rate := &models.ExchangeRate{
+		ID:            uuid.New().String(),
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          req.Rate,
+		SpreadPercent: req.SpreadPercent,
+		Source:        source,
+		EffectiveAt:   effectiveAt,
+		CreatedBy:     adminID,
+		CreatedAt:     now,
+	}
+	_, err := tx.Exec(`
+		INSERT INTO exchange_rates (id, base_currency, quote_currency, rate, spread_percent, source, effective_at, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rate.ID, rate.BaseCurrency, rate.QuoteCurrency, rate.Rate, rate.SpreadPercent, rate.Source,
+		rate.EffectiveAt, rate.CreatedBy, rate.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save exchange rate: %w", err)
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ActorID:    &adminID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "exchange_rate",
+		EntityID:   rate.ID,
+		Details: map[string]interface{}{
+			"pair":          base + "/" + quote,
+			"rate":          rate.Rate,
+			"spreadPercent": rate.SpreadPercent,
+			"source":        source,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+func (s *FXService) getBaseCurrency(chamaID string) (string, error) {
+	var currency sql.NullString
+	err := s.db.QueryRow("SELECT base_currency FROM chamas WHERE id = ?", chamaID).Scan(&currency)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("chama not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get chama: %w", err)
+	}
+	if !currency.Valid || currency.String == "" {
+		return models.DefaultCurrency, nil
+	}
+	return currency.String, nil
+}
+
+func (s *FXService) isMember(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM chama_members WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
+
+func (s *FXService) isOfficial(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM chama_members
+		WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+		AND role IN ('chairperson', 'secretary', 'treasurer')
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
+
+func scanExchangeRates(rows *sql.Rows) ([]*models.ExchangeRate, error) {
+	rates := []*models.ExchangeRate{}
+	for rows.Next() {
+		rate := &models.ExchangeRate{}
+		if err := rows.Scan(&rate.ID, &rate.BaseCurrency, &rate.QuoteCurrency, &rate.Rate, &rate.SpreadPercent,
+			&rate.Source, &rate.EffectiveAt, &rate.CreatedBy, &rate.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read exchange rates: %w", err)
+	}
+	return rates, nil
+}
+
+// roundRate rounds an exchange rate to six decimal places
+func roundRate(rate float64) float64 {
+	return math.Round(rate*1e6) / 1e6
+}
@@ -0,0 +1,267 @@
+package services_test
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type FXTestSuite struct {
+	suite.Suite
+	testDB   *helpers.TestDatabase
+	db       *sql.DB
+	service  *services.FXService
+	adminID  string
+	memberID string
+	chairID  string
+	chamaID  string
+}
+
+func (suite *FXTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewFXService(suite.db)
+
+	suite.adminID = suite.testDB.AddTestUser(suite.T(), "Admin")
+	suite.memberID = suite.testDB.AddTestUser(suite.T(), "Diaspora")
+	suite.chairID = suite.testDB.AddTestUser(suite.T(), "Chair")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.memberID, "member")
+
+	suite.setRate("USD", "KES", 129, 1)
+	suite.setRate("KES", "UGX", 28.5, 0.5)
+}
+
+func (suite *FXTestSuite) setRate(base, quote string, rate, spread float64) {
+	_, err := suite.service.SetRate(suite.adminID, &models.SetExchangeRateRequest{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          rate,
+		SpreadPercent: spread,
+	})
+	suite.Require().NoError(err)
+}
+
+func (suite *FXTestSuite) fund(ownerID, walletType, currency string, balance float64) {
+	_, err := suite.db.Exec(`
+		INSERT INTO wallets (id, type, owner_id, balance, currency) VALUES (?, ?, ?, ?, ?)
+	`, uuid.New().String(), walletType, ownerID, balance, currency)
+	suite.Require().NoError(err)
+}
+
+func (suite *FXTestSuite) balance(ownerID, walletType, currency string) float64 {
+	var balance float64
+	err := suite.db.QueryRow("SELECT balance FROM wallets WHERE owner_id = ? AND type = ? AND currency = ?",
+		ownerID, walletType, currency).Scan(&balance)
+	suite.Require().NoError(err)
+	return balance
+}
+
+func (suite *FXTestSuite) TestQuotesUseDirectInverseAndCrossRates() {
+	quote, err := suite.service.GetQuote("usd", "kes", 100)
+	suite.Require().NoError(err)
+	suite.Equal(129.0, quote.MidRate)
+	suite.Equal(127.71, quote.AppliedRate)
+	suite.Equal(12771.0, quote.ConvertedAmount)
+	suite.Equal(129.0, quote.SpreadAmount)
+
+	quote, err = suite.service.GetQuote("KES", "USD", 12900)
+	suite.Require().NoError(err)
+	suite.Equal(99.0, quote.ConvertedAmount)
+
+	// UGX to USD has no published pair, so it is crossed through KES
+	quote, err = suite.service.GetQuote("UGX", "USD", 367650)
+	suite.Require().NoError(err)
+	suite.InDelta(100.0, quote.MidRate*367650, 0.01)
+	suite.InDelta(1.495, quote.SpreadPercent, 0.0001)
+	suite.InDelta(98.51, quote.ConvertedAmount, 0.01)
+
+	_, err = suite.service.GetQuote("KES", "TZS", 100)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "no exchange rate")
+
+	_, err = suite.service.GetQuote("KES", "EUR", 100)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "unsupported currency")
+
+	// A newer rate replaces the old one; a future-dated rate is not used yet
+	suite.setRate("USD", "KES", 130, 1)
+	_, err = suite.service.SetRate(suite.adminID, &models.SetExchangeRateRequest{
+		BaseCurrency: "USD", QuoteCurrency: "KES", Rate: 150, SpreadPercent: 1,
+		EffectiveAt: time.Now().Add(24 * time.Hour).Format(time.RFC3339),
+	})
+	suite.Require().NoError(err)
+	quote, err = suite.service.GetQuote("USD", "KES", 1)
+	suite.Require().NoError(err)
+	suite.Equal(130.0, quote.MidRate)
+
+	rates, err := suite.service.GetLatestRates()
+	suite.Require().NoError(err)
+	suite.Len(rates, 2)
+
+	history, err := suite.service.GetRateHistory("USD", "KES", 10, 0)
+	suite.Require().NoError(err)
+	suite.Len(history, 3)
+
+	// Stale rates cannot be converted at
+	_, err = suite.service.SetRate(suite.adminID, &models.SetExchangeRateRequest{
+		BaseCurrency: "KES", QuoteCurrency: "TZS", Rate: 17.9,
+		EffectiveAt: time.Now().Add(-30 * 24 * time.Hour).Format(time.RFC3339),
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.GetQuote("KES", "TZS", 100)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "out of date")
+}
+
+func (suite *FXTestSuite) TestImportIsAllOrNothing() {
+	rates, err := services.ParseRatesCSV(strings.NewReader("base,quote,rate,spread\nKES,TZS,17.9,0.5\nUSD,UGX,3700,1\n"))
+	suite.Require().NoError(err)
+	suite.Require().Len(rates, 2)
+	suite.Equal(17.9, rates[0].Rate)
+	suite.Equal(0.5, rates[0].SpreadPercent)
+
+	imported, err := suite.service.ImportRates(suite.adminID, rates)
+	suite.Require().NoError(err)
+	suite.Len(imported, 2)
+	suite.Equal(models.ExchangeRateSourceImport, imported[0].Source)
+
+	_, err = services.ParseRatesCSV(strings.NewReader("KES,TZS,abc\n"))
+	suite.Require().Error(err)
+
+	_, err = suite.service.ImportRates(suite.adminID, []models.SetExchangeRateRequest{
+		{BaseCurrency: "KES", QuoteCurrency: "TZS", Rate: 18},
+		{BaseCurrency: "KES", QuoteCurrency: "KES", Rate: 1},
+	})
+	suite.Require().Error(err)
+
+	history, err := suite.service.GetRateHistory("KES", "TZS", 10, 0)
+	suite.Require().NoError(err)
+	suite.Len(history, 1)
+	suite.Equal(17.9, history[0].Rate)
+}
+
+func (suite *FXTestSuite) TestConversionsRecordRateAndSpread() {
+	suite.fund(suite.memberID, "personal", "KES", 200000)
+
+	conversion, err := suite.service.Convert(suite.memberID, &models.ConvertCurrencyRequest{
+		FromCurrency: "KES", ToCurrency: "USD", Amount: 12900,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(99.0, conversion.Quote.ConvertedAmount)
+	suite.Nil(conversion.RecipientID)
+	suite.Equal(187100.0, suite.balance(suite.memberID, "personal", "KES"))
+	suite.Equal(99.0, suite.balance(suite.memberID, "currency", "USD"))
+
+	var currency, convertedCurrency string
+	var amount, rate, spread, converted float64
+	err = suite.db.QueryRow(`
+		SELECT currency, amount, fx_rate, fx_spread, converted_amount, converted_currency
+		FROM transactions WHERE id = ?
+	`, conversion.TransactionID).Scan(&currency, &amount, &rate, &spread, &converted, &convertedCurrency)
+	suite.Require().NoError(err)
+	suite.Equal("KES", currency)
+	suite.Equal(12900.0, amount)
+	suite.Equal(conversion.Quote.AppliedRate, rate)
+	suite.Equal(1.0, spread)
+	suite.Equal(99.0, converted)
+	suite.Equal("USD", convertedCurrency)
+
+	otherID := suite.testDB.AddTestUser(suite.T(), "Kampala")
+	conversion, err = suite.service.Transfer(suite.memberID, &models.FXTransferRequest{
+		RecipientID: otherID, FromCurrency: "USD", ToCurrency: "UGX", Amount: 50,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(49.0, suite.balance(suite.memberID, "currency", "USD"))
+	suite.Equal(conversion.Quote.ConvertedAmount, suite.balance(otherID, "currency", "UGX"))
+	suite.InDelta(50*129*28.5*0.99*0.995, conversion.Quote.ConvertedAmount, 1)
+
+	var notifications int
+	err = suite.db.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = ? AND title = 'Money Received'", otherID).Scan(&notifications)
+	suite.Require().NoError(err)
+	suite.Equal(1, notifications)
+
+	_, err = suite.service.Transfer(suite.memberID, &models.FXTransferRequest{
+		RecipientID: otherID, FromCurrency: "USD", Amount: 500,
+	})
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "insufficient balance")
+
+	_, err = suite.service.Convert(suite.memberID, &models.ConvertCurrencyRequest{
+		FromCurrency: "UGX", ToCurrency: "KES", Amount: 100,
+	})
+	suite.Require().Error(err)
+
+	// Sending to someone else counts its shilling value against the sender's limits
+	_, err = suite.db.Exec("UPDATE transaction_limit_tiers SET single_transaction_limit = 5000 WHERE tier = 0")
+	suite.Require().NoError(err)
+	_, err = suite.service.Transfer(suite.memberID, &models.FXTransferRequest{
+		RecipientID: suite.chairID, FromCurrency: "USD", Amount: 40,
+	})
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "single transaction limit")
+	suite.Equal(49.0, suite.balance(suite.memberID, "currency", "USD"))
+}
+
+func (suite *FXTestSuite) TestChamaContributionsAndConsolidatedBalances() {
+	suite.fund(suite.memberID, "currency", "UGX", 300000)
+
+	conversion, err := suite.service.Transfer(suite.memberID, &models.FXTransferRequest{
+		ChamaID: suite.chamaID, FromCurrency: "UGX", Amount: 285000,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(9950.0, conversion.Quote.ConvertedAmount)
+	suite.Equal(9950.0, suite.balance(suite.chamaID, "chama", "KES"))
+	suite.Equal(15000.0, suite.balance(suite.memberID, "currency", "UGX"))
+
+	var contributed float64
+	err = suite.db.QueryRow("SELECT total_contributions FROM chama_members WHERE chama_id = ? AND user_id = ?",
+		suite.chamaID, suite.memberID).Scan(&contributed)
+	suite.Require().NoError(err)
+	suite.Equal(9950.0, contributed)
+
+	var transactionType string
+	err = suite.db.QueryRow("SELECT type FROM transactions WHERE id = ?", conversion.TransactionID).Scan(&transactionType)
+	suite.Require().NoError(err)
+	suite.Equal("contribution", transactionType)
+
+	_, err = suite.service.Transfer(suite.memberID, &models.FXTransferRequest{
+		ChamaID: suite.chamaID, FromCurrency: "UGX", ToCurrency: "USD", Amount: 1000,
+	})
+	suite.Require().Error(err)
+
+	err = suite.service.SetChamaBaseCurrency(suite.chamaID, suite.memberID, "USD")
+	suite.Require().Error(err)
+	suite.Require().NoError(suite.service.SetChamaBaseCurrency(suite.chamaID, suite.chairID, "USD"))
+
+	suite.fund(suite.chamaID, "currency", "UGX", 367650)
+	suite.fund(suite.chamaID, "currency", "TZS", 5000)
+
+	report, err := suite.service.GetChamaBalances(suite.chamaID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal("USD", report.BaseCurrency)
+	suite.Require().Len(report.Balances, 3)
+	suite.Equal([]string{"TZS"}, report.MissingRates)
+	suite.InDelta(9950.0/129+100, report.Total, 0.02)
+
+	outsiderID := suite.testDB.AddTestUser(suite.T(), "Outsider")
+	_, err = suite.service.GetChamaBalances(suite.chamaID, outsiderID)
+	suite.Require().Error(err)
+
+	personal, err := suite.service.GetUserBalances(suite.memberID, "")
+	suite.Require().NoError(err)
+	suite.Equal("KES", personal.BaseCurrency)
+	suite.InDelta(15000/28.5, personal.Total, 0.01)
+}
+
+func TestFXSuite(t *testing.T) {
+	suite.Run(t, new(FXTestSuite))
+}
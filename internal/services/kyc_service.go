@@ -0,0 +1,782 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"vaultke-backend/internal/models"
+)
+
+// kycValidity is how long an approved verification lasts before the user must verify again
+var kycValidity = parseKYCValidity(getEnvOrDefault("KYC_VALIDITY_MONTHS", "24"))
+
+// kycExpiryReminderWindow is how long before expiry a user is reminded to re-verify
+const kycExpiryReminderWindow = 30 * 24 * time.Hour
+
+// minKYCAge is the youngest a user may be to pass verification
+const minKYCAge = 18
+
+func parseKYCValidity(value string) int {
+	months, err := strconv.Atoi(value)
+	if err != nil || months <= 0 {
+		log.Printf("Invalid KYC_VALIDITY_MONTHS %q, using 24", value)
+		months = 24
+	}
+	return months
+}
+
+// KYCService handles identity verification submissions, their review and the tiers
+// they grant
+type KYCService struct {
+	db *sql.DB
+}
+
+// NewKYCService creates a new KYC service
+func NewKYCService(db *sql.DB) *KYCService {
+	return &KYCService{db: db}
+}
+
+// CreateSubmission starts a draft submission for a tier. Documents are attached to the
+// draft before it is submitted for review.
+func (s *KYCService) CreateSubmission(userID string, req *models.CreateKYCSubmissionRequest) (*models.KYCSubmission, error) {
+	dateOfBirth, err := time.Parse("2006-01-02", req.DateOfBirth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date of birth, expected YYYY-MM-DD")
+	}
+	now := time.Now()
+	if dateOfBirth.AddDate(minKYCAge, 0, 0).After(now) {
+		return nil, fmt.Errorf("you must be at least %d years old to verify your identity", minKYCAge)
+	}
+	idNumber := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(req.NationalIDNumber), " ", ""))
+
+	tier, expiresAt, err := currentKYCTier(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	// The current tier can be renewed once it is close to expiry; otherwise only a
+	// higher tier is worth applying for
+	renewing := expiresAt != nil && time.Until(*expiresAt) <= kycExpiryReminderWindow
+	if req.RequestedTier < tier || (req.RequestedTier == tier && !renewing) {
+		return nil, fmt.Errorf("you are already verified at tier %d", tier)
+	}
+
+	submission := &models.KYCSubmission{
+		ID:               uuid.New().String(),
+		UserID:           userID,
+		RequestedTier:    req.RequestedTier,
+		Status:           models.KYCStatusDraft,
+		FullName:         strings.TrimSpace(req.FullName),
+		NationalIDNumber: idNumber,
+		DateOfBirth:      dateOfBirth,
+		Documents:        []*models.KYCDocument{},
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO kyc_submissions (id, user_id, requested_tier, status, full_name, national_id_number, date_of_birth, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, submission.ID, userID, submission.RequestedTier, submission.Status, submission.FullName, submission.NationalIDNumber,
+		dateOfBirth.Format("2006-01-02"), now, now)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return nil, fmt.Errorf("you already have a KYC submission in progress")
+		}
+		return nil, fmt.Errorf("failed to create KYC submission: %w", err)
+	}
+	return submission, nil
+}
+
+// AddDocument attaches an uploaded document to a draft submission, replacing any
+// earlier document of the same type. It returns the replaced document, if any, so the
+// caller can remove its file.
+func (s *KYCService) AddDocument(submissionID, userID string, document *models.KYCDocument) (*models.KYCDocument, *models.KYCDocument, error) {
+	if !models.IsValidKYCDocumentType(string(document.DocumentType)) {
+		return nil, nil, fmt.Errorf("invalid document type: %s", document.DocumentType)
+	}
+	submission, err := s.getSubmission(submissionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if submission.UserID != userID {
+		return nil, nil, fmt.Errorf("KYC submission not found")
+	}
+	if submission.Status != models.KYCStatusDraft {
+		return nil, nil, fmt.Errorf("documents can only be added before the submission is sent for review")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	replaced, err := scanKYCDocument(tx.QueryRow(`
+		SELECT id, submission_id, user_id, document_type, file_name, file_path, file_id, file_size, file_type, uploaded_at
+		FROM kyc_documents WHERE submission_id = ? AND document_type = ?
+	`, submissionID, document.DocumentType))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, nil, fmt.Errorf("failed to check existing document: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM kyc_documents WHERE submission_id = ? AND document_type = ?", submissionID, document.DocumentType); err != nil {
+		return nil, nil, fmt.Errorf("failed to replace document: %w", err)
+	}
+
+	document.ID = uuid.New().String()
+	document.SubmissionID = submissionID
+	document.UserID = userID
+	document.UploadedAt = time.Now()
+	_, err = tx.Exec(`
+		INSERT INTO kyc_documents (id, submission_id, user_id, document_type, file_name, file_path, file_id, file_size, file_type, uploaded_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, document.ID, submissionID, userID, document.DocumentType, document.FileName, document.FilePath,
+		document.FileID, document.FileSize, document.FileType, document.UploadedAt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to save document: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE kyc_submissions SET updated_at = ? WHERE id = ?", document.UploadedAt, submissionID); err != nil {
+		return nil, nil, fmt.Errorf("failed to update KYC submission: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return document, replaced, nil
+}
+
+// Submit sends a draft for review once every document its tier needs is attached
+func (s *KYCService) Submit(submissionID, userID string) (*models.KYCSubmission, error) {
+	submission, err := s.getSubmission(submissionID)
+	if err != nil {
+		return nil, err
+	}
+	if submission.UserID != userID {
+		return nil, fmt.Errorf("KYC submission not found")
+	}
+	if submission.Status != models.KYCStatusDraft {
+		return nil, fmt.Errorf("KYC submission has already been sent for review")
+	}
+
+	attached := map[models.KYCDocumentType]bool{}
+	for _, document := range submission.Documents {
+		attached[document.DocumentType] = true
+	}
+	var missing []string
+	for _, documentType := range models.KYCRequiredDocuments(submission.RequestedTier) {
+		if !attached[documentType] {
+			missing = append(missing, string(documentType))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required documents: %s", strings.Join(missing, ", "))
+	}
+
+	// One identity document can only back one account
+	var otherUser string
+	err = s.db.QueryRow(`
+		SELECT user_id FROM kyc_submissions
+		WHERE national_id_number = ? AND user_id != ? AND status IN (?, ?)
+		LIMIT 1
+	`, submission.NationalIDNumber, userID, models.KYCStatusPending, models.KYCStatusApproved).Scan(&otherUser)
+	if err == nil {
+		return nil, fmt.Errorf("this national ID number is already registered to another account")
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to check national ID number: %w", err)
+	}
+
+	now := time.Now()
+	result, err := s.db.Exec(`
+		UPDATE kyc_submissions SET status = ?, submitted_at = ?, updated_at = ? WHERE id = ? AND status = ?
+	`, models.KYCStatusPending, now, now, submissionID, models.KYCStatusDraft)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit KYC submission: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("KYC submission has already been sent for review")
+	}
+
+	submission.Status = models.KYCStatusPending
+	submission.SubmittedAt = &now
+	submission.UpdatedAt = now
+	return submission, nil
+}
+
+// GetSubmission returns a submission to its owner or to an admin
+func (s *KYCService) GetSubmission(submissionID, userID string, isAdmin bool) (*models.KYCSubmission, error) {
+	submission, err := s.getSubmission(submissionID)
+	if err != nil {
+		return nil, err
+	}
+	if submission.UserID != userID && !isAdmin {
+		return nil, fmt.Errorf("KYC submission not found")
+	}
+	return submission, nil
+}
+
+// GetDocument returns a document's metadata and where its file is kept to its owner
+// or to an admin
+func (s *KYCService) GetDocument(documentID, userID string, isAdmin bool) (*models.KYCDocument, error) {
+	document, err := scanKYCDocument(s.db.QueryRow(`
+		SELECT id, submission_id, user_id, document_type, file_name, file_path, file_id, file_size, file_type, uploaded_at
+		FROM kyc_documents WHERE id = ?
+	`, documentID))
+	if err == sql.ErrNoRows || (err == nil && document.UserID != userID && !isAdmin) {
+		return nil, fmt.Errorf("document not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// GetStatus reports the user's tier, when it expires, what it unlocks and any
+// submission still in progress
+func (s *KYCService) GetStatus(userID string) (*models.KYCStatusSummary, error) {
+	tier, expiresAt, err := currentKYCTier(s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := limitStatus(s.db, userID, 0)
+	if err != nil {
+		return nil, err
+	}
+	summary := &models.KYCStatusSummary{
+		Tier:      tier,
+		TierName:  limits.TierName,
+		ExpiresAt: expiresAt,
+		Limits:    limits,
+	}
+	summary.ReverificationDue = expiresAt != nil && time.Until(*expiresAt) <= kycExpiryReminderWindow
+	if summary.MaxLoanAmount, err = maxLoanAmount(s.db, tier); err != nil {
+		return nil, err
+	}
+
+	var openID string
+	err = s.db.QueryRow("SELECT id FROM kyc_submissions WHERE user_id = ? AND status IN (?, ?)",
+		userID, models.KYCStatusDraft, models.KYCStatusPending).Scan(&openID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get open KYC submission: %w", err)
+	}
+	if openID != "" {
+		if summary.OpenSubmission, err = s.getSubmission(openID); err != nil {
+			return nil, err
+		}
+	}
+
+	var reason sql.NullString
+	err = s.db.QueryRow(`
+		SELECT rejection_reason FROM kyc_submissions WHERE user_id = ? AND status = ?
+		ORDER BY reviewed_at DESC LIMIT 1
+	`, userID, models.KYCStatusRejected).Scan(&reason)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get last rejection: %w", err)
+	}
+	if reason.Valid {
+		summary.LastRejectionReason = &reason.String
+	}
+	return summary, nil
+}
+
+// GetReviewQueue lists submissions in a status, oldest first, for admins to work through
+func (s *KYCService) GetReviewQueue(status string, limit, offset int) ([]*models.KYCSubmission, error) {
+	if status == "" {
+		status = string(models.KYCStatusPending)
+	}
+	rows, err := s.db.Query(`
+		SELECT k.id FROM kyc_submissions k
+		WHERE k.status = ?
+		ORDER BY COALESCE(k.submitted_at, k.created_at), k.created_at
+		LIMIT ? OFFSET ?
+	`, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get KYC review queue: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan KYC submission: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	submissions := []*models.KYCSubmission{}
+	for _, id := range ids {
+		submission, err := s.getSubmission(id)
+		if err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, submission)
+	}
+	return submissions, nil
+}
+
+// Approve grants the submission's tier to its user until the verification expires. Any
+// earlier approval is superseded.
+func (s *KYCService) Approve(submissionID, reviewerID string, req *models.ApproveKYCRequest) (*models.KYCSubmission, error) {
+	submission, err := s.getSubmission(submissionID)
+	if err != nil {
+		return nil, err
+	}
+	if submission.UserID == reviewerID {
+		return nil, fmt.Errorf("you cannot review your own KYC submission")
+	}
+
+	now := time.Now()
+	expiresAt := now.AddDate(0, kycValidity, 0)
+	notes := optionalString(req.Notes)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.claimForReview(tx, submissionID, reviewerID, models.KYCStatusApproved, nil, notes, &expiresAt, now); err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(`
+		UPDATE kyc_submissions SET status = ?, updated_at = ? WHERE user_id = ? AND status = ? AND id != ?
+	`, models.KYCStatusSuperseded, now, submission.UserID, models.KYCStatusApproved, submissionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to supersede earlier verification: %w", err)
+	}
+
+	// Fully verified users also carry the verified account status
+	_, err = tx.Exec(`
+		UPDATE users SET kyc_tier = ?,
+			status = CASE WHEN ? >= ? AND status = ? THEN ? ELSE status END,
+			updated_at = ?
+		WHERE id = ?
+	`, submission.RequestedTier, submission.RequestedTier, models.KYCTierVerified, models.UserStatusActive,
+		models.UserStatusVerified, now, submission.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user tier: %w", err)
+	}
+
+	if err := s.recordReview(tx, submission, reviewerID, models.KYCStatusApproved, nil, notes, now); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.notify(submission.UserID, "Identity Verified",
+		fmt.Sprintf("Your identity has been verified at tier %d. Your new limits apply immediately.", submission.RequestedTier),
+		map[string]interface{}{"submissionId": submissionID, "tier": submission.RequestedTier})
+
+	return s.getSubmission(submissionID)
+}
+
+// Reject turns a submission down with a reason the user is shown
+func (s *KYCService) Reject(submissionID, reviewerID string, req *models.RejectKYCRequest) (*models.KYCSubmission, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("a rejection reason is required")
+	}
+	submission, err := s.getSubmission(submissionID)
+	if err != nil {
+		return nil, err
+	}
+	if submission.UserID == reviewerID {
+		return nil, fmt.Errorf("you cannot review your own KYC submission")
+	}
+
+	now := time.Now()
+	notes := optionalString(req.Notes)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.claimForReview(tx, submissionID, reviewerID, models.KYCStatusRejected, &reason, notes, nil, now); err != nil {
+		return nil, err
+	}
+	if err := s.recordReview(tx, submission, reviewerID, models.KYCStatusRejected, &reason, notes, now); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.notify(submission.UserID, "Identity Verification Unsuccessful",
+		fmt.Sprintf("Your identity verification was not approved: %s. You can submit again.", reason),
+		map[string]interface{}{"submissionId": submissionID, "reason": reason})
+
+	return s.getSubmission(submissionID)
+}
+
+// GetReviews lists review decisions, newest first, optionally only those by one reviewer
+func (s *KYCService) GetReviews(reviewerID string, limit, offset int) ([]*models.KYCReview, error) {
+	query := `
+		SELECT r.id, r.submission_id, r.user_id, r.reviewer_id, COALESCE(u.first_name || ' ' || u.last_name, ''),
+			   r.decision, r.tier, r.reason, r.notes, r.created_at
+		FROM kyc_reviews r
+		LEFT JOIN users u ON u.id = r.reviewer_id
+	`
+	args := []interface{}{}
+	if reviewerID != "" {
+		query += " WHERE r.reviewer_id = ?"
+		args = append(args, reviewerID)
+	}
+	query += " ORDER BY r.created_at DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get KYC reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := []*models.KYCReview{}
+	for rows.Next() {
+		review := &models.KYCReview{}
+		var reason, notes sql.NullString
+		if err := rows.Scan(&review.ID, &review.SubmissionID, &review.UserID, &review.ReviewerID, &review.ReviewerName,
+			&review.Decision, &review.Tier, &reason, &notes, &review.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan KYC review: %w", err)
+		}
+		if reason.Valid {
+			review.Reason = &reason.String
+		}
+		if notes.Valid {
+			review.Notes = &notes.String
+		}
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read KYC reviews: %w", err)
+	}
+	return reviews, nil
+}
+
+// CheckLoanEligibility fails when amount is more than the user's tier may borrow
+func (s *KYCService) CheckLoanEligibility(userID string, amount float64) error {
+	return checkLoanEligibility(s.db, userID, amount)
+}
+
+// ProcessExpiringVerifications reminds users whose verification is about to expire and
+// drops those whose verification has expired back to tier 0 until they verify again
+func (s *KYCService) ProcessExpiringVerifications() {
+	now := time.Now()
+
+	rows, err := s.db.Query(`
+		SELECT id, user_id, expires_at FROM kyc_submissions
+		WHERE status = ? AND julianday(expires_at) > julianday(?) AND julianday(expires_at) <= julianday(?)
+		AND expiry_reminder_sent_at IS NULL
+	`, models.KYCStatusApproved, now, now.Add(kycExpiryReminderWindow))
+	if err != nil {
+		log.Printf("Failed to load expiring KYC verifications: %v", err)
+	} else {
+		type reminder struct {
+			id, userID string
+			expiresAt  time.Time
+		}
+		var reminders []reminder
+		for rows.Next() {
+			var r reminder
+			if err := rows.Scan(&r.id, &r.userID, &r.expiresAt); err == nil {
+				reminders = append(reminders, r)
+			}
+		}
+		rows.Close()
+
+		for _, r := range reminders {
+			result, err := s.db.Exec("UPDATE kyc_submissions SET expiry_reminder_sent_at = ? WHERE id = ? AND expiry_reminder_sent_at IS NULL", now, r.id)
+			if err != nil {
+				log.Printf("Failed to mark KYC reminder for %s: %v", r.id, err)
+				continue
+			}
+			if n, _ := result.RowsAffected(); n == 0 {
+				continue
+			}
+			s.notify(r.userID, "Re-verify Your Identity",
+				fmt.Sprintf("Your identity verification expires on %s. Re-verify to keep your current limits.", r.expiresAt.Format("2 Jan 2006")),
+				map[string]interface{}{"submissionId": r.id, "expiresAt": r.expiresAt})
+		}
+	}
+
+	expired, err := s.dueIDs(`
+		SELECT id FROM kyc_submissions WHERE status = ? AND julianday(expires_at) <= julianday(?)
+	`, models.KYCStatusApproved, now)
+	if err != nil {
+		log.Printf("Failed to load expired KYC verifications: %v", err)
+	}
+	for _, submissionID := range expired {
+		if err := s.expire(submissionID, now); err != nil {
+			log.Printf("Failed to expire KYC verification %s: %v", submissionID, err)
+		}
+	}
+}
+
+func (s *KYCService) expire(submissionID string, now time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var userID string
+	if err := tx.QueryRow("SELECT user_id FROM kyc_submissions WHERE id = ?", submissionID).Scan(&userID); err != nil {
+		return fmt.Errorf("failed to get KYC submission: %w", err)
+	}
+	result, err := tx.Exec("UPDATE kyc_submissions SET status = ?, updated_at = ? WHERE id = ? AND status = ?",
+		models.KYCStatusExpired, now, submissionID, models.KYCStatusApproved)
+	if err != nil {
+		return fmt.Errorf("failed to expire KYC submission: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil
+	}
+	_, err = tx.Exec(`
+		UPDATE users SET kyc_tier = ?, status = CASE WHEN status = ? THEN ? ELSE status END, updated_at = ? WHERE id = ?
+	`, models.KYCTierUnverified, models.UserStatusVerified, models.UserStatusActive, now, userID)
+	if err != nil {
+		return fmt.Errorf("failed to reset user tier: %w", err)
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "kyc_submission",
+		EntityID:   submissionID,
+		Details:    map[string]interface{}{"change": "expired", "userId": userID},
+	})
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.notify(userID, "Identity Verification Expired",
+		"Your identity verification has expired and your limits have been reduced. Submit your documents again to restore them.",
+		map[string]interface{}{"submissionId": submissionID})
+	return nil
+}
+
+// claimForReview moves a pending submission to its decision, failing if another
+// reviewer got to it first
+func (s *KYCService) claimForReview(tx *sql.Tx, submissionID, reviewerID string, decision models.KYCStatus, reason, notes *string, expiresAt *time.Time, now time.Time) error {
+	result, err := tx.Exec(`
+		UPDATE kyc_submissions
+		SET status = ?, reviewed_by = ?, reviewed_at = ?, rejection_reason = ?, review_notes = ?, expires_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, decision, reviewerID, now, reason, notes, expiresAt, now, submissionID, models.KYCStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to review KYC submission: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("KYC submission is not awaiting review")
+	}
+	return nil
+}
+
+// recordReview keeps the decision in the review history and the platform audit log
+func (s *KYCService) recordReview(tx *sql.Tx, submission *models.KYCSubmission, reviewerID string, decision models.KYCStatus, reason, notes *string, now time.Time) error {
+	_, err := tx.Exec(`
+		INSERT INTO kyc_reviews (id, submission_id, user_id, reviewer_id, decision, tier, reason, notes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, uuid.New().String(), submission.ID, submission.UserID, reviewerID, decision, submission.RequestedTier, reason, notes, now)
+	if err != nil {
+		return fmt.Errorf("failed to record KYC review: %w", err)
+	}
+
+	details := map[string]interface{}{
+		"decision": decision,
+		"userId":   submission.UserID,
+		"tier":     submission.RequestedTier,
+	}
+	if reason != nil {
+		details["reason"] = *reason
+	}
+	return NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ActorID:    &reviewerID,
+		Action:     models.AuditActionApproval,
+		EntityType: "kyc_submission",
+		EntityID:   submission.ID,
+		Details:    details,
+	})
+}
+
+func (s *KYCService) getSubmission(submissionID string) (*models.KYCSubmission, error) {
+	submission := &models.KYCSubmission{}
+	var submittedAt, reviewedAt, expiresAt sql.NullTime
+	var reviewedBy, rejectionReason, reviewNotes sql.NullString
+	err := s.db.QueryRow(`
+		SELECT k.id, k.user_id, COALESCE(u.first_name || ' ' || u.last_name, ''), k.requested_tier, k.status, k.full_name,
+			   k.national_id_number, k.date_of_birth, k.submitted_at, k.reviewed_by, k.reviewed_at, k.rejection_reason,
+			   k.review_notes, k.expires_at, k.created_at, k.updated_at
+		FROM kyc_submissions k
+		LEFT JOIN users u ON u.id = k.user_id
+		WHERE k.id = ?
+	`, submissionID).Scan(&submission.ID, &submission.UserID, &submission.UserName, &submission.RequestedTier, &submission.Status,
+		&submission.FullName, &submission.NationalIDNumber, &submission.DateOfBirth, &submittedAt, &reviewedBy, &reviewedAt,
+		&rejectionReason, &reviewNotes, &expiresAt, &submission.CreatedAt, &submission.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("KYC submission not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get KYC submission: %w", err)
+	}
+	if submittedAt.Valid {
+		submission.SubmittedAt = &submittedAt.Time
+	}
+	if reviewedBy.Valid {
+		submission.ReviewedBy = &reviewedBy.String
+	}
+	if reviewedAt.Valid {
+		submission.ReviewedAt = &reviewedAt.Time
+	}
+	if rejectionReason.Valid {
+		submission.RejectionReason = &rejectionReason.String
+	}
+	if reviewNotes.Valid {
+		submission.ReviewNotes = &reviewNotes.String
+	}
+	if expiresAt.Valid {
+		submission.ExpiresAt = &expiresAt.Time
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, submission_id, user_id, document_type, file_name, file_path, file_id, file_size, file_type, uploaded_at
+		FROM kyc_documents WHERE submission_id = ? ORDER BY uploaded_at
+	`, submissionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get KYC documents: %w", err)
+	}
+	defer rows.Close()
+
+	submission.Documents = []*models.KYCDocument{}
+	for rows.Next() {
+		document, err := scanKYCDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		submission.Documents = append(submission.Documents, document)
+	}
+	return submission, rows.Err()
+}
+
+func (s *KYCService) dueIDs(query string, args ...interface{}) ([]string, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, rows.Err()
+}
+
+func (s *KYCService) notify(userID, title, message string, data map[string]interface{}) {
+	if err := NewNotificationService(s.db, nil).CreateInAppNotification(userID, "alert", "kyc", title, message, data); err != nil {
+		log.Printf("Failed to send KYC notification to %s: %v", userID, err)
+	}
+}
+
+// currentKYCTier returns the user's tier and, when it came from an approval, when that
+// approval expires
+func currentKYCTier(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, userID string) (int, *time.Time, error) {
+	var tier int
+	err := q.QueryRow("SELECT COALESCE(kyc_tier, 0) FROM users WHERE id = ?", userID).Scan(&tier)
+	if err == sql.ErrNoRows {
+		return 0, nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get user tier: %w", err)
+	}
+
+	var expiresAt sql.NullTime
+	err = q.QueryRow(`
+		SELECT expires_at FROM kyc_submissions WHERE user_id = ? AND status = ? ORDER BY reviewed_at DESC LIMIT 1
+	`, userID, models.KYCStatusApproved).Scan(&expiresAt)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, nil, fmt.Errorf("failed to get verification expiry: %w", err)
+	}
+	if expiresAt.Valid {
+		return tier, &expiresAt.Time, nil
+	}
+	return tier, nil, nil
+}
+
+// maxLoanAmount is the largest loan a member at tier may apply for, from the highest
+// configured tier at or below it
+func maxLoanAmount(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, tier int) (float64, error) {
+	var amount float64
+	err := q.QueryRow("SELECT max_loan_amount FROM transaction_limit_tiers WHERE tier <= ? ORDER BY tier DESC LIMIT 1", tier).Scan(&amount)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to get loan ceiling: %w", err)
+	}
+	return amount, nil
+}
+
+// checkLoanEligibility fails when the borrower's verification tier does not allow a
+// loan of amount
+func checkLoanEligibility(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, userID string, amount float64) error {
+	tier, _, err := currentKYCTier(q, userID)
+	if err != nil {
+		return err
+	}
+	ceiling, err := maxLoanAmount(q, tier)
+	if err != nil {
+		return err
+	}
+	if ceiling <= 0 {
+		return fmt.Errorf("verify your identity to become eligible for loans")
+	}
+	if amount > ceiling {
+		return fmt.Errorf("loan amount exceeds the KES %.2f allowed at your verification tier", ceiling)
+	}
+	return nil
+}
+
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func scanKYCDocument(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.KYCDocument, error) {
+	document := &models.KYCDocument{}
+	var fileID sql.NullString
+	err := row.Scan(&document.ID, &document.SubmissionID, &document.UserID, &document.DocumentType, &document.FileName,
+		&document.FilePath, &fileID, &document.FileSize, &document.FileType, &document.UploadedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan KYC document: %w", err)
+	}
+	if fileID.Valid {
+		document.FileID = &fileID.String
+	}
+	return document, nil
+}
@@ -0,0 +1,239 @@
+package services_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type KYCTestSuite struct {
+	suite.Suite
+	testDB   *helpers.TestDatabase
+	db       *sql.DB
+	service  *services.KYCService
+	adminID  string
+	memberID string
+}
+
+func (suite *KYCTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewKYCService(suite.db)
+	suite.adminID = suite.testDB.AddTestUser(suite.T(), "Admin")
+	suite.memberID = suite.testDB.AddTestUser(suite.T(), "Member")
+}
+
+func (suite *KYCTestSuite) start(userID string, tier int, idNumber string) *models.KYCSubmission {
+	submission, err := suite.service.CreateSubmission(userID, &models.CreateKYCSubmissionRequest{
+		RequestedTier:    tier,
+		FullName:         "Jane Wanjiru",
+		NationalIDNumber: idNumber,
+		DateOfBirth:      "1990-04-12",
+	})
+	suite.Require().NoError(err)
+	return submission
+}
+
+func (suite *KYCTestSuite) attach(submissionID, userID string, documentTypes ...models.KYCDocumentType) {
+	for _, documentType := range documentTypes {
+		_, _, err := suite.service.AddDocument(submissionID, userID, &models.KYCDocument{
+			DocumentType: documentType,
+			FileName:     string(documentType) + ".jpg",
+			FilePath:     "/tmp/" + string(documentType) + ".jpg",
+			FileSize:     1024,
+			FileType:     "image/jpeg",
+		})
+		suite.Require().NoError(err)
+	}
+}
+
+func (suite *KYCTestSuite) tier(userID string) (int, string) {
+	var tier int
+	var status string
+	err := suite.db.QueryRow("SELECT kyc_tier, status FROM users WHERE id = ?", userID).Scan(&tier, &status)
+	suite.Require().NoError(err)
+	return tier, status
+}
+
+func (suite *KYCTestSuite) TestSubmissionReviewAndAudit() {
+	submission := suite.start(suite.memberID, models.KYCTierVerified, "1234 5678")
+	suite.Equal("12345678", submission.NationalIDNumber)
+	suite.Equal(models.KYCStatusDraft, submission.Status)
+
+	_, err := suite.service.CreateSubmission(suite.memberID, &models.CreateKYCSubmissionRequest{
+		RequestedTier: 3, FullName: "Jane", NationalIDNumber: "12345678", DateOfBirth: "1990-04-12",
+	})
+	suite.Require().Error(err, "only one submission can be open")
+
+	suite.attach(submission.ID, suite.memberID, models.KYCDocumentIDFront)
+	_, err = suite.service.Submit(submission.ID, suite.memberID)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "national_id_back")
+
+	// Re-uploading a document replaces the earlier file
+	suite.attach(submission.ID, suite.memberID, models.KYCDocumentIDBack, models.KYCDocumentSelfie)
+	_, replaced, err := suite.service.AddDocument(submission.ID, suite.memberID, &models.KYCDocument{
+		DocumentType: models.KYCDocumentSelfie, FileName: "retake.jpg", FilePath: "/tmp/retake.jpg", FileSize: 2048, FileType: "image/jpeg",
+	})
+	suite.Require().NoError(err)
+	suite.Require().NotNil(replaced)
+	suite.Equal("/tmp/selfie.jpg", replaced.FilePath)
+
+	submitted, err := suite.service.Submit(submission.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(models.KYCStatusPending, submitted.Status)
+	suite.Require().Len(submitted.Documents, 3)
+
+	_, _, err = suite.service.AddDocument(submission.ID, suite.memberID, &models.KYCDocument{DocumentType: models.KYCDocumentIDFront})
+	suite.Require().Error(err, "submitted documents are frozen")
+
+	queue, err := suite.service.GetReviewQueue("", 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(queue, 1)
+	suite.Equal(submission.ID, queue[0].ID)
+
+	_, err = suite.service.Approve(submission.ID, suite.memberID, &models.ApproveKYCRequest{})
+	suite.Require().Error(err, "users cannot review themselves")
+
+	approved, err := suite.service.Approve(submission.ID, suite.adminID, &models.ApproveKYCRequest{Notes: "Photo matches"})
+	suite.Require().NoError(err)
+	suite.Equal(models.KYCStatusApproved, approved.Status)
+	suite.Require().NotNil(approved.ExpiresAt)
+	suite.True(approved.ExpiresAt.After(time.Now().AddDate(1, 0, 0)))
+
+	tier, status := suite.tier(suite.memberID)
+	suite.Equal(models.KYCTierVerified, tier)
+	suite.Equal("verified", status)
+
+	_, err = suite.service.Reject(submission.ID, suite.adminID, &models.RejectKYCRequest{Reason: "Blurry"})
+	suite.Require().Error(err, "a decision cannot be reviewed twice")
+
+	summary, err := suite.service.GetStatus(suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal("Verified", summary.TierName)
+	suite.Equal(500000.0, summary.MaxLoanAmount)
+	suite.Equal(1000000.0, summary.Limits.SingleTransactionLimit)
+	suite.False(summary.ReverificationDue)
+
+	reviews, err := suite.service.GetReviews(suite.adminID, 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(reviews, 1)
+	suite.Equal(models.KYCStatusApproved, reviews[0].Decision)
+	suite.Equal("Admin User", reviews[0].ReviewerName)
+
+	var audited int
+	err = suite.db.QueryRow("SELECT COUNT(*) FROM audit_log WHERE entity_type = 'kyc_submission' AND entity_id = ? AND actor_id = ?",
+		submission.ID, suite.adminID).Scan(&audited)
+	suite.Require().NoError(err)
+	suite.Equal(1, audited)
+
+	// The same ID number cannot verify a second account
+	otherID := suite.testDB.AddTestUser(suite.T(), "Other")
+	other := suite.start(otherID, models.KYCTierBasic, "12345678")
+	suite.attach(other.ID, otherID, models.KYCDocumentIDFront)
+	_, err = suite.service.Submit(other.ID, otherID)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "another account")
+}
+
+func (suite *KYCTestSuite) TestRejectionNeedsReasonAndAllowsResubmission() {
+	submission := suite.start(suite.memberID, models.KYCTierBasic, "A1234567")
+	suite.attach(submission.ID, suite.memberID, models.KYCDocumentIDFront)
+	_, err := suite.service.Submit(submission.ID, suite.memberID)
+	suite.Require().NoError(err)
+
+	_, err = suite.service.Reject(submission.ID, suite.adminID, &models.RejectKYCRequest{Reason: "  "})
+	suite.Require().Error(err)
+	rejected, err := suite.service.Reject(submission.ID, suite.adminID, &models.RejectKYCRequest{Reason: "ID image is unreadable"})
+	suite.Require().NoError(err)
+	suite.Equal(models.KYCStatusRejected, rejected.Status)
+
+	tier, _ := suite.tier(suite.memberID)
+	suite.Equal(0, tier)
+
+	summary, err := suite.service.GetStatus(suite.memberID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(summary.LastRejectionReason)
+	suite.Equal("ID image is unreadable", *summary.LastRejectionReason)
+	suite.Nil(summary.OpenSubmission)
+
+	suite.start(suite.memberID, models.KYCTierBasic, "A1234567")
+
+	var alerts int
+	err = suite.db.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = ? AND title = 'Identity Verification Unsuccessful'", suite.memberID).Scan(&alerts)
+	suite.Require().NoError(err)
+	suite.Equal(1, alerts)
+
+	_, err = suite.service.CreateSubmission(suite.testDB.AddTestUser(suite.T(), "Minor"), &models.CreateKYCSubmissionRequest{
+		RequestedTier: 1, FullName: "Minor", NationalIDNumber: "99999999", DateOfBirth: time.Now().AddDate(-16, 0, 0).Format("2006-01-02"),
+	})
+	suite.Require().Error(err)
+}
+
+func (suite *KYCTestSuite) TestTiersGateLoansAndExpire() {
+	err := suite.service.CheckLoanEligibility(suite.memberID, 1000)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "verify your identity")
+
+	submission := suite.start(suite.memberID, models.KYCTierBasic, "B7654321")
+	suite.attach(submission.ID, suite.memberID, models.KYCDocumentIDFront)
+	_, err = suite.service.Submit(submission.ID, suite.memberID)
+	suite.Require().NoError(err)
+	_, err = suite.service.Approve(submission.ID, suite.adminID, &models.ApproveKYCRequest{})
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(suite.service.CheckLoanEligibility(suite.memberID, 50000))
+	suite.Require().Error(suite.service.CheckLoanEligibility(suite.memberID, 60000))
+
+	tier, status := suite.tier(suite.memberID)
+	suite.Equal(models.KYCTierBasic, tier)
+	suite.Equal("active", status, "basic verification does not mark the account verified")
+
+	_, err = suite.service.CreateSubmission(suite.memberID, &models.CreateKYCSubmissionRequest{
+		RequestedTier: 1, FullName: "Jane", NationalIDNumber: "B7654321", DateOfBirth: "1990-04-12",
+	})
+	suite.Require().Error(err, "the current tier cannot be renewed long before expiry")
+
+	// Near expiry the user is reminded once and may renew
+	_, err = suite.db.Exec("UPDATE kyc_submissions SET expires_at = ? WHERE id = ?", time.Now().Add(10*24*time.Hour), submission.ID)
+	suite.Require().NoError(err)
+	suite.service.ProcessExpiringVerifications()
+	suite.service.ProcessExpiringVerifications()
+
+	var reminders int
+	err = suite.db.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = ? AND title = 'Re-verify Your Identity'", suite.memberID).Scan(&reminders)
+	suite.Require().NoError(err)
+	suite.Equal(1, reminders)
+
+	summary, err := suite.service.GetStatus(suite.memberID)
+	suite.Require().NoError(err)
+	suite.True(summary.ReverificationDue)
+
+	// Once expired the user drops back to tier 0
+	_, err = suite.db.Exec("UPDATE kyc_submissions SET expires_at = ? WHERE id = ?", time.Now().Add(-time.Minute), submission.ID)
+	suite.Require().NoError(err)
+	suite.service.ProcessExpiringVerifications()
+
+	tier, _ = suite.tier(suite.memberID)
+	suite.Equal(models.KYCTierUnverified, tier)
+	suite.Require().Error(suite.service.CheckLoanEligibility(suite.memberID, 1000))
+
+	expired, err := suite.service.GetSubmission(submission.ID, suite.memberID, false)
+	suite.Require().NoError(err)
+	suite.Equal(models.KYCStatusExpired, expired.Status)
+
+	_, err = suite.service.GetSubmission(submission.ID, suite.adminID, false)
+	suite.Require().Error(err, "submissions are private to their owner")
+	_, err = suite.service.GetSubmission(submission.ID, suite.adminID, true)
+	suite.Require().NoError(err)
+}
+
+func TestKYCSuite(t *testing.T) {
+	suite.Run(t, new(KYCTestSuite))
+}
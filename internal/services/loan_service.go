@@ -38,6 +38,11 @@ func (s *LoanService) ApplyForLoan(application *models.LoanApplication, borrower
 		return nil, fmt.Errorf("user is not a member of this chama")
 	}
 
+	// Loan size is capped by the borrower's verification tier
+	if err := checkLoanEligibility(s.db, borrowerID, application.Amount); err != nil {
+		return nil, err
+	}
+
 	// Check if user has any active loans
 	hasActiveLoan, err := s.hasActiveLoan(borrowerID, chamaID)
 	if err != nil {
@@ -287,6 +292,24 @@ func (s *LoanService) ApproveLoan(loanID, approverID string, approval *models.Lo
 		}
 	}
 
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    loan.ChamaID,
+		ActorID:    &approverID,
+		Action:     models.AuditActionApproval,
+		EntityType: "loan",
+		EntityID:   loanID,
+		Amount:     &loan.Amount,
+		Details: map[string]interface{}{
+			"borrowerId":   loan.BorrowerID,
+			"status":       newStatus,
+			"interestRate": loan.InterestRate,
+			"totalAmount":  totalAmount,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -0,0 +1,864 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"vaultke-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MeetingGovernanceService handles meeting agendas, motions and resolutions
+type MeetingGovernanceService struct {
+	db *sql.DB
+}
+
+// NewMeetingGovernanceService creates a new meeting governance service
+func NewMeetingGovernanceService(db *sql.DB) *MeetingGovernanceService {
+	return &MeetingGovernanceService{db: db}
+}
+
+// meetingInfo holds the meeting fields governance rules depend on
+type meetingInfo struct {
+	ID      string
+	ChamaID string
+	Title   string
+	Status  string
+}
+
+// AddAgendaItem adds an item to a meeting agenda. Items are appended unless a position is given.
+func (s *MeetingGovernanceService) AddAgendaItem(meetingID, userID string, req *models.CreateAgendaItemRequest) (*models.AgendaItem, error) {
+	meeting, err := s.getMeeting(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isMeetingOfficial(userID, meeting.ChamaID) {
+		return nil, fmt.Errorf("only the chairperson or secretary can manage the agenda")
+	}
+	if meeting.Status == "ended" || meeting.Status == "cancelled" {
+		return nil, fmt.Errorf("cannot change the agenda of a meeting that has %s", meeting.Status)
+	}
+	if req.PresenterID != nil && *req.PresenterID != "" && !s.isMember(*req.PresenterID, meeting.ChamaID) {
+		return nil, fmt.Errorf("presenter must be an active member of the chama")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM meeting_agenda_items WHERE meeting_id = ?", meetingID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to count agenda items: %w", err)
+	}
+
+	position := count + 1
+	if req.Position != nil && *req.Position <= count {
+		position = *req.Position
+		_, err = tx.Exec(`
+			UPDATE meeting_agenda_items SET position = position + 1, updated_at = ?
+			WHERE meeting_id = ? AND position >= ?
+		`, time.Now(), meetingID, position)
+		if err != nil {
+			return nil, fmt.Errorf("failed to shift agenda items: %w", err)
+		}
+	}
+
+	now := time.Now()
+	item := &models.AgendaItem{
+		ID:               uuid.New().String(),
+		MeetingID:        meetingID,
+		Position:         position,
+		Title:            strings.TrimSpace(req.Title),
+		Description:      req.Description,
+		PresenterID:      req.PresenterID,
+		AllocatedMinutes: req.AllocatedMinutes,
+		Status:           models.AgendaItemStatusPending,
+		CreatedBy:        userID,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO meeting_agenda_items (
+			id, meeting_id, position, title, description, presenter_id,
+			allocated_minutes, status, created_by, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, item.ID, item.MeetingID, item.Position, item.Title, item.Description, item.PresenterID,
+		item.AllocatedMinutes, item.Status, item.CreatedBy, item.CreatedAt, item.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agenda item: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return item, nil
+}
+
+// GetAgenda returns the agenda of a meeting ordered by position
+func (s *MeetingGovernanceService) GetAgenda(meetingID, userID string) ([]models.AgendaItem, error) {
+	meeting, err := s.getMeeting(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isMember(userID, meeting.ChamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	return s.getAgendaItems(meetingID)
+}
+
+// UpdateAgendaItem updates an agenda item's details or status
+func (s *MeetingGovernanceService) UpdateAgendaItem(meetingID, itemID, userID string, req *models.UpdateAgendaItemRequest) (*models.AgendaItem, error) {
+	meeting, err := s.getMeeting(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isMeetingOfficial(userID, meeting.ChamaID) {
+		return nil, fmt.Errorf("only the chairperson or secretary can manage the agenda")
+	}
+	if req.PresenterID != nil && *req.PresenterID != "" && !s.isMember(*req.PresenterID, meeting.ChamaID) {
+		return nil, fmt.Errorf("presenter must be an active member of the chama")
+	}
+
+	setParts := []string{}
+	args := []interface{}{}
+	if req.Title != nil {
+		setParts = append(setParts, "title = ?")
+		args = append(args, strings.TrimSpace(*req.Title))
+	}
+	if req.Description != nil {
+		setParts = append(setParts, "description = ?")
+		args = append(args, *req.Description)
+	}
+	if req.PresenterID != nil {
+		setParts = append(setParts, "presenter_id = NULLIF(?, '')")
+		args = append(args, *req.PresenterID)
+	}
+	if req.AllocatedMinutes != nil {
+		setParts = append(setParts, "allocated_minutes = ?")
+		args = append(args, *req.AllocatedMinutes)
+	}
+	if req.Status != nil {
+		setParts = append(setParts, "status = ?")
+		args = append(args, *req.Status)
+	}
+	if len(setParts) == 0 {
+		return nil, fmt.Errorf("no fields to update")
+	}
+
+	setParts = append(setParts, "updated_at = ?")
+	args = append(args, time.Now(), itemID, meetingID)
+
+	result, err := s.db.Exec(
+		"UPDATE meeting_agenda_items SET "+strings.Join(setParts, ", ")+" WHERE id = ? AND meeting_id = ?",
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update agenda item: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("agenda item not found")
+	}
+
+	return s.getAgendaItem(itemID)
+}
+
+// DeleteAgendaItem removes an agenda item and closes the gap in positions
+func (s *MeetingGovernanceService) DeleteAgendaItem(meetingID, itemID, userID string) error {
+	meeting, err := s.getMeeting(meetingID)
+	if err != nil {
+		return err
+	}
+	if !s.isMeetingOfficial(userID, meeting.ChamaID) {
+		return fmt.Errorf("only the chairperson or secretary can manage the agenda")
+	}
+
+	item, err := s.getAgendaItem(itemID)
+	if err != nil {
+		return err
+	}
+	if item.MeetingID != meetingID {
+		return fmt.Errorf("agenda item not found")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE meeting_motions SET agenda_item_id = NULL WHERE agenda_item_id = ?", itemID); err != nil {
+		return fmt.Errorf("failed to detach motions: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM meeting_agenda_items WHERE id = ?", itemID); err != nil {
+		return fmt.Errorf("failed to delete agenda item: %w", err)
+	}
+	_, err = tx.Exec(`
+		UPDATE meeting_agenda_items SET position = position - 1, updated_at = ?
+		WHERE meeting_id = ? AND position > ?
+	`, time.Now(), meetingID, item.Position)
+	if err != nil {
+		return fmt.Errorf("failed to renumber agenda items: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ReorderAgenda sets the agenda order to the given list of item IDs
+func (s *MeetingGovernanceService) ReorderAgenda(meetingID, userID string, itemIDs []string) ([]models.AgendaItem, error) {
+	meeting, err := s.getMeeting(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isMeetingOfficial(userID, meeting.ChamaID) {
+		return nil, fmt.Errorf("only the chairperson or secretary can manage the agenda")
+	}
+
+	existing, err := s.getAgendaItems(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) != len(itemIDs) {
+		return nil, fmt.Errorf("reorder must include every agenda item exactly once")
+	}
+	known := make(map[string]bool, len(existing))
+	for _, item := range existing {
+		known[item.ID] = true
+	}
+	for _, id := range itemIDs {
+		if !known[id] {
+			return nil, fmt.Errorf("reorder must include every agenda item exactly once")
+		}
+		delete(known, id)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for i, id := range itemIDs {
+		if _, err := tx.Exec("UPDATE meeting_agenda_items SET position = ?, updated_at = ? WHERE id = ?", i+1, now, id); err != nil {
+			return nil, fmt.Errorf("failed to reorder agenda: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.getAgendaItems(meetingID)
+}
+
+// ProposeMotion tables a new motion. The meeting must be in progress and the proposer present.
+func (s *MeetingGovernanceService) ProposeMotion(meetingID, userID string, req *models.CreateMotionRequest) (*models.Motion, error) {
+	meeting, err := s.getMeeting(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if meeting.Status != "active" {
+		return nil, fmt.Errorf("motions can only be proposed while the meeting is in progress")
+	}
+	if !s.isPresent(meetingID, userID) {
+		return nil, fmt.Errorf("only members marked present can propose motions")
+	}
+	if req.AgendaItemID != nil && *req.AgendaItemID != "" {
+		item, err := s.getAgendaItem(*req.AgendaItemID)
+		if err != nil || item.MeetingID != meetingID {
+			return nil, fmt.Errorf("agenda item not found")
+		}
+	}
+
+	now := time.Now()
+	motion := &models.Motion{
+		ID:           uuid.New().String(),
+		MeetingID:    meetingID,
+		ChamaID:      meeting.ChamaID,
+		AgendaItemID: req.AgendaItemID,
+		Title:        strings.TrimSpace(req.Title),
+		Description:  req.Description,
+		ProposedBy:   userID,
+		Status:       models.MotionStatusProposed,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO meeting_motions (
+			id, meeting_id, chama_id, agenda_item_id, title, description,
+			proposed_by, status, created_at, updated_at
+		) VALUES (?, ?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?)
+	`, motion.ID, motion.MeetingID, motion.ChamaID, motion.AgendaItemID, motion.Title,
+		motion.Description, motion.ProposedBy, motion.Status, motion.CreatedAt, motion.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create motion: %w", err)
+	}
+
+	return motion, nil
+}
+
+// SecondMotion records a present member, other than the proposer, as seconder
+func (s *MeetingGovernanceService) SecondMotion(meetingID, motionID, userID string) (*models.Motion, error) {
+	motion, err := s.getMotionForMeeting(meetingID, motionID)
+	if err != nil {
+		return nil, err
+	}
+	if motion.Status != models.MotionStatusProposed {
+		return nil, fmt.Errorf("motion cannot be seconded in its current state (%s)", motion.Status)
+	}
+	if motion.ProposedBy == userID {
+		return nil, fmt.Errorf("the proposer cannot second their own motion")
+	}
+	if !s.isPresent(meetingID, userID) {
+		return nil, fmt.Errorf("only members marked present can second motions")
+	}
+
+	now := time.Now()
+	_, err = s.db.Exec(`
+		UPDATE meeting_motions
+		SET seconded_by = ?, seconded_at = ?, status = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, userID, now, models.MotionStatusSeconded, now, motionID, models.MotionStatusProposed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to second motion: %w", err)
+	}
+
+	return s.getMotion(motionID)
+}
+
+// WithdrawMotion lets the proposer withdraw a motion before voting opens
+func (s *MeetingGovernanceService) WithdrawMotion(meetingID, motionID, userID string) (*models.Motion, error) {
+	motion, err := s.getMotionForMeeting(meetingID, motionID)
+	if err != nil {
+		return nil, err
+	}
+	if motion.ProposedBy != userID {
+		return nil, fmt.Errorf("only the proposer can withdraw a motion")
+	}
+	if motion.Status != models.MotionStatusProposed && motion.Status != models.MotionStatusSeconded {
+		return nil, fmt.Errorf("motion cannot be withdrawn once voting has opened")
+	}
+
+	result, err := s.db.Exec(`
+		UPDATE meeting_motions SET status = ?, updated_at = ?
+		WHERE id = ? AND status IN (?, ?)
+	`, models.MotionStatusWithdrawn, time.Now(), motionID, models.MotionStatusProposed, models.MotionStatusSeconded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to withdraw motion: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected != 1 {
+		return nil, fmt.Errorf("motion cannot be withdrawn once voting has opened")
+	}
+
+	return s.getMotion(motionID)
+}
+
+// OpenVoting opens a live vote on a seconded motion
+func (s *MeetingGovernanceService) OpenVoting(meetingID, motionID, userID string) (*models.Motion, error) {
+	motion, err := s.getMotionForMeeting(meetingID, motionID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isMeetingOfficial(userID, motion.ChamaID) {
+		return nil, fmt.Errorf("only the chairperson or secretary can open voting")
+	}
+	if motion.Status != models.MotionStatusSeconded {
+		return nil, fmt.Errorf("a motion must be seconded before voting opens")
+	}
+	meeting, err := s.getMeeting(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if meeting.Status != "active" {
+		return nil, fmt.Errorf("voting can only be opened while the meeting is in progress")
+	}
+
+	// Guard on both statuses so a withdrawal or the meeting ending in between
+	// cannot be overwritten by a stale read
+	now := time.Now()
+	result, err := s.db.Exec(`
+		UPDATE meeting_motions SET status = ?, voting_opened_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+		  AND EXISTS (SELECT 1 FROM meetings WHERE id = ? AND status = 'active')
+	`, models.MotionStatusVoting, now, now, motionID, models.MotionStatusSeconded, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open voting: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected != 1 {
+		return nil, fmt.Errorf("voting can no longer be opened for this motion")
+	}
+
+	return s.getMotion(motionID)
+}
+
+// CastVote records a present member's vote on a motion that is open for voting
+func (s *MeetingGovernanceService) CastVote(meetingID, motionID, userID string, choice models.MotionVoteChoice) (*models.Motion, error) {
+	if !models.IsValidMotionVoteChoice(string(choice)) {
+		return nil, fmt.Errorf("invalid vote choice")
+	}
+
+	motion, err := s.getMotionForMeeting(meetingID, motionID)
+	if err != nil {
+		return nil, err
+	}
+	if motion.Status != models.MotionStatusVoting {
+		return nil, fmt.Errorf("voting is not open for this motion")
+	}
+	if !s.isPresent(meetingID, userID) || !s.isMember(userID, motion.ChamaID) {
+		return nil, fmt.Errorf("only members marked present can vote")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var existing int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM meeting_motion_votes WHERE motion_id = ? AND user_id = ?", motionID, userID).Scan(&existing); err != nil {
+		return nil, fmt.Errorf("failed to check existing vote: %w", err)
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("user has already voted on this motion")
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO meeting_motion_votes (id, motion_id, user_id, choice, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, uuid.New().String(), motionID, userID, choice, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to record vote: %w", err)
+	}
+
+	column := map[models.MotionVoteChoice]string{
+		models.MotionVoteFor:     "votes_for",
+		models.MotionVoteAgainst: "votes_against",
+		models.MotionVoteAbstain: "abstentions",
+	}[choice]
+	result, err := tx.Exec("UPDATE meeting_motions SET "+column+" = "+column+" + 1, updated_at = ? WHERE id = ? AND status = ?",
+		time.Now(), motionID, models.MotionStatusVoting)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update vote count: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows != 1 {
+		return nil, fmt.Errorf("voting is not open for this motion")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.getMotion(motionID)
+}
+
+// CloseVoting closes the vote on a motion. A simple majority of votes cast carries the motion
+// and records it as a resolution.
+func (s *MeetingGovernanceService) CloseVoting(meetingID, motionID, userID string) (*models.Motion, *models.Resolution, error) {
+	motion, err := s.getMotionForMeeting(meetingID, motionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !s.isMeetingOfficial(userID, motion.ChamaID) {
+		return nil, nil, fmt.Errorf("only the chairperson or secretary can close voting")
+	}
+	if motion.Status != models.MotionStatusVoting {
+		return nil, nil, fmt.Errorf("voting is not open for this motion")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The outcome is decided from the counts as they stand when the vote closes, and
+	// only one close can take the motion out of voting
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE meeting_motions
+		SET status = CASE WHEN votes_for > votes_against THEN ? ELSE ? END,
+			voting_closed_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, models.MotionStatusCarried, models.MotionStatusDefeated, now, now, motionID, models.MotionStatusVoting)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to close voting: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows != 1 {
+		return nil, nil, fmt.Errorf("voting is not open for this motion")
+	}
+
+	motion, err = s.loadMotion(tx, motionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var resolution *models.Resolution
+	if motion.Status == models.MotionStatusCarried {
+		resolution, err = s.createResolution(tx, motion, userID, now)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	updated, err := s.getMotion(motionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resolution != nil {
+		log.Printf("📜 Resolution %s recorded for meeting %s", resolution.ResolutionNumber, meetingID)
+	}
+
+	// Let the proposer know the outcome
+	notificationService := NewNotificationService(s.db, nil)
+	message := fmt.Sprintf("Your motion \"%s\" was %s (%d for, %d against, %d abstained)",
+		updated.Title, updated.Status, updated.VotesFor, updated.VotesAgainst, updated.Abstentions)
+	if err := notificationService.CreateInAppNotification(updated.ProposedBy, "chama", "meeting_motion", "Motion "+string(updated.Status), message, map[string]interface{}{
+		"meetingId": meetingID,
+		"motionId":  motionID,
+		"status":    updated.Status,
+	}); err != nil {
+		log.Printf("Failed to notify proposer of motion outcome: %v", err)
+	}
+
+	return updated, resolution, nil
+}
+
+// GetMotions returns the motions tabled at a meeting with the caller's vote
+func (s *MeetingGovernanceService) GetMotions(meetingID, userID string) ([]models.MotionWithVoters, error) {
+	meeting, err := s.getMeeting(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isMember(userID, meeting.ChamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	eligible, err := s.countPresentMembers(meetingID, meeting.ChamaID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT m.id, m.meeting_id, m.chama_id, m.agenda_item_id, m.title, m.description,
+			m.proposed_by, m.seconded_by, m.seconded_at, m.status, m.votes_for, m.votes_against,
+			m.abstentions, m.voting_opened_at, m.voting_closed_at, m.created_at, m.updated_at,
+			v.choice
+		FROM meeting_motions m
+		LEFT JOIN meeting_motion_votes v ON v.motion_id = m.id AND v.user_id = ?
+		WHERE m.meeting_id = ?
+		ORDER BY m.created_at ASC
+	`, userID, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get motions: %w", err)
+	}
+	defer rows.Close()
+
+	motions := []models.MotionWithVoters{}
+	for rows.Next() {
+		var item models.MotionWithVoters
+		var choice sql.NullString
+		if err := rows.Scan(
+			&item.ID, &item.MeetingID, &item.ChamaID, &item.AgendaItemID, &item.Title, &item.Description,
+			&item.ProposedBy, &item.SecondedBy, &item.SecondedAt, &item.Status, &item.VotesFor, &item.VotesAgainst,
+			&item.Abstentions, &item.VotingOpenedAt, &item.VotingClosedAt, &item.CreatedAt, &item.UpdatedAt,
+			&choice,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan motion: %w", err)
+		}
+		if choice.Valid {
+			c := models.MotionVoteChoice(choice.String)
+			item.UserVote = &c
+		}
+		item.EligibleVoters = eligible
+		motions = append(motions, item)
+	}
+
+	return motions, nil
+}
+
+// GetMeetingResolutions returns the resolutions passed at a meeting
+func (s *MeetingGovernanceService) GetMeetingResolutions(meetingID, userID string) ([]models.Resolution, error) {
+	meeting, err := s.getMeeting(meetingID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isMember(userID, meeting.ChamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	return s.queryResolutions(`WHERE r.meeting_id = ? ORDER BY r.passed_at ASC`, meetingID)
+}
+
+// SearchResolutions searches a chama's resolutions by number, title or text
+func (s *MeetingGovernanceService) SearchResolutions(chamaID, userID, query string, limit, offset int) ([]models.Resolution, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	pattern := "%" + strings.TrimSpace(query) + "%"
+	return s.queryResolutions(`
+		WHERE r.chama_id = ? AND (r.resolution_number LIKE ? OR r.title LIKE ? OR r.text LIKE ?)
+		ORDER BY r.passed_at DESC LIMIT ? OFFSET ?
+	`, chamaID, pattern, pattern, pattern, limit, offset)
+}
+
+// LinkResolutionsToMinutes attaches a meeting's resolutions to its minutes record.
+// It is called when the minutes are approved.
+func (s *MeetingGovernanceService) LinkResolutionsToMinutes(meetingID, minutesID string) error {
+	_, err := s.db.Exec(`
+		UPDATE meeting_resolutions SET minutes_id = ? WHERE meeting_id = ?
+	`, minutesID, meetingID)
+	if err != nil {
+		return fmt.Errorf("failed to link resolutions to minutes: %w", err)
+	}
+	return nil
+}
+
+// Helper functions
+
+// createResolution records a carried motion as the chama's next numbered resolution
+// for the year. It must run in the transaction that closed the vote, so the number is
+// taken while that transaction holds the write lock.
+func (s *MeetingGovernanceService) createResolution(tx *sql.Tx, motion *models.Motion, recordedBy string, passedAt time.Time) (*models.Resolution, error) {
+	prefix := fmt.Sprintf("RES-%s-", passedAt.Format("2006"))
+	var last int
+	err := tx.QueryRow(`
+		SELECT COALESCE(MAX(CAST(substr(resolution_number, ?) AS INTEGER)), 0) FROM meeting_resolutions
+		WHERE chama_id = ? AND resolution_number LIKE ?
+	`, len(prefix)+1, motion.ChamaID, prefix+"%").Scan(&last)
+	if err != nil {
+		return nil, fmt.Errorf("failed to number resolution: %w", err)
+	}
+
+	text := motion.Title
+	if motion.Description != nil && strings.TrimSpace(*motion.Description) != "" {
+		text = *motion.Description
+	}
+
+	resolution := &models.Resolution{
+		ID:               uuid.New().String(),
+		ChamaID:          motion.ChamaID,
+		MeetingID:        motion.MeetingID,
+		MotionID:         &motion.ID,
+		ResolutionNumber: fmt.Sprintf("%s%04d", prefix, last+1),
+		Title:            motion.Title,
+		Text:             text,
+		VotesFor:         motion.VotesFor,
+		VotesAgainst:     motion.VotesAgainst,
+		Abstentions:      motion.Abstentions,
+		PassedAt:         passedAt,
+		RecordedBy:       recordedBy,
+		CreatedAt:        passedAt,
+	}
+
+	// Link immediately if the minutes have already been approved
+	var minutesID string
+	err = tx.QueryRow(`
+		SELECT id FROM meeting_minutes WHERE meeting_id = ? AND status IN ('approved', 'published')
+	`, motion.MeetingID).Scan(&minutesID)
+	if err == nil {
+		resolution.MinutesID = &minutesID
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO meeting_resolutions (
+			id, chama_id, meeting_id, motion_id, minutes_id, resolution_number, title, text,
+			votes_for, votes_against, abstentions, passed_at, recorded_by, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, resolution.ID, resolution.ChamaID, resolution.MeetingID, resolution.MotionID, resolution.MinutesID,
+		resolution.ResolutionNumber, resolution.Title, resolution.Text, resolution.VotesFor,
+		resolution.VotesAgainst, resolution.Abstentions, resolution.PassedAt, resolution.RecordedBy,
+		resolution.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record resolution: %w", err)
+	}
+
+	return resolution, nil
+}
+
+func (s *MeetingGovernanceService) queryResolutions(where string, args ...interface{}) ([]models.Resolution, error) {
+	rows, err := s.db.Query(`
+		SELECT r.id, r.chama_id, r.meeting_id, r.motion_id, r.minutes_id, r.resolution_number,
+			r.title, r.text, r.votes_for, r.votes_against, r.abstentions, r.passed_at,
+			r.recorded_by, r.created_at, COALESCE(m.title, '')
+		FROM meeting_resolutions r
+		LEFT JOIN meetings m ON m.id = r.meeting_id
+	`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resolutions: %w", err)
+	}
+	defer rows.Close()
+
+	resolutions := []models.Resolution{}
+	for rows.Next() {
+		var r models.Resolution
+		if err := rows.Scan(
+			&r.ID, &r.ChamaID, &r.MeetingID, &r.MotionID, &r.MinutesID, &r.ResolutionNumber,
+			&r.Title, &r.Text, &r.VotesFor, &r.VotesAgainst, &r.Abstentions, &r.PassedAt,
+			&r.RecordedBy, &r.CreatedAt, &r.MeetingTitle,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan resolution: %w", err)
+		}
+		resolutions = append(resolutions, r)
+	}
+
+	return resolutions, nil
+}
+
+func (s *MeetingGovernanceService) getAgendaItems(meetingID string) ([]models.AgendaItem, error) {
+	rows, err := s.db.Query(`
+		SELECT a.id, a.meeting_id, a.position, a.title, a.description, a.presenter_id,
+			u.first_name || ' ' || u.last_name, a.allocated_minutes, a.status, a.created_by,
+			a.created_at, a.updated_at
+		FROM meeting_agenda_items a
+		LEFT JOIN users u ON u.id = a.presenter_id
+		WHERE a.meeting_id = ?
+		ORDER BY a.position ASC
+	`, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agenda: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.AgendaItem{}
+	for rows.Next() {
+		var item models.AgendaItem
+		if err := rows.Scan(
+			&item.ID, &item.MeetingID, &item.Position, &item.Title, &item.Description, &item.PresenterID,
+			&item.PresenterName, &item.AllocatedMinutes, &item.Status, &item.CreatedBy,
+			&item.CreatedAt, &item.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan agenda item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (s *MeetingGovernanceService) getAgendaItem(itemID string) (*models.AgendaItem, error) {
+	var item models.AgendaItem
+	err := s.db.QueryRow(`
+		SELECT id, meeting_id, position, title, description, presenter_id, allocated_minutes,
+			status, created_by, created_at, updated_at
+		FROM meeting_agenda_items WHERE id = ?
+	`, itemID).Scan(
+		&item.ID, &item.MeetingID, &item.Position, &item.Title, &item.Description, &item.PresenterID,
+		&item.AllocatedMinutes, &item.Status, &item.CreatedBy, &item.CreatedAt, &item.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("agenda item not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agenda item: %w", err)
+	}
+	return &item, nil
+}
+
+func (s *MeetingGovernanceService) getMotion(motionID string) (*models.Motion, error) {
+	return s.loadMotion(s.db, motionID)
+}
+
+func (s *MeetingGovernanceService) loadMotion(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, motionID string) (*models.Motion, error) {
+	var m models.Motion
+	err := q.QueryRow(`
+		SELECT id, meeting_id, chama_id, agenda_item_id, title, description, proposed_by,
+			seconded_by, seconded_at, status, votes_for, votes_against, abstentions,
+			voting_opened_at, voting_closed_at, created_at, updated_at
+		FROM meeting_motions WHERE id = ?
+	`, motionID).Scan(
+		&m.ID, &m.MeetingID, &m.ChamaID, &m.AgendaItemID, &m.Title, &m.Description, &m.ProposedBy,
+		&m.SecondedBy, &m.SecondedAt, &m.Status, &m.VotesFor, &m.VotesAgainst, &m.Abstentions,
+		&m.VotingOpenedAt, &m.VotingClosedAt, &m.CreatedAt, &m.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("motion not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get motion: %w", err)
+	}
+	return &m, nil
+}
+
+func (s *MeetingGovernanceService) getMotionForMeeting(meetingID, motionID string) (*models.Motion, error) {
+	motion, err := s.getMotion(motionID)
+	if err != nil {
+		return nil, err
+	}
+	if motion.MeetingID != meetingID {
+		return nil, fmt.Errorf("motion not found")
+	}
+	return motion, nil
+}
+
+func (s *MeetingGovernanceService) getMeeting(meetingID string) (*meetingInfo, error) {
+	var m meetingInfo
+	err := s.db.QueryRow(`
+		SELECT id, chama_id, title, status FROM meetings WHERE id = ?
+	`, meetingID).Scan(&m.ID, &m.ChamaID, &m.Title, &m.Status)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("meeting not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get meeting: %w", err)
+	}
+	return &m, nil
+}
+
+func (s *MeetingGovernanceService) isMember(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM chama_members
+		WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
+
+func (s *MeetingGovernanceService) isMeetingOfficial(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM chama_members
+		WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+		AND role IN ('chairperson', 'secretary')
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
+
+func (s *MeetingGovernanceService) isPresent(meetingID, userID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM meeting_attendance
+		WHERE meeting_id = ? AND user_id = ? AND is_present = TRUE
+	`, meetingID, userID).Scan(&exists)
+	return err == nil
+}
+
+func (s *MeetingGovernanceService) countPresentMembers(meetingID, chamaID string) (int, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM meeting_attendance ma
+		JOIN chama_members cm ON cm.user_id = ma.user_id AND cm.chama_id = ? AND cm.is_active = TRUE
+		WHERE ma.meeting_id = ? AND ma.is_present = TRUE
+	`, chamaID, meetingID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count present members: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,234 @@
+package services_test
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type MeetingGovernanceTestSuite struct {
+	suite.Suite
+	testDB    *helpers.TestDatabase
+	db        *sql.DB
+	service   *services.MeetingGovernanceService
+	chairID   string
+	memberID  string
+	secondID  string
+	absentID  string
+	chamaID   string
+	meetingID string
+}
+
+func (suite *MeetingGovernanceTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewMeetingGovernanceService(suite.db)
+
+	suite.chairID = suite.testDB.AddTestUser(suite.T(), "Chair")
+	suite.memberID = suite.testDB.AddTestUser(suite.T(), "Mary")
+	suite.secondID = suite.testDB.AddTestUser(suite.T(), "John")
+	suite.absentID = suite.testDB.AddTestUser(suite.T(), "Absent")
+
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.memberID, "member")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.secondID, "member")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.absentID, "member")
+
+	suite.meetingID = suite.testDB.AddTestMeeting(suite.T(), suite.chamaID, suite.chairID, "active")
+	suite.testDB.AddTestAttendance(suite.T(), suite.meetingID, suite.chairID, true)
+	suite.testDB.AddTestAttendance(suite.T(), suite.meetingID, suite.memberID, true)
+	suite.testDB.AddTestAttendance(suite.T(), suite.meetingID, suite.secondID, true)
+	suite.testDB.AddTestAttendance(suite.T(), suite.meetingID, suite.absentID, false)
+}
+
+func (suite *MeetingGovernanceTestSuite) TestAgendaOrdering() {
+	first, err := suite.service.AddAgendaItem(suite.meetingID, suite.chairID, &models.CreateAgendaItemRequest{Title: "Opening prayer", AllocatedMinutes: 5})
+	suite.Require().NoError(err)
+	second, err := suite.service.AddAgendaItem(suite.meetingID, suite.chairID, &models.CreateAgendaItemRequest{Title: "Loan applications", AllocatedMinutes: 30})
+	suite.Require().NoError(err)
+
+	position := 1
+	inserted, err := suite.service.AddAgendaItem(suite.meetingID, suite.chairID, &models.CreateAgendaItemRequest{Title: "Apologies", Position: &position})
+	suite.Require().NoError(err)
+
+	items, err := suite.service.GetAgenda(suite.meetingID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Require().Len(items, 3)
+	suite.Equal(inserted.ID, items[0].ID)
+	suite.Equal(first.ID, items[1].ID)
+	suite.Equal(second.ID, items[2].ID)
+
+	items, err = suite.service.ReorderAgenda(suite.meetingID, suite.chairID, []string{second.ID, first.ID, inserted.ID})
+	suite.Require().NoError(err)
+	suite.Equal(second.ID, items[0].ID)
+	suite.Equal(3, items[2].Position)
+
+	suite.Require().NoError(suite.service.DeleteAgendaItem(suite.meetingID, first.ID, suite.chairID))
+	items, err = suite.service.GetAgenda(suite.meetingID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Require().Len(items, 2)
+	suite.Equal(2, items[1].Position)
+
+	_, err = suite.service.AddAgendaItem(suite.meetingID, suite.memberID, &models.CreateAgendaItemRequest{Title: "AOB"})
+	suite.Error(err, "ordinary members cannot edit the agenda")
+}
+
+func (suite *MeetingGovernanceTestSuite) TestMotionCarriedCreatesResolution() {
+	description := "Approve Mary's loan of KES 50,000"
+	motion, err := suite.service.ProposeMotion(suite.meetingID, suite.memberID, &models.CreateMotionRequest{Title: "Approve loan", Description: &description})
+	suite.Require().NoError(err)
+
+	_, err = suite.service.SecondMotion(suite.meetingID, motion.ID, suite.memberID)
+	suite.Error(err, "proposer cannot second")
+	_, err = suite.service.SecondMotion(suite.meetingID, motion.ID, suite.absentID)
+	suite.Error(err, "absent members cannot second")
+
+	_, err = suite.service.SecondMotion(suite.meetingID, motion.ID, suite.secondID)
+	suite.Require().NoError(err)
+
+	_, err = suite.service.OpenVoting(suite.meetingID, motion.ID, suite.memberID)
+	suite.Error(err, "only officials open voting")
+	_, err = suite.service.OpenVoting(suite.meetingID, motion.ID, suite.chairID)
+	suite.Require().NoError(err)
+
+	_, err = suite.service.CastVote(suite.meetingID, motion.ID, suite.absentID, models.MotionVoteFor)
+	suite.Error(err, "absent members cannot vote")
+
+	_, err = suite.service.CastVote(suite.meetingID, motion.ID, suite.chairID, models.MotionVoteFor)
+	suite.Require().NoError(err)
+	_, err = suite.service.CastVote(suite.meetingID, motion.ID, suite.memberID, models.MotionVoteFor)
+	suite.Require().NoError(err)
+	_, err = suite.service.CastVote(suite.meetingID, motion.ID, suite.secondID, models.MotionVoteAgainst)
+	suite.Require().NoError(err)
+	_, err = suite.service.CastVote(suite.meetingID, motion.ID, suite.secondID, models.MotionVoteFor)
+	suite.Error(err, "members vote once")
+
+	closed, resolution, err := suite.service.CloseVoting(suite.meetingID, motion.ID, suite.chairID)
+	suite.Require().NoError(err)
+	suite.Equal(models.MotionStatusCarried, closed.Status)
+	suite.Require().NotNil(resolution)
+	suite.Equal(2, resolution.VotesFor)
+	suite.Equal(1, resolution.VotesAgainst)
+	suite.Contains(resolution.ResolutionNumber, "RES-")
+	suite.Nil(resolution.MinutesID)
+
+	_, err = suite.db.Exec(`
+		INSERT INTO meeting_minutes (id, meeting_id, content, taken_by, status)
+		VALUES ('minutes-1', ?, 'Minutes', ?, 'approved')
+	`, suite.meetingID, suite.chairID)
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.service.LinkResolutionsToMinutes(suite.meetingID, "minutes-1"))
+
+	found, err := suite.service.SearchResolutions(suite.chamaID, suite.memberID, "Mary", 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(found, 1)
+	suite.Require().NotNil(found[0].MinutesID)
+	suite.Equal("minutes-1", *found[0].MinutesID)
+}
+
+func (suite *MeetingGovernanceTestSuite) TestMotionDefeatedRecordsNoResolution() {
+	motion, err := suite.service.ProposeMotion(suite.meetingID, suite.memberID, &models.CreateMotionRequest{Title: "Buy land"})
+	suite.Require().NoError(err)
+	_, err = suite.service.SecondMotion(suite.meetingID, motion.ID, suite.secondID)
+	suite.Require().NoError(err)
+	_, err = suite.service.OpenVoting(suite.meetingID, motion.ID, suite.chairID)
+	suite.Require().NoError(err)
+	_, err = suite.service.CastVote(suite.meetingID, motion.ID, suite.chairID, models.MotionVoteAgainst)
+	suite.Require().NoError(err)
+
+	closed, resolution, err := suite.service.CloseVoting(suite.meetingID, motion.ID, suite.chairID)
+	suite.Require().NoError(err)
+	suite.Equal(models.MotionStatusDefeated, closed.Status)
+	suite.Nil(resolution)
+}
+
+// openMotion tables a seconded motion and opens voting with one vote for it
+func (suite *MeetingGovernanceTestSuite) openMotion(title string) string {
+	motion, err := suite.service.ProposeMotion(suite.meetingID, suite.memberID, &models.CreateMotionRequest{Title: title})
+	suite.Require().NoError(err)
+	_, err = suite.service.SecondMotion(suite.meetingID, motion.ID, suite.secondID)
+	suite.Require().NoError(err)
+	_, err = suite.service.OpenVoting(suite.meetingID, motion.ID, suite.chairID)
+	suite.Require().NoError(err)
+	_, err = suite.service.CastVote(suite.meetingID, motion.ID, suite.chairID, models.MotionVoteFor)
+	suite.Require().NoError(err)
+	return motion.ID
+}
+
+func (suite *MeetingGovernanceTestSuite) TestConcurrentClosesRecordOneResolutionEach() {
+	motionIDs := []string{suite.openMotion("Buy a plot"), suite.openMotion("Raise contributions")}
+
+	// Each motion is closed twice at once
+	var wg sync.WaitGroup
+	var closed int32
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(motionID string) {
+			defer wg.Done()
+			if _, _, err := suite.service.CloseVoting(suite.meetingID, motionID, suite.chairID); err == nil {
+				atomic.AddInt32(&closed, 1)
+			}
+		}(motionIDs[i%2])
+	}
+	wg.Wait()
+	suite.Equal(int32(2), closed, "each motion closes once")
+
+	resolutions, err := suite.service.GetMeetingResolutions(suite.meetingID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Require().Len(resolutions, 2)
+	numbers := map[string]bool{}
+	for _, resolution := range resolutions {
+		numbers[resolution.ResolutionNumber] = true
+	}
+	year := time.Now().Format("2006")
+	suite.True(numbers[fmt.Sprintf("RES-%s-0001", year)])
+	suite.True(numbers[fmt.Sprintf("RES-%s-0002", year)])
+
+	_, err = suite.service.CastVote(suite.meetingID, motionIDs[0], suite.memberID, models.MotionVoteAgainst)
+	suite.Error(err, "votes are not taken once the motion is closed")
+}
+
+func (suite *MeetingGovernanceTestSuite) TestOpeningVotingGuardsMotionAndMeeting() {
+	withdrawn, err := suite.service.ProposeMotion(suite.meetingID, suite.memberID, &models.CreateMotionRequest{Title: "Change bank"})
+	suite.Require().NoError(err)
+	_, err = suite.service.SecondMotion(suite.meetingID, withdrawn.ID, suite.secondID)
+	suite.Require().NoError(err)
+	_, err = suite.service.WithdrawMotion(suite.meetingID, withdrawn.ID, suite.memberID)
+	suite.Require().NoError(err)
+	_, err = suite.service.OpenVoting(suite.meetingID, withdrawn.ID, suite.chairID)
+	suite.Error(err, "a withdrawn motion cannot be put to a vote")
+
+	motionID := suite.openMotion("Buy chairs")
+	_, err = suite.service.WithdrawMotion(suite.meetingID, motionID, suite.memberID)
+	suite.Error(err, "motions cannot be withdrawn once voting has opened")
+
+	pending, err := suite.service.ProposeMotion(suite.meetingID, suite.memberID, &models.CreateMotionRequest{Title: "Hire a venue"})
+	suite.Require().NoError(err)
+	_, err = suite.service.SecondMotion(suite.meetingID, pending.ID, suite.secondID)
+	suite.Require().NoError(err)
+	_, err = suite.db.Exec("UPDATE meetings SET status = 'completed' WHERE id = ?", suite.meetingID)
+	suite.Require().NoError(err)
+	_, err = suite.service.OpenVoting(suite.meetingID, pending.ID, suite.chairID)
+	suite.Error(err, "voting cannot open after the meeting has ended")
+}
+
+func (suite *MeetingGovernanceTestSuite) TestMotionsRequireActiveMeeting() {
+	scheduled := suite.testDB.AddTestMeeting(suite.T(), suite.chamaID, suite.chairID, "scheduled")
+	suite.testDB.AddTestAttendance(suite.T(), scheduled, suite.memberID, true)
+
+	_, err := suite.service.ProposeMotion(scheduled, suite.memberID, &models.CreateMotionRequest{Title: "Early motion"})
+	suite.Error(err)
+}
+
+func TestMeetingGovernance(t *testing.T) {
+	suite.Run(t, new(MeetingGovernanceTestSuite))
+}
@@ -0,0 +1,431 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/utils"
+)
+
+// memberAnalyticsCacheTTL is how long computed chama analytics are served from memory
+const memberAnalyticsCacheTTL = 5 * time.Minute
+
+// memberAnalyticsMaxMonths bounds the period a single request can cover
+const memberAnalyticsMaxMonths = 36
+
+// nonSavingsContributionTypes are contributions that do not count towards a member's savings record
+var nonSavingsContributionTypes = []string{"welfare", "penalty", "welfare_levy"}
+
+type memberAnalyticsCacheEntry struct {
+	analytics []*models.MemberContributionAnalytics
+	expiresAt time.Time
+}
+
+// memberAnalyticsCache is shared by every service instance so handlers that
+// create a service per request still benefit from it
+var memberAnalyticsCache = struct {
+	sync.RWMutex
+	entries map[string]memberAnalyticsCacheEntry
+}{entries: make(map[string]memberAnalyticsCacheEntry)}
+
+// MemberAnalyticsService computes member contribution history, consistency and projections
+type MemberAnalyticsService struct {
+	db *sql.DB
+}
+
+// NewMemberAnalyticsService creates a new member analytics service
+func NewMemberAnalyticsService(db *sql.DB) *MemberAnalyticsService {
+	return &MemberAnalyticsService{
+		db: db,
+	}
+}
+
+// InvalidateMemberAnalytics drops cached analytics for a chama after its contributions change
+func InvalidateMemberAnalytics(chamaID string) {
+	memberAnalyticsCache.Lock()
+	defer memberAnalyticsCache.Unlock()
+
+	for key := range memberAnalyticsCache.entries {
+		if strings.HasPrefix(key, chamaID+"|") {
+			delete(memberAnalyticsCache.entries, key)
+		}
+	}
+}
+
+// invalidateMemberAnalyticsForUser drops cached analytics for every chama the user
+// belongs to, for money they move outside a chama that still counts as activity
+func invalidateMemberAnalyticsForUser(db *sql.DB, userID string) {
+	rows, err := db.Query("SELECT chama_id FROM chama_members WHERE user_id = ? AND is_active = TRUE", userID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var chamaID string
+		if err := rows.Scan(&chamaID); err == nil {
+			InvalidateMemberAnalytics(chamaID)
+		}
+	}
+}
+
+// ResolveAnalyticsPeriod turns ?months= or ?from=YYYY-MM&to=YYYY-MM into the first
+// day of the first and last months covered. The default is the last 12 months.
+func ResolveAnalyticsPeriod(months int, from, to string, now time.Time) (time.Time, time.Time, error) {
+	end := monthStart(now)
+	if to != "" {
+		parsed, err := time.ParseInLocation("2006-01", to, utils.EATLocation)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to must be in YYYY-MM format")
+		}
+		end = parsed
+	}
+
+	if from != "" {
+		start, err := time.ParseInLocation("2006-01", from, utils.EATLocation)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("from must be in YYYY-MM format")
+		}
+		if start.After(end) {
+			return time.Time{}, time.Time{}, fmt.Errorf("from must not be after to")
+		}
+		if monthsBetween(start, end)+1 > memberAnalyticsMaxMonths {
+			return time.Time{}, time.Time{}, fmt.Errorf("period cannot be longer than %d months", memberAnalyticsMaxMonths)
+		}
+		return start, end, nil
+	}
+
+	if months <= 0 {
+		months = 12
+	}
+	if months > memberAnalyticsMaxMonths {
+		return time.Time{}, time.Time{}, fmt.Errorf("period cannot be longer than %d months", memberAnalyticsMaxMonths)
+	}
+	return end.AddDate(0, -(months - 1), 0), end, nil
+}
+
+// GetChamaMemberAnalytics returns analytics for every active member, ranked by amount contributed
+func (s *MemberAnalyticsService) GetChamaMemberAnalytics(chamaID, userID string, start, end time.Time) ([]*models.MemberContributionAnalytics, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	return s.chamaAnalytics(chamaID, userID, start, end)
+}
+
+// GetMemberAnalytics returns one member's analytics, including their rank within the chama
+func (s *MemberAnalyticsService) GetMemberAnalytics(chamaID, memberID, userID string, start, end time.Time) (*models.MemberContributionAnalytics, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	analytics, err := s.chamaAnalytics(chamaID, userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range analytics {
+		if member.UserID == memberID {
+			return member, nil
+		}
+	}
+	return nil, fmt.Errorf("member not found")
+}
+
+// ChamaAnalyticsByMember returns the chama's analytics keyed by user ID without a
+// membership check, for handlers that have already authorised the caller
+func (s *MemberAnalyticsService) ChamaAnalyticsByMember(chamaID string, start, end time.Time) (map[string]*models.MemberContributionAnalytics, error) {
+	analytics, err := s.chamaAnalytics(chamaID, "", start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	byMember := make(map[string]*models.MemberContributionAnalytics, len(analytics))
+	for _, member := range analytics {
+		byMember[member.UserID] = member
+	}
+	return byMember, nil
+}
+
+// chamaAnalytics computes (or serves from cache) analytics for every active member.
+// Entries are kept per requester and callers get their own copy, so nothing a
+// handler does to the result can leak into another response.
+func (s *MemberAnalyticsService) chamaAnalytics(chamaID, requesterID string, start, end time.Time) ([]*models.MemberContributionAnalytics, error) {
+	start, end = monthStart(start), monthStart(end)
+	key := fmt.Sprintf("%s|%s|%s|%s", chamaID, requesterID, start.Format("2006-01"), end.Format("2006-01"))
+
+	memberAnalyticsCache.RLock()
+	cached, ok := memberAnalyticsCache.entries[key]
+	memberAnalyticsCache.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return copyMemberAnalytics(cached.analytics), nil
+	}
+
+	analytics, err := s.computeChamaAnalytics(chamaID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	memberAnalyticsCache.Lock()
+	memberAnalyticsCache.entries[key] = memberAnalyticsCacheEntry{
+		analytics: analytics,
+		expiresAt: time.Now().Add(memberAnalyticsCacheTTL),
+	}
+	memberAnalyticsCache.Unlock()
+
+	return copyMemberAnalytics(analytics), nil
+}
+
+func (s *MemberAnalyticsService) computeChamaAnalytics(chamaID string, start, end time.Time) ([]*models.MemberContributionAnalytics, error) {
+	var contributionAmount float64
+	var frequency sql.NullString
+	err := s.db.QueryRow("SELECT contribution_amount, contribution_frequency FROM chamas WHERE id = ?", chamaID).
+		Scan(&contributionAmount, &frequency)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("chama not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chama: %w", err)
+	}
+	expectedPerMonth := contributionAmount * contributionsPerMonth(frequency.String)
+
+	now := utils.NowEAT()
+	currentMonth := monthStart(now)
+
+	// Members and when they last moved money on the platform
+	rows, err := s.db.Query(`
+		SELECT cm.user_id, cm.joined_at,
+			(SELECT MAX(t.created_at) FROM transactions t WHERE t.initiated_by = cm.user_id)
+		FROM chama_members cm
+		WHERE cm.chama_id = ? AND cm.is_active = TRUE
+	`, chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chama members: %w", err)
+	}
+
+	byMember := make(map[string]*models.MemberContributionAnalytics)
+	joined := make(map[string]time.Time)
+	var analytics []*models.MemberContributionAnalytics
+	for rows.Next() {
+		var memberID string
+		var joinedAt sql.NullTime
+		var lastTransaction sql.NullString
+		if err := rows.Scan(&memberID, &joinedAt, &lastTransaction); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan chama member: %w", err)
+		}
+
+		member := &models.MemberContributionAnalytics{
+			ChamaID:     chamaID,
+			UserID:      memberID,
+			PeriodStart: start.Format("2006-01"),
+			PeriodEnd:   end.Format("2006-01"),
+			GeneratedAt: now,
+		}
+		if lastTransaction.Valid {
+			if lastActive, err := parseTimeString(lastTransaction.String); err == nil && !lastActive.IsZero() {
+				member.LastActivityAt = &lastActive
+			}
+		}
+		if joinedAt.Valid {
+			joined[memberID] = monthStart(joinedAt.Time)
+		}
+
+		byMember[memberID] = member
+		analytics = append(analytics, member)
+	}
+	rows.Close()
+
+	// Every savings contribution to the chama, oldest first. Merry-go-round
+	// contributions are paid to a member but carry the chama in their metadata.
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(nonSavingsContributionTypes)), ", ")
+	args := []interface{}{chamaID, chamaID}
+	for _, contributionType := range nonSavingsContributionTypes {
+		args = append(args, contributionType)
+	}
+	rows, err = s.db.Query(fmt.Sprintf(`
+		SELECT t.initiated_by, t.amount, t.created_at
+		FROM transactions t
+		WHERE t.type = 'contribution' AND t.status = 'completed'
+		AND (t.recipient_id = ? OR json_extract(t.metadata, '$.chamaId') = ?)
+		AND COALESCE(json_extract(t.metadata, '$.contributionType'), '') NOT IN (%s)
+		ORDER BY t.created_at ASC
+	`, placeholders), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contributions: %w", err)
+	}
+
+	monthly := make(map[string]map[string]*models.MemberContributionMonth)
+	periodEnd := end.AddDate(0, 1, 0)
+	for rows.Next() {
+		var memberID string
+		var amount float64
+		var createdAt time.Time
+		if err := rows.Scan(&memberID, &amount, &createdAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan contribution: %w", err)
+		}
+
+		member, ok := byMember[memberID]
+		if !ok {
+			continue
+		}
+
+		createdAt = createdAt.In(utils.EATLocation)
+		contributedAt := createdAt
+		member.LastContributionAt = &contributedAt
+		member.LastContributionAmount = amount
+		if createdAt.Year() == now.Year() {
+			member.YearToDate += amount
+		}
+
+		if createdAt.Before(start) || !createdAt.Before(periodEnd) {
+			continue
+		}
+		if monthly[memberID] == nil {
+			monthly[memberID] = make(map[string]*models.MemberContributionMonth)
+		}
+		month := createdAt.Format("2006-01")
+		if monthly[memberID][month] == nil {
+			monthly[memberID][month] = &models.MemberContributionMonth{Month: month}
+		}
+		monthly[memberID][month].Amount += amount
+		monthly[memberID][month].Count++
+	}
+	rows.Close()
+
+	for _, member := range analytics {
+		joinedMonth, hasJoined := joined[member.UserID]
+		run := 0
+		for month := start; !month.After(end); month = month.AddDate(0, 1, 0) {
+			key := month.Format("2006-01")
+			entry := models.MemberContributionMonth{Month: key}
+			if recorded := monthly[member.UserID][key]; recorded != nil {
+				entry = *recorded
+			}
+
+			// Months before joining and months still to come are shown but not expected
+			eligible := (!hasJoined || !month.Before(joinedMonth)) && !month.After(currentMonth)
+			if eligible {
+				entry.Expected = roundCurrency(expectedPerMonth)
+				entry.Met = entry.Amount > 0 && entry.Amount+0.005 >= expectedPerMonth
+			}
+			member.Months = append(member.Months, entry)
+			member.TotalContributed += entry.Amount
+			member.ContributionCount += entry.Count
+
+			if !eligible {
+				continue
+			}
+			// The current month only counts once it has been met, so an unpaid
+			// month in progress is not treated as a miss
+			if month.Equal(currentMonth) && !entry.Met {
+				continue
+			}
+
+			member.MonthsExpected++
+			member.ExpectedTotal += expectedPerMonth
+			if entry.Met {
+				member.MonthsMet++
+				run++
+				if run > member.LongestStreak {
+					member.LongestStreak = run
+				}
+			} else {
+				run = 0
+			}
+		}
+		member.CurrentStreak = run
+
+		member.TotalContributed = roundCurrency(member.TotalContributed)
+		member.ExpectedTotal = roundCurrency(member.ExpectedTotal)
+		member.YearToDate = roundCurrency(member.YearToDate)
+		if member.MonthsExpected > 0 {
+			member.MonthlyAverage = roundCurrency(member.TotalContributed / float64(member.MonthsExpected))
+			member.ConsistencyRate = roundCurrency(float64(member.MonthsMet) * 100 / float64(member.MonthsExpected))
+		}
+
+		// Project the rest of the year at the member's average: the shortfall for
+		// this month plus every month still to come
+		thisMonth := 0.0
+		if recorded := monthly[member.UserID][currentMonth.Format("2006-01")]; recorded != nil {
+			thisMonth = recorded.Amount
+		}
+		remainingMonths := 12 - int(now.Month())
+		member.ProjectedYearEnd = roundCurrency(member.YearToDate +
+			math.Max(0, member.MonthlyAverage-thisMonth) + member.MonthlyAverage*float64(remainingMonths))
+	}
+
+	// Rank by amount contributed in the period; equal totals share a rank
+	sort.SliceStable(analytics, func(i, j int) bool {
+		return analytics[i].TotalContributed > analytics[j].TotalContributed
+	})
+	for i, member := range analytics {
+		member.Rank = i + 1
+		if i > 0 && member.TotalContributed == analytics[i-1].TotalContributed {
+			member.Rank = analytics[i-1].Rank
+		}
+		member.RankOutOf = len(analytics)
+	}
+
+	return analytics, nil
+}
+
+// Helper functions
+
+func (s *MemberAnalyticsService) isMember(userID, chamaID string) bool {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM chama_members WHERE chama_id = ? AND user_id = ? AND is_active = TRUE", chamaID, userID).Scan(&count)
+	return err == nil && count > 0
+}
+
+// contributionsPerMonth converts a chama's contribution frequency to expected payments per month
+func contributionsPerMonth(frequency string) float64 {
+	switch models.ContributionFrequency(frequency) {
+	case models.ContributionFrequencyWeekly:
+		return 52.0 / 12.0
+	case models.ContributionFrequencyQuarterly:
+		return 1.0 / 3.0
+	default:
+		return 1
+	}
+}
+
+// monthStart is the first instant of the month in Kenyan time, which is how
+// contributions are bucketed
+func monthStart(t time.Time) time.Time {
+	t = t.In(utils.EATLocation)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, utils.EATLocation)
+}
+
+// copyMemberAnalytics deep-copies analytics so cached values are never shared
+func copyMemberAnalytics(analytics []*models.MemberContributionAnalytics) []*models.MemberContributionAnalytics {
+	copies := make([]*models.MemberContributionAnalytics, len(analytics))
+	for i, member := range analytics {
+		c := *member
+		c.Months = append([]models.MemberContributionMonth(nil), member.Months...)
+		if member.LastContributionAt != nil {
+			at := *member.LastContributionAt
+			c.LastContributionAt = &at
+		}
+		if member.LastActivityAt != nil {
+			at := *member.LastActivityAt
+			c.LastActivityAt = &at
+		}
+		copies[i] = &c
+	}
+	return copies
+}
+
+func monthsBetween(start, end time.Time) int {
+	return (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month())
+}
+
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,185 @@
+package services_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/internal/utils"
+	"vaultke-backend/test/helpers"
+)
+
+type MemberAnalyticsTestSuite struct {
+	suite.Suite
+	testDB   *helpers.TestDatabase
+	db       *sql.DB
+	service  *services.MemberAnalyticsService
+	chamaID  string
+	chairID  string
+	steadyID string
+	lateID   string
+	month    time.Time
+}
+
+func (suite *MemberAnalyticsTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewMemberAnalyticsService(suite.db)
+
+	now := utils.NowEAT()
+	suite.month = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, utils.EATLocation)
+
+	suite.chairID = suite.testDB.AddTestUser(suite.T(), "Chair")
+	suite.steadyID = suite.testDB.AddTestUser(suite.T(), "Steady")
+	suite.lateID = suite.testDB.AddTestUser(suite.T(), "Late")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.steadyID, "member")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.lateID, "member")
+
+	_, err := suite.db.Exec("UPDATE chama_members SET joined_at = ? WHERE chama_id = ?", suite.monthsAgo(5), suite.chamaID)
+	suite.Require().NoError(err)
+}
+
+// monthsAgo returns the middle of the month n months before the current one
+func (suite *MemberAnalyticsTestSuite) monthsAgo(n int) time.Time {
+	return suite.month.AddDate(0, -n, 14).Add(12 * time.Hour)
+}
+
+func (suite *MemberAnalyticsTestSuite) contribute(userID string, amount float64, at time.Time, contributionType string) {
+	_, err := suite.db.Exec(`
+		INSERT INTO transactions (id, type, status, amount, payment_method, initiated_by, recipient_id, metadata, created_at)
+		VALUES (?, 'contribution', 'completed', ?, 'wallet', ?, ?, ?, ?)
+	`, uuid.New().String(), amount, userID, suite.chamaID,
+		`{"chamaId":"`+suite.chamaID+`","contributionType":"`+contributionType+`"}`, at)
+	suite.Require().NoError(err)
+}
+
+func (suite *MemberAnalyticsTestSuite) TestHistoryConsistencyStreaksAndRank() {
+	suite.contribute(suite.steadyID, 1000, suite.monthsAgo(5), "regular")
+	suite.contribute(suite.steadyID, 1000, suite.monthsAgo(4), "regular")
+	suite.contribute(suite.steadyID, 1000, suite.monthsAgo(2), "regular")
+	suite.contribute(suite.steadyID, 600, suite.monthsAgo(1), "regular")
+	suite.contribute(suite.steadyID, 600, suite.monthsAgo(1).Add(time.Hour), "regular")
+	suite.contribute(suite.steadyID, 5000, suite.monthsAgo(3), "welfare")
+	suite.contribute(suite.lateID, 500, suite.monthsAgo(1), "regular")
+
+	start, end, err := services.ResolveAnalyticsPeriod(6, "", "", time.Now())
+	suite.Require().NoError(err)
+
+	_, err = suite.service.GetMemberAnalytics(suite.chamaID, suite.steadyID, suite.testDB.AddTestUser(suite.T(), "Outsider"), start, end)
+	suite.Error(err, "only members can see analytics")
+
+	steady, err := suite.service.GetMemberAnalytics(suite.chamaID, suite.steadyID, suite.lateID, start, end)
+	suite.Require().NoError(err)
+	suite.Require().Len(steady.Months, 6)
+	suite.Equal(1200.0, steady.Months[4].Amount)
+	suite.Equal(2, steady.Months[4].Count)
+	suite.False(steady.Months[2].Met, "the welfare contribution does not count")
+	suite.Equal(4200.0, steady.TotalContributed)
+	suite.Equal(5, steady.MonthsExpected, "the month in progress is not yet expected")
+	suite.Equal(4, steady.MonthsMet)
+	suite.Equal(80.0, steady.ConsistencyRate)
+	suite.Equal(840.0, steady.MonthlyAverage)
+	suite.Equal(2, steady.CurrentStreak)
+	suite.Equal(2, steady.LongestStreak)
+	suite.Equal(1, steady.Rank)
+	suite.Equal(3, steady.RankOutOf)
+	suite.Equal(600.0, steady.LastContributionAmount)
+	suite.GreaterOrEqual(steady.ProjectedYearEnd, steady.YearToDate)
+
+	late, err := suite.service.GetMemberAnalytics(suite.chamaID, suite.lateID, suite.lateID, start, end)
+	suite.Require().NoError(err)
+	suite.Equal(0.0, late.ConsistencyRate)
+	suite.Equal(0, late.CurrentStreak)
+	suite.Equal(2, late.Rank)
+
+	ranked, err := suite.service.GetChamaMemberAnalytics(suite.chamaID, suite.chairID, start, end)
+	suite.Require().NoError(err)
+	suite.Require().Len(ranked, 3)
+	suite.Equal(suite.chairID, ranked[2].UserID)
+	suite.Equal(3, ranked[2].Rank)
+}
+
+func (suite *MemberAnalyticsTestSuite) TestPeriodFilterAndCache() {
+	suite.contribute(suite.steadyID, 1000, suite.monthsAgo(3), "regular")
+	suite.contribute(suite.steadyID, 1000, suite.monthsAgo(1), "regular")
+
+	month := suite.monthsAgo(1).Format("2006-01")
+	start, end, err := services.ResolveAnalyticsPeriod(0, month, month, time.Now())
+	suite.Require().NoError(err)
+
+	steady, err := suite.service.GetMemberAnalytics(suite.chamaID, suite.steadyID, suite.steadyID, start, end)
+	suite.Require().NoError(err)
+	suite.Len(steady.Months, 1)
+	suite.Equal(1000.0, steady.TotalContributed)
+
+	suite.contribute(suite.steadyID, 500, suite.monthsAgo(1).Add(time.Hour), "regular")
+	cached, err := suite.service.GetMemberAnalytics(suite.chamaID, suite.steadyID, suite.steadyID, start, end)
+	suite.Require().NoError(err)
+	suite.Equal(1000.0, cached.TotalContributed, "served from cache")
+
+	services.InvalidateMemberAnalytics(suite.chamaID)
+	fresh, err := suite.service.GetMemberAnalytics(suite.chamaID, suite.steadyID, suite.steadyID, start, end)
+	suite.Require().NoError(err)
+	suite.Equal(1500.0, fresh.TotalContributed)
+
+	_, _, err = services.ResolveAnalyticsPeriod(0, "2020-01", "2024-01", time.Now())
+	suite.Error(err, "periods are capped")
+	_, _, err = services.ResolveAnalyticsPeriod(0, "2024-05", "2024-01", time.Now())
+	suite.Error(err)
+}
+
+func (suite *MemberAnalyticsTestSuite) TestMonthsFollowKenyanTime() {
+	// Half past midnight on the 1st in Nairobi is still the previous month in UTC
+	firstOfMonth := suite.monthsAgo(1).AddDate(0, 0, -14).Add(-12*time.Hour + 30*time.Minute)
+	suite.contribute(suite.steadyID, 1000, firstOfMonth.UTC(), "regular")
+
+	month := firstOfMonth.Format("2006-01")
+	start, end, err := services.ResolveAnalyticsPeriod(0, month, month, time.Now())
+	suite.Require().NoError(err)
+
+	steady, err := suite.service.GetMemberAnalytics(suite.chamaID, suite.steadyID, suite.steadyID, start, end)
+	suite.Require().NoError(err)
+	suite.Require().Len(steady.Months, 1)
+	suite.Equal(month, steady.Months[0].Month)
+	suite.Equal(1000.0, steady.Months[0].Amount)
+}
+
+func (suite *MemberAnalyticsTestSuite) TestCachedAnalyticsAreCopiedAndRefreshedBySavings() {
+	suite.contribute(suite.steadyID, 1000, suite.monthsAgo(1), "regular")
+	start, end, err := services.ResolveAnalyticsPeriod(6, "", "", time.Now())
+	suite.Require().NoError(err)
+
+	first, err := suite.service.GetMemberAnalytics(suite.chamaID, suite.steadyID, suite.steadyID, start, end)
+	suite.Require().NoError(err)
+	first.TotalContributed = 0
+	first.Months[0].Amount = 99
+
+	second, err := suite.service.GetMemberAnalytics(suite.chamaID, suite.steadyID, suite.steadyID, start, end)
+	suite.Require().NoError(err)
+	suite.Equal(1000.0, second.TotalContributed, "callers cannot change the cached copy")
+	suite.Equal(0.0, second.Months[0].Amount)
+
+	// Saving into a goal is activity the cached analytics must pick up
+	_, err = suite.db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'personal', ?, 500)", uuid.New().String(), suite.steadyID)
+	suite.Require().NoError(err)
+	savings := services.NewSavingsService(suite.db)
+	goal, err := savings.CreateGoal(suite.steadyID, &models.CreateSavingsGoalRequest{Name: "School fees", TargetAmount: 1000})
+	suite.Require().NoError(err)
+	_, err = savings.DepositToGoal(goal.ID, suite.steadyID, 200)
+	suite.Require().NoError(err)
+
+	fresh, err := suite.service.GetMemberAnalytics(suite.chamaID, suite.steadyID, suite.steadyID, start, end)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(fresh.LastActivityAt)
+	suite.True(fresh.LastActivityAt.After(suite.monthsAgo(1)))
+}
+
+func TestMemberAnalytics(t *testing.T) {
+	suite.Run(t, new(MemberAnalyticsTestSuite))
+}
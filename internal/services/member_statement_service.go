@@ -0,0 +1,615 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// statementBatchSize caps how many statements one scheduler run generates, so the
+// first run after month end does not hold up the scheduler's other jobs
+const statementBatchSize = 100
+
+// MemberStatementService builds member statements for any date range, and each
+// month generates, emails and keeps the previous month's statement for every
+// active member
+type MemberStatementService struct {
+	db           *sql.DB
+	emailService *EmailService
+}
+
+// NewMemberStatementService creates a new member statement service
+func NewMemberStatementService(db *sql.DB) *MemberStatementService {
+	// Share the email service instance with the other services that send email
+	if globalEmailService == nil {
+		globalEmailService = NewEmailService()
+	}
+	return &MemberStatementService{
+		db:           db,
+		emailService: globalEmailService,
+	}
+}
+
+// GetStatement builds a statement for a member over any date range. Members can see
+// their own statements; officials can see any member's.
+func (s *MemberStatementService) GetStatement(chamaID, memberID, userID string, start, end time.Time) (*models.MemberStatement, error) {
+	if !s.canView(userID, chamaID, memberID) {
+		return nil, fmt.Errorf("you can only view your own statement")
+	}
+	var exists int
+	err := s.db.QueryRow("SELECT 1 FROM chama_members WHERE chama_id = ? AND user_id = ?", chamaID, memberID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("member not found in this chama")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+	return s.buildStatement(chamaID, memberID, start, end)
+}
+
+// GetStatements lists a member's monthly statements, latest first
+func (s *MemberStatementService) GetStatements(chamaID, memberID, userID string) ([]models.MemberStatementRecord, error) {
+	if !s.canView(userID, chamaID, memberID) {
+		return nil, fmt.Errorf("you can only view your own statements")
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, chama_id, member_id, period_month, period_start, period_end, opening_balance, closing_balance,
+			emailed_at, email_error, created_at
+		FROM member_statements
+		WHERE chama_id = ? AND member_id = ?
+		ORDER BY period_month DESC
+	`, chamaID, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statements: %w", err)
+	}
+	defer rows.Close()
+
+	records := []models.MemberStatementRecord{}
+	for rows.Next() {
+		var record models.MemberStatementRecord
+		var emailedAt sql.NullTime
+		var emailError sql.NullString
+		err := rows.Scan(&record.ID, &record.ChamaID, &record.MemberID, &record.PeriodMonth, &record.PeriodStart,
+			&record.PeriodEnd, &record.OpeningBalance, &record.ClosingBalance, &emailedAt, &emailError, &record.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan statement: %w", err)
+		}
+		if emailedAt.Valid {
+			record.EmailedAt = &emailedAt.Time
+		}
+		record.EmailError = nullStringPtr(emailError)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// GetStoredStatement returns a monthly statement as it was generated and emailed
+func (s *MemberStatementService) GetStoredStatement(chamaID, statementID, userID string) (*models.MemberStatement, error) {
+	var memberID, data string
+	err := s.db.QueryRow(`
+		SELECT member_id, statement_data FROM member_statements WHERE id = ? AND chama_id = ?
+	`, statementID, chamaID).Scan(&memberID, &data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("statement not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement: %w", err)
+	}
+	if !s.canView(userID, chamaID, memberID) {
+		return nil, fmt.Errorf("statement not found")
+	}
+
+	var statement models.MemberStatement
+	if err := json.Unmarshal([]byte(data), &statement); err != nil {
+		return nil, fmt.Errorf("failed to parse statement: %w", err)
+	}
+	statement.ID = statementID
+	return &statement, nil
+}
+
+// ProcessDueStatements generates last month's statement for each active member of
+// an active chama who joined before the month ended and has none yet. Each one is
+// kept for download and emailed to the member as a PDF.
+func (s *MemberStatementService) ProcessDueStatements() {
+	now := utils.NowEAT()
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, utils.EATLocation)
+	start := end.AddDate(0, -1, 0)
+	month := start.Format("2006-01")
+
+	rows, err := s.db.Query(`
+		SELECT cm.chama_id, cm.user_id
+		FROM chama_members cm
+		JOIN chamas c ON c.id = cm.chama_id
+		WHERE cm.is_active = TRUE AND c.status = 'active'
+		AND (cm.joined_at IS NULL OR julianday(cm.joined_at) < julianday(?))
+		AND NOT EXISTS (
+			SELECT 1 FROM member_statements ms
+			WHERE ms.chama_id = cm.chama_id AND ms.member_id = cm.user_id AND ms.period_month = ?
+		)
+		LIMIT ?
+	`, end, month, statementBatchSize)
+	if err != nil {
+		log.Printf("Failed to get members due a statement: %v", err)
+		return
+	}
+
+	type dueStatement struct{ chamaID, memberID string }
+	var due []dueStatement
+	for rows.Next() {
+		var item dueStatement
+		if err := rows.Scan(&item.chamaID, &item.memberID); err != nil {
+			log.Printf("Failed to scan member due a statement: %v", err)
+			continue
+		}
+		due = append(due, item)
+	}
+	rows.Close()
+
+	for _, item := range due {
+		if err := s.generateMonthlyStatement(item.chamaID, item.memberID, month, start, end); err != nil {
+			log.Printf("Failed to generate %s statement for member %s of chama %s: %v", month, item.memberID, item.chamaID, err)
+		}
+	}
+}
+
+// generateMonthlyStatement builds, keeps and emails one member's monthly statement.
+// A failed email is recorded on the statement rather than retried.
+func (s *MemberStatementService) generateMonthlyStatement(chamaID, memberID, month string, start, end time.Time) error {
+	statement, err := s.buildStatement(chamaID, memberID, start, end)
+	if err != nil {
+		return err
+	}
+	statement.ID = uuid.New().String()
+
+	data, err := json.Marshal(statement)
+	if err != nil {
+		return fmt.Errorf("failed to serialize statement: %w", err)
+	}
+	result, err := s.db.Exec(`
+		INSERT INTO member_statements (id, chama_id, member_id, period_month, period_start, period_end,
+			opening_balance, closing_balance, statement_data, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(chama_id, member_id, period_month) DO NOTHING
+	`, statement.ID, chamaID, memberID, month, start, end, statement.OpeningBalance, statement.ClosingBalance, string(data), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save statement: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		// Another run generated it first
+		return nil
+	}
+
+	emailErr := s.emailStatement(statement)
+	if emailErr != nil {
+		_, err = s.db.Exec("UPDATE member_statements SET email_error = ? WHERE id = ?", emailErr.Error(), statement.ID)
+	} else {
+		_, err = s.db.Exec("UPDATE member_statements SET emailed_at = ? WHERE id = ?", time.Now(), statement.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record statement delivery: %w", err)
+	}
+	return nil
+}
+
+func (s *MemberStatementService) emailStatement(statement *models.MemberStatement) error {
+	var email sql.NullString
+	if err := s.db.QueryRow("SELECT email FROM users WHERE id = ?", statement.MemberID).Scan(&email); err != nil {
+		return fmt.Errorf("failed to get member email: %w", err)
+	}
+	if strings.TrimSpace(email.String) == "" {
+		return fmt.Errorf("member has no email address")
+	}
+
+	document, err := s.RenderPDF(statement)
+	if err != nil {
+		return err
+	}
+	period := utils.FormatTimeEAT(statement.PeriodStart, "January 2006")
+	return s.emailService.SendMemberStatementEmail(email.String, statement.MemberName, statement.ChamaName, period, &EmailAttachment{
+		FileName:    StatementFileName(statement),
+		ContentType: "application/pdf",
+		Content:     document,
+	})
+}
+
+// StatementFileName is the download and attachment name of a statement's PDF
+func StatementFileName(statement *models.MemberStatement) string {
+	return fmt.Sprintf("VaultKe_Statement_%s_%s.pdf",
+		utils.FormatTimeEAT(statement.PeriodStart, "2006-01-02"),
+		utils.FormatTimeEAT(statement.PeriodEnd.AddDate(0, 0, -1), "2006-01-02"))
+}
+
+// buildStatement gathers a member's contributions, fines, share purchases, loans,
+// repayments and dividends. Activity before start makes up the opening balances.
+func (s *MemberStatementService) buildStatement(chamaID, memberID string, start, end time.Time) (*models.MemberStatement, error) {
+	statement := &models.MemberStatement{
+		ChamaID:     chamaID,
+		MemberID:    memberID,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		GeneratedAt: time.Now(),
+		Holdings:    []models.MemberStatementHolding{},
+		Activity:    []models.MemberStatementActivity{},
+	}
+	err := s.db.QueryRow(`
+		SELECT c.name, COALESCE(u.first_name || ' ' || u.last_name, '')
+		FROM chamas c, users u
+		WHERE c.id = ? AND u.id = ?
+	`, chamaID, memberID).Scan(&statement.ChamaName, &statement.MemberName)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("chama or member not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chama and member: %w", err)
+	}
+
+	addActivity := func(date time.Time, category, description string, reference *string, amount float64) {
+		statement.Activity = append(statement.Activity, models.MemberStatementActivity{
+			Date:        date,
+			Category:    category,
+			Description: description,
+			Reference:   reference,
+			Amount:      roundCurrency(amount),
+		})
+	}
+
+	// Contributions, fines and share purchases. Fines are contributions marked as
+	// penalties; welfare contributions are not savings and are left out.
+	rows, err := s.db.Query(`
+		SELECT type, amount, description, reference, created_at,
+			CASE WHEN json_valid(metadata) THEN json_extract(metadata, '$.contributionType') END
+		FROM transactions
+		WHERE type IN ('contribution', 'share_purchase') AND status = 'completed' AND initiated_by = ?
+		AND (recipient_id = ? OR (json_valid(metadata) AND json_extract(metadata, '$.chamaId') = ?))
+	`, memberID, chamaID, chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contributions: %w", err)
+	}
+	for rows.Next() {
+		var txType string
+		var amount float64
+		var description, reference, contributionType sql.NullString
+		var date time.Time
+		if err := rows.Scan(&txType, &amount, &description, &reference, &date, &contributionType); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan contribution: %w", err)
+		}
+		if !date.Before(end) {
+			continue
+		}
+
+		category := models.StatementActivityContribution
+		switch {
+		case txType == "share_purchase":
+			category = models.StatementActivitySharePurchase
+		case contributionType.String == "penalty":
+			category = models.StatementActivityFine
+		case contributionType.String == "welfare" || contributionType.String == "welfare_levy":
+			continue
+		}
+		if date.Before(start) {
+			if category == models.StatementActivityContribution {
+				statement.OpeningSavings += amount
+			}
+			continue
+		}
+
+		text := description.String
+		switch category {
+		case models.StatementActivityContribution:
+			statement.Contributions += amount
+			if text == "" {
+				text = "Contribution"
+			}
+		case models.StatementActivityFine:
+			statement.Fines += amount
+			if text == "" {
+				text = "Fine paid"
+			}
+		case models.StatementActivitySharePurchase:
+			statement.SharePurchases += amount
+			if text == "" {
+				text = "Share purchase"
+			}
+		}
+		addActivity(date, category, text, nullStringPtr(reference), amount)
+	}
+	rows.Close()
+
+	// Loans disbursed and repaid. Repayments reduce the loan by their principal.
+	rows, err = s.db.Query(`
+		SELECT amount, purpose, disbursed_at FROM loans
+		WHERE chama_id = ? AND borrower_id = ? AND disbursed_at IS NOT NULL
+	`, chamaID, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loans: %w", err)
+	}
+	for rows.Next() {
+		var amount float64
+		var purpose string
+		var disbursedAt time.Time
+		if err := rows.Scan(&amount, &purpose, &disbursedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan loan: %w", err)
+		}
+		switch {
+		case !disbursedAt.Before(end):
+		case disbursedAt.Before(start):
+			statement.OpeningLoanBalance += amount
+		default:
+			statement.LoansDisbursed += amount
+			addActivity(disbursedAt, models.StatementActivityLoan, "Loan disbursed: "+purpose, nil, amount)
+		}
+	}
+	rows.Close()
+
+	rows, err = s.db.Query(`
+		SELECT p.amount, p.interest_amount, p.reference, p.paid_at
+		FROM loan_payments p
+		JOIN loans l ON l.id = p.loan_id
+		WHERE l.chama_id = ? AND l.borrower_id = ?
+	`, chamaID, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan repayments: %w", err)
+	}
+	for rows.Next() {
+		var amount, interest float64
+		var reference sql.NullString
+		var paidAt time.Time
+		if err := rows.Scan(&amount, &interest, &reference, &paidAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan loan repayment: %w", err)
+		}
+		switch {
+		case !paidAt.Before(end):
+		case paidAt.Before(start):
+			statement.OpeningLoanBalance -= amount - interest
+		default:
+			statement.LoanRepayments += amount
+			statement.PrincipalRepaid += amount - interest
+			statement.InterestPaid += interest
+			addActivity(paidAt, models.StatementActivityRepayment,
+				fmt.Sprintf("Loan repayment (interest %s)", utils.FormatCurrency(interest)), nullStringPtr(reference), amount)
+		}
+	}
+	rows.Close()
+
+	// Dividends paid, in cash or as reinvested shares
+	rows, err = s.db.Query(`
+		SELECT p.dividend_amount, p.reinvested_amount, p.transaction_reference, p.payment_date, p.updated_at
+		FROM dividend_payments p
+		JOIN dividend_declarations d ON d.id = p.dividend_declaration_id
+		WHERE d.chama_id = ? AND p.member_id = ? AND p.payment_status = 'paid'
+	`, chamaID, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dividends: %w", err)
+	}
+	for rows.Next() {
+		var amount, reinvested float64
+		var reference sql.NullString
+		var paymentDate sql.NullTime
+		var paidAt time.Time
+		if err := rows.Scan(&amount, &reinvested, &reference, &paymentDate, &paidAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan dividend: %w", err)
+		}
+		if paymentDate.Valid {
+			paidAt = paymentDate.Time
+		}
+		if paidAt.Before(start) || !paidAt.Before(end) {
+			continue
+		}
+		statement.DividendsEarned += amount
+		statement.DividendsReinvested += reinvested
+		description := "Dividend paid"
+		if reinvested > 0 {
+			description = fmt.Sprintf("Dividend (%s reinvested in shares)", utils.FormatCurrency(reinvested))
+		}
+		addActivity(paidAt, models.StatementActivityDividend, description, nullStringPtr(reference), amount)
+	}
+	rows.Close()
+
+	// Share holdings on record at the end of the period
+	rows, err = s.db.Query(`
+		SELECT share_type, shares_owned, total_value, purchase_date FROM shares
+		WHERE chama_id = ? AND member_id = ? AND status = 'active'
+	`, chamaID, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share holdings: %w", err)
+	}
+	holdings := map[string]*models.MemberStatementHolding{}
+	for rows.Next() {
+		var shareType string
+		var owned int
+		var value float64
+		var purchasedAt time.Time
+		if err := rows.Scan(&shareType, &owned, &value, &purchasedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan share holding: %w", err)
+		}
+		if !purchasedAt.Before(end) {
+			continue
+		}
+		if holdings[shareType] == nil {
+			holdings[shareType] = &models.MemberStatementHolding{ShareType: shareType}
+		}
+		holdings[shareType].SharesOwned += owned
+		holdings[shareType].TotalValue += value
+	}
+	rows.Close()
+	for _, holding := range holdings {
+		holding.TotalValue = roundCurrency(holding.TotalValue)
+		if holding.SharesOwned > 0 {
+			holding.ShareValue = roundCurrency(holding.TotalValue / float64(holding.SharesOwned))
+		}
+		statement.TotalShareValue += holding.TotalValue
+		statement.Holdings = append(statement.Holdings, *holding)
+	}
+	sort.Slice(statement.Holdings, func(i, j int) bool { return statement.Holdings[i].ShareType < statement.Holdings[j].ShareType })
+
+	sort.SliceStable(statement.Activity, func(i, j int) bool {
+		return statement.Activity[i].Date.Before(statement.Activity[j].Date)
+	})
+
+	statement.OpeningSavings = roundCurrency(statement.OpeningSavings)
+	statement.OpeningLoanBalance = roundCurrency(statement.OpeningLoanBalance)
+	statement.Contributions = roundCurrency(statement.Contributions)
+	statement.Fines = roundCurrency(statement.Fines)
+	statement.SharePurchases = roundCurrency(statement.SharePurchases)
+	statement.LoansDisbursed = roundCurrency(statement.LoansDisbursed)
+	statement.LoanRepayments = roundCurrency(statement.LoanRepayments)
+	statement.PrincipalRepaid = roundCurrency(statement.PrincipalRepaid)
+	statement.InterestPaid = roundCurrency(statement.InterestPaid)
+	statement.DividendsEarned = roundCurrency(statement.DividendsEarned)
+	statement.DividendsReinvested = roundCurrency(statement.DividendsReinvested)
+	statement.TotalShareValue = roundCurrency(statement.TotalShareValue)
+
+	statement.ClosingSavings = roundCurrency(statement.OpeningSavings + statement.Contributions)
+	statement.ClosingLoanBalance = roundCurrency(statement.OpeningLoanBalance + statement.LoansDisbursed - statement.PrincipalRepaid)
+	statement.OpeningBalance = roundCurrency(statement.OpeningSavings - statement.OpeningLoanBalance)
+	statement.ClosingBalance = roundCurrency(statement.ClosingSavings - statement.ClosingLoanBalance)
+	return statement, nil
+}
+
+// RenderPDF draws a statement on portrait A4 pages: a summary, share holdings and
+// the period's activity, continued on further pages as needed
+func (s *MemberStatementService) RenderPDF(statement *models.MemberStatement) ([]byte, error) {
+	const width, height = pdfA4Width, pdfA4Height
+	const left, right = 50.0, pdfA4Width - 50
+	doc := newPDFDocument()
+	doc.AddPage(width, height)
+
+	doc.SetFillColor(0.11, 0.31, 0.22)
+	doc.Text(left, 70, pdfFontBold, 20, "MEMBER STATEMENT")
+	doc.TextRight(right, 70, pdfFontBold, 14, statement.ChamaName)
+	doc.SetFillColor(0, 0, 0)
+	doc.Text(left, 95, pdfFontRegular, 11, statement.MemberName)
+	period := fmt.Sprintf("%s to %s",
+		utils.FormatTimeEAT(statement.PeriodStart, "2 January 2006"),
+		utils.FormatTimeEAT(statement.PeriodEnd.AddDate(0, 0, -1), "2 January 2006"))
+	doc.Text(left, 111, pdfFontRegular, 10, "Period: "+period)
+	doc.TextRight(right, 111, pdfFontRegular, 9, "Generated "+utils.FormatTimeEAT(statement.GeneratedAt, "2 January 2006 15:04"))
+
+	doc.SetStrokeColor(0.11, 0.31, 0.22)
+	doc.SetLineWidth(1)
+	doc.Line(left, 122, right, 122)
+
+	// Summary: the balance movement on the left, other activity on the right
+	y := 145.0
+	summaryRow := func(x, valueX float64, label string, amount float64, bold bool) {
+		font := pdfFontRegular
+		if bold {
+			font = pdfFontBold
+		}
+		doc.Text(x, y, font, 10, label)
+		doc.TextRight(valueX, y, font, 10, utils.FormatCurrency(amount))
+	}
+	rowsLeft := []struct {
+		label  string
+		amount float64
+		bold   bool
+	}{
+		{"Opening balance", statement.OpeningBalance, true},
+		{"Contributions", statement.Contributions, false},
+		{"Loans disbursed", -statement.LoansDisbursed, false},
+		{"Principal repaid", statement.PrincipalRepaid, false},
+		{"Closing balance", statement.ClosingBalance, true},
+	}
+	rowsRight := []struct {
+		label  string
+		amount float64
+		bold   bool
+	}{
+		{"Savings", statement.ClosingSavings, false},
+		{"Loan outstanding", statement.ClosingLoanBalance, false},
+		{"Fines paid", statement.Fines, false},
+		{"Interest paid", statement.InterestPaid, false},
+		{"Dividends earned", statement.DividendsEarned, false},
+	}
+	for i := range rowsLeft {
+		summaryRow(left, 280, rowsLeft[i].label, rowsLeft[i].amount, rowsLeft[i].bold)
+		summaryRow(320, right, rowsRight[i].label, rowsRight[i].amount, rowsRight[i].bold)
+		y += 16
+	}
+	doc.Text(left, y+2, pdfFontRegular, 8,
+		"Balance is savings (contributions other than fines and welfare) less loan principal outstanding. Amounts in KES.")
+	y += 30
+
+	// Share holdings
+	doc.Text(left, y, pdfFontBold, 12, "Share holdings")
+	y += 18
+	if len(statement.Holdings) == 0 {
+		doc.Text(left, y, pdfFontRegular, 10, "No shares held")
+		y += 16
+	}
+	for _, holding := range statement.Holdings {
+		doc.Text(left, y, pdfFontRegular, 10, fmt.Sprintf("%d %s shares at %s", holding.SharesOwned, holding.ShareType, utils.FormatCurrency(holding.ShareValue)))
+		doc.TextRight(right, y, pdfFontRegular, 10, utils.FormatCurrency(holding.TotalValue))
+		y += 16
+	}
+	if len(statement.Holdings) > 1 {
+		doc.Text(left, y, pdfFontBold, 10, "Total share value")
+		doc.TextRight(right, y, pdfFontBold, 10, utils.FormatCurrency(statement.TotalShareValue))
+		y += 16
+	}
+	y += 14
+
+	// Activity, repeating the column headings on each new page
+	activityHeader := func() {
+		doc.Text(left, y, pdfFontBold, 12, "Activity")
+		y += 18
+		doc.SetFillColor(0.4, 0.4, 0.4)
+		doc.Text(left, y, pdfFontBold, 9, "Date")
+		doc.Text(left+75, y, pdfFontBold, 9, "Description")
+		doc.Text(left+320, y, pdfFontBold, 9, "Type")
+		doc.TextRight(right, y, pdfFontBold, 9, "Amount")
+		doc.SetFillColor(0, 0, 0)
+		doc.SetStrokeColor(0.8, 0.8, 0.8)
+		doc.SetLineWidth(0.5)
+		doc.Line(left, y+4, right, y+4)
+		y += 18
+	}
+	activityHeader()
+	if len(statement.Activity) == 0 {
+		doc.Text(left, y, pdfFontRegular, 10, "No activity in this period")
+	}
+	for _, item := range statement.Activity {
+		if y > height-60 {
+			doc.AddPage(width, height)
+			y = 60
+			activityHeader()
+		}
+		doc.Text(left, y, pdfFontRegular, 9, utils.FormatTimeEAT(item.Date, "02 Jan 2006"))
+		description := item.Description
+		if item.Reference != nil {
+			description += " - " + *item.Reference
+		}
+		doc.Text(left+75, y, pdfFontRegular, 9, pdfFitText(pdfFontRegular, 9, description, 235))
+		doc.Text(left+320, y, pdfFontRegular, 9, strings.ReplaceAll(item.Category, "_", " "))
+		doc.TextRight(right, y, pdfFontRegular, 9, utils.FormatCurrency(item.Amount))
+		y += 14
+	}
+
+	return doc.Bytes(), nil
+}
+
+func (s *MemberStatementService) canView(userID, chamaID, memberID string) bool {
+	var role string
+	err := s.db.QueryRow(`
+		SELECT role FROM chama_members WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+	`, userID, chamaID).Scan(&role)
+	if err != nil {
+		return false
+	}
+	return userID == memberID || role == "chairperson" || role == "secretary" || role == "treasurer"
+}
@@ -0,0 +1,220 @@
+package services_test
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/internal/utils"
+	"vaultke-backend/test/helpers"
+)
+
+type MemberStatementTestSuite struct {
+	suite.Suite
+	testDB      *helpers.TestDatabase
+	db          *sql.DB
+	service     *services.MemberStatementService
+	treasurerID string
+	memberID    string
+	otherID     string
+	chamaID     string
+	start       time.Time
+	end         time.Time
+}
+
+func (suite *MemberStatementTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewMemberStatementService(suite.db)
+
+	suite.treasurerID = suite.testDB.AddTestUser(suite.T(), "Treasurer")
+	suite.memberID = suite.testDB.AddTestUser(suite.T(), "Member")
+	suite.otherID = suite.testDB.AddTestUser(suite.T(), "Other")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.treasurerID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.treasurerID, "treasurer")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.memberID, "member")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.otherID, "member")
+
+	// Statements run for last month, so the period is relative to today
+	now := utils.NowEAT()
+	suite.end = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, utils.EATLocation)
+	suite.start = suite.end.AddDate(0, -1, 0)
+
+	_, err := suite.db.Exec("UPDATE chama_members SET joined_at = ? WHERE chama_id = ?", suite.start.AddDate(0, -2, 0), suite.chamaID)
+	suite.Require().NoError(err)
+}
+
+func (suite *MemberStatementTestSuite) record(txType string, amount float64, metadata string, at time.Time) {
+	var meta interface{}
+	if metadata != "" {
+		meta = metadata
+	}
+	_, err := suite.db.Exec(`
+		INSERT INTO transactions (
+			id, type, status, amount, currency, description, payment_method,
+			initiated_by, recipient_id, metadata, created_at, updated_at
+		) VALUES (?, ?, 'completed', ?, 'KES', '', 'mpesa', ?, ?, ?, ?, ?)
+	`, uuid.New().String(), txType, amount, suite.memberID, suite.chamaID, meta, at, at)
+	suite.Require().NoError(err)
+}
+
+func (suite *MemberStatementTestSuite) loan(amount float64, disbursedAt time.Time) string {
+	id := uuid.New().String()
+	_, err := suite.db.Exec(`
+		INSERT INTO loans (id, borrower_id, chama_id, type, amount, duration, purpose, status, disbursed_at, required_guarantors)
+		VALUES (?, ?, ?, 'normal', ?, 6, 'Stock', 'active', ?, 0)
+	`, id, suite.memberID, suite.chamaID, amount, disbursedAt)
+	suite.Require().NoError(err)
+	return id
+}
+
+func (suite *MemberStatementTestSuite) repay(loanID string, amount, interest float64, paidAt time.Time) {
+	_, err := suite.db.Exec(`
+		INSERT INTO loan_payments (id, loan_id, amount, principal_amount, interest_amount, payment_method, reference, paid_at)
+		VALUES (?, ?, ?, ?, ?, 'mpesa', 'LP1', ?)
+	`, uuid.New().String(), loanID, amount, amount-interest, interest, paidAt)
+	suite.Require().NoError(err)
+}
+
+// seedActivity records, for the member:
+//
+//	before the period: contributions 5,000 and a loan of 6,000 with 1,000 principal repaid
+//	in the period: contributions 2,000, a 300 fine, 100 welfare, 1,500 of shares,
+//	a 1,000 loan, a 2,200 repayment (200 interest) and an 800 dividend (200 reinvested)
+//	after the period: a contribution and shares that must not appear
+func (suite *MemberStatementTestSuite) seedActivity() {
+	before := suite.start.AddDate(0, 0, -10)
+	during := suite.start.AddDate(0, 0, 3)
+	after := suite.end.AddDate(0, 0, 1)
+
+	suite.record("contribution", 5000, "", before)
+	firstLoan := suite.loan(6000, before)
+	suite.repay(firstLoan, 1100, 100, before.Add(time.Hour))
+
+	suite.record("contribution", 2000, `{"contributionType":"regular"}`, during)
+	suite.record("contribution", 300, `{"contributionType":"penalty"}`, during)
+	suite.record("contribution", 100, `{"contributionType":"welfare"}`, during)
+	suite.record("share_purchase", 1500, "", during)
+	suite.loan(1000, during.Add(time.Hour))
+	suite.repay(firstLoan, 2200, 200, during.AddDate(0, 0, 5))
+
+	declarationID := uuid.New().String()
+	_, err := suite.db.Exec(`
+		INSERT INTO dividend_declarations (id, chama_id, dividend_per_share, total_amount, status)
+		VALUES (?, ?, 10, 800, 'paid')
+	`, declarationID, suite.chamaID)
+	suite.Require().NoError(err)
+	_, err = suite.db.Exec(`
+		INSERT INTO dividend_payments (id, dividend_declaration_id, member_id, shares_eligible, dividend_amount,
+			payment_status, payment_date, reinvested_amount, cash_amount)
+		VALUES (?, ?, ?, 80, 800, 'paid', ?, 200, 600)
+	`, uuid.New().String(), declarationID, suite.memberID, during.AddDate(0, 0, 7))
+	suite.Require().NoError(err)
+
+	for _, share := range []struct {
+		owned int
+		value float64
+		at    time.Time
+	}{{10, 1500, during}, {5, 750, after}} {
+		_, err = suite.db.Exec(`
+			INSERT INTO shares (id, chama_id, member_id, name, share_type, shares_owned, share_value, total_value, purchase_date)
+			VALUES (?, ?, ?, 'Member', 'ordinary', ?, 150, ?, ?)
+		`, uuid.New().String(), suite.chamaID, suite.memberID, share.owned, share.value, share.at)
+		suite.Require().NoError(err)
+	}
+
+	suite.record("contribution", 999, "", after)
+}
+
+func (suite *MemberStatementTestSuite) TestStatementBalancesAndTotals() {
+	suite.seedActivity()
+
+	statement, err := suite.service.GetStatement(suite.chamaID, suite.memberID, suite.memberID, suite.start, suite.end)
+	suite.Require().NoError(err)
+
+	suite.Equal(5000.0, statement.OpeningSavings)
+	suite.Equal(5000.0, statement.OpeningLoanBalance)
+	suite.Equal(0.0, statement.OpeningBalance)
+	suite.Equal(2000.0, statement.Contributions)
+	suite.Equal(300.0, statement.Fines)
+	suite.Equal(1500.0, statement.SharePurchases)
+	suite.Equal(1000.0, statement.LoansDisbursed)
+	suite.Equal(2200.0, statement.LoanRepayments)
+	suite.Equal(2000.0, statement.PrincipalRepaid)
+	suite.Equal(200.0, statement.InterestPaid)
+	suite.Equal(800.0, statement.DividendsEarned)
+	suite.Equal(200.0, statement.DividendsReinvested)
+	suite.Equal(7000.0, statement.ClosingSavings)
+	suite.Equal(4000.0, statement.ClosingLoanBalance)
+	suite.Equal(3000.0, statement.ClosingBalance)
+
+	suite.Require().Len(statement.Holdings, 1)
+	suite.Equal(10, statement.Holdings[0].SharesOwned)
+	suite.Equal(1500.0, statement.TotalShareValue)
+
+	suite.Len(statement.Activity, 6)
+	categories := map[string]float64{}
+	for i, item := range statement.Activity {
+		categories[item.Category] += item.Amount
+		if i > 0 {
+			suite.False(item.Date.Before(statement.Activity[i-1].Date))
+		}
+	}
+	suite.Equal(300.0, categories[models.StatementActivityFine])
+	suite.Equal(800.0, categories[models.StatementActivityDividend])
+
+	document, err := suite.service.RenderPDF(statement)
+	suite.Require().NoError(err)
+	suite.True(strings.HasPrefix(string(document), "%PDF"))
+}
+
+func (suite *MemberStatementTestSuite) TestOnlyOfficialsSeeOtherMembersStatements() {
+	_, err := suite.service.GetStatement(suite.chamaID, suite.memberID, suite.otherID, suite.start, suite.end)
+	suite.Error(err)
+	_, err = suite.service.GetStatements(suite.chamaID, suite.memberID, suite.otherID)
+	suite.Error(err)
+
+	_, err = suite.service.GetStatement(suite.chamaID, suite.memberID, suite.treasurerID, suite.start, suite.end)
+	suite.NoError(err)
+}
+
+func (suite *MemberStatementTestSuite) TestMonthlyStatementsAreGeneratedOnce() {
+	suite.seedActivity()
+
+	suite.service.ProcessDueStatements()
+	suite.service.ProcessDueStatements()
+
+	var count int
+	suite.Require().NoError(suite.db.QueryRow("SELECT COUNT(*) FROM member_statements WHERE chama_id = ?", suite.chamaID).Scan(&count))
+	suite.Equal(3, count)
+
+	records, err := suite.service.GetStatements(suite.chamaID, suite.memberID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Require().Len(records, 1)
+	suite.Equal(suite.start.Format("2006-01"), records[0].PeriodMonth)
+	suite.Equal(3000.0, records[0].ClosingBalance)
+	suite.NotNil(records[0].EmailedAt)
+	suite.Nil(records[0].EmailError)
+
+	// The kept statement is the snapshot that was emailed, unaffected by later changes
+	suite.record("contribution", 4000, "", suite.start.AddDate(0, 0, 1))
+	stored, err := suite.service.GetStoredStatement(suite.chamaID, records[0].ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(3000.0, stored.ClosingBalance)
+	suite.Equal(records[0].ID, stored.ID)
+
+	_, err = suite.service.GetStoredStatement(suite.chamaID, records[0].ID, suite.otherID)
+	suite.Error(err)
+	_, err = suite.service.GetStoredStatement(suite.chamaID, records[0].ID, suite.treasurerID)
+	suite.NoError(err)
+}
+
+func TestMemberStatementTestSuite(t *testing.T) {
+	suite.Run(t, new(MemberStatementTestSuite))
+}
@@ -0,0 +1,626 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"vaultke-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// defaultMoneyRequestExpiry is how long a request stays payable when no expiry is given
+const defaultMoneyRequestExpiry = 24 * time.Hour
+
+// moneyRequestReminderWindow is how long before expiry the payer is reminded
+const moneyRequestReminderWindow = 6 * time.Hour
+
+// MoneyRequestService handles requests for money between users and paying them
+type MoneyRequestService struct {
+	db *sql.DB
+}
+
+// NewMoneyRequestService creates a new money request service
+func NewMoneyRequestService(db *sql.DB) *MoneyRequestService {
+	return &MoneyRequestService{db: db}
+}
+
+// CreateRequest creates a request without a target, e.g. one shown as a QR code
+func (s *MoneyRequestService) CreateRequest(requesterID string, req *models.CreateMoneyRequestRequest) (*models.MoneyRequest, error) {
+	request, err := s.newRequest(requesterID, nil, req.Amount, req.Reason, models.MoneyRequestType(req.RequestType), nil, req.ExpiresInHours)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.insertRequest(s.db, request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// SendRequest sends a request to one user and notifies them
+func (s *MoneyRequestService) SendRequest(requesterID, targetUserID string, req *models.SendMoneyRequestRequest) (*models.MoneyRequest, error) {
+	if targetUserID == requesterID {
+		return nil, fmt.Errorf("you cannot request money from yourself")
+	}
+	if !s.userExists(targetUserID) {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	request, err := s.newRequest(requesterID, &targetUserID, req.Amount, req.Reason, models.MoneyRequestTypeDirect, nil, req.ExpiresInHours)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.insertRequest(s.db, request); err != nil {
+		return nil, err
+	}
+
+	s.notifyTarget(request, s.userName(requesterID))
+	return request, nil
+}
+
+// CreateSplitRequest splits a shared cost across members, creating one request per participant
+func (s *MoneyRequestService) CreateSplitRequest(requesterID string, req *models.CreateSplitMoneyRequestRequest) (*models.MoneyRequestSplit, error) {
+	seen := make(map[string]bool)
+	customTotal := 0.0
+	customCount := 0
+	for _, participant := range req.Participants {
+		if participant.UserID == requesterID {
+			return nil, fmt.Errorf("use includeSelf to carry a share yourself")
+		}
+		if seen[participant.UserID] {
+			return nil, fmt.Errorf("each participant can only be included once")
+		}
+		seen[participant.UserID] = true
+		if !s.userExists(participant.UserID) {
+			return nil, fmt.Errorf("participant %s not found", participant.UserID)
+		}
+		if participant.Amount != nil {
+			customTotal += *participant.Amount
+			customCount++
+		}
+	}
+
+	// Participants without an amount share what is left equally, including the
+	// requester's own share when they are part of the cost
+	sharers := len(req.Participants) - customCount
+	if req.IncludeSelf {
+		sharers++
+	}
+	remaining := roundCurrency(req.TotalAmount - customTotal)
+	if remaining < 0 {
+		return nil, fmt.Errorf("participant amounts exceed the total")
+	}
+	if sharers == 0 && remaining > 0.005 {
+		return nil, fmt.Errorf("participant amounts must add up to the total")
+	}
+	equalShare := 0.0
+	if sharers > 0 {
+		equalShare = math.Floor(remaining/float64(sharers)*100) / 100
+	}
+
+	splitID := uuid.New().String()
+	split := &models.MoneyRequestSplit{
+		SplitID:     splitID,
+		RequesterID: requesterID,
+		Reason:      req.Reason,
+		TotalAmount: req.TotalAmount,
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Rounding cents go to the first participant sharing equally
+	leftover := roundCurrency(remaining - equalShare*float64(sharers))
+	for _, participant := range req.Participants {
+		amount := equalShare
+		if participant.Amount != nil {
+			amount = *participant.Amount
+		} else {
+			amount = roundCurrency(amount + leftover)
+			leftover = 0
+		}
+		if amount <= 0 {
+			return nil, fmt.Errorf("the total is too small to split between %d people", sharers)
+		}
+
+		targetUserID := participant.UserID
+		request, err := s.newRequest(requesterID, &targetUserID, amount, req.Reason, models.MoneyRequestTypeSplit, &splitID, req.ExpiresInHours)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.insertRequest(tx, request); err != nil {
+			return nil, err
+		}
+		split.Requests = append(split.Requests, *request)
+		split.Outstanding += amount
+	}
+	split.Outstanding = roundCurrency(split.Outstanding)
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	requesterName := s.userName(requesterID)
+	for i := range split.Requests {
+		s.notifyTarget(&split.Requests[i], requesterName)
+	}
+
+	log.Printf("User %s split KES %.2f across %d members (%s)", requesterID, req.TotalAmount, len(split.Requests), splitID)
+	return split, nil
+}
+
+// GetSplit returns a split's requests and how much of it has been paid
+func (s *MoneyRequestService) GetSplit(splitID, userID string) (*models.MoneyRequestSplit, error) {
+	requests, err := s.queryRequests("WHERE mr.split_id = ? ORDER BY mr.created_at ASC", splitID)
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("split request not found")
+	}
+
+	split := &models.MoneyRequestSplit{
+		SplitID:     splitID,
+		RequesterID: requests[0].RequesterID,
+		Reason:      requests[0].Reason,
+		Requests:    requests,
+	}
+	allowed := split.RequesterID == userID
+	for _, request := range requests {
+		if request.TargetUserID != nil && *request.TargetUserID == userID {
+			allowed = true
+		}
+		split.TotalAmount += request.Amount
+		switch request.Status {
+		case models.MoneyRequestStatusPaid:
+			split.PaidAmount += request.Amount
+		case models.MoneyRequestStatusPending:
+			split.Outstanding += request.Amount
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("split request not found")
+	}
+
+	split.TotalAmount = roundCurrency(split.TotalAmount)
+	split.PaidAmount = roundCurrency(split.PaidAmount)
+	split.Outstanding = roundCurrency(split.Outstanding)
+	return split, nil
+}
+
+// GetIncomingRequests lists requests addressed to the user
+func (s *MoneyRequestService) GetIncomingRequests(userID, status string, limit, offset int) ([]models.MoneyRequest, error) {
+	s.expireOverdue()
+
+	if status != "" {
+		return s.queryRequests("WHERE mr.target_user_id = ? AND mr.status = ? ORDER BY mr.created_at DESC LIMIT ? OFFSET ?",
+			userID, status, limit, offset)
+	}
+	return s.queryRequests("WHERE mr.target_user_id = ? ORDER BY mr.created_at DESC LIMIT ? OFFSET ?", userID, limit, offset)
+}
+
+// GetOutgoingRequests lists requests the user has made
+func (s *MoneyRequestService) GetOutgoingRequests(userID, status string, limit, offset int) ([]models.MoneyRequest, error) {
+	s.expireOverdue()
+
+	if status != "" {
+		return s.queryRequests("WHERE mr.requester_id = ? AND mr.status = ? ORDER BY mr.created_at DESC LIMIT ? OFFSET ?",
+			userID, status, limit, offset)
+	}
+	return s.queryRequests("WHERE mr.requester_id = ? ORDER BY mr.created_at DESC LIMIT ? OFFSET ?", userID, limit, offset)
+}
+
+// GetRequest returns a request to its requester or target. Open QR code requests
+// can be looked up by anyone holding the ID so they can be paid.
+func (s *MoneyRequestService) GetRequest(requestID, userID string) (*models.MoneyRequest, error) {
+	s.expireOverdue()
+
+	request, err := s.getRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequesterID != userID && request.TargetUserID != nil && *request.TargetUserID != userID {
+		return nil, fmt.Errorf("money request not found")
+	}
+	return request, nil
+}
+
+// PayRequest moves the requested amount from the payer's wallet to the requester's
+// and marks the request paid, all in one transaction
+func (s *MoneyRequestService) PayRequest(requestID, payerID string) (*models.MoneyRequest, error) {
+	request, err := s.getRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.TargetUserID != nil && *request.TargetUserID != payerID {
+		return nil, fmt.Errorf("money request not found")
+	}
+	if request.RequesterID == payerID {
+		return nil, fmt.Errorf("you cannot pay your own money request")
+	}
+	if request.Status != models.MoneyRequestStatusPending {
+		return nil, fmt.Errorf("money request is %s", request.Status)
+	}
+	if time.Now().After(request.ExpiresAt) {
+		s.expireOverdue()
+		return nil, fmt.Errorf("money request has expired")
+	}
+
+	evaluation, err := screenOutgoing(s.db, &models.FraudCheck{
+		UserID:          payerID,
+		TransactionType: models.TransactionTypeTransfer,
+		Amount:          request.Amount,
+		RecipientID:     request.RequesterID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE money_requests SET status = ?, paid_by = ?, paid_at = ?, responded_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, models.MoneyRequestStatusPaid, payerID, now, now, now, requestID, models.MoneyRequestStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update money request: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("money request is no longer pending")
+	}
+
+	if err := reserveTransactionLimit(tx, payerID, requestID, request.Amount); err != nil {
+		return nil, err
+	}
+	fromWalletID, err := debitPersonalWallet(tx, payerID, request.Amount)
+	if err != nil {
+		return nil, err
+	}
+	toWalletID, err := creditPersonalWallet(tx, request.RequesterID, request.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionID := uuid.New().String()
+	description := "Money request payment"
+	if request.Reason != "" {
+		description = "Money request: " + request.Reason
+	}
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"moneyRequestId": request.ID,
+		"requestType":    request.RequestType,
+		"splitId":        request.SplitID,
+		"recipientId":    request.RequesterID,
+	})
+	_, err = tx.Exec(`
+		INSERT INTO transactions (
+			id, from_wallet_id, to_wallet_id, type, status, amount, currency, description,
+			reference, payment_method, metadata, initiated_by, recipient_id, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, 'KES', ?, ?, ?, ?, ?, ?, ?, ?)
+	`, transactionID, fromWalletID, toWalletID, models.TransactionTypeTransfer, models.TransactionStatusCompleted,
+		request.Amount, description, request.ID, models.PaymentMethodWalletTransfer, string(metadata),
+		payerID, request.RequesterID, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record payment: %w", err)
+	}
+
+	if err := NewAuditService(s.db).RecordTransaction(tx, "", transactionID); err != nil {
+		return nil, err
+	}
+	if err := recordFraudEvaluation(tx, evaluation, transactionID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("UPDATE money_requests SET transaction_id = ? WHERE id = ?", transactionID, requestID); err != nil {
+		return nil, fmt.Errorf("failed to link payment to money request: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	message := fmt.Sprintf("%s paid your request of KES %.2f.", s.userName(payerID), request.Amount)
+	if err := NewNotificationService(s.db, nil).CreateInAppNotification(request.RequesterID, "transaction", "money_request", "Money Request Paid", message, map[string]interface{}{
+		"requestId":     request.ID,
+		"transactionId": transactionID,
+		"amount":        request.Amount,
+		"paidBy":        payerID,
+	}); err != nil {
+		log.Printf("Failed to notify requester %s of payment: %v", request.RequesterID, err)
+	}
+
+	return s.getRequest(requestID)
+}
+
+// DeclineRequest lets the target turn a request down
+func (s *MoneyRequestService) DeclineRequest(requestID, userID string, req *models.DeclineMoneyRequestRequest) (*models.MoneyRequest, error) {
+	request, err := s.getRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.TargetUserID == nil || *request.TargetUserID != userID {
+		return nil, fmt.Errorf("only the person asked to pay can decline a money request")
+	}
+
+	var reason *string
+	if strings.TrimSpace(req.Reason) != "" {
+		reason = &req.Reason
+	}
+	if err := s.finishRequest(requestID, models.MoneyRequestStatusDeclined, reason); err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("%s declined your request of KES %.2f.", s.userName(userID), request.Amount)
+	if err := NewNotificationService(s.db, nil).CreateInAppNotification(request.RequesterID, "transaction", "money_request", "Money Request Declined", message, map[string]interface{}{
+		"requestId": request.ID,
+		"reason":    req.Reason,
+	}); err != nil {
+		log.Printf("Failed to notify requester %s of declined request: %v", request.RequesterID, err)
+	}
+
+	return s.getRequest(requestID)
+}
+
+// CancelRequest lets the requester withdraw a pending request
+func (s *MoneyRequestService) CancelRequest(requestID, userID string) (*models.MoneyRequest, error) {
+	request, err := s.getRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequesterID != userID {
+		return nil, fmt.Errorf("only the requester can cancel a money request")
+	}
+
+	if err := s.finishRequest(requestID, models.MoneyRequestStatusCancelled, nil); err != nil {
+		return nil, err
+	}
+	return s.getRequest(requestID)
+}
+
+// ProcessDueRequests expires overdue requests and reminds payers of requests about
+// to expire. It is run by the scheduler.
+func (s *MoneyRequestService) ProcessDueRequests() {
+	s.expireOverdue()
+
+	now := time.Now()
+	requests, err := s.queryRequests(`
+		WHERE mr.status = ? AND mr.target_user_id IS NOT NULL AND mr.reminder_sent_at IS NULL
+		AND julianday(mr.expires_at) > julianday(?) AND julianday(mr.expires_at) <= julianday(?)
+	`, models.MoneyRequestStatusPending, now, now.Add(moneyRequestReminderWindow))
+	if err != nil {
+		log.Printf("Failed to load money requests due for a reminder: %v", err)
+		return
+	}
+
+	for _, request := range requests {
+		result, err := s.db.Exec("UPDATE money_requests SET reminder_sent_at = ? WHERE id = ? AND reminder_sent_at IS NULL", now, request.ID)
+		if err != nil {
+			log.Printf("Failed to mark money request %s reminded: %v", request.ID, err)
+			continue
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			continue
+		}
+
+		message := fmt.Sprintf("%s's request of KES %.2f expires at %s.", request.RequesterName, request.Amount, request.ExpiresAt.Format("15:04 on Jan 2"))
+		if err := NewNotificationService(s.db, nil).CreateInAppNotification(*request.TargetUserID, "reminder", "money_request", "Money Request Expiring", message, map[string]interface{}{
+			"requestId": request.ID,
+			"amount":    request.Amount,
+			"expiresAt": request.ExpiresAt,
+		}); err != nil {
+			log.Printf("Failed to remind user %s of money request %s: %v", *request.TargetUserID, request.ID, err)
+		}
+	}
+}
+
+// expireOverdue marks pending requests past their expiry and tells the requester
+func (s *MoneyRequestService) expireOverdue() {
+	now := time.Now()
+	requests, err := s.queryRequests("WHERE mr.status = ? AND julianday(mr.expires_at) <= julianday(?)", models.MoneyRequestStatusPending, now)
+	if err != nil {
+		log.Printf("Failed to load expired money requests: %v", err)
+		return
+	}
+
+	for _, request := range requests {
+		result, err := s.db.Exec("UPDATE money_requests SET status = ?, updated_at = ? WHERE id = ? AND status = ?",
+			models.MoneyRequestStatusExpired, now, request.ID, models.MoneyRequestStatusPending)
+		if err != nil {
+			log.Printf("Failed to expire money request %s: %v", request.ID, err)
+			continue
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			continue
+		}
+
+		message := fmt.Sprintf("Your request of KES %.2f expired without being paid.", request.Amount)
+		if request.TargetName != "" {
+			message = fmt.Sprintf("Your request of KES %.2f to %s expired without being paid.", request.Amount, request.TargetName)
+		}
+		if err := NewNotificationService(s.db, nil).CreateInAppNotification(request.RequesterID, "transaction", "money_request", "Money Request Expired", message, map[string]interface{}{
+			"requestId": request.ID,
+		}); err != nil {
+			log.Printf("Failed to notify requester %s of expired request: %v", request.RequesterID, err)
+		}
+	}
+}
+
+// Helper functions
+
+func (s *MoneyRequestService) newRequest(requesterID string, targetUserID *string, amount float64, reason string, requestType models.MoneyRequestType, splitID *string, expiresInHours int) (*models.MoneyRequest, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+
+	expiry := defaultMoneyRequestExpiry
+	if expiresInHours > 0 {
+		expiry = time.Duration(expiresInHours) * time.Hour
+	}
+
+	now := time.Now()
+	return &models.MoneyRequest{
+		ID:           uuid.New().String(),
+		RequesterID:  requesterID,
+		TargetUserID: targetUserID,
+		Amount:       roundCurrency(amount),
+		Reason:       reason,
+		RequestType:  requestType,
+		Status:       models.MoneyRequestStatusPending,
+		SplitID:      splitID,
+		ExpiresAt:    now.Add(expiry),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil
+}
+
+func (s *MoneyRequestService) insertRequest(exec interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}, request *models.MoneyRequest) error {
+	_, err := exec.Exec(`
+		INSERT INTO money_requests (
+			id, requester_id, target_user_id, amount, reason, request_type, status,
+			split_id, expires_at, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, request.ID, request.RequesterID, request.TargetUserID, request.Amount, request.Reason, request.RequestType,
+		request.Status, request.SplitID, request.ExpiresAt, request.CreatedAt, request.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create money request: %w", err)
+	}
+	return nil
+}
+
+func (s *MoneyRequestService) finishRequest(requestID string, status models.MoneyRequestStatus, reason *string) error {
+	now := time.Now()
+	result, err := s.db.Exec(`
+		UPDATE money_requests SET status = ?, decline_reason = ?, responded_at = ?, updated_at = ?
+		WHERE id = ? AND status = ? AND julianday(expires_at) > julianday(?)
+	`, status, reason, now, now, requestID, models.MoneyRequestStatusPending, now)
+	if err != nil {
+		return fmt.Errorf("failed to update money request: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("money request is no longer pending")
+	}
+	return nil
+}
+
+func (s *MoneyRequestService) getRequest(requestID string) (*models.MoneyRequest, error) {
+	requests, err := s.queryRequests("WHERE mr.id = ?", requestID)
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("money request not found")
+	}
+	return &requests[0], nil
+}
+
+func (s *MoneyRequestService) queryRequests(where string, args ...interface{}) ([]models.MoneyRequest, error) {
+	rows, err := s.db.Query(`
+		SELECT mr.id, mr.requester_id, COALESCE(ru.first_name || ' ' || ru.last_name, ''),
+			   mr.target_user_id, COALESCE(tu.first_name || ' ' || tu.last_name, ''),
+			   mr.amount, mr.reason, mr.request_type, mr.status, mr.split_id, mr.transaction_id,
+			   mr.paid_by, mr.paid_at, mr.decline_reason, mr.responded_at, mr.reminder_sent_at,
+			   mr.expires_at, mr.created_at, mr.updated_at
+		FROM money_requests mr
+		LEFT JOIN users ru ON ru.id = mr.requester_id
+		LEFT JOIN users tu ON tu.id = mr.target_user_id
+		`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get money requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []models.MoneyRequest{}
+	for rows.Next() {
+		var request models.MoneyRequest
+		var targetUserID, splitID, transactionID, paidBy, declineReason sql.NullString
+		var paidAt, respondedAt, reminderSentAt sql.NullTime
+		err := rows.Scan(&request.ID, &request.RequesterID, &request.RequesterName,
+			&targetUserID, &request.TargetName,
+			&request.Amount, &request.Reason, &request.RequestType, &request.Status, &splitID, &transactionID,
+			&paidBy, &paidAt, &declineReason, &respondedAt, &reminderSentAt,
+			&request.ExpiresAt, &request.CreatedAt, &request.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan money request: %w", err)
+		}
+		if targetUserID.Valid {
+			request.TargetUserID = &targetUserID.String
+		}
+		if splitID.Valid {
+			request.SplitID = &splitID.String
+		}
+		if transactionID.Valid {
+			request.TransactionID = &transactionID.String
+		}
+		if paidBy.Valid {
+			request.PaidBy = &paidBy.String
+		}
+		if paidAt.Valid {
+			request.PaidAt = &paidAt.Time
+		}
+		if declineReason.Valid {
+			request.DeclineReason = &declineReason.String
+		}
+		if respondedAt.Valid {
+			request.RespondedAt = &respondedAt.Time
+		}
+		if reminderSentAt.Valid {
+			request.ReminderSentAt = &reminderSentAt.Time
+		}
+		requests = append(requests, request)
+	}
+
+	return requests, nil
+}
+
+func (s *MoneyRequestService) notifyTarget(request *models.MoneyRequest, requesterName string) {
+	if request.TargetUserID == nil {
+		return
+	}
+
+	message := fmt.Sprintf("%s has requested KES %.2f from you", requesterName, request.Amount)
+	if request.Reason != "" {
+		message += " for " + request.Reason
+	}
+	if err := NewNotificationService(s.db, nil).CreateInAppNotification(*request.TargetUserID, "transaction", "money_request", "Money Request", message, map[string]interface{}{
+		"requestId":   request.ID,
+		"amount":      request.Amount,
+		"reason":      request.Reason,
+		"requesterId": request.RequesterID,
+		"splitId":     request.SplitID,
+		"expiresAt":   request.ExpiresAt,
+	}); err != nil {
+		log.Printf("Failed to notify user %s of money request: %v", *request.TargetUserID, err)
+	}
+}
+
+func (s *MoneyRequestService) userExists(userID string) bool {
+	var exists int
+	err := s.db.QueryRow("SELECT 1 FROM users WHERE id = ?", userID).Scan(&exists)
+	return err == nil
+}
+
+func (s *MoneyRequestService) userName(userID string) string {
+	var name string
+	err := s.db.QueryRow("SELECT TRIM(COALESCE(first_name, '') || ' ' || COALESCE(last_name, '')) FROM users WHERE id = ?", userID).Scan(&name)
+	if err != nil || name == "" {
+		return "Someone"
+	}
+	return name
+}
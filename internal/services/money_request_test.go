@@ -0,0 +1,191 @@
+package services_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type MoneyRequestTestSuite struct {
+	suite.Suite
+	testDB      *helpers.TestDatabase
+	db          *sql.DB
+	service     *services.MoneyRequestService
+	requesterID string
+	payerID     string
+	otherID     string
+}
+
+func (suite *MoneyRequestTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewMoneyRequestService(suite.db)
+
+	suite.requesterID = suite.testDB.AddTestUser(suite.T(), "Requester")
+	suite.payerID = suite.testDB.AddTestUser(suite.T(), "Payer")
+	suite.otherID = suite.testDB.AddTestUser(suite.T(), "Other")
+	suite.fundWallet(suite.payerID, 5000)
+}
+
+func (suite *MoneyRequestTestSuite) fundWallet(userID string, balance float64) {
+	_, err := suite.db.Exec(`
+		INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'personal', ?, ?)
+	`, uuid.New().String(), userID, balance)
+	suite.Require().NoError(err)
+}
+
+func (suite *MoneyRequestTestSuite) balance(userID string) float64 {
+	var balance float64
+	err := suite.db.QueryRow("SELECT balance FROM wallets WHERE owner_id = ? AND type = 'personal'", userID).Scan(&balance)
+	suite.Require().NoError(err)
+	return balance
+}
+
+func (suite *MoneyRequestTestSuite) send(amount float64) *models.MoneyRequest {
+	request, err := suite.service.SendRequest(suite.requesterID, suite.payerID, &models.SendMoneyRequestRequest{
+		Amount:      amount,
+		Reason:      "Lunch",
+		RequestType: "direct",
+	})
+	suite.Require().NoError(err)
+	return request
+}
+
+func (suite *MoneyRequestTestSuite) TestPayMovesMoneyOnce() {
+	request := suite.send(1200)
+	suite.Equal(models.MoneyRequestStatusPending, request.Status)
+
+	_, err := suite.service.PayRequest(request.ID, suite.requesterID)
+	suite.Error(err, "requesters cannot pay their own request")
+	_, err = suite.service.PayRequest(request.ID, suite.otherID)
+	suite.Error(err, "only the target can pay a direct request")
+
+	paid, err := suite.service.PayRequest(request.ID, suite.payerID)
+	suite.Require().NoError(err)
+	suite.Equal(models.MoneyRequestStatusPaid, paid.Status)
+	suite.Require().NotNil(paid.TransactionID)
+	suite.Equal(3800.0, suite.balance(suite.payerID))
+	suite.Equal(1200.0, suite.balance(suite.requesterID), "the requester's wallet is created on first payment")
+
+	var audited int
+	err = suite.db.QueryRow("SELECT COUNT(*) FROM audit_log WHERE entity_id = ?", *paid.TransactionID).Scan(&audited)
+	suite.Require().NoError(err)
+	suite.Equal(1, audited)
+
+	_, err = suite.service.PayRequest(request.ID, suite.payerID)
+	suite.Error(err, "a request can only be paid once")
+	suite.Equal(3800.0, suite.balance(suite.payerID))
+
+	_, err = suite.service.PayRequest(suite.send(9000).ID, suite.payerID)
+	suite.Error(err, "insufficient balance")
+	suite.Equal(3800.0, suite.balance(suite.payerID))
+}
+
+func (suite *MoneyRequestTestSuite) TestDeclineAndCancelPermissions() {
+	declined := suite.send(300)
+	_, err := suite.service.DeclineRequest(declined.ID, suite.requesterID, &models.DeclineMoneyRequestRequest{})
+	suite.Error(err, "only the target can decline")
+	result, err := suite.service.DeclineRequest(declined.ID, suite.payerID, &models.DeclineMoneyRequestRequest{Reason: "Already paid in cash"})
+	suite.Require().NoError(err)
+	suite.Equal(models.MoneyRequestStatusDeclined, result.Status)
+	suite.Require().NotNil(result.DeclineReason)
+	suite.Equal("Already paid in cash", *result.DeclineReason)
+
+	cancelled := suite.send(300)
+	_, err = suite.service.CancelRequest(cancelled.ID, suite.payerID)
+	suite.Error(err, "only the requester can cancel")
+	result, err = suite.service.CancelRequest(cancelled.ID, suite.requesterID)
+	suite.Require().NoError(err)
+	suite.Equal(models.MoneyRequestStatusCancelled, result.Status)
+
+	_, err = suite.service.PayRequest(cancelled.ID, suite.payerID)
+	suite.Error(err, "cancelled requests cannot be paid")
+
+	_, err = suite.service.GetRequest(cancelled.ID, suite.otherID)
+	suite.Error(err, "outsiders cannot see a direct request")
+}
+
+func (suite *MoneyRequestTestSuite) TestSplitRoundsToTheCent() {
+	split, err := suite.service.CreateSplitRequest(suite.requesterID, &models.CreateSplitMoneyRequestRequest{
+		TotalAmount:  100,
+		Reason:       "Dinner",
+		Participants: []models.SplitParticipant{{UserID: suite.payerID}, {UserID: suite.otherID}},
+		IncludeSelf:  true,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(split.Requests, 2)
+	suite.Equal(33.34, split.Requests[0].Amount, "the leftover cent goes to the first participant")
+	suite.Equal(33.33, split.Requests[1].Amount)
+	suite.Equal(66.67, split.Outstanding)
+
+	_, err = suite.service.PayRequest(split.Requests[0].ID, suite.payerID)
+	suite.Require().NoError(err)
+
+	summary, err := suite.service.GetSplit(split.SplitID, suite.otherID)
+	suite.Require().NoError(err)
+	suite.Equal(33.34, summary.PaidAmount)
+	suite.Equal(33.33, summary.Outstanding)
+
+	custom := 70.0
+	split, err = suite.service.CreateSplitRequest(suite.requesterID, &models.CreateSplitMoneyRequestRequest{
+		TotalAmount:  100,
+		Reason:       "Taxi",
+		Participants: []models.SplitParticipant{{UserID: suite.payerID, Amount: &custom}, {UserID: suite.otherID}},
+	})
+	suite.Require().NoError(err)
+	suite.Equal(70.0, split.Requests[0].Amount)
+	suite.Equal(30.0, split.Requests[1].Amount)
+
+	_, err = suite.service.CreateSplitRequest(suite.requesterID, &models.CreateSplitMoneyRequestRequest{
+		TotalAmount:  50,
+		Reason:       "Too much",
+		Participants: []models.SplitParticipant{{UserID: suite.payerID, Amount: &custom}},
+	})
+	suite.Error(err, "custom shares cannot exceed the total")
+}
+
+func (suite *MoneyRequestTestSuite) TestExpiryAndReminders() {
+	expiring := suite.send(200)
+	overdue := suite.send(400)
+
+	_, err := suite.db.Exec("UPDATE money_requests SET expires_at = ? WHERE id = ?", time.Now().Add(2*time.Hour), expiring.ID)
+	suite.Require().NoError(err)
+	_, err = suite.db.Exec("UPDATE money_requests SET expires_at = ? WHERE id = ?", time.Now().Add(-time.Hour), overdue.ID)
+	suite.Require().NoError(err)
+
+	suite.service.ProcessDueRequests()
+
+	reminded, err := suite.service.GetRequest(expiring.ID, suite.payerID)
+	suite.Require().NoError(err)
+	suite.Equal(models.MoneyRequestStatusPending, reminded.Status)
+	suite.NotNil(reminded.ReminderSentAt)
+
+	expired, err := suite.service.GetOutgoingRequests(suite.requesterID, "expired", 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(expired, 1)
+	suite.Equal(overdue.ID, expired[0].ID)
+
+	_, err = suite.service.PayRequest(overdue.ID, suite.payerID)
+	suite.Error(err, "expired requests cannot be paid")
+
+	var reminders int
+	err = suite.db.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = ? AND title = 'Money Request Expiring'", suite.payerID).Scan(&reminders)
+	suite.Require().NoError(err)
+	suite.Equal(1, reminders)
+
+	suite.service.ProcessDueRequests()
+	err = suite.db.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = ? AND title = 'Money Request Expiring'", suite.payerID).Scan(&reminders)
+	suite.Require().NoError(err)
+	suite.Equal(1, reminders, "reminders are only sent once")
+}
+
+func TestMoneyRequests(t *testing.T) {
+	suite.Run(t, new(MoneyRequestTestSuite))
+}
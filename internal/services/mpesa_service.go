@@ -373,6 +373,10 @@ func (s *MpesaService) createMpesaTransaction(tx *sql.Tx, callback *models.Mpesa
 		return "", fmt.Errorf("failed to insert transaction: %w", err)
 	}
 
+	if err := NewAuditService(s.db).RecordTransaction(tx, "", transactionID); err != nil {
+		return "", err
+	}
+
 	return transactionID, nil
 }
 
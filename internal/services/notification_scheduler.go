@@ -1,6 +1,7 @@
 package services
 
 import (
+	"crypto/ed25519"
 	"database/sql"
 	"log"
 	"time"
@@ -10,28 +11,66 @@ import (
 
 // NotificationScheduler handles scheduling and sending reminder notifications
 type NotificationScheduler struct {
-	db              *sql.DB
-	reminderService *ReminderService
-	ticker          *time.Ticker
-	stopChan        chan bool
+	db                     *sql.DB
+	reminderService        *ReminderService
+	welfareLevyService     *WelfareLevyService
+	auditService           *AuditService
+	moneyRequestService    *MoneyRequestService
+	standingOrderService   *StandingOrderService
+	savingsService         *SavingsService
+	kycService             *KYCService
+	disputeService         *DisputeService
+	memberStatementService *MemberStatementService
+	jobs                   []schedulerJob
+	interval               time.Duration
+	ticker                 *time.Ticker
+	stopChan               chan bool
 }
 
-// NewNotificationScheduler creates a new notification scheduler
-func NewNotificationScheduler(db *sql.DB) *NotificationScheduler {
-	return &NotificationScheduler{
-		db:              db,
-		reminderService: NewReminderService(db),
-		stopChan:        make(chan bool),
+// schedulerJob is one piece of work run on every tick
+type schedulerJob struct {
+	name string
+	run  func()
+}
+
+// NewNotificationScheduler creates a new notification scheduler. Audit checkpoints
+// it creates are signed with auditSigningKey.
+func NewNotificationScheduler(db *sql.DB, auditSigningKey ed25519.PrivateKey) *NotificationScheduler {
+	ns := &NotificationScheduler{
+		db:                     db,
+		reminderService:        NewReminderService(db),
+		welfareLevyService:     NewWelfareLevyService(db),
+		auditService:           NewSigningAuditService(db, auditSigningKey),
+		moneyRequestService:    NewMoneyRequestService(db),
+		standingOrderService:   NewStandingOrderService(db),
+		savingsService:         NewSavingsService(db),
+		kycService:             NewKYCService(db),
+		disputeService:         NewDisputeService(db),
+		memberStatementService: NewMemberStatementService(db),
+		interval:               time.Minute,
+		stopChan:               make(chan bool),
 	}
+	ns.jobs = []schedulerJob{
+		{"reminder notifications", ns.processPendingNotifications},
+		{"welfare levy reminders", ns.welfareLevyService.SendDueReminders},
+		{"audit checkpoints", ns.auditService.CreateDueCheckpoints},
+		{"money requests", ns.moneyRequestService.ProcessDueRequests},
+		{"standing orders", ns.standingOrderService.ProcessDueStandingOrders},
+		{"savings sweeps and maturities", ns.savingsService.ProcessDueSavings},
+		{"KYC expiry", ns.kycService.ProcessExpiringVerifications},
+		{"overdue disputes", ns.disputeService.ProcessOverdueDisputes},
+		{"member statements", ns.memberStatementService.ProcessDueStatements},
+	}
+	return ns
 }
 
 // Start begins the notification scheduling process
 func (ns *NotificationScheduler) Start() {
 	log.Println("Starting notification scheduler...")
 
-	// Check for pending notifications every minute
-	ns.ticker = time.NewTicker(1 * time.Minute)
-	defer ns.ticker.Stop()
+	// Check for pending notifications every minute. The ticker runs until Stop is
+	// called; Start returns straight away and the jobs run in the goroutine below.
+	ns.ticker = time.NewTicker(ns.interval)
 
 	go func() {
 		defer func() {
@@ -43,14 +82,9 @@ func (ns *NotificationScheduler) Start() {
 		for {
 			select {
 			case <-ns.ticker.C:
-				func() {
-					defer func() {
-						if r := recover(); r != nil {
-							log.Printf("Notification processing panic recovered: %v", r)
-						}
-					}()
-					ns.processPendingNotifications()
-				}()
+				for _, job := range ns.jobs {
+					ns.runJob(job)
+				}
 			case <-ns.stopChan:
 				log.Println("Stopping notification scheduler...")
 				return
@@ -67,6 +101,22 @@ func (ns *NotificationScheduler) Stop() {
 	ns.stopChan <- true
 }
 
+// runJob runs one job, recovering from a panic so the jobs after it still run on
+// this tick. A job that takes longer than the tick is logged, since it holds up the
+// jobs after it.
+func (ns *NotificationScheduler) runJob(job schedulerJob) {
+	started := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Scheduler job %q panicked: %v", job.name, r)
+		}
+		if elapsed := time.Since(started); elapsed > ns.interval {
+			log.Printf("Scheduler job %q took %s, longer than the %s tick", job.name, elapsed.Round(time.Millisecond), ns.interval)
+		}
+	}()
+	job.run()
+}
+
 // processPendingNotifications checks for and processes pending reminder notifications
 func (ns *NotificationScheduler) processPendingNotifications() {
 	reminders, err := ns.reminderService.GetPendingReminders()
@@ -0,0 +1,331 @@
+package services_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/internal/utils"
+	"vaultke-backend/test/helpers"
+)
+
+func TestSchedulerTickSendsDueLevyReminders(t *testing.T) {
+	testDB := helpers.SetupMigratedTestDatabase(t)
+	db := testDB.DB
+
+	chairID := testDB.AddTestUser(t, "Chair")
+	beneficiaryID := testDB.AddTestUser(t, "Bereaved")
+	memberID := testDB.AddTestUser(t, "Member")
+	chamaID := testDB.AddTestChama(t, chairID)
+	testDB.AddTestChamaMember(t, chamaID, beneficiaryID, "member")
+	testDB.AddTestChamaMember(t, chamaID, memberID, "member")
+
+	requestID := "welfare-" + uuid.New().String()
+	_, err := db.Exec(`
+		INSERT INTO welfare_requests (id, chama_id, requester_id, beneficiary_id, title, description, amount, category, urgency, status)
+		VALUES (?, ?, ?, ?, 'Funeral expenses', 'Burial of a parent', 2000, 'bereavement', 'emergency', 'approved')
+	`, requestID, chamaID, beneficiaryID, beneficiaryID)
+	require.NoError(t, err)
+
+	levy, err := services.NewWelfareLevyService(db).RaiseLevy(chamaID, chairID, &models.RaiseWelfareLevyRequest{WelfareRequestID: requestID})
+	require.NoError(t, err)
+
+	// The last reminder went out two days ago, so the next tick is due to send one
+	_, err = db.Exec("UPDATE welfare_levies SET last_reminder_at = ? WHERE id = ?", time.Now().Add(-48*time.Hour), levy.ID)
+	require.NoError(t, err)
+
+	startScheduler(t, db)
+	requireEventually(t, db, 2, "a scheduler tick reminds both assessed members",
+		"SELECT COALESCE(SUM(reminders_sent), 0) FROM welfare_levy_assessments WHERE levy_id = ?", levy.ID)
+}
+
+func TestSchedulerTickCheckpointsAuditChains(t *testing.T) {
+	testDB := helpers.SetupMigratedTestDatabase(t)
+	db := testDB.DB
+	chairID := testDB.AddTestUser(t, "Chair")
+	chamaID := testDB.AddTestChama(t, chairID)
+
+	_, err := services.NewAuditService(db).Log(&models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &chairID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "chama",
+		EntityID:   chamaID,
+	})
+	require.NoError(t, err)
+
+	startScheduler(t, db)
+	requireEventually(t, db, 1, "a scheduler tick signs the chain head",
+		"SELECT COUNT(*) FROM audit_checkpoints WHERE chain_id = ?", chamaID)
+
+	// Later ticks within the interval leave the chain alone
+	time.Sleep(100 * time.Millisecond)
+	var checkpoints int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM audit_checkpoints WHERE chain_id = ?", chamaID).Scan(&checkpoints))
+	require.Equal(t, 1, checkpoints)
+}
+
+func TestSchedulerTickExpiresAndRemindsMoneyRequests(t *testing.T) {
+	testDB := helpers.SetupMigratedTestDatabase(t)
+	db := testDB.DB
+	requesterID := testDB.AddTestUser(t, "Requester")
+	payerID := testDB.AddTestUser(t, "Payer")
+
+	service := services.NewMoneyRequestService(db)
+	send := func(amount float64, expiresAt time.Time) string {
+		request, err := service.SendRequest(requesterID, payerID, &models.SendMoneyRequestRequest{
+			Amount:      amount,
+			Reason:      "Lunch",
+			RequestType: "direct",
+		})
+		require.NoError(t, err)
+		_, err = db.Exec("UPDATE money_requests SET expires_at = ? WHERE id = ?", expiresAt, request.ID)
+		require.NoError(t, err)
+		return request.ID
+	}
+	// Expiry times are kept in East Africa Time, whatever the host's zone
+	overdueID := send(400, utils.NowEAT().Add(-time.Hour))
+	expiringID := send(200, utils.NowEAT().Add(2*time.Hour))
+
+	startScheduler(t, db)
+	requireEventually(t, db, 1, "a scheduler tick expires the overdue request",
+		"SELECT COUNT(*) FROM money_requests WHERE id = ? AND status = ?", overdueID, models.MoneyRequestStatusExpired)
+	requireEventually(t, db, 1, "a scheduler tick reminds the payer once",
+		"SELECT COUNT(*) FROM money_requests WHERE id = ? AND status = ? AND reminder_sent_at IS NOT NULL", expiringID, models.MoneyRequestStatusPending)
+}
+
+func TestSchedulerTickRunsDueStandingOrders(t *testing.T) {
+	testDB := helpers.SetupMigratedTestDatabase(t)
+	db := testDB.DB
+	memberID := testDB.AddTestUser(t, "Saver")
+	chamaID := testDB.AddTestChama(t, memberID)
+	testDB.AddTestChamaMember(t, chamaID, memberID, "member")
+	_, err := db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'personal', ?, 2500)", uuid.New().String(), memberID)
+	require.NoError(t, err)
+
+	brokeID := testDB.AddTestUser(t, "Broke")
+	testDB.AddTestChamaMember(t, chamaID, brokeID, "member")
+	_, err = db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'personal', ?, 100)", uuid.New().String(), brokeID)
+	require.NoError(t, err)
+
+	createDue := func(userID string) string {
+		day := 5
+		order, err := services.NewStandingOrderService(db).CreateStandingOrder(userID, &models.CreateStandingOrderRequest{
+			TargetType: "chama",
+			ChamaID:    chamaID,
+			Amount:     1000,
+			Frequency:  "monthly",
+			DayOfMonth: &day,
+		})
+		require.NoError(t, err)
+		_, err = db.Exec("UPDATE standing_orders SET next_run_at = ? WHERE id = ?", utils.NowEAT().Add(-time.Minute), order.ID)
+		require.NoError(t, err)
+		return order.ID
+	}
+	paidID := createDue(memberID)
+	failingID := createDue(brokeID)
+
+	startScheduler(t, db)
+	requireEventually(t, db, 1, "a scheduler tick runs the due order",
+		"SELECT execution_count FROM standing_orders WHERE id = ?", paidID)
+	requireEventually(t, db, 1500, "the order is paid once",
+		"SELECT balance FROM wallets WHERE owner_id = ? AND type = 'personal'", memberID)
+	requireEventually(t, db, 1, "an order that cannot be paid is scheduled for a retry",
+		"SELECT COUNT(*) FROM standing_orders WHERE id = ? AND retry_count = 1 AND retry_at IS NOT NULL", failingID)
+}
+
+func TestSchedulerTickSweepsAndMaturesSavings(t *testing.T) {
+	testDB := helpers.SetupMigratedTestDatabase(t)
+	db := testDB.DB
+	memberID := testDB.AddTestUser(t, "Saver")
+	_, err := db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'personal', ?, 10000)", uuid.New().String(), memberID)
+	require.NoError(t, err)
+
+	service := services.NewSavingsService(db)
+	goal, err := service.CreateGoal(memberID, &models.CreateSavingsGoalRequest{Name: "Holiday", TargetAmount: 5000})
+	require.NoError(t, err)
+	day := 1
+	_, err = service.SetSweepRule(goal.ID, memberID, &models.SetSavingsSweepRequest{
+		SweepType: "fixed", Amount: 1500, Frequency: "monthly", Day: &day,
+	})
+	require.NoError(t, err)
+	fixed, err := service.CreateFixedSavings(memberID, &models.CreateFixedSavingsRequest{
+		Name: "School fees", Amount: 4000, TermMonths: 6,
+	})
+	require.NoError(t, err)
+
+	// Both fell due a minute ago, in East Africa Time
+	due := utils.NowEAT().Add(-time.Minute)
+	_, err = db.Exec("UPDATE savings_goals SET next_sweep_at = ? WHERE id = ?", due, goal.ID)
+	require.NoError(t, err)
+	_, err = db.Exec("UPDATE fixed_savings SET maturity_date = ? WHERE id = ?", due, fixed.ID)
+	require.NoError(t, err)
+
+	startScheduler(t, db)
+	requireEventually(t, db, 1, "a scheduler tick matures the fixed savings",
+		"SELECT COUNT(*) FROM fixed_savings WHERE id = ? AND status = ?", fixed.ID, models.FixedSavingsStatusMatured)
+	requireEventually(t, db, 8500, "a scheduler tick sweeps once and pays out the matured savings",
+		"SELECT balance FROM wallets WHERE owner_id = ? AND type = 'personal'", memberID)
+
+	swept, err := service.GetGoal(goal.ID, memberID)
+	require.NoError(t, err)
+	require.Equal(t, 1500.0, swept.Balance)
+}
+
+func TestSchedulerTickRemindsAndExpiresKYC(t *testing.T) {
+	testDB := helpers.SetupMigratedTestDatabase(t)
+	db := testDB.DB
+	adminID := testDB.AddTestUser(t, "Admin")
+	service := services.NewKYCService(db)
+
+	approve := func(idNumber string, expiresAt time.Time) (string, string) {
+		userID := testDB.AddTestUser(t, "Member")
+		submission, err := service.CreateSubmission(userID, &models.CreateKYCSubmissionRequest{
+			RequestedTier:    models.KYCTierBasic,
+			FullName:         "Jane Wanjiru",
+			NationalIDNumber: idNumber,
+			DateOfBirth:      "1990-04-12",
+		})
+		require.NoError(t, err)
+		_, _, err = service.AddDocument(submission.ID, userID, &models.KYCDocument{
+			DocumentType: models.KYCDocumentIDFront,
+			FileName:     "id_front.jpg",
+			FilePath:     "/tmp/id_front.jpg",
+			FileSize:     1024,
+			FileType:     "image/jpeg",
+		})
+		require.NoError(t, err)
+		_, err = service.Submit(submission.ID, userID)
+		require.NoError(t, err)
+		_, err = service.Approve(submission.ID, adminID, &models.ApproveKYCRequest{})
+		require.NoError(t, err)
+		_, err = db.Exec("UPDATE kyc_submissions SET expires_at = ? WHERE id = ?", expiresAt, submission.ID)
+		require.NoError(t, err)
+		return userID, submission.ID
+	}
+	expiringUserID, _ := approve("B1111111", utils.NowEAT().Add(10*24*time.Hour))
+	expiredUserID, expiredID := approve("B2222222", utils.NowEAT().Add(-time.Minute))
+
+	startScheduler(t, db)
+	requireEventually(t, db, 1, "a scheduler tick expires the lapsed verification",
+		"SELECT COUNT(*) FROM kyc_submissions WHERE id = ? AND status = ?", expiredID, models.KYCStatusExpired)
+	requireEventually(t, db, models.KYCTierUnverified, "the lapsed member drops back to tier 0",
+		"SELECT kyc_tier FROM users WHERE id = ?", expiredUserID)
+	requireEventually(t, db, 1, "a scheduler tick reminds the member whose verification is expiring",
+		"SELECT COUNT(*) FROM notifications WHERE user_id = ? AND title = 'Re-verify Your Identity'", expiringUserID)
+}
+
+func TestSchedulerTickEscalatesOverdueDisputes(t *testing.T) {
+	testDB := helpers.SetupMigratedTestDatabase(t)
+	db := testDB.DB
+	senderID := testDB.AddTestUser(t, "Sender")
+	recipientID := testDB.AddTestUser(t, "Recipient")
+	for ownerID, balance := range map[string]float64{senderID: 10000, recipientID: 0} {
+		_, err := db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'personal', ?, ?)", "wallet-"+ownerID, ownerID, balance)
+		require.NoError(t, err)
+	}
+
+	wallets := services.NewWalletService(db)
+	fromWalletID, toWalletID := "wallet-"+senderID, "wallet-"+recipientID
+	transaction, err := wallets.CreateTransaction(&models.TransactionCreation{
+		FromWalletID:  &fromWalletID,
+		ToWalletID:    &toWalletID,
+		Type:          models.TransactionTypeTransfer,
+		Amount:        4000,
+		PaymentMethod: models.PaymentMethodWalletTransfer,
+		Metadata:      map[string]interface{}{},
+	}, senderID)
+	require.NoError(t, err)
+	require.NoError(t, wallets.ProcessTransaction(transaction.ID))
+
+	dispute, err := services.NewDisputeService(db).OpenDispute(senderID, &models.OpenDisputeRequest{
+		TransactionID: transaction.ID, Reason: "unauthorized", Description: "I did not send this",
+	})
+	require.NoError(t, err)
+	_, err = db.Exec("UPDATE disputes SET response_deadline = ? WHERE id = ?", utils.NowEAT().Add(-time.Minute), dispute.ID)
+	require.NoError(t, err)
+
+	startScheduler(t, db)
+	requireEventually(t, db, 1, "a scheduler tick sends the unanswered dispute for review",
+		"SELECT COUNT(*) FROM disputes WHERE id = ? AND status = ?", dispute.ID, models.DisputeStatusUnderReview)
+	requireEventually(t, db, 1, "the recipient is told once",
+		"SELECT COUNT(*) FROM notifications WHERE user_id = ? AND title = 'Dispute Under Review'", recipientID)
+}
+
+func TestSchedulerTickGeneratesMonthlyStatements(t *testing.T) {
+	testDB := helpers.SetupMigratedTestDatabase(t)
+	db := testDB.DB
+	chairID := testDB.AddTestUser(t, "Chair")
+	memberID := testDB.AddTestUser(t, "Member")
+	chamaID := testDB.AddTestChama(t, chairID)
+	testDB.AddTestChamaMember(t, chamaID, memberID, "member")
+
+	now := utils.NowEAT()
+	lastMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, utils.EATLocation).AddDate(0, -1, 0)
+	_, err := db.Exec("UPDATE chama_members SET joined_at = ? WHERE chama_id = ?", lastMonth.AddDate(0, -1, 0), chamaID)
+	require.NoError(t, err)
+
+	startScheduler(t, db)
+	requireEventually(t, db, 2, "a scheduler tick generates, keeps and emails last month's statements",
+		"SELECT COUNT(*) FROM member_statements WHERE chama_id = ? AND period_month = ? AND emailed_at IS NOT NULL",
+		chamaID, lastMonth.Format("2006-01"))
+
+	// Later ticks do not generate the month again
+	time.Sleep(100 * time.Millisecond)
+	var statements int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM member_statements WHERE chama_id = ?", chamaID).Scan(&statements))
+	require.Equal(t, 2, statements)
+}
+
+func TestSchedulerJobPanicDoesNotSkipLaterJobs(t *testing.T) {
+	testDB := helpers.SetupMigratedTestDatabase(t)
+	db := testDB.DB
+
+	requesterID := testDB.AddTestUser(t, "Requester")
+	payerID := testDB.AddTestUser(t, "Payer")
+	request, err := services.NewMoneyRequestService(db).SendRequest(requesterID, payerID, &models.SendMoneyRequestRequest{
+		Amount:      400,
+		Reason:      "Lunch",
+		RequestType: "direct",
+	})
+	require.NoError(t, err)
+	_, err = db.Exec("UPDATE money_requests SET expires_at = ? WHERE id = ?", utils.NowEAT().Add(-time.Hour), request.ID)
+	require.NoError(t, err)
+
+	// The first job panics on every tick
+	startScheduler(t, db, func(scheduler *services.NotificationScheduler) {
+		services.PrependSchedulerJob(scheduler, "broken job", func() { panic("broken job") })
+	})
+	requireEventually(t, db, 1, "the jobs after a panicking one still run",
+		"SELECT COUNT(*) FROM money_requests WHERE id = ? AND status = ?", request.ID, models.MoneyRequestStatusExpired)
+}
+
+// startScheduler runs the notification scheduler on a short tick until the test ends,
+// after applying any setup to it
+func startScheduler(t *testing.T, db *sql.DB, setup ...func(*services.NotificationScheduler)) {
+	t.Helper()
+
+	scheduler := services.NewNotificationScheduler(db, testAuditSigningKey(t))
+	services.SetSchedulerInterval(scheduler, 20*time.Millisecond)
+	for _, apply := range setup {
+		apply(scheduler)
+	}
+	scheduler.Start()
+	t.Cleanup(scheduler.Stop)
+}
+
+// requireEventually waits for a single-value query to return want
+func requireEventually(t *testing.T, db *sql.DB, want float64, message, query string, args ...interface{}) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		var got float64
+		err := db.QueryRow(query, args...).Scan(&got)
+		return err == nil && got == want
+	}, 5*time.Second, 20*time.Millisecond, message)
+}
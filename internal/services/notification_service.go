@@ -139,6 +139,31 @@ func (s *NotificationService) CreateNotification(userID string, notifType Notifi
 	return notification, nil
 }
 
+// CreateInAppNotification stores an in-app notification using the notification
+// system schema. notifType must be one of chama, transaction, reminder, system,
+// marketing or alert.
+func (s *NotificationService) CreateInAppNotification(userID, notifType, category, title, message string, data map[string]interface{}) error {
+	dataJSON := "{}"
+	if data != nil {
+		dataBytes, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to serialize notification data: %w", err)
+		}
+		dataJSON = string(dataBytes)
+	}
+
+	now := time.Now()
+	_, err := s.db.Exec(`
+		INSERT INTO notifications (user_id, title, message, type, priority, category, data, is_read, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 'normal', ?, ?, false, ?, ?)
+	`, userID, title, message, notifType, category, dataJSON, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return nil
+}
+
 // GetUserNotifications retrieves notifications for a user
 func (s *NotificationService) GetUserNotifications(userID string, limit, offset int) ([]*Notification, error) {
 	query := `
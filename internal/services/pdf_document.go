@@ -0,0 +1,238 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// pdfFont is one of the standard PDF fonts, which viewers provide without embedding
+type pdfFont string
+
+const (
+	pdfFontRegular pdfFont = "Helvetica"
+	pdfFontBold    pdfFont = "Helvetica-Bold"
+)
+
+// Standard A4 page sizes in points
+const (
+	pdfA4Width  = 595.0
+	pdfA4Height = 842.0
+)
+
+// Glyph widths of the printable ASCII range (32 to 126) in thousandths of the font
+// size, taken from the Adobe font metrics
+var pdfFontWidths = map[pdfFont][]int{
+	pdfFontRegular: {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	pdfFontBold: {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+type pdfPage struct {
+	width   float64
+	height  float64
+	content bytes.Buffer
+}
+
+// pdfDocument is a minimal PDF writer for generated documents such as certificates
+// and statements. Coordinates are in points measured from the top-left corner of
+// the page.
+type pdfDocument struct {
+	pages []*pdfPage
+	page  *pdfPage
+}
+
+func newPDFDocument() *pdfDocument {
+	return &pdfDocument{}
+}
+
+// AddPage starts a new page; later drawing calls go to it
+func (d *pdfDocument) AddPage(width, height float64) {
+	d.page = &pdfPage{width: width, height: height}
+	d.pages = append(d.pages, d.page)
+}
+
+// PageHeight returns the height of the current page
+func (d *pdfDocument) PageHeight() float64 {
+	return d.page.height
+}
+
+func (d *pdfDocument) SetFillColor(r, g, b float64) {
+	fmt.Fprintf(&d.page.content, "%s %s %s rg\n", pdfNumber(r), pdfNumber(g), pdfNumber(b))
+}
+
+func (d *pdfDocument) SetStrokeColor(r, g, b float64) {
+	fmt.Fprintf(&d.page.content, "%s %s %s RG\n", pdfNumber(r), pdfNumber(g), pdfNumber(b))
+}
+
+func (d *pdfDocument) SetLineWidth(width float64) {
+	fmt.Fprintf(&d.page.content, "%s w\n", pdfNumber(width))
+}
+
+// Text draws text with its baseline at y
+func (d *pdfDocument) Text(x, y float64, font pdfFont, size float64, text string) {
+	fmt.Fprintf(&d.page.content, "BT /%s %s Tf %s %s Td (%s) Tj ET\n",
+		pdfFontResource(font), pdfNumber(size), pdfNumber(x), pdfNumber(d.page.height-y), pdfEscape(text))
+}
+
+// TextCentered draws text centred on x
+func (d *pdfDocument) TextCentered(x, y float64, font pdfFont, size float64, text string) {
+	d.Text(x-pdfTextWidth(font, size, text)/2, y, font, size, text)
+}
+
+// TextRight draws text ending at x, for right-aligned columns of figures
+func (d *pdfDocument) TextRight(x, y float64, font pdfFont, size float64, text string) {
+	d.Text(x-pdfTextWidth(font, size, text), y, font, size, text)
+}
+
+func (d *pdfDocument) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&d.page.content, "%s %s m %s %s l S\n",
+		pdfNumber(x1), pdfNumber(d.page.height-y1), pdfNumber(x2), pdfNumber(d.page.height-y2))
+}
+
+// Rect strokes the outline of a rectangle whose top-left corner is at x, y
+func (d *pdfDocument) Rect(x, y, width, height float64) {
+	fmt.Fprintf(&d.page.content, "%s %s %s %s re S\n",
+		pdfNumber(x), pdfNumber(d.page.height-y-height), pdfNumber(width), pdfNumber(height))
+}
+
+// FillRect fills a rectangle whose top-left corner is at x, y
+func (d *pdfDocument) FillRect(x, y, width, height float64) {
+	fmt.Fprintf(&d.page.content, "%s %s %s %s re f\n",
+		pdfNumber(x), pdfNumber(d.page.height-y-height), pdfNumber(width), pdfNumber(height))
+}
+
+// QRCode draws a QR code as a size by size square, including the quiet zone
+func (d *pdfDocument) QRCode(x, y, size float64, qr *qrCode) {
+	const quietZone = 4
+	module := size / float64(qr.Size+2*quietZone)
+	d.SetFillColor(1, 1, 1)
+	d.FillRect(x, y, size, size)
+	d.SetFillColor(0, 0, 0)
+	for row := 0; row < qr.Size; row++ {
+		// Runs of dark modules are drawn as one rectangle
+		for col := 0; col < qr.Size; col++ {
+			if !qr.Modules[row][col] {
+				continue
+			}
+			start := col
+			for col+1 < qr.Size && qr.Modules[row][col+1] {
+				col++
+			}
+			d.FillRect(x+float64(start+quietZone)*module, y+float64(row+quietZone)*module, float64(col-start+1)*module, module)
+		}
+	}
+}
+
+// Bytes serialises the document
+func (d *pdfDocument) Bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 and 2 are the catalog and page tree, 3 and 4 the fonts, then a
+	// page and content stream object per page
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	for _, font := range []pdfFont{pdfFontRegular, pdfFontBold} {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font))
+	}
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfNumber(page.width), pdfNumber(page.height), 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// pdfTextWidth measures text in points; characters outside the ASCII range are
+// given the width of a digit
+func pdfTextWidth(font pdfFont, size float64, text string) float64 {
+	widths := pdfFontWidths[font]
+	total := 0
+	for _, r := range text {
+		if r >= 32 && r <= 126 {
+			total += widths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// pdfFitText shortens text with an ellipsis so it fits within width, for table cells
+func pdfFitText(font pdfFont, size float64, text string, width float64) string {
+	if pdfTextWidth(font, size, text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdfTextWidth(font, size, string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "..."
+}
+
+func pdfFontResource(font pdfFont) string {
+	if font == pdfFontBold {
+		return "F2"
+	}
+	return "F1"
+}
+
+// pdfEscape encodes text as a WinAnsi string literal; characters it cannot
+// represent are replaced with a question mark
+func pdfEscape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r <= 126:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+func pdfNumber(value float64) string {
+	s := fmt.Sprintf("%.2f", value)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
@@ -620,6 +620,22 @@ func (s *PollsService) executeRoleChange(req *models.RoleEscalationRequest) erro
 		return fmt.Errorf("failed to create role change log: %w", err)
 	}
 
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    req.ChamaID,
+		ActorID:    &req.RequestedBy,
+		Action:     models.AuditActionRoleChange,
+		EntityType: "chama_member",
+		EntityID:   req.CandidateID,
+		Details: map[string]interface{}{
+			"oldRole": req.CurrentRole,
+			"newRole": req.RequestedRole,
+			"reason":  "Role escalation poll approved",
+		},
+	})
+	if err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
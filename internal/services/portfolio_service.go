@@ -0,0 +1,925 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"vaultke-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// PortfolioService tracks what an investment chama's pooled money is invested in:
+// assets such as land and treasury bills, their valuations, the income they earn
+// and the chama's net asset value over time
+type PortfolioService struct {
+	db          *sql.DB
+	shareMarket *ShareMarketService
+}
+
+// NewPortfolioService creates a new portfolio service
+func NewPortfolioService(db *sql.DB) *PortfolioService {
+	return &PortfolioService{
+		db:          db,
+		shareMarket: NewShareMarketService(db),
+	}
+}
+
+// CreateAsset records an asset the chama holds, valued at cost until it is revalued
+func (s *PortfolioService) CreateAsset(chamaID, userID string, req *models.CreatePortfolioAssetRequest) (*models.PortfolioAsset, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can record investments")
+	}
+	if req.AcquisitionDate.After(time.Now()) {
+		return nil, fmt.Errorf("acquisition date cannot be in the future")
+	}
+	if req.MaturityDate != nil && !req.MaturityDate.After(req.AcquisitionDate) {
+		return nil, fmt.Errorf("maturity date must be after the acquisition date")
+	}
+
+	now := time.Now()
+	asset := &models.PortfolioAsset{
+		ID:              uuid.New().String(),
+		ChamaID:         chamaID,
+		Name:            req.Name,
+		AssetType:       models.PortfolioAssetType(req.AssetType),
+		Description:     req.Description,
+		AcquisitionDate: req.AcquisitionDate,
+		AcquisitionCost: req.AcquisitionCost,
+		CurrentValue:    req.AcquisitionCost,
+		LastValuedAt:    req.AcquisitionDate,
+		MaturityDate:    req.MaturityDate,
+		Status:          models.PortfolioAssetActive,
+		CreatedBy:       userID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO portfolio_assets (
+			id, chama_id, name, asset_type, description, acquisition_date, acquisition_cost,
+			current_value, last_valued_at, maturity_date, status, created_by, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, asset.ID, asset.ChamaID, asset.Name, asset.AssetType, asset.Description, asset.AcquisitionDate,
+		asset.AcquisitionCost, asset.CurrentValue, asset.LastValuedAt, asset.MaturityDate, asset.Status,
+		asset.CreatedBy, asset.CreatedAt, asset.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create portfolio asset: %w", err)
+	}
+
+	if err := s.insertValuation(tx, asset, asset.AcquisitionCost, asset.AcquisitionDate, models.PortfolioValuationCost, nil, userID); err != nil {
+		return nil, err
+	}
+
+	// An asset valued differently from its cost when it is first recorded, such as
+	// land bought years ago, gets a second valuation as of today
+	if req.CurrentValue != nil && *req.CurrentValue != req.AcquisitionCost {
+		notes := "Value when first recorded"
+		if err := s.insertValuation(tx, asset, *req.CurrentValue, now, models.PortfolioValuationAppraisal, &notes, userID); err != nil {
+			return nil, err
+		}
+		asset.CurrentValue = *req.CurrentValue
+		asset.LastValuedAt = now
+		if _, err := tx.Exec("UPDATE portfolio_assets SET current_value = ?, last_valued_at = ? WHERE id = ?", asset.CurrentValue, now, asset.ID); err != nil {
+			return nil, fmt.Errorf("failed to update portfolio asset value: %w", err)
+		}
+	}
+
+	if req.PayFromWallet {
+		description := fmt.Sprintf("Investment in %s", asset.Name)
+		_, err := postChamaWalletTransaction(tx, chamaID, userID, models.TransactionTypeInvestment, -asset.AcquisitionCost, nil, description, map[string]interface{}{
+			"portfolioAssetId": asset.ID,
+			"assetType":        asset.AssetType,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionDeclaration,
+		EntityType: "portfolio_asset",
+		EntityID:   asset.ID,
+		Amount:     &asset.AcquisitionCost,
+		Details: map[string]interface{}{
+			"name":          asset.Name,
+			"assetType":     asset.AssetType,
+			"payFromWallet": req.PayFromWallet,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	asset.UnrealizedGain = roundCurrency(asset.CurrentValue - asset.AcquisitionCost)
+	log.Printf("Recorded %s asset %s for chama %s at %.2f", asset.AssetType, asset.ID, chamaID, asset.AcquisitionCost)
+	return asset, nil
+}
+
+// GetAssets lists a chama's assets with their income to date. An empty status returns all.
+func (s *PortfolioService) GetAssets(chamaID, userID, status string) ([]models.PortfolioAsset, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	query := portfolioAssetSelect + " WHERE chama_id = ?"
+	args := []interface{}{chamaID}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY acquisition_date DESC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio assets: %w", err)
+	}
+	defer rows.Close()
+
+	assets := []models.PortfolioAsset{}
+	for rows.Next() {
+		asset, err := scanPortfolioAsset(rows)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, *asset)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read portfolio assets: %w", err)
+	}
+
+	totals, err := s.entryTotals(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range assets {
+		s.applyTotals(&assets[i], totals)
+	}
+	return assets, nil
+}
+
+// GetAsset returns an asset with its valuation and income history
+func (s *PortfolioService) GetAsset(chamaID, assetID, userID string) (*models.PortfolioAssetDetail, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	asset, err := s.getAsset(chamaID, assetID)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.entryTotals(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	s.applyTotals(asset, totals)
+
+	detail := &models.PortfolioAssetDetail{PortfolioAsset: *asset}
+	detail.Valuations, err = s.getValuations("asset_id = ?", assetID)
+	if err != nil {
+		return nil, err
+	}
+	detail.Entries, err = s.getEntries("asset_id = ?", assetID)
+	if err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+// RecordValuation records a new value for an asset. It becomes the current value
+// unless a later valuation has already been recorded.
+func (s *PortfolioService) RecordValuation(chamaID, assetID, userID string, req *models.RecordPortfolioValuationRequest) (*models.PortfolioAsset, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can value investments")
+	}
+
+	asset, err := s.getAsset(chamaID, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if asset.Status != models.PortfolioAssetActive {
+		return nil, fmt.Errorf("a disposed asset cannot be revalued")
+	}
+	if req.ValuationDate.Before(asset.AcquisitionDate) {
+		return nil, fmt.Errorf("valuation date cannot be before the asset was acquired")
+	}
+	if req.ValuationDate.After(time.Now()) {
+		return nil, fmt.Errorf("valuation date cannot be in the future")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.insertValuation(tx, asset, req.Value, req.ValuationDate, models.PortfolioValuationSource(req.Source), req.Notes, userID); err != nil {
+		return nil, err
+	}
+
+	if !req.ValuationDate.Before(asset.LastValuedAt) {
+		previous := asset.CurrentValue
+		asset.CurrentValue = req.Value
+		asset.LastValuedAt = req.ValuationDate
+		asset.UpdatedAt = time.Now()
+		_, err = tx.Exec("UPDATE portfolio_assets SET current_value = ?, last_valued_at = ?, updated_at = ? WHERE id = ?",
+			asset.CurrentValue, asset.LastValuedAt, asset.UpdatedAt, asset.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update portfolio asset value: %w", err)
+		}
+
+		err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+			ChainID:    chamaID,
+			ActorID:    &userID,
+			Action:     models.AuditActionSettingsChange,
+			EntityType: "portfolio_asset",
+			EntityID:   asset.ID,
+			Amount:     &req.Value,
+			Details: map[string]interface{}{
+				"previousValue": previous,
+				"source":        req.Source,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	totals, err := s.entryTotals(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	s.applyTotals(asset, totals)
+	return asset, nil
+}
+
+// RecordEntry records income received from or an expense paid on an asset
+func (s *PortfolioService) RecordEntry(chamaID, assetID, userID string, req *models.RecordPortfolioEntryRequest) (*models.PortfolioEntry, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can record investment income and expenses")
+	}
+
+	asset, err := s.getAsset(chamaID, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if req.EntryDate.Before(asset.AcquisitionDate) {
+		return nil, fmt.Errorf("entry date cannot be before the asset was acquired")
+	}
+	if req.EntryDate.After(time.Now()) {
+		return nil, fmt.Errorf("entry date cannot be in the future")
+	}
+
+	entry := &models.PortfolioEntry{
+		ID:          uuid.New().String(),
+		AssetID:     asset.ID,
+		ChamaID:     chamaID,
+		EntryType:   models.PortfolioEntryType(req.EntryType),
+		Category:    req.Category,
+		Amount:      roundCurrency(req.Amount),
+		EntryDate:   req.EntryDate,
+		Description: req.Description,
+		RecordedBy:  userID,
+		CreatedAt:   time.Now(),
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if req.ThroughWallet {
+		txType, amount := models.TransactionTypeInvestmentIncome, entry.Amount
+		description := fmt.Sprintf("%s from %s", entry.Category, asset.Name)
+		if entry.EntryType == models.PortfolioEntryExpense {
+			txType, amount = models.TransactionTypeInvestmentExpense, -entry.Amount
+			description = fmt.Sprintf("%s for %s", entry.Category, asset.Name)
+		}
+		transactionID, err := postChamaWalletTransaction(tx, chamaID, userID, txType, amount, nil, description, map[string]interface{}{
+			"portfolioAssetId": asset.ID,
+			"portfolioEntryId": entry.ID,
+			"category":         entry.Category,
+		})
+		if err != nil {
+			return nil, err
+		}
+		entry.TransactionID = &transactionID
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO portfolio_entries (
+			id, asset_id, chama_id, entry_type, category, amount, entry_date, description,
+			transaction_id, recorded_by, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, entry.ID, entry.AssetID, entry.ChamaID, entry.EntryType, entry.Category, entry.Amount, entry.EntryDate,
+		entry.Description, entry.TransactionID, entry.RecordedBy, entry.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record portfolio %s: %w", entry.EntryType, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return entry, nil
+}
+
+// DisposeAsset records the sale or maturity of an asset. The proceeds become its
+// final valuation and, with CreditWallet, are paid into the chama wallet.
+func (s *PortfolioService) DisposeAsset(chamaID, assetID, userID string, req *models.DisposePortfolioAssetRequest) (*models.PortfolioAsset, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can dispose of investments")
+	}
+
+	asset, err := s.getAsset(chamaID, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if req.DisposalDate.Before(asset.AcquisitionDate) {
+		return nil, fmt.Errorf("disposal date cannot be before the asset was acquired")
+	}
+	if req.DisposalDate.After(time.Now()) {
+		return nil, fmt.Errorf("disposal date cannot be in the future")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	amount := roundCurrency(req.Amount)
+	result, err := tx.Exec(`
+		UPDATE portfolio_assets
+		SET status = 'disposed', disposal_date = ?, disposal_amount = ?, current_value = 0, last_valued_at = ?, updated_at = ?
+		WHERE id = ? AND status = 'active'
+	`, req.DisposalDate, amount, req.DisposalDate, now, asset.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dispose of portfolio asset: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("asset has already been disposed of")
+	}
+
+	if err := s.insertValuation(tx, asset, amount, req.DisposalDate, models.PortfolioValuationDisposal, req.Notes, userID); err != nil {
+		return nil, err
+	}
+
+	if req.CreditWallet && amount > 0 {
+		description := fmt.Sprintf("Proceeds from %s", asset.Name)
+		_, err := postChamaWalletTransaction(tx, chamaID, userID, models.TransactionTypeInvestmentSale, amount, nil, description, map[string]interface{}{
+			"portfolioAssetId": asset.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionDeclaration,
+		EntityType: "portfolio_asset",
+		EntityID:   asset.ID,
+		Amount:     &amount,
+		Details: map[string]interface{}{
+			"event":           "disposal",
+			"acquisitionCost": asset.AcquisitionCost,
+			"creditWallet":    req.CreditWallet,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	asset, err = s.getAsset(chamaID, assetID)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.entryTotals(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	s.applyTotals(asset, totals)
+	return asset, nil
+}
+
+// RecordNAVSnapshot records the chama's net asset value as it stands now
+func (s *PortfolioService) RecordNAVSnapshot(chamaID, userID string, req *models.RecordNAVSnapshotRequest) (*models.NAVSnapshot, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can record the net asset value")
+	}
+
+	valuation, err := s.shareMarket.computeValuation(chamaID, req.OtherAssets, req.Liabilities)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := newNAVSnapshot(valuation, userID, req.Notes)
+	if err := insertNAVSnapshot(s.db, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// GetNAVHistory returns the recorded net asset values between from and to, oldest first
+func (s *PortfolioService) GetNAVHistory(chamaID, userID string, from, to time.Time) ([]models.NAVSnapshot, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	return s.navHistory(chamaID, from, to)
+}
+
+// GetPerformanceReport reports each asset's return between from (inclusive) and to
+// (exclusive), the portfolio's allocation at the end of the period and the NAV
+// history over it
+func (s *PortfolioService) GetPerformanceReport(chamaID, userID string, from, to time.Time) (*models.PortfolioPerformanceReport, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	rows, err := s.db.Query(portfolioAssetSelect+" WHERE chama_id = ? ORDER BY acquisition_date", chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio assets: %w", err)
+	}
+	var assets []*models.PortfolioAsset
+	for rows.Next() {
+		asset, err := scanPortfolioAsset(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+	rows.Close()
+
+	valuations, err := s.getValuations("chama_id = ?", chamaID)
+	if err != nil {
+		return nil, err
+	}
+	byAsset := make(map[string][]models.PortfolioValuation)
+	for _, valuation := range valuations {
+		byAsset[valuation.AssetID] = append(byAsset[valuation.AssetID], valuation)
+	}
+	entries, err := s.getEntries("chama_id = ?", chamaID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.PortfolioPerformanceReport{
+		ChamaID:    chamaID,
+		From:       from,
+		To:         to,
+		Assets:     []models.PortfolioAssetPerformance{},
+		Allocation: []models.PortfolioAllocation{},
+	}
+	allocation := make(map[models.PortfolioAssetType]float64)
+
+	for _, asset := range assets {
+		if !asset.AcquisitionDate.Before(to) {
+			continue
+		}
+		disposedInPeriod := asset.DisposalDate != nil && asset.DisposalDate.Before(to)
+		if asset.DisposalDate != nil && asset.DisposalDate.Before(from) {
+			continue
+		}
+
+		performance := models.PortfolioAssetPerformance{
+			AssetID:   asset.ID,
+			Name:      asset.Name,
+			AssetType: asset.AssetType,
+			Status:    asset.Status,
+		}
+		if asset.AcquisitionDate.Before(from) {
+			performance.StartBasis = valueBefore(byAsset[asset.ID], from, true)
+		} else {
+			performance.StartBasis = asset.AcquisitionCost
+		}
+		if disposedInPeriod {
+			performance.EndValue = *asset.DisposalAmount
+		} else {
+			performance.EndValue = valueBefore(byAsset[asset.ID], to, false)
+			allocation[asset.AssetType] += performance.EndValue
+		}
+
+		for _, entry := range entries {
+			if entry.AssetID != asset.ID || entry.EntryDate.Before(from) || !entry.EntryDate.Before(to) {
+				continue
+			}
+			if entry.EntryType == models.PortfolioEntryIncome {
+				performance.Income += entry.Amount
+			} else {
+				performance.Expenses += entry.Amount
+			}
+		}
+
+		performance.Income = roundCurrency(performance.Income)
+		performance.Expenses = roundCurrency(performance.Expenses)
+		performance.CapitalGain = roundCurrency(performance.EndValue - performance.StartBasis)
+		performance.TotalReturn = roundCurrency(performance.CapitalGain + performance.Income - performance.Expenses)
+		if performance.StartBasis > 0 {
+			performance.ReturnPercent = roundCurrency(performance.TotalReturn / performance.StartBasis * 100)
+		}
+
+		report.Assets = append(report.Assets, performance)
+		report.TotalStartBasis += performance.StartBasis
+		report.TotalEndValue += performance.EndValue
+		report.TotalIncome += performance.Income
+		report.TotalExpenses += performance.Expenses
+		report.TotalReturn += performance.TotalReturn
+	}
+
+	report.TotalStartBasis = roundCurrency(report.TotalStartBasis)
+	report.TotalEndValue = roundCurrency(report.TotalEndValue)
+	report.TotalIncome = roundCurrency(report.TotalIncome)
+	report.TotalExpenses = roundCurrency(report.TotalExpenses)
+	report.TotalReturn = roundCurrency(report.TotalReturn)
+	if report.TotalStartBasis > 0 {
+		report.ReturnPercent = roundCurrency(report.TotalReturn / report.TotalStartBasis * 100)
+	}
+
+	var held float64
+	for _, value := range allocation {
+		held += value
+	}
+	for assetType, value := range allocation {
+		share := models.PortfolioAllocation{AssetType: assetType, Value: roundCurrency(value)}
+		if held > 0 {
+			share.Percent = roundCurrency(value / held * 100)
+		}
+		report.Allocation = append(report.Allocation, share)
+	}
+	sort.Slice(report.Allocation, func(i, j int) bool {
+		return report.Allocation[i].Value > report.Allocation[j].Value
+	})
+
+	report.NAVHistory, err = s.navHistory(chamaID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(report.NAVHistory) > 0 {
+		report.NAVPerShareStart = &report.NAVHistory[0].NAVPerShare
+		report.NAVPerShareEnd = &report.NAVHistory[len(report.NAVHistory)-1].NAVPerShare
+	}
+
+	return report, nil
+}
+
+// Helper functions
+
+// valueBefore returns the latest valuation dated before the given time, or at it
+// when inclusive. Valuations are sorted by date.
+func valueBefore(valuations []models.PortfolioValuation, at time.Time, inclusive bool) float64 {
+	value := 0.0
+	for _, valuation := range valuations {
+		if valuation.ValuationDate.After(at) || (!inclusive && valuation.ValuationDate.Equal(at)) {
+			break
+		}
+		value = valuation.Value
+	}
+	return value
+}
+
+const portfolioAssetSelect = `
+	SELECT id, chama_id, name, asset_type, description, acquisition_date, acquisition_cost,
+		current_value, last_valued_at, maturity_date, status, disposal_date, disposal_amount,
+		created_by, created_at, updated_at
+	FROM portfolio_assets`
+
+func scanPortfolioAsset(row interface{ Scan(...interface{}) error }) (*models.PortfolioAsset, error) {
+	asset := &models.PortfolioAsset{}
+	var description sql.NullString
+	var maturityDate, disposalDate sql.NullTime
+	var disposalAmount sql.NullFloat64
+	err := row.Scan(&asset.ID, &asset.ChamaID, &asset.Name, &asset.AssetType, &description, &asset.AcquisitionDate,
+		&asset.AcquisitionCost, &asset.CurrentValue, &asset.LastValuedAt, &maturityDate, &asset.Status,
+		&disposalDate, &disposalAmount, &asset.CreatedBy, &asset.CreatedAt, &asset.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan portfolio asset: %w", err)
+	}
+
+	if description.Valid {
+		asset.Description = &description.String
+	}
+	if maturityDate.Valid {
+		asset.MaturityDate = &maturityDate.Time
+	}
+	if disposalDate.Valid {
+		asset.DisposalDate = &disposalDate.Time
+	}
+	if disposalAmount.Valid {
+		asset.DisposalAmount = &disposalAmount.Float64
+	}
+	return asset, nil
+}
+
+func (s *PortfolioService) getAsset(chamaID, assetID string) (*models.PortfolioAsset, error) {
+	asset, err := scanPortfolioAsset(s.db.QueryRow(portfolioAssetSelect+" WHERE id = ? AND chama_id = ?", assetID, chamaID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("portfolio asset not found")
+	}
+	return asset, err
+}
+
+func (s *PortfolioService) insertValuation(tx *sql.Tx, asset *models.PortfolioAsset, value float64, date time.Time, source models.PortfolioValuationSource, notes *string, userID string) error {
+	_, err := tx.Exec(`
+		INSERT INTO portfolio_valuations (id, asset_id, chama_id, value, valuation_date, source, notes, recorded_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, uuid.New().String(), asset.ID, asset.ChamaID, roundCurrency(value), date, source, notes, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record portfolio valuation: %w", err)
+	}
+	return nil
+}
+
+// getValuations returns valuations matching a condition, oldest first
+func (s *PortfolioService) getValuations(condition string, arg interface{}) ([]models.PortfolioValuation, error) {
+	rows, err := s.db.Query(`
+		SELECT id, asset_id, chama_id, value, valuation_date, source, notes, recorded_by, created_at
+		FROM portfolio_valuations WHERE `+condition, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio valuations: %w", err)
+	}
+	defer rows.Close()
+
+	valuations := []models.PortfolioValuation{}
+	for rows.Next() {
+		var valuation models.PortfolioValuation
+		var notes sql.NullString
+		err := rows.Scan(&valuation.ID, &valuation.AssetID, &valuation.ChamaID, &valuation.Value, &valuation.ValuationDate,
+			&valuation.Source, &notes, &valuation.RecordedBy, &valuation.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan portfolio valuation: %w", err)
+		}
+		if notes.Valid {
+			valuation.Notes = &notes.String
+		}
+		valuations = append(valuations, valuation)
+	}
+
+	// Dates are compared in Go: the driver's stored format does not sort reliably
+	sort.SliceStable(valuations, func(i, j int) bool {
+		if valuations[i].ValuationDate.Equal(valuations[j].ValuationDate) {
+			return valuations[i].CreatedAt.Before(valuations[j].CreatedAt)
+		}
+		return valuations[i].ValuationDate.Before(valuations[j].ValuationDate)
+	})
+	return valuations, nil
+}
+
+// getEntries returns income and expense entries matching a condition, newest first
+func (s *PortfolioService) getEntries(condition string, arg interface{}) ([]models.PortfolioEntry, error) {
+	rows, err := s.db.Query(`
+		SELECT id, asset_id, chama_id, entry_type, category, amount, entry_date, description,
+			transaction_id, recorded_by, created_at
+		FROM portfolio_entries WHERE `+condition, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.PortfolioEntry{}
+	for rows.Next() {
+		var entry models.PortfolioEntry
+		var description, transactionID sql.NullString
+		err := rows.Scan(&entry.ID, &entry.AssetID, &entry.ChamaID, &entry.EntryType, &entry.Category, &entry.Amount,
+			&entry.EntryDate, &description, &transactionID, &entry.RecordedBy, &entry.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan portfolio entry: %w", err)
+		}
+		if description.Valid {
+			entry.Description = &description.String
+		}
+		if transactionID.Valid {
+			entry.TransactionID = &transactionID.String
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].EntryDate.After(entries[j].EntryDate)
+	})
+	return entries, nil
+}
+
+type portfolioEntryTotals struct {
+	income   float64
+	expenses float64
+}
+
+func (s *PortfolioService) entryTotals(chamaID string) (map[string]portfolioEntryTotals, error) {
+	rows, err := s.db.Query(`
+		SELECT asset_id, entry_type, COALESCE(SUM(amount), 0)
+		FROM portfolio_entries WHERE chama_id = ?
+		GROUP BY asset_id, entry_type
+	`, chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total portfolio entries: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make(map[string]portfolioEntryTotals)
+	for rows.Next() {
+		var assetID string
+		var entryType models.PortfolioEntryType
+		var amount float64
+		if err := rows.Scan(&assetID, &entryType, &amount); err != nil {
+			return nil, fmt.Errorf("failed to scan portfolio entry totals: %w", err)
+		}
+		total := totals[assetID]
+		if entryType == models.PortfolioEntryIncome {
+			total.income = amount
+		} else {
+			total.expenses = amount
+		}
+		totals[assetID] = total
+	}
+	return totals, nil
+}
+
+// applyTotals fills in an asset's income, expenses and gain. A disposed asset's
+// gain is what it was sold for over what it cost.
+func (s *PortfolioService) applyTotals(asset *models.PortfolioAsset, totals map[string]portfolioEntryTotals) {
+	asset.TotalIncome = roundCurrency(totals[asset.ID].income)
+	asset.TotalExpenses = roundCurrency(totals[asset.ID].expenses)
+	value := asset.CurrentValue
+	if asset.Status == models.PortfolioAssetDisposed && asset.DisposalAmount != nil {
+		value = *asset.DisposalAmount
+	}
+	asset.UnrealizedGain = roundCurrency(value - asset.AcquisitionCost)
+}
+
+func (s *PortfolioService) navHistory(chamaID string, from, to time.Time) ([]models.NAVSnapshot, error) {
+	rows, err := s.db.Query(`
+		SELECT id, chama_id, cash_balance, loan_book, portfolio_value, other_assets, liabilities,
+			net_asset_value, shares_outstanding, nav_per_share, notes, recorded_by, recorded_at
+		FROM chama_nav_history WHERE chama_id = ?
+	`, chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NAV history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []models.NAVSnapshot{}
+	for rows.Next() {
+		var snapshot models.NAVSnapshot
+		var notes sql.NullString
+		err := rows.Scan(&snapshot.ID, &snapshot.ChamaID, &snapshot.CashBalance, &snapshot.LoanBook, &snapshot.PortfolioValue,
+			&snapshot.OtherAssets, &snapshot.Liabilities, &snapshot.NetAssetValue, &snapshot.SharesOutstanding,
+			&snapshot.NAVPerShare, &notes, &snapshot.RecordedBy, &snapshot.RecordedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan NAV snapshot: %w", err)
+		}
+		if snapshot.RecordedAt.Before(from) || !snapshot.RecordedAt.Before(to) {
+			continue
+		}
+		if notes.Valid {
+			snapshot.Notes = &notes.String
+		}
+		history = append(history, snapshot)
+	}
+
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].RecordedAt.Before(history[j].RecordedAt)
+	})
+	return history, nil
+}
+
+func newNAVSnapshot(valuation *models.ShareValuation, userID string, notes *string) *models.NAVSnapshot {
+	return &models.NAVSnapshot{
+		ID:                uuid.New().String(),
+		ChamaID:           valuation.ChamaID,
+		CashBalance:       valuation.CashBalance,
+		LoanBook:          valuation.LoanBook,
+		PortfolioValue:    valuation.PortfolioValue,
+		OtherAssets:       valuation.OtherAssets,
+		Liabilities:       valuation.Liabilities,
+		NetAssetValue:     valuation.NetAssetValue,
+		SharesOutstanding: valuation.SharesOutstanding,
+		NAVPerShare:       valuation.NAVPerShare,
+		Notes:             notes,
+		RecordedBy:        userID,
+		RecordedAt:        time.Now(),
+	}
+}
+
+func insertNAVSnapshot(exec interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}, snapshot *models.NAVSnapshot) error {
+	_, err := exec.Exec(`
+		INSERT INTO chama_nav_history (
+			id, chama_id, cash_balance, loan_book, portfolio_value, other_assets, liabilities,
+			net_asset_value, shares_outstanding, nav_per_share, notes, recorded_by, recorded_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, snapshot.ID, snapshot.ChamaID, snapshot.CashBalance, snapshot.LoanBook, snapshot.PortfolioValue,
+		snapshot.OtherAssets, snapshot.Liabilities, snapshot.NetAssetValue, snapshot.SharesOutstanding,
+		snapshot.NAVPerShare, snapshot.Notes, snapshot.RecordedBy, snapshot.RecordedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record NAV snapshot: %w", err)
+	}
+	return nil
+}
+
+// postChamaWalletTransaction moves money into (a positive amount) or out of (a
+// negative amount) a chama's wallet and records it as a completed chama transaction.
+// counterpartyWalletID is the other side of the movement when it is a wallet the
+// caller has already debited or credited, such as a member being reimbursed.
+func postChamaWalletTransaction(tx *sql.Tx, chamaID, userID string, txType models.TransactionType, amount float64, counterpartyWalletID *string, description string, metadata map[string]interface{}) (string, error) {
+	now := time.Now()
+	var walletID string
+	if amount < 0 {
+		var balance float64
+		err := tx.QueryRow("SELECT id, balance FROM wallets WHERE owner_id = ? AND type = 'chama'", chamaID).Scan(&walletID, &balance)
+		if err != nil && err != sql.ErrNoRows {
+			return "", fmt.Errorf("failed to get chama wallet: %w", err)
+		}
+		if err == sql.ErrNoRows || balance < -amount {
+			return "", fmt.Errorf("the chama wallet does not have enough funds")
+		}
+		if _, err := tx.Exec("UPDATE wallets SET balance = balance + ?, updated_at = ? WHERE id = ?", amount, now, walletID); err != nil {
+			return "", fmt.Errorf("failed to update chama wallet: %w", err)
+		}
+		if err := syncChamaFunds(tx, chamaID); err != nil {
+			return "", err
+		}
+	} else {
+		var err error
+		walletID, err = creditChamaWallet(tx, chamaID, amount)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	fromWalletID, toWalletID := counterpartyWalletID, &walletID
+	if amount < 0 {
+		fromWalletID, toWalletID = &walletID, counterpartyWalletID
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["chamaId"] = chamaID
+	encoded, _ := json.Marshal(metadata)
+
+	transactionID := uuid.New().String()
+	_, err := tx.Exec(`
+		INSERT INTO transactions (
+			id, from_wallet_id, to_wallet_id, type, amount, currency, description, status, payment_method,
+			initiated_by, recipient_id, metadata, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, 'KES', ?, 'completed', 'wallet', ?, ?, ?, ?, ?)
+	`, transactionID, fromWalletID, toWalletID, txType, roundCurrency(math.Abs(amount)), description, userID, chamaID,
+		string(encoded), now, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to record chama transaction: %w", err)
+	}
+
+	if err := NewAuditService(nil).RecordTransaction(tx, chamaID, transactionID); err != nil {
+		return "", err
+	}
+	return transactionID, nil
+}
+
+func (s *PortfolioService) isMember(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow("SELECT 1 FROM chama_members WHERE user_id = ? AND chama_id = ? AND is_active = TRUE", userID, chamaID).Scan(&exists)
+	return err == nil
+}
+
+func (s *PortfolioService) isOfficial(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM chama_members
+		WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+		AND role IN ('chairperson', 'secretary', 'treasurer')
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
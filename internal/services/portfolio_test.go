@@ -0,0 +1,253 @@
+package services_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type PortfolioTestSuite struct {
+	suite.Suite
+	testDB   *helpers.TestDatabase
+	db       *sql.DB
+	service  *services.PortfolioService
+	chairID  string
+	memberID string
+	chamaID  string
+}
+
+func (suite *PortfolioTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewPortfolioService(suite.db)
+
+	suite.chairID = suite.testDB.AddTestUser(suite.T(), "Chair")
+	suite.memberID = suite.testDB.AddTestUser(suite.T(), "Member")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.chairID, "treasurer")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.memberID, "member")
+}
+
+func (suite *PortfolioTestSuite) seedWallet(ownerID, walletType string, balance float64) {
+	_, err := suite.db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, ?, ?, ?)", uuid.New().String(), walletType, ownerID, balance)
+	suite.Require().NoError(err)
+}
+
+func (suite *PortfolioTestSuite) balance() float64 {
+	var balance float64
+	suite.Require().NoError(suite.db.QueryRow("SELECT balance FROM wallets WHERE owner_id = ? AND type = 'chama'", suite.chamaID).Scan(&balance))
+	return balance
+}
+
+func (suite *PortfolioTestSuite) seedShares(memberID string, owned int) {
+	_, err := suite.db.Exec(`
+		INSERT INTO shares (id, chama_id, member_id, name, share_type, shares_owned, share_value, total_value, purchase_date, status)
+		VALUES (?, ?, ?, 'Ordinary', 'ordinary', ?, 100, ?, ?, 'active')
+	`, uuid.New().String(), suite.chamaID, memberID, owned, float64(owned)*100, time.Now())
+	suite.Require().NoError(err)
+}
+
+func (suite *PortfolioTestSuite) TestAssetLifecycleThroughTheWallet() {
+	suite.seedWallet(suite.chamaID, "chama", 150000)
+	bought := time.Now().AddDate(0, -6, 0)
+
+	request := &models.CreatePortfolioAssetRequest{
+		Name:            "91-day T-bill",
+		AssetType:       string(models.PortfolioAssetTreasuryBill),
+		AcquisitionDate: bought,
+		AcquisitionCost: 100000,
+		PayFromWallet:   true,
+	}
+	_, err := suite.service.CreateAsset(suite.chamaID, suite.memberID, request)
+	suite.Error(err, "only officials record investments")
+
+	asset, err := suite.service.CreateAsset(suite.chamaID, suite.chairID, request)
+	suite.Require().NoError(err)
+	suite.Equal(100000.0, asset.CurrentValue, "valued at cost")
+	suite.Equal(50000.0, suite.balance())
+
+	request.AcquisitionCost = 60000
+	_, err = suite.service.CreateAsset(suite.chamaID, suite.chairID, request)
+	suite.Error(err, "the wallet cannot go negative")
+
+	// Interest is paid into the wallet; a custody fee is recorded without moving money
+	income, err := suite.service.RecordEntry(suite.chamaID, asset.ID, suite.chairID, &models.RecordPortfolioEntryRequest{
+		EntryType: string(models.PortfolioEntryIncome), Category: "interest", Amount: 2500, EntryDate: bought.AddDate(0, 3, 0), ThroughWallet: true,
+	})
+	suite.Require().NoError(err)
+	suite.NotNil(income.TransactionID)
+	suite.Equal(52500.0, suite.balance())
+	_, err = suite.service.RecordEntry(suite.chamaID, asset.ID, suite.chairID, &models.RecordPortfolioEntryRequest{
+		EntryType: string(models.PortfolioEntryExpense), Category: "custody fee", Amount: 300, EntryDate: bought.AddDate(0, 3, 0),
+	})
+	suite.Require().NoError(err)
+
+	// A later valuation becomes current; a backdated one only joins the history
+	updated, err := suite.service.RecordValuation(suite.chamaID, asset.ID, suite.chairID, &models.RecordPortfolioValuationRequest{
+		Value: 103000, ValuationDate: bought.AddDate(0, 4, 0), Source: "market",
+	})
+	suite.Require().NoError(err)
+	suite.Equal(103000.0, updated.CurrentValue)
+	updated, err = suite.service.RecordValuation(suite.chamaID, asset.ID, suite.chairID, &models.RecordPortfolioValuationRequest{
+		Value: 101000, ValuationDate: bought.AddDate(0, 2, 0), Source: "market",
+	})
+	suite.Require().NoError(err)
+	suite.Equal(103000.0, updated.CurrentValue)
+	suite.Equal(3000.0, updated.UnrealizedGain)
+	suite.Equal(2500.0, updated.TotalIncome)
+	suite.Equal(300.0, updated.TotalExpenses)
+
+	detail, err := suite.service.GetAsset(suite.chamaID, asset.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Require().Len(detail.Valuations, 3)
+	suite.Equal(models.PortfolioValuationCost, detail.Valuations[0].Source)
+	suite.Equal(103000.0, detail.Valuations[2].Value)
+	suite.Len(detail.Entries, 2)
+
+	disposed, err := suite.service.DisposeAsset(suite.chamaID, asset.ID, suite.chairID, &models.DisposePortfolioAssetRequest{
+		Amount: 104000, DisposalDate: time.Now(), CreditWallet: true,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(models.PortfolioAssetDisposed, disposed.Status)
+	suite.Equal(4000.0, disposed.UnrealizedGain)
+	suite.Equal(156500.0, suite.balance())
+
+	_, err = suite.service.DisposeAsset(suite.chamaID, asset.ID, suite.chairID, &models.DisposePortfolioAssetRequest{Amount: 1, DisposalDate: time.Now()})
+	suite.Error(err, "an asset is disposed of once")
+	_, err = suite.service.RecordValuation(suite.chamaID, asset.ID, suite.chairID, &models.RecordPortfolioValuationRequest{
+		Value: 1, ValuationDate: time.Now(), Source: "market",
+	})
+	suite.Error(err)
+
+	active, err := suite.service.GetAssets(suite.chamaID, suite.memberID, "active")
+	suite.Require().NoError(err)
+	suite.Empty(active)
+
+	var movements int
+	suite.Require().NoError(suite.db.QueryRow(`
+		SELECT COUNT(*) FROM transactions WHERE recipient_id = ? AND type IN ('investment', 'investment_income', 'investment_sale')
+	`, suite.chamaID).Scan(&movements))
+	suite.Equal(3, movements)
+}
+
+func (suite *PortfolioTestSuite) TestNetAssetValueIncludesThePortfolio() {
+	suite.seedWallet(suite.chamaID, "chama", 10000)
+	suite.seedShares(suite.memberID, 100)
+
+	current := 25000.0
+	_, err := suite.service.CreateAsset(suite.chamaID, suite.chairID, &models.CreatePortfolioAssetRequest{
+		Name:            "Plot in Kitengela",
+		AssetType:       string(models.PortfolioAssetLand),
+		AcquisitionDate: time.Now().AddDate(-3, 0, 0),
+		AcquisitionCost: 15000,
+		CurrentValue:    &current,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(10000.0, suite.balance(), "an asset already owned does not touch the wallet")
+
+	snapshot, err := suite.service.RecordNAVSnapshot(suite.chamaID, suite.chairID, &models.RecordNAVSnapshotRequest{})
+	suite.Require().NoError(err)
+	suite.Equal(25000.0, snapshot.PortfolioValue)
+	suite.Equal(35000.0, snapshot.NetAssetValue)
+	suite.Equal(350.0, snapshot.NAVPerShare)
+
+	_, err = suite.service.RecordNAVSnapshot(suite.chamaID, suite.memberID, &models.RecordNAVSnapshotRequest{})
+	suite.Error(err)
+
+	// Pricing shares from NAV values the portfolio too and adds to the history
+	price, err := services.NewShareMarketService(suite.db).SetSharePrice(suite.chamaID, suite.chairID, &models.SetSharePriceRequest{
+		Source: models.SharePriceSourceNAV,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(350.0, price.Price)
+
+	history, err := suite.service.GetNAVHistory(suite.chamaID, suite.memberID, time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 0, 1))
+	suite.Require().NoError(err)
+	suite.Len(history, 2)
+}
+
+func (suite *PortfolioTestSuite) TestPerformanceReport() {
+	suite.seedWallet(suite.chamaID, "chama", 0)
+	from := time.Now().AddDate(0, -6, 0)
+	to := time.Now().AddDate(0, 0, 1)
+
+	// Held since before the period: measured from its value at the start
+	land, err := suite.service.CreateAsset(suite.chamaID, suite.chairID, &models.CreatePortfolioAssetRequest{
+		Name: "Plot", AssetType: string(models.PortfolioAssetLand), AcquisitionDate: from.AddDate(-2, 0, 0), AcquisitionCost: 40000,
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.RecordValuation(suite.chamaID, land.ID, suite.chairID, &models.RecordPortfolioValuationRequest{
+		Value: 50000, ValuationDate: from.AddDate(0, -1, 0), Source: "appraisal",
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.RecordValuation(suite.chamaID, land.ID, suite.chairID, &models.RecordPortfolioValuationRequest{
+		Value: 55000, ValuationDate: from.AddDate(0, 2, 0), Source: "appraisal",
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.RecordEntry(suite.chamaID, land.ID, suite.chairID, &models.RecordPortfolioEntryRequest{
+		EntryType: "expense", Category: "land rates", Amount: 1000, EntryDate: from.AddDate(0, 1, 0),
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.RecordEntry(suite.chamaID, land.ID, suite.chairID, &models.RecordPortfolioEntryRequest{
+		EntryType: "expense", Category: "land rates", Amount: 1000, EntryDate: from.AddDate(0, -2, 0),
+	})
+	suite.Require().NoError(err)
+
+	// Bought during the period: measured from cost
+	fund, err := suite.service.CreateAsset(suite.chamaID, suite.chairID, &models.CreatePortfolioAssetRequest{
+		Name: "Money market", AssetType: string(models.PortfolioAssetMoneyMarketFund), AcquisitionDate: from.AddDate(0, 1, 0), AcquisitionCost: 50000,
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.RecordEntry(suite.chamaID, fund.ID, suite.chairID, &models.RecordPortfolioEntryRequest{
+		EntryType: "income", Category: "interest", Amount: 2000, EntryDate: from.AddDate(0, 3, 0), ThroughWallet: true,
+	})
+	suite.Require().NoError(err)
+
+	// Sold before the period: left out
+	old, err := suite.service.CreateAsset(suite.chamaID, suite.chairID, &models.CreatePortfolioAssetRequest{
+		Name: "Old bond", AssetType: string(models.PortfolioAssetTreasuryBill), AcquisitionDate: from.AddDate(-1, 0, 0), AcquisitionCost: 10000,
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.DisposeAsset(suite.chamaID, old.ID, suite.chairID, &models.DisposePortfolioAssetRequest{
+		Amount: 11000, DisposalDate: from.AddDate(0, -1, 0),
+	})
+	suite.Require().NoError(err)
+
+	_, err = suite.service.GetPerformanceReport(suite.chamaID, uuid.New().String(), from, to)
+	suite.Error(err)
+
+	report, err := suite.service.GetPerformanceReport(suite.chamaID, suite.memberID, from, to)
+	suite.Require().NoError(err)
+	suite.Require().Len(report.Assets, 2)
+
+	byID := map[string]models.PortfolioAssetPerformance{}
+	for _, asset := range report.Assets {
+		byID[asset.AssetID] = asset
+	}
+	suite.Equal(50000.0, byID[land.ID].StartBasis)
+	suite.Equal(55000.0, byID[land.ID].EndValue)
+	suite.Equal(1000.0, byID[land.ID].Expenses, "only expenses within the period count")
+	suite.Equal(4000.0, byID[land.ID].TotalReturn)
+	suite.Equal(8.0, byID[land.ID].ReturnPercent)
+
+	suite.Equal(50000.0, byID[fund.ID].StartBasis)
+	suite.Equal(2000.0, byID[fund.ID].TotalReturn)
+	suite.Equal(4.0, byID[fund.ID].ReturnPercent)
+
+	suite.Equal(6000.0, report.TotalReturn)
+	suite.Equal(6.0, report.ReturnPercent)
+	suite.Require().Len(report.Allocation, 2)
+	suite.Equal(models.PortfolioAssetLand, report.Allocation[0].AssetType)
+	suite.InDelta(52.38, report.Allocation[0].Percent, 0.001)
+}
+
+func TestPortfolioSuite(t *testing.T) {
+	suite.Run(t, new(PortfolioTestSuite))
+}
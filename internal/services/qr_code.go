@@ -0,0 +1,416 @@
+package services
+
+import (
+	"fmt"
+)
+
+// qrVersion describes the error-correction block layout of one QR code version at
+// error-correction level M, which recovers from about 15% damage
+type qrVersion struct {
+	totalCodewords int
+	ecPerBlock     int
+	blocks         []int // data codewords in each block
+	alignment      []int // alignment pattern centre coordinates
+}
+
+// qrVersionsM covers versions 1 to 13, enough for URLs of up to 330 bytes
+var qrVersionsM = []qrVersion{
+	{26, 10, []int{16}, nil},
+	{44, 16, []int{28}, []int{6, 18}},
+	{70, 26, []int{44}, []int{6, 22}},
+	{100, 18, []int{32, 32}, []int{6, 26}},
+	{134, 24, []int{43, 43}, []int{6, 30}},
+	{172, 16, []int{27, 27, 27, 27}, []int{6, 34}},
+	{196, 18, []int{31, 31, 31, 31}, []int{6, 22, 38}},
+	{242, 22, []int{38, 38, 39, 39}, []int{6, 24, 42}},
+	{292, 22, []int{36, 36, 36, 37, 37}, []int{6, 26, 46}},
+	{346, 26, []int{43, 43, 43, 43, 44}, []int{6, 28, 50}},
+	{404, 30, []int{50, 51, 51, 51, 51}, []int{6, 30, 54}},
+	{466, 22, []int{36, 36, 36, 36, 36, 36, 37, 37}, []int{6, 32, 58}},
+	{532, 22, []int{37, 37, 37, 37, 37, 37, 37, 37, 38}, []int{6, 34, 62}},
+}
+
+// qrCode is an encoded QR symbol; Modules[y][x] is true for dark modules
+type qrCode struct {
+	Size    int
+	Modules [][]bool
+
+	function [][]bool
+}
+
+// encodeQRCode encodes text in byte mode using the smallest version that fits
+func encodeQRCode(text string) (*qrCode, error) {
+	data := []byte(text)
+	for index, version := range qrVersionsM {
+		number := index + 1
+		countBits := 8
+		if number >= 10 {
+			countBits = 16
+		}
+		capacity := 0
+		for _, block := range version.blocks {
+			capacity += block
+		}
+		if 4+countBits+len(data)*8 > capacity*8 {
+			continue
+		}
+
+		codewords := qrDataCodewords(data, countBits, capacity)
+		qr := newQRCode(number, version)
+		qr.drawCodewords(qrInterleave(codewords, version))
+		qr.applyBestMask()
+		return qr, nil
+	}
+	return nil, fmt.Errorf("text is too long for a QR code: %d bytes", len(data))
+}
+
+// qrDataCodewords builds the byte-mode bit stream and pads it to capacity
+func qrDataCodewords(data []byte, countBits, capacity int) []byte {
+	var bits []bool
+	appendBits := func(value, length int) {
+		for i := length - 1; i >= 0; i-- {
+			bits = append(bits, (value>>i)&1 == 1)
+		}
+	}
+	appendBits(0x4, 4)
+	appendBits(len(data), countBits)
+	for _, b := range data {
+		appendBits(int(b), 8)
+	}
+
+	terminator := capacity*8 - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	appendBits(0, terminator)
+	if remainder := len(bits) % 8; remainder != 0 {
+		appendBits(0, 8-remainder)
+	}
+
+	codewords := make([]byte, 0, capacity)
+	for i := 0; i < len(bits); i += 8 {
+		var b byte
+		for j := 0; j < 8; j++ {
+			if bits[i+j] {
+				b |= 1 << (7 - j)
+			}
+		}
+		codewords = append(codewords, b)
+	}
+	for pad := byte(0xEC); len(codewords) < capacity; pad ^= 0xEC ^ 0x11 {
+		codewords = append(codewords, pad)
+	}
+	return codewords
+}
+
+// qrInterleave splits the data into blocks, adds Reed-Solomon error correction to
+// each and interleaves the result
+func qrInterleave(data []byte, version qrVersion) []byte {
+	divisor := qrReedSolomonDivisor(version.ecPerBlock)
+	var dataBlocks, ecBlocks [][]byte
+	offset := 0
+	for _, length := range version.blocks {
+		block := data[offset : offset+length]
+		offset += length
+		dataBlocks = append(dataBlocks, block)
+		ecBlocks = append(ecBlocks, qrReedSolomonRemainder(block, divisor))
+	}
+
+	result := make([]byte, 0, version.totalCodewords)
+	longest := version.blocks[len(version.blocks)-1]
+	for i := 0; i < longest; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < version.ecPerBlock; i++ {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// qrReedSolomonDivisor returns the generator polynomial of the given degree,
+// highest coefficient first with the leading 1 dropped
+func qrReedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = qrMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = qrMultiply(root, 0x02)
+	}
+	return result
+}
+
+func qrReedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range divisor {
+			result[i] ^= qrMultiply(coefficient, factor)
+		}
+	}
+	return result
+}
+
+// qrMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func qrMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+func newQRCode(number int, version qrVersion) *qrCode {
+	size := number*4 + 17
+	qr := &qrCode{Size: size}
+	qr.Modules = make([][]bool, size)
+	qr.function = make([][]bool, size)
+	for y := range qr.Modules {
+		qr.Modules[y] = make([]bool, size)
+		qr.function[y] = make([]bool, size)
+	}
+
+	for i := 0; i < size; i++ {
+		qr.setFunction(6, i, i%2 == 0)
+		qr.setFunction(i, 6, i%2 == 0)
+	}
+	qr.drawFinder(3, 3)
+	qr.drawFinder(size-4, 3)
+	qr.drawFinder(3, size-4)
+
+	last := len(version.alignment) - 1
+	for i, x := range version.alignment {
+		for j, y := range version.alignment {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					qr.setFunction(x+dx, y+dy, qrMax(qrAbs(dx), qrAbs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	qr.drawFormat(0) // reserves the format areas until the mask is chosen
+	if number >= 7 {
+		remainder := number
+		for i := 0; i < 12; i++ {
+			remainder = (remainder << 1) ^ ((remainder >> 11) * 0x1F25)
+		}
+		bits := number<<12 | remainder
+		for i := 0; i < 18; i++ {
+			dark := (bits>>i)&1 == 1
+			a, b := size-11+i%3, i/3
+			qr.setFunction(a, b, dark)
+			qr.setFunction(b, a, dark)
+		}
+	}
+	return qr
+}
+
+func (qr *qrCode) setFunction(x, y int, dark bool) {
+	qr.Modules[y][x] = dark
+	qr.function[y][x] = true
+}
+
+func (qr *qrCode) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= qr.Size || yy < 0 || yy >= qr.Size {
+				continue
+			}
+			distance := qrMax(qrAbs(dx), qrAbs(dy))
+			qr.setFunction(xx, yy, distance != 2 && distance != 4)
+		}
+	}
+}
+
+// drawFormat writes the error-correction level (M) and mask into both format areas
+func (qr *qrCode) drawFormat(mask int) {
+	data := mask // level M is 00
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	bits := (data<<10 | remainder) ^ 0x5412
+	bit := func(i int) bool { return (bits>>i)&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		qr.setFunction(8, i, bit(i))
+	}
+	qr.setFunction(8, 7, bit(6))
+	qr.setFunction(8, 8, bit(7))
+	qr.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		qr.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		qr.setFunction(qr.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		qr.setFunction(8, qr.Size-15+i, bit(i))
+	}
+	qr.setFunction(8, qr.Size-8, true)
+}
+
+// drawCodewords places the codewords in the zigzag order of the specification
+func (qr *qrCode) drawCodewords(codewords []byte) {
+	i := 0
+	for right := qr.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vertical := 0; vertical < qr.Size; vertical++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vertical
+				if (right+1)&2 == 0 {
+					y = qr.Size - 1 - vertical
+				}
+				if !qr.function[y][x] && i < len(codewords)*8 {
+					qr.Modules[y][x] = (codewords[i>>3]>>(7-i&7))&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (qr *qrCode) applyMask(mask int) {
+	for y := 0; y < qr.Size; y++ {
+		for x := 0; x < qr.Size; x++ {
+			if qr.function[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				qr.Modules[y][x] = !qr.Modules[y][x]
+			}
+		}
+	}
+}
+
+// applyBestMask tries all eight masks and keeps the one with the lowest penalty
+func (qr *qrCode) applyBestMask() {
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		qr.applyMask(mask)
+		qr.drawFormat(mask)
+		if penalty := qr.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		qr.applyMask(mask) // masks are their own inverse
+	}
+	qr.applyMask(best)
+	qr.drawFormat(best)
+}
+
+// penalty scores how hard the symbol is to scan: long runs, 2x2 blocks, finder-like
+// patterns and an uneven dark/light balance all count against it
+func (qr *qrCode) penalty() int {
+	penalty := 0
+	at := func(x, y int, transpose bool) bool {
+		if transpose {
+			return qr.Modules[x][y]
+		}
+		return qr.Modules[y][x]
+	}
+
+	for _, transpose := range []bool{false, true} {
+		for y := 0; y < qr.Size; y++ {
+			run := 1
+			for x := 1; x < qr.Size; x++ {
+				if at(x, y, transpose) == at(x-1, y, transpose) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					penalty += run - 2
+				}
+				run = 1
+			}
+			if run >= 5 {
+				penalty += run - 2
+			}
+
+			for x := 0; x+10 < qr.Size; x++ {
+				pattern := [11]bool{}
+				for k := range pattern {
+					pattern[k] = at(x+k, y, transpose)
+				}
+				if pattern == [11]bool{true, false, true, true, true, false, true, false, false, false, false} ||
+					pattern == [11]bool{false, false, false, false, true, false, true, true, true, false, true} {
+					penalty += 40
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := 0; y < qr.Size; y++ {
+		for x := 0; x < qr.Size; x++ {
+			if qr.Modules[y][x] {
+				dark++
+			}
+			if x+1 < qr.Size && y+1 < qr.Size {
+				c := qr.Modules[y][x]
+				if c == qr.Modules[y][x+1] && c == qr.Modules[y+1][x] && c == qr.Modules[y+1][x+1] {
+					penalty += 3
+				}
+			}
+		}
+	}
+	total := qr.Size * qr.Size
+	deviation := qrAbs(dark*20 - total*10)
+	penalty += (deviation + total - 1) / total * 10
+	return penalty
+}
+
+func qrAbs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func qrMax(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
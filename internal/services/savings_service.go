@@ -0,0 +1,1200 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/utils"
+)
+
+// defaultEarlyWithdrawalPenaltyRate is the percent of principal forfeited when fixed
+// savings held outside a chama are broken before maturity
+var defaultEarlyWithdrawalPenaltyRate = parsePenaltyRate(getEnvOrDefault("FIXED_SAVINGS_EARLY_WITHDRAWAL_PENALTY_RATE", "5"))
+
+const maxSavingsAmount = 10000000
+
+func parsePenaltyRate(value string) float64 {
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate < 0 || rate > 100 {
+		log.Printf("Invalid FIXED_SAVINGS_EARLY_WITHDRAWAL_PENALTY_RATE %q, using 5", value)
+		return 5
+	}
+	return rate
+}
+
+// SavingsService handles savings goals and fixed-term locked savings
+type SavingsService struct {
+	db *sql.DB
+}
+
+// NewSavingsService creates a new savings service
+func NewSavingsService(db *sql.DB) *SavingsService {
+	return &SavingsService{db: db}
+}
+
+// CreateGoal opens a savings goal with its own wallet, optionally funding it straight away
+func (s *SavingsService) CreateGoal(userID string, req *models.CreateSavingsGoalRequest) (*models.SavingsGoal, error) {
+	if req.TargetAmount > maxSavingsAmount {
+		return nil, fmt.Errorf("savings targets cannot exceed KES %d", maxSavingsAmount)
+	}
+
+	now := time.Now()
+	var targetDate *time.Time
+	if req.TargetDate != "" {
+		date, err := time.ParseInLocation("2006-01-02", req.TargetDate, utils.EATLocation)
+		if err != nil {
+			return nil, fmt.Errorf("targetDate must be in YYYY-MM-DD format")
+		}
+		if !date.After(now) {
+			return nil, fmt.Errorf("targetDate must be in the future")
+		}
+		targetDate = &date
+	}
+
+	var evaluation *models.FraudEvaluation
+	if req.InitialDeposit != nil {
+		var err error
+		if evaluation, err = s.screenDeposit(userID, roundCurrency(*req.InitialDeposit)); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	walletID, err := s.createSubWallet(tx, userID, models.WalletTypeSavingsGoal, false)
+	if err != nil {
+		return nil, err
+	}
+
+	goalID := uuid.New().String()
+	_, err = tx.Exec(`
+		INSERT INTO savings_goals (id, wallet_id, user_id, name, target_amount, target_date, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, goalID, walletID, userID, req.Name, roundCurrency(req.TargetAmount), targetDate, models.SavingsGoalStatusActive, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create savings goal: %w", err)
+	}
+
+	if req.InitialDeposit != nil {
+		goal, err := s.getGoal(tx, goalID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.fundGoal(tx, goal, roundCurrency(*req.InitialDeposit), "Savings goal deposit", evaluation); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.GetGoal(goalID, userID)
+}
+
+// GetGoals lists a user's savings goals, optionally filtered by status
+func (s *SavingsService) GetGoals(userID, status string) ([]models.SavingsGoal, error) {
+	if status != "" {
+		return s.queryGoals(s.db, "WHERE g.user_id = ? AND g.status = ? ORDER BY g.created_at DESC", userID, status)
+	}
+	return s.queryGoals(s.db, "WHERE g.user_id = ? ORDER BY g.created_at DESC", userID)
+}
+
+// GetGoal returns one of the user's savings goals with its progress
+func (s *SavingsService) GetGoal(goalID, userID string) (*models.SavingsGoal, error) {
+	goal, err := s.getGoal(s.db, goalID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.UserID != userID {
+		return nil, fmt.Errorf("savings goal not found")
+	}
+	return goal, nil
+}
+
+// DepositToGoal moves money from the user's personal wallet into a goal
+func (s *SavingsService) DepositToGoal(goalID, userID string, amount float64) (*models.SavingsGoal, error) {
+	goal, err := s.GetGoal(goalID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.Status == models.SavingsGoalStatusClosed {
+		return nil, fmt.Errorf("savings goal is closed")
+	}
+	amount = roundCurrency(amount)
+	evaluation, err := s.screenDeposit(userID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	achieved, err := s.fundGoal(tx, goal, amount, "Savings goal deposit", evaluation)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	invalidateMemberAnalyticsForUser(s.db, userID)
+
+	if achieved {
+		s.notifyGoalAchieved(goal)
+	}
+	return s.GetGoal(goalID, userID)
+}
+
+// WithdrawFromGoal moves money from a goal back to the user's personal wallet. Goals
+// are ring-fenced from everyday spending but not locked.
+func (s *SavingsService) WithdrawFromGoal(goalID, userID string, amount float64) (*models.SavingsGoal, error) {
+	goal, err := s.GetGoal(goalID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.Status == models.SavingsGoalStatusClosed {
+		return nil, fmt.Errorf("savings goal is closed")
+	}
+	amount = roundCurrency(amount)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.debitSubWallet(tx, goal.WalletID, amount); err != nil {
+		return nil, err
+	}
+	toWalletID, err := creditPersonalWallet(tx, userID, amount)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.recordTransfer(tx, "", &goal.WalletID, &toWalletID, models.TransactionTypeTransfer, amount,
+		"Savings goal withdrawal", goal.ID, userID, userID, map[string]interface{}{"savingsGoalId": goal.ID}); err != nil {
+		return nil, err
+	}
+	if _, err := s.updateGoalStatus(tx, goal, goal.Balance-amount); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.GetGoal(goalID, userID)
+}
+
+// SetSweepRule sets or clears the rule that moves money into a goal automatically
+func (s *SavingsService) SetSweepRule(goalID, userID string, req *models.SetSavingsSweepRequest) (*models.SavingsGoal, error) {
+	goal, err := s.GetGoal(goalID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.Status == models.SavingsGoalStatusClosed {
+		return nil, fmt.Errorf("savings goal is closed")
+	}
+
+	now := time.Now()
+	if req.SweepType == "none" {
+		_, err = s.db.Exec(`
+			UPDATE savings_goals
+			SET sweep_type = NULL, sweep_amount = NULL, sweep_frequency = NULL, sweep_day = NULL, next_sweep_at = NULL, updated_at = ?
+			WHERE id = ?
+		`, now, goalID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to clear sweep rule: %w", err)
+		}
+		return s.GetGoal(goalID, userID)
+	}
+
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("amount is required for a sweep rule")
+	}
+	frequency := req.Frequency
+	if frequency == "" {
+		frequency = string(models.StandingOrderFrequencyMonthly)
+	}
+	var day int
+	if frequency == string(models.StandingOrderFrequencyWeekly) {
+		day = int(now.Weekday())
+		if req.Day != nil {
+			if *req.Day > 6 {
+				return nil, fmt.Errorf("day must be between 0 (Sunday) and 6 for weekly sweeps")
+			}
+			day = *req.Day
+		}
+	} else {
+		day = now.Day()
+		if req.Day != nil {
+			if *req.Day < 1 {
+				return nil, fmt.Errorf("day must be between 1 and 31 for monthly sweeps")
+			}
+			day = *req.Day
+		}
+	}
+	nextSweepAt := nextRecurringRun(frequency, day, now)
+
+	_, err = s.db.Exec(`
+		UPDATE savings_goals
+		SET sweep_type = ?, sweep_amount = ?, sweep_frequency = ?, sweep_day = ?, next_sweep_at = ?, updated_at = ?
+		WHERE id = ?
+	`, req.SweepType, roundCurrency(req.Amount), frequency, day, nextSweepAt, now, goalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set sweep rule: %w", err)
+	}
+
+	return s.GetGoal(goalID, userID)
+}
+
+// CloseGoal returns a goal's balance to the personal wallet and closes it
+func (s *SavingsService) CloseGoal(goalID, userID string) (*models.SavingsGoal, error) {
+	goal, err := s.GetGoal(goalID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE savings_goals SET status = ?, next_sweep_at = NULL, closed_at = ?, updated_at = ?
+		WHERE id = ? AND status != ?
+	`, models.SavingsGoalStatusClosed, now, now, goalID, models.SavingsGoalStatusClosed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close savings goal: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("savings goal is already closed")
+	}
+
+	if _, err := s.emptySubWallet(tx, goal.WalletID, userID, "Savings goal closed", goal.ID, "", map[string]interface{}{"savingsGoalId": goal.ID}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.GetGoal(goalID, userID)
+}
+
+// GetFixedSavingsRules returns a chama's fixed savings terms
+func (s *SavingsService) GetFixedSavingsRules(chamaID, userID string) (*models.FixedSavingsRules, error) {
+	if !s.isMember(s.db, userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	return s.getRules(chamaID)
+}
+
+// UpdateFixedSavingsRules changes the interest, penalty and terms a chama offers
+func (s *SavingsService) UpdateFixedSavingsRules(chamaID, userID string, req *models.UpdateFixedSavingsRulesRequest) (*models.FixedSavingsRules, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can change fixed savings rules")
+	}
+
+	rules, err := s.getRules(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Enabled != nil {
+		rules.Enabled = *req.Enabled
+	}
+	if req.AnnualInterestRate != nil {
+		rules.AnnualInterestRate = *req.AnnualInterestRate
+	}
+	if req.EarlyWithdrawalPenaltyRate != nil {
+		rules.EarlyWithdrawalPenaltyRate = *req.EarlyWithdrawalPenaltyRate
+	}
+	if req.MinTermMonths != nil {
+		rules.MinTermMonths = *req.MinTermMonths
+	}
+	if req.MaxTermMonths != nil {
+		rules.MaxTermMonths = *req.MaxTermMonths
+	}
+	if rules.MinTermMonths > rules.MaxTermMonths {
+		return nil, fmt.Errorf("minTermMonths cannot exceed maxTermMonths")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = tx.Exec(`
+		INSERT INTO fixed_savings_rules (
+			chama_id, enabled, annual_interest_rate, early_withdrawal_penalty_rate,
+			min_term_months, max_term_months, updated_by, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(chama_id) DO UPDATE SET
+			enabled = excluded.enabled,
+			annual_interest_rate = excluded.annual_interest_rate,
+			early_withdrawal_penalty_rate = excluded.early_withdrawal_penalty_rate,
+			min_term_months = excluded.min_term_months,
+			max_term_months = excluded.max_term_months,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`, chamaID, rules.Enabled, rules.AnnualInterestRate, rules.EarlyWithdrawalPenaltyRate,
+		rules.MinTermMonths, rules.MaxTermMonths, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update fixed savings rules: %w", err)
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "fixed_savings_rules",
+		EntityID:   chamaID,
+		Details: map[string]interface{}{
+			"enabled":                    rules.Enabled,
+			"annualInterestRate":         rules.AnnualInterestRate,
+			"earlyWithdrawalPenaltyRate": rules.EarlyWithdrawalPenaltyRate,
+			"minTermMonths":              rules.MinTermMonths,
+			"maxTermMonths":              rules.MaxTermMonths,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	rules.UpdatedBy = &userID
+	rules.UpdatedAt = &now
+	return rules, nil
+}
+
+// CreateFixedSavings locks money from the personal wallet until maturity. Deposits made
+// under a chama take that chama's interest rate and penalty at the time of locking.
+func (s *SavingsService) CreateFixedSavings(userID string, req *models.CreateFixedSavingsRequest) (*models.FixedSavings, error) {
+	if req.Amount > maxSavingsAmount {
+		return nil, fmt.Errorf("fixed savings cannot exceed KES %d", maxSavingsAmount)
+	}
+
+	interestRate := 0.0
+	penaltyRate := defaultEarlyWithdrawalPenaltyRate
+	var chamaID *string
+	if req.ChamaID != "" {
+		if !s.isMember(s.db, userID, req.ChamaID) {
+			return nil, fmt.Errorf("user is not a member of this chama")
+		}
+		rules, err := s.getRules(req.ChamaID)
+		if err != nil {
+			return nil, err
+		}
+		if !rules.Enabled {
+			return nil, fmt.Errorf("this chama does not offer fixed savings")
+		}
+		if req.TermMonths < rules.MinTermMonths || req.TermMonths > rules.MaxTermMonths {
+			return nil, fmt.Errorf("term must be between %d and %d months", rules.MinTermMonths, rules.MaxTermMonths)
+		}
+		interestRate = rules.AnnualInterestRate
+		penaltyRate = rules.EarlyWithdrawalPenaltyRate
+		chamaID = &req.ChamaID
+	}
+
+	name := req.Name
+	if name == "" {
+		name = fmt.Sprintf("%d-month fixed savings", req.TermMonths)
+	}
+	amount := roundCurrency(req.Amount)
+	now := time.Now()
+	maturityDate := now.AddDate(0, req.TermMonths, 0)
+
+	evaluation, err := s.screenDeposit(userID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := reserveTransactionLimit(tx, userID, "", amount); err != nil {
+		return nil, err
+	}
+	fromWalletID, err := debitPersonalWallet(tx, userID, amount)
+	if err != nil {
+		return nil, err
+	}
+	walletID, err := s.createSubWallet(tx, userID, models.WalletTypeFixedSavings, true)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("UPDATE wallets SET balance = ? WHERE id = ?", amount, walletID); err != nil {
+		return nil, fmt.Errorf("failed to fund fixed savings wallet: %w", err)
+	}
+
+	fixedID := uuid.New().String()
+	_, err = tx.Exec(`
+		INSERT INTO fixed_savings (
+			id, wallet_id, user_id, chama_id, name, principal, term_months, interest_rate,
+			early_withdrawal_penalty_rate, start_date, maturity_date, status, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, fixedID, walletID, userID, chamaID, name, amount, req.TermMonths, interestRate, penaltyRate,
+		now, maturityDate, models.FixedSavingsStatusActive, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fixed savings: %w", err)
+	}
+
+	chainID := ""
+	if chamaID != nil {
+		chainID = *chamaID
+	}
+	transactionID, err := s.recordTransfer(tx, chainID, &fromWalletID, &walletID, models.TransactionTypeTransfer, amount,
+		"Fixed savings deposit", fixedID, userID, userID, map[string]interface{}{"fixedSavingsId": fixedID})
+	if err != nil {
+		return nil, err
+	}
+	if err := recordFraudEvaluation(tx, evaluation, transactionID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	invalidateMemberAnalyticsForUser(s.db, userID)
+
+	return s.GetFixedSavingsByID(fixedID, userID)
+}
+
+// GetFixedSavings lists a user's fixed savings, optionally filtered by status
+func (s *SavingsService) GetFixedSavings(userID, status string) ([]models.FixedSavings, error) {
+	if status != "" {
+		return s.queryFixedSavings(s.db, "WHERE user_id = ? AND status = ? ORDER BY created_at DESC", userID, status)
+	}
+	return s.queryFixedSavings(s.db, "WHERE user_id = ? ORDER BY created_at DESC", userID)
+}
+
+// GetFixedSavingsByID returns one of the user's fixed savings
+func (s *SavingsService) GetFixedSavingsByID(fixedID, userID string) (*models.FixedSavings, error) {
+	fixed, err := s.getFixedSavings(s.db, fixedID)
+	if err != nil {
+		return nil, err
+	}
+	if fixed.UserID != userID {
+		return nil, fmt.Errorf("fixed savings not found")
+	}
+	return fixed, nil
+}
+
+// BreakFixedSavings releases fixed savings before maturity, less the early withdrawal
+// penalty. The penalty goes to the chama whose rules the savings were made under.
+func (s *SavingsService) BreakFixedSavings(fixedID, userID string) (*models.FixedSavings, error) {
+	fixed, err := s.GetFixedSavingsByID(fixedID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if fixed.Status != models.FixedSavingsStatusActive {
+		return nil, fmt.Errorf("fixed savings is already %s", fixed.Status)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE fixed_savings SET status = ?, closed_at = ?
+		WHERE id = ? AND status = ? AND julianday(maturity_date) > julianday(?)
+	`, models.FixedSavingsStatusBroken, now, fixedID, models.FixedSavingsStatusActive, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update fixed savings: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("fixed savings has matured or is no longer active")
+	}
+
+	chainID := ""
+	if fixed.ChamaID != nil {
+		chainID = *fixed.ChamaID
+	}
+
+	var balance float64
+	if err := tx.QueryRow("SELECT balance FROM wallets WHERE id = ?", fixed.WalletID).Scan(&balance); err != nil {
+		return nil, fmt.Errorf("failed to get fixed savings balance: %w", err)
+	}
+	penalty := math.Min(roundCurrency(balance*fixed.EarlyWithdrawalPenaltyRate/100), balance)
+
+	if penalty > 0 {
+		if _, err := tx.Exec("UPDATE wallets SET balance = balance - ?, updated_at = ? WHERE id = ?", penalty, now, fixed.WalletID); err != nil {
+			return nil, fmt.Errorf("failed to charge early withdrawal penalty: %w", err)
+		}
+		var toWalletID *string
+		var recipientID string
+		if fixed.ChamaID != nil {
+			walletID, err := creditChamaWallet(tx, *fixed.ChamaID, penalty)
+			if err != nil {
+				return nil, err
+			}
+			toWalletID = &walletID
+			recipientID = *fixed.ChamaID
+		}
+		if _, err := s.recordTransfer(tx, chainID, &fixed.WalletID, toWalletID, models.TransactionTypeFee, penalty,
+			"Fixed savings early withdrawal penalty", fixed.ID, userID, recipientID, map[string]interface{}{"fixedSavingsId": fixed.ID}); err != nil {
+			return nil, err
+		}
+	}
+
+	payout, err := s.emptySubWallet(tx, fixed.WalletID, userID, "Fixed savings early withdrawal", fixed.ID, chainID, map[string]interface{}{"fixedSavingsId": fixed.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE fixed_savings SET penalty_charged = ?, payout_amount = ?, payout_transaction_id = ? WHERE id = ?
+	`, penalty, payout.amount, payout.transactionID, fixedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record fixed savings payout: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.GetFixedSavingsByID(fixedID, userID)
+}
+
+// ProcessDueSavings runs due auto-sweeps into savings goals and pays out fixed savings
+// that have matured. It is called by the notification scheduler.
+func (s *SavingsService) ProcessDueSavings() {
+	now := time.Now()
+
+	goalIDs, err := s.dueIDs(`
+		SELECT id FROM savings_goals
+		WHERE status = ? AND sweep_type IS NOT NULL AND julianday(next_sweep_at) <= julianday(?)
+		ORDER BY julianday(next_sweep_at), created_at
+	`, models.SavingsGoalStatusActive, now)
+	if err != nil {
+		log.Printf("Failed to load due savings sweeps: %v", err)
+	}
+	for _, goalID := range goalIDs {
+		if err := s.sweep(goalID, now); err != nil {
+			log.Printf("Failed to sweep into savings goal %s: %v", goalID, err)
+		}
+	}
+
+	fixedIDs, err := s.dueIDs(`
+		SELECT id FROM fixed_savings WHERE status = ? AND julianday(maturity_date) <= julianday(?)
+	`, models.FixedSavingsStatusActive, now)
+	if err != nil {
+		log.Printf("Failed to load matured fixed savings: %v", err)
+	}
+	for _, fixedID := range fixedIDs {
+		if err := s.mature(fixedID, now); err != nil {
+			log.Printf("Failed to pay out fixed savings %s: %v", fixedID, err)
+		}
+	}
+}
+
+func (s *SavingsService) dueIDs(query string, args ...interface{}) ([]string, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// sweep moves one period's auto-sweep into a goal. A sweep the personal wallet or the
+// user's transaction limits cannot cover, or that screening holds or blocks, is
+// skipped until the next period rather than retried.
+func (s *SavingsService) sweep(goalID string, now time.Time) error {
+	// The deposit is screened before the sweep's transaction opens, and no more than
+	// the screened amount is moved
+	goal, err := s.getGoal(s.db, goalID)
+	if err != nil {
+		return err
+	}
+	if goal.SweepType == nil || goal.SweepFrequency == nil || goal.SweepDay == nil || goal.SweepAmount == nil {
+		return nil
+	}
+	screened, err := s.sweepAmount(s.db, goal)
+	if err != nil {
+		return err
+	}
+	var evaluation *models.FraudEvaluation
+	var screenErr error
+	if screened > 0 {
+		evaluation, screenErr = s.screenDeposit(goal.UserID, screened)
+		if screenErr != nil && !errors.Is(screenErr, errTransactionHeld) && !errors.Is(screenErr, errTransactionBlocked) {
+			return screenErr
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	goal, err = s.getGoal(tx, goalID)
+	if err != nil {
+		return err
+	}
+	if goal.SweepType == nil || goal.SweepFrequency == nil || goal.SweepDay == nil || goal.SweepAmount == nil {
+		return nil
+	}
+
+	// Claim this period by moving the schedule on
+	nextSweepAt := nextRecurringRun(*goal.SweepFrequency, *goal.SweepDay, now)
+	result, err := tx.Exec(`
+		UPDATE savings_goals SET next_sweep_at = ?, last_sweep_at = ?, updated_at = ?
+		WHERE id = ? AND status = ? AND julianday(next_sweep_at) <= julianday(?)
+	`, nextSweepAt, now, now, goalID, models.SavingsGoalStatusActive, now)
+	if err != nil {
+		return fmt.Errorf("failed to claim savings sweep: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil
+	}
+
+	amount, err := s.sweepAmount(tx, goal)
+	if err != nil {
+		return err
+	}
+	amount = math.Min(amount, screened)
+
+	// A skipped sweep still claims the period, so its partial funding is undone
+	// back to this savepoint rather than with the whole transaction
+	var achieved bool
+	var failure error
+	switch {
+	case amount <= 0:
+	case screenErr != nil:
+		failure = screenErr
+	default:
+		if _, err := tx.Exec("SAVEPOINT sweep_funding"); err != nil {
+			return fmt.Errorf("failed to start savings sweep: %w", err)
+		}
+		achieved, failure = s.fundGoal(tx, goal, amount, "Automatic savings sweep", evaluation)
+		if failure != nil && !isWalletDebitFailure(failure) && !errors.Is(failure, errTransactionLimitExceeded) {
+			return failure
+		}
+		if failure != nil {
+			if _, err := tx.Exec("ROLLBACK TO sweep_funding"); err != nil {
+				return fmt.Errorf("failed to undo savings sweep: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if failure == nil {
+		invalidateMemberAnalyticsForUser(s.db, goal.UserID)
+	} else {
+		message := fmt.Sprintf("We could not move KES %.2f into %s: %v. We will try again on %s.",
+			amount, goal.Name, failure, nextSweepAt.Format("Jan 2, 2006"))
+		if err := NewNotificationService(s.db, nil).CreateInAppNotification(goal.UserID, "alert", "savings", "Savings Sweep Skipped", message, map[string]interface{}{
+			"savingsGoalId": goal.ID,
+			"amount":        amount,
+		}); err != nil {
+			log.Printf("Failed to notify user %s of skipped sweep: %v", goal.UserID, err)
+		}
+	}
+	if achieved {
+		s.notifyGoalAchieved(goal)
+	}
+	return nil
+}
+
+// mature pays a matured fixed savings deposit back to the member with any interest the
+// chama owes. If the chama wallet cannot cover the interest it pays what it holds.
+func (s *SavingsService) mature(fixedID string, now time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	fixed, err := s.getFixedSavings(tx, fixedID)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE fixed_savings SET status = ?, closed_at = ?
+		WHERE id = ? AND status = ? AND julianday(maturity_date) <= julianday(?)
+	`, models.FixedSavingsStatusMatured, now, fixedID, models.FixedSavingsStatusActive, now)
+	if err != nil {
+		return fmt.Errorf("failed to update fixed savings: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil
+	}
+
+	chainID := ""
+	interestPaid := 0.0
+	if fixed.ChamaID != nil {
+		chainID = *fixed.ChamaID
+		if fixed.ExpectedInterest > 0 {
+			var chamaWalletID string
+			var chamaBalance float64
+			err := tx.QueryRow("SELECT id, balance FROM wallets WHERE owner_id = ? AND type = 'chama'", *fixed.ChamaID).Scan(&chamaWalletID, &chamaBalance)
+			if err != nil && err != sql.ErrNoRows {
+				return fmt.Errorf("failed to get chama wallet: %w", err)
+			}
+			interestPaid = roundCurrency(math.Max(0, math.Min(fixed.ExpectedInterest, chamaBalance)))
+			if interestPaid > 0 {
+				if _, err := tx.Exec("UPDATE wallets SET balance = balance - ?, updated_at = ? WHERE id = ?", interestPaid, now, chamaWalletID); err != nil {
+					return fmt.Errorf("failed to pay interest from chama wallet: %w", err)
+				}
+				if err := syncChamaFunds(tx, *fixed.ChamaID); err != nil {
+					return err
+				}
+				toWalletID, err := creditPersonalWallet(tx, fixed.UserID, interestPaid)
+				if err != nil {
+					return err
+				}
+				if _, err := s.recordTransfer(tx, chainID, &chamaWalletID, &toWalletID, models.TransactionTypeTransfer, interestPaid,
+					"Fixed savings interest", fixed.ID, fixed.UserID, fixed.UserID, map[string]interface{}{"fixedSavingsId": fixed.ID}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	payout, err := s.emptySubWallet(tx, fixed.WalletID, fixed.UserID, "Fixed savings matured", fixed.ID, chainID, map[string]interface{}{"fixedSavingsId": fixed.ID})
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE fixed_savings SET interest_paid = ?, payout_amount = ?, payout_transaction_id = ? WHERE id = ?
+	`, interestPaid, payout.amount+interestPaid, payout.transactionID, fixedID)
+	if err != nil {
+		return fmt.Errorf("failed to record fixed savings payout: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	message := fmt.Sprintf("Your fixed savings %s has matured. KES %.2f has been returned to your wallet", fixed.Name, payout.amount+interestPaid)
+	if interestPaid > 0 {
+		message += fmt.Sprintf(", including KES %.2f interest", interestPaid)
+	}
+	message += "."
+	if interestPaid < fixed.ExpectedInterest {
+		message += fmt.Sprintf(" KES %.2f of the interest due could not be paid from the chama wallet.", fixed.ExpectedInterest-interestPaid)
+	}
+	if err := NewNotificationService(s.db, nil).CreateInAppNotification(fixed.UserID, "transaction", "savings", "Fixed Savings Matured", message, map[string]interface{}{
+		"fixedSavingsId": fixed.ID,
+		"payoutAmount":   payout.amount + interestPaid,
+		"interestPaid":   interestPaid,
+	}); err != nil {
+		log.Printf("Failed to notify user %s of matured savings: %v", fixed.UserID, err)
+	}
+	return nil
+}
+
+// sweepAmount works out how much a goal's sweep rule moves this period
+func (s *SavingsService) sweepAmount(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, goal *models.SavingsGoal) (float64, error) {
+	amount := *goal.SweepAmount
+	if *goal.SweepType == models.SavingsSweepExcess {
+		var balance float64
+		err := q.QueryRow("SELECT COALESCE(balance, 0) FROM wallets WHERE owner_id = ? AND type = 'personal'", goal.UserID).Scan(&balance)
+		if err != nil && err != sql.ErrNoRows {
+			return 0, fmt.Errorf("failed to get personal wallet balance: %w", err)
+		}
+		amount = balance - *goal.SweepAmount
+	}
+	return roundCurrency(math.Min(amount, goal.AmountRemaining)), nil
+}
+
+// screenDeposit screens money leaving the personal wallet for savings. It must be
+// called before the caller opens its database transaction.
+func (s *SavingsService) screenDeposit(userID string, amount float64) (*models.FraudEvaluation, error) {
+	return screenOutgoing(s.db, &models.FraudCheck{
+		UserID:          userID,
+		TransactionType: models.TransactionTypeTransfer,
+		Amount:          amount,
+		RecipientID:     userID,
+	})
+}
+
+// fundGoal moves money from the personal wallet into a goal inside tx and reports
+// whether the goal has just reached its target. The deposit counts against the
+// user's transaction limits and carries the evaluation it was screened with.
+func (s *SavingsService) fundGoal(tx *sql.Tx, goal *models.SavingsGoal, amount float64, description string, evaluation *models.FraudEvaluation) (bool, error) {
+	if err := reserveTransactionLimit(tx, goal.UserID, goal.ID, amount); err != nil {
+		return false, err
+	}
+	fromWalletID, err := debitPersonalWallet(tx, goal.UserID, amount)
+	if err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec("UPDATE wallets SET balance = balance + ?, updated_at = ? WHERE id = ?", amount, time.Now(), goal.WalletID); err != nil {
+		return false, fmt.Errorf("failed to update savings wallet: %w", err)
+	}
+	transactionID, err := s.recordTransfer(tx, "", &fromWalletID, &goal.WalletID, models.TransactionTypeTransfer, amount,
+		description, goal.ID, goal.UserID, goal.UserID, map[string]interface{}{"savingsGoalId": goal.ID})
+	if err != nil {
+		return false, err
+	}
+	if err := recordFraudEvaluation(tx, evaluation, transactionID); err != nil {
+		return false, err
+	}
+	return s.updateGoalStatus(tx, goal, goal.Balance+amount)
+}
+
+// updateGoalStatus marks a goal achieved once its balance reaches the target, and active
+// again if it falls back below it
+func (s *SavingsService) updateGoalStatus(tx *sql.Tx, goal *models.SavingsGoal, balance float64) (bool, error) {
+	now := time.Now()
+	switch {
+	case goal.Status == models.SavingsGoalStatusActive && balance >= goal.TargetAmount:
+		_, err := tx.Exec("UPDATE savings_goals SET status = ?, achieved_at = ?, updated_at = ? WHERE id = ?",
+			models.SavingsGoalStatusAchieved, now, now, goal.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to update savings goal: %w", err)
+		}
+		return true, nil
+	case goal.Status == models.SavingsGoalStatusAchieved && balance < goal.TargetAmount:
+		_, err := tx.Exec("UPDATE savings_goals SET status = ?, updated_at = ? WHERE id = ?",
+			models.SavingsGoalStatusActive, now, goal.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to update savings goal: %w", err)
+		}
+	}
+	return false, nil
+}
+
+func (s *SavingsService) notifyGoalAchieved(goal *models.SavingsGoal) {
+	message := fmt.Sprintf("You have reached your savings goal %s of KES %.2f.", goal.Name, goal.TargetAmount)
+	if err := NewNotificationService(s.db, nil).CreateInAppNotification(goal.UserID, "transaction", "savings", "Savings Goal Reached", message, map[string]interface{}{
+		"savingsGoalId": goal.ID,
+		"targetAmount":  goal.TargetAmount,
+	}); err != nil {
+		log.Printf("Failed to notify user %s of reached goal: %v", goal.UserID, err)
+	}
+}
+
+func (s *SavingsService) createSubWallet(tx *sql.Tx, userID string, walletType models.WalletType, locked bool) (string, error) {
+	walletID := uuid.New().String()
+	now := time.Now()
+	_, err := tx.Exec(`
+		INSERT INTO wallets (id, type, owner_id, balance, currency, is_active, is_locked, created_at, updated_at)
+		VALUES (?, ?, ?, 0, 'KES', TRUE, ?, ?, ?)
+	`, walletID, walletType, userID, locked, now, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to create savings wallet: %w", err)
+	}
+	return walletID, nil
+}
+
+func (s *SavingsService) debitSubWallet(tx *sql.Tx, walletID string, amount float64) error {
+	result, err := tx.Exec(`
+		UPDATE wallets SET balance = balance - ?, updated_at = ?
+		WHERE id = ? AND balance >= ? AND COALESCE(is_locked, FALSE) = FALSE
+	`, amount, time.Now(), walletID, amount)
+	if err != nil {
+		return fmt.Errorf("failed to update savings wallet: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errInsufficientBalance
+	}
+	return nil
+}
+
+// isWalletDebitFailure reports whether err means the personal wallet could not be
+// drawn on, as opposed to a database error
+func isWalletDebitFailure(err error) bool {
+	return errors.Is(err, errInsufficientBalance) || errors.Is(err, errWalletLocked) || errors.Is(err, errWalletNotFound)
+}
+
+type savingsPayout struct {
+	amount        float64
+	transactionID *string
+}
+
+// emptySubWallet returns whatever a savings wallet holds to the owner's personal wallet
+// and retires it
+func (s *SavingsService) emptySubWallet(tx *sql.Tx, walletID, userID, description, reference, chainID string, metadata map[string]interface{}) (*savingsPayout, error) {
+	var balance float64
+	if err := tx.QueryRow("SELECT balance FROM wallets WHERE id = ?", walletID).Scan(&balance); err != nil {
+		return nil, fmt.Errorf("failed to get savings wallet balance: %w", err)
+	}
+	_, err := tx.Exec(`
+		UPDATE wallets SET balance = 0, is_locked = FALSE, is_active = FALSE, updated_at = ? WHERE id = ?
+	`, time.Now(), walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close savings wallet: %w", err)
+	}
+
+	payout := &savingsPayout{amount: roundCurrency(balance)}
+	if payout.amount <= 0 {
+		return payout, nil
+	}
+
+	toWalletID, err := creditPersonalWallet(tx, userID, payout.amount)
+	if err != nil {
+		return nil, err
+	}
+	transactionID, err := s.recordTransfer(tx, chainID, &walletID, &toWalletID, models.TransactionTypeTransfer, payout.amount,
+		description, reference, userID, userID, metadata)
+	if err != nil {
+		return nil, err
+	}
+	payout.transactionID = &transactionID
+	return payout, nil
+}
+
+// recordTransfer inserts a completed wallet transaction and appends it to the audit log
+func (s *SavingsService) recordTransfer(tx *sql.Tx, chainID string, fromWalletID, toWalletID *string, transactionType models.TransactionType, amount float64, description, reference, initiatedBy, recipientID string, metadata map[string]interface{}) (string, error) {
+	transactionID := uuid.New().String()
+	now := time.Now()
+	metadataJSON, _ := json.Marshal(metadata)
+
+	var recipient *string
+	if recipientID != "" {
+		recipient = &recipientID
+	}
+	_, err := tx.Exec(`
+		INSERT INTO transactions (
+			id, from_wallet_id, to_wallet_id, type, status, amount, currency, description,
+			reference, payment_method, metadata, initiated_by, recipient_id, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, 'KES', ?, ?, ?, ?, ?, ?, ?, ?)
+	`, transactionID, fromWalletID, toWalletID, transactionType, models.TransactionStatusCompleted, amount,
+		description, reference, models.PaymentMethodWalletTransfer, string(metadataJSON), initiatedBy, recipient, now, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to record transaction: %w", err)
+	}
+
+	if err := NewAuditService(s.db).RecordTransaction(tx, chainID, transactionID); err != nil {
+		return "", err
+	}
+	return transactionID, nil
+}
+
+func (s *SavingsService) getRules(chamaID string) (*models.FixedSavingsRules, error) {
+	rules := &models.FixedSavingsRules{
+		ChamaID:                    chamaID,
+		EarlyWithdrawalPenaltyRate: defaultEarlyWithdrawalPenaltyRate,
+		MinTermMonths:              1,
+		MaxTermMonths:              60,
+	}
+
+	var updatedBy sql.NullString
+	var updatedAt sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT enabled, annual_interest_rate, early_withdrawal_penalty_rate, min_term_months, max_term_months, updated_by, updated_at
+		FROM fixed_savings_rules WHERE chama_id = ?
+	`, chamaID).Scan(&rules.Enabled, &rules.AnnualInterestRate, &rules.EarlyWithdrawalPenaltyRate,
+		&rules.MinTermMonths, &rules.MaxTermMonths, &updatedBy, &updatedAt)
+	if err == sql.ErrNoRows {
+		return rules, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fixed savings rules: %w", err)
+	}
+
+	if updatedBy.Valid {
+		rules.UpdatedBy = &updatedBy.String
+	}
+	if updatedAt.Valid {
+		rules.UpdatedAt = &updatedAt.Time
+	}
+	return rules, nil
+}
+
+func (s *SavingsService) getGoal(q interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}, goalID string) (*models.SavingsGoal, error) {
+	goals, err := s.queryGoals(q, "WHERE g.id = ?", goalID)
+	if err != nil {
+		return nil, err
+	}
+	if len(goals) == 0 {
+		return nil, fmt.Errorf("savings goal not found")
+	}
+	return &goals[0], nil
+}
+
+func (s *SavingsService) queryGoals(q interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}, where string, args ...interface{}) ([]models.SavingsGoal, error) {
+	rows, err := q.Query(`
+		SELECT g.id, g.wallet_id, g.user_id, g.name, g.target_amount, g.target_date, COALESCE(w.balance, 0),
+			   g.status, g.sweep_type, g.sweep_amount, g.sweep_frequency, g.sweep_day, g.next_sweep_at,
+			   g.last_sweep_at, g.achieved_at, g.closed_at, g.created_at, g.updated_at
+		FROM savings_goals g
+		LEFT JOIN wallets w ON w.id = g.wallet_id
+		`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get savings goals: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	goals := []models.SavingsGoal{}
+	for rows.Next() {
+		var goal models.SavingsGoal
+		var sweepType, sweepFrequency sql.NullString
+		var sweepAmount sql.NullFloat64
+		var sweepDay sql.NullInt64
+		var targetDate, nextSweepAt, lastSweepAt, achievedAt, closedAt sql.NullTime
+		err := rows.Scan(&goal.ID, &goal.WalletID, &goal.UserID, &goal.Name, &goal.TargetAmount, &targetDate, &goal.Balance,
+			&goal.Status, &sweepType, &sweepAmount, &sweepFrequency, &sweepDay, &nextSweepAt,
+			&lastSweepAt, &achievedAt, &closedAt, &goal.CreatedAt, &goal.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan savings goal: %w", err)
+		}
+		if targetDate.Valid {
+			goal.TargetDate = &targetDate.Time
+		}
+		if sweepType.Valid {
+			sweep := models.SavingsSweepType(sweepType.String)
+			goal.SweepType = &sweep
+		}
+		if sweepAmount.Valid {
+			goal.SweepAmount = &sweepAmount.Float64
+		}
+		if sweepFrequency.Valid {
+			goal.SweepFrequency = &sweepFrequency.String
+		}
+		if sweepDay.Valid {
+			day := int(sweepDay.Int64)
+			goal.SweepDay = &day
+		}
+		if nextSweepAt.Valid {
+			goal.NextSweepAt = &nextSweepAt.Time
+		}
+		if lastSweepAt.Valid {
+			goal.LastSweepAt = &lastSweepAt.Time
+		}
+		if achievedAt.Valid {
+			goal.AchievedAt = &achievedAt.Time
+		}
+		if closedAt.Valid {
+			goal.ClosedAt = &closedAt.Time
+		}
+
+		goal.Progress = roundCurrency(math.Min(100, goal.Balance/goal.TargetAmount*100))
+		goal.AmountRemaining = roundCurrency(math.Max(0, goal.TargetAmount-goal.Balance))
+		if goal.TargetDate != nil && goal.Status == models.SavingsGoalStatusActive && goal.AmountRemaining > 0 {
+			months := math.Max(1, math.Ceil(goal.TargetDate.Sub(now).Hours()/24/30.4375))
+			goal.RequiredPerMonth = roundCurrency(goal.AmountRemaining / months)
+		}
+		goals = append(goals, goal)
+	}
+
+	return goals, nil
+}
+
+func (s *SavingsService) getFixedSavings(q interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}, fixedID string) (*models.FixedSavings, error) {
+	savings, err := s.queryFixedSavings(q, "WHERE id = ?", fixedID)
+	if err != nil {
+		return nil, err
+	}
+	if len(savings) == 0 {
+		return nil, fmt.Errorf("fixed savings not found")
+	}
+	return &savings[0], nil
+}
+
+func (s *SavingsService) queryFixedSavings(q interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}, where string, args ...interface{}) ([]models.FixedSavings, error) {
+	rows, err := q.Query(`
+		SELECT id, wallet_id, user_id, chama_id, name, principal, term_months, interest_rate,
+			   early_withdrawal_penalty_rate, start_date, maturity_date, status, interest_paid,
+			   penalty_charged, payout_amount, payout_transaction_id, closed_at, created_at
+		FROM fixed_savings
+		`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fixed savings: %w", err)
+	}
+	defer rows.Close()
+
+	savings := []models.FixedSavings{}
+	for rows.Next() {
+		var fixed models.FixedSavings
+		var chamaID, payoutTransactionID sql.NullString
+		var closedAt sql.NullTime
+		err := rows.Scan(&fixed.ID, &fixed.WalletID, &fixed.UserID, &chamaID, &fixed.Name, &fixed.Principal,
+			&fixed.TermMonths, &fixed.InterestRate, &fixed.EarlyWithdrawalPenaltyRate, &fixed.StartDate,
+			&fixed.MaturityDate, &fixed.Status, &fixed.InterestPaid, &fixed.PenaltyCharged, &fixed.PayoutAmount,
+			&payoutTransactionID, &closedAt, &fixed.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan fixed savings: %w", err)
+		}
+		if chamaID.Valid {
+			fixed.ChamaID = &chamaID.String
+		}
+		if payoutTransactionID.Valid {
+			fixed.PayoutTransactionID = &payoutTransactionID.String
+		}
+		if closedAt.Valid {
+			fixed.ClosedAt = &closedAt.Time
+		}
+		// Simple interest over the term
+		fixed.ExpectedInterest = roundCurrency(fixed.Principal * fixed.InterestRate / 100 * float64(fixed.TermMonths) / 12)
+		savings = append(savings, fixed)
+	}
+
+	return savings, nil
+}
+
+func (s *SavingsService) isMember(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, userID, chamaID string) bool {
+	var exists int
+	err := q.QueryRow(`
+		SELECT 1 FROM chama_members WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
+
+func (s *SavingsService) isOfficial(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM chama_members
+		WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+		AND role IN ('chairperson', 'secretary', 'treasurer')
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
@@ -0,0 +1,284 @@
+package services_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type SavingsTestSuite struct {
+	suite.Suite
+	testDB   *helpers.TestDatabase
+	db       *sql.DB
+	service  *services.SavingsService
+	memberID string
+	chairID  string
+	chamaID  string
+}
+
+func (suite *SavingsTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewSavingsService(suite.db)
+
+	suite.memberID = suite.testDB.AddTestUser(suite.T(), "Saver")
+	suite.chairID = suite.testDB.AddTestUser(suite.T(), "Chair")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.memberID, "member")
+
+	suite.fund(suite.memberID, "personal", 10000)
+}
+
+func (suite *SavingsTestSuite) fund(ownerID, walletType string, balance float64) {
+	_, err := suite.db.Exec(`
+		INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, ?, ?, ?)
+	`, uuid.New().String(), walletType, ownerID, balance)
+	suite.Require().NoError(err)
+}
+
+func (suite *SavingsTestSuite) balance(ownerID, walletType string) float64 {
+	var balance float64
+	err := suite.db.QueryRow("SELECT balance FROM wallets WHERE owner_id = ? AND type = ?", ownerID, walletType).Scan(&balance)
+	suite.Require().NoError(err)
+	return balance
+}
+
+func (suite *SavingsTestSuite) notifications(title string) int {
+	var count int
+	err := suite.db.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = ? AND title = ?", suite.memberID, title).Scan(&count)
+	suite.Require().NoError(err)
+	return count
+}
+
+func (suite *SavingsTestSuite) limitUsage() float64 {
+	var used float64
+	err := suite.db.QueryRow("SELECT COALESCE(SUM(amount), 0) FROM transaction_limit_usage WHERE user_id = ?", suite.memberID).Scan(&used)
+	suite.Require().NoError(err)
+	return used
+}
+
+func (suite *SavingsTestSuite) TestGoalProgressAndLifecycle() {
+	initial := 2000.0
+	goal, err := suite.service.CreateGoal(suite.memberID, &models.CreateSavingsGoalRequest{
+		Name:           "School fees",
+		TargetAmount:   5000,
+		TargetDate:     time.Now().AddDate(0, 6, 0).Format("2006-01-02"),
+		InitialDeposit: &initial,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(2000.0, goal.Balance)
+	suite.Equal(40.0, goal.Progress)
+	suite.Equal(3000.0, goal.AmountRemaining)
+	suite.InDelta(500.0, goal.RequiredPerMonth, 100)
+	suite.Equal(8000.0, suite.balance(suite.memberID, "personal"))
+
+	goal, err = suite.service.DepositToGoal(goal.ID, suite.memberID, 3000)
+	suite.Require().NoError(err)
+	suite.Equal(models.SavingsGoalStatusAchieved, goal.Status)
+	suite.NotNil(goal.AchievedAt)
+	suite.Equal(100.0, goal.Progress)
+	suite.Equal(1, suite.notifications("Savings Goal Reached"))
+
+	_, err = suite.service.WithdrawFromGoal(goal.ID, suite.memberID, 9000)
+	suite.Error(err, "cannot withdraw more than the goal holds")
+	goal, err = suite.service.WithdrawFromGoal(goal.ID, suite.memberID, 1000)
+	suite.Require().NoError(err)
+	suite.Equal(models.SavingsGoalStatusActive, goal.Status)
+	suite.Equal(4000.0, goal.Balance)
+	suite.Equal(6000.0, suite.balance(suite.memberID, "personal"))
+
+	_, err = suite.service.GetGoal(goal.ID, suite.chairID)
+	suite.Error(err, "goals are private")
+
+	goal, err = suite.service.CloseGoal(goal.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(models.SavingsGoalStatusClosed, goal.Status)
+	suite.Equal(0.0, goal.Balance)
+	suite.Equal(10000.0, suite.balance(suite.memberID, "personal"))
+
+	_, err = suite.service.DepositToGoal(goal.ID, suite.memberID, 100)
+	suite.Error(err, "closed goals take no deposits")
+
+	var audited int
+	err = suite.db.QueryRow(`
+		SELECT COUNT(*) FROM audit_log a JOIN transactions t ON t.id = a.entity_id
+		WHERE json_extract(t.metadata, '$.savingsGoalId') = ?
+	`, goal.ID).Scan(&audited)
+	suite.Require().NoError(err)
+	suite.Equal(4, audited, "every goal movement is in the audit log")
+
+	var screened int
+	err = suite.db.QueryRow(`
+		SELECT COUNT(*) FROM fraud_evaluations f JOIN transactions t ON t.id = f.transaction_id
+		WHERE json_extract(t.metadata, '$.savingsGoalId') = ?
+	`, goal.ID).Scan(&screened)
+	suite.Require().NoError(err)
+	suite.Equal(2, screened, "both deposits are screened")
+}
+
+func (suite *SavingsTestSuite) TestAutoSweeps() {
+	fixedGoal, err := suite.service.CreateGoal(suite.memberID, &models.CreateSavingsGoalRequest{Name: "Holiday", TargetAmount: 2500})
+	suite.Require().NoError(err)
+	day := 1
+	fixedGoal, err = suite.service.SetSweepRule(fixedGoal.ID, suite.memberID, &models.SetSavingsSweepRequest{
+		SweepType: "fixed", Amount: 1500, Frequency: "monthly", Day: &day,
+	})
+	suite.Require().NoError(err)
+	suite.Require().NotNil(fixedGoal.NextSweepAt)
+	suite.Equal(1, fixedGoal.NextSweepAt.Day())
+
+	excessGoal, err := suite.service.CreateGoal(suite.memberID, &models.CreateSavingsGoalRequest{Name: "Rainy day", TargetAmount: 100000})
+	suite.Require().NoError(err)
+	_, err = suite.service.SetSweepRule(excessGoal.ID, suite.memberID, &models.SetSavingsSweepRequest{
+		SweepType: "excess", Amount: 6000, Frequency: "weekly",
+	})
+	suite.Require().NoError(err)
+
+	due := func() {
+		_, err := suite.db.Exec("UPDATE savings_goals SET next_sweep_at = ? WHERE sweep_type IS NOT NULL", time.Now().Add(-time.Minute))
+		suite.Require().NoError(err)
+	}
+
+	due()
+	suite.service.ProcessDueSavings()
+
+	fixedGoal, err = suite.service.GetGoal(fixedGoal.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(1500.0, fixedGoal.Balance)
+	suite.True(fixedGoal.NextSweepAt.After(time.Now()))
+
+	excessGoal, err = suite.service.GetGoal(excessGoal.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(6000.0, suite.balance(suite.memberID, "personal"), "the excess sweep keeps the threshold")
+	suite.Equal(2500.0, excessGoal.Balance)
+
+	suite.service.ProcessDueSavings()
+	suite.Equal(6000.0, suite.balance(suite.memberID, "personal"), "each period sweeps once")
+
+	due()
+	suite.service.ProcessDueSavings()
+	fixedGoal, err = suite.service.GetGoal(fixedGoal.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(2500.0, fixedGoal.Balance, "the sweep stops at the target")
+	suite.Equal(models.SavingsGoalStatusAchieved, fixedGoal.Status)
+
+	_, err = suite.db.Exec("UPDATE wallets SET balance = 100 WHERE owner_id = ? AND type = 'personal'", suite.memberID)
+	suite.Require().NoError(err)
+	_, err = suite.service.SetSweepRule(excessGoal.ID, suite.memberID, &models.SetSavingsSweepRequest{
+		SweepType: "fixed", Amount: 500, Frequency: "weekly",
+	})
+	suite.Require().NoError(err)
+	usedBefore := suite.limitUsage()
+	due()
+	suite.service.ProcessDueSavings()
+	suite.Equal(100.0, suite.balance(suite.memberID, "personal"))
+	suite.Equal(1, suite.notifications("Savings Sweep Skipped"))
+	suite.Equal(usedBefore, suite.limitUsage(), "a skipped sweep does not count against the limits")
+	excessGoal, err = suite.service.GetGoal(excessGoal.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.True(excessGoal.NextSweepAt.After(time.Now()), "a skipped sweep waits for the next period")
+}
+
+func (suite *SavingsTestSuite) TestFixedSavingsLockAndEarlyBreak() {
+	fixed, err := suite.service.CreateFixedSavings(suite.memberID, &models.CreateFixedSavingsRequest{
+		Name: "School fees", Amount: 4000, TermMonths: 6,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(models.FixedSavingsStatusActive, fixed.Status)
+	suite.Equal(0.0, fixed.ExpectedInterest)
+	suite.Equal(5.0, fixed.EarlyWithdrawalPenaltyRate)
+	suite.Equal(6000.0, suite.balance(suite.memberID, "personal"))
+
+	var locked bool
+	err = suite.db.QueryRow("SELECT is_locked FROM wallets WHERE id = ?", fixed.WalletID).Scan(&locked)
+	suite.Require().NoError(err)
+	suite.True(locked)
+
+	walletService := services.NewWalletService(suite.db)
+	transaction, err := walletService.CreateTransaction(&models.TransactionCreation{
+		FromWalletID:  &fixed.WalletID,
+		Type:          models.TransactionTypeWithdrawal,
+		Amount:        1000,
+		PaymentMethod: models.PaymentMethodMpesa,
+	}, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Error(walletService.ProcessTransaction(transaction.ID), "locked savings cannot be withdrawn")
+
+	broken, err := suite.service.BreakFixedSavings(fixed.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(models.FixedSavingsStatusBroken, broken.Status)
+	suite.Equal(200.0, broken.PenaltyCharged)
+	suite.Equal(3800.0, broken.PayoutAmount)
+	suite.Equal(9800.0, suite.balance(suite.memberID, "personal"))
+
+	_, err = suite.service.BreakFixedSavings(fixed.ID, suite.memberID)
+	suite.Error(err)
+
+	_, err = suite.service.CreateFixedSavings(suite.memberID, &models.CreateFixedSavingsRequest{Amount: 50000, TermMonths: 3})
+	suite.Error(err, "insufficient balance")
+}
+
+func (suite *SavingsTestSuite) TestChamaRulesInterestAndPenalty() {
+	_, err := suite.service.CreateFixedSavings(suite.memberID, &models.CreateFixedSavingsRequest{
+		Amount: 1000, TermMonths: 12, ChamaID: suite.chamaID,
+	})
+	suite.Error(err, "the chama has not enabled fixed savings")
+
+	enabled := true
+	rate := 12.0
+	penalty := 10.0
+	minTerm := 3
+	_, err = suite.service.UpdateFixedSavingsRules(suite.chamaID, suite.memberID, &models.UpdateFixedSavingsRulesRequest{Enabled: &enabled})
+	suite.Error(err, "only officials can change the rules")
+	rules, err := suite.service.UpdateFixedSavingsRules(suite.chamaID, suite.chairID, &models.UpdateFixedSavingsRulesRequest{
+		Enabled: &enabled, AnnualInterestRate: &rate, EarlyWithdrawalPenaltyRate: &penalty, MinTermMonths: &minTerm,
+	})
+	suite.Require().NoError(err)
+	suite.True(rules.Enabled)
+
+	_, err = suite.service.CreateFixedSavings(suite.memberID, &models.CreateFixedSavingsRequest{
+		Amount: 1000, TermMonths: 1, ChamaID: suite.chamaID,
+	})
+	suite.Error(err, "term is below the chama minimum")
+
+	fixed, err := suite.service.CreateFixedSavings(suite.memberID, &models.CreateFixedSavingsRequest{
+		Amount: 5000, TermMonths: 6, ChamaID: suite.chamaID,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(300.0, fixed.ExpectedInterest)
+
+	suite.fund(suite.chamaID, "chama", 200)
+	_, err = suite.db.Exec("UPDATE fixed_savings SET maturity_date = ? WHERE id = ?", time.Now().Add(-time.Minute), fixed.ID)
+	suite.Require().NoError(err)
+	suite.service.ProcessDueSavings()
+	suite.service.ProcessDueSavings()
+
+	matured, err := suite.service.GetFixedSavingsByID(fixed.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(models.FixedSavingsStatusMatured, matured.Status)
+	suite.Equal(200.0, matured.InterestPaid, "interest is capped at what the chama wallet holds")
+	suite.Equal(5200.0, matured.PayoutAmount)
+	suite.Equal(10200.0, suite.balance(suite.memberID, "personal"))
+	suite.Equal(0.0, suite.balance(suite.chamaID, "chama"))
+	suite.Equal(1, suite.notifications("Fixed Savings Matured"))
+
+	early, err := suite.service.CreateFixedSavings(suite.memberID, &models.CreateFixedSavingsRequest{
+		Amount: 2000, TermMonths: 3, ChamaID: suite.chamaID,
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.BreakFixedSavings(early.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(200.0, suite.balance(suite.chamaID, "chama"), "the penalty is paid to the chama")
+	suite.Equal(10000.0, suite.balance(suite.memberID, "personal"))
+}
+
+func TestSavings(t *testing.T) {
+	suite.Run(t, new(SavingsTestSuite))
+}
@@ -0,0 +1,418 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/utils"
+)
+
+// ShareCertificateService issues signed share certificates, renders them as PDFs and
+// verifies them for third parties. Certificates are kept in step with holdings by
+// syncShareCertificates, which the share services call whenever a holding changes.
+type ShareCertificateService struct {
+	db         *sql.DB
+	signingKey ed25519.PrivateKey
+	baseURL    string
+}
+
+// NewShareCertificateService creates a certificate service. baseURL is the public
+// server address printed in certificates' QR codes.
+func NewShareCertificateService(db *sql.DB, signingKey ed25519.PrivateKey, baseURL string) *ShareCertificateService {
+	return &ShareCertificateService{
+		db:         db,
+		signingKey: signingKey,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// NewCertificateSigningKey decodes CERTIFICATE_SIGNING_KEY, a base64 Ed25519 seed.
+// There is no fallback: a key derived from a guessable secret would let anyone
+// forge certificates.
+func NewCertificateSigningKey(encodedSeed string) (ed25519.PrivateKey, error) {
+	if encodedSeed == "" {
+		return nil, fmt.Errorf("CERTIFICATE_SIGNING_KEY is required to sign share certificates")
+	}
+	seed, err := base64.StdEncoding.DecodeString(encodedSeed)
+	if err != nil {
+		return nil, fmt.Errorf("certificate signing key is not valid base64: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("certificate signing key must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// PublicKey returns the base64 verification key, for anyone checking signatures offline
+func (s *ShareCertificateService) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.signingKey.Public().(ed25519.PublicKey))
+}
+
+// GetMemberCertificates returns a member's certificates in a chama, newest first,
+// including cancelled ones. Holdings from before certificates existed are issued
+// one on first request.
+func (s *ShareCertificateService) GetMemberCertificates(chamaID, memberID string) ([]models.ShareCertificate, error) {
+	var member int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM chama_members WHERE chama_id = ? AND user_id = ? AND is_active = TRUE", chamaID, memberID).Scan(&member)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+	if member == 0 {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	rows, err := s.db.Query("SELECT id FROM shares WHERE chama_id = ? AND member_id = ? AND status = 'active'", chamaID, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member shares: %w", err)
+	}
+	var shareIDs []string
+	for rows.Next() {
+		var shareID string
+		if err := rows.Scan(&shareID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan share: %w", err)
+		}
+		shareIDs = append(shareIDs, shareID)
+	}
+	rows.Close()
+	if err := syncShareCertificates(s.db, "holding updated", shareIDs...); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(shareCertificateSelect+`
+		WHERE sc.chama_id = ? AND sc.member_id = ?
+		ORDER BY sc.issue_date DESC, sc.created_at DESC
+	`, chamaID, memberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share certificates: %w", err)
+	}
+	defer rows.Close()
+
+	certificates := []models.ShareCertificate{}
+	for rows.Next() {
+		certificate, err := scanShareCertificate(rows)
+		if err != nil {
+			return nil, err
+		}
+		s.sign(certificate)
+		certificates = append(certificates, *certificate)
+	}
+	return certificates, nil
+}
+
+// GetCertificate returns a certificate to its holder, the chama's officials or an
+// admin. Certificates the user may not see are reported as not found.
+func (s *ShareCertificateService) GetCertificate(certificateID, userID string, isAdmin bool) (*models.ShareCertificate, error) {
+	certificate, err := scanShareCertificate(s.db.QueryRow(shareCertificateSelect+" WHERE sc.id = ?", certificateID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("certificate not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if certificate.MemberID != userID && !isAdmin {
+		var official int
+		err := s.db.QueryRow(`
+			SELECT COUNT(*) FROM chama_members
+			WHERE chama_id = ? AND user_id = ? AND is_active = TRUE
+			AND role IN ('chairperson', 'secretary', 'treasurer')
+		`, certificate.ChamaID, userID).Scan(&official)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check certificate access: %w", err)
+		}
+		if official == 0 {
+			return nil, fmt.Errorf("certificate not found")
+		}
+	}
+
+	s.sign(certificate)
+	return certificate, nil
+}
+
+// RenderPDF draws a certificate as a landscape A4 page. Cancelled certificates are
+// rendered with a cancellation banner.
+func (s *ShareCertificateService) RenderPDF(certificate *models.ShareCertificate) ([]byte, error) {
+	qr, err := encodeQRCode(certificate.VerificationURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode verification QR code: %w", err)
+	}
+
+	const width, height = pdfA4Height, pdfA4Width
+	doc := newPDFDocument()
+	doc.AddPage(width, height)
+
+	doc.SetStrokeColor(0.11, 0.31, 0.22)
+	doc.SetLineWidth(3)
+	doc.Rect(20, 20, width-40, height-40)
+	doc.SetLineWidth(0.75)
+	doc.Rect(28, 28, width-56, height-56)
+
+	doc.SetFillColor(0.11, 0.31, 0.22)
+	doc.TextCentered(width/2, 95, pdfFontBold, 30, "SHARE CERTIFICATE")
+	doc.TextCentered(width/2, 130, pdfFontBold, 18, certificate.ChamaName)
+
+	doc.SetFillColor(0, 0, 0)
+	doc.Text(50, 60, pdfFontRegular, 10, "Certificate No. "+certificate.CertificateNumber)
+	doc.TextRight(width-50, 60, pdfFontRegular, 10, "Issued "+utils.FormatTimeEAT(certificate.IssueDate, "2 January 2006"))
+
+	doc.TextCentered(width/2, 185, pdfFontRegular, 13, "This is to certify that")
+	doc.TextCentered(width/2, 222, pdfFontBold, 24, certificate.HolderName)
+	doc.TextCentered(width/2, 255, pdfFontRegular, 13, "is the registered holder of")
+	holding := fmt.Sprintf("%d %s shares", certificate.SharesCount, certificate.ShareClass)
+	if certificate.SharesCount == 1 {
+		holding = fmt.Sprintf("1 %s share", certificate.ShareClass)
+	}
+	doc.TextCentered(width/2, 290, pdfFontBold, 20, holding)
+	doc.TextCentered(width/2, 320, pdfFontRegular, 13, "in "+certificate.ChamaName+", subject to its constitution and by-laws.")
+
+	// Signature block on the left, verification QR code on the right
+	doc.SetStrokeColor(0.6, 0.6, 0.6)
+	doc.SetLineWidth(0.5)
+	doc.Line(50, 392, 520, 392)
+	doc.Text(50, 410, pdfFontBold, 10, "Digitally signed by the VaultKe share registry (Ed25519)")
+	y := 425.0
+	for _, line := range splitFixed(certificate.Signature, 64) {
+		doc.Text(50, y, pdfFontRegular, 8, line)
+		y += 11
+	}
+	doc.Text(50, y+8, pdfFontRegular, 8, "Scan the QR code or visit the address below to confirm this certificate is valid:")
+	y += 21
+	for _, line := range splitFixed(certificate.VerificationURL, 90) {
+		doc.Text(50, y, pdfFontRegular, 7, line)
+		y += 9
+	}
+	doc.QRCode(width-200, 380, 150, qr)
+
+	if certificate.Status == models.ShareCertificateCancelled {
+		doc.SetFillColor(0.75, 0.1, 0.1)
+		doc.FillRect(28, 335, width-56, 40)
+		doc.SetFillColor(1, 1, 1)
+		banner := "CANCELLED"
+		if certificate.CancelledAt != nil {
+			banner += " ON " + strings.ToUpper(utils.FormatTimeEAT(*certificate.CancelledAt, "2 January 2006"))
+		}
+		doc.TextCentered(width/2, 362, pdfFontBold, 20, banner)
+	}
+
+	return doc.Bytes(), nil
+}
+
+// Verify checks a certificate number and signature from a QR code. A bad signature
+// reveals nothing; a good one reveals only what is printed on that certificate.
+func (s *ShareCertificateService) Verify(certificateNumber, signature string) (*models.ShareCertificateVerification, error) {
+	result := &models.ShareCertificateVerification{CertificateNumber: certificateNumber}
+
+	certificate, err := scanShareCertificate(s.db.QueryRow(shareCertificateSelect+" WHERE sc.certificate_number = ?", certificateNumber))
+	if err == sql.ErrNoRows {
+		result.Reason = "certificate not found"
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !ed25519.Verify(s.signingKey.Public().(ed25519.PublicKey), certificatePayload(certificate), decoded) {
+		result.Reason = "signature does not match this certificate"
+		return result, nil
+	}
+
+	result.Status = certificate.Status
+	result.ChamaName = certificate.ChamaName
+	result.HolderName = certificate.HolderName
+	result.SharesCount = certificate.SharesCount
+	result.ShareClass = certificate.ShareClass
+	result.IssueDate = &certificate.IssueDate
+	if certificate.Status == models.ShareCertificateCancelled {
+		result.CancelledAt = certificate.CancelledAt
+		result.Reason = "certificate has been cancelled"
+		if certificate.CancellationReason != nil {
+			result.Reason += ": " + *certificate.CancellationReason
+		}
+		return result, nil
+	}
+
+	result.Valid = true
+	return result, nil
+}
+
+// sign fills in a certificate's signature and verification URL
+func (s *ShareCertificateService) sign(certificate *models.ShareCertificate) {
+	signature := ed25519.Sign(s.signingKey, certificatePayload(certificate))
+	certificate.Signature = base64.RawURLEncoding.EncodeToString(signature)
+	certificate.VerificationURL = fmt.Sprintf("%s/api/v1/certificates/verify/%s?sig=%s",
+		s.baseURL, url.PathEscape(certificate.CertificateNumber), certificate.Signature)
+}
+
+// certificatePayload is the signed content of a certificate: everything printed on
+// it that a forger would want to change
+func certificatePayload(certificate *models.ShareCertificate) []byte {
+	return []byte(strings.Join([]string{
+		"vaultke-share-certificate",
+		certificate.CertificateNumber,
+		certificate.ChamaID,
+		certificate.MemberID,
+		certificate.ShareClass,
+		fmt.Sprintf("%d", certificate.SharesCount),
+		certificate.IssueDate.UTC().Format(time.RFC3339),
+	}, "\n"))
+}
+
+const shareCertificateSelect = `
+	SELECT sc.id, sc.certificate_number, sc.chama_id, c.name, sc.share_id, sc.member_id,
+		u.first_name, u.last_name, sc.shares_count, sc.share_class, sc.issue_date, sc.status,
+		sc.cancelled_at, sc.cancellation_reason, sc.replaced_by, sc.created_at
+	FROM share_certificates sc
+	JOIN chamas c ON c.id = sc.chama_id
+	JOIN users u ON u.id = sc.member_id`
+
+func scanShareCertificate(row interface{ Scan(...interface{}) error }) (*models.ShareCertificate, error) {
+	certificate := &models.ShareCertificate{}
+	var firstName, lastName string
+	var cancelledAt sql.NullTime
+	var cancellationReason, replacedBy sql.NullString
+	err := row.Scan(&certificate.ID, &certificate.CertificateNumber, &certificate.ChamaID, &certificate.ChamaName,
+		&certificate.ShareID, &certificate.MemberID, &firstName, &lastName, &certificate.SharesCount,
+		&certificate.ShareClass, &certificate.IssueDate, &certificate.Status, &cancelledAt,
+		&cancellationReason, &replacedBy, &certificate.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan share certificate: %w", err)
+	}
+
+	certificate.HolderName = strings.TrimSpace(firstName + " " + lastName)
+	if cancelledAt.Valid {
+		certificate.CancelledAt = &cancelledAt.Time
+	}
+	if cancellationReason.Valid {
+		certificate.CancellationReason = &cancellationReason.String
+	}
+	if replacedBy.Valid {
+		certificate.ReplacedBy = &replacedBy.String
+	}
+	return certificate, nil
+}
+
+// certificateExecutor is satisfied by both *sql.DB and *sql.Tx, so holdings can be
+// synced inside the transaction that changed them
+type certificateExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// syncShareCertificates brings the certificates of the given holdings in line with
+// the shares table. A holding that changed hands, size or class has its certificate
+// cancelled with the given reason and a replacement issued; a holding that was
+// redeemed or transferred away is only cancelled. A holding's first certificate
+// takes the number generated when the shares were bought.
+func syncShareCertificates(exec certificateExecutor, reason string, shareIDs ...string) error {
+	for _, shareID := range shareIDs {
+		var chamaID, memberID, shareClass, status string
+		var sharesOwned int
+		var shareNumber sql.NullString
+		err := exec.QueryRow(`
+			SELECT chama_id, member_id, share_type, shares_owned, status, certificate_number
+			FROM shares WHERE id = ?
+		`, shareID).Scan(&chamaID, &memberID, &shareClass, &sharesOwned, &status, &shareNumber)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get share %s: %w", shareID, err)
+		}
+
+		var current struct {
+			id, number, memberID, shareClass string
+			sharesCount                      int
+		}
+		err = exec.QueryRow(`
+			SELECT id, certificate_number, member_id, share_class, shares_count
+			FROM share_certificates WHERE share_id = ? AND status = 'active'
+		`, shareID).Scan(&current.id, &current.number, &current.memberID, &current.shareClass, &current.sharesCount)
+		hasCurrent := err == nil
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to get certificate for share %s: %w", shareID, err)
+		}
+
+		held := status == string(models.ShareStatusActive) && sharesOwned > 0
+		if hasCurrent && held && current.memberID == memberID && current.sharesCount == sharesOwned && current.shareClass == shareClass {
+			if shareNumber.String != current.number {
+				if _, err := exec.Exec("UPDATE shares SET certificate_number = ? WHERE id = ?", current.number, shareID); err != nil {
+					return fmt.Errorf("failed to update share certificate number: %w", err)
+				}
+			}
+			continue
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+		if hasCurrent {
+			_, err := exec.Exec(`
+				UPDATE share_certificates SET status = 'cancelled', cancelled_at = ?, cancellation_reason = ?
+				WHERE id = ? AND status = 'active'
+			`, now, reason, current.id)
+			if err != nil {
+				return fmt.Errorf("failed to cancel share certificate: %w", err)
+			}
+			log.Printf("Cancelled share certificate %s (%s)", current.number, reason)
+		}
+		if !held {
+			continue
+		}
+
+		number := ""
+		if !hasCurrent && shareNumber.String != "" {
+			var used int
+			if err := exec.QueryRow("SELECT COUNT(*) FROM share_certificates WHERE certificate_number = ?", shareNumber.String).Scan(&used); err != nil {
+				return fmt.Errorf("failed to check certificate number: %w", err)
+			}
+			if used == 0 {
+				number = shareNumber.String
+			}
+		}
+		if number == "" {
+			number = newShareCertificateNumber()
+		}
+
+		certificateID := uuid.New().String()
+		_, err = exec.Exec(`
+			INSERT INTO share_certificates (
+				id, certificate_number, chama_id, share_id, member_id, shares_count,
+				share_class, issue_date, status, created_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'active', ?)
+		`, certificateID, number, chamaID, shareID, memberID, sharesOwned, shareClass, now, now)
+		if err != nil {
+			return fmt.Errorf("failed to issue share certificate: %w", err)
+		}
+		if _, err := exec.Exec("UPDATE shares SET certificate_number = ? WHERE id = ?", number, shareID); err != nil {
+			return fmt.Errorf("failed to update share certificate number: %w", err)
+		}
+		if hasCurrent {
+			if _, err := exec.Exec("UPDATE share_certificates SET replaced_by = ? WHERE id = ?", certificateID, current.id); err != nil {
+				return fmt.Errorf("failed to link replacement certificate: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// splitFixed breaks a long unbroken string, such as a signature, into lines
+func splitFixed(text string, width int) []string {
+	var lines []string
+	for len(text) > width {
+		lines = append(lines, text[:width])
+		text = text[width:]
+	}
+	return append(lines, text)
+}
@@ -0,0 +1,230 @@
+package services_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type ShareCertificateTestSuite struct {
+	suite.Suite
+	testDB  *helpers.TestDatabase
+	db      *sql.DB
+	service *services.ShareCertificateService
+	shares  *services.SharesService
+	chairID string
+	aliceID string
+	bobID   string
+	chamaID string
+}
+
+func (suite *ShareCertificateTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	key, err := services.NewCertificateSigningKey(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	suite.Require().NoError(err)
+	suite.service = services.NewShareCertificateService(suite.db, key, "https://vaultke.test/")
+	suite.shares = services.NewSharesService(suite.db)
+
+	suite.chairID = suite.testDB.AddTestUser(suite.T(), "Chair")
+	suite.aliceID = suite.testDB.AddTestUser(suite.T(), "Alice")
+	suite.bobID = suite.testDB.AddTestUser(suite.T(), "Bob")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.aliceID, "member")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.bobID, "member")
+}
+
+func (suite *ShareCertificateTestSuite) buy(memberID string, count int) *models.Share {
+	share, err := suite.shares.CreateShares(suite.chamaID, &models.CreateShareRequest{
+		MemberID:     memberID,
+		Name:         "Ordinary",
+		ShareType:    models.ShareTypeOrdinary,
+		SharesCount:  count,
+		ShareValue:   100,
+		PurchaseDate: time.Now().AddDate(-1, 0, 0),
+	})
+	suite.Require().NoError(err)
+	return share
+}
+
+func (suite *ShareCertificateTestSuite) seedWallet(ownerID, walletType string, balance float64) {
+	_, err := suite.db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, ?, ?, ?)", uuid.New().String(), walletType, ownerID, balance)
+	suite.Require().NoError(err)
+}
+
+// certificates splits a member's certificates into the active one and the cancelled ones
+func (suite *ShareCertificateTestSuite) certificates(memberID string) (active []models.ShareCertificate, cancelled []models.ShareCertificate) {
+	all, err := suite.service.GetMemberCertificates(suite.chamaID, memberID)
+	suite.Require().NoError(err)
+	for _, certificate := range all {
+		if certificate.Status == models.ShareCertificateActive {
+			active = append(active, certificate)
+		} else {
+			cancelled = append(cancelled, certificate)
+		}
+	}
+	return active, cancelled
+}
+
+func (suite *ShareCertificateTestSuite) TestCertificatesFollowTheHolding() {
+	share := suite.buy(suite.aliceID, 100)
+	suite.Require().NotNil(share.CertificateNumber)
+
+	active, cancelled := suite.certificates(suite.aliceID)
+	suite.Require().Len(active, 1)
+	suite.Empty(cancelled)
+	original := active[0]
+	suite.Equal(*share.CertificateNumber, original.CertificateNumber, "the purchase's certificate number is kept")
+	suite.Equal(100, original.SharesCount)
+	suite.Equal("ordinary", original.ShareClass)
+	suite.Equal("Alice User", original.HolderName)
+	suite.True(strings.HasPrefix(original.VerificationURL, "https://vaultke.test/api/v1/certificates/verify/"+original.CertificateNumber+"?sig="))
+
+	_, err := suite.service.GetMemberCertificates(suite.chamaID, uuid.New().String())
+	suite.Error(err, "only members have certificates")
+
+	// A partial transfer re-issues the seller's certificate and issues the buyer one
+	suite.seedWallet(suite.bobID, "personal", 4000)
+	suite.Require().NoError(suite.shares.TransferShares(suite.chamaID, suite.aliceID, &models.TransferSharesRequest{
+		ShareID:       share.ID,
+		ToMemberID:    suite.bobID,
+		SharesCount:   40,
+		TransferPrice: 100,
+		TotalAmount:   4000,
+		TransferDate:  time.Now(),
+	}))
+	active, cancelled = suite.certificates(suite.aliceID)
+	suite.Require().Len(active, 1)
+	suite.Require().Len(cancelled, 1)
+	suite.Equal(60, active[0].SharesCount)
+	suite.NotEqual(original.CertificateNumber, active[0].CertificateNumber)
+	suite.Equal("shares transferred", *cancelled[0].CancellationReason)
+	suite.Require().NotNil(cancelled[0].ReplacedBy)
+	suite.Equal(active[0].ID, *cancelled[0].ReplacedBy)
+
+	bobActive, _ := suite.certificates(suite.bobID)
+	suite.Require().Len(bobActive, 1)
+	suite.Equal(40, bobActive[0].SharesCount)
+	suite.NotEqual(original.CertificateNumber, bobActive[0].CertificateNumber, "the buyer does not inherit the seller's number")
+
+	// A split re-issues every holding
+	market := services.NewShareMarketService(suite.db)
+	price := 100.0
+	_, err = market.SetSharePrice(suite.chamaID, suite.chairID, &models.SetSharePriceRequest{Source: models.SharePriceSourceManual, Price: &price})
+	suite.Require().NoError(err)
+	_, err = market.SplitShares(suite.chamaID, suite.chairID, &models.SplitSharesRequest{RatioFrom: 1, RatioTo: 2})
+	suite.Require().NoError(err)
+	active, cancelled = suite.certificates(suite.aliceID)
+	suite.Require().Len(active, 1)
+	suite.Len(cancelled, 2)
+	suite.Equal(120, active[0].SharesCount)
+	bobActive, _ = suite.certificates(suite.bobID)
+	suite.Require().Len(bobActive, 1)
+	suite.Equal(80, bobActive[0].SharesCount)
+
+	// Redeeming the whole holding cancels its certificate without a replacement
+	suite.seedWallet(suite.chamaID, "chama", 100000)
+	_, err = market.RedeemShares(suite.chamaID, suite.aliceID, &models.RedeemSharesRequest{ShareID: share.ID, SharesCount: 120})
+	suite.Require().NoError(err)
+	active, cancelled = suite.certificates(suite.aliceID)
+	suite.Empty(active)
+	suite.Len(cancelled, 3)
+
+	var redeemed int
+	suite.Require().NoError(suite.db.QueryRow(`
+		SELECT COUNT(*) FROM share_certificates WHERE share_id = ? AND cancellation_reason = 'shares redeemed' AND replaced_by IS NULL
+	`, share.ID).Scan(&redeemed))
+	suite.Equal(1, redeemed)
+}
+
+func (suite *ShareCertificateTestSuite) TestVerificationRevealsOnlyTheCertificate() {
+	share := suite.buy(suite.aliceID, 100)
+	suite.buy(suite.aliceID, 50)
+
+	active, _ := suite.certificates(suite.aliceID)
+	suite.Require().Len(active, 2)
+	var certificate models.ShareCertificate
+	for _, candidate := range active {
+		if candidate.ShareID == share.ID {
+			certificate = candidate
+		}
+	}
+
+	verification, err := suite.service.Verify(certificate.CertificateNumber, certificate.Signature)
+	suite.Require().NoError(err)
+	suite.True(verification.Valid)
+	suite.Equal(100, verification.SharesCount, "only this certificate's holding is shown")
+	suite.Equal("Alice User", verification.HolderName)
+
+	tampered := []byte(certificate.Signature)
+	tampered[0] ^= 1
+	verification, err = suite.service.Verify(certificate.CertificateNumber, string(tampered))
+	suite.Require().NoError(err)
+	suite.False(verification.Valid)
+	suite.Empty(verification.HolderName, "a bad signature reveals nothing")
+	suite.Zero(verification.SharesCount)
+
+	otherKey, err := services.NewCertificateSigningKey(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+	suite.Require().NoError(err)
+	verification, err = services.NewShareCertificateService(suite.db, otherKey, "").Verify(certificate.CertificateNumber, certificate.Signature)
+	suite.Require().NoError(err)
+	suite.False(verification.Valid, "signatures from another key are rejected")
+
+	verification, err = suite.service.Verify("CERT-0-missing", certificate.Signature)
+	suite.Require().NoError(err)
+	suite.False(verification.Valid)
+	suite.Equal("certificate not found", verification.Reason)
+
+	_, err = services.NewCertificateSigningKey("c2hvcnQ=")
+	suite.Error(err, "a seed must be 32 bytes")
+	_, err = services.NewCertificateSigningKey("")
+	suite.Error(err, "there is no fallback key")
+
+	// Only the holder, officials and admins can see a certificate
+	_, err = suite.service.GetCertificate(certificate.ID, suite.bobID, false)
+	suite.Error(err)
+	_, err = suite.service.GetCertificate(certificate.ID, suite.chairID, false)
+	suite.NoError(err)
+	_, err = suite.service.GetCertificate(certificate.ID, suite.bobID, true)
+	suite.NoError(err)
+
+	fetched, err := suite.service.GetCertificate(certificate.ID, suite.aliceID, false)
+	suite.Require().NoError(err)
+	document, err := suite.service.RenderPDF(fetched)
+	suite.Require().NoError(err)
+	suite.True(bytes.HasPrefix(document, []byte("%PDF-")))
+	suite.True(bytes.HasSuffix(document, []byte("%%EOF\n")))
+	suite.Contains(string(document), "(Alice User)")
+	suite.Contains(string(document), "(100 ordinary shares)")
+	suite.NotContains(string(document), "CANCELLED")
+
+	// Once cancelled, the old certificate verifies as cancelled
+	zero := 0
+	_, err = suite.shares.UpdateShares(share.ID, &models.UpdateShareRequest{SharesOwned: &zero})
+	suite.Require().NoError(err)
+	verification, err = suite.service.Verify(certificate.CertificateNumber, certificate.Signature)
+	suite.Require().NoError(err)
+	suite.False(verification.Valid)
+	suite.Equal(models.ShareCertificateCancelled, verification.Status)
+	suite.NotNil(verification.CancelledAt)
+
+	fetched, err = suite.service.GetCertificate(certificate.ID, suite.aliceID, false)
+	suite.Require().NoError(err)
+	document, err = suite.service.RenderPDF(fetched)
+	suite.Require().NoError(err)
+	suite.Contains(string(document), "(CANCELLED ON ")
+}
+
+func TestShareCertificateSuite(t *testing.T) {
+	suite.Run(t, new(ShareCertificateTestSuite))
+}
@@ -0,0 +1,1118 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"vaultke-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ShareMarketService handles share valuation, redemption, splits and the
+// member-to-member order book
+type ShareMarketService struct {
+	db            *sql.DB
+	sharesService *SharesService
+}
+
+// NewShareMarketService creates a new share market service
+func NewShareMarketService(db *sql.DB) *ShareMarketService {
+	return &ShareMarketService{
+		db:            db,
+		sharesService: NewSharesService(db),
+	}
+}
+
+// SetSharePrice records a new share price, either set by an official or computed from net asset value
+func (s *ShareMarketService) SetSharePrice(chamaID, userID string, req *models.SetSharePriceRequest) (*models.SharePrice, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can set the share price")
+	}
+
+	valuation, err := s.computeValuation(chamaID, req.OtherAssets, req.Liabilities)
+	if err != nil {
+		return nil, err
+	}
+
+	price := &models.SharePrice{
+		ID:                uuid.New().String(),
+		ChamaID:           chamaID,
+		Source:            req.Source,
+		SharesOutstanding: valuation.SharesOutstanding,
+		Notes:             req.Notes,
+		SetBy:             userID,
+		EffectiveAt:       time.Now(),
+	}
+
+	switch req.Source {
+	case models.SharePriceSourceNAV:
+		if valuation.SharesOutstanding == 0 {
+			return nil, fmt.Errorf("there are no shares outstanding to value")
+		}
+		if valuation.NAVPerShare <= 0 {
+			return nil, fmt.Errorf("net asset value must be positive to price shares")
+		}
+		price.Price = valuation.NAVPerShare
+		price.NetAssetValue = &valuation.NetAssetValue
+	case models.SharePriceSourceManual:
+		if req.Price == nil {
+			return nil, fmt.Errorf("price is required when setting the share price manually")
+		}
+		price.Price = *req.Price
+	default:
+		return nil, fmt.Errorf("invalid price source")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.insertPrice(tx, price); err != nil {
+		return nil, err
+	}
+
+	// A price taken from the net asset value also goes into the NAV history
+	if req.Source == models.SharePriceSourceNAV {
+		if err := insertNAVSnapshot(tx, newNAVSnapshot(valuation, userID, req.Notes)); err != nil {
+			return nil, err
+		}
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "share_price",
+		EntityID:   price.ID,
+		Amount:     &price.Price,
+		Details: map[string]interface{}{
+			"source":            price.Source,
+			"sharesOutstanding": price.SharesOutstanding,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Share price for chama %s set to %.2f (%s) by %s", chamaID, price.Price, price.Source, userID)
+	return price, nil
+}
+
+// GetCurrentPrice returns the latest share price for a chama
+func (s *ShareMarketService) GetCurrentPrice(chamaID, userID string) (*models.SharePrice, error) {
+	if !s.sharesService.isMemberOfChama(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	price, err := s.currentPrice(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	if price == nil {
+		return nil, fmt.Errorf("no share price has been set for this chama")
+	}
+
+	return price, nil
+}
+
+// GetPriceHistory returns a chama's share prices, newest first
+func (s *ShareMarketService) GetPriceHistory(chamaID, userID string, limit, offset int) ([]models.SharePrice, error) {
+	if !s.sharesService.isMemberOfChama(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, chama_id, price, source, net_asset_value, shares_outstanding, notes, set_by, effective_at
+		FROM share_prices
+		WHERE chama_id = ?
+		ORDER BY effective_at DESC
+		LIMIT ? OFFSET ?
+	`, chamaID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share price history: %w", err)
+	}
+	defer rows.Close()
+
+	prices := []models.SharePrice{}
+	for rows.Next() {
+		price, err := scanSharePrice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan share price: %w", err)
+		}
+		prices = append(prices, *price)
+	}
+
+	return prices, nil
+}
+
+// GetValuation returns the chama's net asset value per share.
+// otherAssets and liabilities are optional figures held outside the platform.
+func (s *ShareMarketService) GetValuation(chamaID, userID string, otherAssets, liabilities *float64) (*models.ShareValuation, error) {
+	if !s.sharesService.isMemberOfChama(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	valuation, err := s.computeValuation(chamaID, otherAssets, liabilities)
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := s.currentPrice(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	if price != nil {
+		valuation.CurrentPrice = &price.Price
+	}
+
+	return valuation, nil
+}
+
+// GetSettings returns a chama's share exit settings
+func (s *ShareMarketService) GetSettings(chamaID, userID string) (*models.ShareMarketSettings, error) {
+	if !s.sharesService.isMemberOfChama(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	return s.getSettings(chamaID)
+}
+
+// UpdateSettings changes a chama's lock-in period and which exits are open
+func (s *ShareMarketService) UpdateSettings(chamaID, userID string, req *models.UpdateShareMarketSettingsRequest) (*models.ShareMarketSettings, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can change share market settings")
+	}
+
+	settings, err := s.getSettings(chamaID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.LockInDays != nil {
+		settings.LockInDays = *req.LockInDays
+	}
+	if req.RedemptionEnabled != nil {
+		settings.RedemptionEnabled = *req.RedemptionEnabled
+	}
+	if req.SecondaryMarketEnabled != nil {
+		settings.SecondaryMarketEnabled = *req.SecondaryMarketEnabled
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = tx.Exec(`
+		INSERT INTO share_market_settings (chama_id, lock_in_days, redemption_enabled, secondary_market_enabled, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(chama_id) DO UPDATE SET
+			lock_in_days = excluded.lock_in_days,
+			redemption_enabled = excluded.redemption_enabled,
+			secondary_market_enabled = excluded.secondary_market_enabled,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`, chamaID, settings.LockInDays, settings.RedemptionEnabled, settings.SecondaryMarketEnabled, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update share market settings: %w", err)
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "share_market_settings",
+		EntityID:   chamaID,
+		Details: map[string]interface{}{
+			"lockInDays":             settings.LockInDays,
+			"redemptionEnabled":      settings.RedemptionEnabled,
+			"secondaryMarketEnabled": settings.SecondaryMarketEnabled,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	settings.UpdatedBy = &userID
+	settings.UpdatedAt = &now
+	return settings, nil
+}
+
+// RedeemShares sells a member's shares back to the chama at the current price,
+// paying the member from the chama wallet
+func (s *ShareMarketService) RedeemShares(chamaID, userID string, req *models.RedeemSharesRequest) (*models.ShareRedemption, error) {
+	settings, err := s.getSettings(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.RedemptionEnabled {
+		return nil, fmt.Errorf("share redemption is not enabled for this chama")
+	}
+
+	share, err := s.sellableShare(chamaID, userID, req.ShareID, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	available, err := s.availableShares(share)
+	if err != nil {
+		return nil, err
+	}
+	if req.SharesCount > available {
+		return nil, fmt.Errorf("only %d shares are available to redeem", available)
+	}
+
+	pricePerShare := share.ShareValue
+	current, err := s.currentPrice(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		pricePerShare = current.Price
+	}
+	totalAmount := math.Round(float64(req.SharesCount)*pricePerShare*100) / 100
+
+	// The payout leaves the chama wallet, so it is screened like any other chama outflow
+	evaluation, err := screenOutgoing(s.db, &models.FraudCheck{
+		UserID:          userID,
+		ChamaID:         chamaID,
+		TransactionType: models.TransactionType("share_redemption"),
+		Amount:          totalAmount,
+		RecipientID:     userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The shares are taken first so concurrent redemptions can't both be paid for them
+	now := time.Now()
+	sharesLeft, err := takeShares(tx, share.ID, userID, req.SharesCount, models.ShareStatusRedeemed)
+	if err != nil {
+		return nil, err
+	}
+
+	fromWalletID, err := s.deductFromChamaWallet(tx, chamaID, totalAmount)
+	if err != nil {
+		return nil, err
+	}
+	toWalletID, err := creditPersonalWallet(tx, userID, totalAmount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to credit member: %w", err)
+	}
+
+	if err := syncShareCertificates(tx, "shares redeemed", share.ID); err != nil {
+		return nil, err
+	}
+
+	description := fmt.Sprintf("Redeemed %d shares at %.2f", req.SharesCount, pricePerShare)
+	err = s.sharesService.createShareTransactionInTx(tx, chamaID, &models.CreateShareTransactionRequest{
+		FromMemberID:    &userID,
+		TransactionType: models.ShareTransactionRedemption,
+		SharesCount:     req.SharesCount,
+		ShareValue:      pricePerShare,
+		TransactionDate: now,
+		Description:     &description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record share transaction: %w", err)
+	}
+
+	transactionID := uuid.New().String()
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"chamaId":       chamaID,
+		"shareId":       share.ID,
+		"quantity":      req.SharesCount,
+		"pricePerShare": pricePerShare,
+	})
+	_, err = tx.Exec(`
+		INSERT INTO transactions (
+			id, from_wallet_id, to_wallet_id, type, amount, currency, description, status, payment_method,
+			initiated_by, recipient_id, metadata, created_at, updated_at
+		) VALUES (?, ?, ?, 'share_redemption', ?, 'KES', ?, 'completed', 'wallet', ?, ?, ?, ?, ?)
+	`, transactionID, fromWalletID, toWalletID, totalAmount, description, userID, userID, string(metadata), now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record wallet transaction: %w", err)
+	}
+
+	if err := NewAuditService(s.db).RecordTransaction(tx, chamaID, transactionID); err != nil {
+		return nil, err
+	}
+	if err := recordFraudEvaluation(tx, evaluation, transactionID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("User %s redeemed %d shares in chama %s for %.2f", userID, req.SharesCount, chamaID, totalAmount)
+	return &models.ShareRedemption{
+		ShareID:       share.ID,
+		SharesCount:   req.SharesCount,
+		PricePerShare: pricePerShare,
+		TotalAmount:   totalAmount,
+		SharesLeft:    sharesLeft,
+		TransactionID: transactionID,
+		RedeemedAt:    now,
+	}, nil
+}
+
+// SplitShares multiplies every active holding by RatioTo/RatioFrom and divides the
+// share value and price by the same ratio, so each member's stake is unchanged
+func (s *ShareMarketService) SplitShares(chamaID, userID string, req *models.SplitSharesRequest) (*models.ShareSplit, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can split shares")
+	}
+	if req.RatioFrom == req.RatioTo {
+		return nil, fmt.Errorf("split ratio must change the number of shares")
+	}
+
+	type holding struct {
+		id       string
+		memberID string
+		owned    int
+		value    float64
+	}
+
+	rows, err := s.db.Query("SELECT id, member_id, shares_owned, share_value FROM shares WHERE chama_id = ? AND status = 'active'", chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shares: %w", err)
+	}
+	var holdings []holding
+	for rows.Next() {
+		var h holding
+		if err := rows.Scan(&h.id, &h.memberID, &h.owned, &h.value); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan share: %w", err)
+		}
+		holdings = append(holdings, h)
+	}
+	rows.Close()
+
+	if len(holdings) == 0 {
+		return nil, fmt.Errorf("there are no active shares to split")
+	}
+
+	split := &models.ShareSplit{
+		ID:         uuid.New().String(),
+		ChamaID:    chamaID,
+		RatioFrom:  req.RatioFrom,
+		RatioTo:    req.RatioTo,
+		ExecutedBy: userID,
+		Notes:      req.Notes,
+		CreatedAt:  time.Now(),
+	}
+	for _, h := range holdings {
+		if (h.owned*req.RatioTo)%req.RatioFrom != 0 {
+			return nil, fmt.Errorf("a %d-for-%d split would leave fractional shares", req.RatioTo, req.RatioFrom)
+		}
+		split.SharesBefore += h.owned
+		split.SharesAfter += h.owned * req.RatioTo / req.RatioFrom
+	}
+
+	var fractionalListings int
+	err = s.db.QueryRow(`
+		SELECT COUNT(*) FROM share_listings
+		WHERE chama_id = ? AND status = 'open' AND ((remaining_quantity * ?) % ? != 0 OR (quantity * ?) % ? != 0)
+	`, chamaID, req.RatioTo, req.RatioFrom, req.RatioTo, req.RatioFrom).Scan(&fractionalListings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check open listings: %w", err)
+	}
+	if fractionalListings > 0 {
+		return nil, fmt.Errorf("a %d-for-%d split would leave open listings with fractional shares", req.RatioTo, req.RatioFrom)
+	}
+
+	current, err := s.currentPrice(chamaID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	factor := float64(req.RatioTo) / float64(req.RatioFrom)
+	description := fmt.Sprintf("%d-for-%d share split", req.RatioTo, req.RatioFrom)
+	for _, h := range holdings {
+		owned := h.owned * req.RatioTo / req.RatioFrom
+		value := h.value / factor
+		_, err = tx.Exec("UPDATE shares SET shares_owned = ?, share_value = ?, total_value = ?, updated_at = ? WHERE id = ?",
+			owned, value, float64(owned)*value, split.CreatedAt, h.id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split share record: %w", err)
+		}
+
+		// Record the shares the split added, or a consolidation removed, so the
+		// share register can be replayed to any earlier date
+		memberID := h.memberID
+		movement := &models.CreateShareTransactionRequest{
+			TransactionType: models.ShareTransactionSplit,
+			ShareValue:      value,
+			TransactionDate: split.CreatedAt,
+			Description:     &description,
+		}
+		if owned >= h.owned {
+			movement.ToMemberID = &memberID
+			movement.SharesCount = owned - h.owned
+		} else {
+			movement.FromMemberID = &memberID
+			movement.SharesCount = h.owned - owned
+		}
+		err = s.sharesService.createShareTransactionInTx(tx, chamaID, movement)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record share transaction: %w", err)
+		}
+
+		if err := syncShareCertificates(tx, description, h.id); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE share_listings
+		SET quantity = quantity * ? / ?, remaining_quantity = remaining_quantity * ? / ?,
+			price_per_share = price_per_share / ?, updated_at = ?
+		WHERE chama_id = ? AND status = 'open'
+	`, req.RatioTo, req.RatioFrom, req.RatioTo, req.RatioFrom, factor, split.CreatedAt, chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to adjust open listings: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO share_splits (id, chama_id, ratio_from, ratio_to, shares_before, shares_after, executed_by, notes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, split.ID, chamaID, split.RatioFrom, split.RatioTo, split.SharesBefore, split.SharesAfter, userID, split.Notes, split.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record share split: %w", err)
+	}
+
+	if current != nil {
+		err = s.insertPrice(tx, &models.SharePrice{
+			ID:                uuid.New().String(),
+			ChamaID:           chamaID,
+			Price:             current.Price / factor,
+			Source:            models.SharePriceSourceSplit,
+			NetAssetValue:     current.NetAssetValue,
+			SharesOutstanding: split.SharesAfter,
+			Notes:             &description,
+			SetBy:             userID,
+			EffectiveAt:       split.CreatedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "share_split",
+		EntityID:   split.ID,
+		Details: map[string]interface{}{
+			"ratioFrom":    split.RatioFrom,
+			"ratioTo":      split.RatioTo,
+			"sharesBefore": split.SharesBefore,
+			"sharesAfter":  split.SharesAfter,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	notified := make(map[string]bool)
+	notificationService := NewNotificationService(s.db, nil)
+	message := fmt.Sprintf("Your chama has carried out a %s. Your holdings and share value have been adjusted; the value of your stake is unchanged.", description)
+	for _, h := range holdings {
+		if notified[h.memberID] {
+			continue
+		}
+		notified[h.memberID] = true
+		if err := notificationService.CreateInAppNotification(h.memberID, "chama", "shares", "Share Split", message, map[string]interface{}{
+			"chamaId": chamaID,
+			"splitId": split.ID,
+		}); err != nil {
+			log.Printf("Failed to notify member %s of share split: %v", h.memberID, err)
+		}
+	}
+
+	log.Printf("Split shares in chama %s %d-for-%d: %d -> %d shares", chamaID, req.RatioTo, req.RatioFrom, split.SharesBefore, split.SharesAfter)
+	return split, nil
+}
+
+// CreateListing offers some of a member's shares for sale to other members
+func (s *ShareMarketService) CreateListing(chamaID, userID string, req *models.CreateShareListingRequest) (*models.ShareListing, error) {
+	settings, err := s.getSettings(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.SecondaryMarketEnabled {
+		return nil, fmt.Errorf("share trading between members is not enabled for this chama")
+	}
+
+	share, err := s.sellableShare(chamaID, userID, req.ShareID, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	available, err := s.availableShares(share)
+	if err != nil {
+		return nil, err
+	}
+	if req.Quantity > available {
+		return nil, fmt.Errorf("only %d shares are available to list", available)
+	}
+
+	now := time.Now()
+	listing := &models.ShareListing{
+		ID:                uuid.New().String(),
+		ChamaID:           chamaID,
+		SellerID:          userID,
+		ShareID:           share.ID,
+		ShareName:         share.Name,
+		Quantity:          req.Quantity,
+		RemainingQuantity: req.Quantity,
+		PricePerShare:     req.PricePerShare,
+		Status:            models.ShareListingStatusOpen,
+		Notes:             req.Notes,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO share_listings (id, chama_id, seller_id, share_id, quantity, remaining_quantity, price_per_share, status, notes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, listing.ID, chamaID, userID, share.ID, listing.Quantity, listing.RemainingQuantity, listing.PricePerShare,
+		listing.Status, listing.Notes, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create share listing: %w", err)
+	}
+
+	log.Printf("User %s listed %d shares for sale in chama %s at %.2f", userID, req.Quantity, chamaID, req.PricePerShare)
+	return listing, nil
+}
+
+// GetListings returns a chama's order book, cheapest first (?status= defaults to open)
+func (s *ShareMarketService) GetListings(chamaID, userID, status string, limit, offset int) ([]models.ShareListing, error) {
+	if !s.sharesService.isMemberOfChama(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	if status == "" {
+		status = string(models.ShareListingStatusOpen)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT l.id, l.chama_id, l.seller_id, COALESCE(u.first_name || ' ' || u.last_name, ''), l.share_id,
+			   COALESCE(sh.name, ''), l.quantity, l.remaining_quantity, l.price_per_share, l.status, l.notes,
+			   l.created_at, l.updated_at
+		FROM share_listings l
+		LEFT JOIN users u ON u.id = l.seller_id
+		LEFT JOIN shares sh ON sh.id = l.share_id
+		WHERE l.chama_id = ? AND l.status = ?
+		ORDER BY l.price_per_share ASC, l.created_at ASC
+		LIMIT ? OFFSET ?
+	`, chamaID, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share listings: %w", err)
+	}
+	defer rows.Close()
+
+	listings := []models.ShareListing{}
+	for rows.Next() {
+		var listing models.ShareListing
+		if err := rows.Scan(&listing.ID, &listing.ChamaID, &listing.SellerID, &listing.SellerName, &listing.ShareID,
+			&listing.ShareName, &listing.Quantity, &listing.RemainingQuantity, &listing.PricePerShare, &listing.Status,
+			&listing.Notes, &listing.CreatedAt, &listing.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan share listing: %w", err)
+		}
+		listings = append(listings, listing)
+	}
+
+	return listings, nil
+}
+
+// CancelListing withdraws the unsold part of a listing. Sellers and officials can cancel.
+func (s *ShareMarketService) CancelListing(chamaID, listingID, userID string) (*models.ShareListing, error) {
+	listing, err := s.getListing(listingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.ChamaID != chamaID {
+		return nil, fmt.Errorf("share listing does not belong to this chama")
+	}
+	if listing.SellerID != userID && !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only the seller or a chama official can cancel this listing")
+	}
+
+	now := time.Now()
+	result, err := s.db.Exec("UPDATE share_listings SET status = ?, updated_at = ? WHERE id = ? AND status = ?",
+		models.ShareListingStatusCancelled, now, listingID, models.ShareListingStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel share listing: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("share listing is no longer open")
+	}
+
+	listing.Status = models.ShareListingStatusCancelled
+	listing.UpdatedAt = now
+	return listing, nil
+}
+
+// BuyFromListing buys shares from another member's listing, settling wallet to wallet
+func (s *ShareMarketService) BuyFromListing(chamaID, listingID, buyerID string, req *models.BuyShareListingRequest) (*models.Share, error) {
+	listing, err := s.getListing(listingID)
+	if err != nil {
+		return nil, err
+	}
+	if listing.ChamaID != chamaID {
+		return nil, fmt.Errorf("share listing does not belong to this chama")
+	}
+	if listing.Status != models.ShareListingStatusOpen {
+		return nil, fmt.Errorf("share listing is no longer open")
+	}
+	if listing.SellerID == buyerID {
+		return nil, fmt.Errorf("you cannot buy your own shares")
+	}
+	if !s.sharesService.isMemberOfChama(buyerID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	if req.Quantity > listing.RemainingQuantity {
+		return nil, fmt.Errorf("only %d shares are left on this listing", listing.RemainingQuantity)
+	}
+
+	settings, err := s.getSettings(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.SecondaryMarketEnabled {
+		return nil, fmt.Errorf("share trading between members is not enabled for this chama")
+	}
+
+	share, err := s.sharesService.getShareByID(listing.ShareID)
+	if err != nil {
+		return nil, err
+	}
+	if share.MemberID != listing.SellerID || !share.CanTransfer() || share.SharesOwned < req.Quantity {
+		return nil, fmt.Errorf("the seller no longer holds these shares")
+	}
+
+	totalAmount := math.Round(float64(req.Quantity)*listing.PricePerShare*100) / 100
+
+	evaluation, err := screenOutgoing(s.db, &models.FraudCheck{
+		UserID:          buyerID,
+		TransactionType: models.TransactionType("share_transfer"),
+		Amount:          totalAmount,
+		RecipientID:     listing.SellerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE share_listings
+		SET remaining_quantity = remaining_quantity - ?,
+			status = CASE WHEN remaining_quantity - ? = 0 THEN ? ELSE status END,
+			updated_at = ?
+		WHERE id = ? AND status = ? AND remaining_quantity >= ?
+	`, req.Quantity, req.Quantity, models.ShareListingStatusFilled, now, listingID, models.ShareListingStatusOpen, req.Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update share listing: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("share listing is no longer open")
+	}
+
+	if err := reserveTransactionLimit(tx, buyerID, listingID, totalAmount); err != nil {
+		return nil, err
+	}
+	fromWalletID, err := debitPersonalWallet(tx, buyerID, totalAmount)
+	if err != nil {
+		return nil, fmt.Errorf("payment failed: %w", err)
+	}
+	toWalletID, err := creditPersonalWallet(tx, listing.SellerID, totalAmount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to credit seller: %w", err)
+	}
+
+	if _, err := takeShares(tx, share.ID, listing.SellerID, req.Quantity, models.ShareStatusTransferred); err != nil {
+		return nil, err
+	}
+
+	certificateNumber := s.sharesService.generateCertificateNumber()
+	bought := &models.Share{
+		ID:                uuid.New().String(),
+		ChamaID:           chamaID,
+		MemberID:          buyerID,
+		Name:              share.Name,
+		ShareType:         share.ShareType,
+		SharesOwned:       req.Quantity,
+		ShareValue:        listing.PricePerShare,
+		PurchaseDate:      now,
+		CertificateNumber: &certificateNumber,
+		Status:            models.ShareStatusActive,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	bought.CalculateTotalValue()
+
+	_, err = tx.Exec(`
+		INSERT INTO shares (
+			id, chama_id, member_id, name, share_type, shares_owned, share_value,
+			total_value, purchase_date, certificate_number, status, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, bought.ID, bought.ChamaID, bought.MemberID, bought.Name, bought.ShareType, bought.SharesOwned,
+		bought.ShareValue, bought.TotalValue, bought.PurchaseDate, bought.CertificateNumber,
+		bought.Status, bought.CreatedAt, bought.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create share record for buyer: %w", err)
+	}
+
+	notes := "Secondary market purchase"
+	err = s.sharesService.createShareTransactionInTx(tx, chamaID, &models.CreateShareTransactionRequest{
+		FromMemberID:    &listing.SellerID,
+		ToMemberID:      &buyerID,
+		TransactionType: models.ShareTransactionTransfer,
+		SharesCount:     req.Quantity,
+		ShareValue:      listing.PricePerShare,
+		TransactionDate: now,
+		Description:     &notes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record share transaction: %w", err)
+	}
+
+	transactionID := uuid.New().String()
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"transferType": "share_transfer",
+		"fromUserId":   listing.SellerID,
+		"toUserId":     buyerID,
+		"chamaId":      chamaID,
+		"listingId":    listingID,
+		"notes":        notes,
+	})
+	_, err = tx.Exec(`
+		INSERT INTO transactions (
+			id, from_wallet_id, to_wallet_id, type, amount, currency, description, status, payment_method,
+			initiated_by, recipient_id, metadata, created_at, updated_at
+		) VALUES (?, ?, ?, 'share_transfer', ?, 'KES', ?, 'completed', 'wallet', ?, ?, ?, ?, ?)
+	`, transactionID, fromWalletID, toWalletID, totalAmount, "Share transfer: "+notes, buyerID, listing.SellerID, string(metadata), now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record wallet transaction: %w", err)
+	}
+
+	if err := NewAuditService(s.db).RecordTransaction(tx, chamaID, transactionID); err != nil {
+		return nil, err
+	}
+	if err := recordFraudEvaluation(tx, evaluation, transactionID); err != nil {
+		return nil, err
+	}
+
+	if err := syncShareCertificates(tx, "shares sold on the secondary market", share.ID, bought.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	message := fmt.Sprintf("%d of your listed shares were bought for KES %.2f, which has been credited to your wallet.", req.Quantity, totalAmount)
+	if err := NewNotificationService(s.db, nil).CreateInAppNotification(listing.SellerID, "transaction", "shares", "Shares Sold", message, map[string]interface{}{
+		"chamaId":   chamaID,
+		"listingId": listingID,
+		"amount":    totalAmount,
+	}); err != nil {
+		log.Printf("Failed to notify seller %s of share sale: %v", listing.SellerID, err)
+	}
+
+	log.Printf("User %s bought %d shares from listing %s in chama %s", buyerID, req.Quantity, listingID, chamaID)
+	return bought, nil
+}
+
+// Helper functions
+
+// computeValuation values the chama as its wallet balance plus outstanding loans
+// and any other assets, less liabilities, spread over active shares
+func (s *ShareMarketService) computeValuation(chamaID string, otherAssets, liabilities *float64) (*models.ShareValuation, error) {
+	valuation := &models.ShareValuation{ChamaID: chamaID}
+
+	err := s.db.QueryRow("SELECT COALESCE(SUM(balance), 0) FROM wallets WHERE owner_id = ? AND type = 'chama'", chamaID).Scan(&valuation.CashBalance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chama wallet balance: %w", err)
+	}
+
+	err = s.db.QueryRow(`
+		SELECT COALESCE(SUM(remaining_amount), 0) FROM loans
+		WHERE chama_id = ? AND status IN ('active', 'disbursed')
+	`, chamaID).Scan(&valuation.LoanBook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outstanding loans: %w", err)
+	}
+
+	err = s.db.QueryRow("SELECT COALESCE(SUM(current_value), 0) FROM portfolio_assets WHERE chama_id = ? AND status = 'active'", chamaID).Scan(&valuation.PortfolioValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio value: %w", err)
+	}
+
+	err = s.db.QueryRow("SELECT COALESCE(SUM(shares_owned), 0) FROM shares WHERE chama_id = ? AND status = 'active'", chamaID).Scan(&valuation.SharesOutstanding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count shares outstanding: %w", err)
+	}
+
+	if otherAssets != nil {
+		valuation.OtherAssets = *otherAssets
+	}
+	if liabilities != nil {
+		valuation.Liabilities = *liabilities
+	}
+
+	valuation.NetAssetValue = valuation.CashBalance + valuation.LoanBook + valuation.PortfolioValue + valuation.OtherAssets - valuation.Liabilities
+	if valuation.SharesOutstanding > 0 {
+		valuation.NAVPerShare = math.Round(valuation.NetAssetValue/float64(valuation.SharesOutstanding)*100) / 100
+	}
+
+	return valuation, nil
+}
+
+func (s *ShareMarketService) currentPrice(chamaID string) (*models.SharePrice, error) {
+	row := s.db.QueryRow(`
+		SELECT id, chama_id, price, source, net_asset_value, shares_outstanding, notes, set_by, effective_at
+		FROM share_prices
+		WHERE chama_id = ?
+		ORDER BY effective_at DESC
+		LIMIT 1
+	`, chamaID)
+
+	price, err := scanSharePrice(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current share price: %w", err)
+	}
+
+	return price, nil
+}
+
+func (s *ShareMarketService) insertPrice(exec interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}, price *models.SharePrice) error {
+	_, err := exec.Exec(`
+		INSERT INTO share_prices (id, chama_id, price, source, net_asset_value, shares_outstanding, notes, set_by, effective_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, price.ID, price.ChamaID, price.Price, price.Source, price.NetAssetValue, price.SharesOutstanding,
+		price.Notes, price.SetBy, price.EffectiveAt)
+	if err != nil {
+		return fmt.Errorf("failed to record share price: %w", err)
+	}
+	return nil
+}
+
+func scanSharePrice(row interface{ Scan(...interface{}) error }) (*models.SharePrice, error) {
+	var price models.SharePrice
+	var nav sql.NullFloat64
+	var notes sql.NullString
+	err := row.Scan(&price.ID, &price.ChamaID, &price.Price, &price.Source, &nav, &price.SharesOutstanding,
+		&notes, &price.SetBy, &price.EffectiveAt)
+	if err != nil {
+		return nil, err
+	}
+	if nav.Valid {
+		price.NetAssetValue = &nav.Float64
+	}
+	if notes.Valid {
+		price.Notes = &notes.String
+	}
+	return &price, nil
+}
+
+func (s *ShareMarketService) getSettings(chamaID string) (*models.ShareMarketSettings, error) {
+	settings := &models.ShareMarketSettings{
+		ChamaID:                chamaID,
+		RedemptionEnabled:      true,
+		SecondaryMarketEnabled: true,
+	}
+
+	var updatedBy sql.NullString
+	var updatedAt sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT lock_in_days, redemption_enabled, secondary_market_enabled, updated_by, updated_at
+		FROM share_market_settings WHERE chama_id = ?
+	`, chamaID).Scan(&settings.LockInDays, &settings.RedemptionEnabled, &settings.SecondaryMarketEnabled, &updatedBy, &updatedAt)
+	if err == sql.ErrNoRows {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share market settings: %w", err)
+	}
+
+	if updatedBy.Valid {
+		settings.UpdatedBy = &updatedBy.String
+	}
+	if updatedAt.Valid {
+		settings.UpdatedAt = &updatedAt.Time
+	}
+	return settings, nil
+}
+
+// sellableShare loads a member's share record and checks it is past the lock-in period
+func (s *ShareMarketService) sellableShare(chamaID, userID, shareID string, settings *models.ShareMarketSettings) (*models.Share, error) {
+	if !s.sharesService.isMemberOfChama(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	share, err := s.sharesService.getShareByID(shareID)
+	if err != nil {
+		return nil, err
+	}
+	if share.ChamaID != chamaID {
+		return nil, fmt.Errorf("share does not belong to this chama")
+	}
+	if share.MemberID != userID {
+		return nil, fmt.Errorf("share does not belong to this member")
+	}
+	if !share.CanRedeem() {
+		return nil, fmt.Errorf("share is not active")
+	}
+
+	if settings.LockInDays > 0 {
+		unlocksAt := share.PurchaseDate.AddDate(0, 0, settings.LockInDays)
+		if time.Now().Before(unlocksAt) {
+			return nil, fmt.Errorf("shares are locked in until %s", unlocksAt.Format("02 Jan 2006"))
+		}
+	}
+
+	return share, nil
+}
+
+// availableShares is what a share record holds less what is already listed for sale
+func (s *ShareMarketService) availableShares(share *models.Share) (int, error) {
+	var listed int
+	err := s.db.QueryRow("SELECT COALESCE(SUM(remaining_quantity), 0) FROM share_listings WHERE share_id = ? AND status = 'open'", share.ID).Scan(&listed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check listed shares: %w", err)
+	}
+	return share.SharesOwned - listed, nil
+}
+
+func (s *ShareMarketService) getListing(listingID string) (*models.ShareListing, error) {
+	var listing models.ShareListing
+	err := s.db.QueryRow(`
+		SELECT id, chama_id, seller_id, share_id, quantity, remaining_quantity, price_per_share, status, notes, created_at, updated_at
+		FROM share_listings WHERE id = ?
+	`, listingID).Scan(&listing.ID, &listing.ChamaID, &listing.SellerID, &listing.ShareID, &listing.Quantity,
+		&listing.RemainingQuantity, &listing.PricePerShare, &listing.Status, &listing.Notes, &listing.CreatedAt, &listing.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("share listing not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share listing: %w", err)
+	}
+	return &listing, nil
+}
+
+// takeShares removes quantity from a member's active share record inside tx. Shares
+// still listed for sale are held back, and a record that empties is closed with
+// closedStatus. It returns how many shares the record has left.
+func takeShares(tx *sql.Tx, shareID, memberID string, quantity int, closedStatus models.ShareStatus) (int, error) {
+	result, err := tx.Exec(`
+		UPDATE shares
+		SET shares_owned = CASE WHEN shares_owned = ? THEN shares_owned ELSE shares_owned - ? END,
+			total_value = CASE WHEN shares_owned = ? THEN total_value ELSE (shares_owned - ?) * share_value END,
+			status = CASE WHEN shares_owned = ? THEN ? ELSE status END,
+			updated_at = ?
+		WHERE id = ? AND member_id = ? AND status = 'active'
+		AND shares_owned - ? >= (
+			SELECT COALESCE(SUM(remaining_quantity), 0) FROM share_listings WHERE share_id = ? AND status = 'open'
+		)
+	`, quantity, quantity, quantity, quantity, quantity, closedStatus, time.Now(), shareID, memberID, quantity, shareID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update share record: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected != 1 {
+		return 0, fmt.Errorf("these shares are no longer available")
+	}
+
+	var left int
+	var status models.ShareStatus
+	if err := tx.QueryRow("SELECT shares_owned, status FROM shares WHERE id = ?", shareID).Scan(&left, &status); err != nil {
+		return 0, fmt.Errorf("failed to read share record: %w", err)
+	}
+	if status == closedStatus {
+		return 0, nil
+	}
+	return left, nil
+}
+
+// deductFromChamaWallet pays amount out of a chama's wallet inside tx, keeps
+// chamas.total_funds in step and returns the wallet ID
+func (s *ShareMarketService) deductFromChamaWallet(tx *sql.Tx, chamaID string, amount float64) (string, error) {
+	var walletID string
+	var balance float64
+	err := tx.QueryRow("SELECT id, balance FROM wallets WHERE owner_id = ? AND type = 'chama'", chamaID).Scan(&walletID, &balance)
+	if err == sql.ErrNoRows || (err == nil && balance < amount) {
+		return "", fmt.Errorf("the chama does not have enough funds to redeem these shares")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check chama wallet balance: %w", err)
+	}
+
+	result, err := tx.Exec("UPDATE wallets SET balance = balance - ?, updated_at = ? WHERE id = ? AND balance >= ?",
+		amount, time.Now(), walletID, amount)
+	if err != nil {
+		return "", fmt.Errorf("failed to deduct from chama wallet: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected != 1 {
+		return "", fmt.Errorf("the chama does not have enough funds to redeem these shares")
+	}
+	if err := syncChamaFunds(tx, chamaID); err != nil {
+		return "", err
+	}
+	return walletID, nil
+}
+
+func (s *ShareMarketService) isOfficial(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM chama_members
+		WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+		AND role IN ('chairperson', 'secretary', 'treasurer')
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
@@ -0,0 +1,287 @@
+package services_test
+
+import (
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type ShareMarketTestSuite struct {
+	suite.Suite
+	testDB   *helpers.TestDatabase
+	db       *sql.DB
+	service  *services.ShareMarketService
+	chamaID  string
+	chairID  string
+	sellerID string
+	buyerID  string
+}
+
+func (suite *ShareMarketTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewShareMarketService(suite.db)
+
+	suite.chairID = suite.testDB.AddTestUser(suite.T(), "Chair")
+	suite.sellerID = suite.testDB.AddTestUser(suite.T(), "Seller")
+	suite.buyerID = suite.testDB.AddTestUser(suite.T(), "Buyer")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.sellerID, "member")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.buyerID, "member")
+}
+
+func (suite *ShareMarketTestSuite) seedShares(memberID string, owned int, value float64, purchased time.Time) string {
+	id := uuid.New().String()
+	_, err := suite.db.Exec(`
+		INSERT INTO shares (id, chama_id, member_id, name, share_type, shares_owned, share_value, total_value, purchase_date, status)
+		VALUES (?, ?, ?, 'Ordinary', 'ordinary', ?, ?, ?, ?, 'active')
+	`, id, suite.chamaID, memberID, owned, value, float64(owned)*value, purchased)
+	suite.Require().NoError(err)
+	return id
+}
+
+func (suite *ShareMarketTestSuite) seedWallet(ownerID, walletType string, balance float64) {
+	_, err := suite.db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, ?, ?, ?)", uuid.New().String(), walletType, ownerID, balance)
+	suite.Require().NoError(err)
+}
+
+func (suite *ShareMarketTestSuite) balance(ownerID, walletType string) float64 {
+	var balance float64
+	suite.Require().NoError(suite.db.QueryRow("SELECT balance FROM wallets WHERE owner_id = ? AND type = ?", ownerID, walletType).Scan(&balance))
+	return balance
+}
+
+func (suite *ShareMarketTestSuite) TestPriceFromNetAssetValue() {
+	suite.seedShares(suite.sellerID, 60, 100, time.Now())
+	suite.seedShares(suite.buyerID, 40, 100, time.Now())
+	suite.seedWallet(suite.chamaID, "chama", 12000)
+
+	_, err := suite.service.SetSharePrice(suite.chamaID, suite.sellerID, &models.SetSharePriceRequest{Source: models.SharePriceSourceNAV})
+	suite.Error(err, "only officials set the price")
+
+	liabilities := 2000.0
+	price, err := suite.service.SetSharePrice(suite.chamaID, suite.chairID, &models.SetSharePriceRequest{
+		Source:      models.SharePriceSourceNAV,
+		Liabilities: &liabilities,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(100.0, price.Price, "(12000 - 2000) / 100 shares")
+	suite.Equal(100, price.SharesOutstanding)
+
+	manual := 125.0
+	_, err = suite.service.SetSharePrice(suite.chamaID, suite.chairID, &models.SetSharePriceRequest{Source: models.SharePriceSourceManual, Price: &manual})
+	suite.Require().NoError(err)
+
+	current, err := suite.service.GetCurrentPrice(suite.chamaID, suite.buyerID)
+	suite.Require().NoError(err)
+	suite.Equal(125.0, current.Price)
+
+	history, err := suite.service.GetPriceHistory(suite.chamaID, suite.buyerID, 50, 0)
+	suite.Require().NoError(err)
+	suite.Len(history, 2)
+
+	valuation, err := suite.service.GetValuation(suite.chamaID, suite.buyerID, nil, nil)
+	suite.Require().NoError(err)
+	suite.Equal(120.0, valuation.NAVPerShare)
+	suite.Require().NotNil(valuation.CurrentPrice)
+	suite.Equal(125.0, *valuation.CurrentPrice)
+}
+
+func (suite *ShareMarketTestSuite) TestRedemptionRespectsLockIn() {
+	recent := suite.seedShares(suite.sellerID, 10, 100, time.Now().AddDate(0, 0, -10))
+	older := suite.seedShares(suite.sellerID, 10, 100, time.Now().AddDate(0, -6, 0))
+	suite.seedWallet(suite.chamaID, "chama", 1000)
+
+	lockIn := 90
+	_, err := suite.service.UpdateSettings(suite.chamaID, suite.chairID, &models.UpdateShareMarketSettingsRequest{LockInDays: &lockIn})
+	suite.Require().NoError(err)
+
+	manual := 120.0
+	_, err = suite.service.SetSharePrice(suite.chamaID, suite.chairID, &models.SetSharePriceRequest{Source: models.SharePriceSourceManual, Price: &manual})
+	suite.Require().NoError(err)
+
+	_, err = suite.service.RedeemShares(suite.chamaID, suite.sellerID, &models.RedeemSharesRequest{ShareID: recent, SharesCount: 5})
+	suite.Error(err, "still within the lock-in period")
+
+	_, err = suite.service.RedeemShares(suite.chamaID, suite.sellerID, &models.RedeemSharesRequest{ShareID: older, SharesCount: 10})
+	suite.Error(err, "the chama cannot cover 1200")
+
+	redemption, err := suite.service.RedeemShares(suite.chamaID, suite.sellerID, &models.RedeemSharesRequest{ShareID: older, SharesCount: 5})
+	suite.Require().NoError(err)
+	suite.Equal(600.0, redemption.TotalAmount)
+	suite.Equal(5, redemption.SharesLeft)
+	suite.Equal(400.0, suite.balance(suite.chamaID, "chama"))
+	suite.Equal(600.0, suite.balance(suite.sellerID, "personal"))
+
+	var transactionType string
+	suite.Require().NoError(suite.db.QueryRow("SELECT transaction_type FROM share_transactions WHERE chama_id = ?", suite.chamaID).Scan(&transactionType))
+	suite.Equal(string(models.ShareTransactionRedemption), transactionType)
+
+	disabled := false
+	_, err = suite.service.UpdateSettings(suite.chamaID, suite.chairID, &models.UpdateShareMarketSettingsRequest{RedemptionEnabled: &disabled})
+	suite.Require().NoError(err)
+	_, err = suite.service.RedeemShares(suite.chamaID, suite.sellerID, &models.RedeemSharesRequest{ShareID: older, SharesCount: 1})
+	suite.Error(err)
+}
+
+func (suite *ShareMarketTestSuite) TestConcurrentRedemptionsArePaidOnce() {
+	shareID := suite.seedShares(suite.sellerID, 10, 100, time.Now().AddDate(-1, 0, 0))
+	suite.seedWallet(suite.chamaID, "chama", 10000)
+
+	const attempts = 8
+	var wg sync.WaitGroup
+	var redeemed int32
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := suite.service.RedeemShares(suite.chamaID, suite.sellerID, &models.RedeemSharesRequest{ShareID: shareID, SharesCount: 5}); err == nil {
+				atomic.AddInt32(&redeemed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	suite.Equal(int32(2), redeemed, "ten shares cover two redemptions of five")
+	suite.Equal(1000.0, suite.balance(suite.sellerID, "personal"))
+	suite.Equal(9000.0, suite.balance(suite.chamaID, "chama"))
+
+	var status string
+	suite.Require().NoError(suite.db.QueryRow("SELECT status FROM shares WHERE id = ?", shareID).Scan(&status))
+	suite.Equal(string(models.ShareStatusRedeemed), status)
+
+	var totalFunds float64
+	suite.Require().NoError(suite.db.QueryRow("SELECT total_funds FROM chamas WHERE id = ?", suite.chamaID).Scan(&totalFunds))
+	suite.Equal(9000.0, totalFunds, "the chama's funds follow its wallet")
+
+	var untraced int
+	suite.Require().NoError(suite.db.QueryRow(`
+		SELECT COUNT(*) FROM transactions
+		WHERE type = 'share_redemption' AND (from_wallet_id IS NULL OR to_wallet_id IS NULL)
+	`).Scan(&untraced))
+	suite.Zero(untraced, "redemption payouts name both wallets")
+}
+
+func (suite *ShareMarketTestSuite) TestListingAndRedemptionShareOneHolding() {
+	shareID := suite.seedShares(suite.sellerID, 10, 100, time.Now().AddDate(-1, 0, 0))
+	suite.seedWallet(suite.chamaID, "chama", 10000)
+	suite.seedWallet(suite.buyerID, "personal", 1000)
+
+	listing, err := suite.service.CreateListing(suite.chamaID, suite.sellerID, &models.CreateShareListingRequest{ShareID: shareID, Quantity: 4, PricePerShare: 100})
+	suite.Require().NoError(err)
+	_, err = suite.service.RedeemShares(suite.chamaID, suite.sellerID, &models.RedeemSharesRequest{ShareID: shareID, SharesCount: 6})
+	suite.Require().NoError(err)
+
+	_, err = suite.service.BuyFromListing(suite.chamaID, listing.ID, suite.buyerID, &models.BuyShareListingRequest{Quantity: 4})
+	suite.Require().NoError(err)
+
+	var owned int
+	var status string
+	suite.Require().NoError(suite.db.QueryRow("SELECT shares_owned, status FROM shares WHERE id = ?", shareID).Scan(&owned, &status))
+	suite.Equal(string(models.ShareStatusTransferred), status, "the buy takes the seller's last four shares")
+
+	var outstanding int
+	suite.Require().NoError(suite.db.QueryRow("SELECT COALESCE(SUM(shares_owned), 0) FROM shares WHERE chama_id = ? AND status = 'active'", suite.chamaID).Scan(&outstanding))
+	suite.Equal(4, outstanding, "only the buyer's shares remain")
+}
+
+func (suite *ShareMarketTestSuite) TestSplitKeepsStakeValue() {
+	shareID := suite.seedShares(suite.sellerID, 15, 100, time.Now())
+	suite.seedShares(suite.buyerID, 5, 100, time.Now())
+
+	manual := 100.0
+	_, err := suite.service.SetSharePrice(suite.chamaID, suite.chairID, &models.SetSharePriceRequest{Source: models.SharePriceSourceManual, Price: &manual})
+	suite.Require().NoError(err)
+
+	_, err = suite.service.SplitShares(suite.chamaID, suite.chairID, &models.SplitSharesRequest{RatioFrom: 2, RatioTo: 1})
+	suite.Error(err, "15 shares cannot be consolidated 1-for-2")
+
+	split, err := suite.service.SplitShares(suite.chamaID, suite.chairID, &models.SplitSharesRequest{RatioFrom: 1, RatioTo: 2})
+	suite.Require().NoError(err)
+	suite.Equal(20, split.SharesBefore)
+	suite.Equal(40, split.SharesAfter)
+
+	var owned int
+	var value, total float64
+	suite.Require().NoError(suite.db.QueryRow("SELECT shares_owned, share_value, total_value FROM shares WHERE id = ?", shareID).Scan(&owned, &value, &total))
+	suite.Equal(30, owned)
+	suite.Equal(50.0, value)
+	suite.Equal(1500.0, total)
+
+	current, err := suite.service.GetCurrentPrice(suite.chamaID, suite.sellerID)
+	suite.Require().NoError(err)
+	suite.Equal(50.0, current.Price)
+	suite.Equal(models.SharePriceSourceSplit, current.Source)
+}
+
+func (suite *ShareMarketTestSuite) TestOrderBookSettlesBetweenWallets() {
+	shareID := suite.seedShares(suite.sellerID, 10, 100, time.Now())
+	suite.seedWallet(suite.buyerID, "personal", 1000)
+
+	_, err := suite.service.CreateListing(suite.chamaID, suite.sellerID, &models.CreateShareListingRequest{ShareID: shareID, Quantity: 11, PricePerShare: 110})
+	suite.Error(err)
+
+	listing, err := suite.service.CreateListing(suite.chamaID, suite.sellerID, &models.CreateShareListingRequest{ShareID: shareID, Quantity: 6, PricePerShare: 110})
+	suite.Require().NoError(err)
+
+	_, err = suite.service.CreateListing(suite.chamaID, suite.sellerID, &models.CreateShareListingRequest{ShareID: shareID, Quantity: 5, PricePerShare: 120})
+	suite.Error(err, "listed shares are held back")
+
+	_, err = suite.service.BuyFromListing(suite.chamaID, listing.ID, suite.sellerID, &models.BuyShareListingRequest{Quantity: 1})
+	suite.Error(err, "sellers cannot buy their own listing")
+
+	bought, err := suite.service.BuyFromListing(suite.chamaID, listing.ID, suite.buyerID, &models.BuyShareListingRequest{Quantity: 4})
+	suite.Require().NoError(err)
+	suite.Equal(4, bought.SharesOwned)
+	suite.Equal(110.0, bought.ShareValue)
+	suite.Equal(560.0, suite.balance(suite.buyerID, "personal"))
+	suite.Equal(440.0, suite.balance(suite.sellerID, "personal"))
+
+	book, err := suite.service.GetListings(suite.chamaID, suite.buyerID, "", 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(book, 1)
+	suite.Equal(2, book[0].RemainingQuantity)
+
+	_, err = suite.service.BuyFromListing(suite.chamaID, listing.ID, suite.buyerID, &models.BuyShareListingRequest{Quantity: 3})
+	suite.Error(err, "only two shares are left")
+
+	_, err = suite.service.CancelListing(suite.chamaID, listing.ID, suite.buyerID)
+	suite.Error(err, "only the seller or an official can cancel")
+	cancelled, err := suite.service.CancelListing(suite.chamaID, listing.ID, suite.sellerID)
+	suite.Require().NoError(err)
+	suite.Equal(models.ShareListingStatusCancelled, cancelled.Status)
+
+	var owned int
+	suite.Require().NoError(suite.db.QueryRow("SELECT shares_owned FROM shares WHERE id = ?", shareID).Scan(&owned))
+	suite.Equal(6, owned)
+}
+
+func (suite *ShareMarketTestSuite) TestLockedBuyerWalletCannotPay() {
+	shareID := suite.seedShares(suite.sellerID, 10, 100, time.Now())
+	suite.seedWallet(suite.buyerID, "personal", 1000)
+	_, err := suite.db.Exec("UPDATE wallets SET is_locked = TRUE WHERE owner_id = ?", suite.buyerID)
+	suite.Require().NoError(err)
+
+	listing, err := suite.service.CreateListing(suite.chamaID, suite.sellerID, &models.CreateShareListingRequest{ShareID: shareID, Quantity: 5, PricePerShare: 100})
+	suite.Require().NoError(err)
+	_, err = suite.service.BuyFromListing(suite.chamaID, listing.ID, suite.buyerID, &models.BuyShareListingRequest{Quantity: 2})
+	suite.Error(err)
+	suite.Equal(1000.0, suite.balance(suite.buyerID, "personal"))
+
+	book, err := suite.service.GetListings(suite.chamaID, suite.buyerID, "", 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(book, 1)
+	suite.Equal(5, book[0].RemainingQuantity, "a failed payment leaves the listing untouched")
+}
+
+func TestShareMarket(t *testing.T) {
+	suite.Run(t, new(ShareMarketTestSuite))
+}
@@ -29,10 +29,34 @@ func (s *SharesService) CreateShareOffering(chamaID, userID string, req *models.
 		return nil, fmt.Errorf("user is not a member of this chama")
 	}
 
-	// Generate unique ID and security hash
 	offeringID := uuid.New().String()
-	securityHash := uuid.New().String()
 	now := time.Now()
+	totalValue := float64(req.TotalShares) * req.PricePerShare
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The security hash is the offering's audit entry hash, which ties the row to the audit chain
+	auditEntry := &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionDeclaration,
+		EntityType: "share_offering",
+		EntityID:   offeringID,
+		Amount:     &totalValue,
+		Details: map[string]interface{}{
+			"name":          req.Name,
+			"shareType":     req.ShareType,
+			"totalShares":   req.TotalShares,
+			"pricePerShare": req.PricePerShare,
+		},
+	}
+	if err := NewAuditService(s.db).Record(tx, auditEntry); err != nil {
+		return nil, err
+	}
 
 	offering := &models.ShareOffering{
 		ID:                  offeringID,
@@ -45,13 +69,13 @@ func (s *SharesService) CreateShareOffering(chamaID, userID string, req *models.
 		Description:         req.Description,
 		EligibilityCriteria: req.EligibilityCriteria,
 		ApprovalRequired:    req.ApprovalRequired,
-		TotalValue:          float64(req.TotalShares) * req.PricePerShare,
+		TotalValue:          totalValue,
 		CreatedBy:           userID,
 		CreatedByID:         userID,
 		Timestamp:           now,
 		Status:              "active",
 		TransactionID:       uuid.New().String(),
-		SecurityHash:        securityHash,
+		SecurityHash:        auditEntry.EntryHash,
 		CreatedAt:           now,
 		UpdatedAt:           now,
 	}
@@ -66,7 +90,7 @@ func (s *SharesService) CreateShareOffering(chamaID, userID string, req *models.
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := s.db.Exec(
+	_, err = tx.Exec(
 		query,
 		offering.ID,
 		offering.ChamaID,
@@ -93,6 +117,10 @@ func (s *SharesService) CreateShareOffering(chamaID, userID string, req *models.
 		return nil, fmt.Errorf("failed to create share offering: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	log.Printf("Created share offering %s (%s) for chama %s", offeringID, req.Name, chamaID)
 	return offering, nil
 }
@@ -123,9 +151,22 @@ func (s *SharesService) CreateShares(chamaID string, req *models.CreateShareRequ
 		UpdatedAt:         now,
 	}
 
+	if share.CertificateNumber == nil {
+		certificateNumber := s.generateCertificateNumber()
+		share.CertificateNumber = &certificateNumber
+	}
+
 	// Calculate total value
 	share.CalculateTotalValue()
 
+	// The holding and its movement are written together, since dividend registers
+	// rewind share_transactions to work out past holdings
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// Insert into database
 	query := `
 		INSERT INTO shares (
@@ -134,7 +175,7 @@ func (s *SharesService) CreateShares(chamaID string, req *models.CreateShareRequ
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := s.db.Exec(
+	_, err = tx.Exec(
 		query,
 		share.ID,
 		share.ChamaID,
@@ -156,7 +197,7 @@ func (s *SharesService) CreateShares(chamaID string, req *models.CreateShareRequ
 	}
 
 	// Create share transaction record
-	_, err = s.createShareTransaction(chamaID, &models.CreateShareTransactionRequest{
+	err = s.createShareTransactionInTx(tx, chamaID, &models.CreateShareTransactionRequest{
 		ToMemberID:      &req.MemberID,
 		TransactionType: models.ShareTransactionPurchase,
 		SharesCount:     req.SharesCount,
@@ -164,9 +205,16 @@ func (s *SharesService) CreateShares(chamaID string, req *models.CreateShareRequ
 		TransactionDate: req.PurchaseDate,
 		Description:     stringPtr("Initial share purchase"),
 	})
-
 	if err != nil {
-		log.Printf("Warning: Failed to create share transaction record: %v", err)
+		return nil, fmt.Errorf("failed to record share transaction: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if err := syncShareCertificates(s.db, "holding updated", shareID); err != nil {
+		log.Printf("Warning: Failed to issue share certificate: %v", err)
 	}
 
 	log.Printf("Created shares %s for member %s in chama %s", shareID, req.MemberID, chamaID)
@@ -377,6 +425,10 @@ func (s *SharesService) UpdateShares(shareID string, req *models.UpdateShareRequ
 		return nil, fmt.Errorf("failed to update shares: %w", err)
 	}
 
+	if err := syncShareCertificates(s.db, "holding updated", shareID); err != nil {
+		log.Printf("Warning: Failed to re-issue share certificate: %v", err)
+	}
+
 	log.Printf("Updated shares %s", shareID)
 	return existing, nil
 }
@@ -656,6 +708,24 @@ func (s *SharesService) BuyShares(chamaID, userID string, req *models.BuySharesR
 		return nil, fmt.Errorf("failed to record transaction: %w", err)
 	}
 
+	// Record the shares in the share register, dated when they were actually bought
+	description := fmt.Sprintf("Purchase from offering %s", offering.Name)
+	err = s.createShareTransactionInTx(tx, chamaID, &models.CreateShareTransactionRequest{
+		ToMemberID:      &userID,
+		TransactionType: models.ShareTransactionPurchase,
+		SharesCount:     req.Quantity,
+		ShareValue:      offering.PricePerShare,
+		TransactionDate: now,
+		Description:     &description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record share transaction: %w", err)
+	}
+
+	if err = syncShareCertificates(tx, "holding updated", share.ID); err != nil {
+		return nil, err
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
@@ -780,6 +850,10 @@ func (s *SharesService) BuyDividends(chamaID, userID string, req *models.BuyDivi
 		return nil, fmt.Errorf("failed to record transaction: %w", err)
 	}
 
+	if err = syncShareCertificates(tx, "holding updated", share.ID); err != nil {
+		return nil, err
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
@@ -898,7 +972,11 @@ func (s *SharesService) recordSharePurchaseTransaction(tx *sql.Tx, chamaID, user
 		paymentMethod, userID, chamaID, string(metadataJSON), now, now,
 	)
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	return NewAuditService(s.db).RecordTransaction(tx, chamaID, transactionID)
 }
 
 func (s *SharesService) getDividendDeclarationByID(declarationID string) (*models.DividendDeclaration, error) {
@@ -949,11 +1027,19 @@ func (s *SharesService) recordDividendPurchaseTransaction(tx *sql.Tx, chamaID, u
 		paymentMethod, userID, chamaID, string(metadataJSON), now, now,
 	)
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	return NewAuditService(s.db).RecordTransaction(tx, chamaID, transactionID)
 }
 
 func (s *SharesService) generateCertificateNumber() string {
-	// Generate a unique certificate number
+	return newShareCertificateNumber()
+}
+
+// newShareCertificateNumber generates a unique certificate number
+func newShareCertificateNumber() string {
 	timestamp := time.Now().Unix()
 	random := uuid.New().String()[:8]
 	return fmt.Sprintf("CERT-%d-%s", timestamp, random)
@@ -1018,6 +1104,7 @@ func (s *SharesService) TransferShares(chamaID, fromUserID string, req *models.T
 	}
 
 	now := time.Now()
+	newShareID := uuid.New().String()
 
 	// Handle share transfer based on quantity
 	if req.SharesCount == share.SharesOwned {
@@ -1031,7 +1118,6 @@ func (s *SharesService) TransferShares(chamaID, fromUserID string, req *models.T
 		}
 
 		// Create new active share record for buyer
-		newShareID := uuid.New().String()
 		_, err = tx.Exec(
 			`INSERT INTO shares (
 				id, chama_id, member_id, name, share_type, shares_owned, share_value,
@@ -1058,7 +1144,6 @@ func (s *SharesService) TransferShares(chamaID, fromUserID string, req *models.T
 		}
 
 		// Create new share record for buyer
-		newShareID := uuid.New().String()
 		_, err = tx.Exec(
 			`INSERT INTO shares (
 				id, chama_id, member_id, name, share_type, shares_owned, share_value,
@@ -1093,6 +1178,11 @@ func (s *SharesService) TransferShares(chamaID, fromUserID string, req *models.T
 		return fmt.Errorf("failed to record wallet transaction: %w", err)
 	}
 
+	// The seller's certificate is cancelled and both holdings get fresh ones
+	if err = syncShareCertificates(tx, "shares transferred", req.ShareID, newShareID); err != nil {
+		return err
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -1216,7 +1306,11 @@ func (s *SharesService) recordShareTransferTransaction(tx *sql.Tx, chamaID, from
 		fromUserID, toUserID, string(metadataJSON), now, now,
 	)
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	return NewAuditService(s.db).RecordTransaction(tx, chamaID, transactionID)
 }
 
 func stringPtr(s string) *string {
@@ -0,0 +1,767 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/utils"
+)
+
+const (
+	// standingOrderRunHour is the hour, East Africa Time, at which scheduled runs fall due
+	standingOrderRunHour = 8
+	// standingOrderRetryInterval is how long to wait before retrying a run that failed
+	// for lack of funds
+	standingOrderRetryInterval  = 6 * time.Hour
+	defaultStandingOrderRetries = 3
+)
+
+// StandingOrderService schedules and runs recurring transfers from personal wallets
+type StandingOrderService struct {
+	db *sql.DB
+}
+
+// NewStandingOrderService creates a new standing order service
+func NewStandingOrderService(db *sql.DB) *StandingOrderService {
+	return &StandingOrderService{db: db}
+}
+
+// CreateStandingOrder schedules a recurring transfer to a chama or another member
+func (s *StandingOrderService) CreateStandingOrder(userID string, req *models.CreateStandingOrderRequest) (*models.StandingOrder, error) {
+	now := time.Now()
+	order := &models.StandingOrder{
+		ID:            uuid.New().String(),
+		UserID:        userID,
+		TargetType:    models.StandingOrderTarget(req.TargetType),
+		Amount:        roundCurrency(req.Amount),
+		Description:   req.Description,
+		Frequency:     models.StandingOrderFrequency(req.Frequency),
+		Status:        models.StandingOrderStatusActive,
+		MaxExecutions: req.MaxExecutions,
+		MaxRetries:    defaultStandingOrderRetries,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if req.MaxRetries != nil {
+		order.MaxRetries = *req.MaxRetries
+	}
+
+	switch order.TargetType {
+	case models.StandingOrderTargetChama:
+		if req.ChamaID == "" {
+			return nil, fmt.Errorf("chamaId is required for chama standing orders")
+		}
+		if !s.isActiveMember(s.db, req.ChamaID, userID) {
+			return nil, fmt.Errorf("you must be an active member of the chama")
+		}
+		order.ChamaID = &req.ChamaID
+	case models.StandingOrderTargetMember:
+		if req.RecipientID == "" {
+			return nil, fmt.Errorf("recipientId is required for member standing orders")
+		}
+		if req.RecipientID == userID {
+			return nil, fmt.Errorf("you cannot set up a standing order to yourself")
+		}
+		if !s.userExists(s.db, req.RecipientID) {
+			return nil, fmt.Errorf("recipient not found")
+		}
+		order.RecipientID = &req.RecipientID
+	}
+
+	local := now.In(utils.EATLocation)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, utils.EATLocation)
+	order.StartDate = today
+	if req.StartDate != "" {
+		start, err := time.ParseInLocation("2006-01-02", req.StartDate, utils.EATLocation)
+		if err != nil {
+			return nil, fmt.Errorf("startDate must be in YYYY-MM-DD format")
+		}
+		if start.Before(today) {
+			return nil, fmt.Errorf("startDate cannot be in the past")
+		}
+		order.StartDate = start
+	}
+	if req.EndDate != "" {
+		end, err := time.ParseInLocation("2006-01-02", req.EndDate, utils.EATLocation)
+		if err != nil {
+			return nil, fmt.Errorf("endDate must be in YYYY-MM-DD format")
+		}
+		if end.Before(order.StartDate) {
+			return nil, fmt.Errorf("endDate cannot be before startDate")
+		}
+		order.EndDate = &end
+	}
+
+	switch order.Frequency {
+	case models.StandingOrderFrequencyMonthly:
+		day := order.StartDate.Day()
+		if req.DayOfMonth != nil {
+			day = *req.DayOfMonth
+		}
+		order.DayOfMonth = &day
+	case models.StandingOrderFrequencyWeekly:
+		day := int(order.StartDate.Weekday())
+		if req.DayOfWeek != nil {
+			day = *req.DayOfWeek
+		}
+		order.DayOfWeek = &day
+	}
+
+	// The first run is the first scheduled time on or after the start date that is
+	// still in the future
+	after := order.StartDate.Add(-time.Nanosecond)
+	if now.After(after) {
+		after = now
+	}
+	status, nextRunAt := s.advance(order, after, 0)
+	if status != models.StandingOrderStatusActive {
+		return nil, fmt.Errorf("the end date is before the first scheduled run")
+	}
+	order.NextRunAt = nextRunAt
+
+	_, err := s.db.Exec(`
+		INSERT INTO standing_orders (
+			id, user_id, target_type, chama_id, recipient_id, amount, description, frequency,
+			day_of_month, day_of_week, status, start_date, end_date, max_executions, max_retries,
+			next_run_at, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, order.ID, order.UserID, order.TargetType, order.ChamaID, order.RecipientID, order.Amount, order.Description,
+		order.Frequency, order.DayOfMonth, order.DayOfWeek, order.Status, order.StartDate, order.EndDate,
+		order.MaxExecutions, order.MaxRetries, order.NextRunAt, order.CreatedAt, order.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create standing order: %w", err)
+	}
+
+	return s.GetStandingOrder(order.ID, userID)
+}
+
+// GetStandingOrders lists a user's standing orders, optionally filtered by status
+func (s *StandingOrderService) GetStandingOrders(userID, status string) ([]models.StandingOrder, error) {
+	if status != "" {
+		return s.queryStandingOrders("WHERE so.user_id = ? AND so.status = ? ORDER BY so.created_at DESC", userID, status)
+	}
+	return s.queryStandingOrders("WHERE so.user_id = ? ORDER BY so.created_at DESC", userID)
+}
+
+// GetStandingOrder returns one of the user's standing orders
+func (s *StandingOrderService) GetStandingOrder(orderID, userID string) (*models.StandingOrder, error) {
+	order, err := s.getStandingOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, fmt.Errorf("standing order not found")
+	}
+	return order, nil
+}
+
+// GetExecutions returns the run history of one of the user's standing orders, newest first
+func (s *StandingOrderService) GetExecutions(orderID, userID string, limit, offset int) ([]models.StandingOrderExecution, error) {
+	if _, err := s.GetStandingOrder(orderID, userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, standing_order_id, scheduled_for, attempt, status, amount, transaction_id, failure_reason, executed_at
+		FROM standing_order_executions
+		WHERE standing_order_id = ?
+		ORDER BY executed_at DESC
+		LIMIT ? OFFSET ?
+	`, orderID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get standing order executions: %w", err)
+	}
+	defer rows.Close()
+
+	executions := []models.StandingOrderExecution{}
+	for rows.Next() {
+		var execution models.StandingOrderExecution
+		var transactionID, failureReason sql.NullString
+		err := rows.Scan(&execution.ID, &execution.StandingOrderID, &execution.ScheduledFor, &execution.Attempt,
+			&execution.Status, &execution.Amount, &transactionID, &failureReason, &execution.ExecutedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan standing order execution: %w", err)
+		}
+		if transactionID.Valid {
+			execution.TransactionID = &transactionID.String
+		}
+		if failureReason.Valid {
+			execution.FailureReason = &failureReason.String
+		}
+		executions = append(executions, execution)
+	}
+
+	return executions, nil
+}
+
+// PauseStandingOrder stops an active standing order from running until it is resumed
+func (s *StandingOrderService) PauseStandingOrder(orderID, userID string) (*models.StandingOrder, error) {
+	if _, err := s.GetStandingOrder(orderID, userID); err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.Exec(`
+		UPDATE standing_orders SET status = ?, retry_count = 0, retry_at = NULL, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, models.StandingOrderStatusPaused, time.Now(), orderID, models.StandingOrderStatusActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pause standing order: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("only active standing orders can be paused")
+	}
+
+	return s.getStandingOrder(orderID)
+}
+
+// ResumeStandingOrder reactivates a paused standing order from its next scheduled date.
+// Runs that fell due while it was paused are skipped rather than caught up.
+func (s *StandingOrderService) ResumeStandingOrder(orderID, userID string) (*models.StandingOrder, error) {
+	order, err := s.GetStandingOrder(orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != models.StandingOrderStatusPaused {
+		return nil, fmt.Errorf("only paused standing orders can be resumed")
+	}
+	if order.TargetType == models.StandingOrderTargetChama && !s.isActiveMember(s.db, *order.ChamaID, userID) {
+		return nil, fmt.Errorf("you must be an active member of the chama")
+	}
+
+	now := time.Now()
+	status, nextRunAt := s.advance(order, now, order.ExecutionCount)
+	if status != models.StandingOrderStatusActive {
+		return nil, fmt.Errorf("standing order has no runs left before its end date")
+	}
+
+	result, err := s.db.Exec(`
+		UPDATE standing_orders SET status = ?, next_run_at = ?, last_failure_reason = NULL, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, models.StandingOrderStatusActive, nextRunAt, now, orderID, models.StandingOrderStatusPaused)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume standing order: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("only paused standing orders can be resumed")
+	}
+
+	return s.getStandingOrder(orderID)
+}
+
+// CancelStandingOrder permanently stops a standing order
+func (s *StandingOrderService) CancelStandingOrder(orderID, userID string) (*models.StandingOrder, error) {
+	if _, err := s.GetStandingOrder(orderID, userID); err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.Exec(`
+		UPDATE standing_orders SET status = ?, next_run_at = NULL, retry_at = NULL, updated_at = ?
+		WHERE id = ? AND status IN (?, ?)
+	`, models.StandingOrderStatusCancelled, time.Now(), orderID,
+		models.StandingOrderStatusActive, models.StandingOrderStatusPaused)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel standing order: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("standing order is already finished")
+	}
+
+	return s.getStandingOrder(orderID)
+}
+
+// ProcessDueStandingOrders runs every active standing order whose run or retry is due.
+// It is called by the notification scheduler.
+func (s *StandingOrderService) ProcessDueStandingOrders() {
+	now := time.Now()
+	rows, err := s.db.Query(`
+		SELECT id FROM standing_orders
+		WHERE status = ? AND julianday(COALESCE(retry_at, next_run_at)) <= julianday(?)
+		ORDER BY julianday(COALESCE(retry_at, next_run_at))
+	`, models.StandingOrderStatusActive, now)
+	if err != nil {
+		log.Printf("Failed to load due standing orders: %v", err)
+		return
+	}
+
+	var orderIDs []string
+	for rows.Next() {
+		var orderID string
+		if err := rows.Scan(&orderID); err == nil {
+			orderIDs = append(orderIDs, orderID)
+		}
+	}
+	rows.Close()
+
+	for _, orderID := range orderIDs {
+		if err := s.execute(orderID, now); err != nil {
+			log.Printf("Failed to run standing order %s: %v", orderID, err)
+		}
+	}
+}
+
+// execute runs one due standing order. Failures to pay (no funds, a locked wallet, a
+// member who has left the chama) are recorded against the order; only unexpected
+// database errors are returned, leaving the run to be retried on the next tick.
+func (s *StandingOrderService) execute(orderID string, now time.Time) error {
+	order, err := s.getStandingOrder(orderID)
+	if err != nil {
+		return err
+	}
+	if order.Status != models.StandingOrderStatusActive || order.NextRunAt == nil {
+		return nil
+	}
+	scheduledFor := *order.NextRunAt
+	attempt := order.RetryCount + 1
+
+	// The payment is screened before the run's transaction opens; a hold or block is
+	// recorded against the order like any other failure to pay
+	check := &models.FraudCheck{UserID: order.UserID, Amount: order.Amount, TransactionType: models.TransactionTypeContribution}
+	if order.TargetType == models.StandingOrderTargetMember {
+		check.TransactionType = models.TransactionTypeTransfer
+		check.RecipientID = *order.RecipientID
+	} else {
+		check.RecipientID = *order.ChamaID
+	}
+	evaluation, screenErr := screenOutgoing(s.db, check)
+	if screenErr != nil && !errors.Is(screenErr, errTransactionHeld) && !errors.Is(screenErr, errTransactionBlocked) {
+		return screenErr
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Claim the run so an overlapping tick cannot pay it twice
+	result, err := tx.Exec(`
+		UPDATE standing_orders SET last_run_at = ?, updated_at = ?
+		WHERE id = ? AND status = ? AND julianday(COALESCE(retry_at, next_run_at)) <= julianday(?)
+	`, now, now, orderID, models.StandingOrderStatusActive, now)
+	if err != nil {
+		return fmt.Errorf("failed to claim standing order: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil
+	}
+
+	failure := s.checkTarget(tx, order)
+	var fromWalletID string
+	if failure == nil {
+		failure = screenErr
+	}
+	if failure == nil {
+		failure = reserveTransactionLimit(tx, order.UserID, order.ID, order.Amount)
+	}
+	if failure == nil {
+		fromWalletID, failure = debitPersonalWallet(tx, order.UserID, order.Amount)
+	}
+	if failure != nil {
+		outcome, err := s.recordFailure(tx, order, scheduledFor, attempt, failure, now)
+		if err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		s.notifyFailure(order, outcome, failure)
+		return nil
+	}
+
+	transactionID, err := s.pay(tx, order, fromWalletID, now)
+	if err != nil {
+		return err
+	}
+	if err := recordFraudEvaluation(tx, evaluation, transactionID); err != nil {
+		return err
+	}
+
+	status, nextRunAt := s.advance(order, now, order.ExecutionCount+1)
+	_, err = tx.Exec(`
+		UPDATE standing_orders
+		SET execution_count = execution_count + 1, retry_count = 0, retry_at = NULL,
+			last_failure_reason = NULL, next_run_at = ?, status = ?, updated_at = ?
+		WHERE id = ?
+	`, nextRunAt, status, now, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to update standing order: %w", err)
+	}
+
+	if err := s.recordExecution(tx, order, scheduledFor, attempt, models.StandingOrderExecutionSucceeded, &transactionID, nil, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if order.ChamaID != nil {
+		InvalidateMemberAnalytics(*order.ChamaID)
+	}
+	s.notifySuccess(order, transactionID, status, nextRunAt)
+	return nil
+}
+
+// checkTarget confirms the order can still be paid to its target
+func (s *StandingOrderService) checkTarget(tx *sql.Tx, order *models.StandingOrder) error {
+	switch order.TargetType {
+	case models.StandingOrderTargetChama:
+		if !s.isActiveMember(tx, *order.ChamaID, order.UserID) {
+			return fmt.Errorf("you are no longer an active member of %s", order.ChamaName)
+		}
+	case models.StandingOrderTargetMember:
+		if !s.userExists(tx, *order.RecipientID) {
+			return fmt.Errorf("recipient not found")
+		}
+	}
+	return nil
+}
+
+// pay credits the order's target with money already taken from the payer's wallet and
+// records the transaction in the audit log
+func (s *StandingOrderService) pay(tx *sql.Tx, order *models.StandingOrder, fromWalletID string, now time.Time) (string, error) {
+	transactionID := uuid.New().String()
+	description := order.Description
+
+	if order.TargetType == models.StandingOrderTargetMember {
+		toWalletID, err := creditPersonalWallet(tx, *order.RecipientID, order.Amount)
+		if err != nil {
+			return "", err
+		}
+		if description == "" {
+			description = "Standing order transfer"
+		}
+		metadata, _ := json.Marshal(map[string]interface{}{
+			"standingOrderId": order.ID,
+			"recipientId":     *order.RecipientID,
+		})
+		_, err = tx.Exec(`
+			INSERT INTO transactions (
+				id, from_wallet_id, to_wallet_id, type, status, amount, currency, description,
+				reference, payment_method, metadata, initiated_by, recipient_id, created_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, 'KES', ?, ?, ?, ?, ?, ?, ?, ?)
+		`, transactionID, fromWalletID, toWalletID, models.TransactionTypeTransfer, models.TransactionStatusCompleted,
+			order.Amount, description, order.ID, models.PaymentMethodWalletTransfer, string(metadata),
+			order.UserID, *order.RecipientID, now, now)
+		if err != nil {
+			return "", fmt.Errorf("failed to record transfer: %w", err)
+		}
+		if err := NewAuditService(s.db).RecordTransaction(tx, "", transactionID); err != nil {
+			return "", err
+		}
+		return transactionID, nil
+	}
+
+	chamaID := *order.ChamaID
+	toWalletID, err := creditChamaWallet(tx, chamaID, order.Amount)
+	if err != nil {
+		return "", err
+	}
+
+	if description == "" {
+		description = "Standing order contribution"
+	}
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"contributionType": "regular",
+		"chamaId":          chamaID,
+		"standingOrderId":  order.ID,
+	})
+	_, err = tx.Exec(`
+		INSERT INTO transactions (
+			id, from_wallet_id, to_wallet_id, type, status, amount, currency, description,
+			reference, payment_method, metadata, initiated_by, recipient_id, created_at, updated_at
+		) VALUES (?, ?, ?, 'contribution', ?, ?, 'KES', ?, ?, 'wallet', ?, ?, ?, ?, ?)
+	`, transactionID, fromWalletID, toWalletID, models.TransactionStatusCompleted, order.Amount, description,
+		order.ID, string(metadata), order.UserID, chamaID, now, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to record contribution: %w", err)
+	}
+	if err := NewAuditService(s.db).RecordTransaction(tx, chamaID, transactionID); err != nil {
+		return "", err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE chama_members
+		SET total_contributions = total_contributions + ?, last_contribution = ?
+		WHERE chama_id = ? AND user_id = ?
+	`, order.Amount, now, chamaID, order.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to update member contributions: %w", err)
+	}
+
+	return transactionID, nil
+}
+
+// recordFailure decides what a failed run means for the order. A shortfall or a used-up
+// transaction limit is retried up to MaxRetries times before the run is skipped; anything
+// else pauses the order so the member can fix it.
+func (s *StandingOrderService) recordFailure(tx *sql.Tx, order *models.StandingOrder, scheduledFor time.Time, attempt int, failure error, now time.Time) (models.StandingOrderExecutionStatus, error) {
+	reason := failure.Error()
+	outcome := models.StandingOrderExecutionFailed
+	var err error
+
+	retryable := errors.Is(failure, errInsufficientBalance) || errors.Is(failure, errTransactionLimitExceeded)
+	switch {
+	case retryable && order.RetryCount < order.MaxRetries:
+		outcome = models.StandingOrderExecutionRetrying
+		retryAt := now.Add(standingOrderRetryInterval)
+		order.RetryAt = &retryAt
+		_, err = tx.Exec(`
+			UPDATE standing_orders SET retry_count = retry_count + 1, retry_at = ?, last_failure_reason = ?, updated_at = ?
+			WHERE id = ?
+		`, retryAt, reason, now, order.ID)
+	case retryable:
+		outcome = models.StandingOrderExecutionMissed
+		status, nextRunAt := s.advance(order, now, order.ExecutionCount)
+		order.Status, order.NextRunAt = status, nextRunAt
+		_, err = tx.Exec(`
+			UPDATE standing_orders SET retry_count = 0, retry_at = NULL, next_run_at = ?, status = ?, last_failure_reason = ?, updated_at = ?
+			WHERE id = ?
+		`, nextRunAt, status, reason, now, order.ID)
+	default:
+		order.Status = models.StandingOrderStatusPaused
+		_, err = tx.Exec(`
+			UPDATE standing_orders SET retry_count = 0, retry_at = NULL, status = ?, last_failure_reason = ?, updated_at = ?
+			WHERE id = ?
+		`, models.StandingOrderStatusPaused, reason, now, order.ID)
+	}
+	if err != nil {
+		return outcome, fmt.Errorf("failed to update standing order: %w", err)
+	}
+
+	return outcome, s.recordExecution(tx, order, scheduledFor, attempt, outcome, nil, &reason, now)
+}
+
+func (s *StandingOrderService) recordExecution(tx *sql.Tx, order *models.StandingOrder, scheduledFor time.Time, attempt int, status models.StandingOrderExecutionStatus, transactionID, failureReason *string, now time.Time) error {
+	_, err := tx.Exec(`
+		INSERT INTO standing_order_executions (
+			id, standing_order_id, scheduled_for, attempt, status, amount, transaction_id, failure_reason, executed_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, uuid.New().String(), order.ID, scheduledFor, attempt, status, order.Amount, transactionID, failureReason, now)
+	if err != nil {
+		return fmt.Errorf("failed to record standing order execution: %w", err)
+	}
+	return nil
+}
+
+// advance works out the order's next run after the given time and whether it has
+// reached its end date or run count
+func (s *StandingOrderService) advance(order *models.StandingOrder, after time.Time, executions int) (models.StandingOrderStatus, *time.Time) {
+	if order.MaxExecutions != nil && executions >= *order.MaxExecutions {
+		return models.StandingOrderStatusCompleted, nil
+	}
+	day := 0
+	if order.DayOfWeek != nil {
+		day = *order.DayOfWeek
+	} else if order.DayOfMonth != nil {
+		day = *order.DayOfMonth
+	}
+	next := nextRecurringRun(string(order.Frequency), day, after)
+	if order.EndDate != nil && !next.Before(order.EndDate.AddDate(0, 0, 1)) {
+		return models.StandingOrderStatusCompleted, nil
+	}
+	return models.StandingOrderStatusActive, &next
+}
+
+// nextRecurringRun returns the first weekly or monthly run strictly after the given
+// time. Weekly runs fall on the weekday day (0 = Sunday); monthly runs on day of the
+// month, or on the last day of months too short to have it.
+func nextRecurringRun(frequency string, day int, after time.Time) time.Time {
+	after = after.In(utils.EATLocation)
+
+	if frequency == string(models.StandingOrderFrequencyWeekly) {
+		run := time.Date(after.Year(), after.Month(), after.Day(), standingOrderRunHour, 0, 0, 0, utils.EATLocation)
+		run = run.AddDate(0, 0, (day-int(run.Weekday())+7)%7)
+		if !run.After(after) {
+			run = run.AddDate(0, 0, 7)
+		}
+		return run
+	}
+
+	for i := 0; ; i++ {
+		month := time.Date(after.Year(), after.Month()+time.Month(i), 1, standingOrderRunHour, 0, 0, 0, utils.EATLocation)
+		runDay := day
+		if lastDay := month.AddDate(0, 1, -1).Day(); runDay > lastDay {
+			runDay = lastDay
+		}
+		run := time.Date(month.Year(), month.Month(), runDay, standingOrderRunHour, 0, 0, 0, utils.EATLocation)
+		if run.After(after) {
+			return run
+		}
+	}
+}
+
+func (s *StandingOrderService) targetName(order *models.StandingOrder) string {
+	if order.TargetType == models.StandingOrderTargetChama {
+		return order.ChamaName
+	}
+	return order.RecipientName
+}
+
+func (s *StandingOrderService) notifySuccess(order *models.StandingOrder, transactionID string, status models.StandingOrderStatus, nextRunAt *time.Time) {
+	notificationService := NewNotificationService(s.db, nil)
+	message := fmt.Sprintf("Your standing order of KES %.2f to %s was paid.", order.Amount, s.targetName(order))
+	if status == models.StandingOrderStatusCompleted {
+		message += " This was its final payment."
+	} else if nextRunAt != nil {
+		message += fmt.Sprintf(" Next payment: %s.", nextRunAt.Format("Jan 2, 2006"))
+	}
+	if err := notificationService.CreateInAppNotification(order.UserID, "transaction", "standing_order", "Standing Order Paid", message, map[string]interface{}{
+		"standingOrderId": order.ID,
+		"transactionId":   transactionID,
+		"amount":          order.Amount,
+	}); err != nil {
+		log.Printf("Failed to notify user %s of standing order payment: %v", order.UserID, err)
+	}
+
+	if order.TargetType == models.StandingOrderTargetMember {
+		message := fmt.Sprintf("You received KES %.2f from %s by standing order.", order.Amount, s.userName(order.UserID))
+		if err := notificationService.CreateInAppNotification(*order.RecipientID, "transaction", "standing_order", "Money Received", message, map[string]interface{}{
+			"standingOrderId": order.ID,
+			"transactionId":   transactionID,
+			"amount":          order.Amount,
+		}); err != nil {
+			log.Printf("Failed to notify user %s of standing order receipt: %v", *order.RecipientID, err)
+		}
+	}
+}
+
+func (s *StandingOrderService) notifyFailure(order *models.StandingOrder, outcome models.StandingOrderExecutionStatus, failure error) {
+	message := fmt.Sprintf("Your standing order of KES %.2f to %s could not be paid: %v.", order.Amount, s.targetName(order), failure)
+	switch outcome {
+	case models.StandingOrderExecutionRetrying:
+		message += fmt.Sprintf(" We will try again at %s.", order.RetryAt.Format("15:04 on Jan 2"))
+	case models.StandingOrderExecutionMissed:
+		message += " This payment has been skipped."
+		if order.NextRunAt != nil {
+			message += fmt.Sprintf(" Next payment: %s.", order.NextRunAt.Format("Jan 2, 2006"))
+		}
+	default:
+		message += " The standing order has been paused."
+	}
+	if err := NewNotificationService(s.db, nil).CreateInAppNotification(order.UserID, "alert", "standing_order", "Standing Order Failed", message, map[string]interface{}{
+		"standingOrderId": order.ID,
+		"amount":          order.Amount,
+		"outcome":         outcome,
+	}); err != nil {
+		log.Printf("Failed to notify user %s of standing order failure: %v", order.UserID, err)
+	}
+}
+
+func (s *StandingOrderService) getStandingOrder(orderID string) (*models.StandingOrder, error) {
+	orders, err := s.queryStandingOrders("WHERE so.id = ?", orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("standing order not found")
+	}
+	return &orders[0], nil
+}
+
+func (s *StandingOrderService) queryStandingOrders(where string, args ...interface{}) ([]models.StandingOrder, error) {
+	rows, err := s.db.Query(`
+		SELECT so.id, so.user_id, so.target_type, so.chama_id, COALESCE(c.name, ''),
+			   so.recipient_id, COALESCE(ru.first_name || ' ' || ru.last_name, ''),
+			   so.amount, so.description, so.frequency, so.day_of_month, so.day_of_week, so.status,
+			   so.start_date, so.end_date, so.max_executions, so.execution_count, so.max_retries,
+			   so.retry_count, so.next_run_at, so.retry_at, so.last_run_at, so.last_failure_reason,
+			   so.created_at, so.updated_at
+		FROM standing_orders so
+		LEFT JOIN chamas c ON c.id = so.chama_id
+		LEFT JOIN users ru ON ru.id = so.recipient_id
+		`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get standing orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []models.StandingOrder{}
+	for rows.Next() {
+		var order models.StandingOrder
+		var chamaID, recipientID, lastFailureReason sql.NullString
+		var dayOfMonth, dayOfWeek, maxExecutions sql.NullInt64
+		var endDate, nextRunAt, retryAt, lastRunAt sql.NullTime
+		err := rows.Scan(&order.ID, &order.UserID, &order.TargetType, &chamaID, &order.ChamaName,
+			&recipientID, &order.RecipientName,
+			&order.Amount, &order.Description, &order.Frequency, &dayOfMonth, &dayOfWeek, &order.Status,
+			&order.StartDate, &endDate, &maxExecutions, &order.ExecutionCount, &order.MaxRetries,
+			&order.RetryCount, &nextRunAt, &retryAt, &lastRunAt, &lastFailureReason,
+			&order.CreatedAt, &order.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan standing order: %w", err)
+		}
+		if chamaID.Valid {
+			order.ChamaID = &chamaID.String
+		}
+		if recipientID.Valid {
+			order.RecipientID = &recipientID.String
+		}
+		if lastFailureReason.Valid {
+			order.LastFailureReason = &lastFailureReason.String
+		}
+		if dayOfMonth.Valid {
+			day := int(dayOfMonth.Int64)
+			order.DayOfMonth = &day
+		}
+		if dayOfWeek.Valid {
+			day := int(dayOfWeek.Int64)
+			order.DayOfWeek = &day
+		}
+		if maxExecutions.Valid {
+			count := int(maxExecutions.Int64)
+			order.MaxExecutions = &count
+		}
+		if endDate.Valid {
+			order.EndDate = &endDate.Time
+		}
+		if nextRunAt.Valid {
+			order.NextRunAt = &nextRunAt.Time
+		}
+		if retryAt.Valid {
+			order.RetryAt = &retryAt.Time
+		}
+		if lastRunAt.Valid {
+			order.LastRunAt = &lastRunAt.Time
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+func (s *StandingOrderService) isActiveMember(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, chamaID, userID string) bool {
+	var exists int
+	err := q.QueryRow(`
+		SELECT 1 FROM chama_members cm
+		JOIN chamas c ON c.id = cm.chama_id
+		WHERE cm.chama_id = ? AND cm.user_id = ? AND cm.is_active = TRUE AND c.status = 'active'
+	`, chamaID, userID).Scan(&exists)
+	return err == nil
+}
+
+func (s *StandingOrderService) userExists(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, userID string) bool {
+	var exists int
+	err := q.QueryRow("SELECT 1 FROM users WHERE id = ?", userID).Scan(&exists)
+	return err == nil
+}
+
+func (s *StandingOrderService) userName(userID string) string {
+	var name string
+	err := s.db.QueryRow("SELECT TRIM(COALESCE(first_name, '') || ' ' || COALESCE(last_name, '')) FROM users WHERE id = ?", userID).Scan(&name)
+	if err != nil || name == "" {
+		return "Someone"
+	}
+	return name
+}
@@ -0,0 +1,313 @@
+package services_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/internal/utils"
+	"vaultke-backend/test/helpers"
+)
+
+type StandingOrderTestSuite struct {
+	suite.Suite
+	testDB      *helpers.TestDatabase
+	db          *sql.DB
+	service     *services.StandingOrderService
+	memberID    string
+	recipientID string
+	chamaID     string
+}
+
+func (suite *StandingOrderTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewStandingOrderService(suite.db)
+
+	suite.memberID = suite.testDB.AddTestUser(suite.T(), "Saver")
+	suite.recipientID = suite.testDB.AddTestUser(suite.T(), "Recipient")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.memberID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.memberID, "member")
+
+	_, err := suite.db.Exec(`
+		INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'personal', ?, 2500)
+	`, uuid.New().String(), suite.memberID)
+	suite.Require().NoError(err)
+}
+
+func (suite *StandingOrderTestSuite) balance(ownerID, walletType string) float64 {
+	var balance float64
+	err := suite.db.QueryRow("SELECT balance FROM wallets WHERE owner_id = ? AND type = ?", ownerID, walletType).Scan(&balance)
+	suite.Require().NoError(err)
+	return balance
+}
+
+// makeDue moves the order's next run (and any retry) into the past
+func (suite *StandingOrderTestSuite) makeDue(orderID string) {
+	past := time.Now().Add(-time.Minute)
+	_, err := suite.db.Exec(`
+		UPDATE standing_orders SET next_run_at = ?, retry_at = CASE WHEN retry_at IS NULL THEN NULL ELSE ? END WHERE id = ?
+	`, past, past, orderID)
+	suite.Require().NoError(err)
+}
+
+func (suite *StandingOrderTestSuite) createChamaOrder(amount float64) *models.StandingOrder {
+	day := 5
+	order, err := suite.service.CreateStandingOrder(suite.memberID, &models.CreateStandingOrderRequest{
+		TargetType: "chama",
+		ChamaID:    suite.chamaID,
+		Amount:     amount,
+		Frequency:  "monthly",
+		DayOfMonth: &day,
+	})
+	suite.Require().NoError(err)
+	return order
+}
+
+func (suite *StandingOrderTestSuite) TestChamaContributionRuns() {
+	order := suite.createChamaOrder(1000)
+	suite.Equal(models.StandingOrderStatusActive, order.Status)
+	suite.Require().NotNil(order.NextRunAt)
+	suite.Equal(5, order.NextRunAt.Day())
+	suite.True(order.NextRunAt.After(time.Now()))
+
+	suite.service.ProcessDueStandingOrders()
+	suite.Equal(2500.0, suite.balance(suite.memberID, "personal"), "nothing runs before it is due")
+
+	suite.makeDue(order.ID)
+	suite.service.ProcessDueStandingOrders()
+	suite.service.ProcessDueStandingOrders()
+
+	suite.Equal(1500.0, suite.balance(suite.memberID, "personal"))
+	suite.Equal(1000.0, suite.balance(suite.chamaID, "chama"))
+
+	var contributions float64
+	err := suite.db.QueryRow("SELECT total_contributions FROM chama_members WHERE chama_id = ? AND user_id = ?", suite.chamaID, suite.memberID).Scan(&contributions)
+	suite.Require().NoError(err)
+	suite.Equal(1000.0, contributions)
+
+	run, err := suite.service.GetStandingOrder(order.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(1, run.ExecutionCount)
+	suite.True(run.NextRunAt.After(time.Now()))
+
+	executions, err := suite.service.GetExecutions(order.ID, suite.memberID, 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(executions, 1)
+	suite.Equal(models.StandingOrderExecutionSucceeded, executions[0].Status)
+	suite.Require().NotNil(executions[0].TransactionID)
+
+	var chamaID string
+	err = suite.db.QueryRow("SELECT json_extract(metadata, '$.chamaId') FROM transactions WHERE id = ? AND type = 'contribution'", *executions[0].TransactionID).Scan(&chamaID)
+	suite.Require().NoError(err)
+	suite.Equal(suite.chamaID, chamaID)
+
+	var audited int
+	err = suite.db.QueryRow("SELECT COUNT(*) FROM audit_log WHERE entity_id = ?", *executions[0].TransactionID).Scan(&audited)
+	suite.Require().NoError(err)
+	suite.Equal(1, audited)
+
+	_, err = suite.service.GetStandingOrder(order.ID, suite.recipientID)
+	suite.Error(err, "orders are private to their owner")
+}
+
+func (suite *StandingOrderTestSuite) TestInsufficientFundsRetriesThenSkips() {
+	retries := 1
+	day := 5
+	order, err := suite.service.CreateStandingOrder(suite.memberID, &models.CreateStandingOrderRequest{
+		TargetType: "chama",
+		ChamaID:    suite.chamaID,
+		Amount:     4000,
+		Frequency:  "monthly",
+		DayOfMonth: &day,
+		MaxRetries: &retries,
+	})
+	suite.Require().NoError(err)
+
+	suite.makeDue(order.ID)
+	suite.service.ProcessDueStandingOrders()
+
+	retrying, err := suite.service.GetStandingOrder(order.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(models.StandingOrderStatusActive, retrying.Status)
+	suite.Equal(1, retrying.RetryCount)
+	suite.Require().NotNil(retrying.RetryAt)
+	suite.True(retrying.RetryAt.After(time.Now()))
+	suite.Require().NotNil(retrying.LastFailureReason)
+
+	suite.service.ProcessDueStandingOrders()
+	executions, err := suite.service.GetExecutions(order.ID, suite.memberID, 50, 0)
+	suite.Require().NoError(err)
+	suite.Len(executions, 1, "the retry waits for its interval")
+
+	suite.makeDue(order.ID)
+	suite.service.ProcessDueStandingOrders()
+
+	missed, err := suite.service.GetStandingOrder(order.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(models.StandingOrderStatusActive, missed.Status)
+	suite.Equal(0, missed.RetryCount)
+	suite.Nil(missed.RetryAt)
+	suite.True(missed.NextRunAt.After(time.Now()), "the missed run is skipped")
+	suite.Equal(0, missed.ExecutionCount)
+
+	executions, err = suite.service.GetExecutions(order.ID, suite.memberID, 50, 0)
+	suite.Require().NoError(err)
+	suite.Require().Len(executions, 2)
+	suite.Equal(models.StandingOrderExecutionMissed, executions[0].Status)
+	suite.Equal(2, executions[0].Attempt)
+	suite.Equal(models.StandingOrderExecutionRetrying, executions[1].Status)
+	suite.Equal(2500.0, suite.balance(suite.memberID, "personal"))
+
+	var alerts int
+	err = suite.db.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = ? AND title = 'Standing Order Failed'", suite.memberID).Scan(&alerts)
+	suite.Require().NoError(err)
+	suite.Equal(2, alerts)
+}
+
+func (suite *StandingOrderTestSuite) TestMemberTransferCompletesAfterCount() {
+	count := 1
+	weekday := int(time.Now().AddDate(0, 0, 3).Weekday())
+	order, err := suite.service.CreateStandingOrder(suite.memberID, &models.CreateStandingOrderRequest{
+		TargetType:    "member",
+		RecipientID:   suite.recipientID,
+		Amount:        700,
+		Frequency:     "weekly",
+		DayOfWeek:     &weekday,
+		MaxExecutions: &count,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(time.Weekday(weekday), order.NextRunAt.Weekday())
+	suite.Equal(8, order.NextRunAt.Hour())
+
+	suite.makeDue(order.ID)
+	suite.service.ProcessDueStandingOrders()
+
+	suite.Equal(1800.0, suite.balance(suite.memberID, "personal"))
+	suite.Equal(700.0, suite.balance(suite.recipientID, "personal"))
+
+	completed, err := suite.service.GetStandingOrder(order.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(models.StandingOrderStatusCompleted, completed.Status)
+	suite.Nil(completed.NextRunAt)
+
+	_, err = suite.service.CreateStandingOrder(suite.memberID, &models.CreateStandingOrderRequest{
+		TargetType:  "member",
+		RecipientID: suite.memberID,
+		Amount:      100,
+		Frequency:   "weekly",
+	})
+	suite.Error(err, "standing orders to yourself are rejected")
+}
+
+func (suite *StandingOrderTestSuite) TestPauseResumeAndCancel() {
+	order := suite.createChamaOrder(500)
+
+	paused, err := suite.service.PauseStandingOrder(order.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(models.StandingOrderStatusPaused, paused.Status)
+
+	suite.makeDue(order.ID)
+	suite.service.ProcessDueStandingOrders()
+	suite.Equal(2500.0, suite.balance(suite.memberID, "personal"), "paused orders do not run")
+
+	_, err = suite.service.ResumeStandingOrder(order.ID, suite.recipientID)
+	suite.Error(err)
+	resumed, err := suite.service.ResumeStandingOrder(order.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(models.StandingOrderStatusActive, resumed.Status)
+	suite.True(resumed.NextRunAt.After(time.Now()), "runs missed while paused are not caught up")
+
+	cancelled, err := suite.service.CancelStandingOrder(order.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(models.StandingOrderStatusCancelled, cancelled.Status)
+	_, err = suite.service.ResumeStandingOrder(order.ID, suite.memberID)
+	suite.Error(err)
+	_, err = suite.service.PauseStandingOrder(order.ID, suite.memberID)
+	suite.Error(err)
+}
+
+func (suite *StandingOrderTestSuite) TestLeavingTheChamaPausesTheOrder() {
+	order := suite.createChamaOrder(500)
+
+	_, err := suite.db.Exec("UPDATE chama_members SET is_active = FALSE WHERE chama_id = ? AND user_id = ?", suite.chamaID, suite.memberID)
+	suite.Require().NoError(err)
+
+	suite.makeDue(order.ID)
+	suite.service.ProcessDueStandingOrders()
+
+	paused, err := suite.service.GetStandingOrder(order.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(models.StandingOrderStatusPaused, paused.Status)
+	suite.Require().NotNil(paused.LastFailureReason)
+	suite.Equal(2500.0, suite.balance(suite.memberID, "personal"))
+
+	_, err = suite.service.ResumeStandingOrder(order.ID, suite.memberID)
+	suite.Error(err, "cannot resume while no longer a member")
+}
+
+func (suite *StandingOrderTestSuite) TestScheduleValidation() {
+	day := 31
+	order, err := suite.service.CreateStandingOrder(suite.memberID, &models.CreateStandingOrderRequest{
+		TargetType: "chama",
+		ChamaID:    suite.chamaID,
+		Amount:     100,
+		Frequency:  "monthly",
+		DayOfMonth: &day,
+	})
+	suite.Require().NoError(err)
+	next := *order.NextRunAt
+	suite.Equal(next.AddDate(0, 0, 1).Day(), 1, "day 31 falls on the last day of shorter months")
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	weekday := int(time.Now().AddDate(0, 0, 3).Weekday())
+	_, err = suite.service.CreateStandingOrder(suite.memberID, &models.CreateStandingOrderRequest{
+		TargetType: "chama",
+		ChamaID:    suite.chamaID,
+		Amount:     100,
+		Frequency:  "weekly",
+		DayOfWeek:  &weekday,
+		StartDate:  tomorrow,
+		EndDate:    tomorrow,
+	})
+	suite.Error(err, "the end date falls before the first run")
+
+	_, err = suite.service.CreateStandingOrder(suite.testDB.AddTestUser(suite.T(), "Outsider"), &models.CreateStandingOrderRequest{
+		TargetType: "chama",
+		ChamaID:    suite.chamaID,
+		Amount:     100,
+		Frequency:  "monthly",
+	})
+	suite.Error(err, "only members can contribute by standing order")
+}
+
+func (suite *StandingOrderTestSuite) TestRunsFallDueInEastAfricaTime() {
+	// On a UTC host the schedule must still be worked out in East Africa Time
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+
+	order := suite.createChamaOrder(100)
+	next := order.NextRunAt.In(utils.EATLocation)
+	suite.Equal(5, next.Day())
+	suite.Equal(8, next.Hour())
+
+	stored, err := suite.service.GetStandingOrder(order.ID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.True(stored.NextRunAt.Equal(*order.NextRunAt))
+
+	// A run due a minute ago in EAT is picked up even though its stored offset differs from the host's
+	_, err = suite.db.Exec("UPDATE standing_orders SET next_run_at = ? WHERE id = ?", utils.NowEAT().Add(-time.Minute), order.ID)
+	suite.Require().NoError(err)
+	suite.service.ProcessDueStandingOrders()
+	suite.Equal(2400.0, suite.balance(suite.memberID, "personal"))
+}
+
+func TestStandingOrders(t *testing.T) {
+	suite.Run(t, new(StandingOrderTestSuite))
+}
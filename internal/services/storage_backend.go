@@ -0,0 +1,290 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"vaultke-backend/config"
+)
+
+// errStoredObjectNotFound is returned by a backend when the object does not exist
+var errStoredObjectNotFound = errors.New("stored object not found")
+
+// StorageBackend keeps the bytes of stored files. Keys are slash separated and
+// generated by the storage service, never taken from user input.
+type StorageBackend interface {
+	Name() string
+	Put(key string, content []byte, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// NewStorageBackend creates the backend selected by STORAGE_BACKEND ("local" or "s3")
+func NewStorageBackend(cfg *config.Config) (StorageBackend, error) {
+	switch cfg.StorageBackend {
+	case "", "local":
+		return NewLocalStorageBackend(cfg.StoragePath), nil
+	case "s3":
+		return NewS3StorageBackend(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKeyID, cfg.S3SecretAccessKey, cfg.S3UsePathStyle)
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", cfg.StorageBackend)
+	}
+}
+
+// LocalStorageBackend keeps files in a private directory on the local filesystem
+type LocalStorageBackend struct {
+	root string
+}
+
+// NewLocalStorageBackend creates a backend rooted at dir. The directory must not be
+// served statically.
+func NewLocalStorageBackend(dir string) *LocalStorageBackend {
+	return &LocalStorageBackend{root: dir}
+}
+
+// Name identifies the backend in stored file records
+func (b *LocalStorageBackend) Name() string {
+	return "local"
+}
+
+// Put writes a new object. Existing objects are never overwritten.
+func (b *LocalStorageBackend) Put(key string, content []byte, contentType string) error {
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create stored file: %w", err)
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write stored file: %w", err)
+	}
+	return file.Close()
+}
+
+// Get opens an object for reading
+func (b *LocalStorageBackend) Get(key string) (io.ReadCloser, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, errStoredObjectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stored file: %w", err)
+	}
+	return file, nil
+}
+
+// Delete removes an object; deleting a missing object is not an error
+func (b *LocalStorageBackend) Delete(key string) error {
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete stored file: %w", err)
+	}
+	return nil
+}
+
+func (b *LocalStorageBackend) path(key string) (string, error) {
+	path := filepath.Join(b.root, filepath.FromSlash(key))
+	rel, err := filepath.Rel(b.root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("invalid storage key: %s", key)
+	}
+	return path, nil
+}
+
+// S3StorageBackend keeps files in an S3-compatible bucket (AWS S3, MinIO and the like).
+// Requests are signed with AWS Signature Version 4.
+type S3StorageBackend struct {
+	endpoint        *url.URL
+	region          string
+	bucket          string
+	accessKeyID     string
+	secretAccessKey string
+	pathStyle       bool
+	client          *http.Client
+}
+
+// NewS3StorageBackend creates a backend for bucket at endpoint, e.g.
+// https://s3.af-south-1.amazonaws.com. Path-style addressing puts the bucket in the
+// path rather than the host name, which most self-hosted stores need.
+func NewS3StorageBackend(endpoint, region, bucket, accessKeyID, secretAccessKey string, pathStyle bool) (*S3StorageBackend, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint: %s", endpoint)
+	}
+	if bucket == "" || accessKeyID == "" || secretAccessKey == "" {
+		return nil, fmt.Errorf("S3 bucket and credentials are required")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3StorageBackend{
+		endpoint:        parsed,
+		region:          region,
+		bucket:          bucket,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		pathStyle:       pathStyle,
+		client:          &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// Name identifies the backend in stored file records
+func (b *S3StorageBackend) Name() string {
+	return "s3"
+}
+
+// Put uploads an object
+func (b *S3StorageBackend) Put(key string, content []byte, contentType string) error {
+	resp, err := b.do(http.MethodPut, key, content, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error("upload", resp)
+	}
+	return nil
+}
+
+// Get downloads an object
+func (b *S3StorageBackend) Get(key string) (io.ReadCloser, error) {
+	resp, err := b.do(http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, errStoredObjectNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, s3Error("download", resp)
+	}
+	return resp.Body, nil
+}
+
+// Delete removes an object; S3 treats deleting a missing object as success
+func (b *S3StorageBackend) Delete(key string) error {
+	resp, err := b.do(http.MethodDelete, key, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return s3Error("delete", resp)
+	}
+	return nil
+}
+
+func (b *S3StorageBackend) do(method, key string, body []byte, contentType string) (*http.Response, error) {
+	host := b.endpoint.Host
+	path := strings.TrimSuffix(b.endpoint.Path, "/")
+	if b.pathStyle {
+		path += "/" + s3EscapePath(b.bucket)
+	} else {
+		host = b.bucket + "." + host
+	}
+	path += "/" + s3EscapePath(key)
+
+	req, err := http.NewRequest(method, b.endpoint.Scheme+"://"+host+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 request: %w", err)
+	}
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	headers := map[string]string{
+		"host":                 host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	if contentType != "" {
+		headers["content-type"] = contentType
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+		if name != "host" {
+			req.Header.Set(name, headers[name])
+		}
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{method, path, "", canonicalHeaders.String(), signedHeaders, payloadHash}, "\n")
+	scope := date + "/" + b.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+b.secretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, b.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.accessKeyID, scope, signedHeaders, signature))
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach S3: %w", err)
+	}
+	return resp, nil
+}
+
+func s3Error(action string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("S3 %s failed with status %d: %s", action, resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// s3EscapePath URI-encodes each segment of a key the way Signature Version 4 expects
+func s3EscapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "+", "%2B")
+	}
+	return strings.Join(segments, "/")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
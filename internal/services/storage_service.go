@@ -0,0 +1,599 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"vaultke-backend/internal/models"
+)
+
+// storageURLTTL is how long a signed download URL stays valid
+const storageURLTTL = 5 * time.Minute
+
+// minStorageURLSecretLength is the shortest STORAGE_URL_SECRET accepted
+const minStorageURLSecretLength = 32
+
+// Content types accepted by each kind of upload. Types are checked against the sniffed
+// content, not the type the client declared.
+var (
+	StorageImageTypes = []string{"image/jpeg", "image/png", "image/webp", "image/gif"}
+
+	// StorageIdentityTypes covers ID photos and selfies; StorageEvidenceTypes adds
+	// scanned documents such as proof of address and dispute receipts
+	StorageIdentityTypes = []string{"image/jpeg", "image/png", "image/webp"}
+	StorageEvidenceTypes = append([]string{"application/pdf"}, StorageIdentityTypes...)
+
+	StorageDocumentTypes = append([]string{
+		"application/pdf",
+		"application/msword",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.ms-excel",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"application/vnd.ms-powerpoint",
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		"text/plain",
+		"text/csv",
+	}, StorageImageTypes...)
+
+	// StorageBankStatementTypes covers CSV, OFX (SGML sniffs as plain text, OFX 2 as
+	// XML) and CAMT.053 XML statements
+	StorageBankStatementTypes = []string{"text/csv", "text/plain", "text/xml"}
+)
+
+// officeTypesByExtension maps office formats, which sniff as zip (OOXML) or as an
+// unknown binary (legacy OLE files), to their real content types
+var officeTypesByExtension = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".doc":  "application/msword",
+	".xls":  "application/vnd.ms-excel",
+	".ppt":  "application/vnd.ms-powerpoint",
+}
+
+var oleSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+var safeExtension = regexp.MustCompile(`^\.[a-z0-9]{1,8}$`)
+
+// VirusScanner checks uploaded content before it is stored. Store refuses the file if
+// any configured scanner reports it infected or fails.
+type VirusScanner interface {
+	Scan(fileName string, content []byte) (infected bool, signature string, err error)
+}
+
+// CommandVirusScanner runs an external scanner that reads the file on stdin and exits
+// 0 when clean and 1 when infected, e.g. "clamdscan --no-summary -"
+type CommandVirusScanner struct {
+	command string
+	args    []string
+}
+
+// NewCommandVirusScanner creates a scanner from a command line
+func NewCommandVirusScanner(commandLine string) *CommandVirusScanner {
+	fields := strings.Fields(commandLine)
+	return &CommandVirusScanner{command: fields[0], args: fields[1:]}
+}
+
+// Scan runs the command over content
+func (s *CommandVirusScanner) Scan(fileName string, content []byte) (bool, string, error) {
+	cmd := exec.Command(s.command, s.args...)
+	cmd.Stdin = bytes.NewReader(content)
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return false, "", nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, strings.TrimSpace(string(output)), nil
+	}
+	return false, "", fmt.Errorf("virus scanner failed: %w: %s", err, strings.TrimSpace(string(output)))
+}
+
+// StoreFileInput describes an upload to keep
+type StoreFileInput struct {
+	OwnerID      string
+	FileName     string
+	Category     models.FileCategory
+	AccessScope  models.FileAccessScope
+	ScopeID      string // the chama or chat room for chama and chat_room scopes
+	AllowedTypes []string
+	MaxSize      int64
+}
+
+// StorageService stores uploaded files on a pluggable backend, records who may read
+// them and issues short-lived signed download URLs
+type StorageService struct {
+	db        *sql.DB
+	backend   StorageBackend
+	urlSecret []byte
+	scanners  []VirusScanner
+}
+
+// NewStorageService creates a new storage service. urlSecret signs download URLs and
+// must be set, since anyone who knows it can mint a link to any file.
+func NewStorageService(db *sql.DB, backend StorageBackend, urlSecret string) (*StorageService, error) {
+	if urlSecret == "" {
+		return nil, fmt.Errorf("STORAGE_URL_SECRET is required to sign download URLs")
+	}
+	if len(urlSecret) < minStorageURLSecretLength {
+		return nil, fmt.Errorf("STORAGE_URL_SECRET must be at least %d characters", minStorageURLSecretLength)
+	}
+	return &StorageService{
+		db:        db,
+		backend:   backend,
+		urlSecret: []byte(urlSecret),
+	}, nil
+}
+
+// AddScanner registers a virus scanner to run on every upload
+func (s *StorageService) AddScanner(scanner VirusScanner) {
+	s.scanners = append(s.scanners, scanner)
+}
+
+// Store checks, scans and keeps an uploaded file
+func (s *StorageService) Store(input *StoreFileInput, content io.Reader) (*models.StoredFile, error) {
+	switch input.AccessScope {
+	case models.FileAccessChama, models.FileAccessChatRoom:
+		if input.ScopeID == "" {
+			return nil, fmt.Errorf("a %s file needs a scope ID", input.AccessScope)
+		}
+	case models.FileAccessPublic, models.FileAccessAuthenticated, models.FileAccessPrivate:
+	default:
+		return nil, fmt.Errorf("invalid access scope: %s", input.AccessScope)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(content, input.MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if int64(len(data)) > input.MaxSize {
+		return nil, fmt.Errorf("file too large. Maximum size is %dMB", input.MaxSize/(1024*1024))
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+
+	contentType := sniffContentType(data, input.FileName)
+	allowed := false
+	for _, allowedType := range input.AllowedTypes {
+		if contentType == allowedType {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("file type %s is not allowed", contentType)
+	}
+
+	return s.keep(input, data, contentType)
+}
+
+// keep scans a checked file, saves it on the backend and records it
+func (s *StorageService) keep(input *StoreFileInput, data []byte, contentType string) (*models.StoredFile, error) {
+	scanStatus := models.FileScanUnscanned
+	for _, scanner := range s.scanners {
+		infected, signature, err := scanner.Scan(input.FileName, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan file: %w", err)
+		}
+		if infected {
+			log.Printf("Rejected infected upload %q from %s: %s", input.FileName, input.OwnerID, signature)
+			return nil, fmt.Errorf("file rejected: it appears to contain malware")
+		}
+		scanStatus = models.FileScanClean
+	}
+
+	now := time.Now()
+	file := &models.StoredFile{
+		ID:          uuid.New().String(),
+		Backend:     s.backend.Name(),
+		FileName:    filepath.Base(input.FileName),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Checksum:    sha256Hex(data),
+		OwnerID:     input.OwnerID,
+		Category:    input.Category,
+		AccessScope: input.AccessScope,
+		ScopeID:     optionalString(input.ScopeID),
+		ScanStatus:  scanStatus,
+		CreatedAt:   now,
+	}
+	extension := strings.ToLower(filepath.Ext(input.FileName))
+	if !safeExtension.MatchString(extension) {
+		extension = ""
+	}
+	file.ObjectKey = fmt.Sprintf("%s/%s/%s%s", input.Category, now.Format("2006/01"), file.ID, extension)
+
+	if err := s.backend.Put(file.ObjectKey, data, contentType); err != nil {
+		return nil, err
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO stored_files (
+			id, backend, object_key, file_name, content_type, size, checksum, owner_id,
+			category, access_scope, scope_id, scan_status, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, file.ID, file.Backend, file.ObjectKey, file.FileName, file.ContentType, file.Size, file.Checksum, file.OwnerID,
+		file.Category, file.AccessScope, file.ScopeID, file.ScanStatus, file.CreatedAt)
+	if err != nil {
+		if deleteErr := s.backend.Delete(file.ObjectKey); deleteErr != nil {
+			log.Printf("Failed to remove orphaned file %s: %v", file.ObjectKey, deleteErr)
+		}
+		return nil, fmt.Errorf("failed to record stored file: %w", err)
+	}
+
+	return file, nil
+}
+
+// Authorize returns a file if the user may read it. Files the user cannot read are
+// reported as not found.
+func (s *StorageService) Authorize(fileID, userID string, isAdmin bool) (*models.StoredFile, error) {
+	file, err := s.getFile(fileID)
+	if err != nil {
+		return nil, err
+	}
+	if file.OwnerID == userID || isAdmin {
+		return file, nil
+	}
+
+	var query string
+	switch file.AccessScope {
+	case models.FileAccessPublic, models.FileAccessAuthenticated:
+		return file, nil
+	case models.FileAccessChama:
+		query = "SELECT COUNT(*) FROM chama_members WHERE chama_id = ? AND user_id = ? AND is_active = TRUE"
+	case models.FileAccessChatRoom:
+		query = "SELECT COUNT(*) FROM chat_room_members WHERE room_id = ? AND user_id = ? AND is_active = TRUE"
+	default:
+		return nil, fmt.Errorf("file not found")
+	}
+
+	var count int
+	if err := s.db.QueryRow(query, *file.ScopeID, userID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to check file access: %w", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("file not found")
+	}
+	return file, nil
+}
+
+// SignURL issues a download URL for a file the caller has already authorized
+func (s *StorageService) SignURL(file *models.StoredFile) *models.SignedFileURL {
+	expiresAt := time.Now().Add(storageURLTTL).Truncate(time.Second)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	return &models.SignedFileURL{
+		FileID:    file.ID,
+		URL:       fmt.Sprintf("%s/download?expires=%s&signature=%s", StoredFileURL(file.ID), expires, s.signature(file.ID, expires)),
+		ExpiresAt: expiresAt,
+	}
+}
+
+// VerifyURL checks a signed download URL's parameters and returns its file
+func (s *StorageService) VerifyURL(fileID, expires, signature string) (*models.StoredFile, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(s.signature(fileID, expires))) {
+		return nil, fmt.Errorf("invalid download link")
+	}
+	if time.Now().Unix() > expiresAt {
+		return nil, fmt.Errorf("download link has expired")
+	}
+	return s.getFile(fileID)
+}
+
+// Open reads a file's content from the backend
+func (s *StorageService) Open(file *models.StoredFile) (io.ReadCloser, error) {
+	if file.Backend != s.backend.Name() {
+		return nil, fmt.Errorf("file is kept on the %s backend, which is not configured", file.Backend)
+	}
+	reader, err := s.backend.Get(file.ObjectKey)
+	if err == errStoredObjectNotFound {
+		return nil, fmt.Errorf("file not found")
+	}
+	return reader, err
+}
+
+// OpenChecked reads a file whose access its caller has checked itself, for private
+// files served by their own handlers such as KYC documents and dispute evidence
+func (s *StorageService) OpenChecked(fileID string) (*models.StoredFile, io.ReadCloser, error) {
+	file, err := s.getFile(fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader, err := s.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, reader, nil
+}
+
+// Delete removes a file. Its record is kept, marked deleted, so old links fail cleanly.
+func (s *StorageService) Delete(fileID string) error {
+	file, err := s.getFile(fileID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.db.Exec("UPDATE stored_files SET deleted_at = ? WHERE id = ?", time.Now(), fileID); err != nil {
+		return fmt.Errorf("failed to delete stored file: %w", err)
+	}
+	if err := s.backend.Delete(file.ObjectKey); err != nil {
+		log.Printf("Failed to remove stored file %s: %v", file.ObjectKey, err)
+	}
+	return nil
+}
+
+// legacyChatUploadsDir is where chat attachments were saved before the storage service.
+// Unlike the other legacy uploads, their paths were only kept in message URLs.
+const legacyChatUploadsDir = "uploads/chat"
+
+// legacyUploadMaxSize caps a single file imported from local disk
+const legacyUploadMaxSize = 50 * 1024 * 1024
+
+// legacyUpload is a file saved to local disk before the storage service, and the row
+// that still points at it
+type legacyUpload struct {
+	rowID    string
+	path     string
+	fileName string
+	ownerID  string
+	scopeID  string
+	metadata string // chat messages only
+}
+
+// legacyUploadSource says where one kind of legacy upload is recorded, who may read it
+// once imported and how to point its row at the stored file
+type legacyUploadSource struct {
+	category models.FileCategory
+	scope    models.FileAccessScope
+	query    string
+	link     func(upload *legacyUpload, file *models.StoredFile) (sql.Result, error)
+}
+
+// ImportLegacyUploads moves files saved to local disk before the storage service into
+// it: meeting, chat and welfare files from ./uploads, which is no longer served, and
+// KYC documents and dispute evidence from ./storage. Each row is repointed at its stored
+// file and the local copy removed, so running it again only picks up what is left.
+// Files that cannot be imported are logged and left in place.
+func (s *StorageService) ImportLegacyUploads() (int, error) {
+	linkDocument := func(table string) func(*legacyUpload, *models.StoredFile) (sql.Result, error) {
+		return func(upload *legacyUpload, file *models.StoredFile) (sql.Result, error) {
+			return s.db.Exec(fmt.Sprintf("UPDATE %s SET file_id = ?, file_url = ?, file_path = '' WHERE id = ? AND file_id IS NULL", table),
+				file.ID, StoredFileURL(file.ID), upload.rowID)
+		}
+	}
+	linkPrivate := func(table string) func(*legacyUpload, *models.StoredFile) (sql.Result, error) {
+		return func(upload *legacyUpload, file *models.StoredFile) (sql.Result, error) {
+			return s.db.Exec(fmt.Sprintf("UPDATE %s SET file_id = ?, file_path = '' WHERE id = ? AND file_id IS NULL", table),
+				file.ID, upload.rowID)
+		}
+	}
+
+	sources := []legacyUploadSource{
+		{
+			category: models.FileCategoryMeetingDocument,
+			scope:    models.FileAccessChama,
+			query: `SELECT d.id, d.file_path, d.file_name, d.uploaded_by, m.chama_id, ''
+				FROM meeting_documents d JOIN meetings m ON m.id = d.meeting_id
+				WHERE d.file_id IS NULL AND d.file_path != ''`,
+			link: linkDocument("meeting_documents"),
+		},
+		{
+			category: models.FileCategoryWelfareDocument,
+			scope:    models.FileAccessChama,
+			query: `SELECT d.id, d.file_path, d.file_name, d.uploaded_by, r.chama_id, ''
+				FROM welfare_request_documents d JOIN welfare_requests r ON r.id = d.welfare_request_id
+				WHERE d.file_id IS NULL AND d.file_path != ''`,
+			link: linkDocument("welfare_request_documents"),
+		},
+		{
+			category: models.FileCategoryKYCDocument,
+			scope:    models.FileAccessPrivate,
+			query: `SELECT id, file_path, file_name, user_id, '', ''
+				FROM kyc_documents WHERE file_id IS NULL AND file_path != ''`,
+			link: linkPrivate("kyc_documents"),
+		},
+		{
+			category: models.FileCategoryDisputeEvidence,
+			scope:    models.FileAccessPrivate,
+			query: `SELECT id, file_path, file_name, uploaded_by, '', ''
+				FROM dispute_evidence WHERE file_id IS NULL AND file_path != ''`,
+			link: linkPrivate("dispute_evidence"),
+		},
+		{
+			category: models.FileCategoryChatAttachment,
+			scope:    models.FileAccessChatRoom,
+			query: `SELECT id, COALESCE(file_url, ''), '', sender_id, room_id, metadata
+				FROM chat_messages WHERE metadata LIKE '%/uploads/chat/%'`,
+			link: s.linkChatAttachment,
+		},
+	}
+
+	imported := 0
+	for _, source := range sources {
+		uploads, err := s.legacyUploads(source.query)
+		if err != nil {
+			return imported, fmt.Errorf("failed to find legacy %s uploads: %w", source.category, err)
+		}
+		for _, upload := range uploads {
+			if source.category == models.FileCategoryChatAttachment && !legacyChatAttachment(upload) {
+				continue
+			}
+			if err := s.importLegacyUpload(source, upload); err != nil {
+				log.Printf("Failed to import legacy %s %s: %v", source.category, upload.path, err)
+				continue
+			}
+			imported++
+		}
+	}
+	return imported, nil
+}
+
+func (s *StorageService) legacyUploads(query string) ([]*legacyUpload, error) {
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var uploads []*legacyUpload
+	for rows.Next() {
+		upload := &legacyUpload{}
+		if err := rows.Scan(&upload.rowID, &upload.path, &upload.fileName, &upload.ownerID, &upload.scopeID, &upload.metadata); err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, upload)
+	}
+	return uploads, rows.Err()
+}
+
+func (s *StorageService) importLegacyUpload(source legacyUploadSource, upload *legacyUpload) error {
+	data, err := os.ReadFile(upload.path)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 || len(data) > legacyUploadMaxSize {
+		return fmt.Errorf("file is empty or larger than %dMB", legacyUploadMaxSize/(1024*1024))
+	}
+
+	file, err := s.keep(&StoreFileInput{
+		OwnerID:     upload.ownerID,
+		FileName:    upload.fileName,
+		Category:    source.category,
+		AccessScope: source.scope,
+		ScopeID:     upload.scopeID,
+	}, data, sniffContentType(data, upload.fileName))
+	if err != nil {
+		return err
+	}
+
+	result, err := source.link(upload, file)
+	if err == nil {
+		var linked int64
+		if linked, err = result.RowsAffected(); err == nil && linked != 1 {
+			err = fmt.Errorf("row was changed while importing")
+		}
+	}
+	if err != nil {
+		s.Delete(file.ID)
+		return err
+	}
+
+	if err := os.Remove(upload.path); err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to remove imported upload %s: %v", upload.path, err)
+	}
+	return nil
+}
+
+// legacyChatAttachment finds the local file behind a chat message's old public URL
+func legacyChatAttachment(upload *legacyUpload) bool {
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(upload.metadata), &metadata); err != nil {
+		return false
+	}
+	fileURL, _ := metadata["fileUrl"].(string)
+	if _, stored := metadata["fileId"]; stored || !strings.Contains(fileURL, "/uploads/chat/") {
+		return false
+	}
+	// Only the base name is trusted, so a crafted URL cannot reach outside the directory
+	upload.path = filepath.Join(legacyChatUploadsDir, path.Base(fileURL))
+	upload.fileName, _ = metadata["fileName"].(string)
+	if upload.fileName == "" {
+		upload.fileName = path.Base(fileURL)
+	}
+	return true
+}
+
+func (s *StorageService) linkChatAttachment(upload *legacyUpload, file *models.StoredFile) (sql.Result, error) {
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(upload.metadata), &metadata); err != nil {
+		return nil, err
+	}
+	metadata["fileId"] = file.ID
+	metadata["fileUrl"] = StoredFileURL(file.ID)
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	return s.db.Exec("UPDATE chat_messages SET metadata = ?, file_url = ? WHERE id = ? AND metadata = ?",
+		string(encoded), StoredFileURL(file.ID), upload.rowID, upload.metadata)
+}
+
+// StoredFileURL is the stable, authenticated URL clients call to get a signed download
+// URL for a stored file
+func StoredFileURL(fileID string) string {
+	return fmt.Sprintf("/api/v1/files/%s", fileID)
+}
+
+func (s *StorageService) signature(fileID, expires string) string {
+	mac := hmac.New(sha256.New, s.urlSecret)
+	mac.Write([]byte(fileID + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *StorageService) getFile(fileID string) (*models.StoredFile, error) {
+	file := &models.StoredFile{}
+	var scopeID sql.NullString
+	var deletedAt sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT id, backend, object_key, file_name, content_type, size, checksum, owner_id,
+			category, access_scope, scope_id, scan_status, created_at, deleted_at
+		FROM stored_files WHERE id = ?
+	`, fileID).Scan(&file.ID, &file.Backend, &file.ObjectKey, &file.FileName, &file.ContentType, &file.Size,
+		&file.Checksum, &file.OwnerID, &file.Category, &file.AccessScope, &scopeID, &file.ScanStatus,
+		&file.CreatedAt, &deletedAt)
+	if err == sql.ErrNoRows || deletedAt.Valid {
+		return nil, fmt.Errorf("file not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stored file: %w", err)
+	}
+	if scopeID.Valid {
+		file.ScopeID = &scopeID.String
+	}
+	return file, nil
+}
+
+// sniffContentType works out a file's type from its first bytes, using the extension
+// only to tell apart office formats that share a container
+func sniffContentType(data []byte, fileName string) string {
+	contentType := http.DetectContentType(data)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	extension := strings.ToLower(filepath.Ext(fileName))
+	switch contentType {
+	case "application/zip":
+		if officeType, ok := officeTypesByExtension[extension]; ok && strings.HasSuffix(extension, "x") {
+			return officeType
+		}
+	case "application/octet-stream":
+		if officeType, ok := officeTypesByExtension[extension]; ok && bytes.HasPrefix(data, oleSignature) {
+			return officeType
+		}
+	case "text/plain":
+		if extension == ".csv" {
+			return "text/csv"
+		}
+	}
+	return contentType
+}
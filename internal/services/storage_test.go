@@ -0,0 +1,297 @@
+package services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+// pngHeader is enough of a PNG for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01")
+
+// storageTestSecret is long enough to pass the storage service's secret check
+const storageTestSecret = "storage-url-secret-for-tests-only"
+
+func newTestStorageService(t *testing.T, db *sql.DB, backend services.StorageBackend, urlSecret string) *services.StorageService {
+	t.Helper()
+
+	service, err := services.NewStorageService(db, backend, urlSecret)
+	require.NoError(t, err)
+	return service
+}
+
+type fakeScanner struct {
+	infected bool
+	scanned  int
+}
+
+func (s *fakeScanner) Scan(fileName string, content []byte) (bool, string, error) {
+	s.scanned++
+	return s.infected, "Eicar-Test-Signature", nil
+}
+
+// fakeS3 is an in-memory stand-in for an S3-compatible store using path-style URLs
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=test-key/") || !strings.Contains(auth, "SignedHeaders=") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	sum := sha256.Sum256(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[r.URL.Path] = body
+	case http.MethodGet:
+		object, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(object)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+type StorageTestSuite struct {
+	suite.Suite
+	testDB   *helpers.TestDatabase
+	db       *sql.DB
+	service  *services.StorageService
+	ownerID  string
+	memberID string
+	outsider string
+	chamaID  string
+}
+
+func (suite *StorageTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = newTestStorageService(suite.T(), suite.db, services.NewLocalStorageBackend(suite.T().TempDir()), storageTestSecret)
+	suite.ownerID = suite.testDB.AddTestUser(suite.T(), "Owner")
+	suite.memberID = suite.testDB.AddTestUser(suite.T(), "Member")
+	suite.outsider = suite.testDB.AddTestUser(suite.T(), "Outsider")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.ownerID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.ownerID, "secretary")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.memberID, "member")
+}
+
+func (suite *StorageTestSuite) store(service *services.StorageService, scope models.FileAccessScope, scopeID, fileName string, content []byte) (*models.StoredFile, error) {
+	return service.Store(&services.StoreFileInput{
+		OwnerID:      suite.ownerID,
+		FileName:     fileName,
+		Category:     models.FileCategoryMeetingDocument,
+		AccessScope:  scope,
+		ScopeID:      scopeID,
+		AllowedTypes: services.StorageDocumentTypes,
+		MaxSize:      1024 * 1024,
+	}, bytes.NewReader(content))
+}
+
+func (suite *StorageTestSuite) download(service *services.StorageService, signedURL string) ([]byte, error) {
+	parsed, err := url.Parse(signedURL)
+	suite.Require().NoError(err)
+	fileID := strings.TrimSuffix(strings.TrimPrefix(parsed.Path, "/api/v1/files/"), "/download")
+	file, err := service.VerifyURL(fileID, parsed.Query().Get("expires"), parsed.Query().Get("signature"))
+	if err != nil {
+		return nil, err
+	}
+	reader, err := service.Open(file)
+	suite.Require().NoError(err)
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func (suite *StorageTestSuite) TestChamaFileIsOnlyReadableByMembersThroughSignedURL() {
+	file, err := suite.store(suite.service, models.FileAccessChama, suite.chamaID, "minutes.png", pngHeader)
+	suite.Require().NoError(err)
+	suite.Equal("image/png", file.ContentType)
+	suite.Equal(models.FileScanUnscanned, file.ScanStatus)
+
+	_, err = suite.service.Authorize(file.ID, suite.outsider, false)
+	suite.Require().Error(err, "non-members cannot read chama files")
+	_, err = suite.service.Authorize(file.ID, suite.outsider, true)
+	suite.Require().NoError(err, "admins can")
+
+	authorized, err := suite.service.Authorize(file.ID, suite.memberID, false)
+	suite.Require().NoError(err)
+	signed := suite.service.SignURL(authorized)
+	content, err := suite.download(suite.service, signed.URL)
+	suite.Require().NoError(err)
+	suite.Equal(pngHeader, content)
+
+	tampered := strings.Replace(signed.URL, "expires=", "expires=9", 1)
+	_, err = suite.download(suite.service, tampered)
+	suite.Require().Error(err, "changing the expiry invalidates the signature")
+
+	other := newTestStorageService(suite.T(), suite.db, services.NewLocalStorageBackend(suite.T().TempDir()), strings.Repeat("x", 32))
+	_, err = suite.download(other, signed.URL)
+	suite.Require().Error(err, "URLs are signed with the server's secret")
+
+	suite.Require().NoError(suite.service.Delete(file.ID))
+	_, err = suite.download(suite.service, signed.URL)
+	suite.Require().Error(err, "deleted files cannot be downloaded")
+}
+
+func (suite *StorageTestSuite) TestContentIsSniffedAndScanned() {
+	_, err := suite.store(suite.service, models.FileAccessChama, suite.chamaID, "minutes.pdf", []byte("<html><script>alert(1)</script></html>"))
+	suite.Require().Error(err, "the declared name does not decide the type")
+
+	var docx bytes.Buffer
+	archive := zip.NewWriter(&docx)
+	entry, err := archive.Create("word/document.xml")
+	suite.Require().NoError(err)
+	entry.Write([]byte("<w:document/>"))
+	suite.Require().NoError(archive.Close())
+	file, err := suite.store(suite.service, models.FileAccessChama, suite.chamaID, "Minutes.DOCX", docx.Bytes())
+	suite.Require().NoError(err)
+	suite.Equal("application/vnd.openxmlformats-officedocument.wordprocessingml.document", file.ContentType)
+
+	_, err = suite.store(suite.service, models.FileAccessChama, "", "minutes.png", pngHeader)
+	suite.Require().Error(err, "chama files need a chama")
+
+	scanner := &fakeScanner{infected: true}
+	suite.service.AddScanner(scanner)
+	_, err = suite.store(suite.service, models.FileAccessPrivate, "", "id.png", pngHeader)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "malware")
+	suite.Equal(1, scanner.scanned)
+
+	scanner.infected = false
+	clean, err := suite.store(suite.service, models.FileAccessPrivate, "", "id.png", pngHeader)
+	suite.Require().NoError(err)
+	suite.Equal(models.FileScanClean, clean.ScanStatus)
+	_, err = suite.service.Authorize(clean.ID, suite.memberID, false)
+	suite.Require().Error(err, "private files are for the owner")
+
+	var stored int
+	suite.Require().NoError(suite.db.QueryRow("SELECT COUNT(*) FROM stored_files").Scan(&stored))
+	suite.Equal(2, stored, "rejected uploads are not recorded")
+}
+
+func (suite *StorageTestSuite) TestChatRoomScopeAndS3Backend() {
+	s3 := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewServer(s3)
+	defer server.Close()
+	backend, err := services.NewS3StorageBackend(server.URL, "af-south-1", "vaultke", "test-key", "test-secret-key", true)
+	suite.Require().NoError(err)
+	service := newTestStorageService(suite.T(), suite.db, backend, storageTestSecret)
+
+	_, err = suite.db.Exec("INSERT INTO chat_rooms (id, name, type, created_by) VALUES ('room-1', 'Pair', 'private', ?)", suite.ownerID)
+	suite.Require().NoError(err)
+	_, err = suite.db.Exec(`
+		INSERT INTO chat_room_members (id, room_id, user_id, role, is_active) VALUES ('crm-1', 'room-1', ?, 'member', TRUE), ('crm-2', 'room-1', ?, 'member', FALSE)
+	`, suite.memberID, suite.outsider)
+	suite.Require().NoError(err)
+
+	file, err := suite.store(service, models.FileAccessChatRoom, "room-1", "photo.png", pngHeader)
+	suite.Require().NoError(err)
+	suite.Len(s3.objects, 1)
+	for path := range s3.objects {
+		suite.True(strings.HasPrefix(path, "/vaultke/meeting_document/"), path)
+	}
+
+	_, err = service.Authorize(file.ID, suite.outsider, false)
+	suite.Require().Error(err, "members who left the room cannot read its files")
+	authorized, err := service.Authorize(file.ID, suite.memberID, false)
+	suite.Require().NoError(err)
+	content, err := suite.download(service, service.SignURL(authorized).URL)
+	suite.Require().NoError(err)
+	suite.Equal(pngHeader, content)
+
+	suite.Require().NoError(service.Delete(file.ID))
+	suite.Empty(s3.objects)
+}
+
+func (suite *StorageTestSuite) TestURLSecretIsRequired() {
+	backend := services.NewLocalStorageBackend(suite.T().TempDir())
+	_, err := services.NewStorageService(suite.db, backend, "")
+	suite.Require().Error(err)
+	_, err = services.NewStorageService(suite.db, backend, "short-secret")
+	suite.Require().Error(err)
+}
+
+func (suite *StorageTestSuite) TestLegacyUploadsAreImported() {
+	suite.T().Chdir(suite.T().TempDir())
+	for _, dir := range []string{"uploads/meetings", "uploads/chat"} {
+		suite.Require().NoError(os.MkdirAll(dir, 0o755))
+	}
+	suite.Require().NoError(os.WriteFile("uploads/meetings/minutes.png", pngHeader, 0o644))
+	suite.Require().NoError(os.WriteFile("uploads/chat/photo.png", pngHeader, 0o644))
+
+	meetingID := suite.testDB.AddTestMeeting(suite.T(), suite.chamaID, suite.ownerID, "completed")
+	_, err := suite.db.Exec(`
+		INSERT INTO meeting_documents (id, meeting_id, uploaded_by, file_name, file_path, file_url)
+		VALUES ('doc-1', ?, ?, 'minutes.png', './uploads/meetings/minutes.png', '/uploads/meetings/minutes.png')
+	`, meetingID, suite.ownerID)
+	suite.Require().NoError(err)
+	_, err = suite.db.Exec("INSERT INTO chat_rooms (id, name, type, created_by) VALUES ('room-1', 'Pair', 'private', ?)", suite.ownerID)
+	suite.Require().NoError(err)
+	_, err = suite.db.Exec(`
+		INSERT INTO chat_messages (id, room_id, sender_id, message, content, metadata, file_url)
+		VALUES ('msg-1', 'room-1', ?, 'photo', 'photo', ?, 'https://example.com/uploads/chat/photo.png')
+	`, suite.ownerID, `{"fileName":"photo.png","fileUrl":"https://example.com/uploads/chat/photo.png"}`)
+	suite.Require().NoError(err)
+
+	imported, err := suite.service.ImportLegacyUploads()
+	suite.Require().NoError(err)
+	suite.Equal(2, imported)
+
+	var fileID, filePath, fileURL string
+	err = suite.db.QueryRow("SELECT file_id, file_path, file_url FROM meeting_documents WHERE id = 'doc-1'").Scan(&fileID, &filePath, &fileURL)
+	suite.Require().NoError(err)
+	suite.Empty(filePath)
+	suite.Equal(services.StoredFileURL(fileID), fileURL)
+	_, err = suite.service.Authorize(fileID, suite.outsider, false)
+	suite.Require().Error(err, "imported meeting documents are scoped to the chama")
+	_, err = suite.service.Authorize(fileID, suite.memberID, false)
+	suite.Require().NoError(err)
+	suite.NoFileExists("uploads/meetings/minutes.png")
+
+	var metadata string
+	err = suite.db.QueryRow("SELECT metadata, file_url FROM chat_messages WHERE id = 'msg-1'").Scan(&metadata, &fileURL)
+	suite.Require().NoError(err)
+	suite.Contains(metadata, `"fileId"`)
+	suite.True(strings.HasPrefix(fileURL, "/api/v1/files/"), fileURL)
+	suite.NoFileExists("uploads/chat/photo.png")
+
+	imported, err = suite.service.ImportLegacyUploads()
+	suite.Require().NoError(err)
+	suite.Zero(imported, "imported files are not picked up again")
+}
+
+func TestStorageSuite(t *testing.T) {
+	suite.Run(t, new(StorageTestSuite))
+}
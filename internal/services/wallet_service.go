@@ -13,6 +13,13 @@ import (
 	"vaultke-backend/internal/utils"
 )
 
+// Errors returned when a wallet cannot be debited
+var (
+	errInsufficientBalance = errors.New("insufficient balance")
+	errWalletLocked        = errors.New("wallet is locked")
+	errWalletNotFound      = errors.New("wallet not found")
+)
+
 // WalletService handles wallet-related business logic
 type WalletService struct {
 	db *sql.DB
@@ -28,14 +35,19 @@ func (s *WalletService) CreateWallet(ownerID string, walletType models.WalletTyp
 	return s.CreateWalletWithTx(nil, ownerID, walletType)
 }
 
-// CreateWalletWithTx creates a new wallet within an existing transaction
+// CreateWalletWithTx creates a new KES wallet within an existing transaction
 func (s *WalletService) CreateWalletWithTx(tx *sql.Tx, ownerID string, walletType models.WalletType) (*models.Wallet, error) {
+	return s.CreateCurrencyWalletWithTx(tx, ownerID, walletType, models.DefaultCurrency)
+}
+
+// CreateCurrencyWalletWithTx creates a new wallet held in currency, within tx when it is not nil
+func (s *WalletService) CreateCurrencyWalletWithTx(tx *sql.Tx, ownerID string, walletType models.WalletType, currency string) (*models.Wallet, error) {
 	wallet := &models.Wallet{
 		ID:        uuid.New().String(),
 		Type:      walletType,
 		OwnerID:   ownerID,
 		Balance:   0,
-		Currency:  "KES",
+		Currency:  currency,
 		IsActive:  true,
 		IsLocked:  false,
 		CreatedAt: time.Now(),
@@ -200,7 +212,11 @@ func (s *WalletService) CreateTransaction(transaction *models.TransactionCreatio
 	}
 
 	// Calculate fees
-	tx.Fees = s.calculateTransactionFees(tx)
+	fees, err := s.calculateTransactionFees(tx)
+	if err != nil {
+		return nil, err
+	}
+	tx.Fees = fees
 
 	// Check if transaction requires approval
 	tx.RequiresApproval = s.requiresApproval(tx)
@@ -219,7 +235,50 @@ func (s *WalletService) CreateTransaction(transaction *models.TransactionCreatio
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err = s.db.Exec(query,
+	// Money leaving a wallet is screened for fraud, and counts against the member's
+	// transaction limits when it leaves their own wallet
+	var ownerID, walletType string
+	var evaluation *models.FraudEvaluation
+	if isOutgoingTransactionType(tx.Type) && tx.FromWalletID != nil {
+		err = s.db.QueryRow("SELECT owner_id, type FROM wallets WHERE id = ?", *tx.FromWalletID).Scan(&ownerID, &walletType)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get source wallet: %w", err)
+		}
+		check := &models.FraudCheck{
+			UserID:          initiatedBy,
+			TransactionType: tx.Type,
+			Amount:          tx.Amount,
+			FromWalletID:    *tx.FromWalletID,
+			Reviewable:      true,
+		}
+		if walletType == string(models.WalletTypeChama) {
+			check.ChamaID = ownerID
+		}
+		if tx.ToWalletID != nil {
+			s.db.QueryRow("SELECT owner_id FROM wallets WHERE id = ?", *tx.ToWalletID).Scan(&check.RecipientID)
+		}
+		if evaluation, err = screenOutgoing(s.db, check); err != nil {
+			return nil, err
+		}
+		// A held transaction waits, pending, until an admin reviews it
+		if evaluation.Decision == models.FraudDecisionHold {
+			tx.RequiresApproval = true
+		}
+	}
+
+	dbTx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer dbTx.Rollback()
+
+	if walletType == string(models.WalletTypePersonal) || walletType == string(models.WalletTypeCurrency) {
+		if err := reserveTransactionLimit(dbTx, ownerID, tx.ID, tx.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = dbTx.Exec(query,
 		tx.ID, tx.FromWalletID, tx.ToWalletID, tx.Type, tx.Status, tx.Amount,
 		tx.Currency, tx.Description, tx.Reference, tx.PaymentMethod, metadataJSON,
 		tx.Fees, tx.InitiatedBy, tx.RequiresApproval, tx.CreatedAt, tx.UpdatedAt,
@@ -228,6 +287,20 @@ func (s *WalletService) CreateTransaction(transaction *models.TransactionCreatio
 		return nil, fmt.Errorf("failed to create transaction: %w", err)
 	}
 
+	if evaluation != nil {
+		if err := recordFraudEvaluation(dbTx, evaluation, tx.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if evaluation != nil && evaluation.Decision == models.FraudDecisionHold {
+		notifyFraudDecision(s.db, evaluation)
+	}
+
 	return tx, nil
 }
 
@@ -243,6 +316,9 @@ func (s *WalletService) ProcessTransaction(transactionID string) error {
 	if transaction.Status != models.TransactionStatusPending {
 		return fmt.Errorf("transaction is not in pending status")
 	}
+	if err := checkFraudHold(s.db, transactionID); err != nil {
+		return err
+	}
 
 	// Start database transaction
 	dbTx, err := s.db.Begin()
@@ -267,12 +343,23 @@ func (s *WalletService) ProcessTransaction(transactionID string) error {
 		return err
 	}
 
+	// The fee taken from the payer is platform revenue
+	if transaction.Fees > 0 && transaction.Type != models.TransactionTypeDeposit {
+		if err := NewFeeService(s.db).postFeeRevenue(dbTx, transaction); err != nil {
+			return err
+		}
+	}
+
 	// Update transaction status using centralized function
 	err = s.updateTransactionStatus(dbTx, transactionID, models.TransactionStatusCompleted)
 	if err != nil {
 		return fmt.Errorf("failed to update transaction status: %w", err)
 	}
 
+	if err := NewAuditService(s.db).RecordTransaction(dbTx, "", transactionID); err != nil {
+		return err
+	}
+
 	// Commit transaction
 	if err = dbTx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -391,19 +478,10 @@ func (s *WalletService) updateTransactionStatus(tx *sql.Tx, transactionID string
 
 // Helper methods
 
-func (s *WalletService) calculateTransactionFees(transaction *models.Transaction) float64 {
-	// Simple fee calculation - can be made more sophisticated
-	switch transaction.Type {
-	case models.TransactionTypeWithdrawal:
-		if transaction.PaymentMethod == models.PaymentMethodMpesa {
-			return 10.0 // Fixed M-Pesa withdrawal fee
-		}
-		return 5.0 // Other withdrawal fees
-	case models.TransactionTypeTransfer:
-		return 2.0 // Transfer fee
-	default:
-		return 0.0 // No fees for deposits and other types
-	}
+// calculateTransactionFees prices a transaction against the admin-managed fee schedule
+func (s *WalletService) calculateTransactionFees(transaction *models.Transaction) (float64, error) {
+	fee, _, err := calculateFee(s.db, transaction.Type, transaction.PaymentMethod, transaction.Amount)
+	return fee, err
 }
 
 func (s *WalletService) requiresApproval(transaction *models.Transaction) bool {
@@ -447,11 +525,17 @@ func (s *WalletService) processWithdrawal(tx *sql.Tx, transaction *models.Transa
 
 	// Get current balance
 	var currentBalance float64
-	err := tx.QueryRow("SELECT balance FROM wallets WHERE id = ?", *transaction.FromWalletID).Scan(&currentBalance)
+	var isLocked bool
+	err := tx.QueryRow("SELECT balance, COALESCE(is_locked, FALSE) FROM wallets WHERE id = ?", *transaction.FromWalletID).Scan(&currentBalance, &isLocked)
 	if err != nil {
 		return fmt.Errorf("failed to get wallet balance: %w", err)
 	}
 
+	// Locked wallets, including fixed-term savings before maturity, cannot be drawn on
+	if isLocked {
+		return errWalletLocked
+	}
+
 	// Check sufficient balance (including fees)
 	totalAmount := transaction.Amount + transaction.Fees
 	if currentBalance < totalAmount {
@@ -491,3 +575,177 @@ func (s *WalletService) processTransfer(tx *sql.Tx, transaction *models.Transact
 
 	return nil
 }
+
+// debitPersonalWallet takes amount from a user's personal wallet inside tx and returns
+// the wallet ID. It fails with errWalletNotFound, errWalletLocked or errInsufficientBalance
+// when the wallet cannot be drawn on.
+func debitPersonalWallet(tx *sql.Tx, userID string, amount float64) (string, error) {
+	var walletID string
+	var balance float64
+	var isLocked bool
+	err := tx.QueryRow("SELECT id, balance, COALESCE(is_locked, FALSE) FROM wallets WHERE owner_id = ? AND type = 'personal'", userID).
+		Scan(&walletID, &balance, &isLocked)
+	if err == sql.ErrNoRows {
+		return "", errWalletNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check wallet balance: %w", err)
+	}
+	if isLocked {
+		return "", errWalletLocked
+	}
+	if balance < amount {
+		return "", errInsufficientBalance
+	}
+
+	_, err = tx.Exec("UPDATE wallets SET balance = balance - ?, updated_at = ? WHERE id = ?", amount, time.Now(), walletID)
+	if err != nil {
+		return "", fmt.Errorf("failed to deduct from wallet: %w", err)
+	}
+	return walletID, nil
+}
+
+// creditPersonalWallet adds amount to a user's personal wallet inside tx, creating the
+// wallet on first use, and returns the wallet ID
+func creditPersonalWallet(tx *sql.Tx, userID string, amount float64) (string, error) {
+	var walletID string
+	err := tx.QueryRow("SELECT id FROM wallets WHERE owner_id = ? AND type = 'personal'", userID).Scan(&walletID)
+	if err == sql.ErrNoRows {
+		walletID = uuid.New().String()
+		_, err = tx.Exec("INSERT INTO wallets (id, owner_id, type, balance, created_at, updated_at) VALUES (?, ?, 'personal', ?, ?, ?)",
+			walletID, userID, amount, time.Now(), time.Now())
+		if err != nil {
+			return "", fmt.Errorf("failed to create wallet: %w", err)
+		}
+		return walletID, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check wallet: %w", err)
+	}
+
+	_, err = tx.Exec("UPDATE wallets SET balance = balance + ?, updated_at = ? WHERE id = ?", amount, time.Now(), walletID)
+	if err != nil {
+		return "", fmt.Errorf("failed to update wallet: %w", err)
+	}
+	return walletID, nil
+}
+
+// creditChamaWallet adds amount to a chama's wallet inside tx, creating the wallet on
+// first use, keeps chamas.total_funds in step and returns the wallet ID
+func creditChamaWallet(tx *sql.Tx, chamaID string, amount float64) (string, error) {
+	now := time.Now()
+	var walletID string
+	err := tx.QueryRow("SELECT id FROM wallets WHERE owner_id = ? AND type = 'chama'", chamaID).Scan(&walletID)
+	if err == sql.ErrNoRows {
+		walletID = "wallet-" + chamaID
+		_, err = tx.Exec(`
+			INSERT INTO wallets (id, owner_id, type, balance, created_at, updated_at)
+			VALUES (?, ?, 'chama', ?, ?, ?)
+		`, walletID, chamaID, amount, now, now)
+		if err != nil {
+			return "", fmt.Errorf("failed to create chama wallet: %w", err)
+		}
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get chama wallet: %w", err)
+	} else {
+		_, err = tx.Exec("UPDATE wallets SET balance = balance + ?, updated_at = ? WHERE id = ?", amount, now, walletID)
+		if err != nil {
+			return "", fmt.Errorf("failed to update chama wallet: %w", err)
+		}
+	}
+
+	if err := syncChamaFunds(tx, chamaID); err != nil {
+		return "", err
+	}
+	return walletID, nil
+}
+
+// syncChamaFunds sets chamas.total_funds to the chama wallet's balance
+func syncChamaFunds(tx *sql.Tx, chamaID string) error {
+	_, err := tx.Exec(`
+		UPDATE chamas SET total_funds = (
+			SELECT COALESCE(balance, 0) FROM wallets WHERE owner_id = ? AND type = 'chama'
+		), updated_at = ?
+		WHERE id = ?
+	`, chamaID, time.Now(), chamaID)
+	if err != nil {
+		return fmt.Errorf("failed to update chama funds: %w", err)
+	}
+	return nil
+}
+
+// currencyWallet returns the ID of the owner's wallet in a currency other than KES,
+// opening it when create is set. It fails with errWalletNotFound otherwise.
+func currencyWallet(tx *sql.Tx, ownerID, currency string, create bool) (string, error) {
+	var walletID string
+	err := tx.QueryRow("SELECT id FROM wallets WHERE owner_id = ? AND type = ? AND currency = ?",
+		ownerID, models.WalletTypeCurrency, currency).Scan(&walletID)
+	if err == nil {
+		return walletID, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to get %s wallet: %w", currency, err)
+	}
+	if !create {
+		return "", errWalletNotFound
+	}
+
+	walletID = uuid.New().String()
+	_, err = tx.Exec(`
+		INSERT INTO wallets (id, owner_id, type, balance, currency, is_active, is_locked, created_at, updated_at)
+		VALUES (?, ?, ?, 0, ?, TRUE, FALSE, ?, ?)
+	`, walletID, ownerID, models.WalletTypeCurrency, currency, time.Now(), time.Now())
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s wallet: %w", currency, err)
+	}
+	return walletID, nil
+}
+
+// debitCurrencyWallet takes amount from the user's wallet in currency: the personal
+// wallet for KES, otherwise their foreign-currency wallet
+func debitCurrencyWallet(tx *sql.Tx, userID, currency string, amount float64) (string, error) {
+	if currency == models.DefaultCurrency {
+		return debitPersonalWallet(tx, userID, amount)
+	}
+
+	walletID, err := currencyWallet(tx, userID, currency, false)
+	if err != nil {
+		return "", err
+	}
+	var balance float64
+	var isLocked bool
+	err = tx.QueryRow("SELECT balance, COALESCE(is_locked, FALSE) FROM wallets WHERE id = ?", walletID).Scan(&balance, &isLocked)
+	if err != nil {
+		return "", fmt.Errorf("failed to check wallet balance: %w", err)
+	}
+	if isLocked {
+		return "", errWalletLocked
+	}
+	if balance < amount {
+		return "", errInsufficientBalance
+	}
+
+	_, err = tx.Exec("UPDATE wallets SET balance = balance - ?, updated_at = ? WHERE id = ?", amount, time.Now(), walletID)
+	if err != nil {
+		return "", fmt.Errorf("failed to deduct from wallet: %w", err)
+	}
+	return walletID, nil
+}
+
+// creditCurrencyWallet adds amount to the user's wallet in currency, opening a
+// foreign-currency wallet on first use
+func creditCurrencyWallet(tx *sql.Tx, userID, currency string, amount float64) (string, error) {
+	if currency == models.DefaultCurrency {
+		return creditPersonalWallet(tx, userID, amount)
+	}
+
+	walletID, err := currencyWallet(tx, userID, currency, true)
+	if err != nil {
+		return "", err
+	}
+	_, err = tx.Exec("UPDATE wallets SET balance = balance + ?, updated_at = ? WHERE id = ?", amount, time.Now(), walletID)
+	if err != nil {
+		return "", fmt.Errorf("failed to update wallet: %w", err)
+	}
+	return walletID, nil
+}
@@ -0,0 +1,786 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"vaultke-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// WelfareLevyService handles per-member levies raised for approved welfare cases.
+// Levy payments go into the chama welfare fund, which pays approved cases out.
+type WelfareLevyService struct {
+	db             *sql.DB
+	welfareService *WelfareService
+}
+
+// NewWelfareLevyService creates a new welfare levy service
+func NewWelfareLevyService(db *sql.DB) *WelfareLevyService {
+	return &WelfareLevyService{
+		db:             db,
+		welfareService: NewWelfareService(db),
+	}
+}
+
+// RaiseLevy assesses every active member for an approved welfare case. The beneficiary is
+// exempt unless included explicitly. Members who consented to wallet deductions are charged
+// straight away.
+func (s *WelfareLevyService) RaiseLevy(chamaID, userID string, req *models.RaiseWelfareLevyRequest) (*models.WelfareLevy, error) {
+	if !s.welfareService.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can raise welfare levies")
+	}
+
+	request, err := s.welfareService.getRequest(req.WelfareRequestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.ChamaID != chamaID {
+		return nil, fmt.Errorf("welfare request not found")
+	}
+	if request.Status != models.WelfareRequestStatusApproved && request.Status != models.WelfareRequestStatusDisbursed {
+		return nil, fmt.Errorf("levies can only be raised for approved welfare requests")
+	}
+
+	var existing int
+	err = s.db.QueryRow(`
+		SELECT COUNT(*) FROM welfare_levies WHERE welfare_request_id = ? AND status = ?
+	`, request.ID, models.WelfareLevyStatusActive).Scan(&existing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing levies: %w", err)
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("an active levy already exists for this welfare request")
+	}
+
+	members, err := s.activeMembers(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	assessed := make([]string, 0, len(members))
+	for _, memberID := range members {
+		if memberID == request.BeneficiaryID && !req.IncludeBeneficiary {
+			continue
+		}
+		assessed = append(assessed, memberID)
+	}
+	if len(assessed) == 0 {
+		return nil, fmt.Errorf("there are no members to assess")
+	}
+
+	target := request.Amount
+	if req.TargetAmount != nil {
+		target = *req.TargetAmount
+	}
+
+	amountPerMember, err := s.defaultAmountPerMember(chamaID, target, len(assessed))
+	if err != nil {
+		return nil, err
+	}
+	if req.AmountPerMember != nil {
+		amountPerMember = *req.AmountPerMember
+	}
+
+	now := time.Now()
+	dueDate := now.AddDate(0, 0, 7)
+	if req.DueDate != nil {
+		if !req.DueDate.After(now) {
+			return nil, fmt.Errorf("due date must be in the future")
+		}
+		dueDate = *req.DueDate
+	}
+	reminderInterval := 24
+	if req.ReminderIntervalHours != nil {
+		reminderInterval = *req.ReminderIntervalHours
+	}
+
+	levyID := uuid.New().String()
+	title := "Welfare levy: " + request.Title
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO welfare_levies (
+			id, chama_id, welfare_request_id, title, amount_per_member, target_amount, collected_amount,
+			status, due_date, reminder_interval_hours, last_reminder_at, created_by, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?, ?, ?, ?, ?, ?)
+	`, levyID, chamaID, request.ID, title, amountPerMember, target, models.WelfareLevyStatusActive,
+		dueDate, reminderInterval, now, userID, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create welfare levy: %w", err)
+	}
+
+	for _, memberID := range assessed {
+		_, err = tx.Exec(`
+			INSERT INTO welfare_levy_assessments (id, levy_id, user_id, amount, status, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, uuid.New().String(), levyID, memberID, amountPerMember, models.LevyAssessmentStatusPending, now, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to assess member: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	notificationService := NewNotificationService(s.db, nil)
+	message := fmt.Sprintf("A welfare levy of KES %.2f has been raised for %s (%s). Please pay by %s.",
+		amountPerMember, request.BeneficiaryName, request.Title, dueDate.Format("02 Jan 2006"))
+	for _, memberID := range assessed {
+		if err := notificationService.CreateInAppNotification(memberID, "chama", "welfare_levy", "Welfare Levy Raised", message, map[string]interface{}{
+			"chamaId":          chamaID,
+			"levyId":           levyID,
+			"welfareRequestId": request.ID,
+			"amount":           amountPerMember,
+		}); err != nil {
+			log.Printf("Failed to notify member %s of welfare levy: %v", memberID, err)
+		}
+	}
+
+	s.collectConsented(levyID)
+
+	return s.getLevy(levyID, true)
+}
+
+// GetLevy returns a levy with every member's assessment
+func (s *WelfareLevyService) GetLevy(chamaID, levyID, userID string) (*models.WelfareLevy, error) {
+	if !s.welfareService.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	levy, err := s.getLevy(levyID, true)
+	if err != nil {
+		return nil, err
+	}
+	if levy.ChamaID != chamaID {
+		return nil, fmt.Errorf("welfare levy not found")
+	}
+	return levy, nil
+}
+
+// GetChamaLevies lists a chama's welfare levies, optionally filtered by status
+func (s *WelfareLevyService) GetChamaLevies(chamaID, userID, status string, limit, offset int) ([]models.WelfareLevy, error) {
+	if !s.welfareService.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	query := "SELECT id FROM welfare_levies WHERE chama_id = ?"
+	args := []interface{}{chamaID}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get welfare levies: %w", err)
+	}
+	var levyIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan welfare levy: %w", err)
+		}
+		levyIDs = append(levyIDs, id)
+	}
+	rows.Close()
+
+	levies := []models.WelfareLevy{}
+	for _, id := range levyIDs {
+		levy, err := s.getLevy(id, false)
+		if err != nil {
+			return nil, err
+		}
+		levies = append(levies, *levy)
+	}
+	return levies, nil
+}
+
+// PayAssessment pays the member's share of a levy from their personal wallet
+func (s *WelfareLevyService) PayAssessment(chamaID, levyID, userID string) (*models.WelfareLevyAssessment, error) {
+	levy, err := s.getLevy(levyID, false)
+	if err != nil {
+		return nil, err
+	}
+	if levy.ChamaID != chamaID {
+		return nil, fmt.Errorf("welfare levy not found")
+	}
+	if levy.Status != models.WelfareLevyStatusActive {
+		return nil, fmt.Errorf("welfare levy is %s", levy.Status)
+	}
+
+	assessment, err := s.getAssessment(levyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if assessment.Status != models.LevyAssessmentStatusPending {
+		return nil, fmt.Errorf("levy is already %s", assessment.Status)
+	}
+
+	if err := s.payAssessment(levy, assessment, "wallet"); err != nil {
+		return nil, err
+	}
+	return s.getAssessment(levyID, userID)
+}
+
+// SendReminders reminds every member who has not paid an active levy
+func (s *WelfareLevyService) SendReminders(chamaID, levyID, userID string) (int, error) {
+	if !s.welfareService.isOfficial(userID, chamaID) {
+		return 0, fmt.Errorf("only chama officials can send levy reminders")
+	}
+
+	levy, err := s.getLevy(levyID, false)
+	if err != nil {
+		return 0, err
+	}
+	if levy.ChamaID != chamaID {
+		return 0, fmt.Errorf("welfare levy not found")
+	}
+	if levy.Status != models.WelfareLevyStatusActive {
+		return 0, fmt.Errorf("welfare levy is %s", levy.Status)
+	}
+
+	return s.remind(levy)
+}
+
+// SendDueReminders retries consented wallet deductions and reminds unpaid members of
+// every active levy whose reminder interval has elapsed. It is run by the scheduler.
+func (s *WelfareLevyService) SendDueReminders() {
+	rows, err := s.db.Query("SELECT id FROM welfare_levies WHERE status = ?", models.WelfareLevyStatusActive)
+	if err != nil {
+		log.Printf("Failed to load active welfare levies: %v", err)
+		return
+	}
+	var levyIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err == nil {
+			levyIDs = append(levyIDs, id)
+		}
+	}
+	rows.Close()
+
+	now := time.Now()
+	for _, levyID := range levyIDs {
+		levy, err := s.getLevy(levyID, false)
+		if err != nil {
+			log.Printf("Failed to load welfare levy %s: %v", levyID, err)
+			continue
+		}
+		interval := time.Duration(levy.ReminderIntervalHours) * time.Hour
+		if levy.LastReminderAt != nil && now.Sub(*levy.LastReminderAt) < interval {
+			continue
+		}
+		if sent, err := s.remind(levy); err != nil {
+			log.Printf("Failed to send reminders for welfare levy %s: %v", levyID, err)
+		} else if sent > 0 {
+			log.Printf("Sent %d welfare levy reminders for levy %s", sent, levyID)
+		}
+	}
+}
+
+// CancelLevy stops an active levy. Payments already made stay in the welfare fund.
+func (s *WelfareLevyService) CancelLevy(chamaID, levyID, userID string) (*models.WelfareLevy, error) {
+	if !s.welfareService.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can cancel welfare levies")
+	}
+
+	levy, err := s.getLevy(levyID, false)
+	if err != nil {
+		return nil, err
+	}
+	if levy.ChamaID != chamaID {
+		return nil, fmt.Errorf("welfare levy not found")
+	}
+	if err := s.finishLevy(levyID, models.WelfareLevyStatusCancelled); err != nil {
+		return nil, err
+	}
+	return s.getLevy(levyID, true)
+}
+
+// GetConsent returns the member's wallet deduction consent for a chama
+func (s *WelfareLevyService) GetConsent(chamaID, userID string) (*models.WelfareLevyConsent, error) {
+	if !s.welfareService.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	return s.getConsent(chamaID, userID)
+}
+
+// UpdateConsent grants or withdraws the member's consent to have levies deducted from their wallet.
+// A max amount of zero allows any levy amount.
+func (s *WelfareLevyService) UpdateConsent(chamaID, userID string, req *models.UpdateLevyConsentRequest) (*models.WelfareLevyConsent, error) {
+	if !s.welfareService.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	if !*req.Granted {
+		if _, err := s.db.Exec("DELETE FROM welfare_levy_consents WHERE chama_id = ? AND user_id = ?", chamaID, userID); err != nil {
+			return nil, fmt.Errorf("failed to withdraw consent: %w", err)
+		}
+		return s.getConsent(chamaID, userID)
+	}
+
+	maxAmount := 0.0
+	if req.MaxAmount != nil {
+		maxAmount = *req.MaxAmount
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO welfare_levy_consents (chama_id, user_id, max_amount, granted_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(chama_id, user_id) DO UPDATE SET
+			max_amount = excluded.max_amount,
+			granted_at = excluded.granted_at
+	`, chamaID, userID, maxAmount, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to save consent: %w", err)
+	}
+
+	// Settle any levies that are already outstanding
+	if err := s.collectConsentedForMember(chamaID, userID); err != nil {
+		log.Printf("Failed to collect outstanding levies for %s: %v", userID, err)
+	}
+
+	return s.getConsent(chamaID, userID)
+}
+
+// payAssessment moves a member's levy payment from their wallet into the welfare fund,
+// then closes the levy if the target is reached and pays out cases waiting on the fund
+func (s *WelfareLevyService) payAssessment(levy *models.WelfareLevy, assessment *models.WelfareLevyAssessment, paymentMethod string) error {
+	evaluation, err := screenOutgoing(s.db, &models.FraudCheck{
+		UserID:          assessment.UserID,
+		TransactionType: models.TransactionTypeContribution,
+		Amount:          assessment.Amount,
+		RecipientID:     levy.ChamaID,
+	})
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	transactionID := uuid.New().String()
+
+	result, err := tx.Exec(`
+		UPDATE welfare_levy_assessments
+		SET status = ?, payment_method = ?, transaction_id = ?, paid_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, models.LevyAssessmentStatusPaid, paymentMethod, transactionID, now, now,
+		assessment.ID, models.LevyAssessmentStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to update levy assessment: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("levy has already been settled")
+	}
+
+	if err := reserveTransactionLimit(tx, assessment.UserID, assessment.ID, assessment.Amount); err != nil {
+		return err
+	}
+	fromWalletID, err := debitPersonalWallet(tx, assessment.UserID, assessment.Amount)
+	if err != nil {
+		return err
+	}
+	if err := s.welfareService.creditFund(tx, levy.ChamaID, assessment.UserID, assessment.Amount); err != nil {
+		return err
+	}
+
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"chamaId":          levy.ChamaID,
+		"contributionType": "welfare_levy",
+		"levyId":           levy.ID,
+		"welfareRequestId": levy.WelfareRequestID,
+	})
+	_, err = tx.Exec(`
+		INSERT INTO transactions (
+			id, from_wallet_id, type, amount, currency, description, status, payment_method,
+			initiated_by, recipient_id, metadata, created_at, updated_at
+		) VALUES (?, ?, 'contribution', ?, 'KES', ?, 'completed', ?, ?, ?, ?, ?, ?)
+	`, transactionID, fromWalletID, assessment.Amount, levy.Title, paymentMethod, assessment.UserID,
+		levy.ChamaID, string(metadata), now, now)
+	if err != nil {
+		return fmt.Errorf("failed to record levy payment: %w", err)
+	}
+
+	if err := NewAuditService(s.db).RecordTransaction(tx, levy.ChamaID, transactionID); err != nil {
+		return err
+	}
+	if err := recordFraudEvaluation(tx, evaluation, transactionID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO welfare_contributions (
+			id, welfare_fund_id, user_id, amount, payment_method, reference,
+			welfare_request_id, contributor_id, status, contributed_at, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'completed', ?, ?, ?)
+	`, uuid.New().String(), welfareFundID(levy.ChamaID), assessment.UserID, assessment.Amount, paymentMethod,
+		transactionID, levy.WelfareRequestID, assessment.UserID, now, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to record levy contribution: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE welfare_levies SET collected_amount = collected_amount + ?, updated_at = ? WHERE id = ?
+	`, assessment.Amount, now, levy.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update levy total: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	InvalidateMemberAnalytics(levy.ChamaID)
+
+	if err := s.closeIfTargetReached(levy.ID); err != nil {
+		log.Printf("Failed to close welfare levy %s: %v", levy.ID, err)
+	}
+	s.welfareService.PayAwaitingRequests(levy.ChamaID, assessment.UserID)
+
+	return nil
+}
+
+// closeIfTargetReached closes a levy once collections meet its target and releases
+// members who have not yet paid
+func (s *WelfareLevyService) closeIfTargetReached(levyID string) error {
+	levy, err := s.getLevy(levyID, false)
+	if err != nil {
+		return err
+	}
+	if levy.Status != models.WelfareLevyStatusActive || levy.CollectedAmount < levy.TargetAmount {
+		return nil
+	}
+	return s.finishLevy(levyID, models.WelfareLevyStatusClosed)
+}
+
+// finishLevy closes or cancels an active levy and notifies members who no longer owe
+func (s *WelfareLevyService) finishLevy(levyID string, status models.WelfareLevyStatus) error {
+	pending, err := s.pendingAssessments(levyID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE welfare_levies SET status = ?, closed_at = ?, updated_at = ? WHERE id = ? AND status = ?
+	`, status, now, now, levyID, models.WelfareLevyStatusActive)
+	if err != nil {
+		return fmt.Errorf("failed to update welfare levy: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("welfare levy is no longer active")
+	}
+
+	_, err = tx.Exec(`
+		UPDATE welfare_levy_assessments SET status = ?, updated_at = ? WHERE levy_id = ? AND status = ?
+	`, models.LevyAssessmentStatusCancelled, now, levyID, models.LevyAssessmentStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to release unpaid members: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	levy, err := s.getLevy(levyID, false)
+	if err != nil {
+		return err
+	}
+
+	title := "Welfare Levy Closed"
+	message := fmt.Sprintf("The target for \"%s\" has been reached. You no longer need to pay this levy.", levy.Title)
+	if status == models.WelfareLevyStatusCancelled {
+		title = "Welfare Levy Cancelled"
+		message = fmt.Sprintf("\"%s\" has been cancelled. You no longer need to pay this levy.", levy.Title)
+	}
+
+	notificationService := NewNotificationService(s.db, nil)
+	for _, assessment := range pending {
+		if err := notificationService.CreateInAppNotification(assessment.UserID, "chama", "welfare_levy", title, message, map[string]interface{}{
+			"chamaId": levy.ChamaID,
+			"levyId":  levy.ID,
+		}); err != nil {
+			log.Printf("Failed to notify member %s of levy closure: %v", assessment.UserID, err)
+		}
+	}
+
+	return nil
+}
+
+// remind charges consenting members, then notifies everyone still owing
+func (s *WelfareLevyService) remind(levy *models.WelfareLevy) (int, error) {
+	s.collectConsented(levy.ID)
+
+	levy, err := s.getLevy(levy.ID, false)
+	if err != nil {
+		return 0, err
+	}
+	if levy.Status != models.WelfareLevyStatusActive {
+		return 0, nil
+	}
+
+	pending, err := s.pendingAssessments(levy.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	notificationService := NewNotificationService(s.db, nil)
+	for _, assessment := range pending {
+		message := fmt.Sprintf("Reminder: your welfare levy of KES %.2f for \"%s\" is still unpaid.", assessment.Amount, levy.Title)
+		if levy.DueDate != nil {
+			message += " Due " + levy.DueDate.Format("02 Jan 2006") + "."
+		}
+		if err := notificationService.CreateInAppNotification(assessment.UserID, "reminder", "welfare_levy", "Welfare Levy Reminder", message, map[string]interface{}{
+			"chamaId": levy.ChamaID,
+			"levyId":  levy.ID,
+			"amount":  assessment.Amount,
+		}); err != nil {
+			log.Printf("Failed to remind member %s of welfare levy: %v", assessment.UserID, err)
+			continue
+		}
+		if _, err := s.db.Exec(`
+			UPDATE welfare_levy_assessments SET reminders_sent = reminders_sent + 1, last_reminded_at = ?, updated_at = ? WHERE id = ?
+		`, now, now, assessment.ID); err != nil {
+			log.Printf("Failed to record levy reminder for %s: %v", assessment.UserID, err)
+		}
+	}
+
+	if _, err := s.db.Exec("UPDATE welfare_levies SET last_reminder_at = ?, updated_at = ? WHERE id = ?", now, now, levy.ID); err != nil {
+		return 0, fmt.Errorf("failed to update levy reminder time: %w", err)
+	}
+
+	return len(pending), nil
+}
+
+// collectConsented deducts the levy from every unpaid member who consented to wallet deductions.
+// Members whose wallets cannot cover it are left for the next reminder.
+func (s *WelfareLevyService) collectConsented(levyID string) {
+	levy, err := s.getLevy(levyID, false)
+	if err != nil || levy.Status != models.WelfareLevyStatusActive {
+		return
+	}
+
+	pending, err := s.pendingAssessments(levyID)
+	if err != nil {
+		log.Printf("Failed to load unpaid levy members: %v", err)
+		return
+	}
+
+	for _, assessment := range pending {
+		consent, err := s.getConsent(levy.ChamaID, assessment.UserID)
+		if err != nil || !consent.Granted || (consent.MaxAmount > 0 && assessment.Amount > consent.MaxAmount) {
+			continue
+		}
+
+		if err := s.payAssessment(levy, &assessment, "auto_deduction"); err != nil {
+			log.Printf("Could not deduct welfare levy from %s: %v", assessment.UserID, err)
+			continue
+		}
+
+		levy, err = s.getLevy(levyID, false)
+		if err != nil || levy.Status != models.WelfareLevyStatusActive {
+			return
+		}
+	}
+}
+
+// collectConsentedForMember settles a member's outstanding levies after they grant consent
+func (s *WelfareLevyService) collectConsentedForMember(chamaID, userID string) error {
+	rows, err := s.db.Query(`
+		SELECT l.id FROM welfare_levies l
+		JOIN welfare_levy_assessments a ON a.levy_id = l.id
+		WHERE l.chama_id = ? AND l.status = ? AND a.user_id = ? AND a.status = ?
+		ORDER BY l.created_at
+	`, chamaID, models.WelfareLevyStatusActive, userID, models.LevyAssessmentStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to load outstanding levies: %w", err)
+	}
+	var levyIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err == nil {
+			levyIDs = append(levyIDs, id)
+		}
+	}
+	rows.Close()
+
+	for _, levyID := range levyIDs {
+		s.collectConsented(levyID)
+	}
+	return nil
+}
+
+// defaultAmountPerMember uses the welfare fund's configured contribution per member, or
+// splits the target evenly, rounded up to the next shilling
+func (s *WelfareLevyService) defaultAmountPerMember(chamaID string, target float64, members int) (float64, error) {
+	var perMember sql.NullFloat64
+	err := s.db.QueryRow("SELECT contribution_per_member FROM welfare_funds WHERE id = ?", welfareFundID(chamaID)).Scan(&perMember)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to get welfare fund: %w", err)
+	}
+	if perMember.Valid && perMember.Float64 > 0 {
+		return perMember.Float64, nil
+	}
+	return math.Ceil(target / float64(members)), nil
+}
+
+func (s *WelfareLevyService) activeMembers(chamaID string) ([]string, error) {
+	rows, err := s.db.Query("SELECT user_id FROM chama_members WHERE chama_id = ? AND is_active = TRUE", chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chama members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan chama member: %w", err)
+		}
+		members = append(members, userID)
+	}
+	return members, nil
+}
+
+func (s *WelfareLevyService) getLevy(levyID string, withAssessments bool) (*models.WelfareLevy, error) {
+	var levy models.WelfareLevy
+	var dueDate, lastReminderAt, closedAt sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT id, chama_id, welfare_request_id, title, amount_per_member, target_amount, collected_amount,
+			status, due_date, reminder_interval_hours, last_reminder_at, created_by, closed_at, created_at, updated_at
+		FROM welfare_levies WHERE id = ?
+	`, levyID).Scan(
+		&levy.ID, &levy.ChamaID, &levy.WelfareRequestID, &levy.Title, &levy.AmountPerMember, &levy.TargetAmount,
+		&levy.CollectedAmount, &levy.Status, &dueDate, &levy.ReminderIntervalHours, &lastReminderAt,
+		&levy.CreatedBy, &closedAt, &levy.CreatedAt, &levy.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("welfare levy not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get welfare levy: %w", err)
+	}
+	if dueDate.Valid {
+		levy.DueDate = &dueDate.Time
+	}
+	if lastReminderAt.Valid {
+		levy.LastReminderAt = &lastReminderAt.Time
+	}
+	if closedAt.Valid {
+		levy.ClosedAt = &closedAt.Time
+	}
+
+	err = s.db.QueryRow(`
+		SELECT
+			COALESCE(SUM(CASE WHEN status = 'paid' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END), 0)
+		FROM welfare_levy_assessments WHERE levy_id = ?
+	`, levyID).Scan(&levy.PaidCount, &levy.PendingCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count levy payments: %w", err)
+	}
+
+	if withAssessments {
+		assessments, err := s.queryAssessments("WHERE a.levy_id = ?", levyID)
+		if err != nil {
+			return nil, err
+		}
+		levy.Assessments = assessments
+	}
+
+	return &levy, nil
+}
+
+func (s *WelfareLevyService) getAssessment(levyID, userID string) (*models.WelfareLevyAssessment, error) {
+	assessments, err := s.queryAssessments("WHERE a.levy_id = ? AND a.user_id = ?", levyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(assessments) == 0 {
+		return nil, fmt.Errorf("you have not been assessed for this levy")
+	}
+	return &assessments[0], nil
+}
+
+func (s *WelfareLevyService) pendingAssessments(levyID string) ([]models.WelfareLevyAssessment, error) {
+	return s.queryAssessments("WHERE a.levy_id = ? AND a.status = ?", levyID, models.LevyAssessmentStatusPending)
+}
+
+func (s *WelfareLevyService) queryAssessments(where string, args ...interface{}) ([]models.WelfareLevyAssessment, error) {
+	rows, err := s.db.Query(`
+		SELECT a.id, a.levy_id, a.user_id, COALESCE(u.first_name || ' ' || u.last_name, ''),
+			a.amount, a.status, a.payment_method, a.transaction_id, a.reminders_sent, a.paid_at
+		FROM welfare_levy_assessments a
+		LEFT JOIN users u ON u.id = a.user_id
+		`+where+`
+		ORDER BY u.first_name, u.last_name
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get levy assessments: %w", err)
+	}
+	defer rows.Close()
+
+	assessments := []models.WelfareLevyAssessment{}
+	for rows.Next() {
+		var assessment models.WelfareLevyAssessment
+		var paymentMethod, transactionID sql.NullString
+		var paidAt sql.NullTime
+		err := rows.Scan(&assessment.ID, &assessment.LevyID, &assessment.UserID, &assessment.MemberName,
+			&assessment.Amount, &assessment.Status, &paymentMethod, &transactionID, &assessment.RemindersSent, &paidAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan levy assessment: %w", err)
+		}
+		if paymentMethod.Valid {
+			assessment.PaymentMethod = &paymentMethod.String
+		}
+		if transactionID.Valid {
+			assessment.TransactionID = &transactionID.String
+		}
+		if paidAt.Valid {
+			assessment.PaidAt = &paidAt.Time
+		}
+		assessments = append(assessments, assessment)
+	}
+	return assessments, nil
+}
+
+func (s *WelfareLevyService) getConsent(chamaID, userID string) (*models.WelfareLevyConsent, error) {
+	consent := &models.WelfareLevyConsent{ChamaID: chamaID, UserID: userID}
+	var grantedAt time.Time
+	err := s.db.QueryRow(`
+		SELECT max_amount, granted_at FROM welfare_levy_consents WHERE chama_id = ? AND user_id = ?
+	`, chamaID, userID).Scan(&consent.MaxAmount, &grantedAt)
+	if err == sql.ErrNoRows {
+		return consent, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get levy consent: %w", err)
+	}
+	consent.Granted = true
+	consent.GrantedAt = &grantedAt
+	return consent, nil
+}
@@ -0,0 +1,166 @@
+package services_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type WelfareLevyTestSuite struct {
+	suite.Suite
+	testDB        *helpers.TestDatabase
+	db            *sql.DB
+	service       *services.WelfareLevyService
+	chamaID       string
+	chairID       string
+	beneficiaryID string
+	members       []string
+	requestID     string
+}
+
+func (suite *WelfareLevyTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewWelfareLevyService(suite.db)
+
+	suite.chairID = suite.testDB.AddTestUser(suite.T(), "Chair")
+	suite.beneficiaryID = suite.testDB.AddTestUser(suite.T(), "Bereaved")
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.beneficiaryID, "member")
+
+	suite.members = nil
+	for _, name := range []string{"Akinyi", "Baraka", "Chebet"} {
+		memberID := suite.testDB.AddTestUser(suite.T(), name)
+		suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, memberID, "member")
+		suite.members = append(suite.members, memberID)
+	}
+
+	suite.requestID = "welfare-" + uuid.New().String()
+	_, err := suite.db.Exec(`
+		INSERT INTO welfare_requests (id, chama_id, requester_id, beneficiary_id, title, description, amount, category, urgency, status)
+		VALUES (?, ?, ?, ?, 'Funeral expenses', 'Burial of a parent', 3000, 'bereavement', 'emergency', 'approved')
+	`, suite.requestID, suite.chamaID, suite.beneficiaryID, suite.beneficiaryID)
+	suite.Require().NoError(err)
+}
+
+func (suite *WelfareLevyTestSuite) fundWallet(userID string, balance float64) {
+	_, err := suite.db.Exec("INSERT INTO wallets (id, type, owner_id, balance) VALUES (?, 'personal', ?, ?)", uuid.New().String(), userID, balance)
+	suite.Require().NoError(err)
+}
+
+func (suite *WelfareLevyTestSuite) grantConsent(userID string) {
+	granted := true
+	_, err := suite.service.UpdateConsent(suite.chamaID, userID, &models.UpdateLevyConsentRequest{Granted: &granted})
+	suite.Require().NoError(err)
+}
+
+func (suite *WelfareLevyTestSuite) TestLevyCollectsAndPaysTheCase() {
+	_, err := suite.service.RaiseLevy(suite.chamaID, suite.members[0], &models.RaiseWelfareLevyRequest{WelfareRequestID: suite.requestID})
+	suite.Error(err, "only officials raise levies")
+
+	suite.fundWallet(suite.members[0], 1000)
+	suite.grantConsent(suite.members[0])
+
+	levy, err := suite.service.RaiseLevy(suite.chamaID, suite.chairID, &models.RaiseWelfareLevyRequest{WelfareRequestID: suite.requestID})
+	suite.Require().NoError(err)
+	suite.Equal(750.0, levy.AmountPerMember, "the target is split across members other than the beneficiary")
+	suite.Require().Len(levy.Assessments, 4)
+	suite.Equal(1, levy.PaidCount, "consenting members are charged straight away")
+	suite.Equal(750.0, levy.CollectedAmount)
+
+	_, err = suite.service.RaiseLevy(suite.chamaID, suite.chairID, &models.RaiseWelfareLevyRequest{WelfareRequestID: suite.requestID})
+	suite.Error(err, "one active levy per case")
+
+	_, err = suite.service.PayAssessment(suite.chamaID, levy.ID, suite.beneficiaryID)
+	suite.Error(err, "the beneficiary is not assessed")
+
+	for _, payer := range []string{suite.chairID, suite.members[1], suite.members[2]} {
+		suite.fundWallet(payer, 750)
+		assessment, err := suite.service.PayAssessment(suite.chamaID, levy.ID, payer)
+		suite.Require().NoError(err)
+		suite.Equal(models.LevyAssessmentStatusPaid, assessment.Status)
+	}
+
+	closed, err := suite.service.GetLevy(suite.chamaID, levy.ID, suite.beneficiaryID)
+	suite.Require().NoError(err)
+	suite.Equal(models.WelfareLevyStatusClosed, closed.Status)
+	suite.Equal(3000.0, closed.CollectedAmount)
+
+	var status string
+	var beneficiaryBalance float64
+	suite.Require().NoError(suite.db.QueryRow("SELECT status FROM welfare_requests WHERE id = ?", suite.requestID).Scan(&status))
+	suite.Require().NoError(suite.db.QueryRow("SELECT balance FROM wallets WHERE owner_id = ? AND type = 'personal'", suite.beneficiaryID).Scan(&beneficiaryBalance))
+	suite.Equal("disbursed", status, "the case is paid once the levy covers it")
+	suite.Equal(3000.0, beneficiaryBalance)
+}
+
+func (suite *WelfareLevyTestSuite) TestLevyClosesWhenTargetReached() {
+	target := 1000.0
+	perMember := 500.0
+	levy, err := suite.service.RaiseLevy(suite.chamaID, suite.chairID, &models.RaiseWelfareLevyRequest{
+		WelfareRequestID: suite.requestID,
+		TargetAmount:     &target,
+		AmountPerMember:  &perMember,
+	})
+	suite.Require().NoError(err)
+
+	for _, payer := range suite.members[:2] {
+		suite.fundWallet(payer, 500)
+		_, err := suite.service.PayAssessment(suite.chamaID, levy.ID, payer)
+		suite.Require().NoError(err)
+	}
+
+	closed, err := suite.service.GetLevy(suite.chamaID, levy.ID, suite.chairID)
+	suite.Require().NoError(err)
+	suite.Equal(models.WelfareLevyStatusClosed, closed.Status)
+	suite.Equal(0, closed.PendingCount, "members who had not paid are released")
+
+	suite.fundWallet(suite.members[2], 500)
+	_, err = suite.service.PayAssessment(suite.chamaID, levy.ID, suite.members[2])
+	suite.Error(err)
+}
+
+func (suite *WelfareLevyTestSuite) TestRemindersAndLateConsent() {
+	levy, err := suite.service.RaiseLevy(suite.chamaID, suite.chairID, &models.RaiseWelfareLevyRequest{WelfareRequestID: suite.requestID})
+	suite.Require().NoError(err)
+
+	_, err = suite.service.SendReminders(suite.chamaID, levy.ID, suite.members[0])
+	suite.Error(err, "only officials send reminders")
+
+	sent, err := suite.service.SendReminders(suite.chamaID, levy.ID, suite.chairID)
+	suite.Require().NoError(err)
+	suite.Equal(4, sent)
+
+	suite.fundWallet(suite.members[1], 2000)
+	suite.grantConsent(suite.members[1])
+
+	sent, err = suite.service.SendReminders(suite.chamaID, levy.ID, suite.chairID)
+	suite.Require().NoError(err)
+	suite.Equal(3, sent, "granting consent settles the outstanding levy")
+
+	updated, err := suite.service.GetLevy(suite.chamaID, levy.ID, suite.members[1])
+	suite.Require().NoError(err)
+	for _, assessment := range updated.Assessments {
+		if assessment.UserID == suite.members[1] {
+			suite.Equal(models.LevyAssessmentStatusPaid, assessment.Status)
+			suite.Require().NotNil(assessment.PaymentMethod)
+			suite.Equal("auto_deduction", *assessment.PaymentMethod)
+		} else {
+			suite.Equal(2, assessment.RemindersSent)
+		}
+	}
+
+	cancelled, err := suite.service.CancelLevy(suite.chamaID, levy.ID, suite.chairID)
+	suite.Require().NoError(err)
+	suite.Equal(models.WelfareLevyStatusCancelled, cancelled.Status)
+}
+
+func TestWelfareLevies(t *testing.T) {
+	suite.Run(t, new(WelfareLevyTestSuite))
+}
@@ -0,0 +1,189 @@
+package services_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"vaultke-backend/internal/models"
+	"vaultke-backend/internal/services"
+	"vaultke-backend/test/helpers"
+)
+
+type WelfareLifecycleTestSuite struct {
+	suite.Suite
+	testDB        *helpers.TestDatabase
+	db            *sql.DB
+	service       *services.WelfareService
+	chamaID       string
+	chairID       string
+	requesterID   string
+	beneficiaryID string
+	memberID      string
+}
+
+func (suite *WelfareLifecycleTestSuite) SetupTest() {
+	suite.testDB = helpers.SetupMigratedTestDatabase(suite.T())
+	suite.db = suite.testDB.DB
+	suite.service = services.NewWelfareService(suite.db)
+
+	suite.chairID = suite.testDB.AddTestUser(suite.T(), "Chair")
+	suite.requesterID = suite.testDB.AddTestUser(suite.T(), "Requester")
+	suite.beneficiaryID = suite.testDB.AddTestUser(suite.T(), "Beneficiary")
+	suite.memberID = suite.testDB.AddTestUser(suite.T(), "Member")
+
+	suite.chamaID = suite.testDB.AddTestChama(suite.T(), suite.chairID)
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.requesterID, "member")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.beneficiaryID, "member")
+	suite.testDB.AddTestChamaMember(suite.T(), suite.chamaID, suite.memberID, "member")
+}
+
+func (suite *WelfareLifecycleTestSuite) seedRequest(category, urgency, status string, amount float64) string {
+	id := "welfare-" + uuid.New().String()
+	_, err := suite.db.Exec(`
+		INSERT INTO welfare_requests (id, chama_id, requester_id, beneficiary_id, title, description, amount, category, urgency, status)
+		VALUES (?, ?, ?, ?, 'Hospital bill', 'Admitted after an accident', ?, ?, ?, ?)
+	`, id, suite.chamaID, suite.requesterID, suite.beneficiaryID, amount, category, urgency, status)
+	suite.Require().NoError(err)
+	return id
+}
+
+func (suite *WelfareLifecycleTestSuite) walletBalance(ownerID string) float64 {
+	var balance float64
+	suite.Require().NoError(suite.db.QueryRow("SELECT balance FROM wallets WHERE owner_id = ? AND type = 'personal'", ownerID).Scan(&balance))
+	return balance
+}
+
+func (suite *WelfareLifecycleTestSuite) TestDefaultThresholdIsMajorityOfMembers() {
+	requestID := suite.seedRequest("medical", "medium", "pending", 5000)
+
+	_, decided, err := suite.service.ResolveVote(requestID, 2, 0, 4)
+	suite.Require().NoError(err)
+	suite.False(decided, "two of four members is not yet a majority")
+
+	status, decided, err := suite.service.ResolveVote(requestID, 3, 0, 4)
+	suite.Require().NoError(err)
+	suite.True(decided)
+	suite.Equal(models.WelfareRequestStatusApproved, status)
+
+	status, decided, err = suite.service.ResolveVote(requestID, 2, 2, 4)
+	suite.Require().NoError(err)
+	suite.True(decided, "a tie once everyone has voted is rejected")
+	suite.Equal(models.WelfareRequestStatusRejected, status)
+}
+
+func (suite *WelfareLifecycleTestSuite) TestCategoryAndUrgencyThresholds() {
+	_, err := suite.service.SetApprovalRule(suite.chamaID, suite.memberID, &models.SetWelfareApprovalRuleRequest{Category: "bereavement", Urgency: "any", ApprovalPercentage: 20})
+	suite.Error(err, "only officials configure thresholds")
+
+	_, err = suite.service.SetApprovalRule(suite.chamaID, suite.chairID, &models.SetWelfareApprovalRuleRequest{Category: "Bereavement", Urgency: "any", ApprovalPercentage: 40})
+	suite.Require().NoError(err)
+	_, err = suite.service.SetApprovalRule(suite.chamaID, suite.chairID, &models.SetWelfareApprovalRuleRequest{Category: "bereavement", Urgency: "emergency", ApprovalPercentage: 20})
+	suite.Require().NoError(err)
+
+	emergency := suite.seedRequest("bereavement", "emergency", "pending", 20000)
+	status, decided, err := suite.service.ResolveVote(emergency, 1, 0, 4)
+	suite.Require().NoError(err)
+	suite.True(decided)
+	suite.Equal(models.WelfareRequestStatusApproved, status)
+
+	low := suite.seedRequest("bereavement", "low", "pending", 20000)
+	_, decided, err = suite.service.ResolveVote(low, 1, 0, 4)
+	suite.Require().NoError(err)
+	suite.False(decided, "the category-wide rule needs more than 40%")
+
+	request, err := suite.service.GetRequest(low, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Equal(40.0, request.ApprovalPercentage)
+
+	rules, err := suite.service.GetApprovalRules(suite.chamaID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Len(rules, 2)
+}
+
+func (suite *WelfareLifecycleTestSuite) TestApprovedRequestPaidFromWelfareFund() {
+	requestID := suite.seedRequest("bereavement", "emergency", "approved", 3000)
+
+	request, err := suite.service.ProcessApproval(requestID, suite.requesterID)
+	suite.Error(err, "the fund is empty")
+	suite.Equal(models.WelfareRequestStatusApproved, request.Status)
+
+	_, err = suite.db.Exec("INSERT INTO wallets (id, type, owner_id, balance, is_locked) VALUES (?, 'personal', ?, 5000, TRUE)", uuid.New().String(), suite.memberID)
+	suite.Require().NoError(err)
+	_, err = suite.service.ContributeToFund(suite.chamaID, suite.memberID, 4000)
+	suite.Error(err, "a locked wallet can't contribute")
+
+	_, err = suite.db.Exec("UPDATE wallets SET is_locked = FALSE WHERE owner_id = ?", suite.memberID)
+	suite.Require().NoError(err)
+	fund, err := suite.service.ContributeToFund(suite.chamaID, suite.memberID, 4000)
+	suite.Require().NoError(err)
+	suite.Equal(1000.0, fund.Balance, "the waiting request is paid as soon as the fund can cover it")
+	suite.Equal(3000.0, fund.TotalDisbursed)
+	suite.Equal(0, fund.AwaitingPayout)
+
+	paid, err := suite.service.GetRequest(requestID, suite.beneficiaryID)
+	suite.Require().NoError(err)
+	suite.Equal(models.WelfareRequestStatusDisbursed, paid.Status)
+	suite.NotNil(paid.DisbursementTransactionID)
+	suite.Equal(3000.0, suite.walletBalance(suite.beneficiaryID))
+	suite.Equal(1000.0, suite.walletBalance(suite.memberID))
+
+	_, err = suite.service.DisburseRequestAsOfficial(requestID, suite.chairID)
+	suite.Error(err, "a request is only paid once")
+}
+
+func (suite *WelfareLifecycleTestSuite) TestYearlyClaimLimits() {
+	maxClaims, maxAmount := 2, 10000.0
+	_, err := suite.service.UpdateSettings(suite.chamaID, suite.chairID, &models.UpdateWelfareSettingsRequest{
+		MaxClaimsPerYear: &maxClaims,
+		MaxAmountPerYear: &maxAmount,
+	})
+	suite.Require().NoError(err)
+
+	first := suite.seedRequest("medical", "high", "disbursed", 6000)
+	suite.Require().NoError(suite.service.CheckClaimLimits(suite.chamaID, suite.beneficiaryID, 4000, ""))
+	suite.Error(suite.service.CheckClaimLimits(suite.chamaID, suite.beneficiaryID, 5000, ""), "over the yearly amount")
+
+	suite.seedRequest("medical", "low", "rejected", 9000)
+	suite.seedRequest("school", "low", "pending", 1000)
+	suite.Error(suite.service.CheckClaimLimits(suite.chamaID, suite.beneficiaryID, 100, ""), "over the yearly claim count")
+	suite.NoError(suite.service.CheckClaimLimits(suite.chamaID, suite.beneficiaryID, 100, first), "editing an existing claim")
+	suite.NoError(suite.service.CheckClaimLimits(suite.chamaID, suite.memberID, 100, ""), "limits are per member")
+}
+
+func (suite *WelfareLifecycleTestSuite) TestEditAndDeletePendingRequest() {
+	requestID := suite.seedRequest("medical", "medium", "pending", 5000)
+
+	amount := 7500.0
+	_, err := suite.service.UpdateRequest(requestID, suite.memberID, &models.UpdateWelfareRequestRequest{Amount: &amount})
+	suite.Error(err, "only the requester edits")
+
+	updated, err := suite.service.UpdateRequest(requestID, suite.requesterID, &models.UpdateWelfareRequestRequest{Amount: &amount})
+	suite.Require().NoError(err)
+	suite.Equal(7500.0, updated.Amount)
+
+	_, err = suite.service.AddDocument(requestID, suite.memberID, &models.WelfareDocument{FileName: "invoice.pdf", FilePath: "/tmp/invoice.pdf", FileURL: "/uploads/welfare/invoice.pdf"})
+	suite.Error(err, "other members cannot attach documents")
+	_, err = suite.service.AddDocument(requestID, suite.beneficiaryID, &models.WelfareDocument{FileName: "invoice.pdf", FilePath: "/tmp/invoice.pdf", FileURL: "/uploads/welfare/invoice.pdf"})
+	suite.Require().NoError(err)
+
+	documents, err := suite.service.GetDocuments(requestID, suite.memberID)
+	suite.Require().NoError(err)
+	suite.Require().Len(documents, 1)
+	suite.Equal("supporting_document", documents[0].DocumentType)
+
+	_, err = suite.service.DeleteRequest(requestID, suite.memberID)
+	suite.Error(err)
+	paths, err := suite.service.DeleteRequest(requestID, suite.requesterID)
+	suite.Require().NoError(err)
+	suite.Equal([]string{"/tmp/invoice.pdf"}, paths)
+
+	_, err = suite.service.GetRequest(requestID, suite.requesterID)
+	suite.Error(err)
+}
+
+func TestWelfareLifecycle(t *testing.T) {
+	suite.Run(t, new(WelfareLifecycleTestSuite))
+}
@@ -0,0 +1,1002 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"vaultke-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// WelfareService handles the welfare request lifecycle: approval thresholds,
+// claim limits, supporting documents and payouts from the chama welfare fund
+type WelfareService struct {
+	db *sql.DB
+}
+
+// NewWelfareService creates a new welfare service
+func NewWelfareService(db *sql.DB) *WelfareService {
+	return &WelfareService{db: db}
+}
+
+// GetRequest returns a welfare request with its supporting documents
+func (s *WelfareService) GetRequest(requestID, userID string) (*models.WelfareRequest, error) {
+	request, err := s.getRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isMember(userID, request.ChamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	rule, err := s.resolveRule(request.ChamaID, request.Category, request.Urgency)
+	if err != nil {
+		return nil, err
+	}
+	request.ApprovalPercentage = rule.ApprovalPercentage
+
+	documents, err := s.getDocuments(requestID)
+	if err != nil {
+		return nil, err
+	}
+	request.Documents = documents
+
+	return request, nil
+}
+
+// UpdateRequest lets the requester edit a welfare request before anyone has voted on it
+func (s *WelfareService) UpdateRequest(requestID, userID string, req *models.UpdateWelfareRequestRequest) (*models.WelfareRequest, error) {
+	request, err := s.getRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequesterID != userID {
+		return nil, fmt.Errorf("only the requester can edit this welfare request")
+	}
+	if request.Status != models.WelfareRequestStatusPending {
+		return nil, fmt.Errorf("cannot edit a welfare request that is %s", request.Status)
+	}
+
+	votes, err := s.countVotes(request.ChamaID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if votes > 0 {
+		return nil, fmt.Errorf("cannot edit a welfare request after voting has started")
+	}
+
+	if req.Title != nil {
+		request.Title = *req.Title
+	}
+	if req.Description != nil {
+		request.Description = *req.Description
+	}
+	if req.Amount != nil {
+		request.Amount = *req.Amount
+	}
+	if req.Category != nil {
+		request.Category = *req.Category
+	}
+	if req.Urgency != nil {
+		request.Urgency = *req.Urgency
+	}
+	if req.BeneficiaryID != nil && *req.BeneficiaryID != "" {
+		if !s.isMember(*req.BeneficiaryID, request.ChamaID) {
+			return nil, fmt.Errorf("beneficiary must be an active member of this chama")
+		}
+		request.BeneficiaryID = *req.BeneficiaryID
+	}
+
+	if err := s.CheckClaimLimits(request.ChamaID, request.BeneficiaryID, request.Amount, requestID); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE welfare_requests
+		SET title = ?, description = ?, amount = ?, category = ?, urgency = ?, beneficiary_id = ?, updated_at = ?
+		WHERE id = ?
+	`, request.Title, request.Description, request.Amount, request.Category, request.Urgency,
+		request.BeneficiaryID, time.Now(), requestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update welfare request: %w", err)
+	}
+
+	return s.GetRequest(requestID, userID)
+}
+
+// DeleteRequest removes a pending welfare request. The requester may delete it until
+// voting starts; officials may delete any pending request. It returns the paths of the
+// request's older, locally saved documents so the caller can remove the files.
+func (s *WelfareService) DeleteRequest(requestID, userID string) ([]string, error) {
+	request, err := s.getRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != models.WelfareRequestStatusPending {
+		return nil, fmt.Errorf("cannot delete a welfare request that is %s", request.Status)
+	}
+
+	isOfficial := s.isOfficial(userID, request.ChamaID)
+	if request.RequesterID != userID && !isOfficial {
+		return nil, fmt.Errorf("only the requester or chama officials can delete this welfare request")
+	}
+	if !isOfficial {
+		votes, err := s.countVotes(request.ChamaID, requestID)
+		if err != nil {
+			return nil, err
+		}
+		if votes > 0 {
+			return nil, fmt.Errorf("cannot delete a welfare request after voting has started")
+		}
+	}
+
+	documents, err := s.getDocuments(requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE votes SET status = 'cancelled', ends_at = ?
+		WHERE chama_id = ? AND title = ? AND type = 'welfare' AND status = 'active'
+	`, time.Now(), request.ChamaID, welfareVoteTitle(requestID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel welfare vote: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM welfare_request_documents WHERE welfare_request_id = ?", requestID); err != nil {
+		return nil, fmt.Errorf("failed to delete welfare documents: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM welfare_requests WHERE id = ?", requestID); err != nil {
+		return nil, fmt.Errorf("failed to delete welfare request: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	paths := make([]string, 0, len(documents))
+	for _, document := range documents {
+		if document.FilePath != "" {
+			paths = append(paths, document.FilePath)
+		}
+	}
+	return paths, nil
+}
+
+// CheckClaimLimits enforces the chama's per-member yearly welfare limits for a beneficiary.
+// excludeRequestID skips a request being edited.
+func (s *WelfareService) CheckClaimLimits(chamaID, beneficiaryID string, amount float64, excludeRequestID string) error {
+	settings, err := s.getSettings(chamaID)
+	if err != nil {
+		return err
+	}
+	if settings.MaxClaimsPerYear == 0 && settings.MaxAmountPerYear == 0 {
+		return nil
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, amount, created_at FROM welfare_requests
+		WHERE chama_id = ? AND COALESCE(beneficiary_id, requester_id) = ? AND status != 'rejected'
+	`, chamaID, beneficiaryID)
+	if err != nil {
+		return fmt.Errorf("failed to check welfare claims: %w", err)
+	}
+	defer rows.Close()
+
+	year := time.Now().Year()
+	claims := 0
+	claimed := 0.0
+	for rows.Next() {
+		var id string
+		var claimAmount float64
+		var createdAt time.Time
+		if err := rows.Scan(&id, &claimAmount, &createdAt); err != nil {
+			return fmt.Errorf("failed to scan welfare claim: %w", err)
+		}
+		if id == excludeRequestID || createdAt.Year() != year {
+			continue
+		}
+		claims++
+		claimed += claimAmount
+	}
+
+	if settings.MaxClaimsPerYear > 0 && claims >= settings.MaxClaimsPerYear {
+		return fmt.Errorf("beneficiary has reached the limit of %d welfare claims this year", settings.MaxClaimsPerYear)
+	}
+	if settings.MaxAmountPerYear > 0 && claimed+amount > settings.MaxAmountPerYear {
+		return fmt.Errorf("request exceeds the yearly welfare limit of KES %.2f (KES %.2f already claimed)",
+			settings.MaxAmountPerYear, claimed)
+	}
+	return nil
+}
+
+// ResolveVote applies the chama's approval threshold for the request's category and urgency.
+// A request is approved once more than the threshold percentage of active members vote yes,
+// and rejected once that can no longer happen. decided is false while the outcome is open.
+func (s *WelfareService) ResolveVote(requestID string, yesVotes, noVotes, totalMembers int) (status models.WelfareRequestStatus, decided bool, err error) {
+	request, err := s.getRequest(requestID)
+	if err != nil {
+		return "", false, err
+	}
+	if totalMembers <= 0 {
+		return models.WelfareRequestStatusPending, false, nil
+	}
+
+	rule, err := s.resolveRule(request.ChamaID, request.Category, request.Urgency)
+	if err != nil {
+		return "", false, err
+	}
+
+	threshold := rule.ApprovalPercentage * float64(totalMembers)
+	remaining := totalMembers - yesVotes - noVotes
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	switch {
+	case float64(yesVotes)*100 > threshold:
+		return models.WelfareRequestStatusApproved, true, nil
+	case float64(yesVotes+remaining)*100 <= threshold:
+		return models.WelfareRequestStatusRejected, true, nil
+	default:
+		return models.WelfareRequestStatusPending, false, nil
+	}
+}
+
+// ProcessApproval pays out a newly approved request when its approval rule allows automatic
+// disbursement. If the welfare fund cannot cover it, officials are notified and the request
+// stays approved until the fund is topped up.
+func (s *WelfareService) ProcessApproval(requestID, initiatedBy string) (*models.WelfareRequest, error) {
+	request, err := s.getRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := s.resolveRule(request.ChamaID, request.Category, request.Urgency)
+	if err != nil {
+		return nil, err
+	}
+	if !rule.AutoDisburse {
+		s.notifyOfficials(request, "Welfare Payout Pending",
+			fmt.Sprintf("The welfare request \"%s\" was approved and is waiting for an official to disburse KES %.2f", request.Title, request.Amount))
+		return request, nil
+	}
+
+	disbursed, err := s.DisburseRequest(requestID, initiatedBy)
+	if err != nil {
+		s.notifyOfficials(request, "Welfare Fund Shortfall",
+			fmt.Sprintf("The welfare request \"%s\" was approved but could not be paid: %v", request.Title, err))
+		return request, err
+	}
+	return disbursed, nil
+}
+
+// DisburseRequestAsOfficial lets an official pay out an approved request manually
+func (s *WelfareService) DisburseRequestAsOfficial(requestID, userID string) (*models.WelfareRequest, error) {
+	request, err := s.getRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isOfficial(userID, request.ChamaID) {
+		return nil, fmt.Errorf("only chama officials can disburse welfare payouts")
+	}
+	return s.DisburseRequest(requestID, userID)
+}
+
+// DisburseRequest pays the approved amount from the chama welfare fund into the
+// beneficiary's personal wallet and marks the request as disbursed
+func (s *WelfareService) DisburseRequest(requestID, initiatedBy string) (*models.WelfareRequest, error) {
+	request, err := s.getRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != models.WelfareRequestStatusApproved {
+		return nil, fmt.Errorf("only approved welfare requests can be disbursed")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	transactionID := uuid.New().String()
+
+	result, err := tx.Exec(`
+		UPDATE welfare_requests
+		SET status = ?, disbursed_amount = ?, disbursed_at = ?, disbursement_transaction_id = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, models.WelfareRequestStatusDisbursed, request.Amount, now, transactionID, now,
+		requestID, models.WelfareRequestStatusApproved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update welfare request: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("welfare request has already been disbursed")
+	}
+
+	var balance float64
+	err = tx.QueryRow("SELECT current_amount FROM welfare_funds WHERE id = ?", welfareFundID(request.ChamaID)).Scan(&balance)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to check welfare fund balance: %w", err)
+	}
+	if balance < request.Amount {
+		return nil, fmt.Errorf("welfare fund balance of KES %.2f cannot cover KES %.2f", balance, request.Amount)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE welfare_funds SET current_amount = current_amount - ?, updated_at = ? WHERE id = ?
+	`, request.Amount, now, welfareFundID(request.ChamaID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to debit welfare fund: %w", err)
+	}
+	toWalletID, err := creditPersonalWallet(tx, request.BeneficiaryID, request.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"chamaId":          request.ChamaID,
+		"welfareRequestId": request.ID,
+		"category":         request.Category,
+		"urgency":          request.Urgency,
+	})
+	_, err = tx.Exec(`
+		INSERT INTO transactions (
+			id, to_wallet_id, type, amount, currency, description, status, payment_method,
+			initiated_by, recipient_id, metadata, created_at, updated_at
+		) VALUES (?, ?, 'welfare_disbursement', ?, 'KES', ?, 'completed', 'wallet', ?, ?, ?, ?, ?)
+	`, transactionID, toWalletID, request.Amount, "Welfare payout: "+request.Title, initiatedBy,
+		request.BeneficiaryID, string(metadata), now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record welfare payout: %w", err)
+	}
+
+	if err := NewAuditService(s.db).RecordTransaction(tx, request.ChamaID, transactionID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	notificationService := NewNotificationService(s.db, nil)
+	data := map[string]interface{}{
+		"chamaId":          request.ChamaID,
+		"welfareRequestId": request.ID,
+		"amount":           request.Amount,
+		"transactionId":    transactionID,
+	}
+	message := fmt.Sprintf("KES %.2f from the welfare fund has been paid to your wallet for \"%s\"", request.Amount, request.Title)
+	if err := notificationService.CreateInAppNotification(request.BeneficiaryID, "transaction", "welfare_disbursement", "Welfare Payout Received", message, data); err != nil {
+		log.Printf("Failed to notify beneficiary %s of welfare payout: %v", request.BeneficiaryID, err)
+	}
+	if request.RequesterID != request.BeneficiaryID {
+		message = fmt.Sprintf("KES %.2f has been paid to %s for \"%s\"", request.Amount, request.BeneficiaryName, request.Title)
+		if err := notificationService.CreateInAppNotification(request.RequesterID, "chama", "welfare_disbursement", "Welfare Payout Sent", message, data); err != nil {
+			log.Printf("Failed to notify requester %s of welfare payout: %v", request.RequesterID, err)
+		}
+	}
+
+	return s.getRequest(requestID)
+}
+
+// GetFund returns the chama welfare fund balance and outstanding payouts
+func (s *WelfareService) GetFund(chamaID, userID string) (*models.WelfareFund, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	fund := &models.WelfareFund{ID: welfareFundID(chamaID), ChamaID: chamaID, Name: "Welfare Fund"}
+	var rate sql.NullFloat64
+	err := s.db.QueryRow(`
+		SELECT name, COALESCE(current_amount, 0), contribution_per_member FROM welfare_funds WHERE id = ?
+	`, fund.ID).Scan(&fund.Name, &fund.Balance, &rate)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get welfare fund: %w", err)
+	}
+	fund.ContributionRate = rate.Float64
+
+	err = s.db.QueryRow(`
+		SELECT
+			COALESCE(SUM(CASE WHEN status = 'disbursed' THEN disbursed_amount ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'approved' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'approved' THEN amount ELSE 0 END), 0)
+		FROM welfare_requests WHERE chama_id = ?
+	`, chamaID).Scan(&fund.TotalDisbursed, &fund.AwaitingPayout, &fund.AwaitingAmount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarise welfare payouts: %w", err)
+	}
+
+	return fund, nil
+}
+
+// ContributeToFund moves money from the member's personal wallet into the chama welfare
+// fund, then pays out any approved requests the new balance can cover
+func (s *WelfareService) ContributeToFund(chamaID, userID string, amount float64) (*models.WelfareFund, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("contribution amount must be greater than 0")
+	}
+
+	evaluation, err := screenOutgoing(s.db, &models.FraudCheck{
+		UserID:          userID,
+		TransactionType: models.TransactionTypeContribution,
+		Amount:          amount,
+		RecipientID:     chamaID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := reserveTransactionLimit(tx, userID, "", amount); err != nil {
+		return nil, err
+	}
+	fromWalletID, err := debitPersonalWallet(tx, userID, amount)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.creditFund(tx, chamaID, userID, amount); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	transactionID := uuid.New().String()
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"chamaId":          chamaID,
+		"contributionType": "welfare",
+		"welfareFundId":    welfareFundID(chamaID),
+	})
+	_, err = tx.Exec(`
+		INSERT INTO transactions (
+			id, from_wallet_id, type, amount, currency, description, status, payment_method,
+			initiated_by, recipient_id, metadata, created_at, updated_at
+		) VALUES (?, ?, 'contribution', ?, 'KES', 'Welfare fund contribution', 'completed', 'wallet', ?, ?, ?, ?, ?)
+	`, transactionID, fromWalletID, amount, userID, chamaID, string(metadata), now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record welfare contribution: %w", err)
+	}
+
+	if err := NewAuditService(s.db).RecordTransaction(tx, chamaID, transactionID); err != nil {
+		return nil, err
+	}
+	if err := recordFraudEvaluation(tx, evaluation, transactionID); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO welfare_contributions (
+			id, welfare_fund_id, user_id, amount, payment_method, reference,
+			contributor_id, status, contributed_at, created_at, updated_at
+		) VALUES (?, ?, ?, ?, 'wallet', ?, ?, 'completed', ?, ?, ?)
+	`, uuid.New().String(), welfareFundID(chamaID), userID, amount, transactionID, userID, now, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record welfare contribution: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	InvalidateMemberAnalytics(chamaID)
+
+	s.PayAwaitingRequests(chamaID, userID)
+
+	return s.GetFund(chamaID, userID)
+}
+
+// PayAwaitingRequests disburses approved requests that were waiting for funds, most urgent
+// and oldest first, stopping at the first request the fund cannot cover
+func (s *WelfareService) PayAwaitingRequests(chamaID, initiatedBy string) {
+	rows, err := s.db.Query(`
+		SELECT id FROM welfare_requests
+		WHERE chama_id = ? AND status = 'approved'
+		ORDER BY CASE urgency WHEN 'emergency' THEN 0 WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END, created_at
+	`, chamaID)
+	if err != nil {
+		log.Printf("Failed to load welfare requests awaiting payout: %v", err)
+		return
+	}
+
+	var requestIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err == nil {
+			requestIDs = append(requestIDs, id)
+		}
+	}
+	rows.Close()
+
+	for _, requestID := range requestIDs {
+		if _, err := s.DisburseRequest(requestID, initiatedBy); err != nil {
+			log.Printf("Welfare request %s still awaiting payout: %v", requestID, err)
+			return
+		}
+	}
+}
+
+// AddDocument attaches an uploaded supporting document to a welfare request
+func (s *WelfareService) AddDocument(requestID, userID string, document *models.WelfareDocument) (*models.WelfareDocument, error) {
+	request, err := s.getRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequesterID != userID && request.BeneficiaryID != userID && !s.isOfficial(userID, request.ChamaID) {
+		return nil, fmt.Errorf("only the requester, beneficiary or chama officials can add documents")
+	}
+	if request.Status == models.WelfareRequestStatusRejected {
+		return nil, fmt.Errorf("cannot add documents to a rejected welfare request")
+	}
+
+	document.ID = uuid.New().String()
+	document.WelfareRequestID = requestID
+	document.UploadedBy = userID
+	document.CreatedAt = time.Now()
+	if document.DocumentType == "" {
+		document.DocumentType = "supporting_document"
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO welfare_request_documents (
+			id, welfare_request_id, uploaded_by, file_name, file_path, file_url,
+			file_size, file_type, document_type, description, file_id, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, document.ID, requestID, userID, document.FileName, document.FilePath, document.FileURL,
+		document.FileSize, document.FileType, document.DocumentType, document.Description, document.FileID, document.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save welfare document: %w", err)
+	}
+
+	return document, nil
+}
+
+// GetDocuments lists the supporting documents of a welfare request
+func (s *WelfareService) GetDocuments(requestID, userID string) ([]models.WelfareDocument, error) {
+	request, err := s.getRequest(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isMember(userID, request.ChamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	return s.getDocuments(requestID)
+}
+
+// GetSettings returns a chama's welfare claim limits
+func (s *WelfareService) GetSettings(chamaID, userID string) (*models.WelfareSettings, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+	return s.getSettings(chamaID)
+}
+
+// UpdateSettings creates or updates a chama's welfare claim limits
+func (s *WelfareService) UpdateSettings(chamaID, userID string, req *models.UpdateWelfareSettingsRequest) (*models.WelfareSettings, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can configure welfare limits")
+	}
+
+	settings, err := s.getSettings(chamaID)
+	if err != nil {
+		return nil, err
+	}
+	if req.MaxClaimsPerYear != nil {
+		settings.MaxClaimsPerYear = *req.MaxClaimsPerYear
+	}
+	if req.MaxAmountPerYear != nil {
+		settings.MaxAmountPerYear = *req.MaxAmountPerYear
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO welfare_settings (chama_id, max_claims_per_year, max_amount_per_year, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(chama_id) DO UPDATE SET
+			max_claims_per_year = excluded.max_claims_per_year,
+			max_amount_per_year = excluded.max_amount_per_year,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`, chamaID, settings.MaxClaimsPerYear, settings.MaxAmountPerYear, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to update welfare settings: %w", err)
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "welfare_settings",
+		EntityID:   chamaID,
+		Details: map[string]interface{}{
+			"maxClaimsPerYear": settings.MaxClaimsPerYear,
+			"maxAmountPerYear": settings.MaxAmountPerYear,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return s.getSettings(chamaID)
+}
+
+// GetApprovalRules lists a chama's welfare approval thresholds
+func (s *WelfareService) GetApprovalRules(chamaID, userID string) ([]models.WelfareApprovalRule, error) {
+	if !s.isMember(userID, chamaID) {
+		return nil, fmt.Errorf("user is not a member of this chama")
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, chama_id, category, urgency, approval_percentage, auto_disburse, created_by, updated_at
+		FROM welfare_approval_rules
+		WHERE chama_id = ?
+		ORDER BY category, urgency
+	`, chamaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get welfare approval rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []models.WelfareApprovalRule{}
+	for rows.Next() {
+		var rule models.WelfareApprovalRule
+		err := rows.Scan(&rule.ID, &rule.ChamaID, &rule.Category, &rule.Urgency,
+			&rule.ApprovalPercentage, &rule.AutoDisburse, &rule.CreatedBy, &rule.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan welfare approval rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// SetApprovalRule creates or replaces the approval threshold for a category and urgency
+func (s *WelfareService) SetApprovalRule(chamaID, userID string, req *models.SetWelfareApprovalRuleRequest) (*models.WelfareApprovalRule, error) {
+	if !s.isOfficial(userID, chamaID) {
+		return nil, fmt.Errorf("only chama officials can configure welfare approval rules")
+	}
+	if req.ApprovalPercentage <= 0 || req.ApprovalPercentage >= 100 {
+		return nil, fmt.Errorf("approval percentage must be between 0 and 100")
+	}
+
+	rule := &models.WelfareApprovalRule{
+		ID:                 uuid.New().String(),
+		ChamaID:            chamaID,
+		Category:           normaliseWelfareKey(req.Category),
+		Urgency:            normaliseWelfareKey(req.Urgency),
+		ApprovalPercentage: req.ApprovalPercentage,
+		AutoDisburse:       true,
+		CreatedBy:          userID,
+		UpdatedAt:          time.Now(),
+	}
+	if req.AutoDisburse != nil {
+		rule.AutoDisburse = *req.AutoDisburse
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO welfare_approval_rules (
+			id, chama_id, category, urgency, approval_percentage, auto_disburse, created_by, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(chama_id, category, urgency) DO UPDATE SET
+			approval_percentage = excluded.approval_percentage,
+			auto_disburse = excluded.auto_disburse,
+			created_by = excluded.created_by,
+			updated_at = excluded.updated_at
+	`, rule.ID, chamaID, rule.Category, rule.Urgency, rule.ApprovalPercentage, rule.AutoDisburse, userID, rule.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save welfare approval rule: %w", err)
+	}
+
+	err = tx.QueryRow(`
+		SELECT id FROM welfare_approval_rules WHERE chama_id = ? AND category = ? AND urgency = ?
+	`, chamaID, rule.Category, rule.Urgency).Scan(&rule.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get welfare approval rule: %w", err)
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "welfare_approval_rule",
+		EntityID:   rule.ID,
+		Details: map[string]interface{}{
+			"category":           rule.Category,
+			"urgency":            rule.Urgency,
+			"approvalPercentage": rule.ApprovalPercentage,
+			"autoDisburse":       rule.AutoDisburse,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return rule, nil
+}
+
+// DeleteApprovalRule removes an approval threshold so the next matching rule applies
+func (s *WelfareService) DeleteApprovalRule(chamaID, ruleID, userID string) error {
+	if !s.isOfficial(userID, chamaID) {
+		return fmt.Errorf("only chama officials can configure welfare approval rules")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM welfare_approval_rules WHERE id = ? AND chama_id = ?", ruleID, chamaID)
+	if err != nil {
+		return fmt.Errorf("failed to delete welfare approval rule: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("approval rule not found")
+	}
+
+	err = NewAuditService(s.db).Record(tx, &models.AuditEntry{
+		ChainID:    chamaID,
+		ActorID:    &userID,
+		Action:     models.AuditActionSettingsChange,
+		EntityType: "welfare_approval_rule",
+		EntityID:   ruleID,
+		Details:    map[string]interface{}{"deleted": true},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// resolveRule finds the most specific approval rule for a category and urgency,
+// preferring an exact category match over an exact urgency match
+func (s *WelfareService) resolveRule(chamaID, category, urgency string) (*models.WelfareApprovalRule, error) {
+	category = normaliseWelfareKey(category)
+	urgency = normaliseWelfareKey(urgency)
+
+	rule := &models.WelfareApprovalRule{
+		ChamaID:            chamaID,
+		Category:           models.WelfareRuleAny,
+		Urgency:            models.WelfareRuleAny,
+		ApprovalPercentage: models.DefaultWelfareApprovalPercentage,
+		AutoDisburse:       true,
+	}
+	err := s.db.QueryRow(`
+		SELECT id, category, urgency, approval_percentage, auto_disburse, created_by, updated_at
+		FROM welfare_approval_rules
+		WHERE chama_id = ? AND category IN (?, ?) AND urgency IN (?, ?)
+		ORDER BY CASE WHEN category = ? THEN 0 ELSE 1 END, CASE WHEN urgency = ? THEN 0 ELSE 1 END
+		LIMIT 1
+	`, chamaID, category, models.WelfareRuleAny, urgency, models.WelfareRuleAny, category, urgency).Scan(
+		&rule.ID, &rule.Category, &rule.Urgency, &rule.ApprovalPercentage, &rule.AutoDisburse, &rule.CreatedBy, &rule.UpdatedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get welfare approval rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (s *WelfareService) getRequest(requestID string) (*models.WelfareRequest, error) {
+	var request models.WelfareRequest
+	var disbursedAt sql.NullTime
+	var transactionID sql.NullString
+	err := s.db.QueryRow(`
+		SELECT
+			wr.id, wr.chama_id, wr.requester_id, COALESCE(wr.beneficiary_id, wr.requester_id),
+			wr.title, wr.description, wr.amount, wr.category, wr.urgency, wr.status,
+			COALESCE(wr.votes_for, 0), COALESCE(wr.votes_against, 0), COALESCE(wr.disbursed_amount, 0),
+			wr.disbursed_at, wr.disbursement_transaction_id, wr.created_at, wr.updated_at,
+			COALESCE(r.first_name || ' ' || r.last_name, ''), COALESCE(b.first_name || ' ' || b.last_name, '')
+		FROM welfare_requests wr
+		LEFT JOIN users r ON r.id = wr.requester_id
+		LEFT JOIN users b ON b.id = COALESCE(wr.beneficiary_id, wr.requester_id)
+		WHERE wr.id = ?
+	`, requestID).Scan(
+		&request.ID, &request.ChamaID, &request.RequesterID, &request.BeneficiaryID,
+		&request.Title, &request.Description, &request.Amount, &request.Category, &request.Urgency, &request.Status,
+		&request.VotesFor, &request.VotesAgainst, &request.DisbursedAmount,
+		&disbursedAt, &transactionID, &request.CreatedAt, &request.UpdatedAt,
+		&request.RequesterName, &request.BeneficiaryName,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("welfare request not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get welfare request: %w", err)
+	}
+	if disbursedAt.Valid {
+		request.DisbursedAt = &disbursedAt.Time
+	}
+	if transactionID.Valid {
+		request.DisbursementTransactionID = &transactionID.String
+	}
+	return &request, nil
+}
+
+func (s *WelfareService) getDocuments(requestID string) ([]models.WelfareDocument, error) {
+	rows, err := s.db.Query(`
+		SELECT id, welfare_request_id, uploaded_by, file_name, file_path, file_url,
+			file_size, COALESCE(file_type, ''), document_type, COALESCE(description, ''), file_id, created_at
+		FROM welfare_request_documents
+		WHERE welfare_request_id = ?
+		ORDER BY created_at
+	`, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get welfare documents: %w", err)
+	}
+	defer rows.Close()
+
+	documents := []models.WelfareDocument{}
+	for rows.Next() {
+		var document models.WelfareDocument
+		var fileID sql.NullString
+		err := rows.Scan(&document.ID, &document.WelfareRequestID, &document.UploadedBy, &document.FileName,
+			&document.FilePath, &document.FileURL, &document.FileSize, &document.FileType,
+			&document.DocumentType, &document.Description, &fileID, &document.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan welfare document: %w", err)
+		}
+		if fileID.Valid {
+			document.FileID = &fileID.String
+		}
+		documents = append(documents, document)
+	}
+	return documents, nil
+}
+
+func (s *WelfareService) getSettings(chamaID string) (*models.WelfareSettings, error) {
+	settings := &models.WelfareSettings{ChamaID: chamaID}
+	var updatedBy sql.NullString
+	var updatedAt sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT max_claims_per_year, max_amount_per_year, updated_by, updated_at
+		FROM welfare_settings WHERE chama_id = ?
+	`, chamaID).Scan(&settings.MaxClaimsPerYear, &settings.MaxAmountPerYear, &updatedBy, &updatedAt)
+	if err == sql.ErrNoRows {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get welfare settings: %w", err)
+	}
+	if updatedBy.Valid {
+		settings.UpdatedBy = &updatedBy.String
+	}
+	if updatedAt.Valid {
+		settings.UpdatedAt = &updatedAt.Time
+	}
+	return settings, nil
+}
+
+// countVotes counts the ballots cast on a welfare request's vote
+func (s *WelfareService) countVotes(chamaID, requestID string) (int, error) {
+	var count int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM user_votes uv
+		JOIN votes v ON v.id = uv.vote_id
+		WHERE v.chama_id = ? AND v.title = ? AND v.type = 'welfare'
+	`, chamaID, welfareVoteTitle(requestID)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count welfare votes: %w", err)
+	}
+	return count, nil
+}
+
+// creditFund adds money to the chama welfare fund, creating the fund on first use
+func (s *WelfareService) creditFund(tx *sql.Tx, chamaID, userID string, amount float64) error {
+	now := time.Now()
+	_, err := tx.Exec(`
+		INSERT INTO welfare_funds (id, chama_id, name, description, current_amount, purpose, status, created_by, created_at, updated_at)
+		VALUES (?, ?, 'Welfare Fund', 'Standing welfare fund for approved welfare requests', ?, 'general', 'active', ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			current_amount = COALESCE(welfare_funds.current_amount, 0) + excluded.current_amount,
+			updated_at = excluded.updated_at
+	`, welfareFundID(chamaID), chamaID, amount, userID, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to credit welfare fund: %w", err)
+	}
+	return nil
+}
+
+func (s *WelfareService) notifyOfficials(request *models.WelfareRequest, title, message string) {
+	rows, err := s.db.Query(`
+		SELECT user_id FROM chama_members
+		WHERE chama_id = ? AND is_active = TRUE AND role IN ('chairperson', 'secretary', 'treasurer')
+	`, request.ChamaID)
+	if err != nil {
+		log.Printf("Failed to load officials for welfare notification: %v", err)
+		return
+	}
+
+	var officials []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err == nil {
+			officials = append(officials, userID)
+		}
+	}
+	rows.Close()
+
+	notificationService := NewNotificationService(s.db, nil)
+	for _, officialID := range officials {
+		if err := notificationService.CreateInAppNotification(officialID, "chama", "welfare_payout", title, message, map[string]interface{}{
+			"chamaId":          request.ChamaID,
+			"welfareRequestId": request.ID,
+			"amount":           request.Amount,
+		}); err != nil {
+			log.Printf("Failed to notify official %s about welfare request: %v", officialID, err)
+		}
+	}
+}
+
+func (s *WelfareService) isMember(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM chama_members
+		WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
+
+func (s *WelfareService) isOfficial(userID, chamaID string) bool {
+	var exists int
+	err := s.db.QueryRow(`
+		SELECT 1 FROM chama_members
+		WHERE user_id = ? AND chama_id = ? AND is_active = TRUE
+		AND role IN ('chairperson', 'secretary', 'treasurer')
+	`, userID, chamaID).Scan(&exists)
+	return err == nil
+}
+
+// welfareFundID is the id of a chama's standing welfare fund
+func welfareFundID(chamaID string) string {
+	return "welfare-fund-" + chamaID
+}
+
+// welfareVoteTitle is the title the welfare handlers give a request's vote
+func welfareVoteTitle(requestID string) string {
+	return "Welfare Request: " + requestID
+}
+
+func normaliseWelfareKey(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
@@ -223,8 +223,11 @@ func main() {
 		c.HTML(http.StatusOK, "terms-of-service.html", nil)
 	})
 
-	// Serve static files (uploaded images)
-	router.Static("/uploads", "./uploads")
+	// Serve public uploads. Avatars and learning content are meant to be seen by anyone;
+	// meeting, chat and welfare files go through the storage service instead, and older
+	// ones are imported into it at startup.
+	router.Static("/uploads/avatars", "./uploads/avatars")
+	router.Static("/uploads/learning", "./uploads/learning")
 
 	// Serve notification sound files
 	router.Static("/notification_sound", "./notification_sound")
@@ -259,8 +262,41 @@ func main() {
 	// Initialize LiveKit meeting service
 	api.InitializeMeetingService(db, notificationService)
 
+	// Initialize document storage and its virus scanner, if one is configured
+	storageBackend, err := services.NewStorageBackend(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize file storage: %v", err)
+	}
+	storageService, err := services.NewStorageService(db, storageBackend, cfg.StorageURLSecret)
+	if err != nil {
+		log.Fatalf("Failed to initialize file storage: %v", err)
+	}
+	if cfg.StorageScanCommand != "" {
+		storageService.AddScanner(services.NewCommandVirusScanner(cfg.StorageScanCommand))
+	}
+
+	// Move files saved to local disk before the storage service into it
+	if imported, err := storageService.ImportLegacyUploads(); err != nil {
+		log.Printf("Failed to import legacy uploads: %v", err)
+	} else if imported > 0 {
+		log.Printf("Imported %d legacy uploads into file storage", imported)
+	}
+
+	// Initialize share certificate signing
+	certificateSigningKey, err := services.NewCertificateSigningKey(cfg.CertificateSigningKey)
+	if err != nil {
+		log.Fatalf("Failed to load certificate signing key: %v", err)
+	}
+	certificateService := services.NewShareCertificateService(db, certificateSigningKey, cfg.BaseURL)
+
+	// Initialize audit checkpoint signing
+	auditSigningKey, err := services.NewAuditSigningKey(cfg.AuditSigningKey)
+	if err != nil {
+		log.Fatalf("Failed to load audit signing key: %v", err)
+	}
+
 	// Initialize notification scheduler for reminders
-	notificationScheduler := services.NewNotificationScheduler(db)
+	notificationScheduler := services.NewNotificationScheduler(db, auditSigningKey)
 	notificationScheduler.Start()
 
 	// Initialize scheduler service for meeting auto-unlock
@@ -280,6 +316,25 @@ func main() {
 	receiptHandlers := api.NewReceiptHandlers(db)
 	moneyRequestHandlers := api.NewMoneyRequestHandlers(db)
 	accountHandlers := api.NewAccountHandlers(db)
+	meetingGovernanceHandlers := api.NewMeetingGovernanceHandlers(db)
+	attendanceHandlers := api.NewAttendanceHandlers(db)
+	shareMarketHandlers := api.NewShareMarketHandlers(db)
+	auditHandlers := api.NewAuditHandlers(db, auditSigningKey)
+	memberAnalyticsHandlers := api.NewMemberAnalyticsHandlers(db)
+	standingOrderHandlers := api.NewStandingOrderHandlers(db)
+	savingsHandlers := api.NewSavingsHandlers(db)
+	fxHandlers := api.NewFXHandlers(db)
+	feeHandlers := api.NewFeeHandlers(db)
+	kycHandlers := api.NewKYCHandlers(db)
+	fraudHandlers := api.NewFraudHandlers(db)
+	disputeHandlers := api.NewDisputeHandlers(db)
+	fileHandlers := api.NewFileHandlers(storageService)
+	shareCertificateHandlers := api.NewShareCertificateHandlers(certificateService)
+	portfolioHandlers := api.NewPortfolioHandlers(db)
+	expenseHandlers := api.NewExpenseHandlers(db)
+	bankReconciliationHandlers := api.NewBankReconciliationHandlers(db)
+	accountingHandlers := api.NewAccountingHandlers(db)
+	memberStatementHandlers := api.NewMemberStatementHandlers(db)
 
 	// Initialize E2EE service
 	e2eeService := services.NewMilitaryGradeE2EEService(db)
@@ -320,6 +375,12 @@ func main() {
 		c.Next()
 	}
 
+	// Storage middleware to inject storageService into context
+	storageMiddleware := func(c *gin.Context) {
+		c.Set("storageService", storageService)
+		c.Next()
+	}
+
 	// API routes
 	apiGroup := router.Group("/api/v1")
 	{
@@ -333,6 +394,13 @@ func main() {
 			})
 		})
 
+		// Stored file downloads (public; access is granted by the URL's signature)
+		apiGroup.GET("/files/:id/download", fileHandlers.DownloadFile)
+
+		// Share certificate verification (public; QR codes on certificates link here)
+		apiGroup.GET("/certificates/verify/:number", shareCertificateHandlers.VerifyCertificate)
+		apiGroup.GET("/certificates/public-key", shareCertificateHandlers.GetCertificatePublicKey)
+
 		// Authentication routes with stricter rate limiting
 		auth := apiGroup.Group("/auth")
 		auth.Use(middleware.AuthRateLimitMiddleware()) // Stricter rate limiting for auth endpoints
@@ -399,6 +467,7 @@ func main() {
 		protected.Use(configMiddleware)
 		protected.Use(wsMiddleware)
 		protected.Use(e2eeMiddleware)
+		protected.Use(storageMiddleware)
 		{
 			// User routes
 			users := protected.Group("/users")
@@ -476,6 +545,12 @@ func main() {
 				chamas.DELETE("/:id", api.DeleteChama)
 				chamas.GET("/:id/members", api.GetChamaMembers)
 				chamas.GET("/:id/members/:userId/role", api.GetMemberRole)
+				chamas.GET("/:id/members/:userId/analytics", memberAnalyticsHandlers.GetMemberAnalytics)
+				chamas.GET("/:id/analytics/members", memberAnalyticsHandlers.GetChamaMemberAnalytics)
+				chamas.GET("/:id/fixed-savings-rules", savingsHandlers.GetFixedSavingsRules)
+				chamas.PUT("/:id/fixed-savings-rules", savingsHandlers.UpdateFixedSavingsRules)
+				chamas.GET("/:id/consolidated-balances", fxHandlers.GetChamaConsolidatedBalances)
+				chamas.PUT("/:id/base-currency", fxHandlers.SetChamaBaseCurrency)
 				chamas.POST("/:id/join", api.JoinChama)
 				chamas.POST("/:id/leave", api.LeaveChama)
 				chamas.GET("/:id/transactions", api.GetChamaTransactions)
@@ -514,6 +589,88 @@ func main() {
 				wallets.POST("/transfer", api.TransferMoney)
 				wallets.POST("/deposit", api.DepositMoney)
 				wallets.POST("/withdraw", api.WithdrawMoney)
+				wallets.POST("/currencies", fxHandlers.OpenCurrencyWallet)
+				wallets.GET("/consolidated", fxHandlers.GetConsolidatedBalances)
+				wallets.POST("/convert", fxHandlers.ConvertCurrency)
+				wallets.POST("/fx-transfer", fxHandlers.FXTransfer)
+			}
+
+			// Exchange rates; publishing and importing rates is limited to admins
+			fx := protected.Group("/fx")
+			{
+				fx.GET("/rates", fxHandlers.GetExchangeRates)
+				fx.GET("/rates/:base/:quote", fxHandlers.GetExchangeRateHistory)
+				fx.GET("/quote", fxHandlers.GetFXQuote)
+				fx.POST("/rates", authMiddleware.RequireRole("admin"), fxHandlers.SetExchangeRate)
+				fx.POST("/rates/import", authMiddleware.RequireRole("admin"), fxHandlers.ImportExchangeRates)
+			}
+
+			// Fee schedule and transaction limits; changing them is limited to admins
+			fees := protected.Group("/fees")
+			{
+				fees.GET("/quote", feeHandlers.GetFeeQuote)
+				fees.GET("/rules", feeHandlers.GetFeeRules)
+				fees.GET("/limits", feeHandlers.GetLimitTiers)
+				fees.GET("/limits/me", feeHandlers.GetMyLimits)
+				fees.POST("/rules", authMiddleware.RequireRole("admin"), feeHandlers.CreateFeeRule)
+				fees.PUT("/rules/:id", authMiddleware.RequireRole("admin"), feeHandlers.UpdateFeeRule)
+				fees.DELETE("/rules/:id", authMiddleware.RequireRole("admin"), feeHandlers.DeactivateFeeRule)
+				fees.PUT("/limits/:tier", authMiddleware.RequireRole("admin"), feeHandlers.UpdateLimitTier)
+				fees.GET("/revenue", authMiddleware.RequireRole("admin"), feeHandlers.GetFeeRevenue)
+			}
+
+			// Identity verification; reviewing submissions is limited to admins
+			kyc := protected.Group("/kyc")
+			{
+				kyc.GET("/status", kycHandlers.GetKYCStatus)
+				kyc.POST("/submissions", kycHandlers.CreateKYCSubmission)
+				kyc.GET("/submissions/:id", kycHandlers.GetKYCSubmission)
+				kyc.POST("/submissions/:id/documents", kycHandlers.UploadKYCDocument)
+				kyc.POST("/submissions/:id/submit", kycHandlers.SubmitKYCSubmission)
+				kyc.GET("/documents/:id", kycHandlers.GetKYCDocument)
+				kyc.GET("/queue", authMiddleware.RequireRole("admin"), kycHandlers.GetKYCReviewQueue)
+				kyc.POST("/submissions/:id/approve", authMiddleware.RequireRole("admin"), kycHandlers.ApproveKYCSubmission)
+				kyc.POST("/submissions/:id/reject", authMiddleware.RequireRole("admin"), kycHandlers.RejectKYCSubmission)
+				kyc.GET("/reviews", authMiddleware.RequireRole("admin"), kycHandlers.GetKYCReviews)
+			}
+
+			// Fraud review queue and rule settings (admin only)
+			fraud := protected.Group("/fraud")
+			{
+				fraud.GET("/reviews", authMiddleware.RequireRole("admin"), fraudHandlers.GetFraudReviewQueue)
+				fraud.GET("/reviews/:id", authMiddleware.RequireRole("admin"), fraudHandlers.GetFraudEvaluation)
+				fraud.POST("/reviews/:id/release", authMiddleware.RequireRole("admin"), fraudHandlers.ReleaseFraudEvaluation)
+				fraud.POST("/reviews/:id/reject", authMiddleware.RequireRole("admin"), fraudHandlers.RejectFraudEvaluation)
+				fraud.GET("/rules", authMiddleware.RequireRole("admin"), fraudHandlers.GetFraudRules)
+				fraud.PUT("/rules/:id", authMiddleware.RequireRole("admin"), fraudHandlers.UpdateFraudRule)
+				fraud.GET("/rules/stats", authMiddleware.RequireRole("admin"), fraudHandlers.GetFraudRuleStats)
+			}
+
+			// Signed download URLs for stored files
+			files := protected.Group("/files")
+			{
+				files.GET("/:id", fileHandlers.GetFileURL)
+			}
+
+			// Share certificates
+			certificates := protected.Group("/certificates")
+			{
+				certificates.GET("/:certificateId", shareCertificateHandlers.GetCertificate)
+				certificates.GET("/:certificateId/pdf", shareCertificateHandlers.DownloadCertificatePDF)
+			}
+
+			// Transaction disputes
+			disputes := protected.Group("/disputes")
+			{
+				disputes.POST("", disputeHandlers.OpenDispute)
+				disputes.GET("", disputeHandlers.GetMyDisputes)
+				disputes.GET("/queue", authMiddleware.RequireRole("admin"), disputeHandlers.GetDisputeQueue)
+				disputes.GET("/evidence/:id", disputeHandlers.GetDisputeEvidence)
+				disputes.GET("/:id", disputeHandlers.GetDispute)
+				disputes.POST("/:id/evidence", disputeHandlers.UploadDisputeEvidence)
+				disputes.POST("/:id/respond", disputeHandlers.RespondToDispute)
+				disputes.POST("/:id/withdraw", disputeHandlers.WithdrawDispute)
+				disputes.POST("/:id/resolve", authMiddleware.RequireRole("admin"), disputeHandlers.ResolveDispute)
 			}
 
 			// Money request routes (part of wallet functionality)
@@ -524,6 +681,47 @@ func main() {
 				wallet.GET("/recent-contacts", moneyRequestHandlers.GetRecentContacts)
 			}
 
+			// Money request lifecycle routes
+			moneyRequests := protected.Group("/money-requests")
+			{
+				moneyRequests.GET("/incoming", moneyRequestHandlers.GetIncomingMoneyRequests)
+				moneyRequests.GET("/outgoing", moneyRequestHandlers.GetOutgoingMoneyRequests)
+				moneyRequests.POST("/split", moneyRequestHandlers.CreateSplitMoneyRequest)
+				moneyRequests.GET("/splits/:splitId", moneyRequestHandlers.GetMoneyRequestSplit)
+				moneyRequests.GET("/:requestId", moneyRequestHandlers.GetMoneyRequest)
+				moneyRequests.POST("/:requestId/pay", moneyRequestHandlers.PayMoneyRequest)
+				moneyRequests.POST("/:requestId/decline", moneyRequestHandlers.DeclineMoneyRequest)
+				moneyRequests.POST("/:requestId/cancel", moneyRequestHandlers.CancelMoneyRequest)
+			}
+
+			// Standing order routes (recurring transfers from personal wallets)
+			standingOrders := protected.Group("/standing-orders")
+			{
+				standingOrders.POST("", standingOrderHandlers.CreateStandingOrder)
+				standingOrders.GET("", standingOrderHandlers.GetStandingOrders)
+				standingOrders.GET("/:id", standingOrderHandlers.GetStandingOrder)
+				standingOrders.GET("/:id/executions", standingOrderHandlers.GetStandingOrderExecutions)
+				standingOrders.POST("/:id/pause", standingOrderHandlers.PauseStandingOrder)
+				standingOrders.POST("/:id/resume", standingOrderHandlers.ResumeStandingOrder)
+				standingOrders.POST("/:id/cancel", standingOrderHandlers.CancelStandingOrder)
+			}
+
+			// Savings goals and fixed-term locked savings
+			savings := protected.Group("/savings")
+			{
+				savings.POST("/goals", savingsHandlers.CreateSavingsGoal)
+				savings.GET("/goals", savingsHandlers.GetSavingsGoals)
+				savings.GET("/goals/:goalId", savingsHandlers.GetSavingsGoal)
+				savings.POST("/goals/:goalId/deposit", savingsHandlers.DepositToSavingsGoal)
+				savings.POST("/goals/:goalId/withdraw", savingsHandlers.WithdrawFromSavingsGoal)
+				savings.PUT("/goals/:goalId/sweep", savingsHandlers.SetSavingsGoalSweep)
+				savings.POST("/goals/:goalId/close", savingsHandlers.CloseSavingsGoal)
+				savings.POST("/fixed", savingsHandlers.CreateFixedSavings)
+				savings.GET("/fixed", savingsHandlers.GetFixedSavings)
+				savings.GET("/fixed/:fixedId", savingsHandlers.GetFixedSavingsByID)
+				savings.POST("/fixed/:fixedId/break", savingsHandlers.BreakFixedSavings)
+			}
+
 			// Receipt routes
 			receipts := protected.Group("/receipts")
 			{
@@ -716,7 +914,101 @@ func main() {
 				shares.GET("/summary", sharesHandlers.GetChamaSharesSummary)
 				shares.GET("/transactions", sharesHandlers.GetShareTransactions)
 				shares.GET("/members/:memberId", sharesHandlers.GetMemberShares)
+				shares.GET("/certificates", shareCertificateHandlers.GetMyCertificates)
 				shares.PUT("/:shareId", sharesHandlers.UpdateShares)
+
+				// Valuation, redemption, splits and member-to-member trading
+				shares.GET("/price", shareMarketHandlers.GetSharePrice)
+				shares.POST("/price", shareMarketHandlers.SetSharePrice)
+				shares.GET("/price/history", shareMarketHandlers.GetSharePriceHistory)
+				shares.GET("/valuation", shareMarketHandlers.GetShareValuation)
+				shares.GET("/market/settings", shareMarketHandlers.GetShareMarketSettings)
+				shares.PUT("/market/settings", shareMarketHandlers.UpdateShareMarketSettings)
+				shares.POST("/redeem", shareMarketHandlers.RedeemShares)
+				shares.POST("/split", shareMarketHandlers.SplitShares)
+				shares.GET("/market/listings", shareMarketHandlers.GetShareListings)
+				shares.POST("/market/listings", shareMarketHandlers.CreateShareListing)
+				shares.DELETE("/market/listings/:listingId", shareMarketHandlers.CancelShareListing)
+				shares.POST("/market/listings/:listingId/buy", shareMarketHandlers.BuyShareListing)
+			}
+
+			// Investment portfolio and net asset value routes
+			portfolio := protected.Group("/chamas/:id/portfolio")
+			{
+				portfolio.GET("", portfolioHandlers.GetAssets)
+				portfolio.POST("", portfolioHandlers.CreateAsset)
+				portfolio.GET("/nav", portfolioHandlers.GetNAVHistory)
+				portfolio.POST("/nav", portfolioHandlers.RecordNAVSnapshot)
+				portfolio.GET("/performance", portfolioHandlers.GetPerformanceReport)
+				portfolio.GET("/:assetId", portfolioHandlers.GetAsset)
+				portfolio.POST("/:assetId/valuations", portfolioHandlers.RecordValuation)
+				portfolio.POST("/:assetId/entries", portfolioHandlers.RecordEntry)
+				portfolio.POST("/:assetId/dispose", portfolioHandlers.DisposeAsset)
+			}
+
+			// Expense claims, approval rules and the annual budget
+			expenses := protected.Group("/chamas/:id/expenses")
+			{
+				expenses.GET("", expenseHandlers.GetExpenses)
+				expenses.POST("", expenseHandlers.SubmitExpense)
+				expenses.GET("/budget", expenseHandlers.GetBudget)
+				expenses.PUT("/budget", expenseHandlers.SetBudgetLine)
+				expenses.GET("/budget/report", expenseHandlers.GetBudgetReport)
+				expenses.DELETE("/budget/:lineId", expenseHandlers.DeleteBudgetLine)
+				expenses.GET("/approval-rules", expenseHandlers.GetApprovalRules)
+				expenses.PUT("/approval-rules", expenseHandlers.SetApprovalRule)
+				expenses.DELETE("/approval-rules/:ruleId", expenseHandlers.DeleteApprovalRule)
+				expenses.GET("/:expenseId", expenseHandlers.GetExpense)
+				expenses.POST("/:expenseId/receipt", expenseHandlers.UploadReceipt)
+				expenses.POST("/:expenseId/approve", expenseHandlers.ApproveExpense)
+				expenses.POST("/:expenseId/reject", expenseHandlers.RejectExpense)
+				expenses.POST("/:expenseId/cancel", expenseHandlers.CancelExpense)
+				expenses.POST("/:expenseId/pay", expenseHandlers.PayExpense)
+			}
+
+			// Bank statement import and reconciliation routes
+			bankAccounts := protected.Group("/chamas/:id/bank-accounts")
+			{
+				bankAccounts.GET("", bankReconciliationHandlers.GetBankAccounts)
+				bankAccounts.POST("", bankReconciliationHandlers.CreateBankAccount)
+				bankAccounts.GET("/:accountId/statements", bankReconciliationHandlers.GetStatements)
+				bankAccounts.POST("/:accountId/statements", bankReconciliationHandlers.ImportStatement)
+				bankAccounts.GET("/:accountId/lines", bankReconciliationHandlers.GetStatementLines)
+				bankAccounts.GET("/:accountId/lines/:lineId/candidates", bankReconciliationHandlers.GetMatchCandidates)
+				bankAccounts.POST("/:accountId/lines/:lineId/match", bankReconciliationHandlers.MatchLine)
+				bankAccounts.POST("/:accountId/lines/:lineId/unmatch", bankReconciliationHandlers.UnmatchLine)
+				bankAccounts.POST("/:accountId/lines/:lineId/exclude", bankReconciliationHandlers.ExcludeLine)
+				bankAccounts.POST("/:accountId/auto-match", bankReconciliationHandlers.AutoMatch)
+				bankAccounts.POST("/:accountId/transactions/:transactionId/assign", bankReconciliationHandlers.AssignTransaction)
+				bankAccounts.GET("/:accountId/reconciliation", bankReconciliationHandlers.GetReconciliationReport)
+			}
+
+			// Accounting routes (chart of accounts, financial statements, exports and period close)
+			accounting := protected.Group("/chamas/:id/accounting")
+			{
+				accounting.GET("/accounts", accountingHandlers.GetChartOfAccounts)
+				accounting.POST("/accounts", accountingHandlers.CreateLedgerAccount)
+				accounting.PUT("/accounts/:accountId", accountingHandlers.UpdateLedgerAccount)
+				accounting.PUT("/mappings", accountingHandlers.SetLedgerMapping)
+				accounting.GET("/journal", accountingHandlers.GetJournal)
+				accounting.GET("/general-ledger", accountingHandlers.GetGeneralLedger)
+				accounting.GET("/trial-balance", accountingHandlers.GetTrialBalance)
+				accounting.GET("/income-statement", accountingHandlers.GetIncomeStatement)
+				accounting.GET("/balance-sheet", accountingHandlers.GetBalanceSheet)
+				accounting.GET("/export", accountingHandlers.ExportReport)
+				accounting.GET("/periods", accountingHandlers.GetFinancialPeriods)
+				accounting.POST("/periods/close", accountingHandlers.CloseFinancialPeriod)
+				accounting.GET("/periods/:periodId", accountingHandlers.GetFinancialPeriod)
+				accounting.POST("/periods/:periodId/reopen", accountingHandlers.ReopenFinancialPeriod)
+			}
+
+			// Member statement routes (monthly statements and on-demand date ranges)
+			statements := protected.Group("/chamas/:id/statements")
+			{
+				statements.GET("", memberStatementHandlers.GetStatements)
+				statements.GET("/on-demand", memberStatementHandlers.GetOnDemandStatement)
+				statements.GET("/:statementId", memberStatementHandlers.GetStatement)
+				statements.GET("/:statementId/pdf", memberStatementHandlers.DownloadStatementPDF)
 			}
 
 			// Dividends routes
@@ -724,7 +1016,12 @@ func main() {
 			{
 				dividends.POST("/", dividendsHandlers.DeclareDividend)
 				dividends.GET("/", dividendsHandlers.GetChamaDividendDeclarations)
+				dividends.GET("/tax-report", dividendsHandlers.GetDividendTaxReport)
+				dividends.GET("/reinvestment", dividendsHandlers.GetReinvestmentElection)
+				dividends.PUT("/reinvestment", dividendsHandlers.SetReinvestmentElection)
+				dividends.GET("/reinvestment/statement", dividendsHandlers.GetReinvestmentStatement)
 				dividends.GET("/:declarationId", dividendsHandlers.GetDividendDeclarationDetails)
+				dividends.GET("/:declarationId/preview", dividendsHandlers.PreviewDividend)
 				dividends.POST("/:declarationId/approve", dividendsHandlers.ApproveDividend)
 				dividends.POST("/:declarationId/process", dividendsHandlers.ProcessDividendPayments)
 				dividends.GET("/members/:memberId/history", dividendsHandlers.GetMemberDividendHistory)
@@ -744,6 +1041,50 @@ func main() {
 				polls.GET("/members", pollsHandlers.GetChamaMembers)
 			}
 
+			// Meeting resolutions register
+			protected.GET("/chamas/:id/resolutions", meetingGovernanceHandlers.SearchChamaResolutions)
+
+			// Attendance fines and analytics routes
+			attendance := protected.Group("/chamas/:id")
+			{
+				attendance.GET("/attendance/rules", attendanceHandlers.GetFineRules)
+				attendance.PUT("/attendance/rules", attendanceHandlers.UpdateFineRules)
+				attendance.GET("/attendance/report", attendanceHandlers.GetAttendanceReport)
+				attendance.GET("/fines", attendanceHandlers.GetChamaFines)
+				attendance.POST("/fines/:fineId/pay", attendanceHandlers.PayFine)
+				attendance.POST("/fines/:fineId/waive", attendanceHandlers.WaiveFine)
+			}
+
+			// Chama welfare fund, approval rules, claim limits and levies
+			chamaWelfare := protected.Group("/chamas/:id/welfare")
+			{
+				chamaWelfare.GET("/fund", api.GetWelfareFund)
+				chamaWelfare.POST("/fund/contribute", api.ContributeToWelfareFund)
+				chamaWelfare.GET("/settings", api.GetWelfareSettings)
+				chamaWelfare.PUT("/settings", api.UpdateWelfareSettings)
+				chamaWelfare.GET("/approval-rules", api.GetWelfareApprovalRules)
+				chamaWelfare.PUT("/approval-rules", api.SetWelfareApprovalRule)
+				chamaWelfare.DELETE("/approval-rules/:ruleId", api.DeleteWelfareApprovalRule)
+				chamaWelfare.POST("/levies", api.RaiseWelfareLevy)
+				chamaWelfare.GET("/levies", api.GetWelfareLevies)
+				chamaWelfare.GET("/levies/:levyId", api.GetWelfareLevy)
+				chamaWelfare.POST("/levies/:levyId/pay", api.PayWelfareLevy)
+				chamaWelfare.POST("/levies/:levyId/remind", api.RemindWelfareLevy)
+				chamaWelfare.POST("/levies/:levyId/cancel", api.CancelWelfareLevy)
+				chamaWelfare.GET("/levy-consent", api.GetWelfareLevyConsent)
+				chamaWelfare.PUT("/levy-consent", api.UpdateWelfareLevyConsent)
+			}
+
+			// Tamper-evident audit log and signed checkpoints
+			chamaAudit := protected.Group("/chamas/:id/audit")
+			{
+				chamaAudit.GET("", auditHandlers.GetChamaAuditLog)
+				chamaAudit.GET("/verify", auditHandlers.VerifyChamaAuditLog)
+				chamaAudit.GET("/checkpoints", auditHandlers.GetChamaAuditCheckpoints)
+				chamaAudit.POST("/checkpoints", auditHandlers.CreateChamaAuditCheckpoint)
+				chamaAudit.POST("/checkpoints/verify", auditHandlers.VerifyAuditCheckpoint)
+			}
+
 			// Vote routes (using old vote system - working)
 			votes := protected.Group("/chamas/:id/votes")
 			{
@@ -788,6 +1129,13 @@ func main() {
 				globalAccount.POST("/security-events", accountHandlers.LogSecurityEvent)
 			}
 
+			// Platform audit chain
+			audit := protected.Group("/audit")
+			{
+				audit.GET("/verify", auditHandlers.VerifyPlatformAuditLog)
+				audit.GET("/public-key", auditHandlers.GetAuditPublicKey)
+			}
+
 			// User Search routes
 			userSearch := protected.Group("/user-search")
 			{
@@ -852,6 +1200,27 @@ func main() {
 				meetings.GET("/:id/minutes", api.GetMeetingMinutes)                     // Get meeting minutes/notes
 				meetings.GET("/:id/calendar/add-url", api.GetGoogleCalendarAddEventURL) // Get Google Calendar add-event URL
 				meetings.POST("/:id/calendar/create", api.CreateGoogleCalendarEvent)    // Create calendar event with reminders
+
+				// Structured agenda, motions and resolutions
+				meetings.GET("/:id/agenda", meetingGovernanceHandlers.GetAgenda)
+				meetings.POST("/:id/agenda", meetingGovernanceHandlers.AddAgendaItem)
+				meetings.PUT("/:id/agenda/reorder", meetingGovernanceHandlers.ReorderAgenda)
+				meetings.PUT("/:id/agenda/:itemId", meetingGovernanceHandlers.UpdateAgendaItem)
+				meetings.DELETE("/:id/agenda/:itemId", meetingGovernanceHandlers.DeleteAgendaItem)
+				meetings.GET("/:id/motions", meetingGovernanceHandlers.GetMotions)
+				meetings.POST("/:id/motions", meetingGovernanceHandlers.ProposeMotion)
+				meetings.POST("/:id/motions/:motionId/second", meetingGovernanceHandlers.SecondMotion)
+				meetings.POST("/:id/motions/:motionId/withdraw", meetingGovernanceHandlers.WithdrawMotion)
+				meetings.POST("/:id/motions/:motionId/open-voting", meetingGovernanceHandlers.OpenMotionVoting)
+				meetings.POST("/:id/motions/:motionId/vote", meetingGovernanceHandlers.CastMotionVote)
+				meetings.POST("/:id/motions/:motionId/close", meetingGovernanceHandlers.CloseMotionVoting)
+				meetings.GET("/:id/resolutions", meetingGovernanceHandlers.GetMeetingResolutions)
+
+				// Apologies and attendance fines
+				meetings.POST("/:id/apologies", attendanceHandlers.SubmitApology)
+				meetings.GET("/:id/apologies", attendanceHandlers.GetMeetingApologies)
+				meetings.POST("/:id/apologies/:apologyId/review", attendanceHandlers.ReviewApology)
+				meetings.POST("/:id/fines/apply", attendanceHandlers.ApplyMeetingFines)
 			}
 
 			// Merry-Go-Round routes
@@ -880,6 +1249,9 @@ func main() {
 				welfare.POST("/:id/vote", api.VoteOnWelfareRequest)
 				welfare.POST("/contribute", api.ContributeToWelfare)
 				welfare.GET("/:id/contributions", api.GetWelfareContributions)
+				welfare.POST("/:id/documents", api.UploadWelfareDocument)
+				welfare.GET("/:id/documents", api.GetWelfareDocuments)
+				welfare.POST("/:id/disburse", api.DisburseWelfareRequest)
 			}
 
 			// Loan routes
@@ -18,11 +18,12 @@ import (
 	"github.com/google/uuid"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 
 	"vaultke-backend/internal/api"
 
-	"vaultke-backend/internal/database"
+	"vaultke-backend/database"
 	"vaultke-backend/internal/middleware"
 	"vaultke-backend/internal/services"
 )
@@ -43,12 +44,13 @@ type TestConfig struct {
 
 // TestUser represents a test user
 type TestUser struct {
-	ID       string
-	Email    string
-	Phone    string
-	Role     string
-	Password string
-	Token    string
+	ID        string
+	Email     string
+	Phone     string
+	FirstName string
+	Role      string
+	Password  string
+	Token     string
 }
 
 // TestDatabase manages test database setup and teardown
@@ -86,6 +88,22 @@ func SetupTestDatabase() *TestDatabase {
 	return &TestDatabase{DB: db}
 }
 
+// SetupMigratedTestDatabase creates a throwaway SQLite database with the full
+// production schema, including the named migrations, and closes it when the test ends.
+// It is file-backed so that goroutines started by the code under test share it.
+func SetupMigratedTestDatabase(t *testing.T) *TestDatabase {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db")+"?_busy_timeout=5000")
+	require.NoError(t, err, "Failed to open test database")
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, database.Migrate(db), "Failed to migrate test database")
+	require.NoError(t, database.NewMigrationManager(db).RunMigrations(), "Failed to run named migrations")
+
+	return &TestDatabase{DB: db}
+}
+
 // Close closes the test database
 func (td *TestDatabase) Close() {
 	if td.DB != nil {
@@ -95,16 +113,22 @@ func (td *TestDatabase) Close() {
 
 // CreateTestUser creates a test user in the database
 func (td *TestDatabase) CreateTestUser(user TestUser) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	// The cheapest cost keeps suites that create many users fast
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.MinCost)
 	if err != nil {
 		return err
 	}
 
+	firstName := user.FirstName
+	if firstName == "" {
+		firstName = "Test"
+	}
+
 	query := `
 		INSERT INTO users (id, email, phone, first_name, last_name, password_hash, role, status, is_email_verified, is_phone_verified)
 		VALUES (?, ?, ?, ?, ?, ?, ?, 'active', true, true)
 	`
-	_, err = td.DB.Exec(query, user.ID, user.Email, user.Phone, "Test", "User", string(hashedPassword), user.Role)
+	_, err = td.DB.Exec(query, user.ID, user.Email, user.Phone, firstName, "User", string(hashedPassword), user.Role)
 	return err
 }
 
@@ -150,6 +174,69 @@ func (td *TestDatabase) CreateTestProduct(productID, sellerID string) error {
 	return err
 }
 
+// AddTestUser creates an active user with a generated ID and returns the ID
+func (td *TestDatabase) AddTestUser(t *testing.T, firstName string) string {
+	t.Helper()
+
+	id := uuid.New().String()
+	err := td.CreateTestUser(TestUser{
+		ID:        id,
+		Email:     id + "@example.com",
+		Phone:     "+2547" + id[:8],
+		FirstName: firstName,
+		Role:      "user",
+		Password:  "password123",
+	})
+	require.NoError(t, err, "Failed to create test user")
+	return id
+}
+
+// AddTestChama creates a chama with the given user as its chairperson and returns its ID
+func (td *TestDatabase) AddTestChama(t *testing.T, createdBy string) string {
+	t.Helper()
+
+	id := uuid.New().String()
+	require.NoError(t, td.CreateTestChama(id, createdBy), "Failed to create test chama")
+	return id
+}
+
+// AddTestChamaMember adds a user to a chama with the given role, or changes the role
+// of a user who is already a member
+func (td *TestDatabase) AddTestChamaMember(t *testing.T, chamaID, userID, role string) {
+	t.Helper()
+
+	_, err := td.DB.Exec(`
+		INSERT INTO chama_members (id, chama_id, user_id, role, is_active)
+		VALUES (?, ?, ?, ?, TRUE)
+		ON CONFLICT(chama_id, user_id) DO UPDATE SET role = excluded.role, is_active = TRUE
+	`, uuid.New().String(), chamaID, userID, role)
+	require.NoError(t, err, "Failed to add test chama member")
+}
+
+// AddTestMeeting creates a meeting with the given status and returns its ID
+func (td *TestDatabase) AddTestMeeting(t *testing.T, chamaID, createdBy, status string) string {
+	t.Helper()
+
+	id := uuid.New().String()
+	_, err := td.DB.Exec(`
+		INSERT INTO meetings (id, chama_id, title, scheduled_at, status, created_by)
+		VALUES (?, ?, 'Test Meeting', CURRENT_TIMESTAMP, ?, ?)
+	`, id, chamaID, status, createdBy)
+	require.NoError(t, err, "Failed to create test meeting")
+	return id
+}
+
+// AddTestAttendance marks a member present or absent at a meeting
+func (td *TestDatabase) AddTestAttendance(t *testing.T, meetingID, userID string, present bool) {
+	t.Helper()
+
+	_, err := td.DB.Exec(`
+		INSERT INTO meeting_attendance (id, meeting_id, user_id, attendance_type, is_present, joined_at)
+		VALUES (?, ?, ?, 'physical', ?, CURRENT_TIMESTAMP)
+	`, uuid.New().String(), meetingID, userID, present)
+	require.NoError(t, err, "Failed to record test attendance")
+}
+
 // GenerateJWTToken generates a JWT token for testing
 func GenerateJWTToken(userID, role, secret string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
@@ -220,8 +307,8 @@ func SetupTestRouter(db *sql.DB, cfg *TestConfig) *gin.Engine {
 		// Public routes
 		public := apiGroup.Group("/")
 		{
-			public.GET("/marketplace/products", api.GetProducts)
-			public.GET("/marketplace/products/:id", api.GetProduct)
+			// public.GET("/marketplace/products", api.GetProducts)
+			// public.GET("/marketplace/products/:id", api.GetProduct)
 			public.POST("/payments/mpesa/callback", api.HandleMpesaCallback)
 		}
 
@@ -254,17 +341,17 @@ func SetupTestRouter(db *sql.DB, cfg *TestConfig) *gin.Engine {
 			protected.POST("/wallets/transfer", api.TransferMoney)
 			protected.POST("/wallets/withdraw", api.WithdrawMoney)
 
-			// Marketplace
-			protected.POST("/marketplace/products", api.CreateProduct)
-			protected.PUT("/marketplace/products/:id", api.UpdateProduct)
-			protected.DELETE("/marketplace/products/:id", api.DeleteProduct)
-			protected.GET("/marketplace/cart", api.GetCart)
-			protected.POST("/marketplace/cart", api.AddToCart)
-			protected.DELETE("/marketplace/cart/:id", api.RemoveFromCart)
-			protected.GET("/marketplace/orders", api.GetOrders)
-			protected.POST("/marketplace/orders", api.CreateOrder)
-			protected.GET("/marketplace/orders/:id", api.GetOrder)
-			protected.PUT("/marketplace/orders/:id", api.UpdateOrder)
+			// Marketplace (disabled, as in main.go)
+			// protected.POST("/marketplace/products", api.CreateProduct)
+			// protected.PUT("/marketplace/products/:id", api.UpdateProduct)
+			// protected.DELETE("/marketplace/products/:id", api.DeleteProduct)
+			// protected.GET("/marketplace/cart", api.GetCart)
+			// protected.POST("/marketplace/cart", api.AddToCart)
+			// protected.DELETE("/marketplace/cart/:id", api.RemoveFromCart)
+			// protected.GET("/marketplace/orders", api.GetOrders)
+			// protected.POST("/marketplace/orders", api.CreateOrder)
+			// protected.GET("/marketplace/orders/:id", api.GetOrder)
+			// protected.PUT("/marketplace/orders/:id", api.UpdateOrder)
 
 			// Payments
 			protected.POST("/payments/mpesa/stk", api.InitiateMpesaSTK)